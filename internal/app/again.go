@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+	"github.com/gamzabox/humble-ai-cli/internal/textdiff"
+)
+
+// againDiffContext is how many unchanged lines /again shows around each change.
+const againDiffContext = 3
+
+// askAgain re-asks the last user message, optionally on another model, replaces the
+// previous answer with the new one and prints a unified diff of the two answers.
+func (a *App) askAgain(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		fmt.Fprintln(a.output, "Usage: /again [model]")
+		return nil
+	}
+	n := len(a.messages)
+	if n < 2 || a.messages[n-2].Role != "user" || a.messages[n-1].Role != "assistant" {
+		fmt.Fprintln(a.output, "Nothing to ask again yet.")
+		return nil
+	}
+
+	model := ""
+	if len(args) == 1 {
+		a.cfgMu.RLock()
+		cfg := a.cfg
+		a.cfgMu.RUnlock()
+		found, ok := cfg.FindModel(args[0])
+		if !ok {
+			return fmt.Errorf("model %q is not configured in %s", args[0], a.configFilePath())
+		}
+		model = found.Name
+	}
+
+	question := a.messages[n-2]
+	previous := a.messages[n-1]
+	a.messages = a.messages[:n-2]
+
+	if model != "" {
+		saved := a.modelOverride
+		a.modelOverride = model
+		defer func() { a.modelOverride = saved }()
+	}
+
+	err := a.sendUserMessage(ctx, question.Content, false)
+	if err != nil || a.LastOutcome() != TurnOK {
+		// Keep the original exchange when the new attempt did not produce an answer.
+		a.messages = append(a.messages[:n-2], question, previous)
+		return err
+	}
+
+	newName := "new answer"
+	if model != "" {
+		newName += " (" + model + ")"
+	}
+	a.printAnswerDiff(previous, a.messages[len(a.messages)-1], newName)
+	return nil
+}
+
+func (a *App) printAnswerDiff(previous, current history.Message, newName string) {
+	diff := textdiff.Unified("previous answer", newName, previous.Content, current.Content, againDiffContext)
+	if diff == "" {
+		fmt.Fprintln(a.output, "The new answer is identical to the previous one.")
+		return
+	}
+	fmt.Fprintln(a.output)
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		if a.color {
+			line = render.ColorDiffLine(line)
+		}
+		fmt.Fprint(a.output, line)
+	}
+}
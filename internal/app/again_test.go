@@ -0,0 +1,99 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppAgainReasksOnAnotherModelAndDiffsAnswers(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{
+			{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true},
+			{Name: "model-b", Provider: "openai", APIKey: "sk"},
+		},
+	}}
+	first := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "Paris is the capital.\nIt is in France."}}}
+	second := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "Paris is the capital.\nIt lies on the Seine."}}}
+	factory := newStubFactory()
+	factory.Register("model-a", first)
+	factory.Register("model-b", second)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("capital of France?\n/again model-b\nand its population?\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	wantDiff := "--- previous answer\n+++ new answer (model-b)\n@@ -1,2 +1,2 @@\n Paris is the capital.\n-It is in France.\n+It lies on the Seine.\n"
+	if !strings.Contains(got, wantDiff) {
+		t.Fatalf("expected answer diff, got:\n%s", got)
+	}
+
+	againReq := second.Requests()
+	if len(againReq) != 1 {
+		t.Fatalf("expected one request to model-b, got %d", len(againReq))
+	}
+	if msgs := againReq[0].Messages; len(msgs) != 1 || msgs[0].Content != "capital of France?" {
+		t.Fatalf("expected /again to resend only the question, got %+v", msgs)
+	}
+
+	firstReqs := first.Requests()
+	if len(firstReqs) != 2 {
+		t.Fatalf("expected the follow-up to go back to model-a, got %d requests", len(firstReqs))
+	}
+	followUp := firstReqs[1].Messages
+	if len(followUp) != 3 || followUp[1].Content != "Paris is the capital.\nIt lies on the Seine." {
+		t.Fatalf("expected the new answer to replace the previous one in context, got %+v", followUp)
+	}
+}
+
+func TestAppAgainWithoutHistory(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        newStubFactory(),
+		Input:          strings.NewReader("/again\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(output.String(), "Nothing to ask again yet.") {
+		t.Fatalf("expected empty history notice, got:\n%s", output.String())
+	}
+}
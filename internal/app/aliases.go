@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxAliasDepth bounds alias-to-alias expansion so cycles cannot loop forever.
+	maxAliasDepth        = 5
+	aliasArgsPlaceholder = "{args}"
+)
+
+// runAlias expands a configured alias and dispatches the result as a command or prompt.
+// It reports handled=false when no alias matches.
+func (a *App) runAlias(ctx context.Context, cmd, rest string) (handled, exit bool, err error) {
+	a.cfgMu.RLock()
+	body, ok := a.cfg.FindAlias(cmd)
+	a.cfgMu.RUnlock()
+	if !ok {
+		return false, false, nil
+	}
+	if a.aliasDepth >= maxAliasDepth {
+		return true, false, fmt.Errorf("alias %s expands too deeply (possible cycle)", cmd)
+	}
+
+	expanded := expandAliasBody(body, rest)
+	a.logDebug("alias expanded: %s -> %s", cmd, expanded)
+
+	a.aliasDepth++
+	defer func() { a.aliasDepth-- }()
+
+	if strings.HasPrefix(expanded, "/") {
+		exit, err := a.handleCommand(ctx, expanded)
+		return true, exit, err
+	}
+	return true, false, a.handleUserMessage(ctx, expanded)
+}
+
+// expandAliasBody substitutes {args} with the text after the alias, or appends it.
+func expandAliasBody(body, rest string) string {
+	body = strings.TrimSpace(body)
+	rest = strings.TrimSpace(rest)
+	if strings.Contains(body, aliasArgsPlaceholder) {
+		return strings.TrimSpace(strings.ReplaceAll(body, aliasArgsPlaceholder, rest))
+	}
+	if rest == "" {
+		return body
+	}
+	return body + " " + rest
+}
+
+// printAliases lists configured aliases below the built-in help.
+func (a *App) printAliases() {
+	a.cfgMu.RLock()
+	aliases := a.cfg.Aliases
+	a.cfgMu.RUnlock()
+	if len(aliases) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(a.output, "Aliases:")
+	for _, name := range names {
+		fmt.Fprintf(a.output, "  /%s → %s\n", strings.TrimPrefix(name, "/"), aliases[name])
+	}
+}
@@ -0,0 +1,73 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppExpandsAliases(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		Aliases: map[string]string{
+			"/rev":  "Review the following diff for bugs:",
+			"ask":   "Answer briefly: {args} (be concise)",
+			"auto":  "/set-tool-mode auto",
+			"loop1": "/loop2",
+			"loop2": "/loop1",
+		},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/rev +added line\n/ask why\n/auto\n/loop1\n/help\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 prompts from aliases, got %d", len(requests))
+	}
+	last := func(req llm.ChatRequest) string { return req.Messages[len(req.Messages)-1].Content }
+	if got := last(requests[0]); got != "Review the following diff for bugs: +added line" {
+		t.Fatalf("unexpected /rev expansion: %q", got)
+	}
+	if got := last(requests[1]); got != "Answer briefly: why (be concise)" {
+		t.Fatalf("unexpected /ask expansion: %q", got)
+	}
+	if store.cfg.ToolCallMode != "auto" {
+		t.Fatalf("expected /auto alias to run /set-tool-mode, got %q", store.cfg.ToolCallMode)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "expands too deeply") {
+		t.Fatalf("expected alias cycle error, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Aliases:\n") || !strings.Contains(got, "  /rev → Review the following diff for bugs:\n") {
+		t.Fatalf("expected aliases in help output, got:\n%s", got)
+	}
+}
@@ -10,16 +10,22 @@ import (
 	"os/signal"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 	"unicode"
 
+	"github.com/gamzabox/humble-ai-cli/internal/builtin"
+	"github.com/gamzabox/humble-ai-cli/internal/chaos"
 	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/discovery"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
 	"github.com/gamzabox/humble-ai-cli/internal/llm"
 	"github.com/gamzabox/humble-ai-cli/internal/logging"
 	mcpkg "github.com/gamzabox/humble-ai-cli/internal/mcp"
+	"github.com/gamzabox/humble-ai-cli/internal/redact"
+	"github.com/gamzabox/humble-ai-cli/internal/speech"
+	"github.com/gamzabox/humble-ai-cli/internal/tokenizer"
 )
 
 // Clock abstracts time access for testability.
@@ -47,6 +53,15 @@ type MCPFunction = mcpkg.Function
 // MCPConfiguredServer describes a stored MCP server entry.
 type MCPConfiguredServer = mcpkg.ConfiguredServer
 
+// MCPServerStatus is what an MCP server advertised about itself when it connected.
+type MCPServerStatus = mcpkg.ServerStatus
+
+// MCPStatusReporter is implemented by executors that can report their servers'
+// implementation, version and capabilities, for /mcp-status.
+type MCPStatusReporter interface {
+	Status(ctx context.Context, server string) (MCPServerStatus, error)
+}
+
 // MCPExecutor resolves server metadata and executes MCP tool calls.
 type MCPExecutor interface {
 	EnabledServers() []MCPServer
@@ -57,6 +72,17 @@ type MCPExecutor interface {
 	Reload() error
 }
 
+// InputRewriter transforms a user message before it is sent, e.g. expanding ticket IDs.
+// Returning an error rejects the message.
+type InputRewriter interface {
+	Rewrite(ctx context.Context, message string) (string, error)
+}
+
+// ModelDiscoverer probes local model servers for available models.
+type ModelDiscoverer interface {
+	Probe(ctx context.Context) []discovery.Result
+}
+
 // Options configures App creation.
 type Options struct {
 	Store          config.Store
@@ -69,15 +95,86 @@ type Options struct {
 	Clock          Clock
 	Interrupts     chan os.Signal
 	MCP            MCPExecutor
+	// Model overrides the configured active model when non-empty.
+	Model string
+	// ToolCallMode overrides the configured tool call mode when non-empty.
+	ToolCallMode config.ToolCallMode
+	// Discovery probes local servers for /discover; defaults to Ollama and LM Studio.
+	Discovery ModelDiscoverer
+	// InputRewriters run before configured preSendHooks on every user message.
+	InputRewriters []InputRewriter
+	// SkipDestructiveCheck disables forced confirmation for executors that never touch
+	// the host, such as replay stubs.
+	SkipDestructiveCheck bool
+	// Quiet suppresses status lines, thinking and tool banners so that only the final
+	// assistant answer is written to Output. Confirmation prompts go to ErrorOutput.
+	Quiet bool
+	// WorkDir is where the project for workspace context is detected; defaults to the
+	// current directory.
+	WorkDir string
+	// Speech overrides the sink built from the speech config, e.g. in tests.
+	Speech speech.Sink
+	// Faults injects session drops into the built-in MCP manager for resilience testing.
+	Faults *chaos.Injector
+	// Attachment is data given to one-shot mode with the prompt, such as piped stdin or
+	// watched files; every turn attaches it, in chunks, as context for the prompt.
+	Attachment string
+	// ThinkingOutput overrides the configured thinkingOutput when non-empty.
+	ThinkingOutput config.ThinkingOutput
+	// UI replaces Input and Output as the frontend of the interactive loop when set.
+	// Errors go to its Output too, unless ErrorOutput is set.
+	UI UI
+	// Clipboard is read by /paste; defaults to the system clipboard.
+	Clipboard ClipboardReader
 }
 
-// App coordinates CLI behaviour.
+// App coordinates CLI behaviour. It drives one conversation, its session, through a
+// frontend. The engine behind it, with the configuration, providers, MCP servers and
+// logger, can be shared with Apps driving other sessions; see NewSession.
 type App struct {
+	*engine
+	*session
+
+	output     io.Writer
+	errOutput  io.Writer
+	lineReader lineReader
+	ui         UI
+	color      bool
+	transcript *transcriptLog
+	// outputTerminal reports that Output is a terminal rather than a pipe or file.
+	outputTerminal bool
+
+	// answerOutput receives assistant text; it differs from output only in quiet mode.
+	answerOutput io.Writer
+	quiet        bool
+	// interactive is set by Run; one-time tips only show in the interactive loop.
+	interactive bool
+
+	modelOverride        string
+	toolModeOverride     config.ToolCallMode
+	thinkingOverride     config.ThinkingOutput
+	skipDestructiveCheck bool
+
+	// pendingLine receives a line whose read outlived a timed prompt; the next readLine takes it.
+	pendingLine chan lineResult
+
+	// speaker reads answers aloud while speaking is on; see speech.go.
+	speechSink     speech.Sink
+	speaker        *speech.Speaker
+	speechSplitter speech.Splitter
+	speaking       bool
+
+	signalCh   chan os.Signal
+	stopSignal func()
+	// ownsEngine is set on the App that created the engine; only it closes MCP sessions.
+	ownsEngine bool
+}
+
+// engine is the state shared by every session of the process. The configuration and
+// MCP function lists are guarded by their mutexes; the rest is fixed after New.
+type engine struct {
 	store       config.Store
 	factory     ProviderFactory
-	output      io.Writer
-	errOutput   io.Writer
-	lineReader  lineReader
 	historyRoot string
 	homeDir     string
 	clock       Clock
@@ -89,23 +186,63 @@ type App struct {
 	mcpFunctions map[string][]MCPFunction
 	mcpMu        sync.RWMutex
 
+	discovery ModelDiscoverer
+	rewriters []InputRewriter
+	builtins  *builtin.Registry
+	workDir   string
+	clipboard ClipboardReader
+
 	cfgMu sync.RWMutex
 	cfg   config.Config
+}
 
-	messages []llm.Message
+// session is one conversation: its messages, session file and the turn in progress.
+// Each App owns its session, so the Apps of different sessions never share this state.
+type session struct {
+	aliasDepth int
+
+	messages      []history.Message
+	turnBudget    contextBudget
+	turnToolCalls []history.ToolCall
+	// turnAdjustments counts, per server.method, the failed calls the model was allowed to
+	// retry with adjusted arguments this turn.
+	turnAdjustments map[string]int
+	turnTiming      *turnTiming
+	// turnInstruction is the /with instruction for the turn in progress.
+	turnInstruction string
+	// lastThinking is the reasoning streamed during the last turn, for /show-thinking.
+	lastThinking string
+	// workspaceContext holds the project summary, refreshed per session.
+	workspaceContext string
+	attachment       string
+	// pasted is the clipboard text /paste queued for the next message.
+	pasted      string
+	masker      *redact.Masker
+	turnMasking bool
+	// turnNoVision is set while the turn's model declares supportsVision: false.
+	turnNoVision bool
+	// turnCitations is set while the turn's tool results carry citation markers.
+	turnCitations bool
+	// toolLog lists the tool calls made since the session started, for /tool-log.
+	toolLog []toolLogEntry
 
 	historyMu      sync.Mutex
 	historyPath    string
 	firstUserInput string
 	sessionStart   time.Time
+	sessionTags    []string
+	sessionNotes   []string
+	bookmarks      []history.Bookmark
+	turnErrors     []history.TurnError
+	// The system and tool prompts of the latest turn, pinned in the session file.
+	sessionSystemPrompt string
+	sessionToolPrompt   string
 
 	modeMu        sync.Mutex
 	mode          appMode
 	cancelCurrent context.CancelFunc
 	exitRequested bool
-
-	signalCh   chan os.Signal
-	stopSignal func()
+	lastOutcome   TurnOutcome
 }
 
 type appMode int
@@ -117,6 +254,12 @@ const (
 
 var errToolDeclined = errors.New("mcp call declined by user")
 
+// continuePrompt is sent after an answer stops at the token limit so the model picks up where it stopped.
+const continuePrompt = "Continue exactly where you stopped. Do not repeat anything you already wrote."
+
+// resumePrompt is sent after a stream broke mid-answer, with the part received so far.
+const resumePrompt = "Your previous answer was cut off by a network error. Continue exactly from where it stopped. Do not repeat anything you already wrote."
+
 // New constructs an App from options.
 func New(opts Options) (*App, error) {
 	if opts.Store == nil {
@@ -125,7 +268,10 @@ func New(opts Options) (*App, error) {
 	if opts.Factory == nil {
 		return nil, errors.New("factory is required")
 	}
-	if opts.Input == nil {
+	if opts.UI != nil {
+		opts.Output = opts.UI.Output()
+	}
+	if opts.Input == nil && opts.UI == nil {
 		return nil, errors.New("input is required")
 	}
 	if opts.Output == nil {
@@ -153,17 +299,31 @@ func New(opts Options) (*App, error) {
 
 	historyRoot := opts.HistoryRootDir
 	if historyRoot == "" {
-		historyRoot = filepath.Join(home, ".humble-ai-cli", "sessions")
+		historyRoot = filepath.Join(config.Dir(home), "sessions")
 	}
 
 	mcpExec := opts.MCP
+	var manager *mcpkg.Manager
 	if mcpExec == nil {
-		manager, err := mcpkg.NewManager(home)
+		var err error
+		manager, err = mcpkg.NewManager(home)
 		if err != nil {
 			return nil, fmt.Errorf("initialize MCP manager: %w", err)
 		}
+		if opts.Faults != nil {
+			manager.InjectDisconnects(opts.Faults.MCPDisconnect)
+		}
 		mcpExec = manager
 	}
+	discoverer := opts.Discovery
+	if discoverer == nil {
+		discoverer = discovery.NewProber(nil, discovery.DefaultEndpoints())
+	}
+	clipboard := opts.Clipboard
+	if clipboard == nil {
+		clipboard = systemClipboard{}
+	}
+
 	servers := mcpExec.EnabledServers()
 	serverMap := make(map[string]MCPServer, len(servers))
 	for _, srv := range servers {
@@ -184,28 +344,80 @@ func New(opts Options) (*App, error) {
 	}
 
 	app := &App{
-		store:        opts.Store,
-		factory:      opts.Factory,
-		output:       opts.Output,
-		errOutput:    errOutput,
-		historyRoot:  historyRoot,
-		homeDir:      home,
-		clock:        clock,
-		systemPrompt: "",
-		logger:       logger,
-		mcp:          mcpExec,
-		mcpServers:   serverMap,
-		mcpFunctions: make(map[string][]MCPFunction),
-		cfg:          cfg,
-		mode:         modeInput,
-	}
-
-	app.lineReader = createLineReader(opts.Input, app.output, func() {
-		app.handleInterrupt()
-	})
+		engine: &engine{
+			store:        opts.Store,
+			factory:      opts.Factory,
+			historyRoot:  historyRoot,
+			homeDir:      home,
+			clock:        clock,
+			builtins:     builtin.NewRegistry(clock.Now),
+			systemPrompt: "",
+			logger:       logger,
+			mcp:          mcpExec,
+			mcpServers:   serverMap,
+			mcpFunctions: make(map[string][]MCPFunction),
+			cfg:          cfg,
+			discovery:    discoverer,
+			rewriters:    opts.InputRewriters,
+			workDir:      opts.WorkDir,
+			clipboard:    clipboard,
+		},
+		session: &session{
+			mode:       modeInput,
+			attachment: opts.Attachment,
+		},
+		output:         opts.Output,
+		errOutput:      errOutput,
+		color:          supportsColor(opts.Output),
+		outputTerminal: isTerminal(opts.Output),
+		ownsEngine:     true,
+
+		modelOverride:        strings.TrimSpace(opts.Model),
+		toolModeOverride:     opts.ToolCallMode,
+		thinkingOverride:     opts.ThinkingOutput,
+		skipDestructiveCheck: opts.SkipDestructiveCheck,
+	}
+
+	if cfg.TranscriptLog {
+		app.transcript = newTranscriptLog(transcriptDir(home, cfg.TranscriptDir))
+		app.output = teeWriter{primary: opts.Output, transcript: app.transcript}
+		app.errOutput = teeWriter{primary: errOutput, transcript: app.transcript}
+	}
+
+	app.answerOutput = app.output
+	promptOutput := opts.Output
+	if opts.Quiet {
+		app.quiet = true
+		app.output = io.Discard
+		if app.transcript != nil {
+			app.output = app.transcript
+		}
+		promptOutput = errOutput
+	}
+
+	keys, err := buildKeymap(cfg.Keybindings)
+	if err != nil {
+		return nil, err
+	}
+	if opts.UI != nil {
+		app.ui = opts.UI
+		app.lineReader = opts.UI
+		if notifier, ok := opts.UI.(InterruptNotifier); ok {
+			notifier.SetInterruptHandler(app.handleInterrupt)
+		}
+	} else {
+		app.lineReader = createLineReader(opts.Input, promptOutput, keys, func() {
+			app.handleInterrupt()
+		})
+	}
 
 	app.setupSignals(opts.Interrupts)
 
+	if manager != nil {
+		manager.SetLogHandler(mcpLogLevel(cfg.LogLevel), app.handleMCPLog)
+		manager.Prewarm(context.Background())
+	}
+
 	if err := app.loadMCPFunctions(context.Background()); err != nil {
 		_ = app.mcp.Close()
 		return nil, err
@@ -215,12 +427,44 @@ func New(opts Options) (*App, error) {
 		_ = app.mcp.Close()
 		return nil, err
 	}
+	app.refreshWorkspaceContext()
+	app.speechSink = opts.Speech
+	if cfg.Speech.Enabled && !app.quiet {
+		app.startSpeech()
+	}
 
 	return app, nil
 }
 
+// NewSession returns an App that runs a new, empty conversation on the engine of a:
+// the same configuration, providers, MCP sessions and logger, with its own messages,
+// session file and turn state. Sessions may run turns concurrently. output receives
+// the session's answers and errors; input, when not nil, is read by Run. Closing the
+// returned App leaves the shared engine open.
+func (a *App) NewSession(input io.Reader, output io.Writer) *App {
+	if input == nil {
+		input = strings.NewReader("")
+	}
+	sess := &App{
+		engine:               a.engine,
+		session:              &session{mode: modeInput},
+		output:               output,
+		errOutput:            output,
+		answerOutput:         output,
+		color:                supportsColor(output),
+		outputTerminal:       isTerminal(output),
+		modelOverride:        a.modelOverride,
+		toolModeOverride:     a.toolModeOverride,
+		thinkingOverride:     a.thinkingOverride,
+		skipDestructiveCheck: a.skipDestructiveCheck,
+	}
+	sess.lineReader = newCanonicalLineReader(input, output)
+	sess.refreshWorkspaceContext()
+	return sess
+}
+
 func ensureSystemPrompt(home string, servers []MCPServer, functions map[string][]MCPFunction) (string, error) {
-	path := filepath.Join(home, ".humble-ai-cli", "system_prompt.txt")
+	path := filepath.Join(config.Dir(home), "system_prompt.txt")
 	data, err := os.ReadFile(path)
 	if err == nil {
 		return strings.TrimSpace(string(data)), nil
@@ -257,13 +501,14 @@ func (a *App) loadMCPFunctions(ctx context.Context) error {
 		return nil
 	}
 
-	functions := make(map[string][]MCPFunction, len(a.mcpServers))
+	servers := a.snapshotServers()
+	functions := make(map[string][]MCPFunction, len(servers))
 	a.mcpMu.RLock()
 	for key, funcs := range a.mcpFunctions {
 		functions[key] = cloneMCPFunctions(funcs)
 	}
 	a.mcpMu.RUnlock()
-	for _, srv := range a.mcpServers {
+	for _, srv := range servers {
 		a.logDebug("MCP initialization: loading tools for server=%s", srv.Name)
 		tools, err := a.mcp.Tools(ctx, srv.Name)
 		if err != nil {
@@ -285,9 +530,11 @@ func (a *App) loadMCPFunctions(ctx context.Context) error {
 func (a *App) snapshotServers() []MCPServer {
 	names := a.sortedMCPServerNames()
 	servers := make([]MCPServer, 0, len(names))
+	a.mcpMu.RLock()
 	for _, name := range names {
 		servers = append(servers, a.mcpServers[name])
 	}
+	a.mcpMu.RUnlock()
 	return servers
 }
 
@@ -313,7 +560,8 @@ func buildDefaultSystemPrompt(servers []MCPServer, functions map[string][]MCPFun
 		"   (Deduplicate tool calls to avoid repetition.)\n\n" +
 		"3. **If any tool call returns an error, immediately stop all further tool calls.**\n\n" +
 		"   * Summarize the failure briefly to the user\n" +
-		"   * Ask how they would like to proceed (retry, alternative, provide more info)\n\n" +
+		"   * Ask how they would like to proceed (retry, alternative, provide more info)\n" +
+		"   * Exception: if the tool result says the call may be retried with adjusted arguments, you may call that tool again with corrected arguments\n\n" +
 		"4. **When necessary, call multiple tools and combine their results into a final answer.**\n\n" +
 		"   * Avoid unnecessary tool calls; only call the tools required for the user's request.\n\n" +
 		"5. **When sending a tool call message, NEVER include natural language.**\n" +
@@ -339,6 +587,7 @@ func buildDefaultSystemPrompt(servers []MCPServer, functions map[string][]MCPFun
 		"1. **Stop making any further tool calls**\n" +
 		"2. Return a short summary of the issue\n" +
 		"3. Ask the user how to proceed (e.g., retry, provide different input, try alternative tool)\n\n" +
+		"The only exception is a tool result that explicitly allows retrying the call with adjusted arguments.\n\n" +
 		"Do NOT expose unnecessary internal details, logs, or stack traces\n" +
 		"Provide only concise and relevant information\n\n" +
 		"---\n\n" +
@@ -376,23 +625,17 @@ func (a *App) setupSignals(ch chan os.Signal) {
 
 // Run starts the interactive CLI loop.
 func (a *App) Run(ctx context.Context) error {
-	defer func() {
-		if a.stopSignal != nil {
-			a.stopSignal()
-		}
-	}()
-	defer func() {
-		if err := a.mcp.Close(); err != nil && a.logger != nil {
-			a.logger.Debugf("close MCP sessions: %v", err)
-		}
-	}()
+	defer a.Close()
+	a.interactive = true
+	a.showHint(hintWelcome)
 
 	for {
 		if a.shouldExit() {
 			return nil
 		}
 
-		line, err := a.readLine("humble-ai> ")
+		a.updateStatus()
+		line, err := a.readLine(a.inputPrompt())
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				return nil
@@ -429,11 +672,114 @@ func (a *App) Run(ctx context.Context) error {
 	}
 }
 
+// Ask sends a single user message through the normal turn orchestration without reading input.
+func (a *App) Ask(ctx context.Context, content string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil
+	}
+	return a.handleUserMessage(ctx, content)
+}
+
+// SessionPath returns the history file of the current session, or empty before the first answer.
+func (a *App) SessionPath() string {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	return a.historyPath
+}
+
+// Close releases signal handlers and MCP sessions.
+func (a *App) Close() error {
+	if a.stopSignal != nil {
+		a.stopSignal()
+		a.stopSignal = nil
+	}
+	if a.transcript != nil {
+		if err := a.transcript.close(); err != nil {
+			a.logError("transcript: %v", err)
+		}
+	}
+	if a.speaker != nil {
+		a.speaker.Close()
+		a.speaker = nil
+	}
+	if !a.ownsEngine {
+		return nil
+	}
+	err := a.mcp.Close()
+	if err != nil && a.logger != nil {
+		a.logger.Debugf("close MCP sessions: %v", err)
+	}
+	return err
+}
+
 func (a *App) readLine(prompt string) (string, error) {
 	if a.lineReader == nil {
 		return "", errors.New("line reader not configured")
 	}
-	return a.lineReader.ReadLine(prompt)
+	if pending := a.pendingLine; pending != nil {
+		// A timed-out prompt is still reading; its line answers this prompt instead.
+		a.pendingLine = nil
+		if printer, ok := a.lineReader.(promptPrinter); ok {
+			_ = printer.printPrompt(prompt)
+		}
+		result := <-pending
+		if result.err == nil {
+			a.recordInput(prompt, result.line)
+		}
+		return result.line, result.err
+	}
+	line, err := a.lineReader.ReadLine(prompt)
+	if err == nil {
+		a.recordInput(prompt, line)
+	}
+	return line, err
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// readLineWithin reads a line but gives up after timeout and reports false. Readers that
+// cannot stop a read, such as piped input, keep it running, and it feeds the next readLine.
+func (a *App) readLineWithin(prompt string, timeout time.Duration) (string, bool, error) {
+	if timeout <= 0 || a.lineReader == nil || a.pendingLine != nil {
+		line, err := a.readLine(prompt)
+		return line, true, err
+	}
+	if timed, ok := a.lineReader.(TimedLineReader); ok {
+		line, answered, err := timed.ReadLineWithin(prompt, timeout)
+		if answered && err == nil {
+			a.recordInput(prompt, line)
+		}
+		return line, answered, err
+	}
+	readPrompt := prompt
+	if printer, ok := a.lineReader.(promptPrinter); ok {
+		// Print the prompt here so the reading goroutine does not write while this one does.
+		if err := printer.printPrompt(prompt); err != nil {
+			return "", true, err
+		}
+		readPrompt = ""
+	}
+	results := make(chan lineResult, 1)
+	go func() {
+		line, err := a.lineReader.ReadLine(readPrompt)
+		results <- lineResult{line: line, err: err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case result := <-results:
+		if result.err == nil {
+			a.recordInput(prompt, result.line)
+		}
+		return result.line, true, result.err
+	case <-timer.C:
+		a.pendingLine = results
+		return "", false, nil
+	}
 }
 
 func (a *App) handleCommand(ctx context.Context, line string) (bool, error) {
@@ -456,11 +802,63 @@ func (a *App) handleCommand(ctx context.Context, line string) (bool, error) {
 		return false, a.setToolMode(args)
 	case "/mcp":
 		return false, a.printMCPServers(ctx)
+	case "/mcp-status":
+		return false, a.showMCPStatus(ctx, args)
 	case "/toggle-mcp":
 		return false, a.toggleMCPServer(ctx)
+	case "/call":
+		return false, a.callTool(ctx, strings.TrimSpace(strings.TrimPrefix(line, cmd)))
+	case "/tool-log":
+		return false, a.showToolLog(args)
+	case "/tool-rerun":
+		return false, a.rerunTool(ctx, strings.TrimSpace(strings.TrimPrefix(line, cmd)))
+	case "/persona":
+		return false, a.setPersona(args)
+	case "/tag":
+		return false, a.tagSession(args)
+	case "/note":
+		return false, a.noteSession(strings.TrimSpace(strings.TrimPrefix(line, cmd)))
+	case "/rate":
+		return false, a.rateAnswer(args)
+	case "/history":
+		return false, a.showHistory(args)
+	case "/merge":
+		return false, a.mergeSession(ctx, args)
+	case "/bookmark":
+		return false, a.bookmark(args)
+	case "/goto":
+		return false, a.gotoBookmark(args)
+	case "/discover":
+		return false, a.discoverModels(ctx)
+	case "/redactions":
+		return false, a.printRedactions()
+	case "/export":
+		return false, a.exportSession(args)
+	case "/again":
+		return false, a.askAgain(ctx, args)
+	case "/with":
+		return false, a.askWith(ctx, strings.TrimSpace(strings.TrimPrefix(line, cmd)))
+	case "/translate":
+		return false, a.translateLast(ctx, args)
+	case "/paste":
+		return false, a.pasteClipboard(ctx, args)
+	case "/editor":
+		return false, a.composeInEditor(ctx)
+	case "/show-thinking":
+		a.showThinking()
+	case "/speak":
+		return false, a.toggleSpeech(args)
+	case "/test-model":
+		return false, a.testModel(ctx)
+	case "/hints":
+		return false, a.configureHints(args)
 	case "/exit":
 		return true, nil
 	default:
+		handled, exit, err := a.runAlias(ctx, cmd, strings.TrimSpace(strings.TrimPrefix(line, cmd)))
+		if handled {
+			return exit, err
+		}
 		fmt.Fprintf(a.output, "Unknown command: %s\n", line)
 	}
 	return false, nil
@@ -473,8 +871,33 @@ func (a *App) printHelp() {
 	fmt.Fprintln(a.output, "  /set-model  Select one of the configured models as active.")
 	fmt.Fprintln(a.output, "  /set-tool-mode [auto|manual]  Choose whether MCP tools run automatically.")
 	fmt.Fprintln(a.output, "  /mcp        List enabled MCP servers and their functions.")
+	fmt.Fprintln(a.output, "  /mcp-status [server]  Show each server's version and capabilities, with warnings for missing ones.")
 	fmt.Fprintln(a.output, "  /toggle-mcp Toggle whether an MCP server is enabled.")
+	fmt.Fprintln(a.output, "  /call <server__function> {json args}  Run a tool directly without asking the model.")
+	fmt.Fprintln(a.output, "  /tool-log [n]  List this session's tool calls, or print call n in full.")
+	fmt.Fprintln(a.output, "  /tool-rerun <n> [--inject] [{json args}]  Run call n again, optionally adding the result to the conversation.")
+	fmt.Fprintln(a.output, "  /persona [name|none]  List personas or select the few-shot persona to use.")
+	fmt.Fprintln(a.output, "  /tag [tag...] Show or add session tags (prefix with - to remove).")
+	fmt.Fprintln(a.output, "  /note <text> Attach a note to the current session.")
+	fmt.Fprintln(a.output, "  /rate <1-5> [comment]  Rate the last answer; the rating is saved with the session.")
+	fmt.Fprintln(a.output, "  /history [tag]  List saved sessions (optionally by tag) and resume one.")
+	fmt.Fprintln(a.output, "  /merge <session>  Append a saved session's messages to the current conversation.")
+	fmt.Fprintln(a.output, "  /bookmark [name]  Name the current point of the conversation, or list bookmarks.")
+	fmt.Fprintln(a.output, "  /goto <name>  Rewind the conversation to a bookmark.")
+	fmt.Fprintln(a.output, "  /discover   Find models on local Ollama/LM Studio servers and add them.")
+	fmt.Fprintln(a.output, "  /redactions Review values masked before sending to cloud providers.")
+	fmt.Fprintln(a.output, "  /export html [path]  Save the session as a standalone HTML page.")
+	fmt.Fprintln(a.output, "  /again [model]  Re-ask the last message (optionally on another model) and diff the answers.")
+	fmt.Fprintln(a.output, "  /with \"<instruction>\" <message>  Send a message with a one-off extra instruction.")
+	fmt.Fprintln(a.output, "  /translate <language>  Show the last answer in another language without adding it to the conversation.")
+	fmt.Fprintln(a.output, "  /paste [clear]  Send the clipboard's text with the next message, or drop it.")
+	fmt.Fprintln(a.output, "  /editor     Compose the next message in $VISUAL or $EDITOR and send it when the editor closes.")
+	fmt.Fprintln(a.output, "  /show-thinking  Print the reasoning captured for the last answer.")
+	fmt.Fprintln(a.output, "  /speak [on|off]  Toggle reading answers aloud.")
+	fmt.Fprintln(a.output, "  /test-model  Probe the active model for streaming, JSON and tool call support.")
+	fmt.Fprintln(a.output, "  /hints [on|off|reset]  List the one-time tips, turn them on or off, or show them all again.")
 	fmt.Fprintln(a.output, "  /exit       Exit the application.")
+	a.printAliases()
 }
 
 func (a *App) changeActiveModel(ctx context.Context) error {
@@ -487,30 +910,25 @@ func (a *App) changeActiveModel(ctx context.Context) error {
 		return nil
 	}
 
-	fmt.Fprintln(a.output, "Select a model (0 to cancel):")
+	items := make([]string, len(cfg.Models))
+	active := 0
 	for idx, m := range cfg.Models {
 		activeMarker := ""
 		if m.Active {
 			activeMarker = " *"
+			active = idx
+		}
+		details := m.Provider
+		if capabilities := m.Capabilities(); len(capabilities) > 0 {
+			details += "; " + strings.Join(capabilities, ", ")
 		}
-		fmt.Fprintf(a.output, "  %d) %s (%s)%s\n", idx+1, m.Name, m.Provider, activeMarker)
+		items[idx] = fmt.Sprintf("%s (%s)%s", m.Name, details, activeMarker)
 	}
-	choiceLine, err := a.readLine("Choice: ")
-	if err != nil {
+	choice, err := a.choose("Select a model (0 to cancel):", items, active, "Choice: ")
+	if err != nil || choice < 0 {
 		return err
 	}
 
-	choiceLine = strings.TrimSpace(choiceLine)
-	if choiceLine == "" {
-		return nil
-	}
-
-	choice, err := strconv.Atoi(choiceLine)
-	if err != nil || choice < 0 || choice > len(cfg.Models) {
-		fmt.Fprintln(a.output, "Invalid selection.")
-		return nil
-	}
-
 	if choice == 0 {
 		fmt.Fprintln(a.output, "Model selection cancelled.")
 		return nil
@@ -532,7 +950,7 @@ func (a *App) changeActiveModel(ctx context.Context) error {
 }
 
 func (a *App) configFilePath() string {
-	return filepath.Join(a.homeDir, ".humble-ai-cli", "config.json")
+	return filepath.Join(config.Dir(a.homeDir), "config.json")
 }
 
 func (a *App) setToolMode(args []string) error {
@@ -569,23 +987,60 @@ func (a *App) startNewSession() {
 	a.historyPath = ""
 	a.sessionStart = time.Time{}
 	a.firstUserInput = ""
+	a.sessionTags = nil
+	a.sessionNotes = nil
+	a.bookmarks = nil
+	a.turnErrors = nil
+	a.sessionSystemPrompt = ""
+	a.sessionToolPrompt = ""
 	a.historyMu.Unlock()
 
 	a.messages = nil
+	a.masker = nil
+	a.lastThinking = ""
+	a.toolLog = nil
+	a.pasted = ""
+	if a.transcript != nil {
+		a.transcript.detach()
+	}
+	a.refreshWorkspaceContext()
 
 	fmt.Fprintln(a.output, "Started a new session.")
 }
 
+// resolveModel returns the model this session talks to: the --model or session override
+// when set, the active model otherwise.
+func (a *App) resolveModel(cfg config.Config) (config.Model, bool) {
+	if a.modelOverride != "" {
+		return cfg.FindModel(a.modelOverride)
+	}
+	return cfg.ActiveModel()
+}
+
 func (a *App) handleUserMessage(ctx context.Context, content string) error {
+	return a.sendUserMessage(ctx, content, true)
+}
+
+// sendUserMessage runs one turn; rewrite is false when content already went through
+// the pre-send rewriters, e.g. when /again re-asks a recorded message.
+func (a *App) sendUserMessage(ctx context.Context, content string, rewrite bool) error {
+	a.setOutcome(TurnFailed)
 	a.cfgMu.RLock()
 	cfg := a.cfg
 	a.cfgMu.RUnlock()
 
-	activeModel, ok := cfg.ActiveModel()
+	activeModel, ok := a.resolveModel(cfg)
+	if !ok && a.modelOverride != "" {
+		return fmt.Errorf("model %q is not configured in %s", a.modelOverride, a.configFilePath())
+	}
 	if !ok {
-		fmt.Fprintln(a.output, "No active model is configured. Use /set-model to choose a model.")
+		notice := a.output
+		if a.quiet {
+			notice = a.errOutput
+		}
+		fmt.Fprintln(notice, "No active model is configured. Use /set-model to choose a model.")
 		if len(cfg.Models) == 0 {
-			fmt.Fprintf(a.output, "Add model configuration to %s and try again.\n", a.configFilePath())
+			fmt.Fprintf(notice, "Add model configuration to %s or run /discover and try again.\n", a.configFilePath())
 		}
 		return nil
 	}
@@ -594,188 +1049,496 @@ func (a *App) handleUserMessage(ctx context.Context, content string) error {
 		a.firstUserInput = content
 	}
 
+	if rewrite {
+		rewritten, err := a.rewriteInput(ctx, cfg, content)
+		if err != nil {
+			fmt.Fprintf(a.errOutput, "Message not sent: %v\n", err)
+			a.logError("pre-send hook rejected message: %v", err)
+			return nil
+		}
+		content = rewritten
+	}
+
+	turnStart := a.clock.Now()
+	a.turnToolCalls = nil
+	a.turnAdjustments = nil
+	activeModel = a.probedModel(ctx, cfg, activeModel)
+
 	fmt.Fprintln(a.output, "Waiting for response...")
 
 	provider, err := a.factory.Create(activeModel)
 	if err != nil {
+		a.setOutcome(TurnProviderError)
 		return fmt.Errorf("create provider: %w", err)
 	}
 
-	requestMessages := append([]llm.Message{}, a.messages...)
+	systemPrompt, prelude, err := a.personaPriming(cfg)
+	if err != nil {
+		return err
+	}
+	systemPrompt = a.withLanguageRule(systemPrompt, content, cfg.MatchInputLanguage)
+	systemPrompt = a.withTurnInstruction(systemPrompt)
+
+	requestMessages := a.workspaceContextMessages()
+	requestMessages = append(requestMessages, prelude...)
+	a.turnBudget = budgetForModel(activeModel)
+	requestMessages = append(requestMessages, a.turnBudget.trimHistory(a.historyContext())...)
+	requestMessages = append(requestMessages, a.attachmentMessages()...)
+	pasted := a.chunkAttachment(a.pasted)
+	requestMessages = append(requestMessages, pasted...)
 	requestMessages = append(requestMessages, llm.Message{Role: "user", Content: content})
+	a.turnMasking = redactionActive(cfg, activeModel)
+	a.turnNoVision = !activeModel.VisionEnabled()
+	if a.turnMasking {
+		masked, n, err := a.maskRequestMessages(cfg, requestMessages)
+		if err != nil {
+			return fmt.Errorf("mask request: %w", err)
+		}
+		requestMessages = masked
+		if n > 0 {
+			fmt.Fprintf(a.output, "Masked %d value(s) in your message (use /redactions to review).\n", n)
+		}
+	}
 
-	req := llm.ChatRequest{
-		Model:        activeModel.Name,
-		Messages:     requestMessages,
-		SystemPrompt: a.systemPrompt,
-		Stream:       true,
-		Tools:        a.availableToolDefinitions(),
+	var tools []llm.ToolDefinition
+	if activeModel.ToolsEnabled() {
+		tools = a.availableToolDefinitions()
+	} else {
+		a.logDebug("no tools offered: model %s declares supportsTools: false", activeModel.Name)
+	}
+	expectToolCall := false
+	if activeModel.EffectiveToolStrategy() == config.ToolStrategyChooseFunction && len(tools) > 1 {
+		routed, err := a.chooseFunction(ctx, provider, activeModel.Name, requestMessages[len(requestMessages)-1].Content, tools)
+		switch {
+		case errors.Is(err, context.Canceled):
+			a.setOutcome(TurnCancelled)
+			fmt.Fprintln(a.output, "\nResponse cancelled.")
+			return nil
+		case err != nil:
+			fmt.Fprintf(a.errOutput, "Tool routing failed: %v; offering every tool.\n", err)
+		default:
+			tools = routed
+			expectToolCall = len(routed) > 0
+		}
 	}
+
+	a.turnCitations = cfg.Citations && len(tools) > 0
+	systemPrompt = a.withCitationRule(systemPrompt)
+
+	req := llm.ChatRequest{
+		Model:          activeModel.Name,
+		Messages:       requestMessages,
+		SystemPrompt:   systemPrompt,
+		Stream:         true,
+		Tools:          tools,
+		ExpectToolCall: expectToolCall,
+		TurnID:         newTurnID(),
+	}
+	a.pinSessionPrompts(req)
 	if data, err := json.Marshal(req); err == nil {
 		a.logDebug("LLM request: %s", string(data))
 	} else {
 		a.logError("LLM request marshal error: %v", err)
 	}
+	counter := a.turnBudget.counter
+	promptTokens := estimatePromptTokens(counter, req)
+	a.logDebug("LLM request estimated prompt tokens: %d (tokenizer=%s)", promptTokens, counter.Name())
 
 	reqCtx, cancel := context.WithCancel(ctx)
 	reqCtx = llm.WithLogger(reqCtx, a.logger)
+	if cfg.DebugHTTPDump {
+		if dump := a.openHTTPDump(req.TurnID); dump != nil {
+			defer dump.close()
+			reqCtx = llm.WithHTTPDump(reqCtx, dump)
+		}
+	}
 	a.enterResponding(cancel)
 	defer a.leaveResponding()
+	deadline := startGenerationDeadline(cfg.GenerationLimit(), cancel)
+	defer deadline.stop()
 
-	stream, err := provider.Stream(reqCtx, req)
-	if err != nil {
-		return fmt.Errorf("stream: %w", err)
-	}
-
+	timing := newTurnTiming(a.clock.Now())
+	a.turnTiming = timing
 	var assistant strings.Builder
 	thinking := struct {
 		active         bool
 		needsLineBreak bool
+		started        time.Time
 	}{
 		active:         false,
 		needsLineBreak: false,
 	}
+	var reasoning strings.Builder
+	thinkingOut := a.thinkingOutput(cfg)
 	openThinking := func() {
 		if thinking.active {
 			return
 		}
-		fmt.Fprintln(a.output, "<<< Thinking >>>")
+		if !cfg.CollapseThinking {
+			fmt.Fprintln(thinkingOut, "<<< Thinking >>>")
+		}
 		thinking.active = true
 		thinking.needsLineBreak = false
+		thinking.started = a.clock.Now()
+		if reasoning.Len() > 0 {
+			reasoning.WriteString("\n\n")
+		}
 	}
 	closeThinking := func() {
 		if !thinking.active {
 			return
 		}
 		if thinking.needsLineBreak {
-			fmt.Fprintln(a.output)
+			fmt.Fprintln(thinkingOut)
+		}
+		elapsed := a.clock.Now().Sub(thinking.started)
+		timing.recordThinking(elapsed)
+		if cfg.CollapseThinking {
+			fmt.Fprintf(thinkingOut, "<<< Thinking hidden >>> (thought for %s; /show-thinking to view)\n", formatDuration(elapsed))
+		} else {
+			fmt.Fprintf(thinkingOut, "<<< End Thinking >>> (thought for %s)\n", formatDuration(elapsed))
 		}
-		fmt.Fprintln(a.output, "<<< End Thinking >>>")
 		thinking.active = false
 		thinking.needsLineBreak = false
 	}
+	limit := newOutputCap(cfg, counter)
+	capped := false
 	errored := false
+	var streamErr error
+	// dropped is the network error that broke the current pass, while resumes remain.
+	var dropped error
+	resumes := 0
+	toolFailed := false
 	cancelledByUser := false
+	var routing *llm.RoutingInfo
+	continuations := 0
+	truncated := false
+	streamFailed := func(err error) {
+		closeThinking()
+		if llm.Categorize(err) == llm.ErrorNetwork && resumes < cfg.ResumeStreams && reqCtx.Err() == nil {
+			dropped = err
+			return
+		}
+		a.reportStreamError(cfg, activeModel, err)
+		if streamErr == nil {
+			streamErr = err
+		}
+		errored = true
+	}
+	// On a color terminal, diff blocks in the answer are colored as they stream.
+	var highlighter *diffHighlighter
+	answerOut := a.output
+	if a.color {
+		highlighter = &diffHighlighter{w: a.output}
+		answerOut = highlighter
+	}
 
-loop:
-	for chunk := range stream {
-		if chunk.Err != nil {
-			closeThinking()
-			fmt.Fprintf(a.errOutput, "Stream error: %v\n", chunk.Err)
-			a.logError("LLM stream error: %v", chunk.Err)
-			errored = true
-			continue
+	for {
+		timing.streamStarted()
+		stream, err := provider.Stream(reqCtx, req)
+		if err != nil {
+			a.setOutcome(TurnProviderError)
+			return fmt.Errorf("stream: %w", err)
 		}
 
-		switch chunk.Type {
-		case llm.ChunkThinking:
-			if strings.TrimSpace(chunk.Content) == "" {
+		var pass strings.Builder
+		finishReason := ""
+		order := streamOrder{turnID: req.TurnID}
+	loop:
+		for chunk := range stream {
+			if err := order.check(chunk); err != nil {
+				a.logError("LLM stream out of order: %v", err)
+			}
+			timing.observe(chunk, a.clock.Now())
+			if chunk.Err != nil {
+				streamFailed(chunk.Err)
 				continue
 			}
-			openThinking()
-			if chunk.Content != "" {
-				fmt.Fprint(a.output, chunk.Content)
-				if strings.HasSuffix(chunk.Content, "\n") {
-					thinking.needsLineBreak = false
-				} else {
-					thinking.needsLineBreak = true
+
+			switch chunk.Type {
+			case llm.ChunkThinking:
+				if strings.TrimSpace(chunk.Content) == "" {
+					continue
 				}
+				openThinking()
+				text, within := limit.admit(chunk.Content)
+				reasoning.WriteString(text)
+				if !cfg.CollapseThinking {
+					fmt.Fprint(thinkingOut, text)
+					if strings.HasSuffix(text, "\n") {
+						thinking.needsLineBreak = false
+					} else {
+						thinking.needsLineBreak = true
+					}
+				}
+				if !within {
+					capped = true
+					break loop
+				}
+			case llm.ChunkToken:
+				closeThinking()
+				text, within := limit.admit(chunk.Content)
+				if !a.quiet {
+					fmt.Fprint(answerOut, text)
+				}
+				a.speakText(text)
+				assistant.WriteString(text)
+				pass.WriteString(text)
+				if !within {
+					capped = true
+					break loop
+				}
+			case llm.ChunkToolCall:
+				closeThinking()
+				if chunk.ToolCall == nil {
+					continue
+				}
+				a.flushSpeech()
+				highlighter.Flush()
+				assistant.Reset()
+				pass.Reset()
+				a.logDebug("LLM requested MCP tool: server=%s method=%s", chunk.ToolCall.Server, chunk.ToolCall.Method)
+				deadline.pause()
+				err := a.processToolCall(reqCtx, cancel, chunk.ToolCall)
+				deadline.resume()
+				if err != nil {
+					if errors.Is(err, errToolDeclined) {
+						cancelledByUser = true
+					} else {
+						fmt.Fprintf(a.errOutput, "MCP call failed: %v\n", err)
+						a.logError("MCP call handling failed: %v", err)
+						toolFailed = true
+					}
+					errored = true
+					break loop
+				}
+			case llm.ChunkError:
+				streamFailed(chunk.Err)
+			case llm.ChunkRouting:
+				if chunk.Routing != nil {
+					routing = chunk.Routing
+				}
+			case llm.ChunkRetry:
+				a.logDebug("LLM provider retried the request: %s", chunk.Content)
+			case llm.ChunkDone:
+				closeThinking()
+				finishReason = chunk.FinishReason
 			}
-		case llm.ChunkToken:
-			closeThinking()
-			fmt.Fprint(a.output, chunk.Content)
-			assistant.WriteString(chunk.Content)
-		case llm.ChunkToolCall:
-			closeThinking()
-			if chunk.ToolCall == nil {
-				continue
-			}
-			assistant.Reset()
-			a.logDebug("LLM requested MCP tool: server=%s method=%s", chunk.ToolCall.Server, chunk.ToolCall.Method)
-			if err := a.processToolCall(reqCtx, cancel, chunk.ToolCall); err != nil {
-				if errors.Is(err, errToolDeclined) {
-					cancelledByUser = true
-				} else {
-					fmt.Fprintf(a.errOutput, "MCP call failed: %v\n", err)
-					a.logError("MCP call handling failed: %v", err)
+		}
+
+		closeThinking()
+		if capped {
+			// Stop the provider and let it wind down without a reader.
+			cancel()
+			go func() {
+				for range stream {
 				}
-				errored = true
-				break loop
+			}()
+			break
+		}
+		if dropped != nil && !errored && !cancelledByUser && reqCtx.Err() == nil {
+			resumes++
+			fmt.Fprintf(a.errOutput, "\nConnection lost (%v); resuming the answer (%d/%d).\n", dropped, resumes, cfg.ResumeStreams)
+			a.logDebug("LLM stream dropped: %v; resuming %d/%d", dropped, resumes, cfg.ResumeStreams)
+			dropped = nil
+			if pass.Len() > 0 {
+				req.Messages = append(req.Messages,
+					llm.Message{Role: "assistant", Content: pass.String()},
+					llm.Message{Role: "user", Content: resumePrompt},
+				)
 			}
-		case llm.ChunkError:
-			closeThinking()
-			fmt.Fprintf(a.errOutput, "Stream error: %v\n", chunk.Err)
-			a.logError("LLM stream error chunk: %v", chunk.Err)
-			errored = true
-		case llm.ChunkDone:
-			closeThinking()
-			// finished
+			continue
+		}
+		if errored || cancelledByUser || reqCtx.Err() != nil || finishReason != llm.FinishLength {
+			break
 		}
+		if continuations >= cfg.AutoContinue {
+			truncated = true
+			break
+		}
+		continuations++
+		a.logDebug("LLM response hit the token limit; sending continue follow-up %d/%d", continuations, cfg.AutoContinue)
+		req.Messages = append(req.Messages,
+			llm.Message{Role: "assistant", Content: pass.String()},
+			llm.Message{Role: "user", Content: continuePrompt},
+		)
 	}
 
-	closeThinking()
+	highlighter.Flush()
+	a.lastThinking = strings.TrimSpace(reasoning.String())
+	a.finishSpeech(reqCtx.Err() != nil && !capped)
 
 	if cancelledByUser {
+		a.setOutcome(TurnToolDeclined)
 		a.logDebug("LLM response cancelled by user")
 		return nil
 	}
 
-	if reqCtx.Err() != nil {
+	if deadline.hasExpired() {
+		a.setOutcome(TurnProviderError)
+		fmt.Fprintf(a.errOutput, "\nResponse timed out after %s of generation (generationTimeout).\n", strings.TrimSpace(cfg.GenerationTimeout))
+		a.logDebug("LLM response exceeded generationTimeout %s", cfg.GenerationTimeout)
+		return nil
+	}
+
+	if reqCtx.Err() != nil && !capped {
+		a.setOutcome(TurnCancelled)
 		fmt.Fprintln(a.output, "\nResponse cancelled.")
 		a.logDebug("LLM response context cancelled: %v", reqCtx.Err())
 		return nil
 	}
 
-	if assistant.Len() > 0 {
+	if assistant.Len() > 0 && !a.quiet {
 		fmt.Fprintln(a.output)
 	}
 
 	if errored {
+		if toolFailed {
+			a.setOutcome(TurnToolFailed)
+		} else {
+			a.setOutcome(TurnProviderError)
+			if streamErr != nil {
+				a.recordTurnError(activeModel, streamErr)
+			}
+		}
 		a.logDebug("LLM response aborted due to stream error")
 		return nil
 	}
+	if continuations > 0 {
+		fmt.Fprintf(a.output, "(Answer continued %d time(s) after reaching the token limit.)\n", continuations)
+	}
+	if capped {
+		fmt.Fprintf(a.errOutput, "Answer stopped: it exceeded %s; the saved message is truncated.\n", limit.exceeded)
+		a.logDebug("LLM response stopped at %s", limit.exceeded)
+	}
+	if truncated {
+		fmt.Fprintln(a.errOutput, "Warning: the answer was cut off at the model's token limit.")
+		if cfg.AutoContinue == 0 {
+			fmt.Fprintln(a.errOutput, `Set "autoContinue" in config.json to request the rest automatically.`)
+		}
+	}
+	a.printCitations(assistant.String())
+	if a.quiet && assistant.Len() > 0 {
+		fmt.Fprintln(a.answerOutput, strings.TrimRight(assistant.String(), "\n"))
+	}
 	a.logDebug("LLM response: %s", assistant.String())
+	if routing != nil {
+		a.printRouting(activeModel.Name, *routing)
+	}
+	if cfg.TurnTimings {
+		fmt.Fprintln(a.output, timing.summary(a.clock.Now()))
+	}
+	a.runPostResponseHooks(ctx, cfg, assistant.String())
 
 	now := a.clock.Now()
 
+	metrics := timing.metrics(now)
+	metrics.ProviderRetries += continuations + resumes
+	metrics.PromptTokens = promptTokens
+	metrics.CompletionTokens = counter.Count(assistant.String())
+	metrics.Tokenizer = counter.Name()
+	answer := assistant.String()
+	if capped {
+		answer = strings.TrimRight(answer, " \n") + limit.marker()
+	}
+	reply := history.Message{Role: "assistant", Content: answer, Timestamp: now, ToolCalls: a.turnToolCalls, Metrics: metrics}
+	if cfg.SaveThinking {
+		reply.Thinking = a.lastThinking
+	}
+	// Pasted text stays in the conversation, so later turns can refer to it.
+	for _, msg := range pasted {
+		a.messages = append(a.messages, history.Message{Role: msg.Role, Content: msg.Content, Timestamp: turnStart})
+	}
+	a.pasted = ""
 	a.messages = append(a.messages,
-		llm.Message{Role: "user", Content: content},
-		llm.Message{Role: "assistant", Content: assistant.String()},
+		history.Message{Role: "user", Content: content, Timestamp: turnStart},
+		reply,
 	)
+	a.turnToolCalls = nil
+	if a.interactive && activeModel.ContextWindow > 0 && !cfg.DisableContextGauge {
+		fmt.Fprintln(a.output, a.contextGauge(req.SystemPrompt, activeModel.ContextWindow))
+	}
 
-	if err := a.persistHistory(activeModel.Name, now); err != nil {
+	if err := a.persistHistory(activeModel, cfg.ActivePersona, now); err != nil {
 		fmt.Fprintf(a.errOutput, "Failed to persist history: %v\n", err)
+	} else {
+		a.syncHistory(ctx, cfg.HistorySync)
+		a.showHint(hintHistory)
+	}
+	if len(a.messages) >= 6 {
+		a.showHint(hintBookmark)
 	}
+	a.setOutcome(TurnOK)
 
 	return nil
 }
 
-func (a *App) persistHistory(model string, when time.Time) error {
+// estimatePromptTokens approximates the prompt size of a request, including per-message overhead.
+func estimatePromptTokens(counter tokenizer.Counter, req llm.ChatRequest) int {
+	const perMessageOverhead = 4
+	total := 0
+	if strings.TrimSpace(req.SystemPrompt) != "" {
+		total += counter.Count(req.SystemPrompt) + perMessageOverhead
+	}
+	for _, msg := range req.Messages {
+		total += counter.Count(msg.Content) + perMessageOverhead
+	}
+	return total
+}
+
+// printRouting reports the upstream model a routing provider used for this turn.
+func (a *App) printRouting(requested string, routing llm.RoutingInfo) {
+	summary := "Served by " + routing.Model
+	if routing.Provider != "" {
+		summary += " via " + routing.Provider
+	}
+	if routing.Model != requested {
+		summary += " (requested " + requested + ")"
+	}
+	fmt.Fprintln(a.output, "["+summary+"]")
+}
+
+func (a *App) persistHistory(model config.Model, persona string, when time.Time) error {
 	a.historyMu.Lock()
 	defer a.historyMu.Unlock()
 
 	if a.historyPath == "" {
 		a.sessionStart = when
-		path, err := a.createHistoryFile(model, when)
+		path, err := a.createHistoryFile(model.Name, when)
 		if err != nil {
 			return err
 		}
 		a.historyPath = path
+		a.attachTranscript(path)
 	} else if a.sessionStart.IsZero() {
 		a.sessionStart = when
 	}
 
-	record := map[string]any{
-		"model":     model,
-		"startedAt": a.sessionStart.Format(time.RFC3339),
-		"messages":  a.messages,
-	}
+	return a.sessionStore().Save(a.sessionName(), history.Session{
+		Model:        model.Name,
+		Persona:      persona,
+		Seed:         model.Seed,
+		StartedAt:    a.sessionStart.Truncate(time.Second),
+		Tags:         a.sessionTags,
+		Notes:        a.sessionNotes,
+		Bookmarks:    a.bookmarks,
+		SystemPrompt: a.sessionSystemPrompt,
+		ToolPrompt:   a.sessionToolPrompt,
+		Messages:     a.messages,
+		Errors:       a.turnErrors,
+	})
+}
 
-	data, err := jsonMarshalIndent(record)
-	if err != nil {
-		return err
+// pinSessionPrompts remembers the prompts a request is sent with for the session file.
+func (a *App) pinSessionPrompts(req llm.ChatRequest) {
+	toolPrompt := ""
+	if len(req.Tools) > 0 {
+		toolPrompt = llm.ToolSchemaPrompt(req.Tools)
 	}
-	return os.WriteFile(a.historyPath, data, 0o644)
+	a.historyMu.Lock()
+	a.sessionSystemPrompt = strings.TrimSpace(req.SystemPrompt)
+	a.sessionToolPrompt = toolPrompt
+	a.historyMu.Unlock()
 }
 
 func (a *App) createHistoryFile(model string, when time.Time) (string, error) {
@@ -795,20 +1558,14 @@ func (a *App) createHistoryFile(model string, when time.Time) (string, error) {
 		return path, nil
 	}
 
-	initial := map[string]any{
-		"model":     model,
-		"startedAt": start.Format(time.RFC3339),
-		"messages":  a.messages,
+	initial := history.Session{
+		Model:     model,
+		StartedAt: start.Truncate(time.Second),
+		Messages:  a.messages,
 	}
-
-	data, err := jsonMarshalIndent(initial)
-	if err != nil {
+	if err := history.Save(path, initial); err != nil {
 		return "", err
 	}
-
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return "", fmt.Errorf("write history: %w", err)
-	}
 	return path, nil
 }
 
@@ -822,31 +1579,19 @@ func (a *App) toggleMCPServer(ctx context.Context) error {
 		return nil
 	}
 
-	fmt.Fprintln(a.output, "MCP servers found in mcp-servers.json:")
+	items := make([]string, len(entries))
 	for idx, entry := range entries {
 		status := "disabled"
 		if entry.Enabled {
 			status = "enabled"
 		}
-		fmt.Fprintf(a.output, "  %d) %s: %s\n", idx+1, entry.Name, status)
+		items[idx] = fmt.Sprintf("%s: %s", entry.Name, status)
 	}
 
-	choiceLine, err := a.readLine("Choose the MCP server to enable/disable (0 to cancel): ")
-	if err != nil {
+	choice, err := a.choose("MCP servers found in mcp-servers.json:", items, 0, "Choose the MCP server to enable/disable (0 to cancel): ")
+	if err != nil || choice < 0 {
 		return err
 	}
-
-	choiceLine = strings.TrimSpace(choiceLine)
-	if choiceLine == "" {
-		fmt.Fprintln(a.output, "Toggle cancelled.")
-		return nil
-	}
-
-	choice, err := strconv.Atoi(choiceLine)
-	if err != nil || choice < 0 || choice > len(entries) {
-		fmt.Fprintln(a.output, "Invalid selection.")
-		return nil
-	}
 	if choice == 0 {
 		fmt.Fprintln(a.output, "Toggle cancelled.")
 		return nil
@@ -893,9 +1638,8 @@ func (a *App) applyConfiguredMCPServers(ctx context.Context, entries []MCPConfig
 		}
 	}
 
-	a.mcpServers = updated
-
 	a.mcpMu.Lock()
+	a.mcpServers = updated
 	for name := range a.mcpFunctions {
 		if _, ok := updated[name]; !ok {
 			delete(a.mcpFunctions, name)
@@ -922,6 +1666,13 @@ func (a *App) printMCPServers(ctx context.Context) error {
 		return nil
 	}
 
+	// Functions are marked against the manifest of the previous listing; servers listed for
+	// the first time are not marked.
+	manifest, err := a.loadToolManifest()
+	if err != nil {
+		a.logError("MCP tool manifest: %v", err)
+	}
+
 	fmt.Fprintln(a.output, "Enabled MCP servers:")
 	a.mcpMu.RLock()
 	for _, name := range names {
@@ -932,7 +1683,12 @@ func (a *App) printMCPServers(ctx context.Context) error {
 		sort.Slice(tools, func(i, j int) bool {
 			return tools[i].Name < tools[j].Name
 		})
-		if len(tools) == 0 {
+		var changes toolChanges
+		if previous, ok := manifest.Servers[srv.Name]; ok {
+			changes = diffTools(previous, tools)
+		}
+		manifest.Servers[srv.Name] = manifestEntry(tools)
+		if len(tools) == 0 && len(changes.removed) == 0 {
 			fmt.Fprintln(a.output, "  (no functions reported)")
 			continue
 		}
@@ -942,16 +1698,36 @@ func (a *App) printMCPServers(ctx context.Context) error {
 			if desc == "" {
 				desc = "No description provided."
 			}
-			fmt.Fprintf(a.output, "  - %s: %s\n", tool.Name, desc)
+			marker := ""
+			switch {
+			case changes.added[tool.Name]:
+				marker = " [added]"
+			case changes.changed[tool.Name]:
+				marker = " [schema changed]"
+			}
+			fmt.Fprintf(a.output, "  - %s: %s%s\n", tool.Name, desc, marker)
+		}
+		for _, removed := range changes.removed {
+			fmt.Fprintf(a.output, "  - %s [removed]\n", removed)
+		}
+		if !changes.empty() {
+			fmt.Fprintf(a.output, "  (%d added, %d removed, %d schema changed since the last /mcp)\n",
+				len(changes.added), len(changes.removed), len(changes.changed))
 		}
 	}
 	a.mcpMu.RUnlock()
+	if err == nil {
+		if err := a.saveToolManifest(manifest); err != nil {
+			a.logError("MCP tool manifest: %v", err)
+		}
+	}
 	return nil
 }
 
 func (a *App) availableToolDefinitions() []llm.ToolDefinition {
 	names := a.sortedMCPServerNames()
-	if len(names) == 0 {
+	builtins := a.builtinTools()
+	if len(names) == 0 && len(builtins) == 0 {
 		return nil
 	}
 
@@ -970,14 +1746,7 @@ func (a *App) availableToolDefinitions() []llm.ToolDefinition {
 			if desc == "" {
 				desc = "No description provided."
 			}
-			serverName := strings.TrimSpace(srv.Name)
-			toolName := strings.TrimSpace(fn.Name)
-			namespaced := fn.Name
-			if serverName != "" && toolName != "" {
-				namespaced = fmt.Sprintf("%s__%s", serverName, toolName)
-			} else if toolName != "" {
-				namespaced = toolName
-			}
+			namespaced := llm.ToolName(srv.Name, fn.Name)
 			if serverDesc != "" {
 				desc = fmt.Sprintf("%s — %s", serverDesc, desc)
 			}
@@ -990,6 +1759,15 @@ func (a *App) availableToolDefinitions() []llm.ToolDefinition {
 			})
 		}
 	}
+	for _, tool := range builtins {
+		defs = append(defs, llm.ToolDefinition{
+			Name:        llm.ToolName(builtin.ServerName, tool.Name),
+			Description: "Built-in — " + tool.Description,
+			Server:      builtin.ServerName,
+			Method:      tool.Name,
+			Parameters:  cloneParameters(tool.Parameters),
+		})
+	}
 	return defs
 }
 
@@ -1020,12 +1798,31 @@ func (a *App) processToolCall(ctx context.Context, cancel context.CancelFunc, ca
 	if call == nil {
 		return nil
 	}
+	if a.isBuiltinCall(call) {
+		// Built-in tools are local and side-effect free, so they never need confirmation.
+		fmt.Fprintf(a.output, "\nBuilt-in tool: %s(%s)\n", call.Method, formatBuiltinArguments(call.Arguments))
+		return a.executeToolCall(ctx, call)
+	}
 	a.logDebug("MCP call request received: server=%s method=%s args=%v", call.Server, call.Method, call.Arguments)
 
-	fmt.Fprintln(a.output, "\nMCP tool call")
-	fmt.Fprintf(a.output, "Server: %s\n", call.Server)
-	fmt.Fprintf(a.output, "Tool: %s\n", call.Method)
-	fmt.Fprintln(a.output, "Arguments:")
+	a.cfgMu.RLock()
+	policy := a.cfg.ToolPolicy
+	a.cfgMu.RUnlock()
+	destructive, reason := classifyToolCall(policy, call.Server, call.Method)
+	if a.skipDestructiveCheck {
+		destructive = false
+	}
+	confirm := a.toolCallMode() != config.ToolCallModeAuto || destructive
+
+	// The banner is what the user approves, so it goes wherever the prompt goes.
+	out := a.output
+	if confirm {
+		out = a.confirmOutput()
+	}
+	fmt.Fprintln(out, "\nMCP tool call")
+	fmt.Fprintf(out, "Server: %s\n", call.Server)
+	fmt.Fprintf(out, "Tool: %s\n", call.Method)
+	fmt.Fprintln(out, "Arguments:")
 
 	keys := make([]string, 0, len(call.Arguments))
 	for key := range call.Arguments {
@@ -1033,32 +1830,69 @@ func (a *App) processToolCall(ctx context.Context, cancel context.CancelFunc, ca
 	}
 	sort.Strings(keys)
 	if len(keys) == 0 {
-		fmt.Fprintln(a.output, "  (none)")
+		fmt.Fprintln(out, "  (none)")
 	} else {
 		for _, key := range keys {
-			fmt.Fprintf(a.output, "  %s: %s\n", key, formatToolArgument(call.Arguments[key]))
+			fmt.Fprintf(out, "  %s: %s\n", key, formatToolArgument(call.Arguments[key]))
 		}
 	}
 
-	if a.toolCallMode() == config.ToolCallModeAuto {
+	if destructive {
+		a.printDestructiveWarning(out, reason)
+		a.logDebug("MCP call classified as destructive: server=%s method=%s reason=%s", call.Server, call.Method, reason)
+	}
+
+	if !confirm {
 		return a.executeToolCall(ctx, call)
 	}
 	return a.confirmToolCall(ctx, cancel, call)
 }
 
 func (a *App) toolCallMode() config.ToolCallMode {
+	if a.toolModeOverride != "" {
+		return a.toolModeOverride
+	}
 	a.cfgMu.RLock()
 	defer a.cfgMu.RUnlock()
 	return a.cfg.EffectiveToolCallMode()
 }
 
 func (a *App) executeToolCall(ctx context.Context, call *llm.ToolCall) error {
-	if a.mcp == nil {
+	builtinCall := a.isBuiltinCall(call)
+	if a.mcp == nil && !builtinCall {
 		return errors.New("mcp executor not configured")
 	}
 
 	a.logDebug("MCP call start: server=%s method=%s args=%v", call.Server, call.Method, call.Arguments)
-	result, err := a.mcp.Call(ctx, call.Server, call.Method, call.Arguments)
+	started := a.clock.Now()
+	var (
+		result  llm.ToolResult
+		err     error
+		hint    string
+		retries int
+	)
+	if builtinCall {
+		result, err = a.builtins.Call(call.Method, call.Arguments)
+	} else {
+		a.cfgMu.RLock()
+		retry, hasRetry := a.cfg.ToolPolicy.FindRetry(call.Server, call.Method)
+		a.cfgMu.RUnlock()
+		result, retries, err = a.callMCPWithRetry(ctx, call, retry)
+		if hasRetry && call.Respond != nil && (err != nil || result.IsError) && ctx.Err() == nil {
+			if left, ok := a.allowArgumentAdjustment(call, retry); ok {
+				if err != nil {
+					result, err = llm.ToolResult{Content: err.Error(), IsError: true}, nil
+				}
+				hint = adjustArgumentsHint(call, left)
+				fmt.Fprintln(a.output, "Tool call failed; the model may retry it with adjusted arguments.")
+			}
+		}
+	}
+	elapsed := a.clock.Now().Sub(started)
+	if a.turnTiming != nil {
+		a.turnTiming.recordTool(call.Server, call.Method, elapsed, retries, err != nil || result.IsError)
+	}
+	a.logToolCall(call, started, elapsed, retries, result, err)
 	if err != nil {
 		if call.Respond != nil {
 			_ = call.Respond(ctx, llm.ToolResult{Content: err.Error(), IsError: true})
@@ -1068,25 +1902,88 @@ func (a *App) executeToolCall(ctx context.Context, call *llm.ToolCall) error {
 	}
 
 	if call.Respond != nil {
-		if err := call.Respond(ctx, result); err != nil && !errors.Is(err, context.Canceled) {
+		sent := result
+		content, parts := a.turnBudget.splitToolResult(a.withholdImages(result))
+		if parts > 1 {
+			fmt.Fprintf(a.output, "Tool result is long; sending it in %d parts.\n", parts)
+		}
+		sent.Content = a.citationLabel() + a.scanToolResult(call, a.maskToolResult(content)) + hint
+		if err := call.Respond(ctx, sent); err != nil && !errors.Is(err, context.Canceled) {
 			return fmt.Errorf("deliver MCP result: %w", err)
 		}
 	}
 
+	a.turnToolCalls = append(a.turnToolCalls, history.ToolCall{
+		Server:    call.Server,
+		Method:    call.Method,
+		Arguments: cloneParameters(call.Arguments),
+		Result:    result.Content,
+		IsError:   result.IsError,
+	})
 	a.logDebug("MCP call success: server=%s method=%s result=%s", call.Server, call.Method, strings.TrimSpace(result.Content))
+	if builtinCall {
+		fmt.Fprintf(a.output, "Result: %s\n", truncateRunes(strings.TrimSpace(result.Content), maxToolPreviewLineWidth))
+		return nil
+	}
 	fmt.Fprintln(a.output, "MCP call completed.")
+	for _, path := range result.Assets {
+		fmt.Fprintf(a.output, "Saved tool output to %s\n", path)
+	}
+	a.printToolResultPreview(result.Content)
 	return nil
 }
 
+// confirmOutput is where a tool confirmation and what it asks about are written: the
+// prompt writer, which is stderr in quiet mode.
+func (a *App) confirmOutput() io.Writer {
+	if a.quiet {
+		return a.errOutput
+	}
+	return a.output
+}
+
 func (a *App) confirmToolCall(ctx context.Context, cancel context.CancelFunc, call *llm.ToolCall) error {
-	for {
-		answer, err := a.readLine("Call now? (Y/N): ")
-		if err != nil {
-			return err
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	timeout := cfg.ConfirmTimeout()
+	started := a.clock.Now()
+	for asked := false; ; asked = true {
+		// A repeated prompt shows how long the call has waited and how long is left.
+		prompt := "Call now? (Y/N): "
+		remaining := timeout
+		waited := a.clock.Now().Sub(started)
+		if timeout > 0 {
+			remaining = timeout - waited
+			if !asked {
+				prompt = fmt.Sprintf("Call now? (Y/N, auto-decline in %s): ", strings.TrimSpace(cfg.ToolConfirmTimeout))
+			} else {
+				prompt = fmt.Sprintf("Call now? (Y/N, waited %s, auto-decline in %s): ", formatDuration(waited), formatDuration(max(remaining, 0)))
+			}
+		}
+		answered := false
+		var answer string
+		if timeout <= 0 || remaining > 0 {
+			var err error
+			if answer, answered, err = a.readLineWithin(prompt, remaining); err != nil {
+				return err
+			}
+		}
+		if !answered {
+			// The read gave up after the remaining time, even if the clock has not moved.
+			waited = max(a.clock.Now().Sub(started), waited+max(remaining, 0))
+			if call.Respond != nil {
+				_ = call.Respond(ctx, llm.ToolResult{Content: "MCP call declined: no confirmation within " + formatDuration(waited), IsError: true})
+			}
+			cancel()
+			a.logDebug("MCP call confirmation timed out after %s: server=%s method=%s", formatDuration(waited), call.Server, call.Method)
+			fmt.Fprintf(a.confirmOutput(), "\nNo answer after %s; MCP call declined.\n", formatDuration(waited))
+			return errToolDeclined
 		}
 
 		switch strings.ToLower(strings.TrimSpace(answer)) {
 		case "y", "yes":
+			a.showHint(hintToolMode)
 			return a.executeToolCall(ctx, call)
 		case "n", "no":
 			if call.Respond != nil {
@@ -1094,10 +1991,10 @@ func (a *App) confirmToolCall(ctx context.Context, cancel context.CancelFunc, ca
 			}
 			cancel()
 			a.logDebug("MCP call cancelled by user: server=%s method=%s", call.Server, call.Method)
-			fmt.Fprintln(a.output, "MCP call cancelled by user.")
+			fmt.Fprintln(a.confirmOutput(), "MCP call cancelled by user.")
 			return errToolDeclined
 		default:
-			fmt.Fprintln(a.output, "Please answer with Y or N.")
+			fmt.Fprintln(a.confirmOutput(), "Please answer with Y or N.")
 		}
 	}
 }
@@ -1182,23 +2079,9 @@ func sanitizeTitle(input string) string {
 	return title
 }
 
-func jsonMarshalIndent(v any) ([]byte, error) {
-	data, err := jsonMarshal(v)
-	if err != nil {
-		return nil, err
-	}
-	return append(data, '\n'), nil
-}
-
-func jsonMarshal(v any) ([]byte, error) {
-	data, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("marshal history: %w", err)
-	}
-	return data, nil
-}
-
 func (a *App) sortedMCPServerNames() []string {
+	a.mcpMu.RLock()
+	defer a.mcpMu.RUnlock()
 	if len(a.mcpServers) == 0 {
 		return nil
 	}
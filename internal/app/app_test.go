@@ -15,6 +15,7 @@ import (
 
 	"github.com/gamzabox/humble-ai-cli/internal/app"
 	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
 	"github.com/gamzabox/humble-ai-cli/internal/llm"
 )
 
@@ -1040,6 +1041,25 @@ func TestAppHandlesMCPToolRequests(t *testing.T) {
 	if !strings.Contains(got, "Final answer: 5") {
 		t.Fatalf("expected final answer to be printed, got:\n%s", got)
 	}
+
+	historyFiles, err := filepath.Glob(filepath.Join(home, ".humble-ai-cli", "sessions", "*.json"))
+	if err != nil || len(historyFiles) != 1 {
+		t.Fatalf("expected 1 history file, got %d (err=%v)", len(historyFiles), err)
+	}
+	session, err := history.Load(historyFiles[0])
+	if err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	if len(session.Messages) != 2 {
+		t.Fatalf("expected 2 history messages, got %d", len(session.Messages))
+	}
+	recorded := session.Messages[1].ToolCalls
+	if len(recorded) != 1 || recorded[0].Server != "calculator" || recorded[0].Result != "5" {
+		t.Fatalf("expected tool call to be recorded in history, got %#v", recorded)
+	}
+	if session.Messages[0].Timestamp.IsZero() {
+		t.Fatalf("expected user message timestamp to be recorded")
+	}
 }
 
 func TestAppMCPCommandPrintsEnabledServers(t *testing.T) {
@@ -1298,3 +1318,35 @@ func (c fixedClock) Now() time.Time {
 }
 
 var _ app.Clock = fixedClock(time.Time{})
+
+func TestAppTagsEachTurnWithItsOwnTurnID(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hi"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		HistoryRootDir: filepath.Join(home, "sessions"),
+		HomeDir:        home,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+	for _, message := range []string{"one", "two"} {
+		if err := instance.Ask(context.Background(), message); err != nil {
+			t.Fatalf("Ask() error = %v", err)
+		}
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 2 || requests[0].TurnID == "" || requests[0].TurnID == requests[1].TurnID {
+		t.Fatalf("expected a distinct turn ID per turn, got %+v", requests)
+	}
+}
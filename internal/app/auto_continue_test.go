@@ -0,0 +1,135 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// passProvider answers each request with the next scripted pass.
+type passProvider struct {
+	mu       sync.Mutex
+	passes   []llm.StreamChunk
+	requests []llm.ChatRequest
+}
+
+func (p *passProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+	done := p.passes[0]
+	if len(p.passes) > 1 {
+		p.passes = p.passes[1:]
+	}
+	out := make(chan llm.StreamChunk, 2)
+	out <- llm.StreamChunk{Type: llm.ChunkToken, Content: done.Content}
+	out <- llm.StreamChunk{Type: llm.ChunkDone, FinishReason: done.FinishReason}
+	close(out)
+	return out, nil
+}
+
+func runTruncatedTurn(t *testing.T, autoContinue int, passes ...llm.StreamChunk) (string, *passProvider, history.Session) {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		AutoContinue: autoContinue,
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &passProvider{passes: passes}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	if err := instance.Ask(context.Background(), "write a long story"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	return output.String(), provider, session
+}
+
+func TestAppAutoContinueStitchesTruncatedAnswer(t *testing.T) {
+	output, provider, session := runTruncatedTurn(t, 3,
+		llm.StreamChunk{Content: "Once upon ", FinishReason: llm.FinishLength},
+		llm.StreamChunk{Content: "a time.", FinishReason: "stop"},
+	)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected one continue follow-up, got %d requests", len(provider.requests))
+	}
+	followUp := provider.requests[1].Messages
+	if len(followUp) < 3 {
+		t.Fatalf("unexpected follow-up messages: %+v", followUp)
+	}
+	partial, prompt := followUp[len(followUp)-2], followUp[len(followUp)-1]
+	if partial.Role != "assistant" || partial.Content != "Once upon " {
+		t.Fatalf("expected partial answer before the continue prompt, got %+v", partial)
+	}
+	if prompt.Role != "user" || !strings.Contains(prompt.Content, "Continue") {
+		t.Fatalf("expected continue prompt, got %+v", prompt)
+	}
+
+	if len(session.Messages) != 2 || session.Messages[1].Content != "Once upon a time." {
+		t.Fatalf("expected stitched answer in history, got %+v", session.Messages)
+	}
+	if !strings.Contains(output, "(Answer continued 1 time(s) after reaching the token limit.)") {
+		t.Fatalf("expected continuation notice, got:\n%s", output)
+	}
+}
+
+func TestAppAutoContinueStopsAtLimit(t *testing.T) {
+	output, provider, session := runTruncatedTurn(t, 1,
+		llm.StreamChunk{Content: "one ", FinishReason: llm.FinishLength},
+		llm.StreamChunk{Content: "two ", FinishReason: llm.FinishLength},
+	)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected requests to stop at autoContinue, got %d", len(provider.requests))
+	}
+	if session.Messages[1].Content != "one two " {
+		t.Fatalf("unexpected stitched answer %q", session.Messages[1].Content)
+	}
+	if !strings.Contains(output, "Warning: the answer was cut off at the model's token limit.") {
+		t.Fatalf("expected truncation warning, got:\n%s", output)
+	}
+}
+
+func TestAppWarnsAboutTruncationWithoutAutoContinue(t *testing.T) {
+	output, provider, _ := runTruncatedTurn(t, 0,
+		llm.StreamChunk{Content: "cut", FinishReason: llm.FinishLength},
+	)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("expected no follow-up, got %d requests", len(provider.requests))
+	}
+	if !strings.Contains(output, `Set "autoContinue" in config.json`) {
+		t.Fatalf("expected autoContinue hint, got:\n%s", output)
+	}
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+// bookmark names the current end of the conversation, or lists bookmarks: /bookmark [name].
+// Reusing a name moves the bookmark.
+func (a *App) bookmark(args []string) error {
+	if len(args) > 1 {
+		fmt.Fprintln(a.output, "Usage: /bookmark [name]")
+		return nil
+	}
+
+	a.historyMu.Lock()
+	if len(args) == 0 {
+		marks := append([]history.Bookmark(nil), a.bookmarks...)
+		a.historyMu.Unlock()
+		if len(marks) == 0 {
+			fmt.Fprintln(a.output, "This session has no bookmarks.")
+			fmt.Fprintln(a.output, "Usage: /bookmark <name> (then /goto <name> to rewind)")
+			return nil
+		}
+		fmt.Fprintln(a.output, "Bookmarks:")
+		for _, mark := range marks {
+			fmt.Fprintf(a.output, "  %s (after %d message(s))\n", mark.Name, mark.Position)
+		}
+		return nil
+	}
+
+	name := args[0]
+	position := len(a.messages)
+	a.bookmarks = removeBookmark(a.bookmarks, name)
+	a.bookmarks = append(a.bookmarks, history.Bookmark{Name: name, Position: position})
+	err := a.saveSessionMetadataLocked()
+	a.historyMu.Unlock()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.output, "Bookmarked %q after %d message(s).\n", name, position)
+	return nil
+}
+
+// gotoBookmark rewinds the conversation to a bookmark after confirmation, dropping the
+// later messages from the context and the session file: /goto <name>.
+func (a *App) gotoBookmark(args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintln(a.output, "Usage: /goto <name>")
+		return nil
+	}
+	name := args[0]
+
+	a.historyMu.Lock()
+	mark, ok := findBookmark(a.bookmarks, name)
+	a.historyMu.Unlock()
+	if !ok {
+		fmt.Fprintf(a.output, "No bookmark named %q; use /bookmark to list them.\n", name)
+		return nil
+	}
+	position := min(mark.Position, len(a.messages))
+	dropped := len(a.messages) - position
+	if dropped == 0 {
+		fmt.Fprintf(a.output, "Already at bookmark %q.\n", name)
+		return nil
+	}
+
+	answer, err := a.readLine(fmt.Sprintf("Drop the %d message(s) after bookmark %q? (Y/N): ", dropped, name))
+	if err != nil {
+		return err
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		fmt.Fprintln(a.output, "Rewind cancelled.")
+		return nil
+	}
+
+	a.messages = a.messages[:position:position]
+	a.lastThinking = ""
+
+	a.historyMu.Lock()
+	kept := a.bookmarks[:0]
+	for _, b := range a.bookmarks {
+		if b.Position <= position {
+			kept = append(kept, b)
+		}
+	}
+	a.bookmarks = kept
+	err = a.saveRewoundSessionLocked()
+	a.historyMu.Unlock()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.output, "Rewound to bookmark %q; dropped %d message(s).\n", name, dropped)
+	return nil
+}
+
+// saveRewoundSessionLocked rewrites the messages and bookmarks of an already persisted session.
+func (a *App) saveRewoundSessionLocked() error {
+	if a.historyPath == "" {
+		return nil
+	}
+	session, err := a.sessionStore().Load(a.sessionName())
+	if err != nil {
+		return err
+	}
+	session.Messages = a.messages
+	session.Bookmarks = append([]history.Bookmark(nil), a.bookmarks...)
+	return a.sessionStore().Save(a.sessionName(), session)
+}
+
+func findBookmark(marks []history.Bookmark, name string) (history.Bookmark, bool) {
+	for _, mark := range marks {
+		if mark.Name == name {
+			return mark, true
+		}
+	}
+	return history.Bookmark{}, false
+}
+
+func removeBookmark(marks []history.Bookmark, name string) []history.Bookmark {
+	out := marks[:0]
+	for _, mark := range marks {
+		if mark.Name != name {
+			out = append(out, mark)
+		}
+	}
+	return out
+}
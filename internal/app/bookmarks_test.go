@@ -0,0 +1,94 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppGotoRewindsToBookmark(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("model-a", provider)
+
+	input := strings.Join([]string{
+		"first",
+		"/bookmark clean",
+		"bad tangent",
+		"/bookmark tangent",
+		"/goto missing",
+		"/goto clean",
+		"n",
+		"/goto clean",
+		"y",
+		"second",
+		"/bookmark",
+		"/exit",
+	}, "\n") + "\n"
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	for _, want := range []string{
+		`Bookmarked "clean" after 2 message(s).`,
+		`No bookmark named "missing"; use /bookmark to list them.`,
+		"Rewind cancelled.",
+		`Rewound to bookmark "clean"; dropped 2 message(s).`,
+		"Bookmarks:\n  clean (after 2 message(s))\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q, got:\n%s", want, got)
+		}
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("expected three requests, got %d", len(requests))
+	}
+	var contents []string
+	for _, msg := range requests[2].Messages {
+		contents = append(contents, msg.Content)
+	}
+	if strings.Join(contents, "|") != "first|ok|second" {
+		t.Fatalf("expected the tangent to be dropped from context, got %q", contents)
+	}
+
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if len(session.Messages) != 4 || session.Messages[2].Content != "second" {
+		t.Fatalf("expected the rewound session on disk, got %+v", session.Messages)
+	}
+	if len(session.Bookmarks) != 1 || session.Bookmarks[0] != (history.Bookmark{Name: "clean", Position: 2}) {
+		t.Fatalf("expected only the kept bookmark, got %+v", session.Bookmarks)
+	}
+}
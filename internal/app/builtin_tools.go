@@ -0,0 +1,51 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/builtin"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// builtinTools returns the built-in tools offered to the model. They are hidden when
+// disabled in config or when an MCP server already uses the "builtin" name.
+func (a *App) builtinTools() []builtin.Tool {
+	if a.builtins == nil {
+		return nil
+	}
+	a.cfgMu.RLock()
+	disabled := a.cfg.DisableBuiltinTools
+	a.cfgMu.RUnlock()
+	if disabled {
+		return nil
+	}
+	a.mcpMu.RLock()
+	_, shadowed := a.mcpServers[builtin.ServerName]
+	a.mcpMu.RUnlock()
+	if shadowed {
+		return nil
+	}
+	return a.builtins.Tools()
+}
+
+func (a *App) isBuiltinCall(call *llm.ToolCall) bool {
+	if call == nil || call.Server != builtin.ServerName {
+		return false
+	}
+	return len(a.builtinTools()) > 0
+}
+
+// formatBuiltinArguments renders arguments inline as key=value pairs.
+func formatBuiltinArguments(args map[string]any) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+formatToolArgument(args[key]))
+	}
+	return strings.Join(parts, ", ")
+}
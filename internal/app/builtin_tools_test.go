@@ -0,0 +1,104 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppRunsBuiltinToolsWithoutConfirmation(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		ToolCallMode: "manual",
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	var result llm.ToolResult
+	provider := &toolRequestProvider{
+		call: llm.ToolCall{Server: "builtin", Method: "calculate", Arguments: map[string]any{"expression": "(3 + 4) * 2"}},
+		after: []llm.StreamChunk{
+			{Type: llm.ChunkToken, Content: "It is 14."},
+			{Type: llm.ChunkDone},
+		},
+		onResponded: func(res llm.ToolResult) { result = res },
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	mcp := &stubMCP{}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("what is (3+4)*2?\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcp,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if result.Content != "14" || result.IsError {
+		t.Fatalf("unexpected builtin result: %+v", result)
+	}
+	if len(mcp.Calls()) != 0 {
+		t.Fatalf("built-in tool must not go through MCP, got %v", mcp.Calls())
+	}
+	got := output.String()
+	if !strings.Contains(got, "Built-in tool: calculate(expression=(3 + 4) * 2)") || strings.Contains(got, "Call now?") {
+		t.Fatalf("expected built-in call without confirmation, got:\n%s", got)
+	}
+
+	reqs := provider.requests
+	found := false
+	for _, def := range reqs[0].Tools {
+		if def.Name == "builtin__calculate" && def.Server == "builtin" && def.Method == "calculate" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected builtin__calculate among tools, got %+v", reqs[0].Tools)
+	}
+}
+
+func TestAppHidesBuiltinToolsWhenDisabled(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		DisableBuiltinTools: true,
+		Models:              []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("hi\n/exit\n"),
+		Output:         &bytes.Buffer{},
+		ErrorOutput:    &bytes.Buffer{},
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if tools := provider.Requests()[0].Tools; len(tools) != 0 {
+		t.Fatalf("expected no tools when built-ins are disabled, got %+v", tools)
+	}
+}
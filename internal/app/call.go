@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// callTool runs a tool directly without asking the model: /call <server__function> {json args}.
+// The call and its result are recorded in the conversation like a tool call the model made,
+// so later turns can build on it.
+func (a *App) callTool(ctx context.Context, input string) error {
+	input = strings.TrimSpace(input)
+	name, argText, _ := strings.Cut(input, " ")
+	if name == "" {
+		fmt.Fprintln(a.output, "Usage: /call <server__function> {json args}")
+		return nil
+	}
+	def, ok := a.findToolDefinition(name)
+	if !ok {
+		fmt.Fprintf(a.output, "Unknown tool %s; /mcp lists the available tools.\n", name)
+		return nil
+	}
+	var args map[string]any
+	if argText = strings.TrimSpace(argText); argText != "" {
+		if err := json.Unmarshal([]byte(argText), &args); err != nil {
+			fmt.Fprintf(a.output, "Invalid arguments for %s: expected a JSON object (%v).\n", name, err)
+			return nil
+		}
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	return a.runToolDirectly(ctx, def, args, "/call "+input, true)
+}
+
+// runToolDirectly runs a tool without the model. With record, the call is added to the
+// conversation as command followed by an assistant message carrying the call and its
+// result, so later turns can build on it.
+func (a *App) runToolDirectly(ctx context.Context, def llm.ToolDefinition, args map[string]any, command string, record bool) error {
+	name := def.Name
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a.enterResponding(cancel)
+	defer a.leaveResponding()
+
+	started := a.clock.Now()
+	a.turnToolCalls = nil
+	call := &llm.ToolCall{Server: def.Server, Method: def.Method, Description: def.Description, Arguments: args}
+	fmt.Fprintf(a.output, "Calling %s...\n", name)
+	if err := a.executeToolCall(reqCtx, call); err != nil {
+		fmt.Fprintf(a.errOutput, "Tool call failed: %v\n", err)
+		a.turnToolCalls = append(a.turnToolCalls, history.ToolCall{
+			Server:    def.Server,
+			Method:    def.Method,
+			Arguments: cloneParameters(args),
+			Result:    err.Error(),
+			IsError:   true,
+		})
+	}
+	calls := a.turnToolCalls
+	a.turnToolCalls = nil
+	if !record {
+		return nil
+	}
+
+	now := a.clock.Now()
+	verb, _, _ := strings.Cut(command, " ")
+	a.messages = append(a.messages,
+		history.Message{Role: "user", Content: command, Timestamp: started},
+		history.Message{Role: "assistant", Content: "Called " + name + " directly with " + verb + ".", Timestamp: now, ToolCalls: calls},
+	)
+	a.historyMu.Lock()
+	if a.firstUserInput == "" {
+		a.firstUserInput = name
+	}
+	a.historyMu.Unlock()
+
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	if activeModel, ok := a.resolveModel(cfg); ok {
+		if err := a.persistHistory(activeModel, cfg.ActivePersona, now); err != nil {
+			fmt.Fprintf(a.errOutput, "Failed to persist history: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// findToolDefinition looks up an enabled tool by its namespaced server__function name.
+func (a *App) findToolDefinition(name string) (llm.ToolDefinition, bool) {
+	for _, def := range a.availableToolDefinitions() {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return llm.ToolDefinition{}, false
+}
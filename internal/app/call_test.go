@@ -0,0 +1,138 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func runCallSession(t *testing.T, mcp app.MCPExecutor, input string) (string, *app.App, *recordingProvider) {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcp,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return output.String(), instance, provider
+}
+
+func TestAppCallRunsToolDirectlyAndRecordsIt(t *testing.T) {
+	mcp := &stubMCP{
+		servers:  []app.MCPServer{{Name: "docs"}},
+		toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		response: llm.ToolResult{Content: "chapter one"},
+	}
+	output, instance, provider := runCallSession(t, mcp, "/call docs__read {\"path\": \"a.md\"}\nsummarize it\n/exit\n")
+
+	calls := mcp.Calls()
+	if len(calls) != 1 || calls[0].Server != "docs" || calls[0].Method != "read" || calls[0].Arguments["path"] != "a.md" {
+		t.Fatalf("expected a direct docs.read call, got %+v", calls)
+	}
+	if strings.Contains(output, "Call now?") {
+		t.Fatalf("expected /call to skip confirmation, got:\n%s", output)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected only the follow-up message to reach the model, got %d requests", len(requests))
+	}
+	found := false
+	for _, msg := range requests[0].Messages {
+		if msg.Role == "tool" && msg.Content == "chapter one" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected the tool result in the follow-up context, got %+v", requests[0].Messages)
+	}
+
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if len(session.Messages) != 4 {
+		t.Fatalf("expected 4 messages in session, got %d", len(session.Messages))
+	}
+	if got := session.Messages[0].Content; got != "/call docs__read {\"path\": \"a.md\"}" {
+		t.Fatalf("unexpected recorded command %q", got)
+	}
+	recorded := session.Messages[1].ToolCalls
+	if len(recorded) != 1 || recorded[0].Result != "chapter one" || recorded[0].IsError {
+		t.Fatalf("expected recorded tool call, got %+v", recorded)
+	}
+}
+
+func TestAppCallRecordsFailures(t *testing.T) {
+	mcp := &stubMCP{
+		servers:       []app.MCPServer{{Name: "docs"}},
+		toolset:       map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		responseError: errors.New("server offline"),
+	}
+	output, instance, _ := runCallSession(t, mcp, "/call docs__read\n/exit\n")
+
+	if !strings.Contains(output, "Tool call failed: server offline") {
+		t.Fatalf("expected failure notice, got:\n%s", output)
+	}
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	recorded := session.Messages[1].ToolCalls
+	if len(recorded) != 1 || !recorded[0].IsError || recorded[0].Result != "server offline" {
+		t.Fatalf("expected recorded failed call, got %+v", recorded)
+	}
+}
+
+func TestAppCallRejectsUnknownToolsAndBadArguments(t *testing.T) {
+	mcp := &stubMCP{
+		servers: []app.MCPServer{{Name: "docs"}},
+		toolset: map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+	}
+	output, instance, _ := runCallSession(t, mcp, "/call\n/call docs__write {}\n/call docs__read [1]\n/exit\n")
+
+	for _, phrase := range []string{
+		"Usage: /call <server__function> {json args}",
+		"Unknown tool docs__write; /mcp lists the available tools.",
+		"Invalid arguments for docs__read: expected a JSON object",
+	} {
+		if !strings.Contains(output, phrase) {
+			t.Fatalf("expected %q in output, got:\n%s", phrase, output)
+		}
+	}
+	if len(mcp.Calls()) != 0 {
+		t.Fatalf("expected no tool calls, got %+v", mcp.Calls())
+	}
+	if instance.SessionPath() != "" {
+		t.Fatalf("expected no session file for rejected calls")
+	}
+}
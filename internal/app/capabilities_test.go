@@ -0,0 +1,58 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppOffersNoToolsToModelsWithoutToolSupport(t *testing.T) {
+	home := t.TempDir()
+	no, yes := false, true
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{
+			{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true, SupportsTools: &no},
+			{Name: "vision-model", Provider: "openai", APIKey: "sk", SupportsVision: &yes, SupportsReasoning: &yes},
+		},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "plain answer"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/set-model\n0\nread the guide\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            docsMCP(nil),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(requests))
+	}
+	if len(requests[0].Tools) != 0 {
+		t.Fatalf("expected no tools for supportsTools: false, got %+v", requests[0].Tools)
+	}
+	for _, want := range []string{"1) stub-model (openai; no tools) *", "2) vision-model (openai; vision, reasoning)"} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("expected %q in the model list, got:\n%s", want, output.String())
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+const citationInstruction = "Each tool result starts with a citation marker such as [ref:toolcall-1]. " +
+	"When a statement in your answer relies on a tool result, put that result's marker right after the statement. " +
+	"Only use markers you were given."
+
+// withCitationRule asks the model to cite tool results when citations are on for the turn.
+func (a *App) withCitationRule(systemPrompt string) string {
+	if !a.turnCitations {
+		return systemPrompt
+	}
+	if systemPrompt == "" {
+		return citationInstruction
+	}
+	return systemPrompt + "\n\n" + citationInstruction
+}
+
+// citationLabel heads the result of the tool call about to be recorded with its marker.
+func (a *App) citationLabel() string {
+	if !a.turnCitations {
+		return ""
+	}
+	return fmt.Sprintf(render.CitationMarker, len(a.turnToolCalls)+1) + "\n"
+}
+
+// printCitations lists the tool calls the answer cited, numbered as in its markers.
+func (a *App) printCitations(answer string) {
+	if !a.turnCitations {
+		return
+	}
+	_, citations := render.ResolveCitations(answer, a.turnToolCalls)
+	if len(citations) == 0 {
+		return
+	}
+	fmt.Fprintln(a.output, "Sources:")
+	for _, citation := range citations {
+		fmt.Fprintf(a.output, "  [%d] %s\n", citation.Number, render.ToolCallSummary(citation.Call))
+	}
+}
@@ -0,0 +1,68 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppCitationsLabelToolResultsAndListSources(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		ToolCallMode: "auto",
+		Citations:    true,
+	}}
+	var sent llm.ToolResult
+	provider := docsToolProvider()
+	provider.after = []llm.StreamChunk{{Type: llm.ChunkToken, Content: "The file says ok [ref:toolcall-1]."}}
+	provider.onResponded = func(result llm.ToolResult) { sent = result }
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            docsMCP(nil),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Ask(context.Background(), "what does the file say?"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	_ = instance.Close()
+
+	reqs := provider.Requests()
+	if len(reqs) == 0 || !strings.Contains(reqs[0].SystemPrompt, "[ref:toolcall-1]") {
+		t.Fatalf("expected the citation rule in the system prompt, got %+v", reqs)
+	}
+	if sent.Content != "[ref:toolcall-1]\nok" {
+		t.Fatalf("expected the tool result to carry its marker, got %q", sent.Content)
+	}
+	if !strings.Contains(output.String(), "Sources:\n  [1] docs.read(path=\"/tmp/x\") → ok\n") {
+		t.Fatalf("expected the cited tool call listed, got:\n%s", output.String())
+	}
+
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	last := session.Messages[len(session.Messages)-1]
+	if last.Content != "The file says ok [ref:toolcall-1]." || last.ToolCalls[0].Result != "ok" {
+		t.Fatalf("expected the raw answer and result in the session, got %+v", last)
+	}
+}
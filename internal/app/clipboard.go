@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+// ClipboardReader returns the text on the clipboard.
+type ClipboardReader interface {
+	ReadClipboard(ctx context.Context) (string, error)
+}
+
+// systemClipboard reads the desktop clipboard through the platform's paste command.
+type systemClipboard struct{}
+
+func (systemClipboard) ReadClipboard(ctx context.Context) (string, error) {
+	candidates := clipboardCommands()
+	for _, argv := range candidates {
+		if _, err := exec.LookPath(argv[0]); err != nil {
+			continue
+		}
+		out, err := exec.CommandContext(ctx, argv[0], argv[1:]...).Output()
+		if err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+				return "", fmt.Errorf("%s: %s", argv[0], strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return "", fmt.Errorf("%s: %w", argv[0], err)
+		}
+		return string(out), nil
+	}
+	names := make([]string, 0, len(candidates))
+	for _, argv := range candidates {
+		names = append(names, argv[0])
+	}
+	return "", fmt.Errorf("no clipboard command found (tried %s)", strings.Join(names, ", "))
+}
+
+// clipboardCommands lists the paste commands to try, in order.
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	}
+	commands := [][]string{{"xclip", "-selection", "clipboard", "-o"}, {"xsel", "--clipboard", "--output"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append([][]string{{"wl-paste", "--no-newline"}}, commands...)
+	}
+	return commands
+}
+
+// pasteClipboard handles /paste [clear]: the clipboard text goes with the next message,
+// in chunks like one-shot attachments, so large content never passes through the
+// terminal's line input.
+func (a *App) pasteClipboard(ctx context.Context, args []string) error {
+	if len(args) == 1 && strings.EqualFold(args[0], "clear") {
+		if a.pasted == "" {
+			fmt.Fprintln(a.output, "Nothing pasted.")
+			return nil
+		}
+		a.pasted = ""
+		fmt.Fprintln(a.output, "Pasted text dropped.")
+		return nil
+	}
+	if len(args) > 0 {
+		fmt.Fprintln(a.output, "Usage: /paste [clear]")
+		return nil
+	}
+
+	text, err := a.clipboard.ReadClipboard(ctx)
+	if err != nil {
+		fmt.Fprintf(a.errOutput, "Cannot read the clipboard: %v\n", err)
+		return nil
+	}
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(a.output, "The clipboard is empty; nothing pasted.")
+		return nil
+	}
+	replaced := a.pasted != ""
+	a.pasted = text
+	lines := strings.Count(text, "\n") + 1
+	fmt.Fprintf(a.output, "Pasted %d line(s), %d characters; they go with your next message (/paste clear drops them).\n", lines, utf8.RuneCountInString(text))
+	if replaced {
+		fmt.Fprintln(a.output, "This replaces the text pasted before.")
+	}
+	a.logDebug("pasted %d bytes from the clipboard", len(text))
+	return nil
+}
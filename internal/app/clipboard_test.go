@@ -0,0 +1,97 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+type stubClipboard struct {
+	texts []string
+	err   error
+}
+
+func (c *stubClipboard) ReadClipboard(context.Context) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	text := c.texts[0]
+	c.texts = c.texts[1:]
+	return text, nil
+}
+
+func runPasteSession(t *testing.T, clipboard app.ClipboardReader, input string) (string, *recordingProvider) {
+	t.Helper()
+	home := t.TempDir()
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "noted"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	var output bytes.Buffer
+	a, err := app.New(app.Options{
+		Store:          &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}}}},
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Clipboard:      clipboard,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return output.String(), provider
+}
+
+func TestAppPasteSendsClipboardWithNextMessage(t *testing.T) {
+	clipboard := &stubClipboard{texts: []string{"stale\r\n", "line one\r\nline two\r\n", "   "}}
+	got, provider := runPasteSession(t, clipboard, "/paste clear\n/paste\n/paste\nSummarize this\nAnd again\n/paste\n/exit\n")
+
+	for _, phrase := range []string{
+		"Nothing pasted.",
+		"Pasted 1 line(s), 5 characters; they go with your next message (/paste clear drops them).",
+		"Pasted 2 line(s), 17 characters;",
+		"This replaces the text pasted before.",
+		"The clipboard is empty; nothing pasted.",
+	} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, got)
+		}
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected two requests, got %d", len(requests))
+	}
+	first := requests[0].Messages
+	if len(first) != 2 || !strings.Contains(first[0].Content, "(part 1 of 1)") || !strings.HasSuffix(first[0].Content, "line one\nline two") || first[1].Content != "Summarize this" {
+		t.Fatalf("expected the pasted text before the message, got %+v", first)
+	}
+	second := requests[1].Messages
+	if len(second) != 4 || second[0].Content != first[0].Content || second[3].Content != "And again" {
+		t.Fatalf("expected the pasted text to stay in the conversation once, got %+v", second)
+	}
+}
+
+func TestAppPasteReportsClipboardErrors(t *testing.T) {
+	got, provider := runPasteSession(t, &stubClipboard{err: errors.New("no display")}, "/paste\nhello\n/exit\n")
+	if !strings.Contains(got, "Cannot read the clipboard: no display") {
+		t.Fatalf("expected the clipboard error, got:\n%s", got)
+	}
+	if messages := provider.Requests()[0].Messages; len(messages) != 1 {
+		t.Fatalf("expected only the typed message, got %+v", messages)
+	}
+}
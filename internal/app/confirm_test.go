@@ -0,0 +1,180 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppDeclinesUnansweredConfirmationAfterTimeout(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:             []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		ToolCallMode:       string(config.ToolCallModeManual),
+		ToolConfirmTimeout: "50ms",
+	}}
+	var (
+		mu       sync.Mutex
+		declined llm.ToolResult
+	)
+	provider := docsToolProvider()
+	provider.onResponded = func(result llm.ToolResult) {
+		mu.Lock()
+		declined = result
+		mu.Unlock()
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	mcpExec := docsMCP(nil)
+
+	input, typing := io.Pipe()
+	go func() {
+		io.WriteString(typing, "read the docs\n")
+		// Nobody answers the confirmation; the next line goes to the regular prompt.
+		time.Sleep(300 * time.Millisecond)
+		io.WriteString(typing, "/exit\n")
+		typing.Close()
+	}()
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	out := output.String()
+	if !strings.Contains(out, "Call now? (Y/N, auto-decline in 50ms): ") {
+		t.Fatalf("expected the timeout in the confirmation prompt, got:\n%s", out)
+	}
+	if !strings.Contains(out, "; MCP call declined.") {
+		t.Fatalf("expected an auto-decline notice, got:\n%s", out)
+	}
+	if len(mcpExec.Calls()) != 0 {
+		t.Fatalf("expected the unconfirmed call not to run")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !declined.IsError || !strings.Contains(declined.Content, "no confirmation within") {
+		t.Fatalf("expected the provider to be told the call was declined, got %+v", declined)
+	}
+	if instance.LastOutcome() != app.TurnToolDeclined {
+		t.Fatalf("expected a declined tool call, got %v", instance.LastOutcome())
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe to read while the app writes to it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAppConfirmationPromptShowsWaitedAndRemainingTime(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:             []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		ToolCallMode:       string(config.ToolCallModeManual),
+		ToolConfirmTimeout: "30s",
+	}}
+	var (
+		mu       sync.Mutex
+		declined llm.ToolResult
+	)
+	provider := docsToolProvider()
+	provider.onResponded = func(result llm.ToolResult) {
+		mu.Lock()
+		declined = result
+		mu.Unlock()
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	clock := &manualClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	var output lockedBuffer
+
+	input, typing := io.Pipe()
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !strings.Contains(output.String(), want) {
+			if time.Now().After(deadline) {
+				t.Errorf("timed out waiting for %q, got:\n%s", want, output.String())
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.WriteString(typing, "read the docs\n")
+		waitFor("Call now? (Y/N, auto-decline in 30s): ")
+		clock.Advance(10 * time.Second)
+		io.WriteString(typing, "maybe\n")
+		waitFor("Call now? (Y/N, waited 10.0s, auto-decline in 20.0s): ")
+		clock.Advance(25 * time.Second)
+		io.WriteString(typing, "maybe\n")
+		waitFor("MCP call declined.")
+		io.WriteString(typing, "/exit\n")
+		typing.Close()
+	}()
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            docsMCP(nil),
+		Clock:          clock,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	<-done
+
+	if out := output.String(); !strings.Contains(out, "No answer after 35.0s; MCP call declined.") {
+		t.Fatalf("expected the waited time from the app clock, got:\n%s", out)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(declined.Content, "no confirmation within 35.0s") {
+		t.Fatalf("expected the provider to be told how long the call waited, got %+v", declined)
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+	"github.com/gamzabox/humble-ai-cli/internal/tokenizer"
+)
+
+const minChunkTokenLimit = 256
+
+// contextBudget sizes the parts tool results and attachments are split into and how much
+// of a model's context window history may use.
+type contextBudget struct {
+	counter tokenizer.Counter
+	// chunkTokens is the size of one tool result or attachment part; zero sends them whole.
+	chunkTokens int
+	// overlapTokens repeats the end of a part at the start of the next.
+	overlapTokens int
+	// historyTokens caps prior conversation messages; zero means unlimited.
+	historyTokens int
+}
+
+// budgetForModel derives limits from the model's contextWindow: an eighth for each part,
+// overlapping by chunkOverlapTokens or a sixteenth of a part, and half for prior history.
+// Without a window nothing is split or trimmed.
+func budgetForModel(model config.Model) contextBudget {
+	budget := contextBudget{counter: tokenizer.ForModel(model.Name, model.Tokenizer)}
+	if model.ContextWindow > 0 {
+		budget.chunkTokens = max(model.ContextWindow/8, minChunkTokenLimit)
+		budget.overlapTokens = model.ChunkOverlapTokens
+		if budget.overlapTokens == 0 {
+			budget.overlapTokens = budget.chunkTokens / 16
+		}
+		budget.historyTokens = model.ContextWindow / 2
+	}
+	return budget
+}
+
+// split breaks text into overlapping parts of chunkTokens at paragraph, line, JSON element
+// or sentence boundaries; without a part size it stays whole.
+func (b contextBudget) split(text string) []string {
+	if b.counter == nil || b.chunkTokens <= 0 {
+		return []string{text}
+	}
+	return tokenizer.Chunker{
+		Counter:        b.counter,
+		MaxTokens:      b.chunkTokens,
+		OverlapTokens:  b.overlapTokens,
+		StructureAware: true,
+	}.Split(text)
+}
+
+// splitToolResult labels each part of an oversized tool result so the model reads every
+// part in order, and reports how many parts there are.
+func (b contextBudget) splitToolResult(content string) (string, int) {
+	chunks := b.split(content)
+	if len(chunks) <= 1 {
+		return content, 1
+	}
+	var out strings.Builder
+	for i, chunk := range chunks {
+		if i > 0 {
+			out.WriteString("\n\n")
+		}
+		fmt.Fprintf(&out, "[tool result part %d of %d]\n%s", i+1, len(chunks), chunk)
+	}
+	return out.String(), len(chunks)
+}
+
+// trimHistory drops the oldest messages until the remainder fits the history budget.
+func (b contextBudget) trimHistory(messages []llm.Message) []llm.Message {
+	if b.counter == nil || b.historyTokens <= 0 {
+		return messages
+	}
+	used := 0
+	start := len(messages)
+	for start > 0 {
+		cost := b.counter.Count(messages[start-1].Content)
+		if used+cost > b.historyTokens {
+			break
+		}
+		used += cost
+		start--
+	}
+	// Never start the context with an orphaned assistant reply.
+	for start < len(messages) && messages[start].Role != "user" {
+		start++
+	}
+	return messages[start:]
+}
+
+// historyContext converts the session history into request messages, splitting replayed
+// tool results the same way live results are split.
+func (a *App) historyContext() []llm.Message {
+	messages := history.LLMMessages(a.messages)
+	for i := range messages {
+		if messages[i].Role == "tool" {
+			messages[i].Content, _ = a.turnBudget.splitToolResult(messages[i].Content)
+		}
+	}
+	return messages
+}
+
+// contextGauge renders how much of the context window the next request will take, e.g.
+// "[ctx 12.3k/128k]": the system prompt and the whole conversation so far, before history
+// trimming. Past the history budget the oldest messages are already left out of requests.
+func (a *App) contextGauge(systemPrompt string, window int) string {
+	b := a.turnBudget
+	used := estimatePromptTokens(b.counter, llm.ChatRequest{SystemPrompt: systemPrompt, Messages: a.historyContext()})
+	gauge := fmt.Sprintf("[ctx %s/%s", formatTokenCount(used), formatTokenCount(window))
+	if b.historyTokens > 0 && used > b.historyTokens {
+		gauge += ", oldest messages trimmed"
+	}
+	return gauge + "]"
+}
+
+// formatTokenCount abbreviates thousands: 950, 12.3k, 128k.
+func formatTokenCount(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	text := fmt.Sprintf("%.1f", float64(n)/1000)
+	return strings.TrimSuffix(text, ".0") + "k"
+}
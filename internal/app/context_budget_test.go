@@ -0,0 +1,227 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// runToolCallTurn runs one auto-approved docs.read tool call returning result and reports
+// the terminal output, the result delivered to the provider and the saved session.
+func runToolCallTurn(t *testing.T, cfg config.Config, result string) (string, llm.ToolResult, history.Session) {
+	t.Helper()
+	return runToolResultTurn(t, cfg, llm.ToolResult{Content: result})
+}
+
+// runToolResultTurn is runToolCallTurn for a result with more than text.
+func runToolResultTurn(t *testing.T, cfg config.Config, result llm.ToolResult) (string, llm.ToolResult, history.Session) {
+	t.Helper()
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	cfg.ToolCallMode = "auto"
+	cfg.Models[0].Active = true
+	model := cfg.Models[0]
+	store := &stubStore{cfg: cfg}
+
+	resultCh := make(chan llm.ToolResult, 1)
+	provider := &toolRequestProvider{
+		call:        llm.ToolCall{Server: "docs", Method: "read"},
+		after:       []llm.StreamChunk{{Type: llm.ChunkToken, Content: "done"}},
+		onResponded: func(res llm.ToolResult) { resultCh <- res },
+	}
+	factory := newStubFactory()
+	factory.Register(model.Name, provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("read the docs\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP: &stubMCP{
+			servers:  []app.MCPServer{{Name: "docs"}},
+			toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+			response: result,
+		},
+		Clock: fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	sent := <-resultCh
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	return output.String(), sent, session
+}
+
+func TestAppToolResultPartsFollowContextWindow(t *testing.T) {
+	large := strings.Repeat("line of tool output with several words\n", 400)
+
+	output, small, session := runToolCallTurn(t, config.Config{Models: []config.Model{{Name: "tiny", Provider: "ollama", ContextWindow: 2048}}}, large)
+	parts := regexp.MustCompile(`\[tool result part (\d+) of (\d+)\]\n`).FindAllStringSubmatch(small.Content, -1)
+	if len(parts) < 2 || parts[0][1] != "1" || parts[len(parts)-1][1] != parts[0][2] {
+		t.Fatalf("expected every labelled part for a small context window, got %d labels", len(parts))
+	}
+	if strings.Count(small.Content, "line of tool output with several words\n") < 400 {
+		t.Fatalf("expected the parts to carry the whole tool result")
+	}
+	if !strings.Contains(output, "Tool result is long; sending it in "+parts[0][2]+" parts.") {
+		t.Fatalf("expected the split to be reported, got %q", output)
+	}
+	if got := session.Messages[1].ToolCalls[0].Result; got != large {
+		t.Fatalf("expected full tool result in session history, got %d bytes", len(got))
+	}
+
+	for _, model := range []config.Model{
+		{Name: "big", Provider: "ollama", ContextWindow: 128000},
+		{Name: "plain", Provider: "ollama"},
+	} {
+		output, whole, _ := runToolCallTurn(t, config.Config{Models: []config.Model{model}}, large)
+		if whole.Content != large || strings.Contains(output, "Tool result is long") {
+			t.Fatalf("%s: expected untouched tool result", model.Name)
+		}
+	}
+}
+
+func TestAppToolResultPartsOverlap(t *testing.T) {
+	var large strings.Builder
+	for i := 1; i <= 300; i++ {
+		fmt.Fprintf(&large, "record %d of the tool output\n", i)
+	}
+
+	_, sent, _ := runToolCallTurn(t, config.Config{Models: []config.Model{{Name: "tiny", Provider: "ollama", ContextWindow: 2048, ChunkOverlapTokens: 40}}}, large.String())
+	parts := regexp.MustCompile(`\[tool result part \d+ of \d+\]\n`).Split(sent.Content, -1)[1:]
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %d", len(parts))
+	}
+	lines := strings.Split(strings.TrimSpace(parts[0]), "\n")
+	last := lines[len(lines)-1]
+	if !strings.Contains(parts[1], last+"\n") {
+		t.Fatalf("expected part 2 to open with the last records of part 1 (%q), got %q", last, parts[1][:60])
+	}
+}
+
+func TestAppTrimsHistoryToContextWindow(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{
+		{Name: "tiny", Provider: "ollama", Active: true, ContextWindow: 400},
+	}}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: strings.Repeat("word ", 100)}}}
+	factory := newStubFactory()
+	factory.Register("tiny", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("first\nsecond\nthird\nfourth\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(requests))
+	}
+	last := requests[3].Messages
+	if last[0].Role != "user" || last[0].Content == "first" {
+		t.Fatalf("expected oldest turns to be trimmed, got first message %+v", last[0])
+	}
+	if last[len(last)-1].Content != "fourth" {
+		t.Fatalf("expected current message last, got %+v", last[len(last)-1])
+	}
+}
+
+func TestAppPrintsContextGaugeAfterEachTurn(t *testing.T) {
+	run := func(t *testing.T, cfg config.Config, input string) string {
+		t.Helper()
+		home := t.TempDir()
+		provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: strings.Repeat("word ", 100)}}}
+		factory := newStubFactory()
+		factory.Register(cfg.Models[0].Name, provider)
+
+		var output bytes.Buffer
+		instance, err := app.New(app.Options{
+			Store:          &stubStore{cfg: cfg},
+			Factory:        factory,
+			Input:          strings.NewReader(input),
+			Output:         &output,
+			HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+			HomeDir:        home,
+			MCP:            &stubMCP{},
+			Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := instance.Run(context.Background()); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return output.String()
+	}
+
+	t.Run("large window", func(t *testing.T) {
+		out := run(t, config.Config{Models: []config.Model{
+			{Name: "big", Provider: "ollama", Active: true, ContextWindow: 128000},
+		}}, "first\nsecond\n/exit\n")
+		gauges := regexp.MustCompile(`\[ctx ([\d.]+)k/128k\]`).FindAllStringSubmatch(out, -1)
+		if len(gauges) != 2 {
+			t.Fatalf("expected a gauge after each of 2 turns, got %q", out)
+		}
+		first, _ := strconv.ParseFloat(gauges[0][1], 64)
+		second, _ := strconv.ParseFloat(gauges[1][1], 64)
+		if first <= 0 || second <= first {
+			t.Fatalf("expected usage to grow with the conversation, got %vk then %vk", first, second)
+		}
+	})
+
+	t.Run("history trimmed", func(t *testing.T) {
+		out := run(t, config.Config{Models: []config.Model{
+			{Name: "tiny", Provider: "ollama", Active: true, ContextWindow: 400},
+		}}, "first\nsecond\nthird\n/exit\n")
+		if !regexp.MustCompile(`\[ctx [\d.]+k?/400, oldest messages trimmed\]`).MatchString(out) {
+			t.Fatalf("expected the gauge to report trimming, got %q", out)
+		}
+	})
+
+	t.Run("disabled or unknown window", func(t *testing.T) {
+		for _, cfg := range []config.Config{
+			{DisableContextGauge: true, Models: []config.Model{{Name: "big", Provider: "ollama", Active: true, ContextWindow: 128000}}},
+			{Models: []config.Model{{Name: "plain", Provider: "ollama", Active: true}}},
+		} {
+			if out := run(t, cfg, "first\n/exit\n"); strings.Contains(out, "[ctx ") {
+				t.Fatalf("expected no gauge, got %q", out)
+			}
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// generationDeadline cancels a turn once the model has spent limit generating. The clock
+// stops while a tool call is handled, so a slow Y/N answer or a long MCP call is not
+// charged to the model.
+type generationDeadline struct {
+	limit  time.Duration
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	remaining time.Duration
+	resumed   time.Time
+	expired   bool
+}
+
+// startGenerationDeadline starts the clock; it returns nil when limit is 0, and the
+// methods of a nil deadline do nothing.
+func startGenerationDeadline(limit time.Duration, cancel context.CancelFunc) *generationDeadline {
+	if limit <= 0 {
+		return nil
+	}
+	d := &generationDeadline{limit: limit, cancel: cancel, remaining: limit}
+	d.resume()
+	return d
+}
+
+func (d *generationDeadline) pause() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer == nil || d.expired {
+		return
+	}
+	d.timer.Stop()
+	d.timer = nil
+	d.remaining -= time.Since(d.resumed)
+}
+
+func (d *generationDeadline) resume() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil || d.expired {
+		return
+	}
+	d.resumed = time.Now()
+	d.timer = time.AfterFunc(d.remaining, d.expire)
+}
+
+func (d *generationDeadline) expire() {
+	d.mu.Lock()
+	d.expired = true
+	d.mu.Unlock()
+	d.cancel()
+}
+
+// stop ends the clock when the turn is over.
+func (d *generationDeadline) stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// hasExpired reports whether the deadline cancelled the turn.
+func (d *generationDeadline) hasExpired() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.expired
+}
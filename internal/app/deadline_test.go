@@ -0,0 +1,119 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// stallingProvider starts an answer and then never finishes it.
+type stallingProvider struct{}
+
+func (stallingProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	out := make(chan llm.StreamChunk)
+	go func() {
+		defer close(out)
+		out <- llm.StreamChunk{Type: llm.ChunkToken, Content: "Let me think"}
+		<-ctx.Done()
+	}()
+	return out, nil
+}
+
+func TestAppCancelsAnswerAfterGenerationTimeout(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:            []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		GenerationTimeout: "50ms",
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", stallingProvider{})
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	done := make(chan struct{})
+	go func() {
+		_ = instance.Ask(context.Background(), "question")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the turn did not stop at the generation timeout")
+	}
+	if !strings.Contains(output.String(), "Response timed out after 50ms of generation") {
+		t.Fatalf("expected a timeout notice, got:\n%s", output.String())
+	}
+	if got := instance.LastOutcome(); got != app.TurnProviderError {
+		t.Fatalf("expected a provider error outcome, got %v", got)
+	}
+}
+
+func TestAppGenerationTimeoutExcludesToolConfirmation(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:            []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		ToolCallMode:      string(config.ToolCallModeManual),
+		GenerationTimeout: "200ms",
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", docsToolProvider())
+	mcpExec := docsMCP(nil)
+
+	input, typing := io.Pipe()
+	go func() {
+		io.WriteString(typing, "read the docs\n")
+		// The confirmation takes longer than the whole generation budget.
+		time.Sleep(500 * time.Millisecond)
+		io.WriteString(typing, "y\n/exit\n")
+		typing.Close()
+	}()
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if strings.Contains(output.String(), "timed out") {
+		t.Fatalf("expected the confirmation wait not to count, got:\n%s", output.String())
+	}
+	if len(mcpExec.Calls()) != 1 {
+		t.Fatalf("expected the confirmed call to run, got %d calls", len(mcpExec.Calls()))
+	}
+	if got := instance.LastOutcome(); got != app.TurnOK {
+		t.Fatalf("expected the answer to finish, got %v\n%s", got, output.String())
+	}
+}
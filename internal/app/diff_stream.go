@@ -0,0 +1,65 @@
+package app
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+// diffHighlighter colors ```diff blocks of a streamed answer. Text outside those blocks is
+// written as it arrives; lines inside are held until complete so they can be styled whole.
+type diffHighlighter struct {
+	w      io.Writer
+	line   strings.Builder
+	inDiff bool
+}
+
+func (h *diffHighlighter) Write(p []byte) (int, error) {
+	text := string(p)
+	for text != "" {
+		segment, rest, newline := strings.Cut(text, "\n")
+		text = rest
+		if !h.inDiff {
+			if newline {
+				segment += "\n"
+			}
+			if _, err := io.WriteString(h.w, segment); err != nil {
+				return 0, err
+			}
+		}
+		h.line.WriteString(segment)
+		if !newline {
+			continue
+		}
+		line := strings.TrimSuffix(h.line.String(), "\n")
+		h.line.Reset()
+		switch {
+		case h.inDiff && strings.TrimSpace(line) == "```":
+			h.inDiff = false
+			if _, err := io.WriteString(h.w, line+"\n"); err != nil {
+				return 0, err
+			}
+		case h.inDiff:
+			if _, err := io.WriteString(h.w, render.ColorDiffLine(line+"\n")); err != nil {
+				return 0, err
+			}
+		case render.IsDiffFence(line):
+			h.inDiff = true
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes a diff line still waiting for its newline, e.g. when the answer ends.
+// It does nothing on a nil highlighter.
+func (h *diffHighlighter) Flush() {
+	if h == nil {
+		return
+	}
+	if h.inDiff && h.line.Len() > 0 {
+		_, _ = io.WriteString(h.w, render.ColorDiffLine(h.line.String()))
+	}
+	h.line.Reset()
+	h.inDiff = false
+}
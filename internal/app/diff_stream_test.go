@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiffHighlighterColorsStreamedDiffBlocks(t *testing.T) {
+	var out strings.Builder
+	h := &diffHighlighter{w: &out}
+	answer := "Apply this:\n```diff\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n package main\n-var x = 1\n+var x = 2\n```\nDone: -1 is fine.\n```diff\n+tail"
+	// Stream in small pieces so fences and lines are split across writes.
+	for i := 0; i < len(answer); i += 3 {
+		fmt.Fprint(h, answer[i:min(i+3, len(answer))])
+	}
+	h.Flush()
+
+	want := "Apply this:\n```diff\n" +
+		"\x1b[1m--- a/main.go\x1b[0m\n" +
+		"\x1b[1m+++ b/main.go\x1b[0m\n" +
+		"\x1b[36m@@ -1,2 +1,2 @@\x1b[0m\n" +
+		" package main\n" +
+		"\x1b[31m-var x = 1\x1b[0m\n" +
+		"\x1b[32m+var x = 2\x1b[0m\n" +
+		"```\nDone: -1 is fine.\n```diff\n" +
+		"\x1b[32m+tail\x1b[0m"
+	if got := out.String(); got != want {
+		t.Fatalf("highlighted output = %q, want %q", got, want)
+	}
+}
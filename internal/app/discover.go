@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/discovery"
+)
+
+// discoverModels probes local model servers and offers to add unconfigured models.
+func (a *App) discoverModels(ctx context.Context) error {
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+
+	fmt.Fprintln(a.output, "Probing local model servers...")
+	var candidates []config.Model
+	for _, result := range a.discovery.Probe(ctx) {
+		endpoint := result.Endpoint
+		if result.Err != nil {
+			fmt.Fprintf(a.output, "  %s (%s): unreachable\n", endpoint.Name, endpoint.BaseURL)
+			a.logDebug("discovery probe failed: endpoint=%s err=%v", endpoint.BaseURL, result.Err)
+			continue
+		}
+		fmt.Fprintf(a.output, "  %s (%s): %d models\n", endpoint.Name, endpoint.BaseURL, len(result.Models))
+		for _, name := range result.Models {
+			if isModelConfigured(cfg, name, endpoint) {
+				continue
+			}
+			candidates = append(candidates, config.Model{
+				Name:     name,
+				Provider: endpoint.Provider,
+				APIKey:   endpoint.APIKey,
+				BaseURL:  endpoint.BaseURL,
+			})
+		}
+	}
+
+	if len(candidates) == 0 {
+		fmt.Fprintln(a.output, "No new models found.")
+		return nil
+	}
+
+	fmt.Fprintln(a.output, "Models not yet in config:")
+	for idx, m := range candidates {
+		fmt.Fprintf(a.output, "  %d) %s (%s @ %s)\n", idx+1, m.Name, m.Provider, m.BaseURL)
+	}
+	line, err := a.readLine("Add which models? (e.g. 1,3 or all; 0 to cancel): ")
+	if err != nil {
+		return err
+	}
+	selected, ok := parseModelSelection(line, len(candidates))
+	if !ok {
+		fmt.Fprintln(a.output, "Invalid selection.")
+		return nil
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(a.output, "Discovery cancelled.")
+		return nil
+	}
+
+	hasActive := false
+	for _, m := range cfg.Models {
+		hasActive = hasActive || m.Active
+	}
+	models := append([]config.Model{}, cfg.Models...)
+	for _, idx := range selected {
+		m := candidates[idx]
+		if !hasActive {
+			m.Active = true
+			hasActive = true
+		}
+		models = append(models, m)
+	}
+	cfg.Models = models
+	if err := a.store.Save(cfg); err != nil {
+		return err
+	}
+
+	a.cfgMu.Lock()
+	a.cfg = cfg
+	a.cfgMu.Unlock()
+
+	fmt.Fprintf(a.output, "Added %d models to %s.\n", len(selected), a.configFilePath())
+	return nil
+}
+
+func isModelConfigured(cfg config.Config, name string, endpoint discovery.Endpoint) bool {
+	for _, m := range cfg.Models {
+		base := m.BaseURL
+		if base == "" {
+			// Models without baseUrl use the provider default, which is what we probed.
+			base = endpoint.BaseURL
+		}
+		if m.Name == name && strings.EqualFold(m.Provider, endpoint.Provider) &&
+			strings.TrimRight(base, "/") == strings.TrimRight(endpoint.BaseURL, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseModelSelection turns "1,3", "all" or "0" into zero-based candidate indexes.
+func parseModelSelection(line string, count int) ([]int, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || line == "0" {
+		return nil, true
+	}
+	if strings.EqualFold(line, "all") {
+		out := make([]int, count)
+		for i := range out {
+			out[i] = i
+		}
+		return out, true
+	}
+	seen := make(map[int]bool)
+	var out []int
+	for _, part := range strings.Split(line, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > count {
+			return nil, false
+		}
+		if !seen[n-1] {
+			seen[n-1] = true
+			out = append(out, n-1)
+		}
+	}
+	return out, true
+}
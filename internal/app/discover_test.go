@@ -0,0 +1,74 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/discovery"
+)
+
+type stubDiscoverer struct {
+	results []discovery.Result
+}
+
+func (s stubDiscoverer) Probe(context.Context) []discovery.Result {
+	return s.results
+}
+
+func TestAppDiscoverAddsSelectedModels(t *testing.T) {
+	home := t.TempDir()
+	ollama := discovery.Endpoint{Name: "Ollama", Provider: "ollama", BaseURL: "http://localhost:11434"}
+	lmstudio := discovery.Endpoint{Name: "LM Studio", Provider: "openai", BaseURL: "http://localhost:1234/v1", APIKey: "lm-studio"}
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "llama3", Provider: "ollama"}},
+		},
+	}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:       store,
+		Factory:     newStubFactory(),
+		Input:       strings.NewReader("/discover\n2\n/exit\n"),
+		Output:      &output,
+		ErrorOutput: &output,
+		HomeDir:     home,
+		MCP:         &stubMCP{},
+		Discovery: stubDiscoverer{results: []discovery.Result{
+			{Endpoint: ollama, Models: []string{"llama3", "qwen2.5"}},
+			{Endpoint: lmstudio, Models: []string{"mistral"}},
+			{Endpoint: discovery.Endpoint{Name: "Other", BaseURL: "http://localhost:9999"}, Err: errors.New("refused")},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "Other (http://localhost:9999): unreachable") {
+		t.Fatalf("expected unreachable endpoint in output:\n%s", got)
+	}
+	if strings.Contains(got, ") llama3 (") {
+		t.Fatalf("already configured model should not be offered:\n%s", got)
+	}
+
+	models := store.cfg.Models
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models after discovery, got %#v", models)
+	}
+	added := models[1]
+	if added.Name != "mistral" || added.Provider != "openai" || added.BaseURL != "http://localhost:1234/v1" || added.APIKey != "lm-studio" {
+		t.Fatalf("unexpected added model: %#v", added)
+	}
+	if !added.Active {
+		t.Fatalf("expected first added model to become active when none was active")
+	}
+}
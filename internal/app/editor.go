@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// composeInEditor opens $VISUAL or $EDITOR on a temporary file and sends what was saved
+// as the next user message: /editor.
+func (a *App) composeInEditor(ctx context.Context) error {
+	editor := editorCommand()
+	file, err := os.CreateTemp("", "humble-ai-prompt-*.md")
+	if err != nil {
+		return fmt.Errorf("create prompt file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("create prompt file: %w", err)
+	}
+
+	fmt.Fprintf(a.output, "Waiting for %s to close...\n", editor[0])
+	cmd := exec.CommandContext(ctx, editor[0], append(editor[1:], path)...)
+	// The editor takes over the terminal; the prompt reader only holds it in raw mode
+	// while reading a line.
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(a.errOutput, "Editor failed: %v\n", err)
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read prompt file: %w", err)
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		fmt.Fprintln(a.output, "Empty prompt; nothing sent.")
+		return nil
+	}
+	a.logDebug("Composed a %d-byte prompt in %s", len(content), editor[0])
+	return a.handleUserMessage(ctx, content)
+}
+
+// editorCommand returns the editor to run, with its arguments, e.g. "code --wait".
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
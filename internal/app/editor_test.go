@@ -0,0 +1,105 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppEditorSendsSavedFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the editor")
+	}
+	home := t.TempDir()
+	script := filepath.Join(home, "fake-editor.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf 'Review this plan:\\n\\n1. migrate\\n2. deploy\\n' > \"$1\"\n"), 0o755); err != nil {
+		t.Fatalf("failed to write editor script: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "looks fine"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/editor\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	reqs := provider.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request, got %d\n%s", len(reqs), output.String())
+	}
+	msgs := reqs[0].Messages
+	if got := msgs[len(msgs)-1].Content; got != "Review this plan:\n\n1. migrate\n2. deploy" {
+		t.Fatalf("expected the saved file as the message, got %q", got)
+	}
+	if !strings.Contains(output.String(), "looks fine") {
+		t.Fatalf("expected the answer in output, got:\n%s", output.String())
+	}
+}
+
+func TestAppEditorSkipsEmptyFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses true(1) as the editor")
+	}
+	home := t.TempDir()
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "true")
+
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/editor\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(provider.Requests()) != 0 {
+		t.Fatal("expected no request for an empty prompt")
+	}
+	if !strings.Contains(output.String(), "Empty prompt; nothing sent.") {
+		t.Fatalf("expected the empty prompt notice, got:\n%s", output.String())
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+// exportSession writes the current session in a shareable format: /export html [path].
+func (a *App) exportSession(args []string) error {
+	if len(args) == 0 || len(args) > 2 || !strings.EqualFold(args[0], "html") {
+		fmt.Fprintln(a.output, "Usage: /export html [path]")
+		return nil
+	}
+
+	sessionPath := a.SessionPath()
+	if sessionPath == "" {
+		fmt.Fprintln(a.output, "Nothing to export yet; the session is saved after the first answer.")
+		return nil
+	}
+	session, err := history.Load(sessionPath)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(sessionPath), filepath.Ext(sessionPath))
+	target := base + ".html"
+	if len(args) == 2 {
+		target = args[1]
+	}
+
+	a.cfgMu.RLock()
+	user, assistant := a.cfg.TurnLabels(session.Model)
+	a.cfgMu.RUnlock()
+	var buf bytes.Buffer
+	opts := render.HTMLOptions{Title: base, Labels: render.Labels{User: user, Assistant: assistant}}
+	if err := render.HTML(&buf, session, opts); err != nil {
+		return fmt.Errorf("render html: %w", err)
+	}
+	if dir := filepath.Dir(target); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create export dir: %w", err)
+		}
+	}
+	if err := os.WriteFile(target, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	fmt.Fprintf(a.output, "Exported session to %s\n", target)
+	return nil
+}
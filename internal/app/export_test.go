@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppExportHTMLWritesCurrentSession(t *testing.T) {
+	home := t.TempDir()
+	target := filepath.Join(t.TempDir(), "share", "chat.html")
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "```go\nfmt.Println(1)\n```"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/export html\nprint one\n/export html " + target + "\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "Nothing to export yet") {
+		t.Fatalf("expected empty session notice, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Exported session to "+target) {
+		t.Fatalf("expected export confirmation, got:\n%s", got)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	page := string(data)
+	if !strings.Contains(page, "<title>20250102_030405_printone</title>") || !strings.Contains(page, `class="language-go"`) {
+		t.Fatalf("unexpected export:\n%s", page)
+	}
+}
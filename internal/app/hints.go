@@ -0,0 +1,149 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// hint is a tip shown once, the first time its situation comes up in the interactive loop.
+type hint struct {
+	id   string
+	text string
+}
+
+var (
+	hintWelcome  = hint{"welcome", "Type /help to see every command; /set-model switches models and /new starts over."}
+	hintToolMode = hint{"tool-mode", "/set-tool-mode auto runs tool calls without asking; destructive calls still ask."}
+	hintHistory  = hint{"history", "This conversation is saved; /history lists saved sessions and resumes one."}
+	hintBookmark = hint{"bookmark", "/bookmark names the current point of a conversation and /goto rewinds to it."}
+)
+
+var allHints = []hint{hintWelcome, hintToolMode, hintHistory, hintBookmark}
+
+// hintState is hints.json: the tips already shown, which never show again.
+type hintState struct {
+	Seen []string `json:"seen"`
+}
+
+func (a *App) hintsPath() string {
+	return filepath.Join(config.Dir(a.homeDir), "hints.json")
+}
+
+func (a *App) loadHintState() (hintState, error) {
+	var state hintState
+	data, err := os.ReadFile(a.hintsPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("parse %s: %w", a.hintsPath(), err)
+	}
+	return state, nil
+}
+
+func (a *App) saveHintState(state hintState) error {
+	if err := os.MkdirAll(filepath.Dir(a.hintsPath()), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.hintsPath(), append(data, '\n'), 0o644)
+}
+
+// showHint prints h unless tips are off, output is quiet, the app is not interactive or
+// the tip was shown before.
+func (a *App) showHint(h hint) {
+	a.cfgMu.RLock()
+	disabled := a.cfg.DisableHints
+	a.cfgMu.RUnlock()
+	if disabled || a.quiet || !a.interactive {
+		return
+	}
+	state, err := a.loadHintState()
+	if err != nil {
+		a.logError("hints: %v", err)
+		return
+	}
+	for _, id := range state.Seen {
+		if id == h.id {
+			return
+		}
+	}
+	fmt.Fprintf(a.output, "Tip: %s (/hints off hides tips)\n", h.text)
+	state.Seen = append(state.Seen, h.id)
+	sort.Strings(state.Seen)
+	if err := a.saveHintState(state); err != nil {
+		a.logError("hints: %v", err)
+	}
+}
+
+// configureHints handles /hints [on|off|reset]; without an argument it lists the tips.
+func (a *App) configureHints(args []string) error {
+	if len(args) == 0 {
+		return a.listHints()
+	}
+	switch strings.ToLower(args[0]) {
+	case "on", "off":
+		a.cfgMu.RLock()
+		cfg := a.cfg
+		a.cfgMu.RUnlock()
+		cfg.DisableHints = strings.EqualFold(args[0], "off")
+		if err := a.store.Save(cfg); err != nil {
+			return err
+		}
+		a.cfgMu.Lock()
+		a.cfg = cfg
+		a.cfgMu.Unlock()
+		if cfg.DisableHints {
+			fmt.Fprintln(a.output, "Tips off.")
+		} else {
+			fmt.Fprintln(a.output, "Tips on.")
+		}
+	case "reset":
+		if err := os.Remove(a.hintsPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		fmt.Fprintln(a.output, "All tips will be shown again.")
+	default:
+		fmt.Fprintln(a.output, "Usage: /hints [on|off|reset]")
+	}
+	return nil
+}
+
+func (a *App) listHints() error {
+	state, err := a.loadHintState()
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, id := range state.Seen {
+		seen[id] = true
+	}
+	a.cfgMu.RLock()
+	disabled := a.cfg.DisableHints
+	a.cfgMu.RUnlock()
+	if disabled {
+		fmt.Fprintln(a.output, "Tips are off; /hints on turns them back on.")
+	}
+	for _, h := range allHints {
+		status := "pending"
+		if seen[h.id] {
+			status = "shown"
+		}
+		fmt.Fprintf(a.output, "  [%s] %s\n", status, h.text)
+	}
+	return nil
+}
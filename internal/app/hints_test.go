@@ -0,0 +1,105 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func newHintsApp(t *testing.T, home string, store *stubStore, input string, output *bytes.Buffer) *app.App {
+	t.Helper()
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hi"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         output,
+		ErrorOutput:    output,
+		HistoryRootDir: filepath.Join(home, "sessions"),
+		HomeDir:        home,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { instance.Close() })
+	return instance
+}
+
+func TestAppShowsEachTipOnce(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}}}
+
+	var first bytes.Buffer
+	instance := newHintsApp(t, home, store, "hello\n/exit\n", &first)
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Count(first.String(), "Tip: Type /help") != 1 {
+		t.Fatalf("expected the welcome tip once, got:\n%s", first.String())
+	}
+	if !strings.Contains(first.String(), "Tip: This conversation is saved") {
+		t.Fatalf("expected the history tip after the first answer, got:\n%s", first.String())
+	}
+	if _, err := os.Stat(filepath.Join(config.Dir(home), "hints.json")); err != nil {
+		t.Fatalf("expected hints.json to be written: %v", err)
+	}
+
+	var second bytes.Buffer
+	instance = newHintsApp(t, home, store, "hello\n/exit\n", &second)
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Contains(second.String(), "Tip:") {
+		t.Fatalf("expected no tips on the second run, got:\n%s", second.String())
+	}
+}
+
+func TestAppHintsCommandTurnsTipsOffAndResets(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}}}
+
+	var output bytes.Buffer
+	instance := newHintsApp(t, home, store, "/hints off\n/hints reset\n/hints\nhello\n/exit\n", &output)
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !store.cfg.DisableHints {
+		t.Fatalf("expected /hints off to save disableHints")
+	}
+	out := output.String()
+	if !strings.Contains(out, "Tips off.") || !strings.Contains(out, "All tips will be shown again.") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if !strings.Contains(out, "Tips are off; /hints on turns them back on.") || !strings.Contains(out, "  [pending] This conversation is saved") {
+		t.Fatalf("expected the tip list after reset, got:\n%s", out)
+	}
+	if strings.Contains(out, "Tip: This conversation is saved") {
+		t.Fatalf("expected no tips once they are off, got:\n%s", out)
+	}
+}
+
+func TestAppAskShowsNoTips(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}}}
+
+	var output bytes.Buffer
+	instance := newHintsApp(t, home, store, "", &output)
+	if err := instance.Ask(context.Background(), "hello"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if strings.Contains(output.String(), "Tip:") {
+		t.Fatalf("expected no tips outside the interactive loop, got:\n%s", output.String())
+	}
+	if _, err := os.Stat(filepath.Join(config.Dir(home), "hints.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected no hints.json, got err = %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+// sessionStore returns the store sessions are saved to. The sessions directory is always the
+// working copy, so transcripts, /export and the show subcommand keep reading local files; a
+// remote historyStore receives a copy of every save and serves sessions saved elsewhere.
+func (a *App) sessionStore() history.Store {
+	local := history.FileStore{Root: a.historyRoot}
+	a.cfgMu.RLock()
+	settings := a.cfg.HistoryStore
+	a.cfgMu.RUnlock()
+	switch settings.EffectiveType() {
+	case config.HistoryStoreWebDAV:
+		return history.MirrorStore{
+			Local: local,
+			Remote: history.WebDAVStore{
+				URL:      strings.TrimSpace(settings.URL),
+				Username: settings.Username,
+				Password: settings.Password,
+			},
+		}
+	default:
+		return local
+	}
+}
+
+// sessionName is the store key of the current session file.
+func (a *App) sessionName() string {
+	return filepath.Base(a.historyPath)
+}
@@ -0,0 +1,109 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppResumesSessionFromWebDAVStore(t *testing.T) {
+	var mu sync.Mutex
+	files := map[string][]byte{}
+	remote, _ := json.Marshal(history.Session{
+		Model: "stub-model",
+		Messages: []history.Message{
+			{Role: "user", Content: "written on the laptop"},
+			{Role: "assistant", Content: "noted"},
+		},
+	})
+	files["/dav/20250101_000000_laptop.json"] = remote
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "PROPFIND":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<multistatus xmlns="DAV:"><response><href>/dav/</href></response>`)
+			for name := range files {
+				fmt.Fprintf(w, `<response><href>%s</href></response>`, name)
+			}
+			fmt.Fprint(w, `</multistatus>`)
+		case http.MethodGet:
+			data, ok := files[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		case http.MethodPut:
+			files[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	store := &stubStore{cfg: config.Config{
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		HistoryStore: config.HistoryStore{Type: "webdav", URL: server.URL + "/dav"},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "continued"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/history\n1\nand on the desktop?\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !strings.Contains(output.String(), "1) 20250101_000000_laptop.json") {
+		t.Fatalf("expected the remote session to be listed, got:\n%s", output.String())
+	}
+	requests := provider.Requests()
+	if len(requests) != 1 || len(requests[0].Messages) < 3 || requests[0].Messages[0].Content != "written on the laptop" {
+		t.Fatalf("expected the remote conversation to be resumed, got %+v", requests)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var saved history.Session
+	if err := json.Unmarshal(files["/dav/20250101_000000_laptop.json"], &saved); err != nil {
+		t.Fatalf("failed to parse uploaded session: %v", err)
+	}
+	if len(saved.Messages) != 4 || saved.Messages[3].Content != "continued" {
+		t.Fatalf("expected the continued session to be uploaded, got %+v", saved.Messages)
+	}
+	local, err := history.Load(filepath.Join(sessionDir, "20250101_000000_laptop.json"))
+	if err != nil || len(local.Messages) != 4 {
+		t.Fatalf("expected the local working copy to be updated, got %+v, %v", local, err)
+	}
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/gitsync"
+)
+
+// historySyncTimeout bounds a commit and push so an unreachable remote cannot hold the prompt.
+const historySyncTimeout = 30 * time.Second
+
+// syncHistory commits the session file to the historySync repository. Failures are
+// reported but never fail the turn; the next answer syncs the file again.
+func (a *App) syncHistory(ctx context.Context, settings config.HistorySync) {
+	dir := strings.TrimSpace(settings.Repo)
+	if dir == "" {
+		return
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		dir = filepath.Join(a.homeDir, strings.TrimPrefix(dir[1:], "/"))
+	}
+	repo := gitsync.Repo{
+		Dir:    dir,
+		Remote: strings.TrimSpace(settings.Remote),
+		Branch: strings.TrimSpace(settings.Branch),
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), historySyncTimeout)
+	defer cancel()
+	path := a.SessionPath()
+	if err := repo.Sync(ctx, path); err != nil {
+		fmt.Fprintf(a.errOutput, "History sync failed: %v\n", err)
+		a.logError("history sync %s to %s: %v", path, dir, err)
+		return
+	}
+	a.logDebug("History synced: %s -> %s", filepath.Base(path), dir)
+}
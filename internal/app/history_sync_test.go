@@ -0,0 +1,66 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppCommitsSessionToHistoryRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Tester")
+	t.Setenv("GIT_AUTHOR_EMAIL", "tester@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Tester")
+	t.Setenv("GIT_COMMITTER_EMAIL", "tester@example.com")
+
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:      []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		HistorySync: config.HistorySync{Repo: "~/ai-history"},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hi"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+	for _, question := range []string{"hello", "again"} {
+		if err := instance.Ask(context.Background(), question); err != nil {
+			t.Fatalf("Ask() error = %v", err)
+		}
+	}
+
+	name := filepath.Base(instance.SessionPath())
+	out, err := exec.Command("git", "-C", filepath.Join(home, "ai-history"), "log", "--format=%s").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v\n%s\n%s", err, out, output.String())
+	}
+	if want := "Update " + name + "\nUpdate " + name; strings.TrimSpace(string(out)) != want {
+		t.Fatalf("expected a commit per answer, got:\n%s", out)
+	}
+}
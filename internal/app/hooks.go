@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/hooks"
+)
+
+// runPostResponseHooks pipes the final assistant message through configured hooks and prints their output.
+func (a *App) runPostResponseHooks(ctx context.Context, cfg config.Config, message string) {
+	if len(cfg.PostResponseHooks) == 0 || strings.TrimSpace(message) == "" {
+		return
+	}
+	for _, hook := range cfg.PostResponseHooks {
+		for _, result := range hooks.RunPostResponse(ctx, hook, message) {
+			label := result.Hook
+			if result.Language != "" {
+				label += " (" + result.Language + ")"
+			}
+			switch {
+			case result.Err != nil:
+				fmt.Fprintf(a.errOutput, "[hook %s] failed: %v\n", label, result.Err)
+				a.logError("post-response hook failed: hook=%s err=%v", result.Hook, result.Err)
+				continue
+			case result.ExitCode != 0:
+				fmt.Fprintf(a.output, "[hook %s] exit status %d\n", label, result.ExitCode)
+			default:
+				fmt.Fprintf(a.output, "[hook %s] ok\n", label)
+			}
+			if output := strings.TrimRight(result.Output, "\n"); output != "" {
+				fmt.Fprintln(a.output, output)
+			}
+		}
+	}
+}
+
+// rewriteInput applies Go rewriters and then preSendHooks to a user message.
+func (a *App) rewriteInput(ctx context.Context, cfg config.Config, message string) (string, error) {
+	original := message
+	for _, rewriter := range a.rewriters {
+		out, err := rewriter.Rewrite(ctx, message)
+		if err != nil {
+			return "", err
+		}
+		message = out
+	}
+	for _, hook := range cfg.PreSendHooks {
+		out, err := hooks.RunPreSend(ctx, hook, message)
+		if err != nil {
+			return "", err
+		}
+		message = out
+	}
+	if message != original {
+		fmt.Fprintln(a.output, "(message rewritten by pre-send hooks)")
+		a.logDebug("user message rewritten: %q -> %q", original, message)
+	}
+	return message, nil
+}
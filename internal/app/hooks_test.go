@@ -0,0 +1,112 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppRunsPostResponseHooksOnCodeBlocks(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		PostResponseHooks: []config.Hook{{
+			Name:      "lint",
+			Command:   []string{"sh", "-c", "echo linted $(wc -l); exit 1"},
+			Input:     config.HookInputCode,
+			Languages: []string{"go"},
+		}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{
+		{Type: llm.ChunkToken, Content: "```go\nfmt.Println(1)\nfmt.Println(2)\n```\n"},
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("write code\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "[hook lint (go)] exit status 1\nlinted 2\n") {
+		t.Fatalf("expected hook output, got:\n%s", got)
+	}
+}
+
+type upperRewriter struct{}
+
+func (upperRewriter) Rewrite(_ context.Context, message string) (string, error) {
+	return strings.ToUpper(message), nil
+}
+
+func TestAppRewritesInputBeforeSending(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		PreSendHooks: []config.Hook{
+			{Name: "suffix", Command: []string{"sed", "s/$/ [expanded]/"}},
+			{Name: "guard", Command: []string{"sh", "-c", "if grep -q SECRET; then echo blocked >&2; exit 1; fi"}},
+		},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("see proj-1\nmy secret\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		InputRewriters: []app.InputRewriter{upperRewriter{}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected only the first message to be sent, got %d requests", len(requests))
+	}
+	msgs := requests[0].Messages
+	if got := msgs[len(msgs)-1].Content; got != "SEE PROJ-1 [expanded]" {
+		t.Fatalf("unexpected rewritten message: %q", got)
+	}
+	got := output.String()
+	if !strings.Contains(got, "(message rewritten by pre-send hooks)") {
+		t.Fatalf("expected rewrite notice, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Message not sent: hook guard exited with status 1: blocked") {
+		t.Fatalf("expected rejection message, got:\n%s", got)
+	}
+}
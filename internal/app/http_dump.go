@@ -0,0 +1,51 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// httpDump is the file receiving one turn's raw provider traffic with debugHttpDump.
+// A cancelled response may still be read after the turn ends; those writes are dropped.
+type httpDump struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openHTTPDump creates logs/http-<time>-<turn ID>.log; it returns nil when that fails.
+func (a *App) openHTTPDump(turnID string) *httpDump {
+	dir := filepath.Join(config.Dir(a.homeDir), "logs")
+	name := "http-" + a.clock.Now().Format("20060102-150405") + "-" + turnID + ".log"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		a.logError("debugHttpDump: %v", err)
+		return nil
+	}
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		a.logError("debugHttpDump: %v", err)
+		return nil
+	}
+	a.logDebug("HTTP dump for turn %s: %s", turnID, file.Name())
+	return &httpDump{file: file}
+}
+
+func (d *httpDump) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return len(p), nil
+	}
+	return d.file.Write(p)
+}
+
+func (d *httpDump) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file != nil {
+		_ = d.file.Close()
+		d.file = nil
+	}
+}
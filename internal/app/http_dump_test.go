@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppDumpsProviderTrafficPerTurn(t *testing.T) {
+	frame := `{"message":{"role":"assistant","content":"pong"},"done":true}` + "\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, frame)
+	}))
+	defer server.Close()
+
+	run := func(t *testing.T, dump bool) []string {
+		t.Helper()
+		home := t.TempDir()
+		store := &stubStore{cfg: config.Config{
+			Models:        []config.Model{{Name: "llama3.2", Provider: "ollama", BaseURL: server.URL, Active: true}},
+			DebugHTTPDump: dump,
+		}}
+		var output bytes.Buffer
+		instance, err := app.New(app.Options{
+			Store:          store,
+			Factory:        llm.NewFactory(server.Client()),
+			Input:          strings.NewReader(""),
+			Output:         &output,
+			HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+			HomeDir:        home,
+			MCP:            &stubMCP{},
+			Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer instance.Close()
+		for _, message := range []string{"ping", "ping again"} {
+			if err := instance.Ask(context.Background(), message); err != nil {
+				t.Fatalf("Ask() error = %v", err)
+			}
+		}
+		files, _ := filepath.Glob(filepath.Join(home, ".humble-ai-cli", "logs", "http-*.log"))
+		return files
+	}
+
+	files := run(t, true)
+	if len(files) != 2 {
+		t.Fatalf("expected one dump file per turn, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read dump: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(files[0]), "http-20250102-030405-") {
+		t.Fatalf("expected the file to be named after the turn, got %s", files[0])
+	}
+	for _, phrase := range []string{">>> POST " + server.URL + "/api/chat\n", `"content":"ping"`, "<<< 200 OK\n", frame} {
+		if !strings.Contains(string(data), phrase) {
+			t.Fatalf("expected dump to contain %q, got:\n%s", phrase, data)
+		}
+	}
+
+	if files := run(t, false); len(files) != 0 {
+		t.Fatalf("expected no dump files without debugHttpDump, got %v", files)
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/injection"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// scanToolResult flags or escapes instruction-like content in an MCP result before it is sent to the model.
+func (a *App) scanToolResult(call *llm.ToolCall, content string) string {
+	a.cfgMu.RLock()
+	mode := a.cfg.EffectiveInjectionScan()
+	a.cfgMu.RUnlock()
+	if mode == config.InjectionScanOff {
+		return content
+	}
+
+	findings := injection.Scan(content)
+	if len(findings) == 0 {
+		return content
+	}
+
+	rules := strings.Join(injection.Rules(findings), ", ")
+	a.logDebug("MCP result flagged for prompt injection: server=%s method=%s rules=%s", call.Server, call.Method, rules)
+	warning := fmt.Sprintf("!! Possible prompt injection in %s.%s result (%s)", call.Server, call.Method, rules)
+	if a.color {
+		warning = "\x1b[1;33m" + warning + "\x1b[0m"
+	}
+	fmt.Fprintln(a.output, warning)
+
+	if mode == config.InjectionScanEscape {
+		fmt.Fprintln(a.output, "Suspicious spans were escaped before sending the result to the model.")
+		return injection.Escape(content, findings)
+	}
+	fmt.Fprintln(a.output, "The result was sent with an untrusted-content notice.")
+	return injection.Flag(content, findings)
+}
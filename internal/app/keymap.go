@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// editAction is a line editor operation that can be bound to a control key.
+type editAction string
+
+const (
+	actionMoveLeft  editAction = "move-left"
+	actionMoveRight editAction = "move-right"
+	actionHome      editAction = "home"
+	actionEnd       editAction = "end"
+	actionBackspace editAction = "backspace"
+	actionDelete    editAction = "delete"
+	actionClearLine editAction = "clear-line"
+	actionKillToEnd editAction = "kill-to-end"
+	actionInterrupt editAction = "interrupt"
+	actionEOF       editAction = "eof"
+)
+
+// keymap maps control bytes (Ctrl+A = 0x01 … Ctrl+Z = 0x1a) to editor actions.
+type keymap map[byte]editAction
+
+func defaultKeymap() keymap {
+	return keymap{
+		ctrlKey('a'): actionHome,
+		ctrlKey('e'): actionEnd,
+		ctrlKey('b'): actionMoveLeft,
+		ctrlKey('f'): actionMoveRight,
+		ctrlKey('h'): actionBackspace,
+		ctrlKey('u'): actionClearLine,
+		ctrlKey('k'): actionKillToEnd,
+		ctrlKey('c'): actionInterrupt,
+		ctrlKey('d'): actionEOF,
+	}
+}
+
+func ctrlKey(letter byte) byte {
+	return letter - 'a' + 1
+}
+
+// buildKeymap applies action → key overrides such as {"home": "ctrl+g"} to the defaults.
+// Rebinding an action removes its default key, which then inserts nothing.
+func buildKeymap(bindings map[string]string) (keymap, error) {
+	km := defaultKeymap()
+	if len(bindings) == 0 {
+		return km, nil
+	}
+	overrides := make(map[byte]editAction, len(bindings))
+	for action, key := range bindings {
+		b, err := parseKeyBinding(key)
+		if err != nil {
+			return nil, fmt.Errorf("keybinding %q: %w", action, err)
+		}
+		act := editAction(strings.ToLower(strings.TrimSpace(action)))
+		for k, existing := range km {
+			if existing == act {
+				delete(km, k)
+			}
+		}
+		overrides[b] = act
+	}
+	for b, act := range overrides {
+		km[b] = act
+	}
+	return km, nil
+}
+
+// parseKeyBinding accepts "ctrl+<letter>" (Enter and Tab cannot be rebound).
+func parseKeyBinding(key string) (byte, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(key), " ", ""))
+	letter, ok := strings.CutPrefix(normalized, "ctrl+")
+	if !ok {
+		letter, ok = strings.CutPrefix(normalized, "ctrl-")
+	}
+	if !ok || len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+		return 0, fmt.Errorf("unsupported key %q (use ctrl+<letter>)", key)
+	}
+	switch letter[0] {
+	case 'i', 'j', 'm':
+		return 0, fmt.Errorf("key %q is reserved for Tab/Enter", key)
+	}
+	return ctrlKey(letter[0]), nil
+}
+
+// apply runs action against buffer and reports whether the line must be redrawn.
+func (action editAction) apply(buffer *lineBuffer) bool {
+	switch action {
+	case actionMoveLeft:
+		return buffer.MoveLeft()
+	case actionMoveRight:
+		return buffer.MoveRight()
+	case actionHome:
+		return buffer.MoveHome()
+	case actionEnd:
+		return buffer.MoveEnd()
+	case actionBackspace:
+		return buffer.Backspace()
+	case actionDelete:
+		return buffer.Delete()
+	case actionClearLine:
+		return buffer.Clear()
+	case actionKillToEnd:
+		return buffer.KillToEnd()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func editBytes(t *testing.T, keys keymap, input string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	r := &interactiveLineReader{output: &out, keys: keys}
+	return r.edit(bufio.NewReader(bytes.NewReader([]byte(input))), "> ")
+}
+
+func TestLineEditorDefaultControlKeys(t *testing.T) {
+	keys := defaultKeymap()
+	cases := map[string]string{
+		"abc\x01X\r":         "Xabc", // Ctrl+A then insert
+		"abc\x01\x05Y\r":     "abcY", // Ctrl+A, Ctrl+E
+		"abc\x02\x02\x0bZ\r": "aZ",   // Ctrl+B twice, Ctrl+K
+		"abc\x15ok\r":        "ok",   // Ctrl+U
+		"ab\x07c\r":          "abc",  // unbound Ctrl+G is ignored
+		"abc\x08\r":          "ab",   // Ctrl+H
+	}
+	for input, want := range cases {
+		got, err := editBytes(t, keys, input)
+		if err != nil || got != want {
+			t.Errorf("edit(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestLineEditorRemappedKeys(t *testing.T) {
+	keys, err := buildKeymap(map[string]string{"home": "ctrl+g", "interrupt": "ctrl+q"})
+	if err != nil {
+		t.Fatalf("buildKeymap() error = %v", err)
+	}
+	if got, _ := editBytes(t, keys, "abc\x07X\x01\r"); got != "Xabc" {
+		t.Fatalf("expected ctrl+g to move home and ctrl+a to be unbound, got %q", got)
+	}
+	if _, err := editBytes(t, keys, "ab\x11"); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected ctrl+q to interrupt, got %v", err)
+	}
+	if got, _ := editBytes(t, keys, "a\x03b\r"); got != "ab" {
+		t.Fatalf("expected ctrl+c to be unbound after remapping, got %q", got)
+	}
+}
+
+func TestBuildKeymapRejectsUnsupportedKeys(t *testing.T) {
+	for _, key := range []string{"alt+a", "ctrl+m", "ctrl+1", "a"} {
+		if _, err := buildKeymap(map[string]string{"home": key}); err == nil {
+			t.Fatalf("expected error for %q", key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+const languageInstruction = "The user is writing in %s. Respond in %s unless the user asks for another language."
+
+// minScriptShare is the share of letters a non-Latin script needs before it names the
+// language, so a Korean question full of English identifiers still counts as Korean.
+const minScriptShare = 0.25
+
+var codeSpanPattern = regexp.MustCompile("(?s)```.*?(```|$)|`[^`\n]*`")
+
+// scriptLanguages names the language written in each script. Kana is checked before Han
+// because Japanese mixes both.
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "Korean"},
+	{unicode.Hiragana, "Japanese"},
+	{unicode.Katakana, "Japanese"},
+	{unicode.Han, "Chinese"},
+	{unicode.Cyrillic, "Russian"},
+	{unicode.Greek, "Greek"},
+	{unicode.Arabic, "Arabic"},
+	{unicode.Hebrew, "Hebrew"},
+	{unicode.Thai, "Thai"},
+	{unicode.Devanagari, "Hindi"},
+}
+
+// detectLanguage guesses the language of a message from its script, ignoring code. It
+// returns "" for Latin-script text, where the script alone cannot tell languages apart.
+func detectLanguage(text string) string {
+	text = codeSpanPattern.ReplaceAllString(text, " ")
+	letters := 0
+	counts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, entry := range scriptLanguages {
+			if unicode.Is(entry.script, r) {
+				counts[entry.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	if counts["Japanese"] > 0 {
+		counts["Japanese"] += counts["Chinese"]
+		counts["Chinese"] = 0
+	}
+	best, bestCount := "", 0
+	for _, entry := range scriptLanguages {
+		if n := counts[entry.language]; n > bestCount {
+			best, bestCount = entry.language, n
+		}
+	}
+	if float64(bestCount) < minScriptShare*float64(letters) {
+		return ""
+	}
+	return best
+}
+
+// withLanguageRule asks the model to answer in the language of the message when
+// matchInputLanguage is on and the language can be told from the script.
+func (a *App) withLanguageRule(systemPrompt, message string, enabled bool) string {
+	if !enabled {
+		return systemPrompt
+	}
+	language := detectLanguage(message)
+	if language == "" {
+		return systemPrompt
+	}
+	a.logDebug("Detected input language: %s", language)
+	instruction := fmt.Sprintf(languageInstruction, language, language)
+	if systemPrompt == "" {
+		return instruction
+	}
+	return systemPrompt + "\n\n" + instruction
+}
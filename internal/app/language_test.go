@@ -0,0 +1,75 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppMatchInputLanguageAddsResponseLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		message string
+		want    string
+	}{
+		{"korean", true, "Go 에서 context 를 어떻게 취소하나요?", "Respond in Korean"},
+		{"japanese", true, "このエラーの原因は何ですか？", "Respond in Japanese"},
+		{"chinese", true, "这个错误是什么意思？", "Respond in Chinese"},
+		{"english", true, "How do I cancel a context in Go?", ""},
+		{"code is ignored", true, "설명해줘:\n```go\nfunc main() { ctx, cancel := context.WithCancel(context.Background()); defer cancel() }\n```", "Respond in Korean"},
+		{"disabled", false, "Go 에서 context 를 어떻게 취소하나요?", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			store := &stubStore{cfg: config.Config{
+				Models:             []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+				MatchInputLanguage: tt.enabled,
+			}}
+			provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+			factory := newStubFactory()
+			factory.Register("stub-model", provider)
+
+			var output bytes.Buffer
+			instance, err := app.New(app.Options{
+				Store:          store,
+				Factory:        factory,
+				Input:          strings.NewReader(""),
+				Output:         &output,
+				ErrorOutput:    &output,
+				HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+				HomeDir:        home,
+				MCP:            &stubMCP{},
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer instance.Close()
+			if err := instance.Ask(context.Background(), tt.message); err != nil {
+				t.Fatalf("Ask() error = %v", err)
+			}
+
+			reqs := provider.Requests()
+			if len(reqs) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(reqs))
+			}
+			prompt := reqs[0].SystemPrompt
+			if tt.want == "" {
+				if strings.Contains(prompt, "Respond in") {
+					t.Fatalf("expected no language instruction, got %q", prompt)
+				}
+				return
+			}
+			if !strings.Contains(prompt, tt.want) {
+				t.Fatalf("expected %q in the system prompt, got %q", tt.want, prompt)
+			}
+		})
+	}
+}
@@ -77,6 +77,23 @@ func (b *lineBuffer) Delete() bool {
 	return true
 }
 
+func (b *lineBuffer) Clear() bool {
+	if len(b.runes) == 0 {
+		return false
+	}
+	b.runes = b.runes[:0]
+	b.cursor = 0
+	return true
+}
+
+func (b *lineBuffer) KillToEnd() bool {
+	if b.cursor >= len(b.runes) {
+		return false
+	}
+	b.runes = b.runes[:b.cursor]
+	return true
+}
+
 func (b *lineBuffer) String() string {
 	return string(b.runes)
 }
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	mcpkg "github.com/gamzabox/humble-ai-cli/internal/mcp"
+)
+
+// mcpLogLevel maps the configured logLevel to the lowest MCP severity worth requesting.
+func mcpLogLevel(level string) string {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return "debug"
+	case "warn":
+		return "warning"
+	case "error":
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// handleMCPLog writes a server logging notification to the log file and, with
+// mcpLogEcho, prints warnings and errors to the terminal.
+func (a *App) handleMCPLog(msg mcpkg.LogMessage) {
+	source := msg.Server
+	if msg.Logger != "" {
+		source += "/" + msg.Logger
+	}
+	line := fmt.Sprintf("[mcp:%s] %s", source, msg.Text)
+
+	severe := false
+	switch msg.Level {
+	case "debug":
+		a.logger.Debugf("%s", line)
+	case "info", "notice":
+		a.logger.Infof("%s", line)
+	case "warning":
+		a.logger.Warnf("%s", line)
+		severe = true
+	default:
+		a.logger.Errorf("%s", line)
+		severe = true
+	}
+
+	a.cfgMu.RLock()
+	echo := a.cfg.MCPLogEcho
+	a.cfgMu.RUnlock()
+	if echo && severe {
+		fmt.Fprintf(a.errOutput, "[mcp:%s] %s: %s\n", source, msg.Level, msg.Text)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/logging"
+	mcpkg "github.com/gamzabox/humble-ai-cli/internal/mcp"
+)
+
+func TestHandleMCPLogWritesLogFileAndEchoesWarnings(t *testing.T) {
+	home := t.TempDir()
+	logger, err := logging.NewLogger(home, "info")
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	var errOut bytes.Buffer
+	a := &App{engine: &engine{logger: logger, cfg: config.Config{MCPLogEcho: true}}, errOutput: &errOut}
+
+	a.handleMCPLog(mcpkg.LogMessage{Server: "docs", Level: "debug", Text: "cache miss"})
+	a.handleMCPLog(mcpkg.LogMessage{Server: "docs", Level: "info", Text: "indexed 12 files"})
+	a.handleMCPLog(mcpkg.LogMessage{Server: "docs", Level: "warning", Logger: "index", Text: "slow disk"})
+
+	files, _ := filepath.Glob(filepath.Join(home, ".humble-ai-cli", "logs", "*.log"))
+	if len(files) != 1 {
+		t.Fatalf("expected one log file, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	logged := string(data)
+	if !strings.Contains(logged, "[INFO] [mcp:docs] indexed 12 files") || !strings.Contains(logged, "[WARN] [mcp:docs/index] slow disk") {
+		t.Fatalf("unexpected log contents:\n%s", logged)
+	}
+	if strings.Contains(logged, "cache miss") {
+		t.Fatalf("debug message should be filtered at info level:\n%s", logged)
+	}
+
+	if got, want := errOut.String(), "[mcp:docs/index] warning: slow disk\n"; got != want {
+		t.Fatalf("terminal echo = %q, want %q", got, want)
+	}
+}
+
+func TestMCPLogLevelMapsConfigLevels(t *testing.T) {
+	cases := map[string]string{"": "info", "debug": "debug", "WARN": "warning", "error": "error"}
+	for in, want := range cases {
+		if got := mcpLogLevel(in); got != want {
+			t.Fatalf("mcpLogLevel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
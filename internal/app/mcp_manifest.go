@@ -0,0 +1,108 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// toolManifest is mcp-tools.json: per server, the functions listed by the last /mcp and a
+// hash of each one's input schema.
+type toolManifest struct {
+	Servers map[string]map[string]string `json:"servers"`
+}
+
+// toolChanges is how a server's functions differ from the manifest.
+type toolChanges struct {
+	added   map[string]bool
+	changed map[string]bool
+	removed []string
+}
+
+func (c toolChanges) empty() bool {
+	return len(c.added) == 0 && len(c.changed) == 0 && len(c.removed) == 0
+}
+
+func (a *App) toolManifestPath() string {
+	return filepath.Join(config.Dir(a.homeDir), "mcp-tools.json")
+}
+
+func (a *App) loadToolManifest() (toolManifest, error) {
+	manifest := toolManifest{Servers: map[string]map[string]string{}}
+	data, err := os.ReadFile(a.toolManifestPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("parse %s: %w", a.toolManifestPath(), err)
+	}
+	if manifest.Servers == nil {
+		manifest.Servers = map[string]map[string]string{}
+	}
+	return manifest, nil
+}
+
+func (a *App) saveToolManifest(manifest toolManifest) error {
+	if err := os.MkdirAll(filepath.Dir(a.toolManifestPath()), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(a.toolManifestPath(), append(data, '\n'), 0o644)
+}
+
+// schemaHash identifies a function's input schema; map keys marshal in sorted order, so
+// equal schemas hash equally.
+func schemaHash(fn MCPFunction) string {
+	data, err := json.Marshal(fn.Parameters)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// manifestEntry records the schema hash of each function.
+func manifestEntry(tools []MCPFunction) map[string]string {
+	entry := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		entry[tool.Name] = schemaHash(tool)
+	}
+	return entry
+}
+
+// diffTools compares a server's functions with its manifest entry.
+func diffTools(previous map[string]string, tools []MCPFunction) toolChanges {
+	changes := toolChanges{added: map[string]bool{}, changed: map[string]bool{}}
+	current := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		current[tool.Name] = true
+		hash, ok := previous[tool.Name]
+		switch {
+		case !ok:
+			changes.added[tool.Name] = true
+		case hash != schemaHash(tool):
+			changes.changed[tool.Name] = true
+		}
+	}
+	for name := range previous {
+		if !current[name] {
+			changes.removed = append(changes.removed, name)
+		}
+	}
+	sort.Strings(changes.removed)
+	return changes
+}
@@ -0,0 +1,92 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestAppMCPCommandMarksToolChangesSinceLastListing(t *testing.T) {
+	home := t.TempDir()
+	listTools := func(t *testing.T, toolset map[string][]app.MCPFunction) string {
+		t.Helper()
+		var output bytes.Buffer
+		instance, err := app.New(app.Options{
+			Store:          &stubStore{cfg: config.Config{DisableHints: true}},
+			Factory:        newStubFactory(),
+			Input:          strings.NewReader("/mcp\n/exit\n"),
+			Output:         &output,
+			HistoryRootDir: filepath.Join(home, "sessions"),
+			HomeDir:        home,
+			MCP: &stubMCP{
+				servers: []app.MCPServer{{Name: "calculator"}, {Name: "docs"}},
+				toolset: toolset,
+			},
+			Clock: fixedClock(time.Now()),
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := instance.Run(context.Background()); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return output.String()
+	}
+	number := map[string]any{"type": "number"}
+	twoNumbers := map[string]any{"type": "object", "properties": map[string]any{"a": number, "b": number}}
+
+	first := listTools(t, map[string][]app.MCPFunction{
+		"calculator": {
+			{Name: "add", Description: "Add two numbers.", Parameters: twoNumbers},
+			{Name: "divide", Description: "Divide.", Parameters: twoNumbers},
+			{Name: "subtract", Description: "Subtract.", Parameters: twoNumbers},
+		},
+		"docs": {{Name: "search", Description: "Search docs."}},
+	})
+	if strings.Contains(first, "[added]") || strings.Contains(first, "since the last /mcp") {
+		t.Fatalf("expected no markers on the first listing, got:\n%s", first)
+	}
+
+	withScale := map[string]any{"type": "object", "properties": map[string]any{"a": number, "b": number, "scale": number}}
+	second := listTools(t, map[string][]app.MCPFunction{
+		"calculator": {
+			{Name: "add", Description: "Add two numbers, faster.", Parameters: twoNumbers},
+			{Name: "multiply", Description: "Multiply.", Parameters: twoNumbers},
+			{Name: "subtract", Description: "Subtract.", Parameters: withScale},
+		},
+		"docs": {{Name: "search", Description: "Search docs."}},
+	})
+	for _, phrase := range []string{
+		"  - add: Add two numbers, faster.\n",
+		"  - multiply: Multiply. [added]\n",
+		"  - subtract: Subtract. [schema changed]\n",
+		"  - divide [removed]\n",
+		"  (1 added, 1 removed, 1 schema changed since the last /mcp)\n",
+		"  - search: Search docs.\n",
+	} {
+		if !strings.Contains(second, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, second)
+		}
+	}
+	if strings.Count(second, "since the last /mcp") != 1 {
+		t.Fatalf("expected only calculator to report changes, got:\n%s", second)
+	}
+
+	third := listTools(t, map[string][]app.MCPFunction{
+		"calculator": {
+			{Name: "add", Description: "Add two numbers, faster.", Parameters: twoNumbers},
+			{Name: "multiply", Description: "Multiply.", Parameters: twoNumbers},
+			{Name: "subtract", Description: "Subtract.", Parameters: withScale},
+		},
+		"docs": {{Name: "search", Description: "Search docs."}},
+	})
+	if strings.Contains(third, "[") {
+		t.Fatalf("expected the manifest to be updated after the listing, got:\n%s", third)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// showMCPStatus prints what each enabled MCP server, or the named one, advertised in its
+// handshake: /mcp-status [server]. Servers not connected yet are connected first.
+func (a *App) showMCPStatus(ctx context.Context, args []string) error {
+	if a.mcp == nil {
+		fmt.Fprintln(a.output, "MCP integration is not configured.")
+		return nil
+	}
+	reporter, ok := a.mcp.(MCPStatusReporter)
+	if !ok {
+		fmt.Fprintln(a.output, "Server status is not available for this MCP executor.")
+		return nil
+	}
+	names := a.sortedMCPServerNames()
+	if len(args) > 0 {
+		a.mcpMu.RLock()
+		_, enabled := a.mcpServers[args[0]]
+		a.mcpMu.RUnlock()
+		if !enabled {
+			fmt.Fprintf(a.output, "No enabled MCP server named %q; /mcp lists them.\n", args[0])
+			return nil
+		}
+		names = []string{args[0]}
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(a.output, "No MCP servers are currently enabled.")
+		return nil
+	}
+
+	for _, name := range names {
+		status, err := reporter.Status(ctx, name)
+		if err != nil {
+			fmt.Fprintf(a.output, "%s: cannot connect: %v\n", name, err)
+			continue
+		}
+		server := strings.TrimSpace(status.Implementation + " " + status.Version)
+		if server == "" {
+			server = "unnamed server"
+		}
+		if status.ProtocolVersion != "" {
+			server += ", protocol " + status.ProtocolVersion
+		}
+		fmt.Fprintf(a.output, "%s: %s\n", name, server)
+		capabilities := strings.Join(status.Capabilities, ", ")
+		if capabilities == "" {
+			capabilities = "none"
+		}
+		fmt.Fprintf(a.output, "  Capabilities: %s\n", capabilities)
+		for _, warning := range status.Warnings {
+			fmt.Fprintf(a.output, "  Warning: %s\n", warning)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+)
+
+type statusMCP struct {
+	*stubMCP
+	statuses map[string]app.MCPServerStatus
+}
+
+func (s *statusMCP) Status(_ context.Context, server string) (app.MCPServerStatus, error) {
+	status, ok := s.statuses[server]
+	if !ok {
+		return app.MCPServerStatus{}, errors.New("exec: \"missing-server\": executable file not found")
+	}
+	return status, nil
+}
+
+func TestAppMCPStatusShowsVersionsCapabilitiesAndWarnings(t *testing.T) {
+	mcp := &statusMCP{
+		stubMCP: &stubMCP{servers: []app.MCPServer{{Name: "github"}, {Name: "broken"}, {Name: "bare"}}},
+		statuses: map[string]app.MCPServerStatus{
+			"github": {
+				Name:            "github",
+				Implementation:  "github-mcp",
+				Version:         "1.4.0",
+				ProtocolVersion: "2025-06-18",
+				Capabilities:    []string{"tools (listChanged)", "logging"},
+			},
+			"bare": {Name: "bare", Warnings: []string{"no tools capability: the model gets no functions from this server"}},
+		},
+	}
+	output, _, _ := runCallSession(t, mcp, "/mcp-status\n/mcp-status github\n/mcp-status nope\n/exit\n")
+
+	for _, phrase := range []string{
+		"bare: unnamed server\n  Capabilities: none\n  Warning: no tools capability: the model gets no functions from this server\n" +
+			"broken: cannot connect: exec: \"missing-server\": executable file not found\n" +
+			"github: github-mcp 1.4.0, protocol 2025-06-18\n  Capabilities: tools (listChanged), logging\n",
+		"No enabled MCP server named \"nope\"; /mcp lists them.",
+	} {
+		if !strings.Contains(output, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, output)
+		}
+	}
+	if strings.Count(output, "github: github-mcp 1.4.0") != 2 {
+		t.Fatalf("expected /mcp-status github to print the server again, got:\n%s", output)
+	}
+}
+
+func TestAppMCPStatusNeedsReportingExecutor(t *testing.T) {
+	output, _, _ := runCallSession(t, &stubMCP{servers: []app.MCPServer{{Name: "docs"}}}, "/mcp-status\n/exit\n")
+	if !strings.Contains(output, "Server status is not available for this MCP executor.") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+}
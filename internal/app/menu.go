@@ -0,0 +1,212 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// menuSelector is implemented by readers that can let the user pick from a list with the
+// arrow keys instead of typing its number.
+type menuSelector interface {
+	// selectItem returns the index of the chosen item, or -1 when the menu was cancelled.
+	selectItem(title string, items []string, initial int) (int, error)
+}
+
+// menuKey is a key press the menu reacts to.
+type menuKey int
+
+const (
+	menuNone menuKey = iota
+	menuUp
+	menuDown
+	menuFirst
+	menuLast
+	menuSelect
+	menuCancel
+)
+
+func (r *interactiveLineReader) selectItem(title string, items []string, initial int) (int, error) {
+	fd := int(r.input.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return -1, err
+	}
+	defer func() {
+		_ = term.Restore(fd, oldState)
+	}()
+
+	return r.menu(r.reader, title, items, initial)
+}
+
+// menu runs a selection menu over raw terminal input. Up/Down (or k/j) move the
+// highlight, Enter picks it, a digit picks that item directly, and Esc, q, 0 or the
+// interrupt key cancel.
+func (r *interactiveLineReader) menu(reader *bufio.Reader, title string, items []string, initial int) (int, error) {
+	cursor := initial
+	if cursor < 0 || cursor >= len(items) {
+		cursor = 0
+	}
+	fmt.Fprintf(r.output, "%s\r\n", title)
+	renderMenu(r.output, items, cursor)
+	fmt.Fprint(r.output, "  (↑/↓ to move, Enter to select, Esc to cancel)")
+
+	finish := func(choice int) (int, error) {
+		// Replace the key help with the final state of the list.
+		fmt.Fprintf(r.output, "\r\x1b[K\x1b[%dA", len(items))
+		renderMenu(r.output, items, choice)
+		return choice, nil
+	}
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			fmt.Fprint(r.output, "\r\n")
+			return -1, err
+		}
+		if b >= '1' && b <= '9' {
+			if n := int(b - '0'); n <= len(items) {
+				return finish(n - 1)
+			}
+			continue
+		}
+		switch r.menuKey(b, reader) {
+		case menuUp:
+			if cursor > 0 {
+				cursor--
+			}
+		case menuDown:
+			if cursor < len(items)-1 {
+				cursor++
+			}
+		case menuFirst:
+			cursor = 0
+		case menuLast:
+			cursor = len(items) - 1
+		case menuSelect:
+			return finish(cursor)
+		case menuCancel:
+			return finish(-1)
+		default:
+			continue
+		}
+		fmt.Fprintf(r.output, "\r\x1b[%dA", len(items))
+		renderMenu(r.output, items, cursor)
+	}
+}
+
+func (r *interactiveLineReader) menuKey(b byte, reader *bufio.Reader) menuKey {
+	if action, ok := r.keys[b]; ok {
+		switch action {
+		case actionInterrupt, actionEOF:
+			return menuCancel
+		case actionHome:
+			return menuFirst
+		case actionEnd:
+			return menuLast
+		}
+		return menuNone
+	}
+	switch b {
+	case '\r', '\n':
+		return menuSelect
+	case 'k':
+		return menuUp
+	case 'j':
+		return menuDown
+	case 'q', '0':
+		return menuCancel
+	case 0x1b:
+		// A lone Esc arrives by itself; escape sequences arrive in one read.
+		if reader.Buffered() == 0 {
+			return menuCancel
+		}
+		next, err := reader.ReadByte()
+		if err != nil || (next != '[' && next != 'O') {
+			return menuNone
+		}
+		seq, err := readCSISequence(reader)
+		if err != nil {
+			return menuNone
+		}
+		switch seq {
+		case "A":
+			return menuUp
+		case "B":
+			return menuDown
+		case "H", "1~", "7~":
+			return menuFirst
+		case "F", "4~", "8~":
+			return menuLast
+		}
+	case 0x00, 0xe0:
+		if runtime.GOOS != "windows" {
+			return menuNone
+		}
+		code, err := reader.ReadByte()
+		if err != nil {
+			return menuNone
+		}
+		switch code {
+		case 0x48: // Up arrow
+			return menuUp
+		case 0x50: // Down arrow
+			return menuDown
+		case 0x47: // Home
+			return menuFirst
+		case 0x4f: // End
+			return menuLast
+		}
+	}
+	return menuNone
+}
+
+// renderMenu draws the items, each on its own cleared line, with the cursor marked. Raw
+// mode needs explicit carriage returns.
+func renderMenu(output io.Writer, items []string, cursor int) {
+	for idx, item := range items {
+		marker := "  "
+		if idx == cursor {
+			marker = "> "
+		}
+		fmt.Fprintf(output, "\r\x1b[K%s%d) %s\r\n", marker, idx+1, item)
+	}
+}
+
+// choose asks the user to pick one of items. On a terminal it shows an arrow-key menu;
+// otherwise it prints the numbered list under title and reads a number after prompt. It
+// returns the 1-based choice, 0 when cancelled or left empty, and -1 for invalid input,
+// which it has already reported.
+func (a *App) choose(title string, items []string, initial int, prompt string) (int, error) {
+	if selector, ok := a.lineReader.(menuSelector); ok && a.pendingLine == nil {
+		idx, err := selector.selectItem(title, items, initial)
+		if err != nil {
+			return 0, err
+		}
+		a.recordInput(prompt, strconv.Itoa(idx+1))
+		return idx + 1, nil
+	}
+
+	fmt.Fprintln(a.output, title)
+	for idx, item := range items {
+		fmt.Fprintf(a.output, "  %d) %s\n", idx+1, item)
+	}
+	line, err := a.readLine(prompt)
+	if err != nil {
+		return 0, err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return 0, nil
+	}
+	choice, err := strconv.Atoi(line)
+	if err != nil || choice < 0 || choice > len(items) {
+		fmt.Fprintln(a.output, "Invalid selection.")
+		return -1, nil
+	}
+	return choice, nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runMenu(t *testing.T, input string, initial int) (int, string) {
+	t.Helper()
+	var out bytes.Buffer
+	r := &interactiveLineReader{output: &out, keys: defaultKeymap()}
+	choice, err := r.menu(bufio.NewReader(strings.NewReader(input)), "Pick one:", []string{"alpha", "beta", "gamma"}, initial)
+	if err != nil {
+		t.Fatalf("menu(%q) error = %v", input, err)
+	}
+	return choice, out.String()
+}
+
+func TestMenuSelectsWithArrowKeys(t *testing.T) {
+	cases := []struct {
+		input   string
+		initial int
+		want    int
+	}{
+		{"\r", 0, 0},
+		{"\r", 2, 2},
+		{"\x1b[B\r", 0, 1},
+		{"\x1b[B\x1b[B\x1b[B\r", 0, 2}, // stops at the last item
+		{"\x1b[A\r", 0, 0},             // stops at the first item
+		{"\x1b[A\r", 2, 1},
+		{"jjk\r", 0, 1},
+		{"\x05\r", 0, 2}, // end key binding
+		{"\x1b[H\r", 2, 0},
+		{"3", 0, 2},
+		{"7\r", 1, 1}, // digits past the list are ignored
+	}
+	for _, tc := range cases {
+		if got, _ := runMenu(t, tc.input, tc.initial); got != tc.want {
+			t.Errorf("menu(%q, initial %d) = %d; want %d", tc.input, tc.initial, got, tc.want)
+		}
+	}
+}
+
+func TestMenuCancels(t *testing.T) {
+	for _, input := range []string{"\x1b", "q", "0", "\x03", "\x1b[B\x04"} {
+		if got, _ := runMenu(t, input, 0); got != -1 {
+			t.Errorf("menu(%q) = %d; want -1", input, got)
+		}
+	}
+}
+
+func TestMenuRendersHighlightedItem(t *testing.T) {
+	_, out := runMenu(t, "\x1b[B\r", 0)
+	if !strings.HasPrefix(out, "Pick one:\r\n\r\x1b[K> 1) alpha\r\n\r\x1b[K  2) beta\r\n") {
+		t.Fatalf("unexpected first frame: %q", out)
+	}
+	if !strings.HasSuffix(out, "\r\x1b[K  1) alpha\r\n\r\x1b[K> 2) beta\r\n\r\x1b[K  3) gamma\r\n") {
+		t.Fatalf("expected the final frame to mark the choice, got %q", out)
+	}
+}
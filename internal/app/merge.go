@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+	"github.com/gamzabox/humble-ai-cli/internal/tokenizer"
+)
+
+const mergeSummaryPrompt = "Summarize the conversation below so it can serve as context for continuing the work in a new conversation. " +
+	"Keep decisions, facts, open questions, file names, identifiers and commands; drop small talk. Answer with concise bullet points only."
+
+// mergeSession appends another saved session to the current conversation: /merge <session>.
+// When the combined history would not fit the model's history budget, the other session
+// is summarized by the active model and only the summary is merged.
+func (a *App) mergeSession(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		fmt.Fprintln(a.output, "Usage: /merge <session>")
+		return nil
+	}
+	path, err := history.Resolve(a.historyRoot, args[0])
+	if err != nil {
+		fmt.Fprintf(a.output, "Cannot merge: %v\n", err)
+		return nil
+	}
+	if current := a.SessionPath(); current != "" && filepath.Clean(current) == filepath.Clean(path) {
+		fmt.Fprintln(a.output, "Cannot merge the current session into itself.")
+		return nil
+	}
+	session, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	if len(session.Messages) == 0 {
+		fmt.Fprintf(a.output, "Session %s has no messages to merge.\n", name)
+		return nil
+	}
+
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	activeModel, hasModel := a.resolveModel(cfg)
+
+	merged := session.Messages
+	summarized := false
+	budget := budgetForModel(activeModel)
+	if hasModel && budget.historyTokens > 0 {
+		needed := countTokens(budget.counter, history.LLMMessages(a.messages)) + countTokens(budget.counter, history.LLMMessages(merged))
+		if needed > budget.historyTokens {
+			fmt.Fprintf(a.output, "%s does not fit the context budget (~%d of %d tokens); summarizing it first...\n", name, needed, budget.historyTokens)
+			summary, err := a.summarizeSession(ctx, activeModel, budget, session)
+			if err != nil {
+				fmt.Fprintf(a.errOutput, "Merge failed: %v\n", err)
+				return nil
+			}
+			now := a.clock.Now()
+			merged = []history.Message{
+				{Role: "user", Content: fmt.Sprintf("Summary of the earlier session %s:\n\n%s", name, summary), Timestamp: now},
+				{Role: "assistant", Content: "Noted. I will use this summary as context.", Timestamp: now},
+			}
+			summarized = true
+		}
+	}
+
+	a.messages = append(a.messages, merged...)
+	a.historyMu.Lock()
+	if a.firstUserInput == "" {
+		a.firstUserInput = session.FirstUserMessage()
+	}
+	saved := a.historyPath != ""
+	a.historyMu.Unlock()
+	if saved && hasModel {
+		if err := a.persistHistory(activeModel, cfg.ActivePersona, a.clock.Now()); err != nil {
+			fmt.Fprintf(a.errOutput, "Failed to persist history: %v\n", err)
+		}
+	}
+
+	if summarized {
+		fmt.Fprintf(a.output, "Merged a summary of %s (%d messages).\n", name, len(session.Messages))
+	} else {
+		fmt.Fprintf(a.output, "Merged %d message(s) from %s.\n", len(merged), name)
+	}
+	return nil
+}
+
+// summarizeSession asks the model for a summary of a saved session. The transcript may
+// use three quarters of the context window and keeps its newest messages when larger.
+func (a *App) summarizeSession(ctx context.Context, model config.Model, budget contextBudget, session history.Session) (string, error) {
+	provider, err := a.factory.Create(model)
+	if err != nil {
+		return "", fmt.Errorf("create provider: %w", err)
+	}
+
+	var parts []string
+	used, limit := 0, model.ContextWindow*3/4
+	for i := len(session.Messages) - 1; i >= 0; i-- {
+		part := transcriptPart(session.Messages[i])
+		cost := budget.counter.Count(part)
+		if used+cost > limit && len(parts) > 0 {
+			break
+		}
+		used += cost
+		parts = append([]string{part}, parts...)
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reqCtx = llm.WithLogger(reqCtx, a.logger)
+	a.enterResponding(cancel)
+	defer a.leaveResponding()
+
+	summary, err := streamText(reqCtx, provider, llm.ChatRequest{
+		Model:        model.Name,
+		SystemPrompt: mergeSummaryPrompt,
+		Messages:     []llm.Message{{Role: "user", Content: strings.Join(parts, "\n\n")}},
+		Stream:       true,
+	})
+	if err != nil {
+		return "", err
+	}
+	if reqCtx.Err() != nil {
+		return "", errors.New("summary cancelled")
+	}
+	text := strings.TrimSpace(summary)
+	if text == "" {
+		return "", errors.New("the model returned an empty summary")
+	}
+	return text, nil
+}
+
+func transcriptPart(msg history.Message) string {
+	var b strings.Builder
+	b.WriteString(roleName(msg.Role))
+	b.WriteString(": ")
+	for _, call := range msg.ToolCalls {
+		b.WriteString("[tool " + render.ToolCallSummary(call) + "]\n")
+	}
+	b.WriteString(strings.TrimSpace(msg.Content))
+	return b.String()
+}
+
+func roleName(role string) string {
+	if role == "" {
+		return role
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
+
+func countTokens(counter tokenizer.Counter, messages []llm.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += counter.Count(msg.Content)
+	}
+	return total
+}
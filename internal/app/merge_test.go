@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func runMerge(t *testing.T, model config.Model, input string, previous []history.Message, chunks []llm.StreamChunk) (*recordingProvider, string) {
+	t.Helper()
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		t.Fatalf("failed to create session dir: %v", err)
+	}
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := history.Save(filepath.Join(sessionDir, "20250101_000000_schema.json"), history.Session{
+		Model:     model.Name,
+		StartedAt: at,
+		Messages:  previous,
+	}); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+
+	store := &stubStore{cfg: config.Config{Models: []config.Model{model}}}
+	provider := &recordingProvider{chunks: chunks}
+	factory := newStubFactory()
+	factory.Register(model.Name, provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(at),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return provider, output.String()
+}
+
+func TestAppMergeAppendsSavedSessionMessages(t *testing.T) {
+	model := config.Model{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}
+	previous := []history.Message{
+		{Role: "user", Content: "design the users table"},
+		{Role: "assistant", Content: "id, email, created_at"},
+	}
+	provider, output := runMerge(t, model, "hello\n/merge 20250101_0000\n/merge nope\nadd an index\n/exit\n", previous,
+		[]llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}})
+
+	for _, want := range []string{"Merged 2 message(s) from 20250101_000000_schema.json.", "Cannot merge: session not found: nope"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q, got:\n%s", want, output)
+		}
+	}
+	requests := provider.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected two requests, got %d", len(requests))
+	}
+	var contents []string
+	for _, msg := range requests[1].Messages {
+		contents = append(contents, msg.Content)
+	}
+	want := []string{"hello", "ok", "design the users table", "id, email, created_at", "add an index"}
+	if strings.Join(contents, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected merged context %q, got %q", want, contents)
+	}
+}
+
+func TestAppMergeSummarizesSessionsOverBudget(t *testing.T) {
+	model := config.Model{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true, ContextWindow: 200}
+	previous := []history.Message{
+		{Role: "user", Content: strings.Repeat("long schema discussion ", 20)},
+		{Role: "assistant", Content: strings.Repeat("detailed answer ", 20)},
+		{Role: "user", Content: "and the orders table?"},
+		{Role: "assistant", Content: strings.Repeat("order columns ", 20)},
+	}
+	provider, output := runMerge(t, model, "/merge 20250101_000000_schema\nnext step?\n/exit\n", previous,
+		[]llm.StreamChunk{{Type: llm.ChunkToken, Content: "- users table agreed"}})
+
+	if !strings.Contains(output, "summarizing it first...") || !strings.Contains(output, "Merged a summary of 20250101_000000_schema.json (4 messages).") {
+		t.Fatalf("expected summarized merge, got:\n%s", output)
+	}
+	requests := provider.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected summary and follow-up requests, got %d", len(requests))
+	}
+	if !strings.Contains(requests[0].SystemPrompt, "Summarize the conversation") || !strings.HasSuffix(requests[0].Messages[0].Content, "User: and the orders table?\n\nAssistant: "+strings.TrimSpace(previous[3].Content)) {
+		t.Fatalf("unexpected summary request: %+v", requests[0])
+	}
+	followUp := requests[1].Messages
+	if len(followUp) != 3 || !strings.Contains(followUp[0].Content, "- users table agreed") {
+		t.Fatalf("expected the summary in the follow-up context, got %+v", followUp)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+// TurnOutcome classifies how the most recent user turn ended.
+type TurnOutcome int
+
+const (
+	// TurnFailed covers turns that never reached the model, e.g. no active model or a rejected message.
+	TurnFailed TurnOutcome = iota
+	// TurnOK means the model answered and the answer was recorded.
+	TurnOK
+	// TurnProviderError means the provider could not be created, refused the request or broke the stream.
+	TurnProviderError
+	// TurnToolFailed means an MCP tool call errored while the model was answering.
+	TurnToolFailed
+	// TurnToolDeclined means the user answered N to a tool call confirmation or let it time out.
+	TurnToolDeclined
+	// TurnCancelled means the response was interrupted, e.g. with CTRL+C.
+	TurnCancelled
+)
+
+func (o TurnOutcome) String() string {
+	switch o {
+	case TurnOK:
+		return "ok"
+	case TurnProviderError:
+		return "provider error"
+	case TurnToolFailed:
+		return "tool call failed"
+	case TurnToolDeclined:
+		return "tool call declined"
+	case TurnCancelled:
+		return "cancelled"
+	default:
+		return "failed"
+	}
+}
+
+// LastOutcome reports how the most recent call to Ask or user message ended.
+func (a *App) LastOutcome() TurnOutcome {
+	a.modeMu.Lock()
+	defer a.modeMu.Unlock()
+	return a.lastOutcome
+}
+
+func (a *App) setOutcome(outcome TurnOutcome) {
+	a.modeMu.Lock()
+	a.lastOutcome = outcome
+	a.modeMu.Unlock()
+}
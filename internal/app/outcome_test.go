@@ -0,0 +1,116 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func askForOutcome(t *testing.T, mode string, provider llm.ChatProvider, mcpExec *stubMCP, input string) app.TurnOutcome {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		ToolCallMode: mode,
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	if mcpExec == nil {
+		mcpExec = &stubMCP{}
+	}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	_ = instance.Ask(context.Background(), "question")
+	return instance.LastOutcome()
+}
+
+func docsToolProvider() *toolRequestProvider {
+	return &toolRequestProvider{
+		call:  llm.ToolCall{Server: "docs", Method: "read", Arguments: map[string]any{"path": "/tmp/x"}},
+		after: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "done"}},
+	}
+}
+
+func docsMCP(err error) *stubMCP {
+	return &stubMCP{
+		servers:       []app.MCPServer{{Name: "docs"}},
+		toolset:       map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		response:      llm.ToolResult{Content: "ok"},
+		responseError: err,
+	}
+}
+
+func TestAppLastOutcomeClassifiesTurns(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		provider llm.ChatProvider
+		mcp      *stubMCP
+		input    string
+		want     app.TurnOutcome
+	}{
+		{
+			name:     "answer",
+			provider: &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hi"}}},
+			want:     app.TurnOK,
+		},
+		{
+			name:     "stream error",
+			provider: &recordingProvider{chunks: []llm.StreamChunk{{Err: errors.New("boom")}}},
+			want:     app.TurnProviderError,
+		},
+		{
+			name:     "tool failed",
+			mode:     "auto",
+			provider: docsToolProvider(),
+			mcp:      docsMCP(errors.New("server crashed")),
+			want:     app.TurnToolFailed,
+		},
+		{
+			name:     "tool declined",
+			mode:     "manual",
+			provider: docsToolProvider(),
+			mcp:      docsMCP(nil),
+			input:    "N\n",
+			want:     app.TurnToolDeclined,
+		},
+		{
+			name:     "tool answered",
+			mode:     "auto",
+			provider: docsToolProvider(),
+			mcp:      docsMCP(nil),
+			want:     app.TurnOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := askForOutcome(t, tt.mode, tt.provider, tt.mcp, tt.input); got != tt.want {
+				t.Fatalf("LastOutcome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
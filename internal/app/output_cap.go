@@ -0,0 +1,58 @@
+package app
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/tokenizer"
+)
+
+// outputCap stops a runaway answer: it admits streamed text, answer and reasoning alike,
+// until maxOutputTokens or maxOutputChars is reached.
+type outputCap struct {
+	maxTokens int
+	maxChars  int
+	counter   tokenizer.Counter
+
+	tokens int
+	chars  int
+	// exceeded names the setting that stopped the answer, e.g. "maxOutputChars (2000)".
+	exceeded string
+}
+
+// newOutputCap returns nil when neither limit is set; the methods of a nil cap admit everything.
+func newOutputCap(cfg config.Config, counter tokenizer.Counter) *outputCap {
+	if cfg.MaxOutputTokens <= 0 && cfg.MaxOutputChars <= 0 {
+		return nil
+	}
+	return &outputCap{maxTokens: cfg.MaxOutputTokens, maxChars: cfg.MaxOutputChars, counter: counter}
+}
+
+// admit returns the part of text within the limits and whether the answer may go on.
+// Characters are cut exactly; tokens are counted per chunk, so the chunk that crosses
+// the token limit is still admitted.
+func (c *outputCap) admit(text string) (string, bool) {
+	if c == nil {
+		return text, true
+	}
+	if c.maxChars > 0 {
+		if left := c.maxChars - c.chars; utf8.RuneCountInString(text) > left {
+			text = string([]rune(text)[:left])
+			c.exceeded = fmt.Sprintf("maxOutputChars (%d)", c.maxChars)
+		}
+		c.chars += utf8.RuneCountInString(text)
+	}
+	if c.maxTokens > 0 {
+		c.tokens += c.counter.Count(text)
+		if c.tokens > c.maxTokens && c.exceeded == "" {
+			c.exceeded = fmt.Sprintf("maxOutputTokens (%d)", c.maxTokens)
+		}
+	}
+	return text, c.exceeded == ""
+}
+
+// marker is appended to the saved answer of a stopped turn.
+func (c *outputCap) marker() string {
+	return fmt.Sprintf("\n\n[answer cut off: exceeded %s]", c.exceeded)
+}
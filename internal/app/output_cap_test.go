@@ -0,0 +1,162 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// runawayProvider repeats a chunk until the request is cancelled, which it reports on stopped.
+type runawayProvider struct {
+	chunk   llm.StreamChunk
+	stopped chan struct{}
+}
+
+func (p runawayProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	out := make(chan llm.StreamChunk)
+	go func() {
+		defer close(out)
+		defer close(p.stopped)
+		for {
+			select {
+			case out <- p.chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func TestAppStopsRunawayAnswerAtOutputCap(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      config.Config
+		chunk    llm.StreamChunk
+		notice   string
+		maxSaved int
+	}{
+		{
+			name:     "characters",
+			cfg:      config.Config{MaxOutputChars: 30},
+			chunk:    llm.StreamChunk{Type: llm.ChunkToken, Content: "all work and no play "},
+			notice:   "Answer stopped: it exceeded maxOutputChars (30); the saved message is truncated.",
+			maxSaved: 30,
+		},
+		{
+			name:   "tokens in reasoning",
+			cfg:    config.Config{MaxOutputTokens: 50},
+			chunk:  llm.StreamChunk{Type: llm.ChunkThinking, Content: "hmm, let me reconsider that once more. "},
+			notice: "Answer stopped: it exceeded maxOutputTokens (50); the saved message is truncated.",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			home := t.TempDir()
+			cfg := tc.cfg
+			cfg.Models = []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}
+			provider := runawayProvider{chunk: tc.chunk, stopped: make(chan struct{})}
+			factory := newStubFactory()
+			factory.Register("stub-model", provider)
+
+			var output bytes.Buffer
+			instance, err := app.New(app.Options{
+				Store:          &stubStore{cfg: cfg},
+				Factory:        factory,
+				Input:          strings.NewReader(""),
+				Output:         &output,
+				ErrorOutput:    &output,
+				HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+				HomeDir:        home,
+				MCP:            &stubMCP{},
+				Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer instance.Close()
+
+			done := make(chan error, 1)
+			go func() { done <- instance.Ask(context.Background(), "question") }()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Ask() error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("the turn did not stop at the output cap")
+			}
+			select {
+			case <-provider.stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the request to be cancelled")
+			}
+
+			if !strings.Contains(output.String(), tc.notice) {
+				t.Fatalf("expected %q, got:\n%s", tc.notice, output.String())
+			}
+			if got := instance.LastOutcome(); got != app.TurnOK {
+				t.Fatalf("expected the truncated answer to be recorded, got %v", got)
+			}
+			session, err := history.Load(instance.SessionPath())
+			if err != nil {
+				t.Fatalf("load session: %v", err)
+			}
+			saved := session.Messages[len(session.Messages)-1].Content
+			answer, marker, ok := strings.Cut(saved, "\n\n[answer cut off: exceeded ")
+			if !ok || !strings.HasSuffix(marker, "]") {
+				t.Fatalf("expected a truncation marker, got %q", saved)
+			}
+			if tc.maxSaved > 0 && (len([]rune(answer)) == 0 || len([]rune(answer)) > tc.maxSaved) {
+				t.Fatalf("expected at most %d characters before the marker, got %q", tc.maxSaved, answer)
+			}
+		})
+	}
+}
+
+func TestAppOutputCapLeavesShortAnswersAlone(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:         []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}},
+		MaxOutputChars: 5,
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hello"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+	if err := instance.Ask(context.Background(), "question"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if strings.Contains(output.String(), "Answer stopped") {
+		t.Fatalf("expected an answer within the cap to pass, got:\n%s", output.String())
+	}
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if got := session.Messages[len(session.Messages)-1].Content; got != "hello" {
+		t.Fatalf("expected the answer unchanged, got %q", got)
+	}
+}
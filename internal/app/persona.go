@@ -0,0 +1,123 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// personaPriming resolves the system prompt and few-shot prelude for the active persona.
+func (a *App) personaPriming(cfg config.Config) (string, []llm.Message, error) {
+	persona, ok := cfg.CurrentPersona()
+	if !ok {
+		return a.systemPrompt, nil, nil
+	}
+
+	systemPrompt := a.systemPrompt
+	if extra := strings.TrimSpace(persona.SystemPrompt); extra != "" {
+		if strings.TrimSpace(systemPrompt) == "" {
+			systemPrompt = extra
+		} else {
+			systemPrompt = systemPrompt + "\n\n" + extra
+		}
+	}
+
+	var prelude []llm.Message
+	if file := strings.TrimSpace(persona.PreludeFile); file != "" {
+		loaded, err := a.loadPreludeFile(file)
+		if err != nil {
+			return "", nil, fmt.Errorf("persona %q: %w", persona.Name, err)
+		}
+		prelude = append(prelude, loaded...)
+	}
+	for _, msg := range persona.Prelude {
+		prelude = append(prelude, llm.Message{
+			Role:    strings.ToLower(strings.TrimSpace(msg.Role)),
+			Content: msg.Content,
+		})
+	}
+	return systemPrompt, prelude, nil
+}
+
+// loadPreludeFile reads user/assistant messages from a template or saved session file.
+func (a *App) loadPreludeFile(path string) ([]llm.Message, error) {
+	if strings.HasPrefix(path, "~/") {
+		path = filepath.Join(a.homeDir, path[2:])
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(config.Dir(a.homeDir), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read prelude file: %w", err)
+	}
+
+	var file struct {
+		Messages []llm.Message `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse prelude file %s: %w", path, err)
+	}
+
+	out := make([]llm.Message, 0, len(file.Messages))
+	for _, msg := range file.Messages {
+		role := strings.ToLower(strings.TrimSpace(msg.Role))
+		if role != "user" && role != "assistant" {
+			continue
+		}
+		out = append(out, llm.Message{Role: role, Content: msg.Content})
+	}
+	return out, nil
+}
+
+func (a *App) setPersona(args []string) error {
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+
+	if len(args) == 0 {
+		if len(cfg.Personas) == 0 {
+			fmt.Fprintf(a.output, "No personas configured. Add entries to %s.\n", a.configFilePath())
+			return nil
+		}
+		fmt.Fprintln(a.output, "Configured personas:")
+		for _, p := range cfg.Personas {
+			marker := ""
+			if strings.TrimSpace(p.Name) == strings.TrimSpace(cfg.ActivePersona) {
+				marker = " *"
+			}
+			fmt.Fprintf(a.output, "  - %s (%d prelude messages)%s\n", strings.TrimSpace(p.Name), len(p.Prelude), marker)
+		}
+		fmt.Fprintln(a.output, "Usage: /persona <name|none>")
+		return nil
+	}
+
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if strings.EqualFold(name, "none") {
+		name = ""
+	} else if _, ok := cfg.FindPersona(name); !ok {
+		fmt.Fprintf(a.output, "Unknown persona: %s\n", name)
+		return nil
+	}
+
+	cfg.ActivePersona = name
+	if err := a.store.Save(cfg); err != nil {
+		return err
+	}
+
+	a.cfgMu.Lock()
+	a.cfg = cfg
+	a.cfgMu.Unlock()
+
+	if name == "" {
+		fmt.Fprintln(a.output, "Persona cleared.")
+		return nil
+	}
+	fmt.Fprintf(a.output, "Active persona set to %s.\n", name)
+	return nil
+}
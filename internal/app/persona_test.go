@@ -0,0 +1,94 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppInjectsPersonaPreludeBeforeConversation(t *testing.T) {
+	home := t.TempDir()
+	configDir := filepath.Join(home, ".humble-ai-cli")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("failed to prepare config dir: %v", err)
+	}
+	session := `{"messages":[{"role":"user","content":"file question"},{"role":"assistant","content":"file answer"}]}`
+	if err := os.WriteFile(filepath.Join(configDir, "prelude.json"), []byte(session), 0o644); err != nil {
+		t.Fatalf("failed to write prelude file: %v", err)
+	}
+
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{
+				{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true},
+			},
+			Personas: []config.Persona{
+				{
+					Name:         "translator",
+					SystemPrompt: "Translate everything.",
+					PreludeFile:  "prelude.json",
+					Prelude: []config.PreludeMessage{
+						{Role: "user", Content: "hello"},
+						{Role: "assistant", Content: "안녕하세요"},
+					},
+				},
+			},
+		},
+	}
+	provider := &recordingProvider{
+		chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "감사합니다"}},
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	input := strings.NewReader("/persona translator\nthank you\n/exit\n")
+	var output bytes.Buffer
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(configDir, "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if store.cfg.ActivePersona != "translator" {
+		t.Fatalf("expected persona to be persisted, got %q", store.cfg.ActivePersona)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	req := requests[0]
+	want := []string{"file question", "file answer", "hello", "안녕하세요", "thank you"}
+	if len(req.Messages) != len(want) {
+		t.Fatalf("expected %d messages, got %#v", len(want), req.Messages)
+	}
+	for i, content := range want {
+		if req.Messages[i].Content != content {
+			t.Fatalf("message %d: expected %q, got %q", i, content, req.Messages[i].Content)
+		}
+	}
+	if !strings.HasSuffix(req.SystemPrompt, "Translate everything.") {
+		t.Fatalf("expected persona system prompt to be appended, got %q", req.SystemPrompt)
+	}
+}
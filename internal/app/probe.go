@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/probe"
+)
+
+func (a *App) probeCache() probe.Cache {
+	return probe.Cache{Path: filepath.Join(config.Dir(a.homeDir), "probes.json")}
+}
+
+// testModel probes the active model with /test-model and caches what it found.
+func (a *App) testModel(ctx context.Context) error {
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	model, ok := a.resolveModel(cfg)
+	if !ok {
+		fmt.Fprintln(a.output, "No active model is configured. Use /set-model to choose a model.")
+		return nil
+	}
+
+	fmt.Fprintf(a.output, "Probing %s for streaming, JSON answers and tool calls...\n", model.Name)
+	result, err := a.runProbe(ctx, model)
+	if err != nil {
+		return fmt.Errorf("probe %s: %w", model.Name, err)
+	}
+	fmt.Fprintf(a.output, "  streaming:   %s\n", yesNo(result.Streaming))
+	fmt.Fprintf(a.output, "  JSON output: %s\n", yesNo(result.JSON))
+	fmt.Fprintf(a.output, "  tool calls:  %s\n", yesNo(result.ToolCalls))
+	switch {
+	case declaresTools(model):
+		fmt.Fprintln(a.output, "The model's supportsTools or toolStrategy setting takes precedence over these results.")
+	case result.ToolCalls:
+		fmt.Fprintln(a.output, "Tools will be offered through the provider's tools API.")
+	case result.JSON:
+		fmt.Fprintln(a.output, "Tools will be described in the system prompt (direct strategy).")
+	default:
+		fmt.Fprintln(a.output, "No tools will be offered to this model.")
+	}
+	return nil
+}
+
+// probedModel applies the cached probe result for model. With probeModels on, a model
+// without a result is probed before its first turn.
+func (a *App) probedModel(ctx context.Context, cfg config.Config, model config.Model) config.Model {
+	if declaresTools(model) {
+		return model
+	}
+	result, ok, err := a.probeCache().Load(probe.Key(model))
+	if err != nil {
+		a.logError("probe cache: %v", err)
+	}
+	if !ok && cfg.ProbeModels {
+		fmt.Fprintf(a.output, "Probing %s before its first use (see /test-model)...\n", model.Name)
+		result, err = a.runProbe(ctx, model)
+		if err != nil {
+			fmt.Fprintf(a.errOutput, "Model probe failed: %v\n", err)
+			return model
+		}
+		ok = true
+	}
+	if !ok {
+		return model
+	}
+	switch {
+	case result.ToolCalls:
+		model.ToolStrategy = string(config.ToolStrategyNative)
+	case result.JSON:
+		model.ToolStrategy = string(config.ToolStrategyDirect)
+	default:
+		no := false
+		model.SupportsTools = &no
+	}
+	a.logDebug("model %s probed: streaming=%t json=%t toolCalls=%t", model.Name, result.Streaming, result.JSON, result.ToolCalls)
+	return model
+}
+
+// runProbe probes model through the tools API and caches the result.
+func (a *App) runProbe(ctx context.Context, model config.Model) (probe.Result, error) {
+	native := model
+	native.ToolStrategy = string(config.ToolStrategyNative)
+	native.SupportsTools = nil
+	provider, err := a.factory.Create(native)
+	if err != nil {
+		return probe.Result{}, err
+	}
+	result, err := probe.Run(ctx, provider, model.Name)
+	if err != nil {
+		return probe.Result{}, err
+	}
+	if err := a.probeCache().Save(probe.Key(model), result); err != nil {
+		a.logError("probe cache: %v", err)
+	}
+	return result, nil
+}
+
+// declaresTools reports whether config.json already decides how the model gets tools.
+func declaresTools(model config.Model) bool {
+	return model.SupportsTools != nil || strings.TrimSpace(model.ToolStrategy) != ""
+}
+
+func yesNo(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,102 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppProbesModelOnFirstUseAndCachesTheResult(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:      []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}},
+		ProbeModels: true,
+	}}
+
+	ask := func() (string, []llm.ChatRequest) {
+		provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "Sure! Here you go."}}}
+		factory := newStubFactory()
+		factory.Register("stub-model", provider)
+		var output bytes.Buffer
+		instance, err := app.New(app.Options{
+			Store:          store,
+			Factory:        factory,
+			Input:          strings.NewReader(""),
+			Output:         &output,
+			ErrorOutput:    &output,
+			HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+			HomeDir:        home,
+			MCP:            docsMCP(nil),
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer instance.Close()
+		if err := instance.Ask(context.Background(), "read the guide"); err != nil {
+			t.Fatalf("Ask() error = %v", err)
+		}
+		return output.String(), provider.Requests()
+	}
+
+	output, requests := ask()
+	if !strings.Contains(output, "Probing stub-model before its first use") {
+		t.Fatalf("expected a probe notice, got:\n%s", output)
+	}
+	if len(requests) != 4 {
+		t.Fatalf("expected three probe requests and the turn, got %d", len(requests))
+	}
+	if turn := requests[3]; len(turn.Tools) != 0 || strings.Contains(turn.SystemPrompt, "docs__read") {
+		t.Fatalf("expected no tools for a model that failed the probe, got tools %+v and system prompt %q", turn.Tools, turn.SystemPrompt)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".humble-ai-cli", "probes.json")); err != nil {
+		t.Fatalf("expected the probe result to be cached: %v", err)
+	}
+
+	output, requests = ask()
+	if strings.Contains(output, "Probing") || len(requests) != 1 || len(requests[0].Tools) != 0 {
+		t.Fatalf("expected the cached result to be reused without probing, got %d requests and:\n%s", len(requests), output)
+	}
+}
+
+func TestAppTestModelReportsProbeResults(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", &recordingProvider{chunks: []llm.StreamChunk{
+		{Type: llm.ChunkToken, Content: `{"ok": true, `},
+		{Type: llm.ChunkToken, Content: `"items": [1, 2, 3]}`},
+	}})
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/test-model\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, want := range []string{"streaming:   yes", "JSON output: yes", "tool calls:  no", "direct strategy"} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("expected %q in the probe report, got:\n%s", want, output.String())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+const defaultPrompt = "humble-ai> "
+
+var promptPlaceholder = regexp.MustCompile(`\{([a-zA-Z]+)\}`)
+
+var promptColors = map[string]string{
+	"reset":   "\x1b[0m",
+	"bold":    "\x1b[1m",
+	"dim":     "\x1b[2m",
+	"red":     "\x1b[31m",
+	"green":   "\x1b[32m",
+	"yellow":  "\x1b[33m",
+	"blue":    "\x1b[34m",
+	"magenta": "\x1b[35m",
+	"cyan":    "\x1b[36m",
+}
+
+// inputPrompt renders the configured prompt template for the current model, persona and tool mode.
+// Color placeholders are dropped when the output is not a color terminal.
+func (a *App) inputPrompt() string {
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	if cfg.Prompt == "" {
+		return defaultPrompt
+	}
+
+	status := a.status()
+	values := map[string]string{
+		"model":    status.Model,
+		"provider": status.Provider,
+		"persona":  status.Persona,
+		"mode":     string(status.ToolMode),
+		"profile":  status.Profile,
+	}
+
+	colored := false
+	rendered := promptPlaceholder.ReplaceAllStringFunc(cfg.Prompt, func(match string) string {
+		name := match[1 : len(match)-1]
+		if code, isColor := promptColors[name]; isColor {
+			if !a.color {
+				return ""
+			}
+			colored = true
+			return code
+		}
+		if value, known := values[name]; known {
+			return value
+		}
+		return match
+	})
+	if colored && !strings.HasSuffix(rendered, promptColors["reset"]) {
+		rendered += promptColors["reset"]
+	}
+	return rendered
+}
@@ -2,11 +2,13 @@ package app
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"golang.org/x/term"
@@ -16,6 +18,11 @@ type lineReader interface {
 	ReadLine(prompt string) (string, error)
 }
 
+// promptPrinter is implemented by readers whose prompt can be printed apart from the read.
+type promptPrinter interface {
+	printPrompt(prompt string) error
+}
+
 type canonicalLineReader struct {
 	reader *bufio.Reader
 	output io.Writer
@@ -29,10 +36,8 @@ func newCanonicalLineReader(input io.Reader, output io.Writer) *canonicalLineRea
 }
 
 func (r *canonicalLineReader) ReadLine(prompt string) (string, error) {
-	if prompt != "" {
-		if _, err := fmt.Fprint(r.output, prompt); err != nil {
-			return "", err
-		}
+	if err := r.printPrompt(prompt); err != nil {
+		return "", err
 	}
 	text, err := r.reader.ReadString('\n')
 	if err != nil {
@@ -42,19 +47,36 @@ func (r *canonicalLineReader) ReadLine(prompt string) (string, error) {
 }
 
 type interactiveLineReader struct {
-	input       *os.File
+	input *os.File
+	// terminal and reader read input for every prompt and menu, so a timed-out prompt
+	// leaves nothing reading behind it.
+	terminal    *terminalInput
+	reader      *bufio.Reader
 	output      io.Writer
+	keys        keymap
 	onInterrupt func()
 }
 
-func newInteractiveLineReader(input *os.File, output io.Writer, onInterrupt func()) *interactiveLineReader {
+func newInteractiveLineReader(input *os.File, output io.Writer, keys keymap, onInterrupt func()) *interactiveLineReader {
+	terminal := newTerminalInput(input)
 	return &interactiveLineReader{
 		input:       input,
+		terminal:    terminal,
+		reader:      bufio.NewReader(terminal),
 		output:      output,
+		keys:        keys,
 		onInterrupt: onInterrupt,
 	}
 }
 
+func (r *canonicalLineReader) printPrompt(prompt string) error {
+	if prompt == "" {
+		return nil
+	}
+	_, err := fmt.Fprint(r.output, prompt)
+	return err
+}
+
 func (r *interactiveLineReader) ReadLine(prompt string) (string, error) {
 	fd := int(r.input.Fd())
 	oldState, err := term.MakeRaw(fd)
@@ -65,9 +87,38 @@ func (r *interactiveLineReader) ReadLine(prompt string) (string, error) {
 		_ = term.Restore(fd, oldState)
 	}()
 
-	reader := bufio.NewReader(r.input)
-	buffer := newLineBuffer()
+	return r.edit(r.reader, prompt)
+}
 
+// ReadLineWithin edits a line like ReadLine but gives up once timeout passes, restoring
+// the terminal; keys typed after that go to the next prompt.
+func (r *interactiveLineReader) ReadLineWithin(prompt string, timeout time.Duration) (string, bool, error) {
+	fd := int(r.input.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", true, err
+	}
+	defer func() {
+		_ = term.Restore(fd, oldState)
+	}()
+
+	return r.editWithin(prompt, timeout)
+}
+
+func (r *interactiveLineReader) editWithin(prompt string, timeout time.Duration) (string, bool, error) {
+	r.terminal.setDeadline(time.Now().Add(timeout))
+	defer r.terminal.setDeadline(time.Time{})
+	line, err := r.edit(r.reader, prompt)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		_, _ = fmt.Fprint(r.output, "\r\n")
+		return "", false, nil
+	}
+	return line, true, err
+}
+
+// edit runs the line editor over raw terminal input.
+func (r *interactiveLineReader) edit(reader *bufio.Reader, prompt string) (string, error) {
+	buffer := newLineBuffer()
 	if prompt != "" {
 		if _, err := fmt.Fprint(r.output, prompt); err != nil {
 			return "", err
@@ -80,23 +131,33 @@ func (r *interactiveLineReader) ReadLine(prompt string) (string, error) {
 			return "", err
 		}
 
+		if action, ok := r.keys[b]; ok {
+			switch action {
+			case actionInterrupt:
+				if r.onInterrupt != nil {
+					r.onInterrupt()
+				}
+				_, _ = fmt.Fprint(r.output, "^C\r\n")
+				return "", io.EOF
+			case actionEOF:
+				if len(buffer.runes) == 0 {
+					_, _ = fmt.Fprint(r.output, "\r\n")
+					return "", io.EOF
+				}
+			default:
+				if action.apply(buffer) {
+					renderLine(r.output, prompt, buffer)
+				}
+			}
+			continue
+		}
+
 		switch b {
 		case '\r', '\n':
 			renderLine(r.output, prompt, buffer)
 			_, _ = fmt.Fprint(r.output, "\r\n")
 			return buffer.String(), nil
-		case 0x03: // Ctrl+C
-			if r.onInterrupt != nil {
-				r.onInterrupt()
-			}
-			_, _ = fmt.Fprint(r.output, "^C\r\n")
-			return "", io.EOF
-		case 0x04: // Ctrl+D
-			if len(buffer.runes) == 0 {
-				_, _ = fmt.Fprint(r.output, "\r\n")
-				return "", io.EOF
-			}
-		case 0x7f, 0x08: // Backspace / Ctrl+H
+		case 0x7f: // Backspace
 			if buffer.Backspace() {
 				renderLine(r.output, prompt, buffer)
 			}
@@ -118,6 +179,10 @@ func (r *interactiveLineReader) ReadLine(prompt string) (string, error) {
 					continue
 				}
 			}
+			if b < 0x20 {
+				// Unbound control keys are ignored rather than inserted.
+				continue
+			}
 			if r.insertRune(b, reader, buffer) {
 				renderLine(r.output, prompt, buffer)
 			}
@@ -238,9 +303,9 @@ func handleWindowsControlKey(prefix byte, reader io.ByteReader, buffer *lineBuff
 	}
 }
 
-func createLineReader(input io.Reader, output io.Writer, onInterrupt func()) lineReader {
+func createLineReader(input io.Reader, output io.Writer, keys keymap, onInterrupt func()) lineReader {
 	if file, ok := input.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
-		return newInteractiveLineReader(file, output, onInterrupt)
+		return newInteractiveLineReader(file, output, keys, onInterrupt)
 	}
 	return newCanonicalLineReader(input, output)
 }
@@ -1,8 +1,12 @@
 package app
 
 import (
+	"bufio"
 	"bytes"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHandleWindowsControlKeyRecognizesEditKeys(t *testing.T) {
@@ -132,3 +136,33 @@ func TestHandleWindowsControlKeyIgnoresUnknownPrefix(t *testing.T) {
 		t.Fatalf("expected buffer unchanged, got %q", got)
 	}
 }
+
+func TestInteractiveReaderTimeoutLeavesNothingReading(t *testing.T) {
+	input, typing := io.Pipe()
+	defer typing.Close()
+	var out bytes.Buffer
+	terminal := newTerminalInput(input)
+	r := &interactiveLineReader{output: &out, keys: defaultKeymap(), terminal: terminal, reader: bufio.NewReader(terminal)}
+
+	line, answered, err := r.editWithin("Call now? (Y/N): ", 20*time.Millisecond)
+	if err != nil || answered || line != "" {
+		t.Fatalf("editWithin() = %q, %v, %v; want a timeout", line, answered, err)
+	}
+
+	// Keys typed after the timeout belong to the next prompt, not to the abandoned one.
+	go func() { _, _ = io.WriteString(typing, "hello\r") }()
+	out.Reset()
+	line, err = r.edit(r.reader, "humble-ai> ")
+	if err != nil || line != "hello" {
+		t.Fatalf("edit() = %q, %v; want hello", line, err)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "humble-ai> ") || strings.Contains(got, "Call now?") {
+		t.Fatalf("expected the next prompt to be drawn on its own, got %q", got)
+	}
+
+	go func() { _, _ = io.WriteString(typing, "y\r") }()
+	line, answered, err = r.editWithin("Call now? (Y/N): ", time.Second)
+	if err != nil || !answered || line != "y" {
+		t.Fatalf("editWithin() = %q, %v, %v; want y", line, answered, err)
+	}
+}
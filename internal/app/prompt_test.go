@@ -0,0 +1,55 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestAppRendersConfiguredPrompt(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Prompt:        "{cyan}{model}{reset} [{persona}/{mode}]> ",
+		ActivePersona: "reviewer",
+		Personas:      []config.Persona{{Name: "reviewer"}},
+		Models:        []config.Model{{Name: "llama3", Provider: "ollama", Active: true}},
+		// The welcome tip would print before the first prompt.
+		DisableHints: true,
+	}}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        newStubFactory(),
+		Input:          strings.NewReader("/set-tool-mode auto\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	if !strings.HasPrefix(got, "llama3 [reviewer/manual]> ") {
+		t.Fatalf("expected rendered prompt without colors, got:\n%q", got)
+	}
+	if !strings.Contains(got, "llama3 [reviewer/auto]> ") {
+		t.Fatalf("expected prompt to follow tool mode changes, got:\n%q", got)
+	}
+	if strings.Contains(got, "humble-ai> ") || strings.Contains(got, "\x1b[") {
+		t.Fatalf("unexpected default prompt or ANSI codes in output:\n%q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// reportStreamError prints a provider error with a hint on how to fix it.
+func (a *App) reportStreamError(cfg config.Config, model config.Model, err error) {
+	category := llm.Categorize(err)
+	fmt.Fprintf(a.errOutput, "Stream error: %v\n", err)
+	if hint := remediationHint(cfg, model, category); hint != "" {
+		fmt.Fprintf(a.errOutput, "Hint: %s\n", hint)
+	}
+	a.logError("LLM stream error (%s): %v", category, err)
+}
+
+// remediationHint suggests what to do about an error of the given category.
+func remediationHint(cfg config.Config, model config.Model, category llm.ErrorCategory) string {
+	switch category {
+	case llm.ErrorAuth:
+		key := "models.<n>.apiKey"
+		for i, m := range cfg.Models {
+			if m.Name == model.Name {
+				key = fmt.Sprintf("models.%d.apiKey", i)
+				break
+			}
+		}
+		return fmt.Sprintf("The API key for %s was rejected. Update it with `humble-ai-cli config set %s <key>` or set apiKeyCommand.", model.Name, key)
+	case llm.ErrorRateLimited:
+		return "The provider is rate limiting requests or the quota ran out. Wait a moment before sending the message again, or switch models with /set-model."
+	case llm.ErrorContextTooLong:
+		return "The conversation no longer fits the model's context window. Start a fresh session with /new, or switch to a model with a larger window with /set-model."
+	case llm.ErrorNetwork:
+		if model.BaseURL != "" {
+			return fmt.Sprintf("Could not reach %s. Check your connection, proxy settings and the model's baseUrl.", model.BaseURL)
+		}
+		return "Could not reach the provider. Check your connection and proxy settings."
+	case llm.ErrorServer:
+		return "The provider failed on its side. Send the message again in a moment."
+	}
+	return ""
+}
+
+// recordTurnError keeps the failure in the session; a session that was already saved is
+// rewritten, a new one picks it up on its first save.
+func (a *App) recordTurnError(model config.Model, err error) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+	a.turnErrors = append(a.turnErrors, history.TurnError{
+		Timestamp: a.clock.Now(),
+		Model:     model.Name,
+		Category:  string(llm.Categorize(err)),
+		Message:   err.Error(),
+	})
+	if err := a.saveSessionMetadataLocked(); err != nil {
+		a.logError("Failed to record provider error in session: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppPrintsRemediationHintsForProviderErrors(t *testing.T) {
+	tests := []struct {
+		category llm.ErrorCategory
+		hint     string
+	}{
+		{llm.ErrorAuth, "Hint: The API key for stub-model was rejected. Update it with `humble-ai-cli config set models.1.apiKey <key>`"},
+		{llm.ErrorRateLimited, "Hint: The provider is rate limiting requests"},
+		{llm.ErrorContextTooLong, "Hint: The conversation no longer fits the model's context window. Start a fresh session with /new"},
+		{llm.ErrorNetwork, "Hint: Could not reach http://gateway.local. Check your connection"},
+		{llm.ErrorServer, "Hint: The provider failed on its side."},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.category), func(t *testing.T) {
+			home := t.TempDir()
+			store := &stubStore{cfg: config.Config{Models: []config.Model{
+				{Name: "other", Provider: "openai", APIKey: "sk"},
+				{Name: "stub-model", Provider: "openai", APIKey: "sk", BaseURL: "http://gateway.local", Active: true},
+			}}}
+			provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkError, Err: &llm.ProviderError{Category: tc.category, Message: "openai response 400: nope"}}}}
+			factory := newStubFactory()
+			factory.Register("stub-model", provider)
+
+			var output bytes.Buffer
+			instance, err := app.New(app.Options{
+				Store:          store,
+				Factory:        factory,
+				Input:          strings.NewReader(""),
+				Output:         &output,
+				ErrorOutput:    &output,
+				HistoryRootDir: filepath.Join(home, "sessions"),
+				HomeDir:        home,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer instance.Close()
+
+			_ = instance.Ask(context.Background(), "question")
+			if !strings.Contains(output.String(), "Stream error: openai response 400: nope\n") {
+				t.Fatalf("expected the error itself, got:\n%s", output.String())
+			}
+			if !strings.Contains(output.String(), tc.hint) {
+				t.Fatalf("expected hint %q, got:\n%s", tc.hint, output.String())
+			}
+		})
+	}
+}
+
+func TestAppRecordsProviderErrorCategoryInSession(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}}}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hi"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	dir := filepath.Join(home, "sessions")
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: dir,
+		HomeDir:        home,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	if err := instance.Ask(context.Background(), "first"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	provider.mu.Lock()
+	provider.chunks = []llm.StreamChunk{{Type: llm.ChunkError, Err: &llm.ProviderError{Category: llm.ErrorRateLimited, Status: 429, Message: "openai response 429: slow down"}}}
+	provider.mu.Unlock()
+	_ = instance.Ask(context.Background(), "second")
+
+	sessions, err := history.FileStore{Root: dir}.List()
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected one session, got %v (%v)", sessions, err)
+	}
+	session, err := history.FileStore{Root: dir}.Load(sessions[0])
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if len(session.Messages) != 2 {
+		t.Fatalf("expected only the successful turn's messages, got %d", len(session.Messages))
+	}
+	if len(session.Errors) != 1 || session.Errors[0].Category != "rate_limited" || session.Errors[0].Model != "stub-model" || session.Errors[0].Message != "openai response 429: slow down" {
+		t.Fatalf("unexpected recorded errors: %+v", session.Errors)
+	}
+}
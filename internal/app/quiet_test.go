@@ -0,0 +1,122 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppQuietModeHidesToolBannersAndStatus(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		ToolCallMode: "auto",
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &toolRequestProvider{
+		call:  llm.ToolCall{Server: "docs", Method: "read", Arguments: map[string]any{"path": "/tmp/x"}},
+		after: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "final answer"}},
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	mcpExec := &stubMCP{
+		servers:  []app.MCPServer{{Name: "docs"}},
+		toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		response: llm.ToolResult{Content: "file contents"},
+	}
+
+	var output, errOutput bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &errOutput,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	if err := instance.Ask(context.Background(), "read the file"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if got := output.String(); got != "final answer\n" {
+		t.Fatalf("expected only the final answer, got %q", got)
+	}
+	if len(mcpExec.Calls()) != 1 {
+		t.Fatalf("expected the tool to run in quiet mode")
+	}
+	if instance.SessionPath() == "" {
+		t.Fatalf("expected quiet turns to be saved to history")
+	}
+}
+
+func TestAppQuietModeShowsConfirmedToolCallOnStderr(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		ToolCallMode: "manual",
+		ToolPolicy:   config.ToolPolicy{Destructive: []string{"docs.*"}},
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &toolRequestProvider{
+		call:  llm.ToolCall{Server: "docs", Method: "read", Arguments: map[string]any{"path": "/tmp/x"}},
+		after: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "final answer"}},
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	mcpExec := &stubMCP{
+		servers:  []app.MCPServer{{Name: "docs"}},
+		toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		response: llm.ToolResult{Content: "file contents"},
+	}
+
+	var output, errOutput bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("y\n"),
+		Output:         &output,
+		ErrorOutput:    &errOutput,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Quiet:          true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	if err := instance.Ask(context.Background(), "read the file"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if got := output.String(); got != "final answer\n" {
+		t.Fatalf("expected only the final answer on stdout, got %q", got)
+	}
+	stderr := errOutput.String()
+	banner := strings.Index(stderr, "Tool: read")
+	warning := strings.Index(stderr, "DESTRUCTIVE TOOL CALL")
+	prompt := strings.Index(stderr, "Call now?")
+	if banner < 0 || !strings.Contains(stderr, "path: /tmp/x") || warning < 0 || prompt < 0 {
+		t.Fatalf("expected the call, its arguments, the warning and the prompt on stderr, got %q", stderr)
+	}
+	if banner > prompt || warning > prompt {
+		t.Fatalf("expected the call to be described before the prompt, got %q", stderr)
+	}
+	if len(mcpExec.Calls()) != 1 {
+		t.Fatalf("expected the approved tool call to run")
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+	"github.com/gamzabox/humble-ai-cli/internal/redact"
+)
+
+// redactionActive reports whether requests to model should be masked.
+func redactionActive(cfg config.Config, model config.Model) bool {
+	if !cfg.Redaction.Enabled {
+		return false
+	}
+	return cfg.Redaction.IncludeLocal || !isLocalModel(model)
+}
+
+// isLocalModel reports whether model is served by Ollama, the mock provider or a loopback endpoint.
+func isLocalModel(model config.Model) bool {
+	switch strings.ToLower(strings.TrimSpace(model.Provider)) {
+	case "ollama", "mock":
+		return true
+	}
+	parsed, err := url.Parse(strings.TrimSpace(model.BaseURL))
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	host := parsed.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sessionMasker returns the masker for the current session, creating it on first use
+// so placeholders stay stable across turns.
+func (a *App) sessionMasker(cfg config.Config) (*redact.Masker, error) {
+	if a.masker != nil {
+		return a.masker, nil
+	}
+	custom := make([][2]string, 0, len(cfg.Redaction.Patterns))
+	for _, p := range cfg.Redaction.Patterns {
+		custom = append(custom, [2]string{p.Name, p.Regex})
+	}
+	rules, err := redact.Compile(cfg.Redaction.Builtins, custom)
+	if err != nil {
+		return nil, err
+	}
+	a.masker = redact.NewMasker(rules)
+	return a.masker, nil
+}
+
+// maskRequestMessages masks user, assistant and replayed tool content, and the workspace
+// context, and reports how many values were masked in the newest message.
+func (a *App) maskRequestMessages(cfg config.Config, messages []llm.Message) ([]llm.Message, int, error) {
+	masker, err := a.sessionMasker(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]llm.Message, len(messages))
+	latest := 0
+	for i, msg := range messages {
+		if msg.Role == "user" || msg.Role == "assistant" || msg.Role == "tool" || isWorkspaceContextMessage(msg) {
+			var n int
+			msg.Content, n = masker.Mask(msg.Content)
+			if i == len(messages)-1 {
+				latest = n
+			}
+		}
+		out[i] = msg
+	}
+	return out, latest, nil
+}
+
+// maskToolResult masks an MCP result when masking is active for the current turn.
+func (a *App) maskToolResult(content string) string {
+	if !a.turnMasking || a.masker == nil {
+		return content
+	}
+	masked, n := a.masker.Mask(content)
+	if n > 0 {
+		fmt.Fprintf(a.output, "Masked %d value(s) in the tool result (use /redactions to review).\n", n)
+	}
+	return masked
+}
+
+func (a *App) printRedactions() error {
+	var entries []redact.Entry
+	if a.masker != nil {
+		entries = a.masker.Entries()
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(a.output, "Nothing has been masked in this session.")
+		return nil
+	}
+	fmt.Fprintln(a.output, "Masked values in this session:")
+	for _, e := range entries {
+		fmt.Fprintf(a.output, "  %s ← %s (%s, %d×)\n", e.Placeholder, e.Original, e.Rule, e.Count)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func runRedactionSession(t *testing.T, model config.Model, input string) (string, *recordingProvider, *app.App) {
+	t.Helper()
+	home := t.TempDir()
+	model.Active = true
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{model},
+		Redaction: config.Redaction{
+			Enabled:  true,
+			Patterns: []config.RedactionPattern{{Name: "ticket", Regex: `SEC-\d+`}},
+		},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "noted"}}}
+	factory := newStubFactory()
+	factory.Register(model.Name, provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return output.String(), provider, instance
+}
+
+func TestAppMasksPIIForCloudProviders(t *testing.T) {
+	input := "Email alice@example.com about SEC-42\nRemind alice@example.com again\n/redactions\n/exit\n"
+	output, provider, instance := runRedactionSession(t, config.Model{Name: "gpt", Provider: "openai", APIKey: "sk"}, input)
+
+	requests := provider.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	for _, req := range requests {
+		for _, msg := range req.Messages {
+			if strings.Contains(msg.Content, "alice@example.com") || strings.Contains(msg.Content, "SEC-42") {
+				t.Fatalf("expected PII to be masked, got %q", msg.Content)
+			}
+		}
+	}
+	if got := requests[0].Messages[0].Content; got != "Email [EMAIL_1] about [TICKET_1]" {
+		t.Fatalf("unexpected masked message %q", got)
+	}
+	if got := requests[1].Messages[2].Content; got != "Remind [EMAIL_1] again" {
+		t.Fatalf("expected stable placeholder across turns, got %q", got)
+	}
+
+	if !strings.Contains(output, "Masked 2 value(s) in your message (use /redactions to review).") {
+		t.Fatalf("expected masking notice, got:\n%s", output)
+	}
+	if !strings.Contains(output, "  [EMAIL_1] ← alice@example.com (email, 3×)\n") ||
+		!strings.Contains(output, "  [TICKET_1] ← SEC-42 (ticket, 2×)\n") {
+		t.Fatalf("expected /redactions review, got:\n%s", output)
+	}
+
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if session.Messages[0].Content != "Email alice@example.com about SEC-42" {
+		t.Fatalf("expected original message in history, got %q", session.Messages[0].Content)
+	}
+}
+
+func TestAppSkipsMaskingForLocalProviders(t *testing.T) {
+	for _, model := range []config.Model{
+		{Name: "llama3", Provider: "ollama"},
+		{Name: "lm", Provider: "openai", BaseURL: "http://127.0.0.1:1234/v1"},
+	} {
+		_, provider, _ := runRedactionSession(t, model, "Email alice@example.com\n/exit\n")
+		if got := provider.Requests()[0].Messages[0].Content; got != "Email alice@example.com" {
+			t.Fatalf("%s: expected unmasked local request, got %q", model.Name, got)
+		}
+	}
+}
+
+func TestAppMasksToolResults(t *testing.T) {
+	cfg := config.Config{
+		Redaction: config.Redaction{Enabled: true},
+		Models:    []config.Model{{Name: "gpt", Provider: "openai", APIKey: "sk"}},
+	}
+	output, sent, session := runToolCallTurn(t, cfg, "owner: bob@example.org")
+	if sent.Content != "owner: [EMAIL_1]" {
+		t.Fatalf("expected masked tool result, got %q", sent.Content)
+	}
+	if !strings.Contains(output, "Masked 1 value(s) in the tool result") {
+		t.Fatalf("expected tool masking notice, got:\n%s", output)
+	}
+	if got := session.Messages[1].ToolCalls[0].Result; got != "owner: bob@example.org" {
+		t.Fatalf("expected original tool result in history, got %q", got)
+	}
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+const historyListLimit = 20
+
+func (a *App) tagSession(args []string) error {
+	a.historyMu.Lock()
+	if len(args) == 0 {
+		tags := append([]string(nil), a.sessionTags...)
+		a.historyMu.Unlock()
+		if len(tags) == 0 {
+			fmt.Fprintln(a.output, "This session has no tags.")
+		} else {
+			fmt.Fprintf(a.output, "Tags: %s\n", strings.Join(tags, ", "))
+		}
+		fmt.Fprintln(a.output, "Usage: /tag <tag>... (prefix a tag with - to remove it)")
+		return nil
+	}
+
+	for _, arg := range args {
+		if tag, ok := strings.CutPrefix(arg, "-"); ok {
+			a.sessionTags = removeTag(a.sessionTags, tag)
+			continue
+		}
+		if !containsTag(a.sessionTags, arg) {
+			a.sessionTags = append(a.sessionTags, arg)
+		}
+	}
+	tags := append([]string(nil), a.sessionTags...)
+	err := a.saveSessionMetadataLocked()
+	a.historyMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		fmt.Fprintln(a.output, "All tags removed.")
+	} else {
+		fmt.Fprintf(a.output, "Tags: %s\n", strings.Join(tags, ", "))
+	}
+	return nil
+}
+
+func (a *App) noteSession(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		fmt.Fprintln(a.output, "Usage: /note <text>")
+		return nil
+	}
+
+	a.historyMu.Lock()
+	a.sessionNotes = append(a.sessionNotes, text)
+	err := a.saveSessionMetadataLocked()
+	a.historyMu.Unlock()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.output, "Note added to session.")
+	return nil
+}
+
+// rateAnswer attaches a 1-5 rating and optional comment to the last answer: /rate <1-5> [comment].
+// Rating again replaces the previous rating.
+func (a *App) rateAnswer(args []string) error {
+	score := 0
+	if len(args) > 0 {
+		score, _ = strconv.Atoi(args[0])
+	}
+	if score < 1 || score > 5 {
+		fmt.Fprintln(a.output, "Usage: /rate <1-5> [comment]")
+		return nil
+	}
+	index := -1
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		if a.messages[i].Role == "assistant" {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		fmt.Fprintln(a.output, "No answer to rate yet.")
+		return nil
+	}
+
+	rating := &history.Rating{Score: score, Comment: strings.Join(args[1:], " "), RatedAt: a.clock.Now()}
+	a.messages[index].Rating = rating
+	a.historyMu.Lock()
+	err := a.saveRatingLocked(index, rating)
+	a.historyMu.Unlock()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.output, "Rated the last answer %d/5.\n", score)
+	return nil
+}
+
+// saveRatingLocked writes a rating into an already persisted session.
+func (a *App) saveRatingLocked(index int, rating *history.Rating) error {
+	if a.historyPath == "" {
+		return nil
+	}
+	session, err := a.sessionStore().Load(a.sessionName())
+	if err != nil {
+		return err
+	}
+	if index >= len(session.Messages) || session.Messages[index].Role != "assistant" {
+		return fmt.Errorf("session file %s is out of sync with the conversation", filepath.Base(a.historyPath))
+	}
+	session.Messages[index].Rating = rating
+	return a.sessionStore().Save(a.sessionName(), session)
+}
+
+// saveSessionMetadataLocked rewrites tags, notes, bookmarks and errors of an already persisted session.
+// Sessions that have not been written yet pick the metadata up on their first save.
+func (a *App) saveSessionMetadataLocked() error {
+	if a.historyPath == "" {
+		return nil
+	}
+	session, err := a.sessionStore().Load(a.sessionName())
+	if err != nil {
+		return err
+	}
+	session.Tags = append([]string(nil), a.sessionTags...)
+	session.Notes = append([]string(nil), a.sessionNotes...)
+	session.Bookmarks = append([]history.Bookmark(nil), a.bookmarks...)
+	session.Errors = append([]history.TurnError(nil), a.turnErrors...)
+	return a.sessionStore().Save(a.sessionName(), session)
+}
+
+type historyEntry struct {
+	path    string
+	session history.Session
+}
+
+func (a *App) showHistory(args []string) error {
+	tag := ""
+	if len(args) > 0 {
+		tag = strings.TrimSpace(args[0])
+	}
+
+	store := a.sessionStore()
+	names, err := store.List()
+	if err != nil {
+		if len(names) == 0 {
+			return err
+		}
+		fmt.Fprintf(a.errOutput, "Some sessions could not be listed: %v\n", err)
+		a.logError("list sessions: %v", err)
+	}
+
+	var entries []historyEntry
+	for _, name := range names {
+		session, err := store.Load(name)
+		if err != nil {
+			a.logError("skip unreadable session %s: %v", name, err)
+			continue
+		}
+		if tag != "" && !session.HasTag(tag) {
+			continue
+		}
+		entries = append(entries, historyEntry{path: filepath.Join(a.historyRoot, name), session: session})
+		if len(entries) >= historyListLimit {
+			break
+		}
+	}
+
+	if len(entries) == 0 {
+		if tag != "" {
+			fmt.Fprintf(a.output, "No saved sessions tagged %q.\n", tag)
+		} else {
+			fmt.Fprintln(a.output, "No saved sessions found.")
+		}
+		return nil
+	}
+
+	items := make([]string, len(entries))
+	for idx, entry := range entries {
+		items[idx] = filepath.Base(entry.path)
+		if len(entry.session.Tags) > 0 {
+			items[idx] += " [" + strings.Join(entry.session.Tags, ", ") + "]"
+		}
+	}
+
+	choice, err := a.choose("Saved sessions (0 to cancel):", items, 0, "Resume session: ")
+	if err != nil || choice < 0 {
+		return err
+	}
+	if choice == 0 {
+		fmt.Fprintln(a.output, "History selection cancelled.")
+		return nil
+	}
+
+	selected := entries[choice-1]
+	a.resumeSession(selected.path, selected.session)
+	fmt.Fprintf(a.output, "Resumed session %s (%d messages).\n", filepath.Base(selected.path), len(selected.session.Messages))
+	return nil
+}
+
+func (a *App) resumeSession(path string, session history.Session) {
+	a.historyMu.Lock()
+	a.historyPath = path
+	a.sessionStart = session.StartedAt
+	a.firstUserInput = session.FirstUserMessage()
+	a.sessionTags = append([]string(nil), session.Tags...)
+	a.sessionNotes = append([]string(nil), session.Notes...)
+	a.bookmarks = append([]history.Bookmark(nil), session.Bookmarks...)
+	a.turnErrors = append([]history.TurnError(nil), session.Errors...)
+	a.sessionSystemPrompt = session.SystemPrompt
+	a.sessionToolPrompt = session.ToolPrompt
+	a.historyMu.Unlock()
+
+	a.messages = append([]history.Message(nil), session.Messages...)
+	a.masker = nil
+	a.lastThinking = lastSavedThinking(session.Messages)
+	a.toolLog = nil
+	a.pasted = ""
+	a.attachTranscript(path)
+	a.refreshWorkspaceContext()
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeTag(tags []string, tag string) []string {
+	out := tags[:0]
+	for _, t := range tags {
+		if !strings.EqualFold(t, tag) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
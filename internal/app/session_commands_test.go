@@ -0,0 +1,416 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppTagAndNoteArePersistedWithSession(t *testing.T) {
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		},
+	}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	input := strings.NewReader("/tag release-1.4 debugging\nhello\n/note flaky build\n/tag -debugging\n/exit\n")
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(sessionDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 session file, got %d", len(files))
+	}
+	session, err := history.Load(files[0])
+	if err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if len(session.Tags) != 1 || session.Tags[0] != "release-1.4" {
+		t.Fatalf("unexpected tags: %#v", session.Tags)
+	}
+	if len(session.Notes) != 1 || session.Notes[0] != "flaky build" {
+		t.Fatalf("unexpected notes: %#v", session.Notes)
+	}
+}
+
+func TestAppRateIsPersistedOnLastAnswer(t *testing.T) {
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		},
+	}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := strings.NewReader("/rate 5\nhello\n/rate 9\n/rate 2 too   vague\nagain\n/rate 4 better\n/exit\n")
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(at),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	out := output.String()
+	for _, want := range []string{"No answer to rate yet.", "Usage: /rate <1-5> [comment]", "Rated the last answer 4/5."} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if len(session.Messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(session.Messages))
+	}
+	first, second := session.Messages[1].Rating, session.Messages[3].Rating
+	if first == nil || first.Score != 2 || first.Comment != "too vague" || !first.RatedAt.Equal(at) {
+		t.Fatalf("unexpected rating on the first answer: %+v", first)
+	}
+	if second == nil || second.Score != 4 || second.Comment != "better" {
+		t.Fatalf("unexpected rating on the second answer: %+v", second)
+	}
+	if session.Messages[0].Rating != nil || session.Messages[2].Rating != nil {
+		t.Fatalf("expected no ratings on user messages")
+	}
+}
+
+func TestAppHistoryFiltersByTagAndResumes(t *testing.T) {
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeHistory := func(name string, tags []string, question string) {
+		t.Helper()
+		if err := history.Save(filepath.Join(sessionDir, name), history.Session{
+			Model:     "stub-model",
+			StartedAt: at,
+			Tags:      tags,
+			Messages: []history.Message{
+				{Role: "user", Content: question},
+				{Role: "assistant", Content: "answer to " + question},
+			},
+		}); err != nil {
+			t.Fatalf("failed to write session: %v", err)
+		}
+	}
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		t.Fatalf("failed to create session dir: %v", err)
+	}
+	writeHistory("20250101_000000_first.json", []string{"release"}, "first")
+	writeHistory("20250102_000000_second.json", nil, "second")
+
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		},
+	}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	input := strings.NewReader("/history release\n1\nfollow up\n/exit\n")
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          input,
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(at),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "20250101_000000_first.json [release]") || strings.Contains(got, "second.json") {
+		t.Fatalf("expected only tagged session to be listed, got:\n%s", got)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 || len(requests[0].Messages) != 3 {
+		t.Fatalf("expected resumed context plus follow up, got %#v", requests)
+	}
+	if requests[0].Messages[0].Content != "first" {
+		t.Fatalf("expected resumed context to start with first session, got %q", requests[0].Messages[0].Content)
+	}
+
+	session, err := history.Load(filepath.Join(sessionDir, "20250101_000000_first.json"))
+	if err != nil {
+		t.Fatalf("failed to load resumed session: %v", err)
+	}
+	if len(session.Messages) != 4 || !session.HasTag("release") {
+		t.Fatalf("expected resumed session file to be extended, got %#v", session)
+	}
+}
+
+func TestAppRecordsModelSeedInSession(t *testing.T) {
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	seed := int64(7)
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true, Seed: &seed}},
+		},
+	}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("hello\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(sessionDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 session file, got %d", len(files))
+	}
+	session, err := history.Load(files[0])
+	if err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if session.Seed == nil || *session.Seed != 7 {
+		t.Fatalf("expected seed 7 in session, got %v", session.Seed)
+	}
+}
+
+func TestAppPrintsUpstreamRoutingSummary(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "openai/gpt-4o", Provider: "openrouter", APIKey: "sk", Active: true}},
+		},
+	}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{
+		{Type: llm.ChunkRouting, Routing: &llm.RoutingInfo{Model: "anthropic/claude-3.5-sonnet", Provider: "Anthropic"}},
+		{Type: llm.ChunkToken, Content: "ok"},
+	}}
+	factory := newStubFactory()
+	factory.Register("openai/gpt-4o", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("hello\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := "[Served by anthropic/claude-3.5-sonnet via Anthropic (requested openai/gpt-4o)]"
+	if !strings.Contains(output.String(), want) {
+		t.Fatalf("expected routing summary %q, got:\n%s", want, output.String())
+	}
+}
+
+func TestAppResumeReplaysRecordedToolCalls(t *testing.T) {
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		t.Fatalf("failed to create session dir: %v", err)
+	}
+	if err := history.Save(filepath.Join(sessionDir, "20250101_000000_weather.json"), history.Session{
+		Model:     "stub-model",
+		StartedAt: at,
+		Messages: []history.Message{
+			{Role: "user", Content: "weather in Seoul?"},
+			{
+				Role:      "assistant",
+				Content:   "It is sunny.",
+				ToolCalls: []history.ToolCall{{Server: "weather", Method: "forecast", Arguments: map[string]any{"city": "Seoul"}, Result: "sunny, 21C"}},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/history\n1\nand tomorrow?\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(at),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(requests))
+	}
+	messages := requests[0].Messages
+	roles := make([]string, len(messages))
+	for i, msg := range messages {
+		roles[i] = msg.Role
+	}
+	if got := strings.Join(roles, ","); got != "user,assistant,tool,assistant,user" {
+		t.Fatalf("expected tool call context to be reconstructed, got roles %s", got)
+	}
+	if call := messages[1].ToolCalls; len(call) != 1 || call[0].Name() != "weather__forecast" || call[0].Arguments["city"] != "Seoul" {
+		t.Fatalf("unexpected replayed tool call: %+v", messages[1])
+	}
+	if messages[2].Content != "sunny, 21C" || messages[2].ToolCallID != messages[1].ToolCalls[0].ID {
+		t.Fatalf("unexpected replayed tool result: %+v", messages[2])
+	}
+}
+
+func TestAppPinsSystemPromptInSession(t *testing.T) {
+	home := t.TempDir()
+	sessionDir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	if err := os.MkdirAll(filepath.Join(home, ".humble-ai-cli"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	promptPath := filepath.Join(home, ".humble-ai-cli", "system_prompt.txt")
+	if err := os.WriteFile(promptPath, []byte("Answer tersely.\n"), 0o644); err != nil {
+		t.Fatalf("write system prompt: %v", err)
+	}
+	store := &stubStore{
+		cfg: config.Config{
+			Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		},
+	}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("hello\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: sessionDir,
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// Editing the prompt file afterwards must not change what the session recorded.
+	if err := os.WriteFile(promptPath, []byte("Answer verbosely.\n"), 0o644); err != nil {
+		t.Fatalf("rewrite system prompt: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(sessionDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 session file, got %d", len(files))
+	}
+	session, err := history.Load(files[0])
+	if err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	if session.SystemPrompt != strings.TrimSpace(requests[0].SystemPrompt) {
+		t.Fatalf("expected session to pin the sent system prompt %q, got %q", requests[0].SystemPrompt, session.SystemPrompt)
+	}
+	if !strings.HasPrefix(session.SystemPrompt, "Answer tersely.") {
+		t.Fatalf("expected pinned prompt from system_prompt.txt, got %q", session.SystemPrompt)
+	}
+	for _, def := range requests[0].Tools {
+		if !strings.Contains(session.ToolPrompt, "**"+def.Name+"**") {
+			t.Fatalf("expected tool prompt to describe %s, got %q", def.Name, session.ToolPrompt)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppRunsSessionsConcurrently(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{Models: []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		HistoryRootDir: filepath.Join(home, "sessions"),
+		HomeDir:        home,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	names := []string{"alpha", "beta"}
+	sessions := make([]*app.App, len(names))
+	outputs := make([]*bytes.Buffer, len(names))
+	for idx := range names {
+		outputs[idx] = &bytes.Buffer{}
+		sessions[idx] = instance.NewSession(nil, outputs[idx])
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for idx, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for turn := 1; turn <= 3; turn++ {
+				if err := sessions[idx].Ask(context.Background(), fmt.Sprintf("%s %d", name, turn)); err != nil {
+					errs[idx] = err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for idx, name := range names {
+		if errs[idx] != nil {
+			t.Fatalf("Ask() in session %s error = %v", name, errs[idx])
+		}
+		path := sessions[idx].SessionPath()
+		if path == "" || path == sessions[1-idx].SessionPath() {
+			t.Fatalf("expected a session file of its own for %s, got %q", name, path)
+		}
+		record, err := history.Load(path)
+		if err != nil {
+			t.Fatalf("load session %s: %v", name, err)
+		}
+		var users []string
+		for _, msg := range record.Messages {
+			if msg.Role == "user" {
+				users = append(users, msg.Content)
+			}
+		}
+		if want := []string{name + " 1", name + " 2", name + " 3"}; strings.Join(users, "|") != strings.Join(want, "|") {
+			t.Fatalf("session %s kept messages %v, want %v", name, users, want)
+		}
+		if got := strings.Count(outputs[idx].String(), "ok"); got != 3 {
+			t.Fatalf("expected three answers in the output of %s, got %q", name, outputs[idx].String())
+		}
+	}
+	if output.Len() != 0 {
+		t.Fatalf("expected nothing on the first session's output, got %q", output.String())
+	}
+
+	// Each request carries only its own session's conversation.
+	for _, req := range provider.Requests() {
+		var first string
+		for _, msg := range req.Messages {
+			if msg.Role != "user" {
+				continue
+			}
+			prefix, _, _ := strings.Cut(msg.Content, " ")
+			if first == "" {
+				first = prefix
+			} else if prefix != first {
+				t.Fatalf("request mixes sessions: %+v", req.Messages)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/speech"
+)
+
+// toggleSpeech handles /speak [on|off]; without an argument it flips the current state.
+func (a *App) toggleSpeech(args []string) error {
+	on := !a.speaking
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "on":
+			on = true
+		case "off":
+			on = false
+		default:
+			fmt.Fprintln(a.output, "Usage: /speak [on|off]")
+			return nil
+		}
+	}
+	if !on {
+		a.speaking = false
+		if a.speaker != nil {
+			a.speaker.Stop()
+		}
+		a.speechSplitter.Reset()
+		fmt.Fprintln(a.output, "Speech output off.")
+		return nil
+	}
+	a.startSpeech()
+	fmt.Fprintf(a.output, "Speech output on (%s).\n", speech.Describe(a.speaker.Sink()))
+	return nil
+}
+
+// startSpeech turns speech on, starting the speaker on first use.
+func (a *App) startSpeech() {
+	if a.speaker == nil {
+		sink := a.speechSink
+		if sink == nil {
+			a.cfgMu.RLock()
+			cfg := a.cfg.Speech
+			a.cfgMu.RUnlock()
+			sink = speech.NewSink(cfg, nil)
+		}
+		a.speaker = speech.NewSpeaker(sink)
+	}
+	a.speaking = true
+}
+
+// speakText queues the sentences completed by a streamed answer token.
+func (a *App) speakText(text string) {
+	if !a.speaking {
+		return
+	}
+	for _, sentence := range a.speechSplitter.Write(text) {
+		a.speaker.Say(sentence)
+	}
+}
+
+// flushSpeech speaks the unfinished tail of the answer so far.
+func (a *App) flushSpeech() {
+	if !a.speaking {
+		return
+	}
+	for _, sentence := range a.speechSplitter.Flush() {
+		a.speaker.Say(sentence)
+	}
+}
+
+// finishSpeech ends a turn: a cancelled answer stops speaking at once, a completed one
+// speaks its last sentence. Sink failures turn speech off so they are reported once.
+func (a *App) finishSpeech(cancelled bool) {
+	if !a.speaking {
+		return
+	}
+	if cancelled {
+		a.speaker.Stop()
+		a.speechSplitter.Reset()
+	} else {
+		a.flushSpeech()
+	}
+	if err := a.speaker.Err(); err != nil {
+		a.speaking = false
+		a.speaker.Stop()
+		fmt.Fprintf(a.errOutput, "Speech output failed: %v; turning it off (/speak on to retry).\n", err)
+		a.logError("speech: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+type recordingSpeechSink struct {
+	mu     sync.Mutex
+	spoken []string
+	err    error
+}
+
+func (r *recordingSpeechSink) Speak(ctx context.Context, text string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spoken = append(r.spoken, text)
+	return r.err
+}
+
+func (r *recordingSpeechSink) waitFor(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		spoken := append([]string(nil), r.spoken...)
+		r.mu.Unlock()
+		if len(spoken) >= n || time.Now().After(deadline) {
+			return spoken
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newSpeechApp(t *testing.T, input string, enabled bool, sink *recordingSpeechSink) (*app.App, *bytes.Buffer) {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Speech: config.Speech{Enabled: enabled},
+		Models: []config.Model{{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{
+		{Type: llm.ChunkToken, Content: "Sure. Run this:\n```sh\nmake test\n```\nIt takes "},
+		{Type: llm.ChunkToken, Content: "a **minute**"},
+	}}
+	factory := newStubFactory()
+	factory.Register("model-a", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Speech:         sink,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { instance.Close() })
+	return instance, &output
+}
+
+func TestAppSpeaksAnswerSentencesWithoutCode(t *testing.T) {
+	sink := &recordingSpeechSink{}
+	instance, _ := newSpeechApp(t, "", true, sink)
+	if err := instance.Ask(context.Background(), "how do I test?"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	want := []string{"Sure.", "Run this:", "It takes a minute"}
+	if got := sink.waitFor(t, len(want)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("spoken = %q, want %q", got, want)
+	}
+}
+
+func TestAppSpeakToggle(t *testing.T) {
+	sink := &recordingSpeechSink{}
+	instance, output := newSpeechApp(t, "/speak on\n/speak off\n/speak loud\n/exit\n", false, sink)
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	got := output.String()
+	for _, want := range []string{"Speech output on (custom sink).", "Speech output off.", "Usage: /speak [on|off]"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestAppTurnsSpeechOffAfterSinkFailure(t *testing.T) {
+	sink := &recordingSpeechSink{err: errors.New("no audio device")}
+	instance, output := newSpeechApp(t, "", true, sink)
+	if err := instance.Ask(context.Background(), "first"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	sink.waitFor(t, 1)
+	time.Sleep(20 * time.Millisecond)
+	if err := instance.Ask(context.Background(), "second"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	want := "Speech output failed: no audio device; turning it off (/speak on to retry)."
+	if got := output.String(); strings.Count(got, want) != 1 {
+		t.Fatalf("expected one failure notice, got:\n%s", got)
+	}
+}
@@ -0,0 +1,139 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// flakyProvider answers each request with the next scripted pass of chunks.
+type flakyProvider struct {
+	mu       sync.Mutex
+	passes   [][]llm.StreamChunk
+	requests []llm.ChatRequest
+}
+
+func (p *flakyProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	p.mu.Lock()
+	req.Messages = append([]llm.Message(nil), req.Messages...)
+	p.requests = append(p.requests, req)
+	pass := p.passes[0]
+	if len(p.passes) > 1 {
+		p.passes = p.passes[1:]
+	}
+	p.mu.Unlock()
+
+	out := make(chan llm.StreamChunk, len(pass))
+	for _, chunk := range pass {
+		out <- chunk
+	}
+	close(out)
+	return out, nil
+}
+
+func runFlakySession(t *testing.T, cfg config.Config, provider *flakyProvider) (string, *app.App) {
+	t.Helper()
+	home := t.TempDir()
+	cfg.Models = []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          &stubStore{cfg: cfg},
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = instance.Close() })
+	if err := instance.Ask(context.Background(), "greet me"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	return output.String(), instance
+}
+
+func TestAppResumesAnswerAfterNetworkDrop(t *testing.T) {
+	provider := &flakyProvider{passes: [][]llm.StreamChunk{
+		{{Type: llm.ChunkToken, Content: "Hello, wor"}, {Type: llm.ChunkError, Err: io.ErrUnexpectedEOF}},
+		{{Type: llm.ChunkError, Err: &llm.ProviderError{Category: llm.ErrorNetwork, Message: "connection reset"}}},
+		{{Type: llm.ChunkToken, Content: "ld!"}, {Type: llm.ChunkDone}},
+	}}
+	output, instance := runFlakySession(t, config.Config{ResumeStreams: 2}, provider)
+
+	if strings.Contains(output, "Stream error") {
+		t.Fatalf("expected the drops to be resumed, got:\n%s", output)
+	}
+	for _, phrase := range []string{"resuming the answer (1/2)", "resuming the answer (2/2)"} {
+		if !strings.Contains(output, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, output)
+		}
+	}
+	if len(provider.requests) != 3 {
+		t.Fatalf("expected two resumed requests, got %d", len(provider.requests))
+	}
+	resumed := provider.requests[1].Messages
+	if n := len(resumed); n < 3 || resumed[n-2].Role != "assistant" || resumed[n-2].Content != "Hello, wor" ||
+		resumed[n-1].Role != "user" || !strings.Contains(resumed[n-1].Content, "Continue exactly from where it stopped") {
+		t.Fatalf("expected the partial answer and a resume instruction, got %+v", resumed)
+	}
+	if len(provider.requests[2].Messages) != len(resumed) {
+		t.Fatalf("expected a drop before any text to resend the same request, got %+v", provider.requests[2].Messages)
+	}
+
+	if got := instance.LastOutcome(); got != app.TurnOK {
+		t.Fatalf("expected an ok outcome, got %v", got)
+	}
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if got := session.Messages[len(session.Messages)-1].Content; got != "Hello, world!" {
+		t.Fatalf("expected the parts stitched into one answer, got %q", got)
+	}
+}
+
+func TestAppReportsNetworkDropWhenResumesRunOut(t *testing.T) {
+	drop := []llm.StreamChunk{{Type: llm.ChunkToken, Content: "Hel"}, {Type: llm.ChunkError, Err: io.ErrUnexpectedEOF}}
+	for _, resumes := range []int{0, 1} {
+		provider := &flakyProvider{passes: [][]llm.StreamChunk{drop}}
+		output, instance := runFlakySession(t, config.Config{ResumeStreams: resumes}, provider)
+		if len(provider.requests) != resumes+1 {
+			t.Fatalf("resumeStreams %d: expected %d requests, got %d", resumes, resumes+1, len(provider.requests))
+		}
+		if !strings.Contains(output, "Stream error: unexpected EOF") {
+			t.Fatalf("resumeStreams %d: expected the drop to be reported, got:\n%s", resumes, output)
+		}
+		if got := instance.LastOutcome(); got != app.TurnProviderError {
+			t.Fatalf("resumeStreams %d: expected a provider error, got %v", resumes, got)
+		}
+	}
+}
+
+func TestAppDoesNotResumeNonNetworkErrors(t *testing.T) {
+	provider := &flakyProvider{passes: [][]llm.StreamChunk{{
+		{Type: llm.ChunkToken, Content: "Hel"},
+		{Type: llm.ChunkError, Err: &llm.ProviderError{Category: llm.ErrorRateLimited, Message: "rate limit exceeded"}},
+	}}}
+	output, _ := runFlakySession(t, config.Config{ResumeStreams: 3}, provider)
+	if len(provider.requests) != 1 || !strings.Contains(output, "Stream error: rate limit exceeded") {
+		t.Fatalf("expected a rate limit to be reported without resuming, got %d request(s):\n%s", len(provider.requests), output)
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// terminalInput reads the terminal from one long-lived goroutine, so a read can give up at
+// a deadline without leaving a blocked reader behind: bytes typed later go to the next
+// read instead of to an abandoned line editor. The goroutine reads only when asked, so
+// between prompts the terminal is left to others, such as a command asking for a
+// password; only a read that outlived its deadline stays pending. It is not safe for
+// concurrent reads.
+type terminalInput struct {
+	src      io.Reader
+	start    sync.Once
+	requests chan struct{}
+	chunks   chan inputChunk
+	// inFlight is set while the goroutine reads for a request not yet answered.
+	inFlight bool
+
+	pending  []byte
+	err      error
+	deadline time.Time
+}
+
+type inputChunk struct {
+	data []byte
+	err  error
+}
+
+func newTerminalInput(src io.Reader) *terminalInput {
+	return &terminalInput{src: src, requests: make(chan struct{}, 1), chunks: make(chan inputChunk, 1)}
+}
+
+func (t *terminalInput) pump() {
+	for range t.requests {
+		buf := make([]byte, 1024)
+		n, err := t.src.Read(buf)
+		t.chunks <- inputChunk{data: buf[:n], err: err}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// setDeadline makes reads fail with os.ErrDeadlineExceeded once d passes; the zero time
+// waits indefinitely.
+func (t *terminalInput) setDeadline(d time.Time) {
+	t.deadline = d
+}
+
+func (t *terminalInput) Read(p []byte) (int, error) {
+	if len(t.pending) == 0 && t.err == nil {
+		t.start.Do(func() { go t.pump() })
+		var expired <-chan time.Time
+		if !t.deadline.IsZero() {
+			timer := time.NewTimer(time.Until(t.deadline))
+			defer timer.Stop()
+			expired = timer.C
+		}
+		for len(t.pending) == 0 && t.err == nil {
+			if !t.inFlight {
+				t.requests <- struct{}{}
+				t.inFlight = true
+			}
+			select {
+			case chunk := <-t.chunks:
+				t.inFlight = false
+				t.pending, t.err = chunk.data, chunk.err
+			case <-expired:
+				return 0, os.ErrDeadlineExceeded
+			}
+		}
+	}
+	if len(t.pending) == 0 {
+		return 0, t.err
+	}
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingReader returns one byte per read and counts the reads.
+type countingReader struct {
+	reads atomic.Int32
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads.Add(1)
+	p[0] = 'x'
+	return 1, nil
+}
+
+func TestTerminalInputReadsOnlyWhenAsked(t *testing.T) {
+	src := &countingReader{}
+	in := newTerminalInput(src)
+	buf := make([]byte, 8)
+	if n, err := in.Read(buf); n != 1 || err != nil {
+		t.Fatalf("Read() = %d, %v", n, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := src.reads.Load(); got != 1 {
+		t.Fatalf("expected the terminal to be left alone between reads, got %d reads", got)
+	}
+}
+
+func TestTerminalInputDeadline(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	in := newTerminalInput(readerFunc(func(p []byte) (int, error) {
+		<-block
+		return 0, os.ErrClosed
+	}))
+	in.setDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := in.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected the deadline to end the read, got %v", err)
+	}
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
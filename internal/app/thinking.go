@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+// thinkingOutput returns where streamed reasoning and its markers are written, following
+// the thinkingOutput setting or its override. Quiet mode prints no reasoning at all.
+func (a *App) thinkingOutput(cfg config.Config) io.Writer {
+	if a.quiet {
+		return a.output
+	}
+	mode := a.thinkingOverride
+	if mode == "" {
+		// Validate rejects unknown values, so an error here only means stdout.
+		mode, _ = config.ParseThinkingOutput(cfg.ThinkingOutput)
+	}
+	switch mode {
+	case config.ThinkingOutputStderr:
+		return a.errOutput
+	case config.ThinkingOutputDrop:
+		return io.Discard
+	case config.ThinkingOutputAuto:
+		if !a.outputTerminal {
+			return a.errOutput
+		}
+	}
+	return a.output
+}
+
+// showThinking prints the reasoning captured for the last answer, which is hidden
+// while streaming when collapseThinking is set.
+func (a *App) showThinking() {
+	if a.lastThinking == "" {
+		fmt.Fprintln(a.output, "No thinking was captured for the last answer.")
+		return
+	}
+	fmt.Fprintln(a.output, "<<< Thinking >>>")
+	fmt.Fprintln(a.output, a.lastThinking)
+	fmt.Fprintln(a.output, "<<< End Thinking >>>")
+}
+
+// lastSavedThinking returns the reasoning stored with the last answer of a resumed session.
+func lastSavedThinking(messages []history.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "assistant" {
+			return messages[i].Thinking
+		}
+	}
+	return ""
+}
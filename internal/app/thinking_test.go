@@ -0,0 +1,164 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppShowThinkingRevealsCollapsedReasoning(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		CollapseThinking: true,
+		SaveThinking:     true,
+		Models:           []config.Model{{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{
+		{Type: llm.ChunkThinking, Content: "six times seven"},
+		{Type: llm.ChunkThinking, Content: " is forty-two"},
+		{Type: llm.ChunkToken, Content: "42"},
+	}}
+	factory := newStubFactory()
+	factory.Register("model-a", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("what is 6*7?\n/show-thinking\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := output.String()
+	hidden := strings.Index(got, "<<< Thinking hidden >>> (thought for 0ms; /show-thinking to view)")
+	if hidden == -1 {
+		t.Fatalf("expected collapsed thinking marker, got:\n%s", got)
+	}
+	revealed := strings.Index(got, "<<< Thinking >>>\nsix times seven is forty-two\n<<< End Thinking >>>")
+	if revealed == -1 {
+		t.Fatalf("expected /show-thinking to print the reasoning, got:\n%s", got)
+	}
+	if strings.Count(got, "six times seven") != 1 || revealed < hidden {
+		t.Fatalf("expected reasoning only after /show-thinking, got:\n%s", got)
+	}
+
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if last := session.Messages[len(session.Messages)-1]; last.Thinking != "six times seven is forty-two" {
+		t.Fatalf("expected reasoning saved with the answer, got %+v", last)
+	}
+}
+
+func TestAppShowThinkingWithoutReasoning(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        newStubFactory(),
+		Input:          strings.NewReader("/show-thinking\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(output.String(), "No thinking was captured for the last answer.") {
+		t.Fatalf("expected empty notice, got:\n%s", output.String())
+	}
+}
+
+func TestAppRoutesThinkingOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		override   config.ThinkingOutput
+		wantOut    bool
+		wantErr    bool
+	}{
+		{name: "default", wantOut: true},
+		{name: "stderr", configured: "stderr", wantErr: true},
+		{name: "drop", configured: "drop"},
+		{name: "auto on a pipe", configured: "auto", wantErr: true},
+		{name: "override", configured: "stderr", override: config.ThinkingOutputStdout, wantOut: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			home := t.TempDir()
+			store := &stubStore{cfg: config.Config{
+				ThinkingOutput: tc.configured,
+				Models:         []config.Model{{Name: "model-a", Provider: "openai", APIKey: "sk", Active: true}},
+			}}
+			provider := &recordingProvider{chunks: []llm.StreamChunk{
+				{Type: llm.ChunkThinking, Content: "six times seven"},
+				{Type: llm.ChunkToken, Content: "42"},
+			}}
+			factory := newStubFactory()
+			factory.Register("model-a", provider)
+
+			var output, errOutput bytes.Buffer
+			instance, err := app.New(app.Options{
+				Store:          store,
+				Factory:        factory,
+				Input:          strings.NewReader(""),
+				Output:         &output,
+				ErrorOutput:    &errOutput,
+				HistoryRootDir: filepath.Join(home, "sessions"),
+				HomeDir:        home,
+				MCP:            &stubMCP{},
+				ThinkingOutput: tc.override,
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer instance.Close()
+			if err := instance.Ask(context.Background(), "what is 6*7?"); err != nil {
+				t.Fatalf("Ask() error = %v", err)
+			}
+
+			if !strings.Contains(output.String(), "42") {
+				t.Fatalf("expected the answer on output, got:\n%s", output.String())
+			}
+			for _, check := range []struct {
+				name string
+				got  string
+				want bool
+			}{{"output", output.String(), tc.wantOut}, {"error output", errOutput.String(), tc.wantErr}} {
+				has := strings.Contains(check.got, "<<< Thinking >>>\nsix times seven\n<<< End Thinking >>>")
+				if has != check.want {
+					t.Fatalf("thinking on %s = %v, want %v:\n%s", check.name, has, check.want, check.got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// turnTiming collects the timing breakdown of a single turn.
+type turnTiming struct {
+	start      time.Time
+	firstToken time.Time
+	// roundTrips counts provider requests: one per stream plus one follow-up per batch of tool results.
+	roundTrips  int
+	inToolBatch bool
+	tools       []toolTiming
+	// thinking is the total time spent in streamed reasoning; thought reports whether there was any.
+	thinking time.Duration
+	thought  bool
+	// retries counts requests the provider sent again, as reported by ChunkRetry.
+	retries int
+}
+
+type toolTiming struct {
+	server   string
+	method   string
+	duration time.Duration
+	retries  int
+	isError  bool
+}
+
+func newTurnTiming(start time.Time) *turnTiming {
+	return &turnTiming{start: start}
+}
+
+// streamStarted records a new provider stream.
+func (t *turnTiming) streamStarted() {
+	t.roundTrips++
+	t.inToolBatch = false
+}
+
+// observe updates first-token and round-trip bookkeeping for a streamed chunk.
+func (t *turnTiming) observe(chunk llm.StreamChunk, now time.Time) {
+	switch chunk.Type {
+	case llm.ChunkToolCall:
+		// Consecutive tool calls are answered together in a single follow-up request.
+		if !t.inToolBatch {
+			t.roundTrips++
+			t.inToolBatch = true
+		}
+	case llm.ChunkToken, llm.ChunkThinking:
+		if strings.TrimSpace(chunk.Content) == "" {
+			return
+		}
+		if t.firstToken.IsZero() {
+			t.firstToken = now
+		}
+		t.inToolBatch = false
+	case llm.ChunkRetry:
+		t.roundTrips++
+		t.retries++
+	}
+}
+
+func (t *turnTiming) recordThinking(d time.Duration) {
+	t.thinking += d
+	t.thought = true
+}
+
+func (t *turnTiming) recordTool(server, method string, duration time.Duration, retries int, isError bool) {
+	t.tools = append(t.tools, toolTiming{server: server, method: method, duration: duration, retries: retries, isError: isError})
+}
+
+// summary renders the breakdown as a single line.
+func (t *turnTiming) summary(end time.Time) string {
+	firstToken := "-"
+	if !t.firstToken.IsZero() {
+		firstToken = formatDuration(t.firstToken.Sub(t.start))
+	}
+	parts := []string{"first token " + firstToken}
+	if t.thought {
+		parts = append(parts, "thinking "+formatDuration(t.thinking))
+	}
+	parts = append(parts,
+		"total "+formatDuration(end.Sub(t.start)),
+		fmt.Sprintf("%d round-trip(s)", t.roundTrips),
+	)
+	if len(t.tools) > 0 {
+		tools := make([]string, 0, len(t.tools))
+		for _, tool := range t.tools {
+			tools = append(tools, tool.server+"."+tool.method+" "+formatDuration(tool.duration))
+		}
+		parts = append(parts, "tools: "+strings.Join(tools, ", "))
+	}
+	return "[timing] " + strings.Join(parts, " · ")
+}
+
+// metrics converts the breakdown for the session history; the caller adds token counts.
+func (t *turnTiming) metrics(end time.Time) *history.TurnMetrics {
+	m := &history.TurnMetrics{
+		TotalMillis:     end.Sub(t.start).Milliseconds(),
+		ThinkingMillis:  t.thinking.Milliseconds(),
+		RoundTrips:      t.roundTrips,
+		ProviderRetries: t.retries,
+	}
+	if !t.firstToken.IsZero() {
+		m.FirstTokenMillis = t.firstToken.Sub(t.start).Milliseconds()
+	}
+	for _, tool := range t.tools {
+		m.Tools = append(m.Tools, history.ToolMetric{
+			Server:         tool.server,
+			Method:         tool.method,
+			DurationMillis: tool.duration.Milliseconds(),
+			Retries:        tool.retries,
+			IsError:        tool.isError,
+		})
+	}
+	return m
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
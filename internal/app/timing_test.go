@@ -0,0 +1,191 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// slowMCP advances the clock while a tool call runs.
+type slowMCP struct {
+	*stubMCP
+	clock *manualClock
+	delay time.Duration
+}
+
+func (s *slowMCP) Call(ctx context.Context, server, method string, arguments map[string]any) (llm.ToolResult, error) {
+	s.clock.Advance(s.delay)
+	return s.stubMCP.Call(ctx, server, method, arguments)
+}
+
+func runTimedTurn(t *testing.T, enabled bool) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	clock := &manualClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	store := &stubStore{cfg: config.Config{
+		ToolCallMode: "auto",
+		TurnTimings:  enabled,
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &toolRequestProvider{
+		call:  llm.ToolCall{Server: "docs", Method: "read", Arguments: map[string]any{"path": "/tmp/x"}},
+		after: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "done"}},
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	mcpExec := &slowMCP{
+		stubMCP: &stubMCP{
+			servers:  []app.MCPServer{{Name: "docs"}},
+			toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+			response: llm.ToolResult{Content: "ok"},
+		},
+		clock: clock,
+		delay: 1500 * time.Millisecond,
+	}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+		Clock:          clock,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+	if err := instance.Ask(context.Background(), "read it"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	return output.String(), instance.SessionPath()
+}
+
+func TestAppPrintsTurnTimingBreakdown(t *testing.T) {
+	output, _ := runTimedTurn(t, true)
+	want := "[timing] first token 1.5s · total 1.5s · 2 round-trip(s) · tools: docs.read 1.5s"
+	if !strings.Contains(output, want) {
+		t.Fatalf("expected %q, got:\n%s", want, output)
+	}
+}
+
+func TestAppOmitsTurnTimingByDefault(t *testing.T) {
+	if output, _ := runTimedTurn(t, false); strings.Contains(output, "[timing]") {
+		t.Fatalf("expected no timing line, got:\n%s", output)
+	}
+}
+
+func TestAppSavesTurnMetricsInSession(t *testing.T) {
+	_, path := runTimedTurn(t, false)
+	session, err := history.Load(path)
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if session.Messages[0].Metrics != nil {
+		t.Fatalf("expected no metrics on the user message")
+	}
+	metrics := session.Messages[1].Metrics
+	if metrics == nil {
+		t.Fatalf("expected metrics on the assistant message")
+	}
+	if metrics.TotalMillis != 1500 || metrics.FirstTokenMillis != 1500 || metrics.RoundTrips != 2 || metrics.ProviderRetries != 0 {
+		t.Fatalf("unexpected latency metrics: %+v", metrics)
+	}
+	if metrics.PromptTokens == 0 || metrics.CompletionTokens == 0 || metrics.Tokenizer == "" {
+		t.Fatalf("expected estimated token counts, got %+v", metrics)
+	}
+	want := history.ToolMetric{Server: "docs", Method: "read", DurationMillis: 1500}
+	if len(metrics.Tools) != 1 || metrics.Tools[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, metrics.Tools)
+	}
+}
+
+// thinkingProvider reasons for a while before answering, advancing the clock in between.
+type thinkingProvider struct {
+	clock *manualClock
+	delay time.Duration
+}
+
+func (p *thinkingProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	stream := make(chan llm.StreamChunk)
+	go func() {
+		defer close(stream)
+		stream <- llm.StreamChunk{Type: llm.ChunkThinking, Content: "Let me see"}
+		// The unbuffered send above returns once the app has opened the thinking block.
+		stream <- llm.StreamChunk{Type: llm.ChunkThinking, Content: "..."}
+		p.clock.Advance(p.delay)
+		stream <- llm.StreamChunk{Type: llm.ChunkToken, Content: "42"}
+		stream <- llm.StreamChunk{Type: llm.ChunkDone}
+	}()
+	return stream, nil
+}
+
+func TestAppReportsThinkingDuration(t *testing.T) {
+	home := t.TempDir()
+	clock := &manualClock{now: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+	store := &stubStore{cfg: config.Config{
+		TurnTimings: true,
+		Models:      []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true, ReasoningEffort: "high"}},
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", &thinkingProvider{clock: clock, delay: 2300 * time.Millisecond})
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		Clock:          clock,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+	if err := instance.Ask(context.Background(), "think hard"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+
+	got := output.String()
+	for _, want := range []string{
+		"<<< End Thinking >>> (thought for 2.3s)",
+		"[timing] first token 0ms · thinking 2.3s · total 2.3s",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q, got:\n%s", want, got)
+		}
+	}
+}
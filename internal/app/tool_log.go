@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// toolLogEntry is one tool call made in the current session, for /tool-log.
+type toolLogEntry struct {
+	at        time.Time
+	server    string
+	method    string
+	arguments map[string]any
+	result    string
+	isError   bool
+	duration  time.Duration
+	retries   int
+}
+
+// logToolCall records a finished tool call; err is set when the call itself failed.
+func (a *App) logToolCall(call *llm.ToolCall, started time.Time, duration time.Duration, retries int, result llm.ToolResult, err error) {
+	entry := toolLogEntry{
+		at:        started,
+		server:    call.Server,
+		method:    call.Method,
+		arguments: cloneParameters(call.Arguments),
+		result:    result.Content,
+		isError:   result.IsError,
+		duration:  duration,
+		retries:   retries,
+	}
+	if err != nil {
+		entry.result, entry.isError = err.Error(), true
+	}
+	a.toolLog = append(a.toolLog, entry)
+}
+
+// showToolLog handles /tool-log [n]: without an argument it lists the session's tool
+// calls, with one it prints that call's full arguments and result.
+func (a *App) showToolLog(args []string) error {
+	if len(a.toolLog) == 0 {
+		fmt.Fprintln(a.output, "No tool calls in this session yet.")
+		return nil
+	}
+	if len(args) == 0 {
+		for idx, entry := range a.toolLog {
+			status := "ok"
+			if entry.isError {
+				status = "error"
+			}
+			fmt.Fprintf(a.output, "  %d) %s %s.%s(%s) %s %s\n", idx+1, entry.at.Format("15:04:05"),
+				entry.server, entry.method, summarizeArguments(entry.arguments), formatDuration(entry.duration), status)
+		}
+		fmt.Fprintln(a.output, "Use /tool-log <n> to print a call's full arguments and result.")
+		return nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(a.toolLog) {
+		fmt.Fprintf(a.output, "Usage: /tool-log [n], where n is between 1 and %d.\n", len(a.toolLog))
+		return nil
+	}
+	entry := a.toolLog[n-1]
+	fmt.Fprintf(a.output, "Call %d: %s.%s at %s\n", n, entry.server, entry.method, entry.at.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(a.output, "Duration: %s", formatDuration(entry.duration))
+	if entry.retries > 0 {
+		fmt.Fprintf(a.output, " (%d retries)", entry.retries)
+	}
+	fmt.Fprintln(a.output)
+	data, err := json.MarshalIndent(entry.arguments, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.output, "Arguments:\n%s\n", data)
+	if entry.isError {
+		fmt.Fprintln(a.output, "Error:")
+	} else {
+		fmt.Fprintln(a.output, "Result:")
+	}
+	fmt.Fprintln(a.output, strings.TrimRight(entry.result, "\n"))
+	return nil
+}
+
+// summarizeArguments renders arguments as key=value pairs on one short line.
+func summarizeArguments(args map[string]any) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(strings.Fields(formatToolArgument(args[key])), " ")
+		pairs = append(pairs, key+"="+truncateRunes(value, 40))
+	}
+	return truncateRunes(strings.Join(pairs, ", "), 80)
+}
+
+const toolRerunUsage = "Usage: /tool-rerun <n> [--inject] [{json args}]"
+
+// rerunTool handles /tool-rerun: it runs call n of /tool-log again, with any given JSON
+// arguments replacing the logged ones. The result is only shown unless --inject adds the
+// call to the conversation the way /call does.
+func (a *App) rerunTool(ctx context.Context, input string) error {
+	number, rest, _ := strings.Cut(strings.TrimSpace(input), " ")
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		fmt.Fprintln(a.output, toolRerunUsage)
+		return nil
+	}
+	if n < 1 || n > len(a.toolLog) {
+		if len(a.toolLog) == 0 {
+			fmt.Fprintln(a.output, "No tool calls in this session yet.")
+		} else {
+			fmt.Fprintf(a.output, "No call %d; /tool-log lists calls 1 to %d.\n", n, len(a.toolLog))
+		}
+		return nil
+	}
+	rest = strings.TrimSpace(rest)
+	inject := false
+	if after, ok := strings.CutPrefix(rest, "--inject"); ok {
+		inject, rest = true, strings.TrimSpace(after)
+	}
+
+	entry := a.toolLog[n-1]
+	args := cloneParameters(entry.arguments)
+	if args == nil {
+		args = map[string]any{}
+	}
+	if rest != "" {
+		var edits map[string]any
+		if err := json.Unmarshal([]byte(rest), &edits); err != nil {
+			fmt.Fprintf(a.output, "Invalid arguments: expected a JSON object (%v).\n", err)
+			return nil
+		}
+		for key, value := range edits {
+			args[key] = value
+		}
+	}
+	def, ok := a.findToolDefinition(llm.ToolName(entry.server, entry.method))
+	if !ok {
+		fmt.Fprintf(a.output, "%s.%s is no longer available; /mcp lists the available tools.\n", entry.server, entry.method)
+		return nil
+	}
+
+	command := "/tool-rerun " + strings.TrimSpace(input)
+	if err := a.runToolDirectly(ctx, def, args, command, inject); err != nil {
+		return err
+	}
+	if !inject {
+		fmt.Fprintf(a.output, "Not added to the conversation; /tool-log %d prints the full result.\n", len(a.toolLog))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package app_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppToolLogListsCallsAndPrintsOneInFull(t *testing.T) {
+	mcp := &stubMCP{
+		servers:  []app.MCPServer{{Name: "docs"}},
+		toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		response: llm.ToolResult{Content: "chapter one\nchapter two"},
+	}
+	output, _, _ := runCallSession(t, mcp, "/tool-log\n"+
+		"/call docs__read {\"path\": \"a.md\", \"lines\": 20}\n"+
+		"/call docs__read {\"path\": \"b.md\"}\n"+
+		"/tool-log\n/tool-log 2\n/tool-log 3\n/new\n/tool-log\n/exit\n")
+
+	for _, phrase := range []string{
+		"No tool calls in this session yet.",
+		"  1) 03:04:05 docs.read(lines=20, path=a.md) 0ms ok\n",
+		"  2) 03:04:05 docs.read(path=b.md) 0ms ok\n",
+		"Use /tool-log <n> to print a call's full arguments and result.",
+		"Call 2: docs.read at 2025-01-02 03:04:05\n",
+		"Arguments:\n{\n  \"path\": \"b.md\"\n}\n",
+		"Result:\nchapter one\nchapter two\n",
+		"Usage: /tool-log [n], where n is between 1 and 2.",
+	} {
+		if !strings.Contains(output, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, output)
+		}
+	}
+	if strings.Count(output, "No tool calls in this session yet.") != 2 {
+		t.Fatalf("expected /new to clear the tool log, got:\n%s", output)
+	}
+}
+
+func TestAppToolLogRecordsFailedCalls(t *testing.T) {
+	mcp := &stubMCP{
+		servers:       []app.MCPServer{{Name: "docs"}},
+		toolset:       map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		responseError: errors.New("server unavailable"),
+	}
+	output, _, _ := runCallSession(t, mcp, "/call docs__read {\"path\": \"a.md\"}\n/tool-log\n/tool-log 1\n/exit\n")
+
+	for _, phrase := range []string{
+		"  1) 03:04:05 docs.read(path=a.md) 0ms error\n",
+		"Error:\nserver unavailable\n",
+	} {
+		if !strings.Contains(output, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, output)
+		}
+	}
+}
+
+func TestAppToolRerunRunsALoggedCallAgain(t *testing.T) {
+	mcp := &stubMCP{
+		servers:  []app.MCPServer{{Name: "docs"}},
+		toolset:  map[string][]app.MCPFunction{"docs": {{Name: "read"}}},
+		response: llm.ToolResult{Content: "chapter one"},
+	}
+	output, instance, provider := runCallSession(t, mcp, "/call docs__read {\"path\": \"a.md\", \"lines\": 20}\n"+
+		"/tool-rerun 1\n"+
+		"/tool-rerun 1 --inject {\"path\": \"b.md\"}\n"+
+		"/tool-rerun 9\n/tool-rerun x\n/tool-rerun 1 {broken\n"+
+		"summarize it\n/exit\n")
+
+	calls := mcp.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected the call and two reruns, got %+v", calls)
+	}
+	if calls[1].Arguments["path"] != "a.md" || calls[2].Arguments["path"] != "b.md" || calls[2].Arguments["lines"] != float64(20) {
+		t.Fatalf("expected reruns with the logged and the edited arguments, got %+v", calls)
+	}
+	for _, phrase := range []string{
+		"Not added to the conversation; /tool-log 2 prints the full result.",
+		"No call 9; /tool-log lists calls 1 to 3.",
+		"Usage: /tool-rerun <n> [--inject] [{json args}]",
+		"Invalid arguments: expected a JSON object",
+	} {
+		if !strings.Contains(output, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, output)
+		}
+	}
+	if strings.Count(output, "Not added to the conversation") != 1 {
+		t.Fatalf("expected only the plain rerun to stay out of the conversation, got:\n%s", output)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected one model request, got %d", len(requests))
+	}
+	var commands []string
+	for _, msg := range requests[0].Messages {
+		if msg.Role == "user" && strings.HasPrefix(msg.Content, "/") {
+			commands = append(commands, msg.Content)
+		}
+	}
+	want := []string{"/call docs__read {\"path\": \"a.md\", \"lines\": 20}", "/tool-rerun 1 --inject {\"path\": \"b.md\"}"}
+	if strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected the call and the injected rerun in the context, got %q", commands)
+	}
+	if instance.SessionPath() == "" {
+		t.Fatal("expected the session to be saved")
+	}
+}
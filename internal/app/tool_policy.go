@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// destructiveVerbs mark tool names that modify or execute something.
+var destructiveVerbs = map[string]struct{}{
+	"delete": {}, "remove": {}, "rm": {}, "rmdir": {}, "drop": {}, "destroy": {},
+	"write": {}, "overwrite": {}, "edit": {}, "move": {}, "mv": {}, "rename": {},
+	"exec": {}, "execute": {}, "run": {}, "shell": {}, "command": {}, "kill": {},
+	"truncate": {}, "purge": {}, "wipe": {}, "reset": {}, "terminate": {},
+	"push": {}, "deploy": {}, "uninstall": {}, "format": {},
+}
+
+// destructiveServers mark servers whose every tool can change the host.
+var destructiveServers = map[string]struct{}{
+	"shell": {}, "terminal": {}, "exec": {}, "bash": {},
+}
+
+// classifyToolCall reports whether a call must be confirmed even in auto mode, and why.
+// Config patterns are matched against "server.method" and take precedence over the heuristics.
+func classifyToolCall(policy config.ToolPolicy, server, method string) (bool, string) {
+	target := server + "." + method
+	for _, pattern := range policy.Safe {
+		if ok, _ := path.Match(pattern, target); ok {
+			return false, ""
+		}
+	}
+	for _, pattern := range policy.Destructive {
+		if ok, _ := path.Match(pattern, target); ok {
+			return true, fmt.Sprintf("matches toolPolicy pattern %q", pattern)
+		}
+	}
+	for _, word := range splitIdentifier(server) {
+		if _, ok := destructiveServers[word]; ok {
+			return true, fmt.Sprintf("server %q can run commands", server)
+		}
+	}
+	for _, word := range splitIdentifier(method) {
+		if _, ok := destructiveVerbs[word]; ok {
+			return true, fmt.Sprintf("tool name contains %q", word)
+		}
+	}
+	return false, ""
+}
+
+// splitIdentifier breaks snake, kebab, dotted and camelCase names into lowercase words.
+func splitIdentifier(name string) []string {
+	var (
+		words   []string
+		current []rune
+	)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return words
+}
+
+func (a *App) printDestructiveWarning(w io.Writer, reason string) {
+	banner := "!! DESTRUCTIVE TOOL CALL: " + reason + " — confirmation required"
+	if a.color {
+		banner = "\x1b[1;37;41m" + banner + "\x1b[0m"
+	}
+	fmt.Fprintln(w, banner)
+}
+
+// supportsColor reports whether w is a terminal and NO_COLOR is unset.
+func supportsColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
@@ -0,0 +1,88 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func runAutoModeToolCall(t *testing.T, policy config.ToolPolicy, server, method, input string) (string, *stubMCP) {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		ToolCallMode: "auto",
+		ToolPolicy:   policy,
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &toolRequestProvider{
+		call:  llm.ToolCall{Server: server, Method: method, Arguments: map[string]any{"path": "/tmp/x"}},
+		after: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "done"}},
+	}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+	mcpExec := &stubMCP{
+		servers:  []app.MCPServer{{Name: server}},
+		toolset:  map[string][]app.MCPFunction{server: {{Name: method}}},
+		response: llm.ToolResult{Content: "ok"},
+	}
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(input),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcpExec,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return output.String(), mcpExec
+}
+
+func TestAppForcesConfirmationForDestructiveToolsInAutoMode(t *testing.T) {
+	output, mcpExec := runAutoModeToolCall(t, config.ToolPolicy{}, "filesystem", "deleteFile", "clean up\nN\n/exit\n")
+	if !strings.Contains(output, `!! DESTRUCTIVE TOOL CALL: tool name contains "delete"`) {
+		t.Fatalf("expected destructive warning banner, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Call now?") {
+		t.Fatalf("expected confirmation prompt in auto mode, got:\n%s", output)
+	}
+	if len(mcpExec.Calls()) != 0 {
+		t.Fatalf("declined destructive call must not run")
+	}
+}
+
+func TestAppToolPolicyOverridesHeuristics(t *testing.T) {
+	output, mcpExec := runAutoModeToolCall(t, config.ToolPolicy{Safe: []string{"git.push*"}}, "git", "push_branch", "ship it\n/exit\n")
+	if strings.Contains(output, "DESTRUCTIVE") || strings.Contains(output, "Call now?") {
+		t.Fatalf("expected safe pattern to skip confirmation, got:\n%s", output)
+	}
+	if len(mcpExec.Calls()) != 1 {
+		t.Fatalf("expected safe call to run automatically")
+	}
+
+	output, _ = runAutoModeToolCall(t, config.ToolPolicy{Destructive: []string{"db.*"}}, "db", "query", "look\nY\n/exit\n")
+	if !strings.Contains(output, `matches toolPolicy pattern "db.*"`) {
+		t.Fatalf("expected configured destructive pattern to force confirmation, got:\n%s", output)
+	}
+
+	output, _ = runAutoModeToolCall(t, config.ToolPolicy{}, "shell", "list", "look\nY\n/exit\n")
+	if !strings.Contains(output, `server "shell" can run commands`) {
+		t.Fatalf("expected shell server to be treated as destructive, got:\n%s", output)
+	}
+}
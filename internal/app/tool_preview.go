@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultToolResultPreviewLines = 5
+	maxToolPreviewLineWidth       = 160
+)
+
+// toolResultPreviewLines returns how many result lines to echo; zero disables the preview.
+func (a *App) toolResultPreviewLines() int {
+	a.cfgMu.RLock()
+	configured := a.cfg.ToolResultPreviewLines
+	a.cfgMu.RUnlock()
+
+	switch {
+	case configured < 0:
+		return 0
+	case configured == 0:
+		return defaultToolResultPreviewLines
+	default:
+		return configured
+	}
+}
+
+// printToolResultPreview shows the first lines of a tool result and how much was omitted.
+func (a *App) printToolResultPreview(content string) {
+	limit := a.toolResultPreviewLines()
+	content = strings.TrimRight(content, "\n")
+	if limit == 0 || strings.TrimSpace(content) == "" {
+		return
+	}
+
+	lines := strings.Split(content, "\n")
+	shown := min(limit, len(lines))
+	for _, line := range lines[:shown] {
+		fmt.Fprintf(a.output, "  │ %s\n", truncateRunes(line, maxToolPreviewLineWidth))
+	}
+	if hidden := len(lines) - shown; hidden > 0 {
+		fmt.Fprintf(a.output, "  … %d more lines (%s total)\n", hidden, formatByteSize(len(content)))
+	}
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
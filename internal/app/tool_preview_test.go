@@ -0,0 +1,44 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestAppPreviewsLargeToolResults(t *testing.T) {
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, "row "+strings.Repeat("x", i))
+	}
+	result := strings.Join(lines, "\n")
+
+	output, sent, _ := runToolCallTurn(t, config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk"}},
+	}, result)
+
+	if !strings.Contains(output, "  │ row \n") || !strings.Contains(output, "  │ row xxxx\n") {
+		t.Fatalf("expected first result lines in preview, got:\n%s", output)
+	}
+	if strings.Contains(output, "  │ row xxxxx\n") {
+		t.Fatalf("expected preview to stop after 5 lines, got:\n%s", output)
+	}
+	if !strings.Contains(output, "… 35 more lines (") {
+		t.Fatalf("expected omitted line indicator, got:\n%s", output)
+	}
+	if sent.Content != result {
+		t.Fatalf("preview must not change the result sent to the model")
+	}
+}
+
+func TestAppToolResultPreviewCanBeDisabled(t *testing.T) {
+	output, _, _ := runToolCallTurn(t, config.Config{
+		ToolResultPreviewLines: -1,
+		Models:                 []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk"}},
+	}, "secret\nvalues")
+
+	if strings.Contains(output, "│") {
+		t.Fatalf("expected no preview when disabled, got:\n%s", output)
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// callMCPWithRetry runs an MCP call and repeats it with the same arguments while it fails,
+// as often as the matching toolPolicy retry allows. A call fails when it returns an error
+// or a result marked IsError. It also reports how many retries were made.
+func (a *App) callMCPWithRetry(ctx context.Context, call *llm.ToolCall, retry config.ToolRetry) (llm.ToolResult, int, error) {
+	result, err := a.mcp.Call(ctx, call.Server, call.Method, call.Arguments)
+	backoff := time.Duration(retry.BackoffMillis) * time.Millisecond
+	retries := 0
+	for retries < retry.Retries && (err != nil || result.IsError) {
+		retries++
+		fmt.Fprintf(a.output, "Tool call failed (%s); retrying %d/%d...\n", toolFailure(result, err), retries, retry.Retries)
+		a.logDebug("MCP call retry: server=%s method=%s attempt=%d backoff=%s", call.Server, call.Method, retries, backoff)
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return result, retries, err
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+		if ctx.Err() != nil {
+			return result, retries, err
+		}
+		result, err = a.mcp.Call(ctx, call.Server, call.Method, call.Arguments)
+	}
+	return result, retries, err
+}
+
+// allowArgumentAdjustment reports whether the model may call a failed tool again with
+// corrected arguments, and counts the attempt against the turn's allowance.
+func (a *App) allowArgumentAdjustment(call *llm.ToolCall, retry config.ToolRetry) (int, bool) {
+	key := call.Server + "." + call.Method
+	if a.turnAdjustments[key] >= retry.AdjustArguments {
+		return 0, false
+	}
+	if a.turnAdjustments == nil {
+		a.turnAdjustments = map[string]int{}
+	}
+	a.turnAdjustments[key]++
+	return retry.AdjustArguments - a.turnAdjustments[key], true
+}
+
+// adjustArgumentsHint is appended to a failed tool result; it overrides the system prompt's
+// rule to stop after a tool error.
+func adjustArgumentsHint(call *llm.ToolCall, left int) string {
+	return fmt.Sprintf("\n\nThis call may be retried with adjusted arguments. Correct the arguments using the error above "+
+		"and call %s__%s again (%d more attempt(s) after this one). Do not repeat the same arguments; if you cannot fix them, stop and report the failure.",
+		call.Server, call.Method, left)
+}
+
+func toolFailure(result llm.ToolResult, err error) string {
+	text := strings.TrimSpace(result.Content)
+	if err != nil {
+		text = err.Error()
+	}
+	if text == "" {
+		return "no details"
+	}
+	line, _, _ := strings.Cut(text, "\n")
+	return truncateRunes(line, 80)
+}
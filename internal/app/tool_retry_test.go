@@ -0,0 +1,88 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// runRetriedToolCall runs one auto-approved docs.read call against mcp under the retry
+// policy and reports the output, the result delivered to the provider and the outcome.
+func runRetriedToolCall(t *testing.T, retry config.ToolRetry, mcp *stubMCP) (string, llm.ToolResult, app.TurnOutcome) {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:       []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		ToolCallMode: "auto",
+		ToolPolicy:   config.ToolPolicy{Retries: []config.ToolRetry{retry}},
+	}}
+	resultCh := make(chan llm.ToolResult, 1)
+	provider := docsToolProvider()
+	provider.onResponded = func(res llm.ToolResult) { resultCh <- res }
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcp,
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	_ = instance.Ask(context.Background(), "read the docs")
+	return output.String(), <-resultCh, instance.LastOutcome()
+}
+
+func TestAppRetriesFailedToolCall(t *testing.T) {
+	mcp := docsMCP(errors.New("connection reset"))
+	output, sent, outcome := runRetriedToolCall(t, config.ToolRetry{Match: "docs.*", Retries: 2}, mcp)
+
+	if got := len(mcp.Calls()); got != 3 {
+		t.Fatalf("expected the call and 2 retries, got %d calls", got)
+	}
+	for _, want := range []string{"Tool call failed (connection reset); retrying 1/2...", "retrying 2/2..."} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+	if !sent.IsError || outcome != app.TurnToolFailed {
+		t.Fatalf("expected the turn to fail after the retries, got %+v (%v)", sent, outcome)
+	}
+}
+
+func TestAppLetsModelAdjustArgumentsOfFailedToolCall(t *testing.T) {
+	mcp := docsMCP(nil)
+	mcp.response = llm.ToolResult{Content: "path /tmp/x not found", IsError: true}
+	output, sent, outcome := runRetriedToolCall(t, config.ToolRetry{Match: "docs.read", AdjustArguments: 1}, mcp)
+
+	if got := len(mcp.Calls()); got != 1 {
+		t.Fatalf("expected no same-argument retries, got %d calls", got)
+	}
+	if !sent.IsError || !strings.HasPrefix(sent.Content, "path /tmp/x not found") {
+		t.Fatalf("expected the tool error to reach the model, got %+v", sent)
+	}
+	if !strings.Contains(sent.Content, "may be retried with adjusted arguments") || !strings.Contains(sent.Content, "call docs__read again (0 more attempt(s)") {
+		t.Fatalf("expected an adjustment hint in the result, got %q", sent.Content)
+	}
+	if !strings.Contains(output, "the model may retry it with adjusted arguments") || outcome != app.TurnOK {
+		t.Fatalf("expected the turn to continue, got %v:\n%s", outcome, output)
+	}
+}
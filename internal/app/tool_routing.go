@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+const chooseFunctionPrompt = "You choose the tool for the user's latest message. Do not call any function now; only name it.\n" +
+	"Reply with exactly one function name from the list below, or none if no function is needed.\n\nFunctions:\n"
+
+// chooseFunction is the first step of the chooseFunction tool strategy: the model sees only
+// tool names and descriptions and picks one, so the turn itself carries a single schema.
+// It returns the chosen tool, or nil when the model picks none.
+func (a *App) chooseFunction(ctx context.Context, provider llm.ChatProvider, model, message string, tools []llm.ToolDefinition) ([]llm.ToolDefinition, error) {
+	var list strings.Builder
+	list.WriteString(chooseFunctionPrompt)
+	for _, def := range tools {
+		fmt.Fprintf(&list, "- %s: %s\n", def.Name, strings.Join(strings.Fields(def.Description), " "))
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reqCtx = llm.WithLogger(reqCtx, a.logger)
+	a.enterResponding(cancel)
+	defer a.leaveResponding()
+
+	answer, err := streamText(reqCtx, provider, llm.ChatRequest{
+		Model:        model,
+		SystemPrompt: strings.TrimRight(list.String(), "\n"),
+		Messages:     []llm.Message{{Role: "user", Content: message}},
+		Stream:       true,
+	})
+	if reqCtx.Err() != nil {
+		return nil, context.Canceled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chosen, ok := matchToolName(answer, tools)
+	a.logDebug("Tool routing answer=%q chosen=%s", answer, chosen.Name)
+	if !ok {
+		fmt.Fprintln(a.output, "Tool routing: no tool needed.")
+		return nil, nil
+	}
+	fmt.Fprintf(a.output, "Tool routing: %s\n", chosen.Name)
+	return []llm.ToolDefinition{chosen}, nil
+}
+
+// matchToolName finds the tool named in a routing answer. An exact answer wins; otherwise
+// the longest tool name mentioned in the answer is used.
+func matchToolName(answer string, tools []llm.ToolDefinition) (llm.ToolDefinition, bool) {
+	trimmed := strings.Trim(strings.TrimSpace(answer), "`*\"'.")
+	for _, def := range tools {
+		if strings.EqualFold(trimmed, def.Name) {
+			return def, true
+		}
+	}
+	var best llm.ToolDefinition
+	for _, def := range tools {
+		if strings.Contains(answer, def.Name) && len(def.Name) > len(best.Name) {
+			best = def
+		}
+	}
+	return best, best.Name != ""
+}
+
+// streamText collects the answer text of a request that offers no tools.
+func streamText(ctx context.Context, provider llm.ChatProvider, req llm.ChatRequest) (string, error) {
+	stream, err := provider.Stream(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("stream: %w", err)
+	}
+	var text strings.Builder
+	var streamErr error
+	for chunk := range stream {
+		if chunk.Err != nil && streamErr == nil {
+			streamErr = chunk.Err
+		}
+		if chunk.Type == llm.ChunkToken {
+			text.WriteString(chunk.Content)
+		}
+	}
+	return text.String(), streamErr
+}
@@ -0,0 +1,110 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func runRoutedTurn(t *testing.T, strategy string, passes ...llm.StreamChunk) (string, *passProvider) {
+	t.Helper()
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true, ToolStrategy: strategy}},
+	}}
+	provider := &passProvider{passes: passes}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP: &stubMCP{
+			servers: []app.MCPServer{{Name: "docs"}},
+			toolset: map[string][]app.MCPFunction{"docs": {{Name: "read", Description: "Read a document."}, {Name: "write"}}},
+		},
+		Clock: fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer instance.Close()
+
+	if err := instance.Ask(context.Background(), "open the guide"); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	return output.String(), provider
+}
+
+func TestAppChooseFunctionOffersOnlyTheChosenTool(t *testing.T) {
+	output, provider := runRoutedTurn(t, "chooseFunction",
+		llm.StreamChunk{Content: "`docs__read`"},
+		llm.StreamChunk{Content: "Here it is."},
+	)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected a routing request and the turn, got %d requests", len(provider.requests))
+	}
+	routing := provider.requests[0]
+	if len(routing.Tools) != 0 {
+		t.Fatalf("expected the routing request to offer no tools, got %d", len(routing.Tools))
+	}
+	if !strings.Contains(routing.SystemPrompt, "- docs__read: Read a document.") || !strings.Contains(routing.SystemPrompt, "- docs__write:") {
+		t.Fatalf("expected tool names in the routing prompt, got %q", routing.SystemPrompt)
+	}
+	if got := routing.Messages; len(got) != 1 || got[0].Content != "open the guide" {
+		t.Fatalf("expected only the user message in the routing request, got %+v", got)
+	}
+	turn := provider.requests[1]
+	if len(turn.Tools) != 1 || turn.Tools[0].Name != "docs__read" {
+		t.Fatalf("expected only docs__read in the turn, got %+v", turn.Tools)
+	}
+	if !turn.ExpectToolCall {
+		t.Fatalf("expected the turn to expect a call to the chosen tool")
+	}
+	if !strings.Contains(output, "Tool routing: docs__read") {
+		t.Fatalf("expected routing notice, got:\n%s", output)
+	}
+}
+
+func TestAppChooseFunctionCanPickNoTool(t *testing.T) {
+	output, provider := runRoutedTurn(t, "chooseFunction",
+		llm.StreamChunk{Content: "none"},
+		llm.StreamChunk{Content: "Which guide?"},
+	)
+
+	if len(provider.requests) != 2 || len(provider.requests[1].Tools) != 0 || provider.requests[1].ExpectToolCall {
+		t.Fatalf("expected the turn to offer no tools, got %+v", provider.requests)
+	}
+	if !strings.Contains(output, "Tool routing: no tool needed.") {
+		t.Fatalf("expected routing notice, got:\n%s", output)
+	}
+}
+
+func TestAppNativeStrategyOffersEveryTool(t *testing.T) {
+	_, provider := runRoutedTurn(t, "native", llm.StreamChunk{Content: "Here it is."})
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("expected a single request, got %d", len(provider.requests))
+	}
+	names := make([]string, 0, len(provider.requests[0].Tools))
+	for _, def := range provider.requests[0].Tools {
+		names = append(names, def.Name)
+	}
+	if got := strings.Join(names, ","); !strings.Contains(got, "docs__read,docs__write") {
+		t.Fatalf("expected every tool in the request, got %s", got)
+	}
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// transcriptLog mirrors rendered conversation output into an append-only plaintext file
+// per session. Output produced before the session file exists is buffered and written
+// once the session is attached.
+type transcriptLog struct {
+	mu      sync.Mutex
+	dir     string
+	file    *os.File
+	path    string
+	pending bytes.Buffer
+	err     error
+}
+
+func newTranscriptLog(dir string) *transcriptLog {
+	return &transcriptLog{dir: dir}
+}
+
+func (t *transcriptLog) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clean := ansiEscape.ReplaceAll(p, nil)
+	if t.file == nil {
+		t.pending.Write(clean)
+		return len(p), nil
+	}
+	if _, err := t.file.Write(clean); err != nil && t.err == nil {
+		t.err = err
+	}
+	return len(p), nil
+}
+
+// attach starts appending to the transcript that belongs to sessionPath.
+func (t *transcriptLog) attach(sessionPath string, when time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	path := filepath.Join(t.dir, strings.TrimSuffix(filepath.Base(sessionPath), ".json")+".log")
+	if t.path == path {
+		return nil
+	}
+	t.closeLocked()
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("create transcript dir: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open transcript: %w", err)
+	}
+	t.file = file
+	t.path = path
+	fmt.Fprintf(file, "=== %s — %s ===\n", filepath.Base(sessionPath), when.Format("2006-01-02 15:04:05"))
+	if _, err := t.pending.WriteTo(file); err != nil {
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	return nil
+}
+
+// detach stops writing to the current session's transcript and drops buffered output.
+func (t *transcriptLog) detach() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeLocked()
+	t.pending.Reset()
+}
+
+func (t *transcriptLog) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := t.err
+	t.closeLocked()
+	return err
+}
+
+func (t *transcriptLog) closeLocked() {
+	if t.file != nil {
+		_ = t.file.Close()
+	}
+	t.file = nil
+	t.path = ""
+}
+
+// teeWriter writes to the terminal and the transcript; transcript failures never block output.
+type teeWriter struct {
+	primary    io.Writer
+	transcript io.Writer
+}
+
+func (w teeWriter) Write(p []byte) (int, error) {
+	n, err := w.primary.Write(p)
+	_, _ = w.transcript.Write(p[:n])
+	return n, err
+}
+
+// transcriptDir resolves the configured transcript directory, defaulting to ~/.humble-ai-cli/transcripts.
+func transcriptDir(home, configured string) string {
+	dir := strings.TrimSpace(configured)
+	switch {
+	case dir == "":
+		return filepath.Join(config.Dir(home), "transcripts")
+	case strings.HasPrefix(dir, "~/"):
+		return filepath.Join(home, dir[2:])
+	default:
+		return dir
+	}
+}
+
+// recordInput appends a prompt and the line typed at it, which the terminal echoes itself.
+func (a *App) recordInput(prompt, line string) {
+	if a.transcript == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(a.transcript, "%s%s\n", prompt, line)
+}
+
+func (a *App) attachTranscript(sessionPath string) {
+	if a.transcript == nil || sessionPath == "" {
+		return
+	}
+	if err := a.transcript.attach(sessionPath, a.clock.Now()); err != nil {
+		a.logError("transcript: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppWritesPlaintextTranscriptPerSession(t *testing.T) {
+	home := t.TempDir()
+	transcripts := filepath.Join(home, "logs")
+	store := &stubStore{cfg: config.Config{
+		TranscriptLog: true,
+		TranscriptDir: transcripts,
+		Models:        []config.Model{{Name: "llama3", Provider: "ollama", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "Hi there!"}}}
+	factory := newStubFactory()
+	factory.Register("llama3", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/help\nhello\n/new\nsecond topic\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Clock:          fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	first, err := os.ReadFile(filepath.Join(transcripts, "20250102_030405_hello.log"))
+	if err != nil {
+		t.Fatalf("read first transcript: %v", err)
+	}
+	got := string(first)
+	for _, want := range []string{
+		"=== 20250102_030405_hello.json — 2025-01-02 03:04:05 ===\n",
+		"humble-ai> /help\nAvailable commands:",
+		"humble-ai> hello\nWaiting for response...\n",
+		"Hi there!",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected first transcript to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "second topic") {
+		t.Fatalf("expected /new to start a separate transcript, got:\n%s", got)
+	}
+
+	second, err := os.ReadFile(filepath.Join(transcripts, "20250102_030405_secondtopi.log"))
+	if err != nil {
+		t.Fatalf("read second transcript: %v", err)
+	}
+	if !strings.Contains(string(second), "Started a new session.\nhumble-ai> second topic\n") {
+		t.Fatalf("unexpected second transcript:\n%s", second)
+	}
+	if strings.Contains(output.String(), "=== ") {
+		t.Fatalf("transcript header leaked into terminal output")
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+const translatePrompt = "Translate the text below into %s. Keep the markdown structure, code blocks, commands, " +
+	"identifiers and URLs unchanged, and answer with the translation only."
+
+// translateLast renders the last answer in another language: /translate <language>.
+// The exchange is a side channel; neither the request nor the translation enters the
+// conversation context or the session file.
+func (a *App) translateLast(ctx context.Context, args []string) error {
+	language := strings.TrimSpace(strings.Join(args, " "))
+	if language == "" {
+		fmt.Fprintln(a.output, "Usage: /translate <language>")
+		return nil
+	}
+	answer := ""
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		if a.messages[i].Role == "assistant" && strings.TrimSpace(a.messages[i].Content) != "" {
+			answer = a.messages[i].Content
+			break
+		}
+	}
+	if answer == "" {
+		fmt.Fprintln(a.output, "No answer to translate yet.")
+		return nil
+	}
+
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	activeModel, ok := a.resolveModel(cfg)
+	if !ok {
+		fmt.Fprintln(a.output, "No active model is configured. Use /set-model to choose a model.")
+		return nil
+	}
+	provider, err := a.factory.Create(activeModel)
+	if err != nil {
+		return fmt.Errorf("create provider: %w", err)
+	}
+
+	messages := []llm.Message{{Role: "user", Content: answer}}
+	if redactionActive(cfg, activeModel) {
+		if messages, _, err = a.maskRequestMessages(cfg, messages); err != nil {
+			return fmt.Errorf("redaction: %w", err)
+		}
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	reqCtx = llm.WithLogger(reqCtx, a.logger)
+	a.enterResponding(cancel)
+	defer a.leaveResponding()
+
+	stream, err := provider.Stream(reqCtx, llm.ChatRequest{
+		Model:        activeModel.Name,
+		SystemPrompt: fmt.Sprintf(translatePrompt, language),
+		Messages:     messages,
+		Stream:       true,
+	})
+	if err != nil {
+		fmt.Fprintf(a.errOutput, "Translation failed: %v\n", err)
+		return nil
+	}
+	fmt.Fprintf(a.output, "Translation (%s):\n", language)
+	var streamErr error
+	for chunk := range stream {
+		switch {
+		case chunk.Err != nil && streamErr == nil:
+			streamErr = chunk.Err
+		case chunk.Type == llm.ChunkToken:
+			fmt.Fprint(a.output, chunk.Content)
+		}
+	}
+	fmt.Fprintln(a.output)
+	switch {
+	case reqCtx.Err() != nil:
+		fmt.Fprintln(a.output, "Translation cancelled.")
+	case streamErr != nil:
+		fmt.Fprintf(a.errOutput, "Translation failed: %v\n", streamErr)
+	}
+	a.logDebug("Translated the last answer into %s", language)
+	return nil
+}
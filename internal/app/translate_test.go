@@ -0,0 +1,111 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppTranslateKeepsSideChannelOutOfContext(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/translate Korean\nhello\n/translate\n/translate Korean\nnext\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	out := output.String()
+	for _, want := range []string{"No answer to translate yet.", "Usage: /translate <language>", "Translation (Korean):\nok\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	reqs := provider.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(reqs))
+	}
+	if !strings.Contains(reqs[1].SystemPrompt, "into Korean") {
+		t.Fatalf("expected a translation prompt, got %q", reqs[1].SystemPrompt)
+	}
+	if msgs := reqs[1].Messages; len(msgs) != 1 || msgs[0].Role != "user" || msgs[0].Content != "ok" {
+		t.Fatalf("expected only the last answer to be sent, got %+v", msgs)
+	}
+	if msgs := reqs[2].Messages; len(msgs) != 3 || msgs[2].Content != "next" {
+		t.Fatalf("expected the translation to stay out of the conversation, got %+v", msgs)
+	}
+
+	session, err := history.Load(instance.SessionPath())
+	if err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if len(session.Messages) != 4 {
+		t.Fatalf("expected only the two exchanges in the session, got %d messages", len(session.Messages))
+	}
+}
+
+func TestAppTranslateUsesModelOverride(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{
+			{Name: "active-model", Provider: "openai", APIKey: "sk", Active: true},
+			{Name: "override-model", Provider: "openai", APIKey: "sk"},
+		},
+	}}
+	active := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	override := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("active-model", active)
+	factory.Register("override-model", override)
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("hello\n/translate Korean\n/exit\n"),
+		Output:         &bytes.Buffer{},
+		ErrorOutput:    &bytes.Buffer{},
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		Model:          "override-model",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if n := len(active.Requests()); n != 0 {
+		t.Fatalf("expected the active model to stay unused, got %d requests", n)
+	}
+	if reqs := override.Requests(); len(reqs) != 2 || !strings.Contains(reqs[1].SystemPrompt, "into Korean") {
+		t.Fatalf("expected the answer and its translation from the override model, got %d requests", len(reqs))
+	}
+}
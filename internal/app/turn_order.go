@@ -0,0 +1,44 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// newTurnID returns a random identifier for the requests of one turn, so chunks of
+// different turns can never be mistaken for each other.
+func newTurnID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// streamOrder checks that the chunks of one stream belong to its turn and arrive in
+// sequence.
+type streamOrder struct {
+	turnID string
+	last   uint64
+}
+
+// check reports a chunk from another turn, or one whose sequence number does not follow
+// the previous chunk's. Chunks that are not numbered, Seq 0, are accepted as they come.
+func (o *streamOrder) check(chunk llm.StreamChunk) error {
+	if chunk.Seq == 0 {
+		return nil
+	}
+	if chunk.TurnID != o.turnID {
+		return fmt.Errorf("chunk %d belongs to turn %q, not %q", chunk.Seq, chunk.TurnID, o.turnID)
+	}
+	expected := o.last + 1
+	o.last = max(o.last, chunk.Seq)
+	switch {
+	case chunk.Seq < expected:
+		return fmt.Errorf("chunk %d arrived after chunk %d", chunk.Seq, expected-1)
+	case chunk.Seq > expected:
+		return fmt.Errorf("chunks %d to %d are missing", expected, chunk.Seq-1)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestStreamOrderReportsDropsAndReordering(t *testing.T) {
+	order := streamOrder{turnID: "t1"}
+	steps := []struct {
+		chunk llm.StreamChunk
+		want  string
+	}{
+		{llm.StreamChunk{Seq: 1, TurnID: "t1"}, ""},
+		{llm.StreamChunk{Seq: 2, TurnID: "t1"}, ""},
+		{llm.StreamChunk{Seq: 5, TurnID: "t1"}, "chunks 3 to 4 are missing"},
+		{llm.StreamChunk{Seq: 3, TurnID: "t1"}, "chunk 3 arrived after chunk 5"},
+		{llm.StreamChunk{Seq: 6, TurnID: "t1"}, ""},
+		{llm.StreamChunk{Seq: 7, TurnID: "t2"}, `chunk 7 belongs to turn "t2", not "t1"`},
+		{llm.StreamChunk{}, ""},
+	}
+	for i, step := range steps {
+		err := order.check(step.chunk)
+		switch {
+		case step.want == "" && err != nil:
+			t.Fatalf("step %d: unexpected error %v", i, err)
+		case step.want != "" && (err == nil || !strings.Contains(err.Error(), step.want)):
+			t.Fatalf("step %d: error = %v; want %q", i, err, step.want)
+		}
+	}
+}
+
+func TestNewTurnIDIsUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := newTurnID()
+		if len(id) != 16 || seen[id] {
+			t.Fatalf("unexpected turn ID %q", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// UI is an alternate frontend for the interactive loop, such as the full-screen terminal
+// UI. Run reads the user's lines through ReadLine and writes the conversation, status
+// lines and errors to Output, so every frontend shares the same turn orchestration. The
+// plain CLI is the frontend built from Options.Input and Options.Output.
+type UI interface {
+	ReadLine(prompt string) (string, error)
+	Output() io.Writer
+	// SetStatus is called before each prompt with the state the prompt template can show.
+	SetStatus(Status)
+}
+
+// TimedLineReader is implemented by frontends whose reads can give up: ReadLineWithin is
+// ReadLine that returns answered false once timeout passes, leaving nothing reading behind
+// it. Without it, a timed prompt keeps its read running and the next prompt takes its line.
+type TimedLineReader interface {
+	ReadLineWithin(prompt string, timeout time.Duration) (line string, answered bool, err error)
+}
+
+// InterruptNotifier is implemented by frontends that read keys while an answer streams.
+// The App passes the handler for Ctrl+C there: it cancels the answer, as SIGINT does in
+// the plain CLI.
+type InterruptNotifier interface {
+	SetInterruptHandler(func())
+}
+
+// Status is the state of the App shown next to the input, e.g. in a status bar.
+type Status struct {
+	Model    string
+	Provider string
+	Persona  string
+	Profile  string
+	ToolMode config.ToolCallMode
+	// Session is the file name of the current session; empty before the first answer.
+	Session string
+}
+
+// status reports the current model, persona, tool mode, profile and session.
+func (a *App) status() Status {
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+
+	status := Status{
+		Model:    "no model",
+		Persona:  cfg.ActivePersona,
+		Profile:  config.ActiveProfile(),
+		ToolMode: a.toolCallMode(),
+	}
+	model, ok := a.resolveModel(cfg)
+	if ok {
+		status.Model = model.Name
+		status.Provider = model.Provider
+	}
+	if path := a.SessionPath(); path != "" {
+		status.Session = filepath.Base(path)
+	}
+	return status
+}
+
+func (a *App) updateStatus() {
+	if a.ui != nil {
+		a.ui.SetStatus(a.status())
+	}
+}
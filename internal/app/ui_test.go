@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// scriptedUI is a frontend that answers prompts from a list of lines.
+type scriptedUI struct {
+	lines    []string
+	prompts  []string
+	statuses []app.Status
+	output   bytes.Buffer
+}
+
+func (u *scriptedUI) ReadLine(prompt string) (string, error) {
+	u.prompts = append(u.prompts, prompt)
+	if len(u.lines) == 0 {
+		return "", io.EOF
+	}
+	line := u.lines[0]
+	u.lines = u.lines[1:]
+	return line, nil
+}
+
+func (u *scriptedUI) Output() io.Writer { return &u.output }
+
+func (u *scriptedUI) SetStatus(status app.Status) { u.statuses = append(u.statuses, status) }
+
+func TestAppRunsTurnsThroughUI(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models:       []config.Model{{Name: "stub-model", Provider: "ollama", Active: true}},
+		DisableHints: true,
+	}}
+	factory := newStubFactory()
+	factory.Register("stub-model", &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "hi there"}}})
+
+	ui := &scriptedUI{lines: []string{"hello", "/set-tool-mode auto", "/bogus"}}
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		UI:             ui,
+		HistoryRootDir: filepath.Join(home, "sessions"),
+		HomeDir:        home,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	out := ui.output.String()
+	if !strings.Contains(out, "hi there") || !strings.Contains(out, "Unknown command: /bogus") {
+		t.Fatalf("expected answers and command output in the UI, got:\n%s", out)
+	}
+	if len(ui.prompts) != 4 || ui.prompts[0] != "humble-ai> " {
+		t.Fatalf("unexpected prompts %q", ui.prompts)
+	}
+	if len(ui.statuses) != 4 {
+		t.Fatalf("expected a status update before each prompt, got %d", len(ui.statuses))
+	}
+	first, last := ui.statuses[0], ui.statuses[3]
+	if first.Model != "stub-model" || first.Provider != "ollama" || first.ToolMode != config.ToolCallModeManual || first.Session != "" {
+		t.Fatalf("unexpected first status %+v", first)
+	}
+	if last.ToolMode != config.ToolCallModeAuto || last.Session != filepath.Base(instance.SessionPath()) || last.Session == "" {
+		t.Fatalf("unexpected last status %+v", last)
+	}
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// withholdImages returns the tool result text for the model. A model that declares
+// supportsVision: false is told an image was left out instead of where it was saved, so it
+// does not try to read or describe it.
+func (a *App) withholdImages(result llm.ToolResult) string {
+	content := result.Content
+	if !a.turnNoVision {
+		return content
+	}
+	withheld := 0
+	for _, path := range result.Assets {
+		reference := "[image saved to " + path
+		if !strings.Contains(content, reference) {
+			continue
+		}
+		content = strings.ReplaceAll(content, reference, "[image withheld: this model does not read images; the user can open "+path)
+		withheld++
+	}
+	if withheld > 0 {
+		fmt.Fprintf(a.output, "The active model does not read images (supportsVision: false); %d image(s) from the tool result were not sent to it.\n", withheld)
+	}
+	return content
+}
@@ -0,0 +1,35 @@
+package app_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppWithholdsToolResultImagesFromNonVisionModels(t *testing.T) {
+	result := llm.ToolResult{
+		Content: "Chart rendered.\n[image saved to /tmp/assets/abc.png (image/png, 12 bytes)]",
+		Assets:  []string{"/tmp/assets/abc.png"},
+	}
+	no, yes := false, true
+
+	output, sent, _ := runToolResultTurn(t, config.Config{Models: []config.Model{{Name: "text-only", Provider: "ollama", SupportsVision: &no}}}, result)
+	if strings.Contains(sent.Content, "[image saved to") || !strings.Contains(sent.Content, "[image withheld: this model does not read images; the user can open /tmp/assets/abc.png") {
+		t.Fatalf("expected the image withheld from the model, got %q", sent.Content)
+	}
+	if !strings.Contains(output, "does not read images (supportsVision: false); 1 image(s) from the tool result were not sent to it.") {
+		t.Fatalf("expected a notice about the withheld image, got %q", output)
+	}
+
+	for _, model := range []config.Model{
+		{Name: "vision", Provider: "ollama", SupportsVision: &yes},
+		{Name: "undeclared", Provider: "ollama"},
+	} {
+		output, sent, _ := runToolResultTurn(t, config.Config{Models: []config.Model{model}}, result)
+		if sent.Content != result.Content || strings.Contains(output, "does not read images") {
+			t.Fatalf("%s: expected the image reference unchanged, got %q", model.Name, sent.Content)
+		}
+	}
+}
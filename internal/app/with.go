@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// askWith sends a message with a one-off instruction appended to the system prompt
+// for this turn only: /with "<instruction>" <message>.
+func (a *App) askWith(ctx context.Context, rest string) error {
+	instruction, message, ok := parseWithArgs(rest)
+	if !ok {
+		fmt.Fprintln(a.output, `Usage: /with "<instruction>" <message>`)
+		return nil
+	}
+	a.turnInstruction = instruction
+	defer func() { a.turnInstruction = "" }()
+	return a.handleUserMessage(ctx, message)
+}
+
+// parseWithArgs splits a quoted instruction (single or double quotes, with backslash
+// escapes) from the message that follows it.
+func parseWithArgs(rest string) (string, string, bool) {
+	rest = strings.TrimSpace(rest)
+	if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+		return "", "", false
+	}
+	quote := rest[0]
+	var instruction strings.Builder
+	for i := 1; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case c == '\\' && i+1 < len(rest) && (rest[i+1] == quote || rest[i+1] == '\\'):
+			instruction.WriteByte(rest[i+1])
+			i++
+		case c == quote:
+			text := strings.TrimSpace(instruction.String())
+			message := strings.TrimSpace(rest[i+1:])
+			if text == "" || message == "" {
+				return "", "", false
+			}
+			return text, message, true
+		default:
+			instruction.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// withTurnInstruction appends the /with instruction, if any, to the system prompt.
+func (a *App) withTurnInstruction(systemPrompt string) string {
+	if a.turnInstruction == "" {
+		return systemPrompt
+	}
+	if strings.TrimSpace(systemPrompt) == "" {
+		return a.turnInstruction
+	}
+	return systemPrompt + "\n\n" + a.turnInstruction
+}
@@ -0,0 +1,58 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppWithAddsInstructionForOneTurn(t *testing.T) {
+	home := t.TempDir()
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	var output bytes.Buffer
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("/with \"answer in \\\"Korean\\\"\" hello there\nnext question\n/with missing quotes\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	reqs := provider.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	if !strings.HasSuffix(reqs[0].SystemPrompt, "\n\nanswer in \"Korean\"") {
+		t.Fatalf("expected instruction appended to system prompt, got %q", reqs[0].SystemPrompt)
+	}
+	if msgs := reqs[0].Messages; msgs[len(msgs)-1].Content != "hello there" {
+		t.Fatalf("expected message without the instruction, got %q", msgs[len(msgs)-1].Content)
+	}
+	if strings.Contains(reqs[1].SystemPrompt, "Korean") {
+		t.Fatalf("instruction leaked into the next turn: %q", reqs[1].SystemPrompt)
+	}
+	if !strings.Contains(output.String(), `Usage: /with "<instruction>" <message>`) {
+		t.Fatalf("expected usage for malformed /with, got:\n%s", output.String())
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+	"github.com/gamzabox/humble-ai-cli/internal/workspace"
+)
+
+const workspaceContextPreamble = "The user is working in the project summarized below. Use it as background context; it may be incomplete.\n\n"
+
+// refreshWorkspaceContext rebuilds the project summary for a new or resumed session.
+// The project-local .humble-ai-cli.json overrides the workspaceContext settings.
+func (a *App) refreshWorkspaceContext() {
+	a.workspaceContext = ""
+
+	dir := a.workDir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		dir = wd
+	}
+	root, markers, ok := workspace.FindRoot(dir)
+	if !ok {
+		return
+	}
+
+	a.cfgMu.RLock()
+	cfg := a.cfg
+	a.cfgMu.RUnlock()
+	overlay, err := config.LoadProjectOverlay(root)
+	if err != nil {
+		fmt.Fprintf(a.errOutput, "Ignoring project config: %v\n", err)
+		a.logError("load project config: %v", err)
+	} else {
+		cfg = overlay.Apply(cfg)
+	}
+	settings := cfg.WorkspaceContext
+	if !settings.Enabled {
+		return
+	}
+
+	summary, err := workspace.Summarize(root, markers, workspace.Options{
+		MaxDepth:    settings.MaxDepth,
+		MaxEntries:  settings.MaxEntries,
+		HeaderLines: settings.HeaderLines,
+		KeyFiles:    settings.KeyFiles,
+	})
+	if err != nil {
+		a.logError("summarize workspace %s: %v", root, err)
+		return
+	}
+	a.workspaceContext = summary
+	a.logDebug("workspace context built for %s (%d bytes)", root, len(summary))
+}
+
+// workspaceContextMessages returns the context message to lead each request with.
+func (a *App) workspaceContextMessages() []llm.Message {
+	if a.workspaceContext == "" {
+		return nil
+	}
+	return []llm.Message{{Role: "system", Content: workspaceContextPreamble + a.workspaceContext}}
+}
+
+// isWorkspaceContextMessage reports whether msg is the project summary, which carries file
+// contents and so is masked like user content.
+func isWorkspaceContextMessage(msg llm.Message) bool {
+	return msg.Role == "system" && strings.HasPrefix(msg.Content, workspaceContextPreamble)
+}
@@ -0,0 +1,154 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+func TestAppAddsWorkspaceContextWithProjectOverlay(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	files := map[string]string{
+		"go.mod":              "module example.com/demo\n",
+		"README.md":           "# Demo project\n",
+		"cmd/demo/main.go":    "package main\n",
+		".humble-ai-cli.json": `{"workspaceContext": {"enabled": true, "headerLines": 1}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(project, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	store := &stubStore{cfg: config.Config{
+		Models:           []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		WorkspaceContext: config.WorkspaceContext{Enabled: true},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("what is this project?\n/exit\n"),
+		Output:         &bytes.Buffer{},
+		ErrorOutput:    &bytes.Buffer{},
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		WorkDir:        filepath.Join(project, "cmd", "demo"),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	msgs := provider.Requests()[0].Messages
+	if len(msgs) != 2 || msgs[0].Role != "system" {
+		t.Fatalf("expected a leading workspace context message, got %+v", msgs)
+	}
+	for _, want := range []string{"Project: " + filepath.Base(project), "Detected by: go.mod", "  cmd/\n    demo/\n      main.go", "--- README.md ---\n# Demo project"} {
+		if !strings.Contains(msgs[0].Content, want) {
+			t.Fatalf("expected %q in workspace context, got:\n%s", want, msgs[0].Content)
+		}
+	}
+}
+
+func TestAppOmitsWorkspaceContextByDefault(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte("module demo\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	store := &stubStore{cfg: config.Config{
+		Models: []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("hi\n/exit\n"),
+		Output:         &bytes.Buffer{},
+		ErrorOutput:    &bytes.Buffer{},
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		WorkDir:        project,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if msgs := provider.Requests()[0].Messages; len(msgs) != 1 {
+		t.Fatalf("expected no workspace context without opting in, got %+v", msgs)
+	}
+}
+
+func TestAppMasksWorkspaceContext(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module example.com/demo\n",
+		"README.md": "Maintainer: alice@example.com\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(project, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	store := &stubStore{cfg: config.Config{
+		Models:           []config.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+		WorkspaceContext: config.WorkspaceContext{Enabled: true},
+		Redaction:        config.Redaction{Enabled: true},
+	}}
+	provider := &recordingProvider{chunks: []llm.StreamChunk{{Type: llm.ChunkToken, Content: "ok"}}}
+	factory := newStubFactory()
+	factory.Register("stub-model", provider)
+
+	instance, err := app.New(app.Options{
+		Store:          store,
+		Factory:        factory,
+		Input:          strings.NewReader("who maintains this?\n/exit\n"),
+		Output:         &bytes.Buffer{},
+		ErrorOutput:    &bytes.Buffer{},
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            &stubMCP{},
+		WorkDir:        project,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := instance.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	msgs := provider.Requests()[0].Messages
+	if len(msgs) != 2 || msgs[0].Role != "system" || !strings.Contains(msgs[0].Content, "--- README.md ---") {
+		t.Fatalf("expected a leading workspace context message, got %+v", msgs)
+	}
+	if strings.Contains(msgs[0].Content, "alice@example.com") {
+		t.Fatalf("expected the workspace context to be masked, got:\n%s", msgs[0].Content)
+	}
+}
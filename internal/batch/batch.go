@@ -0,0 +1,238 @@
+// Package batch reads the prompt lists run by the batch subcommand.
+package batch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PromptPlaceholder marks where a template takes the item's prompt.
+const PromptPlaceholder = "{prompt}"
+
+// Item is one prompt of a batch.
+type Item struct {
+	// Name labels the answer file; empty items are numbered only.
+	Name   string
+	Prompt string
+	// Model overrides the batch's model for this item.
+	Model string
+	// Template wraps the prompt, which replaces {prompt}; it overrides the batch's template.
+	Template string
+}
+
+// Text returns the prompt to send, wrapped in the item's template or else fallback.
+func (it Item) Text(fallback string) string {
+	template := it.Template
+	if template == "" {
+		template = fallback
+	}
+	if template == "" {
+		return it.Prompt
+	}
+	return strings.ReplaceAll(template, PromptPlaceholder, it.Prompt)
+}
+
+// Parse reads a batch file. Files ending in .yaml or .yml hold a list of items; any other
+// file holds one prompt per line, skipping blank lines and lines starting with #.
+func Parse(path string, data []byte) ([]Item, error) {
+	var (
+		items []Item
+		err   error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		items, err = parseYAML(string(data))
+	default:
+		items = parseLines(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s has no prompts", filepath.Base(path))
+	}
+	for i, item := range items {
+		if strings.TrimSpace(item.Prompt) == "" {
+			return nil, fmt.Errorf("item %d has no prompt", i+1)
+		}
+		if err := CheckTemplate(item.Template); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+	return items, nil
+}
+
+// CheckTemplate reports a non-empty template without the {prompt} placeholder.
+func CheckTemplate(template string) error {
+	if template != "" && !strings.Contains(template, PromptPlaceholder) {
+		return fmt.Errorf("template %q does not contain %s", template, PromptPlaceholder)
+	}
+	return nil
+}
+
+func parseLines(data string) []Item {
+	var items []Item
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, Item{Prompt: line})
+	}
+	return items
+}
+
+// parseYAML reads the YAML subset batch files need: a top-level list whose entries are
+// either a bare prompt or a map of name, prompt, model and template. Values may be plain,
+// quoted, or | and > block scalars.
+func parseYAML(data string) ([]Item, error) {
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	var (
+		items   []Item
+		current *Item
+		// fieldIndent is the indentation of the keys of the current item.
+		fieldIndent int
+	)
+	for i := 0; i < len(lines); i++ {
+		raw := lines[i]
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if strings.HasPrefix(raw, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", i+1)
+		}
+
+		rest := trimmed
+		if after, ok := strings.CutPrefix(trimmed, "-"); ok && (after == "" || after[0] == ' ') && (current == nil || indent < fieldIndent) {
+			items = append(items, Item{})
+			current = &items[len(items)-1]
+			rest = strings.TrimSpace(after)
+			fieldIndent = indent + 1 + (len(after) - len(strings.TrimLeft(after, " ")))
+			if rest == "" {
+				continue
+			}
+			if _, _, isField := cutKey(rest); !isField {
+				value, next, err := yamlValue(rest, lines, i, indent)
+				if err != nil {
+					return nil, err
+				}
+				current.Prompt, i = value, next
+				continue
+			}
+		} else if current == nil {
+			return nil, fmt.Errorf("line %d: expected a list item starting with \"- \"", i+1)
+		} else if indent != fieldIndent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", i+1)
+		}
+
+		key, value, ok := cutKey(rest)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", i+1)
+		}
+		parsed, next, err := yamlValue(value, lines, i, fieldIndent)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		switch key {
+		case "name":
+			current.Name = parsed
+		case "prompt":
+			current.Prompt = parsed
+		case "model":
+			current.Model = parsed
+		case "template":
+			current.Template = parsed
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q (use name, prompt, model or template)", i+1, key)
+		}
+	}
+	return items, nil
+}
+
+// cutKey splits "key: value" when key is a plain identifier.
+func cutKey(text string) (string, string, bool) {
+	key, value, ok := strings.Cut(text, ":")
+	if !ok || key == "" || strings.ContainsAny(key, " \"'") {
+		return "", "", false
+	}
+	if value != "" && value[0] != ' ' {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
+
+// yamlValue decodes the scalar starting on line at; block scalars consume the following
+// lines indented deeper than parentIndent. It returns the index of the last line used.
+func yamlValue(value string, lines []string, at, parentIndent int) (string, int, error) {
+	switch {
+	case value == "|" || value == ">" || value == "|-" || value == ">-":
+		var block []string
+		blockIndent := -1
+		next := at
+		for j := at + 1; j < len(lines); j++ {
+			line := strings.TrimRight(lines[j], " \r")
+			if line == "" {
+				block = append(block, "")
+				continue
+			}
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			if indent <= parentIndent {
+				break
+			}
+			if blockIndent < 0 {
+				blockIndent = indent
+			}
+			if indent < blockIndent {
+				return "", 0, fmt.Errorf("line %d: block scalar lines must keep their indentation", j+1)
+			}
+			block = append(block, line[blockIndent:])
+			next = j
+		}
+		block = block[:next-at]
+		text := strings.Join(block, "\n")
+		if value[0] == '>' {
+			text = foldLines(block)
+		}
+		if !strings.HasSuffix(value, "-") {
+			text += "\n"
+		}
+		return text, next, nil
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", 0, fmt.Errorf("line %d: invalid double-quoted string", at+1)
+		}
+		return unquoted, at, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", 0, fmt.Errorf("line %d: unterminated single-quoted string", at+1)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), at, nil
+	default:
+		if idx := strings.Index(value, " #"); idx >= 0 {
+			value = strings.TrimSpace(value[:idx])
+		}
+		return value, at, nil
+	}
+}
+
+// foldLines joins the lines of a > block with spaces, keeping blank lines as breaks.
+func foldLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		switch {
+		case line == "":
+			b.WriteString("\n")
+		case i > 0 && lines[i-1] != "":
+			b.WriteString(" " + line)
+		default:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
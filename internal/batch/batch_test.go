@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLines(t *testing.T) {
+	items, err := Parse("prompts.txt", []byte("# product blurbs\nWrite about tea\n\n  Write about coffee  \n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Item{{Prompt: "Write about tea"}, {Prompt: "Write about coffee"}}
+	if !reflect.DeepEqual(items, want) {
+		t.Fatalf("Parse() = %+v, want %+v", items, want)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	data := `# launch copy
+- Write a haiku about tea
+- name: coffee
+  prompt: "Describe \"cold brew\""
+  model: llama3 # local model
+- prompt: |
+    Line one.
+      Indented line.
+
+    Last line.
+  template: 'Answer in Korean: {prompt}'
+-   prompt: >-
+      folded
+      text
+`
+	items, err := Parse("batch.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Item{
+		{Prompt: "Write a haiku about tea"},
+		{Name: "coffee", Prompt: `Describe "cold brew"`, Model: "llama3"},
+		{Prompt: "Line one.\n  Indented line.\n\nLast line.\n", Template: "Answer in Korean: {prompt}"},
+		{Prompt: "folded text"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Fatalf("Parse() =\n%#v\nwant\n%#v", items, want)
+	}
+	if got := items[2].Text("ignored {prompt}"); !strings.HasPrefix(got, "Answer in Korean: Line one.") {
+		t.Fatalf("expected the item template to win, got %q", got)
+	}
+	if got := items[0].Text("Tweet: {prompt}"); got != "Tweet: Write a haiku about tea" {
+		t.Fatalf("expected the fallback template, got %q", got)
+	}
+}
+
+func TestParseRejectsInvalidFiles(t *testing.T) {
+	cases := map[string]string{
+		"empty.txt":    "# nothing\n\n",
+		"key.yaml":     "- prompt: hi\n  temperature: 1\n",
+		"top.yaml":     "prompt: hi\n",
+		"template.yml": "- prompt: hi\n  template: no placeholder\n",
+		"noprompt.yml": "- name: only a name\n",
+		"indent.yaml":  "- prompt: hi\n    model: x\n",
+	}
+	for name, data := range cases {
+		if _, err := Parse(name, []byte(data)); err == nil {
+			t.Fatalf("Parse(%s) expected an error", name)
+		}
+	}
+}
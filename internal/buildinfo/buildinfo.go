@@ -0,0 +1,89 @@
+// Package buildinfo reports the version the binary was built from.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/gamzabox/humble-ai-cli/internal/buildinfo.Version=v1.2.0 \
+//	  -X github.com/gamzabox/humble-ai-cli/internal/buildinfo.Commit=$(git rev-parse HEAD)"
+var (
+	Version = ""
+	Commit  = ""
+	Date    = ""
+)
+
+// DevVersion is reported when no release version is known.
+const DevVersion = "dev"
+
+// Info describes the running binary.
+type Info struct {
+	Version   string
+	Commit    string
+	Date      string
+	Modified  bool
+	GoVersion string
+	Platform  string
+}
+
+// Get combines ldflags values with the module and VCS data embedded by the Go toolchain.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = DevVersion
+	}
+	return info
+}
+
+// IsRelease reports whether the binary carries a release version rather than a dev build.
+func (i Info) IsRelease() bool {
+	return i.Version != DevVersion && !strings.Contains(i.Version, "-0.") && !i.Modified
+}
+
+func (i Info) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "humble-ai-cli %s\n", i.Version)
+	if i.Commit != "" {
+		commit := i.Commit
+		if i.Modified {
+			commit += " (modified)"
+		}
+		fmt.Fprintf(&b, "commit:   %s\n", commit)
+	}
+	if i.Date != "" {
+		fmt.Fprintf(&b, "built:    %s\n", i.Date)
+	}
+	fmt.Fprintf(&b, "go:       %s\n", i.GoVersion)
+	fmt.Fprintf(&b, "platform: %s\n", i.Platform)
+	return b.String()
+}
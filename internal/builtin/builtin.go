@@ -0,0 +1,186 @@
+// Package builtin provides small local tools that are offered to the LLM next to
+// MCP tools, so trivial operations do not need an MCP server.
+package builtin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// ServerName is the pseudo server name built-in tools are namespaced under.
+const ServerName = "builtin"
+
+// Tool is a local function exposed to the LLM.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+	run         func(args map[string]any) (string, error)
+}
+
+// Registry holds the built-in tools.
+type Registry struct {
+	tools map[string]Tool
+	now   func() time.Time
+}
+
+// NewRegistry returns a registry with the default tools. now supplies the current
+// time and defaults to time.Now.
+func NewRegistry(now func() time.Time) *Registry {
+	if now == nil {
+		now = time.Now
+	}
+	r := &Registry{tools: make(map[string]Tool), now: now}
+	r.add(Tool{
+		Name:        "current_time",
+		Description: "Returns the current date and time, optionally in an IANA time zone such as \"Asia/Seoul\".",
+		Parameters: objectSchema(map[string]any{
+			"timezone": stringProperty("IANA time zone name; defaults to the local zone."),
+		}),
+		run: r.currentTime,
+	})
+	r.add(Tool{
+		Name:        "calculate",
+		Description: "Evaluates an arithmetic expression with + - * / % ^, parentheses, pi, e and functions such as sqrt, abs, round, floor, ceil, sin, cos, tan, log, log10, exp, min and max.",
+		Parameters: objectSchema(map[string]any{
+			"expression": stringProperty("Expression to evaluate, e.g. \"(3 + 4) * 2 ^ 3\"."),
+		}, "expression"),
+		run: calculate,
+	})
+	r.add(Tool{
+		Name:        "uuid",
+		Description: "Generates a random version 4 UUID.",
+		Parameters:  objectSchema(map[string]any{}),
+		run:         newUUID,
+	})
+	r.add(Tool{
+		Name:        "base64",
+		Description: "Encodes text to base64 or decodes base64 to text.",
+		Parameters: objectSchema(map[string]any{
+			"text": stringProperty("Input text."),
+			"mode": map[string]any{
+				"type":        "string",
+				"enum":        []any{"encode", "decode"},
+				"description": "encode (default) or decode.",
+			},
+			"url_safe": map[string]any{
+				"type":        "boolean",
+				"description": "Use the URL-safe alphabet.",
+			},
+		}, "text"),
+		run: convertBase64,
+	})
+	return r
+}
+
+func (r *Registry) add(tool Tool) {
+	r.tools[tool.Name] = tool
+}
+
+// Tools returns the registered tools sorted by name.
+func (r *Registry) Tools() []Tool {
+	out := make([]Tool, 0, len(r.tools))
+	for _, tool := range r.tools {
+		out = append(out, tool)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Call runs a built-in tool. Invalid arguments produce an error result the model can
+// correct; only an unknown tool name returns an error.
+func (r *Registry) Call(name string, args map[string]any) (llm.ToolResult, error) {
+	tool, ok := r.tools[name]
+	if !ok {
+		return llm.ToolResult{}, fmt.Errorf("unknown built-in tool %q", name)
+	}
+	out, err := tool.run(args)
+	if err != nil {
+		return llm.ToolResult{Content: err.Error(), IsError: true}, nil
+	}
+	return llm.ToolResult{Content: out}, nil
+}
+
+func (r *Registry) currentTime(args map[string]any) (string, error) {
+	now := r.now()
+	if name := stringArg(args, "timezone"); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return "", fmt.Errorf("unknown timezone %q", name)
+		}
+		now = now.In(loc)
+	}
+	return fmt.Sprintf("%s (%s)", now.Format(time.RFC3339), now.Weekday()), nil
+}
+
+func calculate(args map[string]any) (string, error) {
+	expr := stringArg(args, "expression")
+	if expr == "" {
+		return "", fmt.Errorf("expression is required")
+	}
+	value, err := Evaluate(expr)
+	if err != nil {
+		return "", err
+	}
+	return formatNumber(value), nil
+}
+
+func newUUID(map[string]any) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate uuid: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func convertBase64(args map[string]any) (string, error) {
+	text, _ := args["text"].(string)
+	encoding := base64.StdEncoding
+	if urlSafe, _ := args["url_safe"].(bool); urlSafe {
+		encoding = base64.URLEncoding
+	}
+	switch mode := strings.ToLower(stringArg(args, "mode")); mode {
+	case "", "encode":
+		return encoding.EncodeToString([]byte(text)), nil
+	case "decode":
+		decoded, err := encoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 input: %v", err)
+		}
+		return string(decoded), nil
+	default:
+		return "", fmt.Errorf("unsupported mode %q (use encode or decode)", mode)
+	}
+}
+
+func stringArg(args map[string]any, key string) string {
+	value, _ := args[key].(string)
+	return strings.TrimSpace(value)
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		list := make([]any, len(required))
+		for i, name := range required {
+			list[i] = name
+		}
+		schema["required"] = list
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
@@ -0,0 +1,72 @@
+package builtin
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	cases := map[string]string{
+		"1 + 2 * 3":          "7",
+		"(1 + 2) * 3":        "9",
+		"2 ^ 3 ^ 2":          "512",
+		"-2 ^ 2":             "-4",
+		"10 % 4":             "2",
+		"7 / 2":              "3.5",
+		"sqrt(16) + abs(-3)": "7",
+		"max(1, 5, 3)":       "5",
+		"round(pi * 100)":    "314",
+		"1.5e3":              "1500",
+	}
+	for expr, want := range cases {
+		v, err := Evaluate(expr)
+		if err != nil {
+			t.Fatalf("Evaluate(%q) error = %v", expr, err)
+		}
+		if got := formatNumber(v); got != want {
+			t.Fatalf("Evaluate(%q) = %s, want %s", expr, got, want)
+		}
+	}
+}
+
+func TestEvaluateRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 / 0", "foo(1)", "2 $ 3", "sqrt(1, 2)"} {
+		if _, err := Evaluate(expr); err == nil {
+			t.Fatalf("Evaluate(%q) expected error", expr)
+		}
+	}
+}
+
+func TestRegistryCall(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := NewRegistry(func() time.Time { return now })
+
+	res, err := r.Call("current_time", map[string]any{"timezone": "Asia/Seoul"})
+	if err != nil || res.IsError || res.Content != "2025-01-02T12:04:05+09:00 (Thursday)" {
+		t.Fatalf("current_time = %+v, %v", res, err)
+	}
+
+	res, _ = r.Call("base64", map[string]any{"text": "hello?"})
+	if res.Content != "aGVsbG8/" {
+		t.Fatalf("base64 encode = %q", res.Content)
+	}
+	res, _ = r.Call("base64", map[string]any{"text": "aGVsbG8_", "mode": "decode", "url_safe": true})
+	if res.Content != "hello?" {
+		t.Fatalf("base64 decode = %q", res.Content)
+	}
+
+	res, _ = r.Call("uuid", nil)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(res.Content) {
+		t.Fatalf("uuid = %q", res.Content)
+	}
+
+	res, err = r.Call("calculate", map[string]any{"expression": "1 /"})
+	if err != nil || !res.IsError {
+		t.Fatalf("expected error result for bad expression, got %+v, %v", res, err)
+	}
+
+	if _, err := r.Call("missing", nil); err == nil {
+		t.Fatalf("expected error for unknown tool")
+	}
+}
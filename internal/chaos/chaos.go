@@ -0,0 +1,129 @@
+// Package chaos injects provider and MCP faults at configurable rates so that retry and
+// reconnect handling can be exercised by hand. It is enabled only through hidden flags.
+package chaos
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Rates are the probabilities, from 0 to 1, of each injected fault.
+type Rates struct {
+	// ProviderTimeout fails a provider request as if it timed out.
+	ProviderTimeout float64
+	// MalformedSSE truncates a line of a provider's streamed response.
+	MalformedSSE float64
+	// MCPDisconnect drops the MCP session before a tool call.
+	MCPDisconnect float64
+}
+
+// Injector decides when to inject faults. A nil Injector never injects.
+type Injector struct {
+	rates Rates
+	seed  int64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// New returns an injector; the same seed reproduces the same sequence of faults.
+func New(rates Rates, seed int64) *Injector {
+	return &Injector{rates: rates, seed: seed, rng: rand.New(rand.NewSource(seed))}
+}
+
+// String describes the active rates, e.g. for a startup warning.
+func (i *Injector) String() string {
+	return fmt.Sprintf("provider timeouts %g, malformed stream lines %g, MCP disconnects %g (seed %d)",
+		i.rates.ProviderTimeout, i.rates.MalformedSSE, i.rates.MCPDisconnect, i.seed)
+}
+
+func (i *Injector) roll(rate float64) bool {
+	if i == nil || rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < rate
+}
+
+// MCPDisconnect reports whether the next MCP call should find its session dropped.
+func (i *Injector) MCPDisconnect() bool {
+	if i == nil {
+		return false
+	}
+	return i.roll(i.rates.MCPDisconnect)
+}
+
+// Doer is the HTTP client interface the providers use.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// HTTPClient wraps base so provider requests time out and stream lines break at the
+// configured rates. A nil base uses a client without timeout.
+func (i *Injector) HTTPClient(base Doer) Doer {
+	if base == nil {
+		base = &http.Client{Timeout: 0}
+	}
+	if i == nil {
+		return base
+	}
+	return &faultyClient{base: base, injector: i}
+}
+
+type faultyClient struct {
+	base     Doer
+	injector *Injector
+}
+
+func (c *faultyClient) Do(req *http.Request) (*http.Response, error) {
+	if c.injector.roll(c.injector.rates.ProviderTimeout) {
+		return nil, fmt.Errorf("chaos: injected provider timeout: %w", context.DeadlineExceeded)
+	}
+	resp, err := c.base.Do(req)
+	if err != nil || c.injector.rates.MalformedSSE <= 0 {
+		return resp, err
+	}
+	resp.Body = &faultyBody{
+		closer:   resp.Body,
+		lines:    bufio.NewReader(resp.Body),
+		injector: c.injector,
+	}
+	return resp, nil
+}
+
+// faultyBody passes a response through line by line and cuts some lines in half, which
+// breaks the JSON of SSE data lines and Ollama's NDJSON chunks alike.
+type faultyBody struct {
+	closer   io.Closer
+	lines    *bufio.Reader
+	injector *Injector
+	pending  []byte
+}
+
+func (b *faultyBody) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		line, err := b.lines.ReadString('\n')
+		if line == "" {
+			return 0, err
+		}
+		if strings.TrimSpace(line) != "" && b.injector.roll(b.injector.rates.MalformedSSE) {
+			line = strings.TrimRight(line, "\r\n")
+			line = line[:len(line)/2] + "\n"
+		}
+		b.pending = []byte(line)
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+func (b *faultyBody) Close() error {
+	return b.closer.Close()
+}
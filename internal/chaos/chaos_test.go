@@ -0,0 +1,64 @@
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/chaos"
+)
+
+type staticClient struct{ body string }
+
+func (c staticClient) Do(*http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(c.body))}, nil
+}
+
+func TestInjectorBreaksProviderRequests(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/chat", nil)
+	body := "data: {\"choices\":[]}\n\ndata: [DONE]\n"
+
+	timeouts := chaos.New(chaos.Rates{ProviderTimeout: 1}, 1).HTTPClient(staticClient{body})
+	if _, err := timeouts.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected an injected timeout, got %v", err)
+	}
+
+	malformed := chaos.New(chaos.Rates{MalformedSSE: 1}, 1).HTTPClient(staticClient{body})
+	resp, err := malformed.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if got, want := string(data), "data: {\"ch\n\ndata: \n"; got != want {
+		t.Fatalf("body = %q, want %q", got, want)
+	}
+
+	var off *chaos.Injector
+	resp, err = off.HTTPClient(staticClient{body}).Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != body || off.MCPDisconnect() {
+		t.Fatalf("expected a nil injector to pass everything through")
+	}
+}
+
+func TestInjectorIsReproducibleWithSeed(t *testing.T) {
+	sequence := func() []bool {
+		injector := chaos.New(chaos.Rates{MCPDisconnect: 0.5}, 42)
+		out := make([]bool, 20)
+		for i := range out {
+			out[i] = injector.MCPDisconnect()
+		}
+		return out
+	}
+	first, second := sequence(), sequence()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same faults for the same seed, differ at %d", i)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/batch"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// runBatch sends every prompt of a batch file in one-shot mode, one after another, and
+// writes each answer to its own file.
+func runBatch(ctx context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	model := fs.String("model", "", "configured model for items that do not name one")
+	template := fs.String("template", "", "template for items without their own, e.g. \"Write a tweet about {prompt}\"")
+	outDir := fs.String("out-dir", "", "directory for answer files (default ~/.humble-ai-cli/batch/<file>-<time>)")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli batch <file> [--model <name>] [--template <text>] [--out-dir <dir>]")
+		fs.PrintDefaults()
+		fmt.Fprintln(env.Stderr)
+		fmt.Fprintln(env.Stderr, "A .yaml or .yml file lists items with prompt and optional name, model and template;")
+		fmt.Fprintln(env.Stderr, "any other file has one prompt per line. Templates take the prompt at {prompt}.")
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := batch.CheckTemplate(*template); err != nil {
+		fmt.Fprintf(env.Stderr, "batch: %v\n", err)
+		return exitUsage
+	}
+	path := positional[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "batch: %v\n", err)
+		return exitFailure
+	}
+	items, err := batch.Parse(path, data)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "batch: %s: %v\n", path, err)
+		return exitUsage
+	}
+
+	dir := *outDir
+	if dir == "" {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		dir = filepath.Join(env.configDir(), "batch", name+"-"+time.Now().Format("20060102-150405"))
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Fprintf(env.Stderr, "batch: create output directory: %v\n", err)
+		return exitFailure
+	}
+
+	// One factory serves the whole batch, so the providers share their caches.
+	factory := env.providerFactory()
+	code, failed := exitOK, 0
+	for i, item := range items {
+		if ctx.Err() != nil {
+			fmt.Fprintln(env.Stdout, "Batch stopped.")
+			return exitCancelled
+		}
+		itemModel := item.Model
+		if itemModel == "" {
+			itemModel = *model
+		}
+		answerPath := filepath.Join(dir, batchFileName(i, item.Name))
+		outcome := runBatchItem(ctx, env, factory, item.Text(*template), itemModel, answerPath)
+		if outcome != app.TurnOK {
+			failed++
+			if code == exitOK {
+				code = exitCodeFor(outcome)
+			}
+			fmt.Fprintf(env.Stdout, "[%d/%d] failed: %s\n", i+1, len(items), outcome)
+			continue
+		}
+		fmt.Fprintf(env.Stdout, "[%d/%d] wrote %s\n", i+1, len(items), answerPath)
+	}
+	fmt.Fprintf(env.Stdout, "Batch finished: %d of %d answered; answers are in %s\n", len(items)-failed, len(items), dir)
+	return code
+}
+
+// runBatchItem answers one prompt into path, removing the file when the turn fails.
+func runBatchItem(ctx context.Context, env Environment, factory *llm.Factory, prompt, model, path string) app.TurnOutcome {
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "batch: %v\n", err)
+		return app.TurnFailed
+	}
+	// Batches are unattended: tools run automatically, and destructive calls that still
+	// need confirmation are declined because there is no input.
+	instance, err := app.New(app.Options{
+		Store:          config.NewFileStore(env.Home),
+		Factory:        factory,
+		Input:          strings.NewReader(""),
+		Output:         file,
+		ErrorOutput:    env.Stderr,
+		HistoryRootDir: env.sessionsDir(),
+		HomeDir:        env.Home,
+		Model:          model,
+		Quiet:          true,
+		ToolCallMode:   config.ToolCallModeAuto,
+		Faults:         env.faults,
+	})
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		fmt.Fprintf(env.Stderr, "batch: failed to initialize application: %v\n", err)
+		return app.TurnFailed
+	}
+	if err := instance.Ask(ctx, prompt); err != nil {
+		fmt.Fprintf(env.Stderr, "batch: %v\n", err)
+	}
+	_ = instance.Close()
+	outcome := instance.LastOutcome()
+	_ = file.Close()
+	if outcome != app.TurnOK {
+		_ = os.Remove(path)
+	}
+	return outcome
+}
+
+// batchFileName numbers answer files in batch order, adding the item name when given.
+func batchFileName(index int, name string) string {
+	base := fmt.Sprintf("%03d", index+1)
+	if name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "-"), "-."); name != "" {
+		base += "-" + name
+	}
+	return base + ".md"
+}
@@ -0,0 +1,109 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestRunBatchWritesAnswerPerItem(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		prompt := body.Messages[len(body.Messages)-1].Content
+		mu.Lock()
+		requests = append(requests, body.Model+": "+prompt)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if strings.Contains(prompt, "fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"message":{"role":"assistant","content":%q},"done":false}`+"\n", "re "+prompt)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	t.Cleanup(server.Close)
+
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{
+			{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true},
+			{Name: "other", Provider: "ollama", BaseURL: server.URL},
+		},
+	})
+	batchFile := filepath.Join(t.TempDir(), "copy.yaml")
+	data := "- tea\n- name: Cold Brew!\n  prompt: coffee\n  model: other\n  template: 'Describe {prompt}'\n- please fail\n"
+	if err := os.WriteFile(batchFile, []byte(data), 0o644); err != nil {
+		t.Fatalf("write batch: %v", err)
+	}
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	code := cli.Run(context.Background(), env, []string{"batch", batchFile, "--template", "Write about {prompt}", "--out-dir", outDir})
+	if code != 3 {
+		t.Fatalf("expected the provider error exit code 3, got %d (stderr=%s)", code, stderr.String())
+	}
+
+	files, _ := filepath.Glob(filepath.Join(outDir, "*.md"))
+	sort.Strings(files)
+	want := map[string]string{"001.md": "re Write about tea\n", "002-Cold-Brew.md": "re Describe coffee\n"}
+	if len(files) != len(want) {
+		t.Fatalf("expected %d answer files, got %v", len(want), files)
+	}
+	for _, file := range files {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if string(got) != want[filepath.Base(file)] {
+			t.Fatalf("%s: unexpected contents %q", filepath.Base(file), got)
+		}
+	}
+	out := stdout.String()
+	for _, line := range []string{"[1/3] wrote ", "[3/3] failed: provider error", "Batch finished: 2 of 3 answered"} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("expected %q in output, got:\n%s", line, out)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 3 || requests[1] != "other: Describe coffee" {
+		t.Fatalf("expected per-item model and template, got %q", requests)
+	}
+}
+
+func TestRunBatchRejectsInvalidArguments(t *testing.T) {
+	lines := filepath.Join(t.TempDir(), "prompts.txt")
+	if err := os.WriteFile(lines, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write batch: %v", err)
+	}
+	for _, args := range [][]string{
+		{"batch"},
+		{"batch", lines, "--template", "no placeholder"},
+		{"batch", filepath.Join(t.TempDir(), "empty.txt~")},
+	} {
+		env, _, _ := newTestEnv(t)
+		if code := cli.Run(context.Background(), env, args); code == 0 {
+			t.Fatalf("%v: expected a failure exit code", args)
+		}
+	}
+}
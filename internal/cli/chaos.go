@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/chaos"
+)
+
+// The --chaos-* flags inject faults for resilience testing. They are accepted before any
+// command and are deliberately left out of usage and shell completion.
+var chaosRateFlags = map[string]func(*chaos.Rates, float64){
+	"--chaos-timeout":        func(r *chaos.Rates, v float64) { r.ProviderTimeout = v },
+	"--chaos-malformed-sse":  func(r *chaos.Rates, v float64) { r.MalformedSSE = v },
+	"--chaos-mcp-disconnect": func(r *chaos.Rates, v float64) { r.MCPDisconnect = v },
+}
+
+const chaosSeedFlag = "--chaos-seed"
+
+// extractChaosFlags removes the chaos flags from args and returns the injector they
+// configure, or nil when none was given.
+func extractChaosFlags(args []string) ([]string, *chaos.Injector, error) {
+	var (
+		rest    []string
+		rates   chaos.Rates
+		seed    = time.Now().UnixNano()
+		enabled bool
+	)
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		set, isRate := chaosRateFlags[name]
+		if !isRate && name != chaosSeedFlag {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == chaosSeedFlag {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+			seed = parsed
+			continue
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, nil, fmt.Errorf("invalid %s %q (use a rate between 0 and 1)", name, value)
+		}
+		set(&rates, rate)
+		enabled = true
+	}
+	if !enabled {
+		return args, nil, nil
+	}
+	return rest, chaos.New(rates, seed), nil
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/chaos"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// Environment carries the process-level dependencies shared by every subcommand.
+type Environment struct {
+	Home   string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// StdinPiped reports that Stdin is a pipe or file rather than a terminal; with -p its
+	// content is attached to the prompt.
+	StdinPiped bool
+
+	// faults is set by the hidden --chaos-* flags.
+	faults *chaos.Injector
+}
+
+func (e Environment) configDir() string {
+	return config.Dir(e.Home)
+}
+
+func (e Environment) sessionsDir() string {
+	return filepath.Join(e.configDir(), "sessions")
+}
+
+// providerFactory builds the LLM provider factory, injecting faults in chaos mode.
+func (e Environment) providerFactory() *llm.Factory {
+	return llm.NewFactory(e.faults.HTTPClient(nil))
+}
+
+// stdoutIsTerminal reports whether styled output can be written to stdout.
+func (e Environment) stdoutIsTerminal() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := e.Stdout.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, env Environment, args []string) int
+	// complete describes the command's flags and arguments for shell completion scripts.
+	complete completionSpec
+}
+
+var commands = map[string]command{
+	"show": {
+		summary:  "Pretty-print a saved session transcript.",
+		run:      runShow,
+		complete: completionSpec{flags: []string{"--no-color"}, sessions: true},
+	},
+	"replay": {
+		summary:  "Re-run a saved session's user messages against another model.",
+		run:      runReplay,
+		complete: completionSpec{flags: []string{"--model", "--tools"}, sessions: true},
+	},
+	"export-dataset": {
+		summary:  "Convert saved sessions into an OpenAI-style JSONL chat dataset.",
+		run:      runExportDataset,
+		complete: completionSpec{flags: []string{"-o", "--tag", "--strip-tools", "--strip-thinking", "--system"}, sessions: true},
+	},
+	"config": {
+		summary:  "Read, change or validate config.json (get, set, list, validate).",
+		run:      runConfig,
+		complete: completionSpec{flags: []string{"--show-secrets"}, words: []string{"get", "set", "list", "validate"}},
+	},
+	"batch": {
+		summary:  "Send each prompt of a file (lines or YAML items) and write every answer to a file.",
+		run:      runBatch,
+		complete: completionSpec{flags: []string{"--model", "--template", "--out-dir"}},
+	},
+	"run": {
+		summary:  "Run a prompt template on a cron-like schedule, writing each answer to a file.",
+		run:      runScheduled,
+		complete: completionSpec{flags: []string{"--schedule", "-p", "--prompt-file", "--model", "--out-dir", "--runs"}},
+	},
+	"tui": {
+		summary: "Start the interactive chat in a full-screen terminal UI.",
+		run:     runTUI,
+	},
+	"version": {
+		summary: "Print version and build information.",
+		run:     runVersion,
+	},
+	"self-update": {
+		summary:  "Check GitHub releases and install a newer, checksum-verified binary.",
+		run:      runSelfUpdate,
+		complete: completionSpec{flags: []string{"--check", "--force"}},
+	},
+}
+
+// Run dispatches args to a subcommand, or starts the interactive chat loop when none is given.
+func Run(ctx context.Context, env Environment, args []string) int {
+	args, faults, err := extractChaosFlags(args)
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	if faults != nil {
+		env.faults = faults
+		fmt.Fprintf(env.Stderr, "Chaos mode: injecting %s\n", faults)
+	}
+	args, profile, err := extractProfileFlag(args)
+	if err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	if err := selectProfile(env, profile); err != nil {
+		fmt.Fprintln(env.Stderr, err)
+		return exitUsage
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "--help":
+			printUsage(env.Stdout)
+			return 0
+		}
+		if cmd, ok := commands[args[0]]; ok {
+			return cmd.run(ctx, env, args[1:])
+		}
+		if strings.HasPrefix(args[0], "-") {
+			return runOneShot(ctx, env, args)
+		}
+		fmt.Fprintf(env.Stderr, "unknown command %q\n\n", args[0])
+		printUsage(env.Stderr)
+		return 2
+	}
+	return runInteractive(ctx, env)
+}
+
+func runInteractive(ctx context.Context, env Environment) int {
+	return runChat(ctx, env, app.Options{
+		Input:       env.Stdin,
+		Output:      env.Stdout,
+		ErrorOutput: env.Stderr,
+	})
+}
+
+// runChat runs the interactive loop on the frontend set in opts.
+func runChat(ctx context.Context, env Environment, opts app.Options) int {
+	opts.Store = config.NewFileStore(env.Home)
+	opts.Factory = env.providerFactory()
+	opts.HistoryRootDir = env.sessionsDir()
+	opts.HomeDir = env.Home
+	opts.Faults = env.faults
+	instance, err := app.New(opts)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "failed to initialize application: %v\n", err)
+		return 1
+	}
+
+	if err := instance.Run(ctx); err != nil {
+		fmt.Fprintf(env.Stderr, "application error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: humble-ai-cli [command] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the interactive chat loop starts.")
+	fmt.Fprintln(w, "Use -p <prompt> [--model <name>] [--quiet] to ask a single question and exit.")
+	fmt.Fprintf(w, "Use --profile <name> (or %s) to use the configuration in ~/.humble-ai-cli/profiles/<name>.\n", config.ProfileEnv)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w)
+	printExitCodes(w)
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
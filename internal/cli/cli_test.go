@@ -0,0 +1,117 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func newTestEnv(t *testing.T) (cli.Environment, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	return cli.Environment{
+		Home:   t.TempDir(),
+		Stdin:  strings.NewReader(""),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}, &stdout, &stderr
+}
+
+func writeSession(t *testing.T, home, name string, session history.Session) string {
+	t.Helper()
+	dir := filepath.Join(home, ".humble-ai-cli", "sessions")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create sessions dir: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := history.Save(path, session); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+	return path
+}
+
+func TestRunShowPrintsTranscript(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeSession(t, env.Home, "20250102_030405_hello.json", history.Session{
+		Model:     "llama3",
+		StartedAt: at,
+		Messages: []history.Message{
+			{Role: "user", Content: "hello", Timestamp: at},
+			{Role: "assistant", Content: "hi there", Timestamp: at},
+		},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"show", "20250102_030405_hello"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	got := stdout.String()
+	for _, phrase := range []string{"Model: llama3", "You:", "hello", "Assistant:", "hi there"} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected output to contain %q, got:\n%s", phrase, got)
+		}
+	}
+}
+
+func TestRunShowUsesConfiguredLabels(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		UserLabel:      "Me",
+		AssistantLabel: "Bot",
+		Models:         []config.Model{{Name: "llama3", Provider: "ollama", AssistantLabel: "Llama"}},
+	})
+	writeSession(t, env.Home, "20250102_030405_hello.json", history.Session{
+		Model: "llama3",
+		Messages: []history.Message{
+			{Role: "user", Content: "hello"},
+			{Role: "assistant", Content: "hi there"},
+		},
+	})
+
+	if code := cli.Run(context.Background(), env, []string{"show", "20250102_030405_hello"}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	got := stdout.String()
+	if !strings.Contains(got, "Me:\nhello") || !strings.Contains(got, "Llama:\nhi there") {
+		t.Fatalf("expected configured labels, got:\n%s", got)
+	}
+}
+
+func TestRunShowReportsMissingSession(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"show", "nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "session not found") {
+		t.Fatalf("expected not found error, got %q", stderr.String())
+	}
+}
+
+func TestRunRejectsUnknownCommand(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"bogus"}); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Commands:") {
+		t.Fatalf("expected usage on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunTUIRequiresTerminal(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"tui"}); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "tui: the full-screen UI needs an interactive terminal") {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const binaryName = "humble-ai-cli"
+
+// completionSpec lists what a subcommand accepts so completion scripts can be generated.
+type completionSpec struct {
+	flags []string
+	// words are the fixed choices for the first positional argument.
+	words []string
+	// sessions reports whether positional arguments name saved sessions.
+	sessions bool
+}
+
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// rootCompletion lists the flags accepted without a command. They are offered for the
+// first word and after it when that word is a flag. The chaos flags stay hidden on purpose.
+var rootCompletion = completionSpec{flags: []string{"--help", "-p", "--prompt", "--model", "--quiet", "--watch", "--watch-interval", "--profile", "--thinking"}}
+
+// flagValueCompletions maps flags that take a value to what should be offered for it:
+// "models", "profiles", "files", or a space-separated list of choices.
+var flagValueCompletions = map[string]string{
+	"--model":    "models",
+	"--profile":  "profiles",
+	"--thinking": "stdout stderr drop auto",
+	"--tools":    "stub execute",
+	"-o":         "files",
+}
+
+// freeValueFlags take a value nothing can be suggested for.
+var freeValueFlags = []string{"--tag", "--system", "-p", "--prompt", "--watch", "--watch-interval"}
+
+const listModelsCommand = binaryName + ` config list 2>/dev/null | sed -n 's/^models\.[0-9]*\.name=//p'`
+
+const listProfilesCommand = `command ls "$HOME/.humble-ai-cli/profiles" 2>/dev/null`
+
+func init() {
+	commands["completion"] = command{
+		summary:  "Print a shell completion script (bash, zsh, fish, powershell).",
+		run:      runCompletion,
+		complete: completionSpec{words: completionShells},
+	}
+}
+
+func runCompletion(_ context.Context, env Environment, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(env.Stderr, "Usage: %s completion <%s>\n", binaryName, strings.Join(completionShells, "|"))
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "bash":
+		err = writeBashCompletion(env.Stdout)
+	case "zsh":
+		err = writeZshCompletion(env.Stdout)
+	case "fish":
+		err = writeFishCompletion(env.Stdout)
+	case "powershell":
+		err = writePowerShellCompletion(env.Stdout)
+	default:
+		fmt.Fprintf(env.Stderr, "completion: unsupported shell %q (want %s)\n", args[0], strings.Join(completionShells, ", "))
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "completion: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands)+1)
+	for name := range commands {
+		names = append(names, name)
+	}
+	names = append(names, "help")
+	sort.Strings(names)
+	return names
+}
+
+func valueFlags() []string {
+	flags := make([]string, 0, len(flagValueCompletions))
+	for flag := range flagValueCompletions {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+func sortedCommands() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeBashCompletion(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", binaryName)
+	fmt.Fprintf(&b, "# Load with: source <(%s completion bash)\n\n", binaryName)
+	b.WriteString("_humble_ai_cli_sessions() {\n")
+	b.WriteString("    local dir=\"$HOME/.humble-ai-cli/sessions\"\n")
+	b.WriteString("    [[ -d \"$dir\" ]] && command ls \"$dir\" 2>/dev/null | sed -n 's/\\.json$//p'\n")
+	b.WriteString("}\n\n")
+	b.WriteString("_humble_ai_cli() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	rootFlags := strings.Join(rootCompletion.flags, " ")
+	b.WriteString("    case \"$prev\" in\n")
+	for _, flag := range valueFlags() {
+		fmt.Fprintf(&b, "        %s)\n            %s\n            return ;;\n", flag, bashValues(flagValueCompletions[flag]))
+	}
+	fmt.Fprintf(&b, "        %s)\n            return ;;\n", strings.Join(freeValueFlags, "|"))
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&b, "        if [[ \"$cur\" == -* ]]; then\n            COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", rootFlags)
+	fmt.Fprintf(&b, "        else\n            COMPREPLY=($(compgen -W %q -- \"$cur\"))\n        fi\n", strings.Join(commandNames(), " "))
+	b.WriteString("        return\n    fi\n")
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, name := range sortedCommands() {
+		spec := commands[name].complete
+		fmt.Fprintf(&b, "        %s)\n", name)
+		if len(spec.flags) > 0 {
+			fmt.Fprintf(&b, "            if [[ \"$cur\" == -* ]]; then\n                COMPREPLY=($(compgen -W %q -- \"$cur\"))\n                return\n            fi\n", strings.Join(spec.flags, " "))
+		}
+		switch {
+		case len(spec.words) > 0:
+			fmt.Fprintf(&b, "            [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(spec.words, " "))
+		case spec.sessions:
+			b.WriteString("            COMPREPLY=($(compgen -W \"$(_humble_ai_cli_sessions)\" -- \"$cur\"))\n")
+		}
+		b.WriteString("            ;;\n")
+	}
+	fmt.Fprintf(&b, "        -*)\n            [[ \"$cur\" == -* ]] && COMPREPLY=($(compgen -W %q -- \"$cur\"))\n            ;;\n", rootFlags)
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "complete -F _humble_ai_cli %s\n", binaryName)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeZshCompletion(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", binaryName)
+	fmt.Fprintf(&b, "# zsh completion for %s\n", binaryName)
+	fmt.Fprintf(&b, "# Load with: source <(%s completion zsh)\n\n", binaryName)
+	b.WriteString("_humble_ai_cli_sessions() {\n")
+	b.WriteString("    local dir=\"$HOME/.humble-ai-cli/sessions\"\n")
+	b.WriteString("    [[ -d \"$dir\" ]] && command ls \"$dir\" 2>/dev/null | sed -n 's/\\.json$//p'\n")
+	b.WriteString("}\n\n")
+	b.WriteString("_humble_ai_cli() {\n")
+	b.WriteString("    local cmd=${words[2]} prev=${words[CURRENT-1]}\n")
+	b.WriteString("    case $prev in\n")
+	for _, flag := range valueFlags() {
+		fmt.Fprintf(&b, "        %s) %s; return ;;\n", flag, zshValues(flagValueCompletions[flag]))
+	}
+	fmt.Fprintf(&b, "        %s) return ;;\n", strings.Join(freeValueFlags, "|"))
+	b.WriteString("    esac\n")
+	fmt.Fprintf(&b, "    if [[ $PREFIX == -* && ( $CURRENT -eq 2 || $cmd == -* ) ]]; then\n        compadd -- %s\n        return\n    fi\n", strings.Join(rootCompletion.flags, " "))
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	b.WriteString("        local -a subcommands\n        subcommands=(\n")
+	for _, name := range commandNames() {
+		summary := "Show usage."
+		if cmd, ok := commands[name]; ok {
+			summary = cmd.summary
+		}
+		fmt.Fprintf(&b, "            %s\n", zshQuote(name+":"+summary))
+	}
+	b.WriteString("        )\n        _describe 'command' subcommands\n        return\n    fi\n")
+	b.WriteString("    case $cmd in\n")
+	for _, name := range sortedCommands() {
+		spec := commands[name].complete
+		fmt.Fprintf(&b, "        %s)\n", name)
+		if len(spec.flags) > 0 {
+			fmt.Fprintf(&b, "            if [[ $PREFIX == -* ]]; then\n                compadd -- %s\n                return\n            fi\n", strings.Join(spec.flags, " "))
+		}
+		switch {
+		case len(spec.words) > 0:
+			fmt.Fprintf(&b, "            (( CURRENT == 3 )) && compadd -- %s\n", strings.Join(spec.words, " "))
+		case spec.sessions:
+			b.WriteString("            compadd -- ${(f)\"$(_humble_ai_cli_sessions)\"}\n")
+		}
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "compdef _humble_ai_cli %s\n", binaryName)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bashValues completes the value of a flag from its flagValueCompletions kind.
+func bashValues(kind string) string {
+	switch kind {
+	case "models":
+		return fmt.Sprintf("COMPREPLY=($(compgen -W \"$(%s)\" -- \"$cur\"))", listModelsCommand)
+	case "profiles":
+		return fmt.Sprintf("COMPREPLY=($(compgen -W \"$(%s)\" -- \"$cur\"))", listProfilesCommand)
+	case "files":
+		return "COMPREPLY=($(compgen -f -- \"$cur\"))"
+	}
+	return fmt.Sprintf("COMPREPLY=($(compgen -W %q -- \"$cur\"))", kind)
+}
+
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// zshValues completes the value of a flag from its flagValueCompletions kind.
+func zshValues(kind string) string {
+	switch kind {
+	case "models":
+		return fmt.Sprintf("compadd -- ${(f)\"$(%s)\"}", listModelsCommand)
+	case "profiles":
+		return fmt.Sprintf("compadd -- ${(f)\"$(%s)\"}", listProfilesCommand)
+	case "files":
+		return "_files"
+	}
+	return "compadd -- " + kind
+}
+
+func writeFishCompletion(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n", binaryName)
+	fmt.Fprintf(&b, "# Load with: %s completion fish | source\n\n", binaryName)
+	b.WriteString("function __humble_ai_cli_sessions\n")
+	b.WriteString("    set -l dir $HOME/.humble-ai-cli/sessions\n")
+	b.WriteString("    test -d $dir; and command ls $dir 2>/dev/null | string replace -rf '\\.json$' ''\n")
+	b.WriteString("end\n\n")
+	fmt.Fprintf(&b, "complete -c %s -f\n", binaryName)
+	for _, name := range commandNames() {
+		summary := "Show usage."
+		if cmd, ok := commands[name]; ok {
+			summary = cmd.summary
+		}
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", binaryName, name, fishQuote(summary))
+	}
+	rootCond := fishQuote("not __fish_seen_subcommand_from " + strings.Join(sortedCommands(), " "))
+	for _, flag := range rootCompletion.flags {
+		fmt.Fprintf(&b, "complete -c %s -n %s %s\n", binaryName, rootCond, fishFlagOption(flag))
+	}
+	for _, name := range sortedCommands() {
+		spec := commands[name].complete
+		cond := fishQuote("__fish_seen_subcommand_from " + name)
+		for _, flag := range spec.flags {
+			fmt.Fprintf(&b, "complete -c %s -n %s %s\n", binaryName, cond, fishFlagOption(flag))
+		}
+		switch {
+		case len(spec.words) > 0:
+			fmt.Fprintf(&b, "complete -c %s -n %s -a %s\n", binaryName,
+				fishQuote("__fish_seen_subcommand_from "+name+"; and not __fish_seen_subcommand_from "+strings.Join(spec.words, " ")),
+				fishQuote(strings.Join(spec.words, " ")))
+		case spec.sessions:
+			fmt.Fprintf(&b, "complete -c %s -n %s -a '(__humble_ai_cli_sessions)'\n", binaryName, cond)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishFlagOption declares flag and what its value completes to.
+func fishFlagOption(flag string) string {
+	option := "-l " + strings.TrimLeft(flag, "-")
+	if !strings.HasPrefix(flag, "--") {
+		option = "-s " + strings.TrimLeft(flag, "-")
+	}
+	switch kind := flagValueCompletions[flag]; kind {
+	case "":
+		if slices.Contains(freeValueFlags, flag) {
+			option += " -x"
+		}
+	case "models":
+		option += " -x -a " + fishQuote("("+binaryName+` config list 2>/dev/null | string replace -rf '^models\.[0-9]+\.name=' '')`)
+	case "profiles":
+		option += " -x -a " + fishQuote("(command ls $HOME/.humble-ai-cli/profiles 2>/dev/null)")
+	case "files":
+		option += " -r -F"
+	default:
+		option += " -x -a " + fishQuote(kind)
+	}
+	return option
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func writePowerShellCompletion(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# PowerShell completion for %s\n", binaryName)
+	fmt.Fprintf(&b, "# Load with: %s completion powershell | Out-String | Invoke-Expression\n\n", binaryName)
+	fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {\n", binaryName)
+	b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+	b.WriteString("    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })\n")
+	b.WriteString("    if ($wordToComplete -ne '') { $words = @($words | Select-Object -SkipLast 1) }\n")
+	b.WriteString("    $prev = $words[-1]\n")
+	b.WriteString("    $sessionDir = Join-Path $HOME '.humble-ai-cli/sessions'\n")
+	b.WriteString("    $candidates = @()\n")
+	clause := "if"
+	for _, flag := range valueFlags() {
+		fmt.Fprintf(&b, "    %s ($prev -eq '%s') {\n        %s\n", clause, flag, psValues(flagValueCompletions[flag]))
+		clause = "} elseif"
+	}
+	conds := make([]string, len(freeValueFlags))
+	for i, flag := range freeValueFlags {
+		conds[i] = fmt.Sprintf("$prev -eq '%s'", flag)
+	}
+	fmt.Fprintf(&b, "    } elseif (%s) {\n        return\n", strings.Join(conds, " -or "))
+	b.WriteString("    } elseif ($wordToComplete -like '-*' -and ($words.Count -eq 1 -or $words[1] -like '-*')) {\n")
+	fmt.Fprintf(&b, "        $candidates = %s\n", psList(rootCompletion.flags))
+	b.WriteString("    } elseif ($words.Count -eq 1) {\n")
+	fmt.Fprintf(&b, "        $candidates = %s\n", psList(commandNames()))
+	b.WriteString("    } else {\n")
+	b.WriteString("        switch ($words[1]) {\n")
+	for _, name := range sortedCommands() {
+		spec := commands[name].complete
+		fmt.Fprintf(&b, "            '%s' {\n", name)
+		if len(spec.flags) > 0 {
+			fmt.Fprintf(&b, "                if ($wordToComplete -like '-*') { $candidates = %s; break }\n", psList(spec.flags))
+		}
+		switch {
+		case len(spec.words) > 0:
+			fmt.Fprintf(&b, "                if ($words.Count -eq 2) { $candidates = %s }\n", psList(spec.words))
+		case spec.sessions:
+			b.WriteString("                if (Test-Path $sessionDir) { $candidates = @(Get-ChildItem $sessionDir -Filter '*.json' | ForEach-Object { $_.BaseName }) }\n")
+		}
+		b.WriteString("            }\n")
+	}
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+	b.WriteString("    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	b.WriteString("        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// psValues completes the value of a flag from its flagValueCompletions kind; files are
+// left to PowerShell's own path completion.
+func psValues(kind string) string {
+	switch kind {
+	case "models":
+		return fmt.Sprintf("$candidates = @(& '%s' config list 2>$null | ForEach-Object { if ($_ -match '^models\\.\\d+\\.name=(.*)$') { $Matches[1] } })", binaryName)
+	case "profiles":
+		return "$profileDir = Join-Path $HOME '.humble-ai-cli/profiles'; if (Test-Path $profileDir) { $candidates = @(Get-ChildItem $profileDir -Directory | ForEach-Object { $_.Name }) }"
+	case "files":
+		return "return"
+	}
+	return "$candidates = " + psList(strings.Fields(kind))
+}
+
+func psList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "'" + strings.ReplaceAll(item, "'", "''") + "'"
+	}
+	return "@(" + strings.Join(quoted, ", ") + ")"
+}
@@ -0,0 +1,115 @@
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func TestRunCompletionCoversCommandsAndFlags(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		env, stdout, stderr := newTestEnv(t)
+		if code := cli.Run(context.Background(), env, []string{"completion", shell}); code != 0 {
+			t.Fatalf("%s: exit code %d (%s)", shell, code, stderr.String())
+		}
+		script := stdout.String()
+		for _, want := range []string{
+			"show", "replay", "export-dataset", "config", "completion",
+			"no-color", "model", "strip-thinking", "show-secrets", "validate",
+			"stub", "execute", ".humble-ai-cli",
+		} {
+			if !strings.Contains(script, want) {
+				t.Fatalf("%s script missing %q:\n%s", shell, want, script)
+			}
+		}
+	}
+}
+
+func TestRunCompletionBashScriptCompletes(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	env, stdout, _ := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"completion", "bash"}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	scriptPath := filepath.Join(t.TempDir(), "completion.bash")
+	if err := os.WriteFile(scriptPath, stdout.Bytes(), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	writeSession(t, env.Home, "20250102_030405_hello.json", history.Session{Model: "llama3"})
+
+	probe := `source "$1"; COMP_WORDS=(humble-ai-cli sh); COMP_CWORD=1; _humble_ai_cli; echo "${COMPREPLY[*]}";` +
+		` COMP_WORDS=(humble-ai-cli show 2025); COMP_CWORD=2; _humble_ai_cli; echo "${COMPREPLY[*]}"`
+	cmd := exec.Command(bash, "-c", probe, "bash", scriptPath)
+	cmd.Env = append(os.Environ(), "HOME="+env.Home)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash completion failed: %v\n%s", err, out)
+	}
+	if got := string(out); got != "show\n20250102_030405_hello\n" {
+		t.Fatalf("unexpected completions %q", got)
+	}
+}
+
+func TestRunCompletionBashCompletesRootFlags(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	env, stdout, _ := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"completion", "bash"}); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	scriptPath := filepath.Join(t.TempDir(), "completion.bash")
+	if err := os.WriteFile(scriptPath, stdout.Bytes(), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	tests := []struct {
+		words string
+		want  string
+	}{
+		{words: "humble-ai-cli --he", want: "--help"},
+		{words: "humble-ai-cli -p hello --q", want: "--quiet"},
+		{words: "humble-ai-cli --quiet --pro", want: "--prompt --profile"},
+		{words: "humble-ai-cli --profile w", want: "work"},
+		{words: "humble-ai-cli -p why --thinking std", want: "stdout stderr"},
+		{words: "humble-ai-cli -p review --watch-", want: "--watch-interval"},
+		{words: "humble-ai-cli -p review --watch --", want: ""},
+	}
+	if err := os.MkdirAll(filepath.Join(env.Home, ".humble-ai-cli", "profiles", "work"), 0o755); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	for _, tt := range tests {
+		words := strings.Fields(tt.words)
+		probe := fmt.Sprintf(`source "$1"; COMP_WORDS=(%s); COMP_CWORD=%d; _humble_ai_cli; echo "${COMPREPLY[*]}"`, tt.words, len(words)-1)
+		cmd := exec.Command(bash, "-c", probe, "bash", scriptPath)
+		cmd.Env = append(os.Environ(), "HOME="+env.Home)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("bash completion failed: %v\n%s", err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Fatalf("completing %q: got %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestRunCompletionRejectsUnknownShell(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"completion", "tcsh"}); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "unsupported shell") {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+var configCommands = map[string]func(ctx context.Context, env Environment, args []string) int{
+	"get":      runConfigGet,
+	"set":      runConfigSet,
+	"list":     runConfigList,
+	"validate": runConfigValidate,
+}
+
+func runConfig(ctx context.Context, env Environment, args []string) int {
+	if len(args) > 0 {
+		if run, ok := configCommands[args[0]]; ok {
+			return run(ctx, env, args[1:])
+		}
+	}
+	printConfigUsage(env)
+	return 2
+}
+
+func printConfigUsage(env Environment) {
+	fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli config <get|set|list|validate> [arguments]")
+	fmt.Fprintln(env.Stderr, "  get <key>          Print the value of a key such as toolCallMode or models.0.name.")
+	fmt.Fprintln(env.Stderr, "  set <key> <value>  Set a key; JSON values are parsed, null removes the key.")
+	fmt.Fprintln(env.Stderr, "  list [--show-secrets]  Print every configured key and value.")
+	fmt.Fprintln(env.Stderr, "  validate           Check config.json and mcp-servers.json for mistakes.")
+}
+
+// loadConfigForEdit reads config.json, treating a missing file as an empty configuration.
+func loadConfigForEdit(store config.Store) (config.Config, error) {
+	cfg, err := store.Load()
+	if errors.Is(err, config.ErrNotFound) {
+		return config.Config{}, nil
+	}
+	return cfg, err
+}
+
+func runConfigGet(_ context.Context, env Environment, args []string) int {
+	if len(args) != 1 {
+		printConfigUsage(env)
+		return 2
+	}
+	cfg, err := loadConfigForEdit(config.NewFileStore(env.Home))
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config get: %v\n", err)
+		return 1
+	}
+	value, err := config.Get(cfg, args[0])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config get: %v\n", err)
+		return 1
+	}
+	if s, ok := value.(string); ok {
+		fmt.Fprintln(env.Stdout, s)
+		return 0
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config get: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(env.Stdout, string(data))
+	return 0
+}
+
+func runConfigSet(_ context.Context, env Environment, args []string) int {
+	if len(args) != 2 {
+		printConfigUsage(env)
+		return 2
+	}
+	store := config.NewFileStore(env.Home)
+	cfg, err := loadConfigForEdit(store)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config set: %v\n", err)
+		return 1
+	}
+	updated, err := config.Set(cfg, args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config set: %v\n", err)
+		return 1
+	}
+	if err := store.Save(updated); err != nil {
+		fmt.Fprintf(env.Stderr, "config set: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runConfigList(_ context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("config list", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	showSecrets := fs.Bool("show-secrets", false, "print API keys instead of masking them")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	cfg, err := loadConfigForEdit(config.NewFileStore(env.Home))
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config list: %v\n", err)
+		return 1
+	}
+	settings, err := config.List(cfg)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "config list: %v\n", err)
+		return 1
+	}
+	for _, s := range settings {
+		value := formatSetting(s.Value)
+		if !*showSecrets && (strings.HasSuffix(s.Key, ".apiKey") || strings.HasSuffix(s.Key, ".password")) {
+			value = maskSecret(value)
+		}
+		fmt.Fprintf(env.Stdout, "%s=%s\n", s.Key, value)
+	}
+	return 0
+}
+
+func formatSetting(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func maskSecret(value string) string {
+	if len(value) <= 4 {
+		return "****"
+	}
+	return "****" + value[len(value)-4:]
+}
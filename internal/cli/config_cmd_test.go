@@ -0,0 +1,71 @@
+package cli_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestRunConfigSetGetList(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+	run := func(args ...string) int {
+		stdout.Reset()
+		stderr.Reset()
+		return cli.Run(context.Background(), env, append([]string{"config"}, args...))
+	}
+
+	for _, args := range [][]string{
+		{"set", "models.0", `{"name": "gpt-4o", "provider": "openai", "apiKey": "sk-secret-1234"}`},
+		{"set", "models.0.active", "true"},
+		{"set", "toolCallMode", "auto"},
+	} {
+		if code := run(args...); code != 0 {
+			t.Fatalf("config %v exit code %d: %s", args, code, stderr.String())
+		}
+	}
+
+	cfg, err := config.NewFileStore(env.Home).Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.ToolCallMode != "auto" || cfg.ActiveModelName() != "gpt-4o" {
+		t.Fatalf("unexpected saved config %+v", cfg)
+	}
+
+	if code := run("get", "models.0.name"); code != 0 || stdout.String() != "gpt-4o\n" {
+		t.Fatalf("config get = %d %q", code, stdout.String())
+	}
+
+	if code := run("list"); code != 0 {
+		t.Fatalf("config list exit code %d", code)
+	}
+	want := "models.0.active=true\nmodels.0.apiKey=****1234\nmodels.0.name=gpt-4o\nmodels.0.provider=openai\ntoolCallMode=auto\n"
+	if stdout.String() != want {
+		t.Fatalf("unexpected list output:\n%s", stdout.String())
+	}
+	if run("list", "--show-secrets"); !strings.Contains(stdout.String(), "models.0.apiKey=sk-secret-1234") {
+		t.Fatalf("expected unmasked key, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunConfigSetRejectsInvalidValues(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	for _, args := range [][]string{
+		{"config", "set", "toolCallMode", "sometimes"},
+		{"config", "set", "toolCalMode", "auto"},
+	} {
+		stderr.Reset()
+		if code := cli.Run(context.Background(), env, args); code != 1 {
+			t.Fatalf("%v: expected exit code 1, got %d", args, code)
+		}
+		if !strings.Contains(stderr.String(), "config set:") {
+			t.Fatalf("%v: expected error message, got %q", args, stderr.String())
+		}
+	}
+	if _, err := config.NewFileStore(env.Home).Load(); err != config.ErrNotFound {
+		t.Fatalf("expected no config to be written, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/jsoncheck"
+	"github.com/gamzabox/humble-ai-cli/internal/mcp"
+)
+
+func runConfigValidate(_ context.Context, env Environment, args []string) int {
+	usage := func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli config validate")
+		fmt.Fprintln(env.Stderr, "Checks config.json and mcp-servers.json for unknown fields, wrong types and invalid values.")
+	}
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		usage()
+		return 2
+	}
+
+	failed := false
+	configPath := filepath.Join(env.configDir(), "config.json")
+	issues, err := config.CheckFile(configPath)
+	if errors.Is(err, config.ErrNotFound) {
+		fmt.Fprintf(env.Stdout, "%s: not found, skipped\n", configPath)
+	} else {
+		failed = reportIssues(env, configPath, issues, err) || failed
+	}
+
+	mcpPath := filepath.Join(env.configDir(), "mcp-servers.json")
+	issues, err = mcp.CheckConfigFile(mcpPath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(env.Stdout, "%s: not found, skipped\n", mcpPath)
+	} else {
+		failed = reportIssues(env, mcpPath, issues, err) || failed
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// reportIssues prints file problems in a compiler-like format and reports whether any were found.
+func reportIssues(env Environment, path string, issues []jsoncheck.Issue, err error) bool {
+	if err != nil {
+		fmt.Fprintf(env.Stdout, "%s: %v\n", path, err)
+		return true
+	}
+	if len(issues) == 0 {
+		fmt.Fprintf(env.Stdout, "%s: ok\n", path)
+		return false
+	}
+	for _, issue := range issues {
+		location := path
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", path, issue.Line, issue.Column)
+		}
+		if issue.Path != "" {
+			fmt.Fprintf(env.Stdout, "%s: %s: %s\n", location, issue.Path, issue.Message)
+		} else {
+			fmt.Fprintf(env.Stdout, "%s: %s\n", location, issue.Message)
+		}
+	}
+	return true
+}
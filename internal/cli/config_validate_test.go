@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+)
+
+func writeConfigFile(t *testing.T, home, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(home, ".humble-ai-cli")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestRunConfigValidateReportsPositions(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+	configPath := writeConfigFile(t, env.Home, "config.json", `{
+  "toolCalMode": "auto",
+  "models": [
+    {"name": "gpt", "provider": "openai", "contextWindow": "8k"}
+  ]
+}`)
+	mcpPath := writeConfigFile(t, env.Home, "mcp-servers.json", `{
+  "mcpServers": {
+    "fs": {"command": "npx", "args": "server-filesystem"}
+  }
+}`)
+
+	if code := cli.Run(context.Background(), env, []string{"config", "validate"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr=%s)", code, stderr.String())
+	}
+	got := stdout.String()
+	for _, want := range []string{
+		configPath + `:2:3: toolCalMode: unknown field (did you mean "toolCallMode"?)`,
+		configPath + `:4:60: models[0].contextWindow: expected integer, found string`,
+		mcpPath + `:3:38: mcpServers.fs.args: expected array, found string`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunConfigValidateAcceptsValidFiles(t *testing.T) {
+	env, stdout, _ := newTestEnv(t)
+	configPath := writeConfigFile(t, env.Home, "config.json", `{"toolCallMode": "auto"}`)
+
+	if code := cli.Run(context.Background(), env, []string{"config", "validate"}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d:\n%s", code, stdout.String())
+	}
+	got := stdout.String()
+	if !strings.Contains(got, configPath+": ok") || !strings.Contains(got, "mcp-servers.json: not found, skipped") {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestRunConfigValidateReportsInvalidServers(t *testing.T) {
+	env, stdout, _ := newTestEnv(t)
+	mcpPath := writeConfigFile(t, env.Home, "mcp-servers.json", `{"mcpServers": {"broken": {"description": "no command"}}}`)
+
+	if code := cli.Run(context.Background(), env, []string{"config", "validate"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	want := mcpPath + `: mcpServers.broken: server "broken" must define either a command or url`
+	if !strings.Contains(stdout.String(), want) {
+		t.Fatalf("expected %q, got:\n%s", want, stdout.String())
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func runExportDataset(_ context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("export-dataset", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	outputPath := fs.String("o", "", "write JSONL to this file instead of stdout")
+	tag := fs.String("tag", "", "only export sessions carrying this tag")
+	stripTools := fs.Bool("strip-tools", false, "drop tool calls and tool results")
+	stripThinking := fs.Bool("strip-thinking", false, "remove inline <think> reasoning from answers")
+	systemPrompt := fs.String("system", "", "prepend this system prompt to every example")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli export-dataset [flags] [session...]")
+		fmt.Fprintln(env.Stderr, "Without session arguments every saved session is exported.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	paths, err := selectSessions(env, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "export-dataset: %v\n", err)
+		return 1
+	}
+
+	var (
+		out  io.Writer = env.Stdout
+		file *os.File
+	)
+	if *outputPath != "" {
+		file, err = os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "export-dataset: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	opts := history.DatasetOptions{
+		StripToolCalls: *stripTools,
+		StripThinking:  *stripThinking,
+		SystemPrompt:   *systemPrompt,
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	exported := 0
+	for _, path := range paths {
+		session, err := history.Load(path)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "export-dataset: skipping %v\n", err)
+			continue
+		}
+		if *tag != "" && !session.HasTag(*tag) {
+			continue
+		}
+		if len(session.Messages) == 0 {
+			continue
+		}
+		if err := encoder.Encode(history.ToDataset(session, opts)); err != nil {
+			fmt.Fprintf(env.Stderr, "export-dataset: %v\n", err)
+			return 1
+		}
+		exported++
+	}
+
+	if file != nil {
+		// Some filesystems report a failed write only when the file is closed.
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(env.Stderr, "export-dataset: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(env.Stderr, "Exported %d sessions to %s\n", exported, *outputPath)
+	}
+	return 0
+}
+
+// selectSessions resolves explicit session references, or lists every saved session.
+func selectSessions(env Environment, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return history.List(env.sessionsDir())
+	}
+	paths := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		path, err := history.Resolve(env.sessionsDir(), ref)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
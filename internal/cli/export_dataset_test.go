@@ -0,0 +1,45 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func TestRunExportDatasetWritesJSONLFilteredByTag(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+	writeSession(t, env.Home, "20250101_000000_a.json", history.Session{
+		Tags: []string{"eval"},
+		Messages: []history.Message{
+			{Role: "user", Content: "q1"},
+			{Role: "assistant", Content: "a1"},
+		},
+	})
+	writeSession(t, env.Home, "20250102_000000_b.json", history.Session{
+		Messages: []history.Message{
+			{Role: "user", Content: "q2"},
+			{Role: "assistant", Content: "a2"},
+		},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"export-dataset", "--tag", "eval"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 JSONL line, got %d:\n%s", len(lines), stdout.String())
+	}
+	var example history.DatasetExample
+	if err := json.Unmarshal([]byte(lines[0]), &example); err != nil {
+		t.Fatalf("invalid JSONL line: %v", err)
+	}
+	if len(example.Messages) != 2 || example.Messages[0].Content != "q1" {
+		t.Fatalf("unexpected example: %+v", example)
+	}
+}
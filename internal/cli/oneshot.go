@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/watch"
+)
+
+// Exit codes returned by one-shot mode so calling scripts can branch on the turn outcome.
+const (
+	exitOK            = 0
+	exitFailure       = 1
+	exitUsage         = 2
+	exitProviderError = 3
+	exitToolCall      = 4
+	exitCancelled     = 130
+)
+
+// oneShotExitCodes documents the exit codes in the order they are printed by --help.
+var oneShotExitCodes = []struct {
+	code    int
+	meaning string
+}{
+	{exitOK, "the model answered"},
+	{exitFailure, "the prompt was not sent (no usable model, rejected by a hook, setup error)"},
+	{exitUsage, "invalid arguments"},
+	{exitProviderError, "the provider failed or the response stream broke"},
+	{exitToolCall, "an MCP tool call was declined or failed"},
+	{exitCancelled, "the response was cancelled (CTRL+C)"},
+}
+
+func exitCodeFor(outcome app.TurnOutcome) int {
+	switch outcome {
+	case app.TurnOK:
+		return exitOK
+	case app.TurnProviderError:
+		return exitProviderError
+	case app.TurnToolFailed, app.TurnToolDeclined:
+		return exitToolCall
+	case app.TurnCancelled:
+		return exitCancelled
+	default:
+		return exitFailure
+	}
+}
+
+func printExitCodes(w io.Writer) {
+	fmt.Fprintln(w, "Exit codes (-p):")
+	for _, entry := range oneShotExitCodes {
+		fmt.Fprintf(w, "  %-4d %s\n", entry.code, entry.meaning)
+	}
+}
+
+// runOneShot sends a single prompt given with -p, prints the answer and exits.
+func runOneShot(ctx context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("humble-ai-cli", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	var prompt string
+	fs.StringVar(&prompt, "p", "", "prompt to send; the answer is printed and the program exits")
+	fs.StringVar(&prompt, "prompt", "", "alias for -p")
+	model := fs.String("model", "", "configured model to use instead of the active one")
+	quiet := fs.Bool("quiet", false, "print only the final assistant answer (no status lines, thinking or tool banners)")
+	thinking := fs.String("thinking", "", "where reasoning goes: stdout, stderr, drop or auto (stderr when stdout is piped); overrides thinkingOutput")
+	watchPattern := fs.String("watch", "", "re-run the prompt whenever files matching this glob change, attaching their contents")
+	watchInterval := fs.Duration("watch-interval", watch.DefaultInterval, "how often --watch polls the files")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli -p <prompt> [--model <name>] [--quiet] [--thinking <where>] [--watch <glob>]")
+		fmt.Fprintln(env.Stderr, "       <command> | humble-ai-cli -p <instruction>  (piped input is attached as context)")
+		fs.PrintDefaults()
+		fmt.Fprintln(env.Stderr)
+		printExitCodes(env.Stderr)
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(positional) > 0 || strings.TrimSpace(prompt) == "" || *watchInterval <= 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	var thinkingOutput config.ThinkingOutput
+	if *thinking != "" {
+		if thinkingOutput, err = config.ParseThinkingOutput(*thinking); err != nil {
+			fmt.Fprintf(env.Stderr, "invalid --thinking %q (use stdout, stderr, drop or auto)\n", *thinking)
+			return exitUsage
+		}
+	}
+
+	// Piped stdin is context for the prompt, so it cannot also answer confirmation prompts;
+	// destructive tool calls are then declined.
+	input, piped := env.Stdin, ""
+	if env.StdinPiped {
+		data, err := io.ReadAll(env.Stdin)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "read stdin: %v\n", err)
+			return exitFailure
+		}
+		input, piped = strings.NewReader(""), strings.TrimSpace(string(data))
+	}
+
+	run := oneShot{
+		env:    env,
+		input:  input,
+		prompt: prompt,
+		model:  *model,
+		quiet:  *quiet,
+		piped:  piped,
+
+		thinking: thinkingOutput,
+	}
+	if *watchPattern != "" {
+		return run.watch(ctx, *watchPattern, *watchInterval)
+	}
+	return run.ask(ctx, "")
+}
+
+// oneShot holds the settings of a -p invocation.
+type oneShot struct {
+	env    Environment
+	input  io.Reader
+	prompt string
+	model  string
+	quiet  bool
+	// piped is the stdin content attached to the prompt.
+	piped string
+	// thinking overrides the configured thinkingOutput when non-empty.
+	thinking config.ThinkingOutput
+}
+
+// ask sends the prompt once with the piped input and extra attached, and returns the exit code.
+func (o oneShot) ask(ctx context.Context, extra string) int {
+	attachment := o.piped
+	if extra != "" {
+		if attachment != "" {
+			attachment += "\n\n"
+		}
+		attachment += extra
+	}
+	instance, err := app.New(app.Options{
+		Store:          config.NewFileStore(o.env.Home),
+		Factory:        o.env.providerFactory(),
+		Input:          o.input,
+		Output:         o.env.Stdout,
+		ErrorOutput:    o.env.Stderr,
+		HistoryRootDir: o.env.sessionsDir(),
+		HomeDir:        o.env.Home,
+		Model:          o.model,
+		Quiet:          o.quiet,
+		ThinkingOutput: o.thinking,
+		Faults:         o.env.faults,
+		Attachment:     attachment,
+	})
+	if err != nil {
+		fmt.Fprintf(o.env.Stderr, "failed to initialize application: %v\n", err)
+		return exitFailure
+	}
+	defer instance.Close()
+
+	if err := instance.Ask(ctx, o.prompt); err != nil {
+		fmt.Fprintf(o.env.Stderr, "Error: %v\n", err)
+	}
+	return exitCodeFor(instance.LastOutcome())
+}
@@ -0,0 +1,230 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func newThinkingOllama(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"message":{"role":"assistant","thinking":"pondering"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"forty"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"-two"},"done":false}`)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunOneShotQuietPrintsOnlyTheAnswer(t *testing.T) {
+	server := newThinkingOllama(t)
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"-p", "what is the answer?", "--quiet"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "forty-two\n" {
+		t.Fatalf("expected only the answer on stdout, got %q", got)
+	}
+}
+
+func TestRunOneShotShowsStatusWithoutQuiet(t *testing.T) {
+	server := newThinkingOllama(t)
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"--prompt", "what is the answer?"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"Waiting for response...", "<<< Thinking >>>", "forty-two"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunOneShotAttachesPipedStdinInChunks(t *testing.T) {
+	var request struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"disk is full"},"done":false}`)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	t.Cleanup(server.Close)
+
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true, ContextWindow: 8192}},
+	})
+	var log strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&log, "2024-05-01 12:00:%02d ERROR write failed: no space left on device (attempt %d)\n", i%60, i)
+	}
+	env.Stdin = strings.NewReader(log.String())
+	env.StdinPiped = true
+
+	code := cli.Run(context.Background(), env, []string{"-p", "explain this log", "--quiet"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "disk is full\n" {
+		t.Fatalf("expected the answer on stdout, got %q", got)
+	}
+	messages := request.Messages
+	if len(messages) > 0 && messages[0].Role == "system" {
+		messages = messages[1:]
+	}
+	if len(messages) < 3 {
+		t.Fatalf("expected the log in several parts before the instruction, got %d messages", len(messages))
+	}
+	last := messages[len(messages)-1]
+	if last.Role != "user" || last.Content != "explain this log" {
+		t.Fatalf("expected the instruction as the last message, got %+v", last)
+	}
+	var attached strings.Builder
+	parts := messages[:len(messages)-1]
+	for i, msg := range parts {
+		if msg.Role != "user" || !strings.Contains(msg.Content, fmt.Sprintf("part %d of %d", i+1, len(parts))) {
+			t.Fatalf("expected piped part %d of %d, got %+v", i+1, len(parts), msg)
+		}
+		attached.WriteString(msg.Content)
+	}
+	for _, want := range []string{"(attempt 1)", "(attempt 100)"} {
+		if !strings.Contains(attached.String(), want) {
+			t.Fatalf("expected %q in the attached input", want)
+		}
+	}
+}
+
+func TestRunOneShotQuietReportsMissingModelOnStderr(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+
+	code := cli.Run(context.Background(), env, []string{"-p", "hello", "--quiet"})
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected empty stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "No active model is configured.") {
+		t.Fatalf("expected missing model notice on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunOneShotRequiresPrompt(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+
+	if code := cli.Run(context.Background(), env, []string{"--quiet"}); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Usage: humble-ai-cli -p <prompt>") {
+		t.Fatalf("expected usage on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunOneShotReturnsProviderErrorExitCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	env, _, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+
+	if code := cli.Run(context.Background(), env, []string{"-p", "hello", "--quiet"}); code != 3 {
+		t.Fatalf("expected provider error exit code 3, got %d (stderr=%s)", code, stderr.String())
+	}
+}
+
+func TestHelpDocumentsOneShotExitCodes(t *testing.T) {
+	env, stdout, _ := newTestEnv(t)
+
+	if code := cli.Run(context.Background(), env, []string{"--help"}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	for _, want := range []string{"Exit codes (-p):", "3    the provider failed", "4    an MCP tool call was declined or failed", "130  the response was cancelled"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("expected %q in help output, got:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRunChaosFlagsInjectProviderTimeouts(t *testing.T) {
+	server := newThinkingOllama(t)
+	env, _, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"--chaos-timeout=1", "--chaos-seed", "7", "-p", "hello", "--quiet"})
+	if code != 3 {
+		t.Fatalf("expected the provider error exit code, got %d (stderr=%s)", code, stderr.String())
+	}
+	for _, want := range []string{"Chaos mode: injecting provider timeouts 1", "(seed 7)", "injected provider timeout"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Fatalf("expected %q on stderr, got:\n%s", want, stderr.String())
+		}
+	}
+
+	env, stdout, _ := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"--chaos-timeout", "2"}); code != 2 {
+		t.Fatalf("expected a usage error for an invalid rate, got %d", code)
+	}
+	if code := cli.Run(context.Background(), env, []string{"help"}); code != 0 || strings.Contains(stdout.String(), "chaos") {
+		t.Fatalf("expected the chaos flags to stay out of usage, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunOneShotRoutesThinkingToStderr(t *testing.T) {
+	server := newThinkingOllama(t)
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"-p", "what is the answer?", "--thinking", "stderr"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if out := stdout.String(); strings.Contains(out, "pondering") || strings.Contains(out, "Thinking") || !strings.Contains(out, "forty-two") {
+		t.Fatalf("expected the answer without reasoning on stdout, got:\n%s", out)
+	}
+	if !strings.Contains(stderr.String(), "<<< Thinking >>>\npondering\n") {
+		t.Fatalf("expected reasoning on stderr, got:\n%s", stderr.String())
+	}
+}
+
+func TestRunOneShotRejectsUnknownThinkingOutput(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"-p", "hi", "--thinking", "file"}); code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `invalid --thinking "file"`) {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+const profileFlag = "--profile"
+
+// extractProfileFlag removes --profile <name> from args and returns the named profile, or
+// "" when the flag was not given. Like the chaos flags it is accepted before any command.
+func extractProfileFlag(args []string) ([]string, string, error) {
+	var (
+		rest    []string
+		profile string
+	)
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != profileFlag {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("flag %s needs a value", profileFlag)
+			}
+			i++
+			value = args[i]
+		}
+		profile = strings.TrimSpace(value)
+		if err := config.CheckProfileName(profile); err != nil {
+			return nil, "", err
+		}
+	}
+	return rest, profile, nil
+}
+
+// selectProfile makes the profile from --profile or HUMBLE_AI_PROFILE the active one. The
+// flag is exported to the environment so config.Dir, hooks and MCP servers all see it.
+func selectProfile(env Environment, flagProfile string) error {
+	if flagProfile != "" {
+		if err := os.Setenv(config.ProfileEnv, flagProfile); err != nil {
+			return err
+		}
+	}
+	name := config.ActiveProfile()
+	if name == "" {
+		return nil
+	}
+	if err := config.CheckProfileName(name); err != nil {
+		return fmt.Errorf("%s: %w", config.ProfileEnv, err)
+	}
+	if _, err := os.Stat(env.configDir()); os.IsNotExist(err) {
+		fmt.Fprintf(env.Stderr, "Profile %q is new; its files will be created in %s\n", name, env.configDir())
+	}
+	return nil
+}
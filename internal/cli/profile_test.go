@@ -0,0 +1,87 @@
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func newAnsweringOllama(t *testing.T, answer string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintf(w, `{"message":{"role":"assistant","content":%q},"done":false}`+"\n", answer)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunUsesSelectedProfile(t *testing.T) {
+	personal := newAnsweringOllama(t, "from personal")
+	work := newAnsweringOllama(t, "from work")
+	env, stdout, stderr := newTestEnv(t)
+
+	// cli.Run exports --profile to the environment; t.Setenv restores it afterwards.
+	t.Setenv(config.ProfileEnv, "")
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "personal", Provider: "ollama", BaseURL: personal.URL, Active: true}},
+	})
+	t.Setenv(config.ProfileEnv, "work")
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "work", Provider: "ollama", BaseURL: work.URL, Active: true}},
+	})
+	t.Setenv(config.ProfileEnv, "")
+
+	code := cli.Run(context.Background(), env, []string{"--profile", "work", "-p", "hello", "--quiet"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "from work\n" {
+		t.Fatalf("expected the work profile's model to answer, got %q", got)
+	}
+	sessions, err := history.List(filepath.Join(env.Home, ".humble-ai-cli", "profiles", "work", "sessions"))
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("expected the session in the profile's sessions dir, got %v, %v", sessions, err)
+	}
+	if others, _ := history.List(filepath.Join(env.Home, ".humble-ai-cli", "sessions")); len(others) != 0 {
+		t.Fatalf("expected no session in the default sessions dir, got %v", others)
+	}
+
+	stdout.Reset()
+	t.Setenv(config.ProfileEnv, "")
+	if code := cli.Run(context.Background(), env, []string{"-p", "hello", "--quiet"}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "from personal\n" {
+		t.Fatalf("expected the default configuration without a profile, got %q", got)
+	}
+}
+
+func TestRunRejectsInvalidProfile(t *testing.T) {
+	for _, setup := range []struct {
+		env  string
+		args []string
+	}{
+		{args: []string{"--profile", "../work", "version"}},
+		{args: []string{"version", "--profile"}},
+		{env: "a/b", args: []string{"version"}},
+	} {
+		t.Setenv(config.ProfileEnv, setup.env)
+		env, _, stderr := newTestEnv(t)
+		if code := cli.Run(context.Background(), env, setup.args); code != 2 {
+			t.Fatalf("Run(%v) with %s=%q exit code = %d, want 2", setup.args, config.ProfileEnv, setup.env, code)
+		}
+		if !strings.Contains(stderr.String(), "profile") {
+			t.Fatalf("expected a profile error, got %q", stderr.String())
+		}
+	}
+}
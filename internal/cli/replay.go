@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+const (
+	replayToolsStub    = "stub"
+	replayToolsExecute = "execute"
+)
+
+func runReplay(ctx context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	model := fs.String("model", "", "configured model to replay the session against (required)")
+	tools := fs.String("tools", replayToolsStub, "tool call handling: stub (answer with recorded results) or execute (call MCP servers)")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli replay <session> --model <name> [--tools stub|execute]")
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 || *model == "" {
+		fs.Usage()
+		return 2
+	}
+	if *tools != replayToolsStub && *tools != replayToolsExecute {
+		fmt.Fprintf(env.Stderr, "replay: unsupported --tools value %q\n", *tools)
+		return 2
+	}
+
+	sourcePath, err := history.Resolve(env.sessionsDir(), positional[0])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	source, err := history.Load(sourcePath)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "replay: %v\n", err)
+		return 1
+	}
+
+	opts := app.Options{
+		Store:          config.NewFileStore(env.Home),
+		Factory:        env.providerFactory(),
+		Input:          env.Stdin,
+		Output:         env.Stdout,
+		ErrorOutput:    env.Stderr,
+		HistoryRootDir: env.sessionsDir(),
+		HomeDir:        env.Home,
+		Model:          *model,
+		ToolCallMode:   config.ToolCallModeAuto,
+		Faults:         env.faults,
+	}
+	if *tools == replayToolsStub {
+		opts.MCP = newRecordedMCP(source)
+		opts.SkipDestructiveCheck = true
+	}
+	return replaySession(ctx, env, opts, sourcePath, source)
+}
+
+func replaySession(ctx context.Context, env Environment, opts app.Options, sourcePath string, source history.Session) int {
+	instance, err := app.New(opts)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	defer instance.Close()
+
+	// A turn that did not end with an answer, or a stubbed tool call the original session
+	// has no result for, means the replay diverged; the session is still written so it can
+	// be inspected, but the exit code reports it.
+	asked, failed := 0, 0
+	for _, msg := range source.Messages {
+		if msg.Role != "user" {
+			continue
+		}
+		asked++
+		fmt.Fprintf(env.Stdout, ">>> %s\n", msg.Content)
+		if err := instance.Ask(ctx, msg.Content); err != nil {
+			fmt.Fprintf(env.Stderr, "replay: %v\n", err)
+			return 1
+		}
+		if outcome := instance.LastOutcome(); outcome != app.TurnOK {
+			fmt.Fprintf(env.Stderr, "replay: message %d: %s\n", asked, outcome)
+			failed++
+		}
+	}
+	var unrecorded []string
+	if recorded, ok := opts.MCP.(*recordedMCP); ok {
+		unrecorded = recorded.unrecordedCalls()
+	}
+	for _, call := range unrecorded {
+		fmt.Fprintf(env.Stderr, "replay: no recorded result for %s\n", call)
+	}
+
+	path := instance.SessionPath()
+	if path == "" {
+		fmt.Fprintln(env.Stderr, "replay: no answers were recorded")
+		return 1
+	}
+	replayed, err := history.Load(path)
+	if err == nil {
+		if !replayed.HasTag("replay") {
+			replayed.Tags = append(replayed.Tags, "replay")
+		}
+		replayed.Notes = append(replayed.Notes, fmt.Sprintf("replay of %s (model %s)", filepath.Base(sourcePath), source.Model))
+		err = history.Save(path, replayed)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.Stderr, "Replay written to %s\n", path)
+	if failed > 0 || len(unrecorded) > 0 {
+		fmt.Fprintf(env.Stderr, "replay: %d of %d messages failed, %d tool calls had no recorded result\n", failed, asked, len(unrecorded))
+		return 1
+	}
+	return 0
+}
+
+// recordedMCP answers tool calls with the results captured in the original session.
+type recordedMCP struct {
+	mu        sync.Mutex
+	servers   []app.MCPServer
+	functions map[string][]app.MCPFunction
+	results   map[string][]history.ToolCall
+	// unrecorded lists the calls, as server.method, that had no result to answer with.
+	unrecorded []string
+}
+
+func newRecordedMCP(session history.Session) *recordedMCP {
+	r := &recordedMCP{
+		functions: make(map[string][]app.MCPFunction),
+		results:   make(map[string][]history.ToolCall),
+	}
+	seen := make(map[string]bool)
+	for _, msg := range session.Messages {
+		for _, call := range msg.ToolCalls {
+			key := call.Server + "__" + call.Method
+			r.results[key] = append(r.results[key], call)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := r.functions[call.Server]; !ok {
+				r.servers = append(r.servers, app.MCPServer{Name: call.Server, Description: "Recorded responses for replay."})
+			}
+			r.functions[call.Server] = append(r.functions[call.Server], app.MCPFunction{Name: call.Method})
+		}
+	}
+	sort.Slice(r.servers, func(i, j int) bool { return r.servers[i].Name < r.servers[j].Name })
+	return r
+}
+
+func (r *recordedMCP) EnabledServers() []app.MCPServer {
+	return append([]app.MCPServer(nil), r.servers...)
+}
+
+func (r *recordedMCP) Describe(server string) (app.MCPServer, bool) {
+	for _, srv := range r.servers {
+		if srv.Name == server {
+			return srv, true
+		}
+	}
+	return app.MCPServer{}, false
+}
+
+func (r *recordedMCP) Call(_ context.Context, server, method string, _ map[string]any) (llm.ToolResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := server + "__" + method
+	queue := r.results[key]
+	if len(queue) == 0 {
+		r.unrecorded = append(r.unrecorded, server+"."+method)
+		return llm.ToolResult{Content: fmt.Sprintf("no recorded result for %s.%s", server, method), IsError: true}, nil
+	}
+	next := queue[0]
+	if len(queue) > 1 {
+		r.results[key] = queue[1:]
+	}
+	return llm.ToolResult{Content: next.Result, IsError: next.IsError}, nil
+}
+
+func (r *recordedMCP) unrecordedCalls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.unrecorded...)
+}
+
+func (r *recordedMCP) Tools(_ context.Context, server string) ([]app.MCPFunction, error) {
+	return append([]app.MCPFunction(nil), r.functions[server]...), nil
+}
+
+func (r *recordedMCP) Close() error { return nil }
+
+func (r *recordedMCP) Reload() error { return nil }
+
+var _ app.MCPExecutor = (*recordedMCP)(nil)
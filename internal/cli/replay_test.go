@@ -0,0 +1,156 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func writeConfig(t *testing.T, home string, cfg config.Config) {
+	t.Helper()
+	if err := config.NewFileStore(home).Save(cfg); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestRunReplayFeedsUserMessagesToAnotherModel(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(data))
+		n := len(bodies)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintf(w, `{"message":{"role":"assistant","content":"replayed %d"},"done":false}`+"\n", n)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	defer server.Close()
+
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{
+			{Name: "original", Provider: "openai", APIKey: "sk", Active: true},
+			{Name: "candidate", Provider: "ollama", BaseURL: server.URL},
+		},
+	})
+	writeSession(t, env.Home, "20250101_000000_src.json", history.Session{
+		Model: "original",
+		Messages: []history.Message{
+			{Role: "user", Content: "first question"},
+			{Role: "assistant", Content: "old answer 1"},
+			{Role: "user", Content: "second question"},
+			{Role: "assistant", Content: "old answer 2"},
+		},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"replay", "20250101_000000_src", "--model", "candidate"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "replayed 2") {
+		t.Fatalf("expected replayed answers on stdout, got:\n%s", stdout.String())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 provider requests, got %d", len(bodies))
+	}
+	var payload struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(bodies[1]), &payload); err != nil {
+		t.Fatalf("invalid request body: %v", err)
+	}
+	if payload.Model != "candidate" {
+		t.Fatalf("expected candidate model, got %s", payload.Model)
+	}
+	last := payload.Messages[len(payload.Messages)-1]
+	if last.Content != "second question" {
+		t.Fatalf("expected second user message, got %q", last.Content)
+	}
+	if prev := payload.Messages[len(payload.Messages)-2]; prev.Content != "replayed 1" {
+		t.Fatalf("expected replayed context, got %q", prev.Content)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(env.Home, ".humble-ai-cli", "sessions", "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("expected a new session file, got %v", files)
+	}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_src.json") {
+			continue
+		}
+		replayed, err := history.Load(path)
+		if err != nil {
+			t.Fatalf("failed to load replay: %v", err)
+		}
+		if replayed.Model != "candidate" || !replayed.HasTag("replay") || len(replayed.Messages) != 4 {
+			t.Fatalf("unexpected replay session: %+v", replayed)
+		}
+	}
+}
+
+func TestRunReplayFailsWhenAMessageDoesNotReplay(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		if n == 2 {
+			http.Error(w, "model overloaded", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"replayed"},"done":false}`)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	defer server.Close()
+
+	env, _, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "candidate", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+	writeSession(t, env.Home, "20250101_000000_src.json", history.Session{
+		Model: "original",
+		Messages: []history.Message{
+			{Role: "user", Content: "first question"},
+			{Role: "assistant", Content: "old answer 1"},
+			{Role: "user", Content: "second question"},
+			{Role: "assistant", Content: "old answer 2"},
+		},
+	})
+
+	code := cli.Run(context.Background(), env, []string{"replay", "20250101_000000_src", "--model", "candidate"})
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d (stderr=%s)", code, stderr.String())
+	}
+	for _, phrase := range []string{"replay: message 2: provider error", "Replay written to", "1 of 2 messages failed"} {
+		if !strings.Contains(stderr.String(), phrase) {
+			t.Fatalf("expected stderr to contain %q, got:\n%s", phrase, stderr.String())
+		}
+	}
+}
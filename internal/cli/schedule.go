@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/schedule"
+)
+
+// runScheduled executes a prompt template on a cron-like schedule in one-shot mode and
+// writes each answer to its own file.
+func runScheduled(ctx context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	spec := fs.String("schedule", "", `cron expression ("0 8 * * *"), @every <duration>, @hourly, @daily, @weekly or @monthly (required)`)
+	var prompt string
+	fs.StringVar(&prompt, "p", "", "prompt template to send")
+	fs.StringVar(&prompt, "prompt", "", "alias for -p")
+	promptFile := fs.String("prompt-file", "", "file holding the prompt template; re-read before every run")
+	model := fs.String("model", "", "configured model to use instead of the active one")
+	outDir := fs.String("out-dir", "", "directory for answer files (default ~/.humble-ai-cli/runs)")
+	maxRuns := fs.Int("runs", 0, "stop after this many runs (0 runs until interrupted)")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli run --schedule <spec> (-p <prompt> | --prompt-file <path>) [--model <name>] [--out-dir <dir>] [--runs <n>]")
+		fs.PrintDefaults()
+		fmt.Fprintln(env.Stderr)
+		fmt.Fprintln(env.Stderr, "The prompt may use {date}, {time} and {datetime}, filled in at run time.")
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(positional) > 0 || strings.TrimSpace(*spec) == "" || (prompt == "") == (*promptFile == "") || *maxRuns < 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	sched, err := schedule.Parse(*spec)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "run: %v\n", err)
+		return exitUsage
+	}
+
+	dir := *outDir
+	if dir == "" {
+		dir = filepath.Join(env.configDir(), "runs")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Fprintf(env.Stderr, "run: create output directory: %v\n", err)
+		return exitFailure
+	}
+	name := "prompt"
+	if *promptFile != "" {
+		name = strings.TrimSuffix(filepath.Base(*promptFile), filepath.Ext(*promptFile))
+	}
+
+	job := scheduledJob{
+		env:        env,
+		prompt:     prompt,
+		promptFile: *promptFile,
+		model:      *model,
+		dir:        dir,
+		name:       name,
+	}
+	code := exitOK
+	for runs := 0; *maxRuns == 0 || runs < *maxRuns; runs++ {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			fmt.Fprintln(env.Stderr, "run: the schedule has no upcoming runs")
+			return exitFailure
+		}
+		fmt.Fprintf(env.Stdout, "Next run at %s\n", next.Format(time.DateTime))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			fmt.Fprintln(env.Stdout, "Scheduler stopped.")
+			return code
+		case <-timer.C:
+		}
+		code = job.run(ctx, next)
+	}
+	return code
+}
+
+type scheduledJob struct {
+	env        Environment
+	prompt     string
+	promptFile string
+	model      string
+	dir        string
+	name       string
+}
+
+func (j scheduledJob) run(ctx context.Context, at time.Time) int {
+	template := j.prompt
+	if j.promptFile != "" {
+		data, err := os.ReadFile(j.promptFile)
+		if err != nil {
+			fmt.Fprintf(j.env.Stderr, "run: read prompt file: %v\n", err)
+			return exitFailure
+		}
+		template = string(data)
+	}
+	prompt := strings.NewReplacer(
+		"{date}", at.Format(time.DateOnly),
+		"{time}", at.Format("15:04"),
+		"{datetime}", at.Format(time.RFC3339),
+	).Replace(strings.TrimSpace(template))
+
+	path := uniqueRunPath(j.dir, j.name, at)
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(j.env.Stderr, "run: %v\n", err)
+		return exitFailure
+	}
+
+	// Scheduled runs are unattended: tools run automatically, and destructive calls
+	// that still need confirmation are declined because there is no input.
+	instance, err := app.New(app.Options{
+		Store:          config.NewFileStore(j.env.Home),
+		Factory:        j.env.providerFactory(),
+		Input:          strings.NewReader(""),
+		Output:         file,
+		ErrorOutput:    j.env.Stderr,
+		HistoryRootDir: j.env.sessionsDir(),
+		HomeDir:        j.env.Home,
+		Model:          j.model,
+		Quiet:          true,
+		ToolCallMode:   config.ToolCallModeAuto,
+		Faults:         j.env.faults,
+	})
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		fmt.Fprintf(j.env.Stderr, "run: failed to initialize application: %v\n", err)
+		return exitFailure
+	}
+	if err := instance.Ask(ctx, prompt); err != nil {
+		fmt.Fprintf(j.env.Stderr, "run: %v\n", err)
+	}
+	_ = instance.Close()
+	outcome := instance.LastOutcome()
+	_ = file.Close()
+
+	if outcome != app.TurnOK {
+		_ = os.Remove(path)
+		fmt.Fprintf(j.env.Stdout, "[%s] run failed: %s\n", at.Format(time.DateTime), outcome)
+		return exitCodeFor(outcome)
+	}
+	fmt.Fprintf(j.env.Stdout, "[%s] wrote %s\n", at.Format(time.DateTime), path)
+	return exitOK
+}
+
+// uniqueRunPath names the answer file after the prompt and run time, adding a counter
+// when several runs land in the same second.
+func uniqueRunPath(dir, name string, at time.Time) string {
+	base := fmt.Sprintf("%s-%s", name, at.Format("20060102-150405"))
+	path := filepath.Join(dir, base+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", base, i))
+	}
+}
@@ -0,0 +1,93 @@
+package cli_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestRunScheduleWritesEachAnswerToAFile(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		prompts = append(prompts, body.Messages[len(body.Messages)-1].Content)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"all quiet"},"done":false}`)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	t.Cleanup(server.Close)
+
+	env, stdout, stderr := newTestEnv(t)
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+	promptFile := filepath.Join(t.TempDir(), "daily.txt")
+	if err := os.WriteFile(promptFile, []byte("Summarize {date}\n"), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	outDir := filepath.Join(t.TempDir(), "reports")
+
+	code := cli.Run(context.Background(), env, []string{"run", "--schedule", "@every 10ms", "--prompt-file", promptFile, "--out-dir", outDir, "--runs", "2"})
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+	}
+
+	files, _ := filepath.Glob(filepath.Join(outDir, "daily-*.md"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 answer files, got %v", files)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if string(data) != "all quiet\n" {
+			t.Fatalf("unexpected answer file contents %q", data)
+		}
+	}
+	if strings.Count(stdout.String(), "wrote ") != 2 {
+		t.Fatalf("expected a line per run, got:\n%s", stdout.String())
+	}
+	want := "Summarize " + time.Now().Format(time.DateOnly)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(prompts) != 2 || prompts[0] != want {
+		t.Fatalf("expected expanded prompt %q, got %q", want, prompts)
+	}
+}
+
+func TestRunScheduleRequiresScheduleAndPrompt(t *testing.T) {
+	for _, args := range [][]string{
+		{"run", "-p", "hi"},
+		{"run", "--schedule", "@daily"},
+		{"run", "--schedule", "@daily", "-p", "hi", "--prompt-file", "x.txt"},
+		{"run", "--schedule", "61 * * * *", "-p", "hi"},
+	} {
+		env, _, _ := newTestEnv(t)
+		if code := cli.Run(context.Background(), env, args); code != 2 {
+			t.Fatalf("%v: expected exit code 2, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+func runShow(_ context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	noColor := fs.Bool("no-color", false, "disable ANSI colors")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli show [--no-color] <session>")
+		fmt.Fprintln(env.Stderr, "<session> is a file path, a file name in ~/.humble-ai-cli/sessions, or a unique prefix of one.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	path, err := history.Resolve(env.sessionsDir(), fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return 1
+	}
+	session, err := history.Load(path)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return 1
+	}
+
+	cfg, err := loadConfigForEdit(config.NewFileStore(env.Home))
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return 1
+	}
+	user, assistant := cfg.TurnLabels(session.Model)
+	opts := render.Options{
+		Color:  !*noColor && env.stdoutIsTerminal(),
+		Title:  filepath.Base(path),
+		Labels: render.Labels{User: user, Assistant: assistant},
+	}
+	if err := render.Transcript(env.Stdout, session, opts); err != nil {
+		fmt.Fprintf(env.Stderr, "show: %v\n", err)
+		return 1
+	}
+	return 0
+}
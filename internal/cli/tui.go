@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/tui"
+)
+
+func runTUI(ctx context.Context, env Environment, args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli tui")
+		return exitUsage
+	}
+	stdin, inOK := env.Stdin.(*os.File)
+	stdout, outOK := env.Stdout.(*os.File)
+	if !inOK || !outOK {
+		fmt.Fprintf(env.Stderr, "tui: %v\n", tui.ErrNotTerminal)
+		return exitUsage
+	}
+	screen, err := tui.New(stdin, stdout)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "tui: %v\n", err)
+		return exitUsage
+	}
+
+	// Restores the terminal even if the chat panics; Close is a no-op the second time.
+	defer screen.Close()
+
+	// Errors that end the loop are printed after the alternate screen is gone.
+	var failures bytes.Buffer
+	chatEnv := env
+	chatEnv.Stderr = &failures
+	code := runChat(ctx, chatEnv, app.Options{UI: screen})
+	_ = screen.Close()
+	_, _ = io.Copy(env.Stderr, &failures)
+	return code
+}
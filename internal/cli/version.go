@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/buildinfo"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/update"
+)
+
+const updateTimeout = 2 * time.Minute
+
+func runVersion(_ context.Context, env Environment, args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli version")
+		return 2
+	}
+	fmt.Fprint(env.Stdout, buildinfo.Get().String())
+	return 0
+}
+
+func runSelfUpdate(ctx context.Context, env Environment, args []string) int {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	checkOnly := fs.Bool("check", false, "only report whether a newer release exists")
+	force := fs.Bool("force", false, "install the latest release even on development builds or when up to date")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "Usage: humble-ai-cli self-update [--check] [--force]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := loadConfigForEdit(config.NewFileStore(env.Home))
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "self-update: %v\n", err)
+		return 1
+	}
+	if cfg.DisableUpdateCheck {
+		fmt.Fprintln(env.Stderr, "self-update: update checks are disabled (disableUpdateCheck in config.json)")
+		return 1
+	}
+
+	info := buildinfo.Get()
+	if !info.IsRelease() && !*checkOnly && !*force {
+		fmt.Fprintf(env.Stderr, "self-update: this is a development build (%s); use --force to replace it with the latest release\n", info.Version)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	checker := update.NewChecker(nil)
+	release, err := checker.Latest(ctx)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "self-update: %v\n", err)
+		return 1
+	}
+	if !update.Newer(info.Version, release.TagName) && !*force {
+		fmt.Fprintf(env.Stdout, "humble-ai-cli %s is up to date.\n", info.Version)
+		return 0
+	}
+	if *checkOnly {
+		fmt.Fprintf(env.Stdout, "A newer release is available: %s (current %s).\n", release.TagName, info.Version)
+		if release.HTMLURL != "" {
+			fmt.Fprintln(env.Stdout, release.HTMLURL)
+		}
+		fmt.Fprintln(env.Stdout, "Run `humble-ai-cli self-update` to install it.")
+		return 0
+	}
+
+	asset, ok := release.AssetFor(runtime.GOOS, runtime.GOARCH)
+	if !ok {
+		fmt.Fprintf(env.Stderr, "self-update: %v (%s/%s in %s)\n", update.ErrNoAsset, runtime.GOOS, runtime.GOARCH, release.TagName)
+		return 1
+	}
+	data, err := checker.Download(ctx, release, asset)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "self-update: %v\n", err)
+		return 1
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "self-update: locate executable: %v\n", err)
+		return 1
+	}
+	if err := update.Replace(exe, data); err != nil {
+		fmt.Fprintf(env.Stderr, "self-update: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.Stdout, "Updated humble-ai-cli %s → %s (%s).\n", info.Version, release.TagName, exe)
+	return 0
+}
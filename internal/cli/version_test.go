@@ -0,0 +1,42 @@
+package cli_test
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+)
+
+func TestRunVersionPrintsBuildInfo(t *testing.T) {
+	env, stdout, _ := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"version"}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	got := stdout.String()
+	if !strings.HasPrefix(got, "humble-ai-cli ") || !strings.Contains(got, "platform: "+runtime.GOOS+"/"+runtime.GOARCH) {
+		t.Fatalf("unexpected version output:\n%s", got)
+	}
+}
+
+func TestRunSelfUpdateHonorsDisableUpdateCheck(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	writeConfigFile(t, env.Home, "config.json", `{"disableUpdateCheck": true}`)
+	if code := cli.Run(context.Background(), env, []string{"self-update", "--check"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "update checks are disabled") {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+}
+
+func TestRunSelfUpdateRefusesDevelopmentBuilds(t *testing.T) {
+	env, _, stderr := newTestEnv(t)
+	if code := cli.Run(context.Background(), env, []string{"self-update"}); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "development build") {
+		t.Fatalf("unexpected stderr %q", stderr.String())
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/watch"
+)
+
+// watch runs the prompt with the contents of the files matching pattern, then again every
+// time they change, until interrupted. It returns the exit code of the last run.
+func (o oneShot) watch(ctx context.Context, pattern string, interval time.Duration) int {
+	watcher, err := watch.New(pattern, interval)
+	if err != nil {
+		fmt.Fprintf(o.env.Stderr, "watch: %v\n", err)
+		return exitUsage
+	}
+	fmt.Fprintf(o.env.Stderr, "Watching %s (%d files); press CTRL+C to stop.\n", pattern, len(watcher.Files()))
+	code := o.ask(ctx, watchedFiles(watcher.Files()))
+	for {
+		changed, err := watcher.Wait(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(o.env.Stderr, "watch: %v\n", err)
+				return exitFailure
+			}
+			return code
+		}
+		fmt.Fprintf(o.env.Stdout, "\n──── %s · changed: %s ────\n", time.Now().Format(time.TimeOnly), strings.Join(changed, ", "))
+		code = o.ask(ctx, watchedFiles(watcher.Files()))
+	}
+}
+
+// watchedFiles renders the files as fenced blocks headed by their paths. Unreadable and
+// binary files are listed without content.
+func watchedFiles(paths []string) string {
+	var b strings.Builder
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "File: %s (unreadable: %v)\n\n", path, err)
+		case bytes.IndexByte(data, 0) >= 0:
+			fmt.Fprintf(&b, "File: %s (binary, %d bytes)\n\n", path, len(data))
+		default:
+			fmt.Fprintf(&b, "File: %s\n```\n%s\n```\n\n", path, strings.TrimRight(string(data), "\n"))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
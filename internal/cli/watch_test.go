@@ -0,0 +1,113 @@
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// lockedBuffer lets the test read output while watch mode is still writing it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRunOneShotWatchRerunsOnChange(t *testing.T) {
+	requests := make(chan string, 4)
+	var served atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintf(w, `{"message":{"role":"assistant","content":"review %d"},"done":false}`+"\n", served.Add(1))
+		fmt.Fprintln(w, `{"done":true}`)
+		requests <- string(data)
+	}))
+	t.Cleanup(server.Close)
+
+	env, _, stderr := newTestEnv(t)
+	stdout := &lockedBuffer{}
+	env.Stdout = stdout
+	writeConfig(t, env.Home, config.Config{
+		Models: []config.Model{{Name: "local", Provider: "ollama", BaseURL: server.URL, Active: true}},
+	})
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(source, []byte("package main // first draft"), 0o644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int)
+	go func() {
+		done <- cli.Run(ctx, env, []string{"-p", "critique my code", "--quiet", "--watch", filepath.Join(dir, "*.go"), "--watch-interval", "10ms"})
+	}()
+
+	waitRequest := func() string {
+		t.Helper()
+		select {
+		case body := <-requests:
+			return body
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a request")
+			return ""
+		}
+	}
+	if first := waitRequest(); !strings.Contains(first, "first draft") {
+		t.Fatalf("expected the file contents in the first request, got %s", first)
+	}
+	if err := os.WriteFile(source, []byte("package main // second draft, now longer"), 0o644); err != nil {
+		t.Fatalf("failed to update source: %v", err)
+	}
+	second := waitRequest()
+	if !strings.Contains(second, "second draft") || strings.Contains(second, "first draft") {
+		t.Fatalf("expected only the fresh contents in the second request, got %s", second)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(stdout.String(), "review 2\n") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case code := <-done:
+		if code != 0 {
+			t.Fatalf("expected exit code 0, got %d (stderr=%s)", code, stderr.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch mode did not stop after cancellation")
+	}
+	out := stdout.String()
+	for _, want := range []string{"review 1\n", "changed: " + source, "review 2\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "review 1") > strings.Index(out, "changed:") {
+		t.Fatalf("expected the separator between the runs, got:\n%s", out)
+	}
+}
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/jsoncheck"
 )
 
 // ErrNotFound indicates that the configuration file does not exist.
@@ -20,6 +25,119 @@ type Model struct {
 	APIKey   string `json:"apiKey,omitempty"`
 	BaseURL  string `json:"baseUrl,omitempty"`
 	Active   bool   `json:"active,omitempty"`
+	// APIKeyCommand is the argv whose first output line is used as the key when apiKey is
+	// empty, e.g. ["pass", "show", "openai"]. It runs once per process.
+	APIKeyCommand []string `json:"apiKeyCommand,omitempty"`
+	// Seed makes sampling reproducible on providers that support it.
+	Seed *int64 `json:"seed,omitempty"`
+	// ExtraParams are merged verbatim into the OpenAI payload or Ollama options.
+	ExtraParams map[string]any `json:"extraParams,omitempty"`
+	// ProviderPreferences is sent as the OpenRouter "provider" routing object.
+	ProviderPreferences map[string]any `json:"providerPreferences,omitempty"`
+	// FallbackModels lists OpenRouter models to try when the primary model is unavailable.
+	FallbackModels []string `json:"fallbackModels,omitempty"`
+	// Tokenizer selects the token estimator (o200k_base, llama, heuristic; cl100k_base is
+	// an alias of heuristic).
+	Tokenizer string `json:"tokenizer,omitempty"`
+	// ContextWindow is the model's context size in tokens; it sizes tool result and history budgets.
+	ContextWindow int `json:"contextWindow,omitempty"`
+	// ChunkOverlapTokens repeats the end of each tool result or attachment part at the start
+	// of the next (0 = a sixteenth of the part size).
+	ChunkOverlapTokens int `json:"chunkOverlapTokens,omitempty"`
+	// ReasoningEffort asks reasoning models to think less or more: none, minimal, low, medium or high.
+	ReasoningEffort string `json:"reasoningEffort,omitempty"`
+	// ThinkingBudget caps reasoning tokens on APIs that take a budget (OpenRouter, Anthropic).
+	ThinkingBudget int `json:"thinkingBudget,omitempty"`
+	// ToolStrategy selects how tools are offered to the model: native, direct or chooseFunction.
+	// It defaults to direct for Ollama and native for the other providers.
+	ToolStrategy string `json:"toolStrategy,omitempty"`
+	// ConstrainToolCalls makes Ollama decode the answer against the function call JSON schema
+	// whenever a call is expected, such as after the chooseFunction step picked a tool.
+	ConstrainToolCalls bool `json:"constrainToolCalls,omitempty"`
+	// ToolCallRepairs is how many corrective follow-ups to send when an answer's tool call JSON
+	// does not parse (direct and chooseFunction strategies); 0 keeps such answers as text.
+	ToolCallRepairs int `json:"toolCallRepairs,omitempty"`
+	// OmitRepeatedTools sends the tools array only with the first request of an OpenAI-style
+	// tool loop, for endpoints that keep it; elsewhere the model cannot chain further calls.
+	OmitRepeatedTools bool `json:"omitRepeatedTools,omitempty"`
+	// Scenario is the JSON file the mock provider plays back instead of calling a model.
+	Scenario string `json:"scenario,omitempty"`
+	// SupportsTools declares whether the model can call tools. False offers it no tools;
+	// true makes native tool calling the default strategy, also on Ollama.
+	SupportsTools *bool `json:"supportsTools,omitempty"`
+	// SupportsVision declares whether the model reads images. False keeps images from tool
+	// results away from it.
+	SupportsVision *bool `json:"supportsVision,omitempty"`
+	// SupportsReasoning declares whether the model takes reasoningEffort and thinkingBudget.
+	SupportsReasoning *bool `json:"supportsReasoning,omitempty"`
+	// AssistantLabel names this model's answers in transcripts and exports, in place of the
+	// global assistantLabel.
+	AssistantLabel string `json:"assistantLabel,omitempty"`
+}
+
+// ToolsEnabled reports whether tools may be offered to the model; undeclared models get them.
+func (m Model) ToolsEnabled() bool {
+	return m.SupportsTools == nil || *m.SupportsTools
+}
+
+// VisionEnabled reports whether the model may be offered images; undeclared models are.
+func (m Model) VisionEnabled() bool {
+	return m.SupportsVision == nil || *m.SupportsVision
+}
+
+// Capabilities lists the declared capabilities for display, e.g. ["tools", "no vision"].
+func (m Model) Capabilities() []string {
+	var labels []string
+	for _, c := range []struct {
+		name     string
+		declared *bool
+	}{
+		{"tools", m.SupportsTools},
+		{"vision", m.SupportsVision},
+		{"reasoning", m.SupportsReasoning},
+	} {
+		switch {
+		case c.declared == nil:
+		case *c.declared:
+			labels = append(labels, c.name)
+		default:
+			labels = append(labels, "no "+c.name)
+		}
+	}
+	switch {
+	case m.ContextWindow >= 1000:
+		labels = append(labels, fmt.Sprintf("%dk context", m.ContextWindow/1000))
+	case m.ContextWindow > 0:
+		labels = append(labels, fmt.Sprintf("%d token context", m.ContextWindow))
+	}
+	return labels
+}
+
+// ToolStrategy describes how tools are offered to a model.
+type ToolStrategy string
+
+const (
+	// ToolStrategyNative sends tool definitions through the provider's tools API.
+	ToolStrategyNative ToolStrategy = "native"
+	// ToolStrategyDirect embeds every tool schema in the system prompt and reads calls from the answer text.
+	ToolStrategyDirect ToolStrategy = "direct"
+	// ToolStrategyChooseFunction first asks the model to pick one function from a short list,
+	// then offers only that function's schema in the system prompt.
+	ToolStrategyChooseFunction ToolStrategy = "chooseFunction"
+)
+
+// EffectiveToolStrategy returns the configured tool strategy or the provider's default.
+func (m Model) EffectiveToolStrategy() ToolStrategy {
+	if strategy, ok := validToolStrategies[strings.ToLower(strings.TrimSpace(m.ToolStrategy))]; ok {
+		return strategy
+	}
+	if m.SupportsTools != nil && *m.SupportsTools {
+		return ToolStrategyNative
+	}
+	if strings.EqualFold(m.Provider, "ollama") {
+		return ToolStrategyDirect
+	}
+	return ToolStrategyNative
 }
 
 // ToolCallMode represents how MCP tool calls should be executed.
@@ -32,11 +150,326 @@ const (
 	ToolCallModeAuto ToolCallMode = "auto"
 )
 
+// ThinkingOutput is where streamed reasoning is written.
+type ThinkingOutput string
+
+const (
+	// ThinkingOutputStdout writes reasoning inline with the answer.
+	ThinkingOutputStdout ThinkingOutput = "stdout"
+	// ThinkingOutputStderr writes reasoning and its markers to the error output.
+	ThinkingOutputStderr ThinkingOutput = "stderr"
+	// ThinkingOutputDrop does not print reasoning; /show-thinking and saveThinking still see it.
+	ThinkingOutputDrop ThinkingOutput = "drop"
+	// ThinkingOutputAuto uses stdout on a terminal and stderr when output is piped.
+	ThinkingOutputAuto ThinkingOutput = "auto"
+)
+
+// ParseThinkingOutput validates a thinkingOutput value; empty means stdout.
+func ParseThinkingOutput(raw string) (ThinkingOutput, error) {
+	switch value := ThinkingOutput(strings.ToLower(strings.TrimSpace(raw))); value {
+	case "":
+		return ThinkingOutputStdout, nil
+	case ThinkingOutputStdout, ThinkingOutputStderr, ThinkingOutputDrop, ThinkingOutputAuto:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid thinkingOutput %q (use stdout, stderr, drop or auto)", raw)
+}
+
+// PreludeMessage is a fixed conversation turn injected before the live conversation.
+type PreludeMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Persona bundles task-specific priming: an extra system prompt and a few-shot prelude.
+type Persona struct {
+	Name         string           `json:"name"`
+	SystemPrompt string           `json:"systemPrompt,omitempty"`
+	Prelude      []PreludeMessage `json:"prelude,omitempty"`
+	PreludeFile  string           `json:"preludeFile,omitempty"`
+}
+
 // Config captures CLI configuration.
 type Config struct {
-	LogLevel     string  `json:"logLevel,omitempty"`
-	ToolCallMode string  `json:"toolCallMode,omitempty"`
-	Models       []Model `json:"models,omitempty"`
+	LogLevel      string    `json:"logLevel,omitempty"`
+	ToolCallMode  string    `json:"toolCallMode,omitempty"`
+	Models        []Model   `json:"models,omitempty"`
+	Personas      []Persona `json:"personas,omitempty"`
+	ActivePersona string    `json:"activePersona,omitempty"`
+	// ToolResultPreviewLines is how many MCP result lines to echo (0 = default, negative = off).
+	ToolResultPreviewLines int `json:"toolResultPreviewLines,omitempty"`
+	// PostResponseHooks run external commands on each final assistant message.
+	PostResponseHooks []Hook `json:"postResponseHooks,omitempty"`
+	// PreSendHooks rewrite each user message before it is added to the request.
+	PreSendHooks []Hook `json:"preSendHooks,omitempty"`
+	// Aliases maps short slash commands to longer commands or canned prompts.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// ToolPolicy adjusts which MCP calls always require confirmation.
+	ToolPolicy ToolPolicy `json:"toolPolicy,omitzero"`
+	// ToolConfirmTimeout declines a pending "Call now?" confirmation after this long, e.g. "2m";
+	// empty waits for an answer indefinitely.
+	ToolConfirmTimeout string `json:"toolConfirmTimeout,omitempty"`
+	// GenerationTimeout cancels an answer the model takes longer than this to produce, e.g.
+	// "5m"; time spent on tool calls, confirmations included, is not counted.
+	GenerationTimeout string `json:"generationTimeout,omitempty"`
+	// MaxOutputTokens and MaxOutputChars stop an answer, reasoning included, once it grows
+	// past this many tokens or characters; 0 leaves it unbounded.
+	MaxOutputTokens int `json:"maxOutputTokens,omitempty"`
+	MaxOutputChars  int `json:"maxOutputChars,omitempty"`
+	// InjectionScan inspects MCP results for prompt-injection content ("off", "warn", or "escape").
+	InjectionScan string `json:"injectionScan,omitempty"`
+	// Redaction masks personal data in messages and tool results sent to cloud providers.
+	Redaction Redaction `json:"redaction,omitzero"`
+	// DisableUpdateCheck makes the self-update command refuse to run, e.g. for managed
+	// installs. The CLI never checks for releases on its own, so nothing else is affected.
+	DisableUpdateCheck bool `json:"disableUpdateCheck,omitempty"`
+	// DisableHints turns off the one-time tips shown in the interactive loop.
+	DisableHints bool `json:"disableHints,omitempty"`
+	// DisableContextGauge hides the context window usage printed after each answer.
+	DisableContextGauge bool `json:"disableContextGauge,omitempty"`
+	// Prompt replaces "humble-ai> " and may use {model}, {provider}, {persona}, {mode}, {profile} and color placeholders.
+	Prompt string `json:"prompt,omitempty"`
+	// UserLabel and AssistantLabel prefix the turns of `show` transcripts and HTML exports in
+	// place of "You" and "Assistant".
+	UserLabel      string `json:"userLabel,omitempty"`
+	AssistantLabel string `json:"assistantLabel,omitempty"`
+	// Keybindings maps line editor actions to keys such as "ctrl+a".
+	Keybindings map[string]string `json:"keybindings,omitempty"`
+	// TranscriptLog appends all rendered output to a plaintext file per session.
+	TranscriptLog bool `json:"transcriptLog,omitempty"`
+	// TranscriptDir overrides where transcripts are written (default ~/.humble-ai-cli/transcripts).
+	TranscriptDir string `json:"transcriptDir,omitempty"`
+	// AutoContinue is how many "continue" follow-ups to send when an answer stops at the
+	// token limit; 0 only warns about the truncation.
+	AutoContinue int `json:"autoContinue,omitempty"`
+	// ResumeStreams is how many times per turn an answer whose stream broke on a network
+	// error is requested again from where it stopped; 0 reports the error.
+	ResumeStreams int `json:"resumeStreams,omitempty"`
+	// TurnTimings prints time-to-first-token, total time, round-trips and tool durations after each answer.
+	TurnTimings bool `json:"turnTimings,omitempty"`
+	// CollapseThinking hides streamed reasoning behind a one-line marker; /show-thinking prints it.
+	CollapseThinking bool `json:"collapseThinking,omitempty"`
+	// SaveThinking stores each answer's reasoning in the session file.
+	SaveThinking bool `json:"saveThinking,omitempty"`
+	// ThinkingOutput routes streamed reasoning: stdout (default), stderr, drop, or auto,
+	// which keeps piped output free of reasoning.
+	ThinkingOutput string `json:"thinkingOutput,omitempty"`
+	// Citations asks models to cite tool results with [ref:toolcall-N] markers, which are
+	// resolved to footnotes naming the tool call.
+	Citations bool `json:"citations,omitempty"`
+	// MatchInputLanguage tells the model to answer in the language of the user's message
+	// when it is written in a non-Latin script such as Korean or Japanese.
+	MatchInputLanguage bool `json:"matchInputLanguage,omitempty"`
+	// ProbeModels probes each model for streaming, JSON and tool call support before its
+	// first turn; results are cached and decide how tools are offered.
+	ProbeModels bool `json:"probeModels,omitempty"`
+	// MCPLogEcho also prints MCP server warnings and errors to the terminal; all server logs go to the log file.
+	MCPLogEcho bool `json:"mcpLogEcho,omitempty"`
+	// DebugHTTPDump writes each turn's raw provider requests and responses to its own file under logs/.
+	DebugHTTPDump bool `json:"debugHttpDump,omitempty"`
+	// DisableBuiltinTools hides the local current_time, calculate, uuid and base64 tools from the model.
+	DisableBuiltinTools bool `json:"disableBuiltinTools,omitempty"`
+	// WorkspaceContext adds a project summary to each request when started inside a project.
+	WorkspaceContext WorkspaceContext `json:"workspaceContext,omitzero"`
+	// Speech reads completed sentences of each answer aloud.
+	Speech Speech `json:"speech,omitzero"`
+	// HistorySync commits each session file to a git repository after every answer.
+	HistorySync HistorySync `json:"historySync,omitzero"`
+	// HistoryStore mirrors session files to a remote store; the local sessions directory
+	// stays the working copy.
+	HistoryStore HistoryStore `json:"historyStore,omitzero"`
+}
+
+// History store types.
+const (
+	HistoryStoreFile   = "file"
+	HistoryStoreWebDAV = "webdav"
+)
+
+// HistoryStore selects where session files are kept besides the local sessions directory.
+type HistoryStore struct {
+	// Type is "file" (the default, local only) or "webdav".
+	Type string `json:"type,omitempty"`
+	// URL is the WebDAV collection that holds the session files.
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// EffectiveType returns the normalized store type, defaulting to the local file store.
+func (h HistoryStore) EffectiveType() string {
+	if t := strings.ToLower(strings.TrimSpace(h.Type)); t != "" {
+		return t
+	}
+	return HistoryStoreFile
+}
+
+// Validate reports unknown store types and remote stores without a URL.
+func (h HistoryStore) Validate() error {
+	switch h.EffectiveType() {
+	case HistoryStoreFile:
+		return nil
+	case HistoryStoreWebDAV:
+		if strings.TrimSpace(h.URL) == "" {
+			return errors.New("historyStore: webdav requires a url")
+		}
+		return nil
+	default:
+		return fmt.Errorf("historyStore: invalid type %q (use file or webdav)", h.Type)
+	}
+}
+
+// HistorySync configures committing session files to a git repository.
+type HistorySync struct {
+	// Repo is the git work tree (~/ expands to the home directory); syncing is off when empty.
+	Repo string `json:"repo,omitempty"`
+	// Remote, such as "origin", is pushed to after every commit when set.
+	Remote string `json:"remote,omitempty"`
+	// Branch is the remote branch to push to; empty pushes the current branch.
+	Branch string `json:"branch,omitempty"`
+}
+
+// WorkspaceContext configures the project summary sent as a context message.
+type WorkspaceContext struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxDepth limits how deep the directory tree goes (0 = default 3).
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// MaxEntries caps the number of tree entries (0 = default 200).
+	MaxEntries int `json:"maxEntries,omitempty"`
+	// HeaderLines is how many leading lines of each key file to include (0 = default 10).
+	HeaderLines int `json:"headerLines,omitempty"`
+	// KeyFiles replaces the default list of files whose headers are included.
+	KeyFiles []string `json:"keyFiles,omitempty"`
+}
+
+// Speech configures spoken answers through a local command or a speech endpoint.
+type Speech struct {
+	// Enabled turns speech on at startup; /speak toggles it during a session.
+	Enabled bool `json:"enabled,omitempty"`
+	// Command is the argv that reads a sentence on stdin (default say on macOS, espeak elsewhere).
+	// "{voice}" is replaced with Voice.
+	Command []string `json:"command,omitempty"`
+	Voice   string   `json:"voice,omitempty"`
+	// Endpoint is an OpenAI-style /audio/speech URL used instead of Command.
+	Endpoint string `json:"endpoint,omitempty"`
+	Model    string `json:"model,omitempty"`
+	APIKey   string `json:"apiKey,omitempty"`
+	// Player is the argv that plays the audio returned by Endpoint from stdin.
+	Player []string `json:"player,omitempty"`
+}
+
+// Validate reports settings that cannot be combined.
+func (s Speech) Validate() error {
+	if s.Endpoint != "" && len(s.Command) > 0 {
+		return errors.New("speech: set either endpoint or command, not both")
+	}
+	if len(s.Player) > 0 && s.Endpoint == "" {
+		return errors.New("speech: player requires an endpoint")
+	}
+	return nil
+}
+
+// Redaction configures PII masking for outbound requests.
+type Redaction struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Builtins selects built-in rules ("email", "phone", "ipv4"); empty means email and phone.
+	Builtins []string `json:"builtins,omitempty"`
+	// Patterns adds named regular expressions to mask.
+	Patterns []RedactionPattern `json:"patterns,omitempty"`
+	// IncludeLocal also masks requests to local providers such as Ollama.
+	IncludeLocal bool `json:"includeLocal,omitempty"`
+}
+
+// RedactionPattern is a named regular expression whose matches are masked.
+type RedactionPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+// Injection scan modes.
+const (
+	InjectionScanOff    = "off"
+	InjectionScanWarn   = "warn"
+	InjectionScanEscape = "escape"
+)
+
+// EffectiveInjectionScan returns the normalized injection scan mode, defaulting to off.
+func (c Config) EffectiveInjectionScan() string {
+	mode := strings.ToLower(strings.TrimSpace(c.InjectionScan))
+	if mode == "" {
+		return InjectionScanOff
+	}
+	return mode
+}
+
+// ToolPolicy overrides the destructive tool call heuristics with "server.method" glob patterns.
+type ToolPolicy struct {
+	// Destructive patterns always require confirmation, even in auto mode.
+	Destructive []string `json:"destructive,omitempty"`
+	// Safe patterns are exempt from the built-in heuristics.
+	Safe []string `json:"safe,omitempty"`
+	// Retries configures what happens when matching MCP calls fail; the first match wins.
+	Retries []ToolRetry `json:"retries,omitempty"`
+}
+
+// ToolRetry is the retry policy for MCP calls matching a "server.method" glob pattern.
+type ToolRetry struct {
+	Match string `json:"match"`
+	// Retries is how many times a failed call is repeated with the same arguments.
+	Retries int `json:"retries,omitempty"`
+	// BackoffMillis is the delay before the first retry; it doubles for each further retry.
+	BackoffMillis int `json:"backoffMs,omitempty"`
+	// AdjustArguments is how many times per turn the model may call the tool again with
+	// corrected arguments once the retries have failed.
+	AdjustArguments int `json:"adjustArguments,omitempty"`
+}
+
+// FindRetry returns the retry policy for server.method.
+func (p ToolPolicy) FindRetry(server, method string) (ToolRetry, bool) {
+	target := server + "." + method
+	for _, retry := range p.Retries {
+		if ok, _ := path.Match(retry.Match, target); ok {
+			return retry, true
+		}
+	}
+	return ToolRetry{}, false
+}
+
+// FindAlias returns the expansion for a slash command such as "/rev" (the leading slash is optional).
+func (c Config) FindAlias(command string) (string, bool) {
+	name := strings.TrimPrefix(strings.TrimSpace(command), "/")
+	for key, body := range c.Aliases {
+		if strings.TrimPrefix(strings.TrimSpace(key), "/") == name {
+			return body, true
+		}
+	}
+	return "", false
+}
+
+// Hook input modes.
+const (
+	HookInputMessage = "message"
+	HookInputCode    = "code"
+)
+
+// Hook is an external command run around an LLM turn.
+type Hook struct {
+	Name string `json:"name,omitempty"`
+	// Command is the argv to execute; "{file}" is replaced with a temp file holding the input.
+	Command []string `json:"command"`
+	// Input selects what is piped to the command: the whole message (default) or each code block.
+	Input string `json:"input,omitempty"`
+	// Languages limits code input to fenced blocks with these language tags.
+	Languages      []string `json:"languages,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"`
+}
+
+// DisplayName returns the hook name, falling back to the command.
+func (h Hook) DisplayName() string {
+	if name := strings.TrimSpace(h.Name); name != "" {
+		return name
+	}
+	return strings.Join(h.Command, " ")
 }
 
 // FindModel locates a model by name.
@@ -49,7 +482,25 @@ func (c Config) FindModel(name string) (Model, bool) {
 	return Model{}, false
 }
 
-// Validate ensures configuration integrity.
+// FindPersona locates a persona by name, ignoring surrounding spaces as validation does.
+func (c Config) FindPersona(name string) (Persona, bool) {
+	name = strings.TrimSpace(name)
+	for _, p := range c.Personas {
+		if strings.TrimSpace(p.Name) == name {
+			return p, true
+		}
+	}
+	return Persona{}, false
+}
+
+// CurrentPersona returns the persona selected by activePersona, if any.
+func (c Config) CurrentPersona() (Persona, bool) {
+	name := strings.TrimSpace(c.ActivePersona)
+	if name == "" {
+		return Persona{}, false
+	}
+	return c.FindPersona(name)
+}
 
 // ActiveModel returns the active model configuration if present.
 func (c Config) ActiveModel() (Model, bool) {
@@ -61,6 +512,16 @@ func (c Config) ActiveModel() (Model, bool) {
 	return Model{}, false
 }
 
+// TurnLabels returns the label of the user's turns and of answers from the named model;
+// an empty label means the default.
+func (c Config) TurnLabels(model string) (user, assistant string) {
+	assistant = strings.TrimSpace(c.AssistantLabel)
+	if m, ok := c.FindModel(model); ok && strings.TrimSpace(m.AssistantLabel) != "" {
+		assistant = strings.TrimSpace(m.AssistantLabel)
+	}
+	return strings.TrimSpace(c.UserLabel), assistant
+}
+
 // ActiveModelName returns the name of the active model, or empty string.
 func (c Config) ActiveModelName() string {
 	if m, ok := c.ActiveModel(); ok {
@@ -80,6 +541,49 @@ func (c Config) Validate() error {
 	if activeCount > 1 {
 		return errors.New("multiple models marked as active")
 	}
+	for _, m := range c.Models {
+		if m.ContextWindow < 0 {
+			return fmt.Errorf("model %q has negative contextWindow", m.Name)
+		}
+		if m.ChunkOverlapTokens < 0 {
+			return fmt.Errorf("model %q has negative chunkOverlapTokens", m.Name)
+		}
+		if name := strings.TrimSpace(m.Tokenizer); name != "" {
+			if _, ok := validTokenizers[strings.ToLower(name)]; !ok {
+				return fmt.Errorf("model %q has invalid tokenizer %q", m.Name, m.Tokenizer)
+			}
+		}
+		if effort := strings.TrimSpace(m.ReasoningEffort); effort != "" {
+			if _, ok := validReasoningEfforts[strings.ToLower(effort)]; !ok {
+				return fmt.Errorf("model %q has invalid reasoningEffort %q", m.Name, m.ReasoningEffort)
+			}
+		}
+		if m.ThinkingBudget < 0 {
+			return fmt.Errorf("model %q has negative thinkingBudget", m.Name)
+		}
+		if strategy := strings.TrimSpace(m.ToolStrategy); strategy != "" {
+			if _, ok := validToolStrategies[strings.ToLower(strategy)]; !ok {
+				return fmt.Errorf("model %q has invalid toolStrategy %q (use native, direct or chooseFunction)", m.Name, m.ToolStrategy)
+			}
+		}
+		if m.ToolCallRepairs < 0 {
+			return fmt.Errorf("model %q has negative toolCallRepairs", m.Name)
+		}
+		if m.SupportsReasoning != nil && !*m.SupportsReasoning && (strings.TrimSpace(m.ReasoningEffort) != "" || m.ThinkingBudget > 0) {
+			return fmt.Errorf("model %q sets reasoningEffort or thinkingBudget but declares supportsReasoning: false", m.Name)
+		}
+		if !m.ToolsEnabled() && (strings.TrimSpace(m.ToolStrategy) != "" || m.ConstrainToolCalls || m.ToolCallRepairs > 0) {
+			return fmt.Errorf("model %q configures tool calling but declares supportsTools: false", m.Name)
+		}
+		if len(m.APIKeyCommand) > 0 {
+			if m.APIKey != "" {
+				return fmt.Errorf("model %q: set either apiKey or apiKeyCommand, not both", m.Name)
+			}
+			if strings.TrimSpace(m.APIKeyCommand[0]) == "" {
+				return fmt.Errorf("model %q has an empty apiKeyCommand", m.Name)
+			}
+		}
+	}
 	if strings.TrimSpace(c.LogLevel) != "" {
 		if _, ok := validLogLevels[strings.ToLower(strings.TrimSpace(c.LogLevel))]; !ok {
 			return fmt.Errorf("invalid logLevel %q", c.LogLevel)
@@ -92,10 +596,231 @@ func (c Config) Validate() error {
 			return fmt.Errorf("invalid toolCallMode %q", c.ToolCallMode)
 		}
 	}
+	if raw := strings.TrimSpace(c.ToolConfirmTimeout); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			return fmt.Errorf("invalid toolConfirmTimeout %q (use a positive duration such as \"2m\")", c.ToolConfirmTimeout)
+		}
+	}
+	if _, err := ParseThinkingOutput(c.ThinkingOutput); err != nil {
+		return err
+	}
+	if raw := strings.TrimSpace(c.GenerationTimeout); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+			return fmt.Errorf("invalid generationTimeout %q (use a positive duration such as \"5m\")", c.GenerationTimeout)
+		}
+	}
 
+	if err := validatePersonas(c.Personas); err != nil {
+		return err
+	}
+	if err := validateHooks(c.PostResponseHooks); err != nil {
+		return err
+	}
+	if err := validateHooks(c.PreSendHooks); err != nil {
+		return err
+	}
+	for key, body := range c.Aliases {
+		name := strings.TrimPrefix(strings.TrimSpace(key), "/")
+		if name == "" || strings.ContainsAny(name, " \t/") {
+			return fmt.Errorf("invalid alias name %q", key)
+		}
+		if strings.TrimSpace(body) == "" {
+			return fmt.Errorf("alias %q has an empty expansion", key)
+		}
+	}
+	for _, pattern := range append(append([]string(nil), c.ToolPolicy.Destructive...), c.ToolPolicy.Safe...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid toolPolicy pattern %q: %w", pattern, err)
+		}
+	}
+	for _, retry := range c.ToolPolicy.Retries {
+		if strings.TrimSpace(retry.Match) == "" {
+			return errors.New("toolPolicy retry needs a match pattern")
+		}
+		if _, err := path.Match(retry.Match, ""); err != nil {
+			return fmt.Errorf("invalid toolPolicy retry pattern %q: %w", retry.Match, err)
+		}
+		if retry.Retries < 0 || retry.BackoffMillis < 0 || retry.AdjustArguments < 0 {
+			return fmt.Errorf("toolPolicy retry %q must not have negative values", retry.Match)
+		}
+	}
+	if err := validatePrompt(c.Prompt); err != nil {
+		return err
+	}
+	if strings.ContainsAny(c.UserLabel, "\r\n") || strings.ContainsAny(c.AssistantLabel, "\r\n") {
+		return errors.New("userLabel and assistantLabel must be a single line")
+	}
+	for _, m := range c.Models {
+		if strings.ContainsAny(m.AssistantLabel, "\r\n") {
+			return fmt.Errorf("model %q assistantLabel must be a single line", m.Name)
+		}
+	}
+	if err := validateKeybindings(c.Keybindings); err != nil {
+		return err
+	}
+	if err := validateRedaction(c.Redaction); err != nil {
+		return err
+	}
+	if c.AutoContinue < 0 {
+		return fmt.Errorf("autoContinue must not be negative, got %d", c.AutoContinue)
+	}
+	if c.ResumeStreams < 0 {
+		return fmt.Errorf("resumeStreams must not be negative, got %d", c.ResumeStreams)
+	}
+	if c.MaxOutputTokens < 0 {
+		return fmt.Errorf("maxOutputTokens must not be negative, got %d", c.MaxOutputTokens)
+	}
+	if c.MaxOutputChars < 0 {
+		return fmt.Errorf("maxOutputChars must not be negative, got %d", c.MaxOutputChars)
+	}
+	if err := c.WorkspaceContext.Validate(); err != nil {
+		return err
+	}
+	if err := c.Speech.Validate(); err != nil {
+		return err
+	}
+	if err := c.HistoryStore.Validate(); err != nil {
+		return err
+	}
+	switch c.EffectiveInjectionScan() {
+	case InjectionScanOff, InjectionScanWarn, InjectionScanEscape:
+	default:
+		return fmt.Errorf("invalid injectionScan %q", c.InjectionScan)
+	}
+	for _, h := range c.PreSendHooks {
+		if strings.EqualFold(strings.TrimSpace(h.Input), HookInputCode) {
+			return fmt.Errorf("pre-send hook %q only supports message input", h.DisplayName())
+		}
+	}
+	if name := strings.TrimSpace(c.ActivePersona); name != "" {
+		if _, ok := c.FindPersona(name); !ok {
+			return fmt.Errorf("activePersona %q is not defined", c.ActivePersona)
+		}
+	}
+
+	return nil
+}
+
+func validatePersonas(personas []Persona) error {
+	seen := make(map[string]struct{}, len(personas))
+	for _, p := range personas {
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			return errors.New("persona name is required")
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate persona %q", name)
+		}
+		seen[name] = struct{}{}
+		for _, msg := range p.Prelude {
+			role := strings.ToLower(strings.TrimSpace(msg.Role))
+			if role != "user" && role != "assistant" {
+				return fmt.Errorf("persona %q prelude has invalid role %q", name, msg.Role)
+			}
+		}
+	}
+	return nil
+}
+
+func validateHooks(hooks []Hook) error {
+	for i, h := range hooks {
+		if len(h.Command) == 0 || strings.TrimSpace(h.Command[0]) == "" {
+			return fmt.Errorf("hook %d (%s) requires a command", i+1, h.Name)
+		}
+		switch strings.ToLower(strings.TrimSpace(h.Input)) {
+		case "", HookInputMessage, HookInputCode:
+		default:
+			return fmt.Errorf("hook %q has invalid input %q", h.DisplayName(), h.Input)
+		}
+		if h.TimeoutSeconds < 0 {
+			return fmt.Errorf("hook %q has negative timeoutSeconds", h.DisplayName())
+		}
+	}
 	return nil
 }
 
+var promptPlaceholder = regexp.MustCompile(`\{([a-zA-Z]+)\}`)
+
+func validatePrompt(prompt string) error {
+	for _, match := range promptPlaceholder.FindAllStringSubmatch(prompt, -1) {
+		if _, ok := validPromptPlaceholders[match[1]]; !ok {
+			return fmt.Errorf("prompt has unknown placeholder %q", match[0])
+		}
+	}
+	return nil
+}
+
+var keybindingPattern = regexp.MustCompile(`^ctrl[+-][a-z]$`)
+
+func validateKeybindings(bindings map[string]string) error {
+	seen := make(map[string]string, len(bindings))
+	for action, key := range bindings {
+		if _, ok := validKeyActions[strings.ToLower(strings.TrimSpace(action))]; !ok {
+			return fmt.Errorf("unknown keybinding action %q", action)
+		}
+		normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(key), " ", ""))
+		if !keybindingPattern.MatchString(normalized) {
+			return fmt.Errorf("keybinding %q: unsupported key %q (use ctrl+<letter>)", action, key)
+		}
+		switch normalized[len(normalized)-1] {
+		case 'i', 'j', 'm':
+			return fmt.Errorf("keybinding %q: key %q is reserved for Tab/Enter", action, key)
+		}
+		letter := normalized[len(normalized)-1:]
+		if other, dup := seen[letter]; dup {
+			return fmt.Errorf("keybindings %q and %q use the same key", other, action)
+		}
+		seen[letter] = action
+	}
+	return nil
+}
+
+func validateRedaction(r Redaction) error {
+	for _, name := range r.Builtins {
+		if _, ok := validRedactionBuiltins[strings.ToLower(strings.TrimSpace(name))]; !ok {
+			return fmt.Errorf("invalid redaction builtin %q", name)
+		}
+	}
+	for i, p := range r.Patterns {
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("redaction pattern %d requires a name", i+1)
+		}
+		if strings.TrimSpace(p.Regex) == "" {
+			return fmt.Errorf("redaction pattern %q requires a regex", p.Name)
+		}
+		if _, err := regexp.Compile(p.Regex); err != nil {
+			return fmt.Errorf("redaction pattern %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// ConfirmTimeout returns the parsed toolConfirmTimeout, or 0 when confirmations never time out.
+func (c Config) ConfirmTimeout() time.Duration {
+	raw := strings.TrimSpace(c.ToolConfirmTimeout)
+	if raw == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// GenerationLimit returns the parsed generationTimeout, or 0 when answers never time out.
+func (c Config) GenerationLimit() time.Duration {
+	raw := strings.TrimSpace(c.GenerationTimeout)
+	if raw == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
 // EffectiveToolCallMode returns the configured tool call mode, defaulting to manual.
 func (c Config) EffectiveToolCallMode() ToolCallMode {
 	mode := strings.ToLower(strings.TrimSpace(c.ToolCallMode))
@@ -123,7 +848,7 @@ func NewFileStore(home string) *FileStore {
 }
 
 func (f *FileStore) configPath() string {
-	return filepath.Join(f.home, ".humble-ai-cli", "config.json")
+	return filepath.Join(Dir(f.home), "config.json")
 }
 
 // Load reads configuration from disk.
@@ -142,7 +867,7 @@ func (f *FileStore) Load() (Config, error) {
 
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return Config{}, fmt.Errorf("parse config: %w", err)
+		return Config{}, fmt.Errorf("parse config: %w", jsoncheck.Annotate(data, err))
 	}
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
@@ -150,6 +875,28 @@ func (f *FileStore) Load() (Config, error) {
 	return cfg, nil
 }
 
+// CheckFile reports unknown fields, type mismatches and validation errors in the config file at path.
+func CheckFile(path string) ([]jsoncheck.Issue, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	issues, err := jsoncheck.Check(data, Config{})
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err == nil {
+		if err := cfg.Validate(); err != nil {
+			issues = append(issues, jsoncheck.Issue{Message: err.Error()})
+		}
+	}
+	return issues, nil
+}
+
 // Save writes configuration to disk.
 func (f *FileStore) Save(cfg Config) error {
 	f.mu.Lock()
@@ -183,3 +930,49 @@ var validLogLevels = map[string]struct{}{
 	"warn":  {},
 	"error": {},
 }
+
+var validPromptPlaceholders = map[string]struct{}{
+	"model": {}, "provider": {}, "persona": {}, "mode": {}, "profile": {},
+	"reset": {}, "bold": {}, "dim": {},
+	"red": {}, "green": {}, "yellow": {}, "blue": {}, "magenta": {}, "cyan": {},
+}
+
+var validKeyActions = map[string]struct{}{
+	"move-left":   {},
+	"move-right":  {},
+	"home":        {},
+	"end":         {},
+	"backspace":   {},
+	"delete":      {},
+	"clear-line":  {},
+	"kill-to-end": {},
+	"interrupt":   {},
+	"eof":         {},
+}
+
+var validRedactionBuiltins = map[string]struct{}{
+	"email": {},
+	"phone": {},
+	"ipv4":  {},
+}
+
+var validReasoningEfforts = map[string]struct{}{
+	"none":    {},
+	"minimal": {},
+	"low":     {},
+	"medium":  {},
+	"high":    {},
+}
+
+var validToolStrategies = map[string]ToolStrategy{
+	"native":         ToolStrategyNative,
+	"direct":         ToolStrategyDirect,
+	"choosefunction": ToolStrategyChooseFunction,
+}
+
+var validTokenizers = map[string]struct{}{
+	"o200k_base":  {},
+	"llama":       {},
+	"heuristic":   {},
+	"cl100k_base": {},
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gamzabox/humble-ai-cli/internal/config"
 )
@@ -113,6 +115,58 @@ func TestConfigValidateRejectsInvalidLogLevel(t *testing.T) {
 	}
 }
 
+func TestConfigValidateRejectsNegativeAutoContinue(t *testing.T) {
+	cfg := config.Config{AutoContinue: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected validation error for negative autoContinue")
+	}
+	cfg.AutoContinue = 2
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidateRejectsNegativeResumeStreams(t *testing.T) {
+	cfg := config.Config{ResumeStreams: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected validation error for negative resumeStreams")
+	}
+	cfg.ResumeStreams = 3
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigTurnLabelsPreferModelLabel(t *testing.T) {
+	cfg := config.Config{
+		UserLabel:      "Me",
+		AssistantLabel: "Bot",
+		Models:         []config.Model{{Name: "claude", AssistantLabel: "Claude"}, {Name: "llama3"}},
+	}
+	if user, assistant := cfg.TurnLabels("claude"); user != "Me" || assistant != "Claude" {
+		t.Fatalf("TurnLabels(claude) = %q, %q", user, assistant)
+	}
+	if _, assistant := cfg.TurnLabels("llama3"); assistant != "Bot" {
+		t.Fatalf("TurnLabels(llama3) assistant = %q", assistant)
+	}
+	cfg.Models[0].AssistantLabel = "Two\nLines"
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected validation error for a multi-line label")
+	}
+}
+
+func TestConfigValidateRejectsNegativeOutputCaps(t *testing.T) {
+	for _, cfg := range []config.Config{{MaxOutputTokens: -1}, {MaxOutputChars: -1}} {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", cfg)
+		}
+	}
+	cfg := config.Config{MaxOutputTokens: 4000, MaxOutputChars: 20000}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestConfigValidateRejectsInvalidToolCallMode(t *testing.T) {
 	cfg := config.Config{
 		ToolCallMode: "sometimes",
@@ -125,6 +179,64 @@ func TestConfigValidateRejectsInvalidToolCallMode(t *testing.T) {
 	}
 }
 
+func TestConfigToolConfirmTimeout(t *testing.T) {
+	cfg := config.Config{}
+	if got := cfg.ConfirmTimeout(); got != 0 {
+		t.Fatalf("expected no timeout by default, got %s", got)
+	}
+	cfg.ToolConfirmTimeout = "2m"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.ConfirmTimeout(); got != 2*time.Minute {
+		t.Fatalf("expected 2m, got %s", got)
+	}
+	for _, invalid := range []string{"soon", "-5s", "0s"} {
+		cfg.ToolConfirmTimeout = invalid
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for toolConfirmTimeout %q", invalid)
+		}
+	}
+}
+
+func TestConfigGenerationTimeout(t *testing.T) {
+	cfg := config.Config{}
+	if got := cfg.GenerationLimit(); got != 0 {
+		t.Fatalf("expected no limit by default, got %s", got)
+	}
+	cfg.GenerationTimeout = "5m"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GenerationLimit(); got != 5*time.Minute {
+		t.Fatalf("expected 5m, got %s", got)
+	}
+	for _, invalid := range []string{"later", "-1m", "0"} {
+		cfg.GenerationTimeout = invalid
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for generationTimeout %q", invalid)
+		}
+	}
+}
+
+func TestParseThinkingOutput(t *testing.T) {
+	for raw, want := range map[string]config.ThinkingOutput{
+		"":       config.ThinkingOutputStdout,
+		"stdout": config.ThinkingOutputStdout,
+		" Drop ": config.ThinkingOutputDrop,
+		"stderr": config.ThinkingOutputStderr,
+		"auto":   config.ThinkingOutputAuto,
+	} {
+		if got, err := config.ParseThinkingOutput(raw); err != nil || got != want {
+			t.Fatalf("ParseThinkingOutput(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	cfg := config.Config{ThinkingOutput: "file"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `invalid thinkingOutput "file"`) {
+		t.Fatalf("expected validation error for thinkingOutput, got %v", err)
+	}
+}
+
 func TestConfigEffectiveToolCallModeDefaultsToManual(t *testing.T) {
 	cfg := config.Config{}
 	if got := cfg.EffectiveToolCallMode(); got != config.ToolCallModeManual {
@@ -148,3 +260,382 @@ func TestConfigValidateRejectsMultipleActiveModels(t *testing.T) {
 		t.Fatalf("expected validation error when multiple models are active")
 	}
 }
+
+func TestConfigValidatePersonas(t *testing.T) {
+	valid := config.Config{
+		Personas: []config.Persona{
+			{
+				Name: "reviewer",
+				Prelude: []config.PreludeMessage{
+					{Role: "user", Content: "Review: x := 1"},
+					{Role: "assistant", Content: "Looks fine."},
+				},
+			},
+		},
+		ActivePersona: "reviewer",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid persona config, got %v", err)
+	}
+	if p, ok := valid.CurrentPersona(); !ok || p.Name != "reviewer" {
+		t.Fatalf("expected current persona reviewer, got %+v (ok=%v)", p, ok)
+	}
+
+	badRole := valid
+	badRole.Personas = []config.Persona{{
+		Name:    "reviewer",
+		Prelude: []config.PreludeMessage{{Role: "system", Content: "nope"}},
+	}}
+	if err := badRole.Validate(); err == nil {
+		t.Fatalf("expected validation error for invalid prelude role")
+	}
+
+	missing := config.Config{ActivePersona: "ghost"}
+	if err := missing.Validate(); err == nil {
+		t.Fatalf("expected validation error for undefined active persona")
+	}
+
+	duplicate := config.Config{Personas: []config.Persona{{Name: "a"}, {Name: "a"}}}
+	if err := duplicate.Validate(); err == nil {
+		t.Fatalf("expected validation error for duplicate personas")
+	}
+
+	padded := config.Config{Personas: []config.Persona{{Name: " coder "}}, ActivePersona: "coder"}
+	if err := padded.Validate(); err != nil {
+		t.Fatalf("expected a padded persona name to match, got %v", err)
+	}
+	if p, ok := padded.FindPersona(" coder"); !ok || p.Name != " coder " {
+		t.Fatalf("expected FindPersona to ignore surrounding spaces, got %+v (ok=%v)", p, ok)
+	}
+}
+
+func TestConfigValidateTokenizer(t *testing.T) {
+	for _, name := range []string{"llama", "cl100k_base"} {
+		valid := config.Config{Models: []config.Model{{Name: "m", Provider: "ollama", Tokenizer: name}}}
+		if err := valid.Validate(); err != nil {
+			t.Fatalf("expected valid tokenizer %s, got %v", name, err)
+		}
+	}
+
+	invalid := config.Config{Models: []config.Model{{Name: "llama3", Provider: "ollama", Tokenizer: "p50k"}}}
+	if err := invalid.Validate(); err == nil {
+		t.Fatalf("expected validation error for unknown tokenizer")
+	}
+}
+
+func TestConfigValidateRejectsNegativeChunkOverlap(t *testing.T) {
+	cfg := config.Config{Models: []config.Model{{Name: "m", Provider: "ollama", ContextWindow: 8192, ChunkOverlapTokens: -1}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "chunkOverlapTokens") {
+		t.Fatalf("expected validation error for negative chunkOverlapTokens, got %v", err)
+	}
+}
+
+func TestConfigValidateReasoning(t *testing.T) {
+	valid := config.Config{Models: []config.Model{{Name: "o3", Provider: "openai", ReasoningEffort: "High", ThinkingBudget: 2048}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid reasoning config, got %v", err)
+	}
+
+	for _, model := range []config.Model{
+		{Name: "o3", Provider: "openai", ReasoningEffort: "extreme"},
+		{Name: "o3", Provider: "openai", ThinkingBudget: -1},
+	} {
+		invalid := config.Config{Models: []config.Model{model}}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", model)
+		}
+	}
+}
+
+func TestModelToolStrategy(t *testing.T) {
+	yes := true
+	cases := []struct {
+		model config.Model
+		want  config.ToolStrategy
+	}{
+		{config.Model{Provider: "openai"}, config.ToolStrategyNative},
+		{config.Model{Provider: "ollama"}, config.ToolStrategyDirect},
+		{config.Model{Provider: "ollama", ToolStrategy: "native"}, config.ToolStrategyNative},
+		{config.Model{Provider: "openai", ToolStrategy: "ChooseFunction"}, config.ToolStrategyChooseFunction},
+		{config.Model{Provider: "ollama", SupportsTools: &yes}, config.ToolStrategyNative},
+		{config.Model{Provider: "ollama", SupportsTools: &yes, ToolStrategy: "direct"}, config.ToolStrategyDirect},
+	}
+	for _, tc := range cases {
+		if got := tc.model.EffectiveToolStrategy(); got != tc.want {
+			t.Fatalf("EffectiveToolStrategy(%+v) = %s, want %s", tc.model, got, tc.want)
+		}
+	}
+
+	invalid := config.Config{Models: []config.Model{{Name: "m", Provider: "openai", ToolStrategy: "twoStep"}}}
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "toolStrategy") {
+		t.Fatalf("expected toolStrategy validation error, got %v", err)
+	}
+	negative := config.Config{Models: []config.Model{{Name: "m", Provider: "ollama", ToolCallRepairs: -1}}}
+	if err := negative.Validate(); err == nil || !strings.Contains(err.Error(), "toolCallRepairs") {
+		t.Fatalf("expected toolCallRepairs validation error, got %v", err)
+	}
+}
+
+func TestToolPolicyRetries(t *testing.T) {
+	policy := config.ToolPolicy{Retries: []config.ToolRetry{{Match: "docs.read", Retries: 1}, {Match: "docs.*", Retries: 3}}}
+	if retry, ok := policy.FindRetry("docs", "read"); !ok || retry.Retries != 1 {
+		t.Fatalf("expected the first matching policy, got %+v", retry)
+	}
+	if _, ok := policy.FindRetry("git", "push"); ok {
+		t.Fatalf("expected no policy for git.push")
+	}
+
+	for _, retry := range []config.ToolRetry{
+		{Retries: 1},
+		{Match: "docs.[", Retries: 1},
+		{Match: "docs.*", BackoffMillis: -1},
+	} {
+		invalid := config.Config{ToolPolicy: config.ToolPolicy{Retries: []config.ToolRetry{retry}}}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", retry)
+		}
+	}
+}
+
+func TestConfigValidateSpeech(t *testing.T) {
+	valid := config.Config{Speech: config.Speech{Enabled: true, Endpoint: "http://localhost:8880/v1/audio/speech", Player: []string{"mpv", "-"}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid speech config, got %v", err)
+	}
+
+	for _, speech := range []config.Speech{
+		{Endpoint: "http://localhost:8880/v1/audio/speech", Command: []string{"say"}},
+		{Command: []string{"say"}, Player: []string{"mpv", "-"}},
+	} {
+		invalid := config.Config{Speech: speech}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", speech)
+		}
+	}
+}
+
+func TestModelCapabilities(t *testing.T) {
+	yes, no := true, false
+	model := config.Model{Name: "llava", Provider: "ollama", SupportsTools: &no, SupportsVision: &yes, ContextWindow: 32768}
+	if model.ToolsEnabled() {
+		t.Fatal("expected tools to be disabled for supportsTools: false")
+	}
+	if got, want := strings.Join(model.Capabilities(), ", "), "no tools, vision, 32k context"; got != want {
+		t.Fatalf("Capabilities() = %q, want %q", got, want)
+	}
+	if undeclared := (config.Model{Provider: "openai"}); !undeclared.ToolsEnabled() || len(undeclared.Capabilities()) != 0 {
+		t.Fatalf("expected undeclared capabilities to keep the defaults, got %v", undeclared.Capabilities())
+	}
+
+	for _, invalid := range []config.Model{
+		{Name: "m", Provider: "openai", SupportsReasoning: &no, ReasoningEffort: "high"},
+		{Name: "m", Provider: "ollama", SupportsTools: &no, ToolStrategy: "native"},
+	} {
+		if err := (config.Config{Models: []config.Model{invalid}}).Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", invalid)
+		}
+	}
+}
+
+func TestConfigValidateAPIKeyCommand(t *testing.T) {
+	valid := config.Config{Models: []config.Model{{Name: "gpt-4o", Provider: "openai", APIKeyCommand: []string{"pass", "show", "openai"}}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid apiKeyCommand, got %v", err)
+	}
+
+	for _, model := range []config.Model{
+		{Name: "gpt-4o", Provider: "openai", APIKey: "sk", APIKeyCommand: []string{"pass", "show", "openai"}},
+		{Name: "gpt-4o", Provider: "openai", APIKeyCommand: []string{" "}},
+	} {
+		invalid := config.Config{Models: []config.Model{model}}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", model)
+		}
+	}
+}
+
+func TestConfigValidateHistoryStore(t *testing.T) {
+	valid := config.Config{HistoryStore: config.HistoryStore{Type: "WebDAV", URL: "https://dav.example.com/sessions/"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid historyStore, got %v", err)
+	}
+
+	for _, store := range []config.HistoryStore{
+		{Type: "webdav"},
+		{Type: "s3", URL: "https://bucket.example.com"},
+	} {
+		invalid := config.Config{HistoryStore: store}
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("expected validation error for %+v", store)
+		}
+	}
+}
+
+func TestConfigValidateHooks(t *testing.T) {
+	valid := config.Config{PostResponseHooks: []config.Hook{{Name: "fmt", Command: []string{"gofmt"}, Input: "code"}}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid hook config, got %v", err)
+	}
+
+	for name, hook := range map[string]config.Hook{
+		"missing command":  {Name: "empty"},
+		"invalid input":    {Command: []string{"cat"}, Input: "file"},
+		"negative timeout": {Command: []string{"cat"}, TimeoutSeconds: -1},
+	} {
+		cfg := config.Config{PostResponseHooks: []config.Hook{hook}}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestConfigValidateAliases(t *testing.T) {
+	valid := config.Config{Aliases: map[string]string{"/rev": "Review this", "auto": "/set-tool-mode auto"}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid aliases, got %v", err)
+	}
+	if body, ok := valid.FindAlias("rev"); !ok || body != "Review this" {
+		t.Fatalf("expected to find alias without slash, got %q (%v)", body, ok)
+	}
+
+	for _, aliases := range []map[string]string{
+		{"two words": "x"},
+		{"/": "x"},
+		{"empty": "  "},
+	} {
+		if err := (config.Config{Aliases: aliases}).Validate(); err == nil {
+			t.Fatalf("expected validation error for %v", aliases)
+		}
+	}
+}
+
+func TestConfigValidateInjectionScan(t *testing.T) {
+	for _, mode := range []string{"", "off", "warn", "Escape"} {
+		if err := (config.Config{InjectionScan: mode}).Validate(); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", mode, err)
+		}
+	}
+	if err := (config.Config{InjectionScan: "block"}).Validate(); err == nil {
+		t.Fatalf("expected validation error for unknown injectionScan")
+	}
+}
+
+func TestConfigValidateRedaction(t *testing.T) {
+	valid := config.Config{Redaction: config.Redaction{
+		Enabled:  true,
+		Builtins: []string{"email", "IPv4"},
+		Patterns: []config.RedactionPattern{{Name: "ticket", Regex: `JIRA-\d+`}},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid redaction config, got %v", err)
+	}
+
+	for name, r := range map[string]config.Redaction{
+		"unknown builtin": {Builtins: []string{"ssn"}},
+		"missing name":    {Patterns: []config.RedactionPattern{{Regex: "x"}}},
+		"invalid regex":   {Patterns: []config.RedactionPattern{{Name: "bad", Regex: "("}}},
+	} {
+		if err := (config.Config{Redaction: r}).Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestFileStoreLoadReportsErrorPosition(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, ".humble-ai-cli")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	content := "{\n  \"models\": [\n    {\"name\": \"gpt\", \"active\": \"yes\"}\n  ]\n}"
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_, err := config.NewFileStore(home).Load()
+	if err == nil || !strings.Contains(err.Error(), "line 3, column") || !strings.Contains(err.Error(), "active") {
+		t.Fatalf("expected positioned type error, got %v", err)
+	}
+}
+
+func TestConfigValidatePromptAndKeybindings(t *testing.T) {
+	valid := config.Config{
+		Prompt:      "{bold}{model}{reset} ({mode})> ",
+		Keybindings: map[string]string{"home": "ctrl+g", "clear-line": "Ctrl-W"},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid prompt config, got %v", err)
+	}
+
+	for name, cfg := range map[string]config.Config{
+		"unknown placeholder": {Prompt: "{cwd}> "},
+		"unknown action":      {Keybindings: map[string]string{"undo": "ctrl+z"}},
+		"unsupported key":     {Keybindings: map[string]string{"home": "alt+a"}},
+		"reserved key":        {Keybindings: map[string]string{"home": "ctrl+m"}},
+		"duplicate key":       {Keybindings: map[string]string{"home": "ctrl+g", "end": "ctrl-g"}},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestLoadProjectOverlayOverridesWorkspaceContext(t *testing.T) {
+	root := t.TempDir()
+	overlay, err := config.LoadProjectOverlay(root)
+	if err != nil || overlay.WorkspaceContext != nil {
+		t.Fatalf("expected empty overlay without a project file, got %+v, %v", overlay, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, config.ProjectFileName), []byte(`{"workspaceContext": {"enabled": false}}`), 0o644); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+	overlay, err = config.LoadProjectOverlay(root)
+	if err != nil {
+		t.Fatalf("LoadProjectOverlay() error = %v", err)
+	}
+	cfg := overlay.Apply(config.Config{WorkspaceContext: config.WorkspaceContext{Enabled: true, MaxDepth: 5}})
+	if cfg.WorkspaceContext.Enabled || cfg.WorkspaceContext.MaxDepth != 5 {
+		t.Fatalf("expected the project overlay to disable workspaceContext, got %+v", cfg.WorkspaceContext)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, config.ProjectFileName), []byte(`{"workspaceContext": {"maxDepth": -1}}`), 0o644); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+	if _, err := config.LoadProjectOverlay(root); err == nil {
+		t.Fatalf("expected negative limits to be rejected")
+	}
+}
+
+func TestProjectOverlayCannotWidenWorkspaceContext(t *testing.T) {
+	overlay := config.ProjectOverlay{WorkspaceContext: &config.WorkspaceContext{
+		Enabled:     true,
+		MaxEntries:  5000,
+		HeaderLines: 500,
+		MaxDepth:    2,
+	}}
+
+	cfg := overlay.Apply(config.Config{})
+	if cfg.WorkspaceContext.Enabled {
+		t.Fatalf("expected the project overlay not to enable workspaceContext, got %+v", cfg.WorkspaceContext)
+	}
+
+	cfg = overlay.Apply(config.Config{WorkspaceContext: config.WorkspaceContext{Enabled: true, HeaderLines: 20}})
+	got := cfg.WorkspaceContext
+	if !got.Enabled || got.MaxEntries != 200 || got.HeaderLines != 20 || got.MaxDepth != 2 {
+		t.Fatalf("expected limits capped at the user's config, got %+v", got)
+	}
+}
+
+func TestLoadProjectOverlayRejectsKeyFilesOutsideProject(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"../../.ssh/id_rsa", "/etc/passwd"} {
+		data := []byte(`{"workspaceContext": {"keyFiles": ["README.md", "` + name + `"]}}`)
+		if err := os.WriteFile(filepath.Join(root, config.ProjectFileName), data, 0o644); err != nil {
+			t.Fatalf("write overlay: %v", err)
+		}
+		if _, err := config.LoadProjectOverlay(root); err == nil {
+			t.Fatalf("expected keyFiles entry %q to be rejected", name)
+		}
+	}
+}
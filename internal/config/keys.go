@@ -0,0 +1,235 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/jsoncheck"
+)
+
+// Setting is a flattened config entry such as "models.0.name".
+type Setting struct {
+	Key   string
+	Value any
+}
+
+// ErrKeyNotSet indicates that a config key has no value.
+var ErrKeyNotSet = errors.New("config key not set")
+
+// splitKey accepts "models.0.name" and "models[0].name".
+func splitKey(key string) ([]string, error) {
+	key = strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimSpace(key))
+	if key == "" {
+		return nil, errors.New("config key is required")
+	}
+	parts := strings.Split(key, ".")
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("invalid config key %q", key)
+		}
+	}
+	return parts, nil
+}
+
+func toTree(cfg Config) (map[string]any, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	tree := map[string]any{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	return tree, nil
+}
+
+// Get returns the value stored at key.
+func Get(cfg Config, key string) (any, error) {
+	parts, err := splitKey(key)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := toTree(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var current any = tree
+	for _, part := range parts {
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrKeyNotSet, key)
+			}
+			current = next
+		case []any:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("%w: %s", ErrKeyNotSet, key)
+			}
+			current = node[idx]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrKeyNotSet, key)
+		}
+	}
+	return current, nil
+}
+
+// Set returns a copy of cfg with key set to raw. Raw is parsed as JSON when possible
+// (numbers, booleans, arrays, objects, null) and otherwise used as a string; "null" removes the key.
+func Set(cfg Config, key, raw string) (Config, error) {
+	parts, err := splitKey(key)
+	if err != nil {
+		return Config{}, err
+	}
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	updated, err := setValue(cfg, parts, value)
+	if err != nil {
+		if _, isString := value.(string); !isString && value != nil {
+			if retried, retryErr := setValue(cfg, parts, raw); retryErr == nil {
+				return retried, nil
+			}
+		}
+		return Config{}, fmt.Errorf("set %s: %w", key, err)
+	}
+	return updated, nil
+}
+
+func setValue(cfg Config, parts []string, value any) (Config, error) {
+	tree, err := toTree(cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	root, err := assign(tree, parts, value)
+	if err != nil {
+		return Config{}, err
+	}
+	data, err := json.Marshal(root)
+	if err != nil {
+		return Config{}, fmt.Errorf("marshal config: %w", err)
+	}
+	issues, err := jsoncheck.Check(data, Config{})
+	if err != nil {
+		return Config{}, err
+	}
+	if len(issues) > 0 {
+		return Config{}, fmt.Errorf("%s: %s", issues[0].Path, issues[0].Message)
+	}
+	var out Config
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Config{}, err
+	}
+	return out, nil
+}
+
+func assign(node any, parts []string, value any) (any, error) {
+	if len(parts) == 0 {
+		return value, nil
+	}
+	part, rest := parts[0], parts[1:]
+	switch n := node.(type) {
+	case nil:
+		if _, err := strconv.Atoi(part); err == nil {
+			return assign([]any{}, parts, value)
+		}
+		return assign(map[string]any{}, parts, value)
+	case map[string]any:
+		if len(rest) == 0 && value == nil {
+			delete(n, part)
+			return n, nil
+		}
+		child, err := assign(n[part], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[part] = child
+		return n, nil
+	case []any:
+		idx, err := strconv.Atoi(part)
+		if err != nil || idx < 0 || idx > len(n) {
+			return nil, fmt.Errorf("index %q out of range (have %d entries)", part, len(n))
+		}
+		if len(rest) == 0 && value == nil {
+			if idx == len(n) {
+				return n, nil
+			}
+			return append(n[:idx], n[idx+1:]...), nil
+		}
+		var existing any
+		if idx < len(n) {
+			existing = n[idx]
+		}
+		child, err := assign(existing, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		if idx == len(n) {
+			return append(n, child), nil
+		}
+		n[idx] = child
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot set %q inside a %T value", part, node)
+	}
+}
+
+// List flattens every configured value into sorted dotted keys.
+func List(cfg Config) ([]Setting, error) {
+	tree, err := toTree(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var settings []Setting
+	flatten("", tree, &settings)
+	sort.SliceStable(settings, func(i, j int) bool { return keyLess(settings[i].Key, settings[j].Key) })
+	return settings, nil
+}
+
+// keyLess orders dotted keys segment by segment, comparing array indices numerically.
+func keyLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		ai, aErr := strconv.Atoi(as[i])
+		bi, bErr := strconv.Atoi(bs[i])
+		if aErr == nil && bErr == nil {
+			return ai < bi
+		}
+		return as[i] < bs[i]
+	}
+	return len(as) < len(bs)
+}
+
+func flatten(prefix string, node any, out *[]Setting) {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			flatten(joinKey(prefix, key), child, out)
+		}
+	case []any:
+		if len(n) == 0 {
+			*out = append(*out, Setting{Key: prefix, Value: n})
+		}
+		for i, child := range n {
+			flatten(joinKey(prefix, strconv.Itoa(i)), child, out)
+		}
+	default:
+		*out = append(*out, Setting{Key: prefix, Value: n})
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestSetAndGetNestedKeys(t *testing.T) {
+	cfg := config.Config{}
+	var err error
+	for _, step := range [][2]string{
+		{"toolCallMode", "auto"},
+		{"models[0].name", "gpt-4o"},
+		{"models.0.provider", "openai"},
+		{"models.0.seed", "42"},
+		{"models.1", `{"name": "llama3", "provider": "ollama"}`},
+		{"aliases.rev", "Review this"},
+		{"personas.0.name", "123"},
+	} {
+		cfg, err = config.Set(cfg, step[0], step[1])
+		if err != nil {
+			t.Fatalf("Set(%s) error = %v", step[0], err)
+		}
+	}
+
+	if cfg.ToolCallMode != "auto" || len(cfg.Models) != 2 || cfg.Models[1].Provider != "ollama" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if cfg.Models[0].Seed == nil || *cfg.Models[0].Seed != 42 {
+		t.Fatalf("expected numeric seed, got %v", cfg.Models[0].Seed)
+	}
+	if cfg.Personas[0].Name != "123" {
+		t.Fatalf("expected numeric-looking string to stay a string, got %q", cfg.Personas[0].Name)
+	}
+	if got, err := config.Get(cfg, "models.0.name"); err != nil || got != "gpt-4o" {
+		t.Fatalf("Get() = %v, %v", got, err)
+	}
+	if _, err := config.Get(cfg, "models.5.name"); !errors.Is(err, config.ErrKeyNotSet) {
+		t.Fatalf("expected ErrKeyNotSet, got %v", err)
+	}
+
+	cfg, err = config.Set(cfg, "models.0", "null")
+	if err != nil || len(cfg.Models) != 1 || cfg.Models[0].Name != "llama3" {
+		t.Fatalf("expected null to remove the entry, got %+v (%v)", cfg.Models, err)
+	}
+}
+
+func TestSetRejectsUnknownKeysAndWrongTypes(t *testing.T) {
+	for key, value := range map[string]string{
+		"toolCalMode":         "auto",
+		"models.0.active":     "sometimes",
+		"models.3.name":       "gap",
+		"toolCallMode.nested": "x",
+		"models..name":        "x",
+	} {
+		if _, err := config.Set(config.Config{}, key, value); err == nil {
+			t.Fatalf("Set(%s=%s) expected error", key, value)
+		}
+	}
+}
+
+func TestListFlattensSortedKeys(t *testing.T) {
+	cfg := config.Config{ToolCallMode: "auto"}
+	for i := 0; i < 11; i++ {
+		cfg.Models = append(cfg.Models, config.Model{Name: "m", Provider: "ollama"})
+	}
+	settings, err := config.List(cfg)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var keys []string
+	for _, s := range settings {
+		keys = append(keys, s.Key)
+	}
+	if keys[0] != "models.0.name" || keys[2] != "models.1.name" || keys[len(keys)-1] != "toolCallMode" {
+		t.Fatalf("unexpected key order %v", keys)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ProfileEnv names the environment variable that selects a configuration profile; the
+// --profile flag sets it for the process and everything it starts.
+const ProfileEnv = "HUMBLE_AI_PROFILE"
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ActiveProfile returns the selected profile, or "" for the default configuration.
+func ActiveProfile() string {
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
+
+// Dir returns the directory holding config.json, mcp-servers.json, sessions and logs:
+// ~/.humble-ai-cli, or ~/.humble-ai-cli/profiles/<name> when a profile is selected.
+func Dir(home string) string {
+	base := filepath.Join(home, ".humble-ai-cli")
+	if name := ActiveProfile(); name != "" && CheckProfileName(name) == nil {
+		return filepath.Join(base, "profiles", name)
+	}
+	return base
+}
+
+// CheckProfileName reports names that cannot be used as a profile directory.
+func CheckProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, '.', '_' or '-')", name)
+	}
+	return nil
+}
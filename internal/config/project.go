@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gamzabox/humble-ai-cli/internal/jsoncheck"
+	"github.com/gamzabox/humble-ai-cli/internal/workspace"
+)
+
+// ProjectFileName is the project-local overlay read from the project root.
+const ProjectFileName = ".humble-ai-cli.json"
+
+// ProjectOverlay holds settings a project can override on top of config.json.
+type ProjectOverlay struct {
+	WorkspaceContext *WorkspaceContext `json:"workspaceContext,omitempty"`
+}
+
+// LoadProjectOverlay reads the overlay in root. A missing file yields an empty overlay.
+func LoadProjectOverlay(root string) (ProjectOverlay, error) {
+	path := filepath.Join(root, ProjectFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ProjectOverlay{}, nil
+	}
+	if err != nil {
+		return ProjectOverlay{}, fmt.Errorf("read project config: %w", err)
+	}
+	var overlay ProjectOverlay
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return ProjectOverlay{}, fmt.Errorf("parse %s: %w", path, jsoncheck.Annotate(data, err))
+	}
+	if overlay.WorkspaceContext != nil {
+		if err := overlay.WorkspaceContext.Validate(); err != nil {
+			return ProjectOverlay{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return overlay, nil
+}
+
+// Apply returns cfg with the overlay's settings taking precedence. A project can only
+// narrow workspaceContext: it cannot enable it or raise a limit beyond config.json.
+func (o ProjectOverlay) Apply(cfg Config) Config {
+	if o.WorkspaceContext != nil {
+		user := cfg.WorkspaceContext
+		project := *o.WorkspaceContext
+		project.Enabled = project.Enabled && user.Enabled
+		project.MaxDepth = capLimit(project.MaxDepth, user.MaxDepth, workspace.DefaultMaxDepth)
+		project.MaxEntries = capLimit(project.MaxEntries, user.MaxEntries, workspace.DefaultMaxEntries)
+		project.HeaderLines = capLimit(project.HeaderLines, user.HeaderLines, workspace.DefaultHeaderLines)
+		cfg.WorkspaceContext = project
+	}
+	return cfg
+}
+
+// capLimit keeps a project limit at or below the user's, where zero means def.
+func capLimit(project, user, def int) int {
+	if user == 0 {
+		user = def
+	}
+	if project == 0 || project > user {
+		return user
+	}
+	return project
+}
+
+// Validate rejects negative limits and key files outside the project.
+func (w WorkspaceContext) Validate() error {
+	if w.MaxDepth < 0 || w.MaxEntries < 0 || w.HeaderLines < 0 {
+		return fmt.Errorf("workspaceContext limits must not be negative")
+	}
+	for _, name := range w.KeyFiles {
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("workspaceContext keyFiles entry %q must be a relative path inside the project", name)
+		}
+	}
+	return nil
+}
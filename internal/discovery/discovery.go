@@ -0,0 +1,132 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const probeTimeout = 2 * time.Second
+
+// HTTPClient abstracts http.Client for testability.
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Endpoint is a local model server that may be running.
+type Endpoint struct {
+	// Name is a human readable label such as "Ollama".
+	Name string
+	// Provider is the config provider used for models found at this endpoint.
+	Provider string
+	// BaseURL is written to config.Model.BaseURL for discovered models.
+	BaseURL string
+	// APIKey is a placeholder key for servers that ignore authentication.
+	APIKey string
+}
+
+// Result captures the models reported by a single endpoint.
+type Result struct {
+	Endpoint Endpoint
+	Models   []string
+	Err      error
+}
+
+// DefaultEndpoints returns the local servers probed by /discover.
+func DefaultEndpoints() []Endpoint {
+	return []Endpoint{
+		{Name: "Ollama", Provider: "ollama", BaseURL: "http://localhost:11434"},
+		{Name: "LM Studio", Provider: "openai", BaseURL: "http://localhost:1234/v1", APIKey: "lm-studio"},
+	}
+}
+
+// Prober queries endpoints for their available models.
+type Prober struct {
+	client    HTTPClient
+	endpoints []Endpoint
+}
+
+// NewProber builds a Prober; a nil client uses a short-timeout http.Client.
+func NewProber(client HTTPClient, endpoints []Endpoint) *Prober {
+	if client == nil {
+		client = &http.Client{Timeout: probeTimeout}
+	}
+	return &Prober{client: client, endpoints: endpoints}
+}
+
+// Probe queries all endpoints concurrently and returns results in endpoint order.
+func (p *Prober) Probe(ctx context.Context) []Result {
+	results := make([]Result, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			models, err := p.listModels(ctx, endpoint)
+			results[i] = Result{Endpoint: endpoint, Models: models, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (p *Prober) listModels(ctx context.Context, endpoint Endpoint) ([]string, error) {
+	base := strings.TrimRight(endpoint.BaseURL, "/")
+	if endpoint.Provider == "ollama" {
+		var body struct {
+			Models []struct {
+				Name string `json:"name"`
+			} `json:"models"`
+		}
+		if err := p.getJSON(ctx, base+"/api/tags", &body); err != nil {
+			return nil, err
+		}
+		models := make([]string, 0, len(body.Models))
+		for _, m := range body.Models {
+			models = append(models, m.Name)
+		}
+		return models, nil
+	}
+
+	var body struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := p.getJSON(ctx, base+"/models", &body); err != nil {
+		return nil, err
+	}
+	models := make([]string, 0, len(body.Data))
+	for _, m := range body.Data {
+		models = append(models, m.ID)
+	}
+	return models, nil
+}
+
+func (p *Prober) getJSON(ctx context.Context, url string, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
+}
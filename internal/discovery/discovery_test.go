@@ -0,0 +1,51 @@
+package discovery_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/discovery"
+)
+
+func TestProberListsOllamaAndOpenAICompatibleModels(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected ollama path: %s", r.URL.Path)
+		}
+		io.WriteString(w, `{"models":[{"name":"llama3:8b"},{"name":"qwen2.5:7b"}]}`)
+	}))
+	defer ollama.Close()
+
+	lmstudio := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("unexpected lm studio path: %s", r.URL.Path)
+		}
+		io.WriteString(w, `{"data":[{"id":"mistral-7b-instruct"}]}`)
+	}))
+	defer lmstudio.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	prober := discovery.NewProber(nil, []discovery.Endpoint{
+		{Name: "Ollama", Provider: "ollama", BaseURL: ollama.URL},
+		{Name: "LM Studio", Provider: "openai", BaseURL: lmstudio.URL + "/v1"},
+		{Name: "Offline", Provider: "ollama", BaseURL: down.URL},
+	})
+	results := prober.Probe(context.Background())
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Err != nil || len(results[0].Models) != 2 || results[0].Models[0] != "llama3:8b" {
+		t.Fatalf("unexpected ollama result: %#v", results[0])
+	}
+	if results[1].Err != nil || len(results[1].Models) != 1 || results[1].Models[0] != "mistral-7b-instruct" {
+		t.Fatalf("unexpected lm studio result: %#v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Fatalf("expected error for offline endpoint")
+	}
+}
@@ -0,0 +1,127 @@
+// Package gitsync commits session files to a git repository so transcripts are versioned
+// and can be synced between machines through an ordinary git remote.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo is a git work tree that receives session files.
+type Repo struct {
+	// Dir is the work tree; it is created and initialized on first use.
+	Dir string
+	// Remote, when set, is pushed to after every commit.
+	Remote string
+	// Branch is the remote branch to push to; empty pushes the current branch.
+	Branch string
+}
+
+// Sync copies the session file at path into the repository, commits it when it changed
+// and pushes the commit when a remote is configured. A file already inside the work tree
+// is committed in place.
+func (r Repo) Sync(ctx context.Context, path string) error {
+	if err := r.ensure(ctx); err != nil {
+		return err
+	}
+	name, err := r.place(path)
+	if err != nil {
+		return err
+	}
+	if _, err := r.git(ctx, "add", "--", name); err != nil {
+		return err
+	}
+	if _, err := r.git(ctx, "diff", "--cached", "--quiet", "--", name); err == nil {
+		return nil
+	}
+	if _, err := r.git(ctx, "commit", "--quiet", "-m", "Update "+filepath.ToSlash(name), "--", name); err != nil {
+		return err
+	}
+	if r.Remote == "" {
+		return nil
+	}
+	ref := "HEAD"
+	if r.Branch != "" {
+		ref = "HEAD:" + r.Branch
+	}
+	_, err = r.git(ctx, "push", "--quiet", r.Remote, ref)
+	return err
+}
+
+// ensure creates the work tree, running git init when it is not the top of a repository
+// yet. A Dir nested inside another work tree gets its own repository so sessions are
+// never committed to, or pushed from, the outer one.
+func (r Repo) ensure(ctx context.Context) error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", r.Dir, err)
+	}
+	if r.isTopLevel(ctx) {
+		return nil
+	}
+	_, err := r.git(ctx, "init", "--quiet")
+	return err
+}
+
+// isTopLevel reports whether Dir is the root of its own work tree.
+func (r Repo) isTopLevel(ctx context.Context) bool {
+	out, err := r.git(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false
+	}
+	top, err := filepath.EvalSymlinks(filepath.FromSlash(strings.TrimSpace(out)))
+	if err != nil {
+		return false
+	}
+	dir, err := filepath.EvalSymlinks(r.Dir)
+	if err != nil {
+		return false
+	}
+	dir, err = filepath.Abs(dir)
+	return err == nil && dir == top
+}
+
+// place returns the path of the session file relative to the work tree, copying it in
+// when it lives elsewhere.
+func (r Repo) place(path string) (string, error) {
+	dir, err := filepath.Abs(r.Dir)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel, nil
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return "", fmt.Errorf("read session: %w", err)
+	}
+	name := filepath.Base(abs)
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		return "", fmt.Errorf("copy session: %w", err)
+	}
+	return name, nil
+}
+
+func (r Repo) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.Dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); msg != "" && errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
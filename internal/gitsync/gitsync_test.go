@@ -0,0 +1,114 @@
+package gitsync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolateGit gives git a fixed identity and keeps the user's config out of the test.
+func isolateGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Tester")
+	t.Setenv("GIT_AUTHOR_EMAIL", "tester@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Tester")
+	t.Setenv("GIT_COMMITTER_EMAIL", "tester@example.com")
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeSession(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+}
+
+func TestSyncCommitsChangedSessionsOnly(t *testing.T) {
+	isolateGit(t)
+	session := filepath.Join(t.TempDir(), "20250101_120000_hello.json")
+	repo := Repo{Dir: filepath.Join(t.TempDir(), "history")}
+	ctx := context.Background()
+
+	writeSession(t, session, `{"messages":[1]}`)
+	if err := repo.Sync(ctx, session); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if err := repo.Sync(ctx, session); err != nil {
+		t.Fatalf("Sync() without changes error = %v", err)
+	}
+	writeSession(t, session, `{"messages":[1,2]}`)
+	if err := repo.Sync(ctx, session); err != nil {
+		t.Fatalf("Sync() after a change error = %v", err)
+	}
+
+	log := gitOutput(t, repo.Dir, "log", "--format=%s")
+	if log != "Update 20250101_120000_hello.json\nUpdate 20250101_120000_hello.json" {
+		t.Fatalf("expected two commits, got:\n%s", log)
+	}
+	if got := gitOutput(t, repo.Dir, "show", "HEAD:20250101_120000_hello.json"); got != `{"messages":[1,2]}` {
+		t.Fatalf("expected the latest session committed, got %q", got)
+	}
+}
+
+func TestSyncCommitsInPlaceAndPushes(t *testing.T) {
+	isolateGit(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "--quiet")
+	gitOutput(t, dir, "remote", "add", "origin", remote)
+	if err := os.MkdirAll(filepath.Join(dir, "sessions"), 0o755); err != nil {
+		t.Fatalf("failed to create sessions dir: %v", err)
+	}
+	session := filepath.Join(dir, "sessions", "chat.json")
+	writeSession(t, session, `{}`)
+
+	repo := Repo{Dir: dir, Remote: "origin", Branch: "history"}
+	if err := repo.Sync(context.Background(), session); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := gitOutput(t, remote, "log", "--format=%s", "history"); got != "Update sessions/chat.json" {
+		t.Fatalf("expected the commit pushed to history, got %q", got)
+	}
+}
+
+func TestSyncKeepsNestedDirOutOfOuterRepo(t *testing.T) {
+	isolateGit(t)
+	outer := t.TempDir()
+	gitOutput(t, outer, "init", "--quiet")
+	writeSession(t, filepath.Join(outer, "notes.md"), "notes")
+	gitOutput(t, outer, "add", "notes.md")
+	gitOutput(t, outer, "commit", "--quiet", "-m", "Add notes")
+
+	repo := Repo{Dir: filepath.Join(outer, "history")}
+	session := filepath.Join(t.TempDir(), "chat.json")
+	writeSession(t, session, `{}`)
+	if err := repo.Sync(context.Background(), session); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if got := gitOutput(t, outer, "log", "--format=%s"); got != "Add notes" {
+		t.Fatalf("expected the outer repo untouched, got:\n%s", got)
+	}
+	if got := gitOutput(t, repo.Dir, "log", "--format=%s"); got != "Update chat.json" {
+		t.Fatalf("expected the session committed in its own repo, got:\n%s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DatasetOptions controls how a session is converted into a chat dataset example.
+type DatasetOptions struct {
+	// StripToolCalls drops the assistant tool_calls and tool result messages.
+	StripToolCalls bool
+	// StripThinking removes inline <think>…</think> reasoning from assistant content.
+	StripThinking bool
+	// SystemPrompt is prepended as a system message when non-empty.
+	SystemPrompt string
+}
+
+// DatasetMessage is a single message in an OpenAI-style chat dataset example.
+type DatasetMessage struct {
+	Role       string            `json:"role"`
+	Content    string            `json:"content,omitempty"`
+	ToolCalls  []DatasetToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+}
+
+// DatasetToolCall mirrors the OpenAI tool_calls entry.
+type DatasetToolCall struct {
+	ID       string              `json:"id"`
+	Type     string              `json:"type"`
+	Function DatasetToolFunction `json:"function"`
+}
+
+// DatasetToolFunction carries the function name and JSON-encoded arguments.
+type DatasetToolFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// DatasetExample is one JSONL line of an OpenAI-style chat dataset.
+type DatasetExample struct {
+	Messages []DatasetMessage `json:"messages"`
+}
+
+var thinkBlockPattern = regexp.MustCompile(`(?s)<think(?:ing)?>.*?</think(?:ing)?>`)
+
+// ToDataset converts a session into a dataset example.
+func ToDataset(session Session, opts DatasetOptions) DatasetExample {
+	var out []DatasetMessage
+	if prompt := strings.TrimSpace(opts.SystemPrompt); prompt != "" {
+		out = append(out, DatasetMessage{Role: "system", Content: prompt})
+	}
+
+	callSeq := 0
+	for _, msg := range session.Messages {
+		if msg.Role != "assistant" {
+			out = append(out, DatasetMessage{Role: msg.Role, Content: msg.Content})
+			continue
+		}
+
+		if !opts.StripToolCalls && len(msg.ToolCalls) > 0 {
+			for _, call := range msg.ToolCalls {
+				callSeq++
+				id := fmt.Sprintf("call_%d", callSeq)
+				out = append(out,
+					DatasetMessage{
+						Role: "assistant",
+						ToolCalls: []DatasetToolCall{{
+							ID:   id,
+							Type: "function",
+							Function: DatasetToolFunction{
+								Name:      call.Server + "__" + call.Method,
+								Arguments: encodeArguments(call.Arguments),
+							},
+						}},
+					},
+					DatasetMessage{Role: "tool", ToolCallID: id, Content: call.Result},
+				)
+			}
+		}
+
+		content := msg.Content
+		if opts.StripThinking {
+			content = strings.TrimSpace(thinkBlockPattern.ReplaceAllString(content, ""))
+		}
+		out = append(out, DatasetMessage{Role: "assistant", Content: content})
+	}
+	return DatasetExample{Messages: out}
+}
+
+func encodeArguments(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,58 @@
+package history_test
+
+import (
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func TestToDatasetExpandsToolCalls(t *testing.T) {
+	session := history.Session{
+		Messages: []history.Message{
+			{Role: "user", Content: "add 2 and 3"},
+			{
+				Role:    "assistant",
+				Content: "<think>need the calculator</think>The sum is 5.",
+				ToolCalls: []history.ToolCall{{
+					Server:    "calculator",
+					Method:    "add",
+					Arguments: map[string]any{"a": 2, "b": 3},
+					Result:    "5",
+				}},
+			},
+		},
+	}
+
+	example := history.ToDataset(session, history.DatasetOptions{SystemPrompt: "Be brief."})
+	roles := make([]string, 0, len(example.Messages))
+	for _, msg := range example.Messages {
+		roles = append(roles, msg.Role)
+	}
+	want := []string{"system", "user", "assistant", "tool", "assistant"}
+	if len(roles) != len(want) {
+		t.Fatalf("unexpected roles %v", roles)
+	}
+	for i := range want {
+		if roles[i] != want[i] {
+			t.Fatalf("unexpected roles %v", roles)
+		}
+	}
+	call := example.Messages[2].ToolCalls[0]
+	if call.Function.Name != "calculator__add" || call.Function.Arguments != `{"a":2,"b":3}` {
+		t.Fatalf("unexpected tool call %+v", call)
+	}
+	if example.Messages[3].ToolCallID != call.ID || example.Messages[3].Content != "5" {
+		t.Fatalf("unexpected tool message %+v", example.Messages[3])
+	}
+	if example.Messages[4].Content != "<think>need the calculator</think>The sum is 5." {
+		t.Fatalf("expected thinking to be kept by default, got %q", example.Messages[4].Content)
+	}
+
+	stripped := history.ToDataset(session, history.DatasetOptions{StripToolCalls: true, StripThinking: true})
+	if len(stripped.Messages) != 2 {
+		t.Fatalf("expected tool messages to be stripped, got %+v", stripped.Messages)
+	}
+	if stripped.Messages[1].Content != "The sum is 5." {
+		t.Fatalf("expected thinking to be stripped, got %q", stripped.Messages[1].Content)
+	}
+}
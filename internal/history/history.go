@@ -0,0 +1,244 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// ErrNotFound indicates that no session file matched the requested reference.
+var ErrNotFound = errors.New("session not found")
+
+// ToolCall records a single MCP invocation made while producing an assistant message.
+type ToolCall struct {
+	Server    string         `json:"server"`
+	Method    string         `json:"method"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Result    string         `json:"result,omitempty"`
+	IsError   bool           `json:"isError,omitempty"`
+}
+
+// Message is a conversation turn stored in a session file.
+type Message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Timestamp time.Time  `json:"timestamp,omitzero"`
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
+	// Thinking is the reasoning streamed before the answer, kept only when saveThinking is set.
+	Thinking string `json:"thinking,omitempty"`
+	// Metrics describes how an assistant answer was produced.
+	Metrics *TurnMetrics `json:"metrics,omitempty"`
+	// Rating is the user's /rate feedback on an assistant answer.
+	Rating *Rating `json:"rating,omitempty"`
+}
+
+// Rating is a 1-5 score with an optional comment, collected for evaluating models and prompts.
+type Rating struct {
+	Score   int       `json:"score"`
+	Comment string    `json:"comment,omitempty"`
+	RatedAt time.Time `json:"ratedAt,omitzero"`
+}
+
+// TurnMetrics records latency, size and tool statistics of one turn, so many sessions can
+// be analysed offline. Token counts are estimates from the model's tokenizer.
+type TurnMetrics struct {
+	FirstTokenMillis int64 `json:"firstTokenMs,omitempty"`
+	TotalMillis      int64 `json:"totalMs"`
+	ThinkingMillis   int64 `json:"thinkingMs,omitempty"`
+	// RoundTrips counts provider requests, including tool result follow-ups and retries.
+	RoundTrips int `json:"roundTrips"`
+	// ProviderRetries counts requests sent again without new tool results: token limit
+	// continuations and tool call repairs.
+	ProviderRetries  int          `json:"providerRetries,omitempty"`
+	PromptTokens     int          `json:"promptTokens"`
+	CompletionTokens int          `json:"completionTokens"`
+	Tokenizer        string       `json:"tokenizer,omitempty"`
+	Tools            []ToolMetric `json:"tools,omitempty"`
+}
+
+// ToolMetric is the outcome of one tool call within a turn.
+type ToolMetric struct {
+	Server         string `json:"server"`
+	Method         string `json:"method"`
+	DurationMillis int64  `json:"durationMs"`
+	// Retries counts same-argument repeats made by the toolPolicy retry policy.
+	Retries int  `json:"retries,omitempty"`
+	IsError bool `json:"isError,omitempty"`
+}
+
+// Session is the JSON document persisted for each conversation.
+type Session struct {
+	Model     string    `json:"model"`
+	Persona   string    `json:"persona,omitempty"`
+	Seed      *int64    `json:"seed,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	Tags      []string  `json:"tags,omitempty"`
+	Notes     []string  `json:"notes,omitempty"`
+	// SystemPrompt and ToolPrompt pin the prompts the latest turn was sent with, so a
+	// transcript stays reproducible after system_prompt.txt or the MCP tools change.
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	ToolPrompt   string `json:"toolPrompt,omitempty"`
+	// Bookmarks are named positions in Messages set with /bookmark.
+	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
+	Messages  []Message  `json:"messages"`
+	// Errors lists the turns that failed at the provider; they leave no messages behind.
+	Errors []TurnError `json:"errors,omitempty"`
+}
+
+// TurnError records a provider failure with its category, such as "auth" or "network".
+type TurnError struct {
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	Model     string    `json:"model"`
+	Category  string    `json:"category"`
+	Message   string    `json:"message"`
+}
+
+// Bookmark names the point in a session after its first Position messages.
+type Bookmark struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// HasTag reports whether the session carries the given tag (case-insensitive).
+func (s Session) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstUserMessage returns the opening user prompt, used as a session title.
+func (s Session) FirstUserMessage() string {
+	for _, msg := range s.Messages {
+		if msg.Role == "user" {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+// LLMMessages converts stored messages into provider-visible context messages.
+// Assistant turns that used tools are expanded into the tool call request and
+// "tool" result messages the model saw, followed by the final answer.
+func LLMMessages(messages []Message) []llm.Message {
+	if len(messages) == 0 {
+		return nil
+	}
+	out := make([]llm.Message, 0, len(messages))
+	for i, msg := range messages {
+		if msg.Role == "assistant" && len(msg.ToolCalls) > 0 {
+			out = append(out, toolCallMessages(i, msg.ToolCalls)...)
+		}
+		out = append(out, llm.Message{Role: msg.Role, Content: msg.Content})
+	}
+	return out
+}
+
+func toolCallMessages(turn int, calls []ToolCall) []llm.Message {
+	request := llm.Message{Role: "assistant"}
+	results := make([]llm.Message, 0, len(calls))
+	for j, call := range calls {
+		replayed := llm.MessageToolCall{
+			ID:        fmt.Sprintf("call_%d_%d", turn, j),
+			Server:    call.Server,
+			Method:    call.Method,
+			Arguments: call.Arguments,
+		}
+		request.ToolCalls = append(request.ToolCalls, replayed)
+		content := strings.TrimSpace(call.Result)
+		if content == "" {
+			content = "{}"
+		}
+		results = append(results, llm.Message{
+			Role:       "tool",
+			Content:    content,
+			ToolCallID: replayed.ID,
+			ToolName:   replayed.Name(),
+		})
+	}
+	return append([]llm.Message{request}, results...)
+}
+
+// Load reads a session file from disk.
+func Load(path string) (Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Session{}, fmt.Errorf("read session: %w", err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return Session{}, fmt.Errorf("parse session %s: %w", filepath.Base(path), err)
+	}
+	return session, nil
+}
+
+// Save writes a session file to disk.
+func Save(path string, session Session) error {
+	if session.Messages == nil {
+		session.Messages = []Message{}
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal history: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	return nil
+}
+
+// List returns the session file paths under root, newest first.
+func List(root string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(root, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	return paths, nil
+}
+
+// Resolve locates a session file by path, file name, or unique file name prefix under root.
+func Resolve(root, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", errors.New("session reference is required")
+	}
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		return ref, nil
+	}
+
+	for _, candidate := range []string{ref, ref + ".json"} {
+		path := filepath.Join(root, candidate)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+
+	paths, err := List(root)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, path := range paths {
+		if strings.HasPrefix(filepath.Base(path), ref) {
+			matches = append(matches, path)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session reference %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
@@ -0,0 +1,116 @@
+package history_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+func TestSaveAndLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := history.Session{
+		Model:     "gpt-4o",
+		StartedAt: started,
+		Messages: []history.Message{
+			{Role: "user", Content: "add", Timestamp: started},
+			{
+				Role:      "assistant",
+				Content:   "3",
+				Timestamp: started.Add(time.Second),
+				ToolCalls: []history.ToolCall{{Server: "calc", Method: "add", Result: "3"}},
+			},
+		},
+	}
+	if err := history.Save(path, input); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := history.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !got.StartedAt.Equal(started) || got.Model != "gpt-4o" {
+		t.Fatalf("unexpected session header: %+v", got)
+	}
+	if len(got.Messages) != 2 || len(got.Messages[1].ToolCalls) != 1 {
+		t.Fatalf("unexpected messages: %+v", got.Messages)
+	}
+	if llmMessages := history.LLMMessages(got.Messages); llmMessages[len(llmMessages)-1].Content != "3" {
+		t.Fatalf("unexpected llm conversion: %+v", llmMessages)
+	}
+}
+
+func TestLLMMessagesReconstructsToolCalls(t *testing.T) {
+	messages := []history.Message{
+		{Role: "user", Content: "add 1 and 2"},
+		{
+			Role:    "assistant",
+			Content: "The sum is 3.",
+			ToolCalls: []history.ToolCall{
+				{Server: "calc", Method: "add", Arguments: map[string]any{"a": 1.0, "b": 2.0}, Result: "3"},
+				{Server: "calc", Method: "log", Result: ""},
+			},
+		},
+	}
+
+	got := history.LLMMessages(messages)
+	if len(got) != 5 {
+		t.Fatalf("expected user, tool request, two results and answer, got %+v", got)
+	}
+	request := got[1]
+	if request.Role != "assistant" || request.Content != "" || len(request.ToolCalls) != 2 {
+		t.Fatalf("unexpected tool request message: %+v", request)
+	}
+	if request.ToolCalls[0].Name() != "calc__add" || request.ToolCalls[0].Arguments["b"] != 2.0 {
+		t.Fatalf("unexpected tool call: %+v", request.ToolCalls[0])
+	}
+	for i, result := range got[2:4] {
+		if result.Role != "tool" || result.ToolCallID != request.ToolCalls[i].ID {
+			t.Fatalf("tool result %d not linked to its call: %+v", i, result)
+		}
+	}
+	if got[2].Content != "3" || got[3].Content != "{}" || got[3].ToolName != "calc__log" {
+		t.Fatalf("unexpected tool results: %+v", got[2:4])
+	}
+	if got[4].Role != "assistant" || got[4].Content != "The sum is 3." {
+		t.Fatalf("expected final answer last, got %+v", got[4])
+	}
+}
+
+func TestResolveFindsSessionsByNameAndPrefix(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"20250101_100000_hello.json", "20250102_100000_world.json"} {
+		if err := history.Save(filepath.Join(root, name), history.Session{Model: "m"}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "20250101_100000_hello.json", want: "20250101_100000_hello.json"},
+		{ref: "20250102_100000_world", want: "20250102_100000_world.json"},
+		{ref: "20250102", want: "20250102_100000_world.json"},
+	}
+	for _, tt := range tests {
+		got, err := history.Resolve(root, tt.ref)
+		if err != nil {
+			t.Fatalf("Resolve(%q) error = %v", tt.ref, err)
+		}
+		if filepath.Base(got) != tt.want {
+			t.Fatalf("Resolve(%q) = %s, want %s", tt.ref, got, tt.want)
+		}
+	}
+
+	if _, err := history.Resolve(root, "2025"); err == nil {
+		t.Fatalf("expected ambiguous prefix to fail")
+	}
+	if _, err := history.Resolve(root, "missing"); !errors.Is(err, history.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
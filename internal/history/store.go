@@ -0,0 +1,131 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Store persists sessions by file name, such as "20240102_150405_title.json".
+type Store interface {
+	Load(name string) (Session, error)
+	Save(name string, session Session) error
+	// List returns the stored session names, newest first.
+	List() ([]string, error)
+}
+
+// FileStore keeps session files in a local directory; it is the default store.
+type FileStore struct {
+	Root string
+}
+
+// Path returns the local file a session name is stored in.
+func (s FileStore) Path(name string) string {
+	return filepath.Join(s.Root, name)
+}
+
+// Load reads the named session, returning an error wrapping ErrNotFound when it does not exist.
+func (s FileStore) Load(name string) (Session, error) {
+	if err := checkName(name); err != nil {
+		return Session{}, err
+	}
+	session, err := Load(s.Path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Session{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return session, err
+}
+
+// Save writes the named session, creating the directory when needed.
+func (s FileStore) Save(name string, session Session) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Root, 0o755); err != nil {
+		return fmt.Errorf("create history dir: %w", err)
+	}
+	return Save(s.Path(name), session)
+}
+
+// List returns the names of the session files in the directory, newest first.
+func (s FileStore) List() ([]string, error) {
+	paths, err := List(s.Root)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names, nil
+}
+
+// MirrorStore keeps the local files as the working copy and mirrors every save to a remote
+// store, so sessions written on one machine can be listed and resumed on another.
+type MirrorStore struct {
+	Local  FileStore
+	Remote Store
+}
+
+// Load reads the local copy, downloading and caching the session when only the remote has it.
+func (s MirrorStore) Load(name string) (Session, error) {
+	session, err := s.Local.Load(name)
+	if !errors.Is(err, ErrNotFound) {
+		return session, err
+	}
+	session, err = s.Remote.Load(name)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := s.Local.Save(name, session); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// Save writes the local copy first, so a failing remote never loses the session.
+func (s MirrorStore) Save(name string, session Session) error {
+	if err := s.Local.Save(name, session); err != nil {
+		return err
+	}
+	if err := s.Remote.Save(name, session); err != nil {
+		return fmt.Errorf("remote store: %w", err)
+	}
+	return nil
+}
+
+// List merges the local and remote names. When the remote cannot be listed it still
+// returns the local names together with the error.
+func (s MirrorStore) List() ([]string, error) {
+	names, err := s.Local.List()
+	if err != nil {
+		return nil, err
+	}
+	remote, remoteErr := s.Remote.List()
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, name := range remote {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	if remoteErr != nil {
+		return names, fmt.Errorf("remote store: %w", remoteErr)
+	}
+	return names, nil
+}
+
+// checkName rejects names that would escape the store, such as "../x.json".
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		return fmt.Errorf("invalid session name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package history_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+// davServer is an in-memory WebDAV server supporting the methods WebDAVStore uses.
+type davServer struct {
+	mu          sync.Mutex
+	files       map[string][]byte
+	collections map[string]bool
+}
+
+func newDAVServer(t *testing.T) (*httptest.Server, *davServer) {
+	t.Helper()
+	dav := &davServer{files: map[string][]byte{}, collections: map[string]bool{"/": true}}
+	server := httptest.NewServer(dav)
+	t.Cleanup(server.Close)
+	return server, dav
+}
+
+func (d *davServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if user, pass, _ := r.BasicAuth(); user != "alice" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case "MKCOL":
+		dir := strings.TrimSuffix(r.URL.Path, "/")
+		if d.collections[dir+"/"] {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if !d.collections[path.Dir(dir)+"/"] && path.Dir(dir) != "/" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		d.collections[dir+"/"] = true
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		if !d.collections[path.Dir(r.URL.Path)+"/"] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		d.files[r.URL.Path] = data
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := d.files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case "PROPFIND":
+		if !d.collections[r.URL.Path] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:"><d:response><d:href>%s</d:href></d:response>`, r.URL.Path)
+		for name := range d.files {
+			if path.Dir(name)+"/" == r.URL.Path {
+				fmt.Fprintf(w, `<d:response><d:href>%s</d:href></d:response>`, strings.ReplaceAll(name, " ", "%20"))
+			}
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestWebDAVStoreRoundTrip(t *testing.T) {
+	server, dav := newDAVServer(t)
+	store := history.WebDAVStore{URL: server.URL + "/ai/sessions", Username: "alice", Password: "secret"}
+
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Fatalf("List() before saving = %v, %v; want empty", names, err)
+	}
+	if _, err := store.Load("missing.json"); !errors.Is(err, history.ErrNotFound) {
+		t.Fatalf("Load() of a missing session error = %v, want ErrNotFound", err)
+	}
+	for _, name := range []string{"20250101_090000_first.json", "20250102_090000_second try.json"} {
+		if err := store.Save(name, history.Session{Model: "gpt-4o", Messages: []history.Message{{Role: "user", Content: name}}}); err != nil {
+			t.Fatalf("Save(%q) error = %v", name, err)
+		}
+	}
+	if !dav.collections["/ai/sessions/"] {
+		t.Fatalf("expected the collection to be created, got %v", dav.collections)
+	}
+
+	names, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []string{"20250102_090000_second try.json", "20250101_090000_first.json"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("List() = %v, want %v", names, want)
+	}
+	session, err := store.Load(want[0])
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if session.Model != "gpt-4o" || len(session.Messages) != 1 || session.Messages[0].Content != want[0] {
+		t.Fatalf("Load() = %+v", session)
+	}
+	if err := store.Save("../escape.json", history.Session{}); err == nil {
+		t.Fatal("expected an error for a name outside the collection")
+	}
+}
+
+func TestWebDAVStoreReportsAuthFailures(t *testing.T) {
+	server, _ := newDAVServer(t)
+	store := history.WebDAVStore{URL: server.URL + "/sessions/", Username: "alice", Password: "wrong"}
+	err := store.Save("session.json", history.Session{})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Save() error = %v, want a 401 error", err)
+	}
+}
+
+func TestMirrorStoreSharesSessionsBetweenMachines(t *testing.T) {
+	server, _ := newDAVServer(t)
+	remote := history.WebDAVStore{URL: server.URL + "/sessions/", Username: "alice", Password: "secret"}
+	laptop := history.MirrorStore{Local: history.FileStore{Root: t.TempDir()}, Remote: remote}
+	desktop := history.MirrorStore{Local: history.FileStore{Root: t.TempDir()}, Remote: remote}
+
+	if err := laptop.Save("20250101_090000_laptop.json", history.Session{Model: "gpt-4o"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := desktop.Save("20250102_090000_desktop.json", history.Session{Model: "llama3"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	names, err := desktop.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if want := []string{"20250102_090000_desktop.json", "20250101_090000_laptop.json"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("List() = %v, want %v", names, want)
+	}
+	session, err := desktop.Load("20250101_090000_laptop.json")
+	if err != nil || session.Model != "gpt-4o" {
+		t.Fatalf("Load() = %+v, %v", session, err)
+	}
+	if _, err := os.Stat(desktop.Local.Path("20250101_090000_laptop.json")); err != nil {
+		t.Fatalf("expected the downloaded session to be cached locally: %v", err)
+	}
+}
+
+func TestMirrorStoreKeepsLocalCopyWhenRemoteFails(t *testing.T) {
+	server, _ := newDAVServer(t)
+	local := history.FileStore{Root: filepath.Join(t.TempDir(), "sessions")}
+	store := history.MirrorStore{Local: local, Remote: history.WebDAVStore{URL: server.URL + "/sessions/", Username: "alice"}}
+
+	if err := store.Save("session.json", history.Session{Model: "gpt-4o"}); err == nil {
+		t.Fatal("expected the remote failure to be reported")
+	}
+	if _, err := local.Load("session.json"); err != nil {
+		t.Fatalf("expected the local copy to be written, got %v", err)
+	}
+	names, err := store.List()
+	if err == nil || !reflect.DeepEqual(names, []string{"session.json"}) {
+		t.Fatalf("List() = %v, %v; want the local session and an error", names, err)
+	}
+}
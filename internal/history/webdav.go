@@ -0,0 +1,215 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// webDAVTimeout bounds each request so an unreachable server cannot hold the prompt.
+const webDAVTimeout = 30 * time.Second
+
+// WebDAVStore keeps sessions in a WebDAV collection, such as a Nextcloud folder or an
+// rclone serve webdav endpoint. The collection is created on the first save.
+type WebDAVStore struct {
+	// URL is the collection, e.g. "https://dav.example.com/humble-ai/sessions/".
+	URL      string
+	Username string
+	Password string
+	// Client sends the requests; nil uses a client with a 30 second timeout.
+	Client *http.Client
+}
+
+// Load downloads the named session.
+func (s WebDAVStore) Load(name string) (Session, error) {
+	if err := checkName(name); err != nil {
+		return Session{}, err
+	}
+	resp, err := s.do(http.MethodGet, s.fileURL(name), nil, nil)
+	if err != nil {
+		return Session{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Session{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err := statusError(resp, http.MethodGet, name); err != nil {
+		return Session{}, err
+	}
+	var session Session
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return Session{}, fmt.Errorf("parse session %s: %w", name, err)
+	}
+	return session, nil
+}
+
+// Save uploads the named session, creating the collection when the server reports it missing.
+func (s WebDAVStore) Save(name string, session Session) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if session.Messages == nil {
+		session.Messages = []Message{}
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal history: %w", err)
+	}
+	data = append(data, '\n')
+	put := func() (int, error) {
+		resp, err := s.do(http.MethodPut, s.fileURL(name), data, http.Header{"Content-Type": {"application/json"}})
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	status, err := put()
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound || status == http.StatusConflict {
+		if err := s.makeCollection(); err != nil {
+			return err
+		}
+		if status, err = put(); err != nil {
+			return err
+		}
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("PUT %s: server returned %d %s", name, status, http.StatusText(status))
+	}
+	return nil
+}
+
+// List returns the session names in the collection, newest first. A missing collection
+// has no sessions.
+func (s WebDAVStore) List() ([]string, error) {
+	body := []byte(`<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`)
+	resp, err := s.do("PROPFIND", s.collectionURL(), body, http.Header{
+		"Depth":        {"1"},
+		"Content-Type": {"application/xml"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := statusError(resp, "PROPFIND", s.collectionURL()); err != nil {
+		return nil, err
+	}
+	var status struct {
+		Responses []struct {
+			Href string `xml:"href"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("parse PROPFIND response: %w", err)
+	}
+	var names []string
+	for _, r := range status.Responses {
+		href := strings.TrimSpace(r.Href)
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		if strings.HasSuffix(href, "/") {
+			continue
+		}
+		if name := path.Base(href); strings.HasSuffix(name, ".json") {
+			names = append(names, name)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// makeCollection creates the collection, creating missing parents when the server
+// answers 409 Conflict.
+func (s WebDAVStore) makeCollection() error {
+	base, err := url.Parse(s.collectionURL())
+	if err != nil {
+		return fmt.Errorf("invalid WebDAV URL: %w", err)
+	}
+	return s.mkcol(base, strings.Trim(base.Path, "/"))
+}
+
+func (s WebDAVStore) mkcol(base *url.URL, dir string) error {
+	if dir == "" || dir == "." {
+		return nil
+	}
+	target := *base
+	target.Path = "/" + dir + "/"
+	target.RawPath = ""
+	for attempt := 0; ; attempt++ {
+		resp, err := s.do("MKCOL", target.String(), nil, nil)
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode <= 299,
+			// 405 means the collection already exists.
+			resp.StatusCode == http.StatusMethodNotAllowed:
+			return nil
+		case resp.StatusCode == http.StatusConflict && attempt == 0:
+			if err := s.mkcol(base, path.Dir(dir)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("MKCOL %s: server returned %s", target.Path, resp.Status)
+		}
+	}
+}
+
+func (s WebDAVStore) collectionURL() string {
+	return strings.TrimRight(strings.TrimSpace(s.URL), "/") + "/"
+}
+
+func (s WebDAVStore) fileURL(name string) string {
+	return s.collectionURL() + url.PathEscape(name)
+}
+
+func (s WebDAVStore) do(method, target string, body []byte, header http.Header) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebDAV URL: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if s.Username != "" || s.Password != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: webDAVTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, req.URL.Redacted(), err)
+	}
+	return resp, nil
+}
+
+func statusError(resp *http.Response, method, target string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	return fmt.Errorf("%s %s: server returned %s", method, target, resp.Status)
+}
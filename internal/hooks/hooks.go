@@ -0,0 +1,197 @@
+// Package hooks runs user-configured external commands around LLM turns.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// FilePlaceholder in a command argument is replaced with a temp file holding the input.
+	FilePlaceholder = "{file}"
+)
+
+// CodeBlock is a fenced code block extracted from a markdown message.
+type CodeBlock struct {
+	Language string
+	Code     string
+}
+
+// Result is the outcome of a single hook invocation.
+type Result struct {
+	Hook     string
+	Language string
+	Output   string
+	Stderr   string
+	ExitCode int
+	Err      error
+}
+
+var fencePattern = regexp.MustCompile("(?s)```([A-Za-z0-9_+.#-]*)[^\\n]*\\n(.*?)```")
+
+// ExtractCodeBlocks returns the fenced code blocks in a markdown message.
+func ExtractCodeBlocks(markdown string) []CodeBlock {
+	matches := fencePattern.FindAllStringSubmatch(markdown, -1)
+	blocks := make([]CodeBlock, 0, len(matches))
+	for _, m := range matches {
+		blocks = append(blocks, CodeBlock{Language: strings.ToLower(m[1]), Code: m[2]})
+	}
+	return blocks
+}
+
+// RunPreSend pipes a user message through a hook and returns the rewritten message.
+// Empty output leaves the message unchanged; a non-zero exit rejects the message.
+func RunPreSend(ctx context.Context, hook config.Hook, message string) (string, error) {
+	result := run(ctx, hook, "", message, false)
+	if result.Err != nil {
+		return "", fmt.Errorf("hook %s: %w", result.Hook, result.Err)
+	}
+	if result.ExitCode != 0 {
+		detail := strings.TrimSpace(result.Stderr)
+		if detail == "" {
+			detail = strings.TrimSpace(result.Output)
+		}
+		return "", fmt.Errorf("hook %s exited with status %d: %s", result.Hook, result.ExitCode, detail)
+	}
+	rewritten := strings.TrimRight(result.Output, "\n")
+	if strings.TrimSpace(rewritten) == "" {
+		return message, nil
+	}
+	return rewritten, nil
+}
+
+// RunPostResponse pipes an assistant message, or its matching code blocks, through a hook.
+func RunPostResponse(ctx context.Context, hook config.Hook, message string) []Result {
+	if !strings.EqualFold(hook.Input, config.HookInputCode) {
+		return []Result{run(ctx, hook, "", message, true)}
+	}
+	var results []Result
+	for _, block := range ExtractCodeBlocks(message) {
+		if !matchesLanguage(hook.Languages, block.Language) {
+			continue
+		}
+		results = append(results, run(ctx, hook, block.Language, block.Code, true))
+	}
+	return results
+}
+
+func matchesLanguage(languages []string, language string) bool {
+	if len(languages) == 0 {
+		return true
+	}
+	for _, l := range languages {
+		if strings.EqualFold(l, language) {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes a hook; combined merges stderr into Output, otherwise it goes to Stderr.
+func run(ctx context.Context, hook config.Hook, language, input string, combined bool) Result {
+	result := Result{Hook: hook.DisplayName(), Language: language}
+	if len(hook.Command) == 0 {
+		result.Err = errors.New("hook command is empty")
+		return result
+	}
+
+	timeout := defaultTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := append([]string(nil), hook.Command...)
+	if usesFilePlaceholder(args) {
+		path, cleanup, err := writeTempInput(language, input)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		defer cleanup()
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, FilePlaceholder, path)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(os.Environ(), "HAC_HOOK_LANGUAGE="+language)
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+	if combined {
+		cmd.Stderr = &out
+	}
+
+	err := cmd.Run()
+	result.Output = out.String()
+	result.Stderr = errOut.String()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		result.Err = err
+	}
+	return result
+}
+
+func usesFilePlaceholder(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, FilePlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTempInput(language, input string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "hac-hook-")
+	if err != nil {
+		return "", nil, fmt.Errorf("create hook temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	path := filepath.Join(dir, "input"+extensionFor(language))
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("write hook input: %w", err)
+	}
+	return path, cleanup, nil
+}
+
+func extensionFor(language string) string {
+	switch language {
+	case "":
+		return ".txt"
+	case "go", "golang":
+		return ".go"
+	case "python", "py":
+		return ".py"
+	case "javascript", "js":
+		return ".js"
+	case "typescript", "ts":
+		return ".ts"
+	case "shell", "sh", "bash":
+		return ".sh"
+	case "rust", "rs":
+		return ".rs"
+	default:
+		return "." + language
+	}
+}
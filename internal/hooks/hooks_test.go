@@ -0,0 +1,88 @@
+package hooks_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/hooks"
+)
+
+const sampleAnswer = "Here you go:\n\n```go\npackage main\nfunc main(){}\n```\n\nAnd a script:\n\n```sh\necho hi\n```\n"
+
+func TestExtractCodeBlocks(t *testing.T) {
+	blocks := hooks.ExtractCodeBlocks(sampleAnswer)
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 code blocks, got %d", len(blocks))
+	}
+	if blocks[0].Language != "go" || blocks[0].Code != "package main\nfunc main(){}\n" {
+		t.Fatalf("unexpected first block: %#v", blocks[0])
+	}
+	if blocks[1].Language != "sh" || blocks[1].Code != "echo hi\n" {
+		t.Fatalf("unexpected second block: %#v", blocks[1])
+	}
+}
+
+func TestRunPostResponseFiltersCodeBlocksByLanguage(t *testing.T) {
+	hook := config.Hook{
+		Name:      "count",
+		Command:   []string{"sh", "-c", "wc -l < {file}; echo lang=$HAC_HOOK_LANGUAGE"},
+		Input:     config.HookInputCode,
+		Languages: []string{"go"},
+	}
+	results := hooks.RunPostResponse(context.Background(), hook, sampleAnswer)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	got := results[0]
+	if got.Err != nil || got.ExitCode != 0 {
+		t.Fatalf("unexpected hook failure: %#v", got)
+	}
+	if !strings.Contains(got.Output, "2") || !strings.Contains(got.Output, "lang=go") {
+		t.Fatalf("unexpected hook output: %q", got.Output)
+	}
+}
+
+func TestRunPostResponsePipesWholeMessageAndReportsExitCode(t *testing.T) {
+	hook := config.Hook{Command: []string{"sh", "-c", "grep -c script; exit 4"}}
+	results := hooks.RunPostResponse(context.Background(), hook, sampleAnswer)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].ExitCode != 4 || strings.TrimSpace(results[0].Output) != "1" {
+		t.Fatalf("unexpected result: %#v", results[0])
+	}
+	if results[0].Hook != "sh -c grep -c script; exit 4" {
+		t.Fatalf("expected command as display name, got %q", results[0].Hook)
+	}
+}
+
+func TestRunPostResponseTimesOut(t *testing.T) {
+	hook := config.Hook{Name: "slow", Command: []string{"sleep", "5"}, TimeoutSeconds: 1}
+	results := hooks.RunPostResponse(context.Background(), hook, "x")
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %#v", results)
+	}
+}
+
+func TestRunPreSendRewritesMessage(t *testing.T) {
+	hook := config.Hook{Name: "tickets", Command: []string{"sed", "s/PROJ-1/PROJ-1 (Fix login timeout)/"}}
+	got, err := hooks.RunPreSend(context.Background(), hook, "look at PROJ-1")
+	if err != nil {
+		t.Fatalf("RunPreSend() error = %v", err)
+	}
+	if got != "look at PROJ-1 (Fix login timeout)" {
+		t.Fatalf("unexpected rewrite: %q", got)
+	}
+
+	unchanged, err := hooks.RunPreSend(context.Background(), config.Hook{Command: []string{"true"}}, "keep me")
+	if err != nil || unchanged != "keep me" {
+		t.Fatalf("expected empty output to keep message, got %q (%v)", unchanged, err)
+	}
+
+	_, err = hooks.RunPreSend(context.Background(), config.Hook{Name: "guard", Command: []string{"sh", "-c", "echo contains secret >&2; exit 2"}}, "password=1")
+	if err == nil || !strings.Contains(err.Error(), "contains secret") {
+		t.Fatalf("expected rejection with stderr detail, got %v", err)
+	}
+}
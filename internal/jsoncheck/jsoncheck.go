@@ -0,0 +1,353 @@
+// Package jsoncheck validates JSON documents against Go struct types and reports
+// problems with line, column and field references.
+package jsoncheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Issue is a single schema problem found in a document.
+type Issue struct {
+	// Line and Column are 1-based; zero when the issue has no source position.
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d, column %d: ", i.Line, i.Column)
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// Position converts a byte offset into a 1-based line and column.
+func Position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	prefix := data[:offset]
+	line := bytes.Count(prefix, []byte("\n")) + 1
+	column := len(prefix) - bytes.LastIndexByte(prefix, '\n')
+	return line, column
+}
+
+// Annotate adds the line and column to syntax and type errors returned by encoding/json.
+func Annotate(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := Position(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		line, col := Position(data, typeErr.Offset)
+		field := typeErr.Field
+		if field == "" {
+			field = "value"
+		}
+		return fmt.Errorf("line %d, column %d: %s: expected %s, found %s", line, col, field, typeErr.Type, typeErr.Value)
+	}
+	return err
+}
+
+// Check reports unknown fields and type mismatches in data relative to the type of target.
+// Syntax errors are returned as an error annotated with their position.
+func Check(data []byte, target any) ([]Issue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	c := &checker{data: data, dec: dec}
+	if err := c.value(reflect.TypeOf(target), ""); err != nil {
+		return nil, Annotate(data, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		line, col := Position(data, c.next())
+		return nil, fmt.Errorf("line %d, column %d: unexpected data after top-level value", line, col)
+	}
+	return c.issues, nil
+}
+
+type checker struct {
+	data   []byte
+	dec    *json.Decoder
+	issues []Issue
+}
+
+// next returns the offset of the next token, skipping whitespace and separators.
+func (c *checker) next() int64 {
+	offset := c.dec.InputOffset()
+	for offset < int64(len(c.data)) {
+		switch c.data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+			continue
+		}
+		break
+	}
+	return offset
+}
+
+func (c *checker) report(offset int64, path, format string, args ...any) {
+	line, col := Position(c.data, offset)
+	c.issues = append(c.issues, Issue{Line: line, Column: col, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) value(t reflect.Type, path string) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	start := c.next()
+	tok, err := c.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil || t == nil || t.Kind() == reflect.Interface {
+		if delim, ok := tok.(json.Delim); ok {
+			return c.skip(delim)
+		}
+		return nil
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		switch {
+		case v == '{' && t.Kind() == reflect.Struct:
+			return c.object(t, path)
+		case v == '{' && t.Kind() == reflect.Map:
+			return c.mapEntries(t, path)
+		case v == '[' && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			for i := 0; c.dec.More(); i++ {
+				if err := c.value(t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err := c.dec.Token()
+			return err
+		}
+		found := "object"
+		if v == '[' {
+			found = "array"
+		}
+		c.report(start, displayPath(path), "expected %s, found %s", describe(t), found)
+		return c.skip(v)
+	case string:
+		if t.Kind() != reflect.String {
+			c.report(start, displayPath(path), "expected %s, found string", describe(t))
+		}
+	case bool:
+		if t.Kind() != reflect.Bool {
+			c.report(start, displayPath(path), "expected %s, found boolean", describe(t))
+		}
+	case json.Number:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if _, err := strconv.ParseInt(v.String(), 10, 64); err != nil {
+				c.report(start, displayPath(path), "expected integer, found %s", v)
+			}
+		case reflect.Float32, reflect.Float64:
+		default:
+			c.report(start, displayPath(path), "expected %s, found number", describe(t))
+		}
+	}
+	return nil
+}
+
+func (c *checker) object(t reflect.Type, path string) error {
+	fields := structFields(t)
+	for c.dec.More() {
+		keyOffset := c.next()
+		tok, err := c.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		field, ok := lookupField(fields, key)
+		if !ok {
+			msg := "unknown field"
+			if suggestion := closestField(fields, key); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			c.report(keyOffset, joinPath(path, key), "%s", msg)
+			if err := c.value(nil, ""); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.value(field.typ, joinPath(path, field.name)); err != nil {
+			return err
+		}
+	}
+	_, err := c.dec.Token()
+	return err
+}
+
+func (c *checker) mapEntries(t reflect.Type, path string) error {
+	for c.dec.More() {
+		tok, err := c.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		if err := c.value(t.Elem(), joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+	_, err := c.dec.Token()
+	return err
+}
+
+// skip consumes the remainder of a container whose opening delimiter was already read.
+func (c *checker) skip(open json.Delim) error {
+	if open != '{' && open != '[' {
+		return nil
+	}
+	depth := 1
+	for depth > 0 {
+		tok, err := c.dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			default:
+				depth--
+			}
+		}
+	}
+	return nil
+}
+
+type field struct {
+	name string
+	typ  reflect.Type
+}
+
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, structFields(embedded)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, field{name: name, typ: f.Type})
+	}
+	return fields
+}
+
+// lookupField matches keys the way encoding/json does: exactly, then case-insensitively.
+func lookupField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func closestField(fields []field, key string) string {
+	best, bestDistance := "", len(key)/3+2
+	for _, f := range fields {
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(f.name)); d < bestDistance {
+			best, bestDistance = f.name, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return t.String()
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
@@ -0,0 +1,69 @@
+package jsoncheck_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/jsoncheck"
+)
+
+type item struct {
+	Name    string         `json:"name"`
+	Enabled *bool          `json:"enabled,omitempty"`
+	Limit   int            `json:"limit,omitempty"`
+	Extra   map[string]any `json:"extra,omitempty"`
+}
+
+type document struct {
+	Mode  string          `json:"mode"`
+	Items []item          `json:"items"`
+	Tags  map[string]item `json:"tags"`
+}
+
+func TestCheckReportsUnknownFieldsAndTypes(t *testing.T) {
+	data := []byte(`{
+  "mode": "auto",
+  "items": [
+    {"nmae": "a", "limit": 1.5},
+    {"name": "b", "enabled": "yes", "extra": {"anything": [1, {"x": true}]}}
+  ],
+  "tags": {"k": {"name": 3}},
+  "mdoe": "x"
+}`)
+	issues, err := jsoncheck.Check(data, document{})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	want := []string{
+		`line 4, column 6: items[0].nmae: unknown field (did you mean "name"?)`,
+		`line 4, column 28: items[0].limit: expected integer, found 1.5`,
+		`line 5, column 30: items[1].enabled: expected boolean, found string`,
+		`line 7, column 26: tags.k.name: expected string, found number`,
+		`line 8, column 3: mdoe: unknown field (did you mean "mode"?)`,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for i, issue := range issues {
+		if issue.String() != want[i] {
+			t.Errorf("issue %d = %q, want %q", i, issue.String(), want[i])
+		}
+	}
+}
+
+func TestCheckAnnotatesSyntaxErrors(t *testing.T) {
+	_, err := jsoncheck.Check([]byte("{\n  \"mode\": \"auto\",\n}"), document{})
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2, column 18:") {
+		t.Fatalf("expected positioned syntax error, got %v", err)
+	}
+}
+
+func TestAnnotateTypeError(t *testing.T) {
+	data := []byte("{\n  \"items\": {}\n}")
+	var doc document
+	err := jsoncheck.Annotate(data, json.Unmarshal(data, &doc))
+	if err == nil || !strings.Contains(err.Error(), "line 2, column") || !strings.Contains(err.Error(), "items") {
+		t.Fatalf("expected annotated type error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// apiKeyCommandTimeout leaves room for password managers that ask for a fingerprint or
+// master password before printing the secret.
+const apiKeyCommandTimeout = time.Minute
+
+// promptTerminal returns where an apiKeyCommand may ask for a master password: stdin to
+// read the answer and stderr to show the prompt, each only when it is a terminal.
+var promptTerminal = func() (io.Reader, io.Writer) {
+	var (
+		in  io.Reader
+		out io.Writer
+	)
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		in = os.Stdin
+	}
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		out = os.Stderr
+	}
+	return in, out
+}
+
+// apiKeyCache keeps the keys printed by apiKeyCommand for the life of the process, so a
+// password manager is asked once rather than for every provider that is created.
+var apiKeyCache = struct {
+	sync.Mutex
+	keys map[string]string
+}{keys: map[string]string{}}
+
+// resolveAPIKey fills model.APIKey from model.APIKeyCommand when no key is configured.
+func resolveAPIKey(model config.Model) (config.Model, error) {
+	if model.APIKey != "" || len(model.APIKeyCommand) == 0 {
+		return model, nil
+	}
+	key, err := commandAPIKey(model.APIKeyCommand)
+	if err != nil {
+		return model, fmt.Errorf("model %q: apiKeyCommand: %w", model.Name, err)
+	}
+	model.APIKey = key
+	return model, nil
+}
+
+// commandAPIKey runs argv and returns the first line of its output, which is where pass,
+// gopass and op read print the secret.
+func commandAPIKey(argv []string) (string, error) {
+	cacheKey := strings.Join(argv, "\x00")
+	apiKeyCache.Lock()
+	defer apiKeyCache.Unlock()
+	if key, ok := apiKeyCache.keys[cacheKey]; ok {
+		return key, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Prompts go to the terminal as well, so they can be seen and answered; the copy
+	// explains a failure.
+	in, prompts := promptTerminal()
+	cmd.Stdin = in
+	if prompts != nil {
+		cmd.Stderr = io.MultiWriter(prompts, &stderr)
+	}
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s timed out after %s", argv[0], apiKeyCommandTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %v: %s", argv[0], err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", argv[0], err)
+	}
+	key, _, _ := strings.Cut(stdout.String(), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("%s printed no key", argv[0])
+	}
+	apiKeyCache.keys[cacheKey] = key
+	return key, nil
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestFactoryReadsAPIKeyFromCommandOnce(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := []string{"sh", "-c", `echo run >> "$0"; printf 'sk-from-pass\nlogin: me\n'`, runs}
+
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	factory := NewFactory(server.Client())
+	model := config.Model{Name: "gpt-4o", Provider: "openai", BaseURL: server.URL, APIKeyCommand: command}
+	for i := 0; i < 2; i++ {
+		provider, err := factory.Create(model)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		stream, err := provider.Stream(context.Background(), ChatRequest{Model: "gpt-4o", Messages: []Message{{Role: "user", Content: "hi"}}})
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		for range stream {
+		}
+	}
+
+	if len(auth) != 2 || auth[0] != "Bearer sk-from-pass" || auth[1] != auth[0] {
+		t.Fatalf("expected the first output line as the bearer key, got %q", auth)
+	}
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatalf("failed to read run log: %v", err)
+	}
+	if got := strings.Count(string(data), "run"); got != 1 {
+		t.Fatalf("expected the command to run once per process, ran %d times", got)
+	}
+}
+
+func TestFactoryReportsFailingAPIKeyCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	factory := NewFactory(nil)
+	for _, tc := range []struct {
+		command []string
+		want    string
+	}{
+		{[]string{"sh", "-c", "echo 'gpg: decryption failed' >&2; exit 2"}, "gpg: decryption failed"},
+		{[]string{"sh", "-c", "printf '\\n'"}, "printed no key"},
+	} {
+		_, err := factory.Create(config.Model{Name: "gpt-4o", Provider: "openai", APIKeyCommand: tc.command})
+		if err == nil || !strings.Contains(err.Error(), tc.want) || !strings.Contains(err.Error(), "apiKeyCommand") {
+			t.Fatalf("Create() with %v error = %v, want it to mention %q", tc.command, err, tc.want)
+		}
+	}
+}
+
+func TestAPIKeyCommandCanPromptOnTheTerminal(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	var prompts bytes.Buffer
+	saved := promptTerminal
+	promptTerminal = func() (io.Reader, io.Writer) { return strings.NewReader("hunter2\n"), &prompts }
+	defer func() { promptTerminal = saved }()
+
+	key, err := commandAPIKey([]string{"sh", "-c", `printf 'Master password: ' >&2; read pw; echo "sk-$pw"`, t.Name()})
+	if err != nil {
+		t.Fatalf("commandAPIKey() error = %v", err)
+	}
+	if key != "sk-hunter2" {
+		t.Fatalf("expected the key printed after the password was answered, got %q", key)
+	}
+	if prompts.String() != "Master password: " {
+		t.Fatalf("expected the prompt on the terminal, got %q", prompts.String())
+	}
+}
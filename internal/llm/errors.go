@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrorCategory classifies a provider failure by what the user can do about it.
+type ErrorCategory string
+
+const (
+	// ErrorUnknown covers failures that fit no other category.
+	ErrorUnknown ErrorCategory = "unknown"
+	// ErrorAuth means the API key was missing, rejected or lacks access.
+	ErrorAuth ErrorCategory = "auth"
+	// ErrorRateLimited means the provider throttled the request or the quota ran out.
+	ErrorRateLimited ErrorCategory = "rate_limited"
+	// ErrorContextTooLong means the conversation does not fit the model's context window.
+	ErrorContextTooLong ErrorCategory = "context_too_long"
+	// ErrorNetwork means the provider could not be reached or the connection broke.
+	ErrorNetwork ErrorCategory = "network"
+	// ErrorServer means the provider failed on its side.
+	ErrorServer ErrorCategory = "server"
+)
+
+// ProviderError is a failed provider request or an error reported inside a stream.
+type ProviderError struct {
+	Category ErrorCategory
+	// Status is the HTTP status code, or 0 for errors that did not come with one.
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *ProviderError) Error() string {
+	switch {
+	case e.Message != "" && e.Err != nil:
+		return e.Message + ": " + e.Err.Error()
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return string(e.Category)
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// Categorize returns the category of err; errors that are not a ProviderError are
+// classified by their type, so broken connections still count as network errors.
+func Categorize(err error) ErrorCategory {
+	var providerErr *ProviderError
+	switch {
+	case err == nil:
+		return ErrorUnknown
+	case errors.As(err, &providerErr):
+		return providerErr.Category
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorUnknown
+	case isNetworkError(err):
+		return ErrorNetwork
+	}
+	return messageCategory(err.Error())
+}
+
+// responseError describes a non-2xx HTTP response.
+func responseError(api string, status int, body []byte) error {
+	category := messageCategory(string(body))
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		category = ErrorAuth
+	case status == http.StatusTooManyRequests:
+		category = ErrorRateLimited
+	case status == http.StatusRequestEntityTooLarge:
+		category = ErrorContextTooLong
+	case status >= 500 && category == ErrorUnknown:
+		category = ErrorServer
+	}
+	return &ProviderError{Category: category, Status: status, Message: fmt.Sprintf("%s response %d: %s", api, status, string(body))}
+}
+
+// streamError describes an error event sent inside a response stream.
+func streamError(message string) error {
+	return &ProviderError{Category: messageCategory(message), Message: message}
+}
+
+// networkError marks a failed request as a network error unless it was cancelled.
+func networkError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return &ProviderError{Category: ErrorNetwork, Err: err}
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// messageCategory recognizes the wording providers use for common failures.
+func messageCategory(message string) ErrorCategory {
+	text := strings.ToLower(message)
+	containsAny := func(words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(text, word) {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case containsAny("context length", "context_length", "context window", "maximum context", "too many tokens", "prompt is too long", "input is too long", "inputs too long", "reduce the length"):
+		return ErrorContextTooLong
+	case containsAny("rate limit", "rate_limit", "too many requests", "quota"):
+		return ErrorRateLimited
+	case containsAny("invalid api key", "invalid_api_key", "incorrect api key", "unauthorized", "authentication"):
+		return ErrorAuth
+	case containsAny("overloaded", "internal server error", "service unavailable", "bad gateway"):
+		return ErrorServer
+	}
+	return ErrorUnknown
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestResponseErrorCategories(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		status int
+		body   string
+		want   ErrorCategory
+	}{
+		{http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided"}}`, ErrorAuth},
+		{http.StatusForbidden, `forbidden`, ErrorAuth},
+		{http.StatusTooManyRequests, `slow down`, ErrorRateLimited},
+		{http.StatusBadRequest, `{"error":{"code":"context_length_exceeded","message":"This model's maximum context length is 8192 tokens"}}`, ErrorContextTooLong},
+		{http.StatusRequestEntityTooLarge, `payload too large`, ErrorContextTooLong},
+		{http.StatusBadRequest, `{"error":{"message":"You exceeded your current quota"}}`, ErrorRateLimited},
+		{http.StatusInternalServerError, `oops`, ErrorServer},
+		{http.StatusServiceUnavailable, `{"error":{"message":"prompt is too long"}}`, ErrorContextTooLong},
+		{http.StatusNotFound, `model "x" not found`, ErrorUnknown},
+	}
+	for _, tc := range cases {
+		err := responseError("openai", tc.status, []byte(tc.body))
+		if got := Categorize(err); got != tc.want {
+			t.Errorf("%d %s: category = %s, want %s", tc.status, tc.body, got, tc.want)
+		}
+		if !strings.HasPrefix(err.Error(), "openai response ") {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+	}
+}
+
+func TestCategorizeStreamAndNetworkErrors(t *testing.T) {
+	t.Parallel()
+
+	if got := Categorize(streamError("Input validation error: inputs too long")); got != ErrorContextTooLong {
+		t.Fatalf("expected a context error, got %s", got)
+	}
+	if got := Categorize(streamError("Provider returned error: overloaded")); got != ErrorServer {
+		t.Fatalf("expected a server error, got %s", got)
+	}
+	if got := Categorize(networkError(context.Canceled)); got != ErrorUnknown {
+		t.Fatalf("expected cancellation not to count as a network error, got %s", got)
+	}
+	if got := Categorize(io.ErrUnexpectedEOF); got != ErrorNetwork {
+		t.Fatalf("expected a cut-off body to count as a network error, got %s", got)
+	}
+	if got := Categorize(errors.New("something else")); got != ErrorUnknown {
+		t.Fatalf("expected an unknown error, got %s", got)
+	}
+}
+
+func TestProvidersReportTypedErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"Invalid API key"}}`, http.StatusUnauthorized)
+	}))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer server.Close()
+
+	cases := []struct {
+		name  string
+		model config.Model
+		want  ErrorCategory
+	}{
+		{"openai auth", config.Model{Name: "gpt", Provider: "openai", APIKey: "sk", BaseURL: server.URL}, ErrorAuth},
+		{"ollama auth", config.Model{Name: "llama3", Provider: "ollama", BaseURL: server.URL}, ErrorAuth},
+		{"openai unreachable", config.Model{Name: "gpt", Provider: "openai", APIKey: "sk", BaseURL: closed.URL}, ErrorNetwork},
+		{"ollama unreachable", config.Model{Name: "llama3", Provider: "ollama", BaseURL: closed.URL}, ErrorNetwork},
+	}
+	for _, tc := range cases {
+		provider, err := NewFactory(nil).Create(tc.model)
+		if err != nil {
+			t.Fatalf("%s: create provider: %v", tc.name, err)
+		}
+		stream, err := provider.Stream(context.Background(), ChatRequest{Model: tc.model.Name, Stream: true})
+		if err != nil {
+			t.Fatalf("%s: stream: %v", tc.name, err)
+		}
+		var streamErr error
+		for chunk := range stream {
+			if chunk.Type == ChunkError {
+				streamErr = chunk.Err
+			}
+		}
+		var providerErr *ProviderError
+		if !errors.As(streamErr, &providerErr) || providerErr.Category != tc.want {
+			t.Fatalf("%s: expected a %s ProviderError, got %#v", tc.name, tc.want, streamErr)
+		}
+	}
+}
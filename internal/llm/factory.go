@@ -1,7 +1,6 @@
 package llm
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -11,6 +10,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gamzabox/humble-ai-cli/internal/config"
@@ -24,79 +24,312 @@ type HTTPClient interface {
 // Factory wires config models to providers.
 type Factory struct {
 	client HTTPClient
+	tools  *toolSchemaCache
+	// streamless remembers endpoints that answer without streaming.
+	streamless *streamlessEndpoints
+
+	mocksMu sync.Mutex
+	mocks   map[string]*mockState
+}
+
+const (
+	defaultTemperature = 0.1
+	openRouterBaseURL  = "https://openrouter.ai/api/v1"
+	openRouterReferer  = "https://github.com/gamzabox/humble-ai-cli"
+	openRouterTitle    = "humble-ai-cli"
+)
+
+// samplingOptions carries per-model generation settings from config.Model.
+type samplingOptions struct {
+	seed  *int64
+	extra map[string]any
+	// think is the Ollama "think" field: false, true or an effort level.
+	think any
+}
+
+func samplingFromModel(model config.Model) samplingOptions {
+	return samplingOptions{seed: model.Seed, extra: model.ExtraParams}
+}
+
+// openAIReasoningParams maps reasoningEffort and thinkingBudget onto OpenAI chat
+// completion fields. Anthropic's OpenAI-compatible endpoint also takes a thinking budget.
+func openAIReasoningParams(model config.Model, baseURL string) map[string]any {
+	params := make(map[string]any, len(model.ExtraParams)+2)
+	if effort := strings.ToLower(strings.TrimSpace(model.ReasoningEffort)); effort != "" {
+		params["reasoning_effort"] = effort
+	}
+	if model.ThinkingBudget > 0 && strings.Contains(baseURL, "anthropic.com") {
+		params["thinking"] = map[string]any{"type": "enabled", "budget_tokens": model.ThinkingBudget}
+	}
+	for key, value := range model.ExtraParams {
+		params[key] = value
+	}
+	return params
+}
+
+// openRouterReasoning builds OpenRouter's unified "reasoning" object. It accepts either
+// an effort or a token budget, so the budget wins when both are configured.
+func openRouterReasoning(model config.Model) map[string]any {
+	effort := strings.ToLower(strings.TrimSpace(model.ReasoningEffort))
+	switch {
+	case model.ThinkingBudget > 0:
+		return map[string]any{"max_tokens": model.ThinkingBudget}
+	case effort == "none":
+		return map[string]any{"enabled": false}
+	case effort == "minimal":
+		return map[string]any{"effort": "low"}
+	case effort != "":
+		return map[string]any{"effort": effort}
+	}
+	return nil
 }
 
-const defaultTemperature = 0.1
+// ollamaThink maps reasoningEffort onto Ollama's "think" flag, which takes a boolean or,
+// for models with effort levels, low, medium or high.
+func ollamaThink(model config.Model) any {
+	switch effort := strings.ToLower(strings.TrimSpace(model.ReasoningEffort)); effort {
+	case "none":
+		return false
+	case "minimal":
+		return "low"
+	case "low", "medium", "high":
+		return effort
+	}
+	if model.ThinkingBudget > 0 {
+		return true
+	}
+	return nil
+}
+
+// openAIReservedParams are payload fields that extraParams must not replace.
+var openAIReservedParams = map[string]struct{}{
+	"model":    {},
+	"messages": {},
+	"stream":   {},
+	"tools":    {},
+}
+
+// mergeExtraParams overlays extraParams onto an encoded OpenAI payload.
+func mergeExtraParams(payload []byte, extra map[string]any) ([]byte, error) {
+	if len(extra) == 0 {
+		return payload, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		if _, reserved := openAIReservedParams[key]; reserved {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode extra param %q: %w", key, err)
+		}
+		fields[key] = encoded
+	}
+	return json.Marshal(fields)
+}
 
 // NewFactory builds a Factory with optional custom HTTP client.
 func NewFactory(client HTTPClient) *Factory {
 	if client == nil {
 		client = &http.Client{Timeout: 0}
 	}
-	return &Factory{client: client}
+	return &Factory{client: client, tools: &toolSchemaCache{}, streamless: &streamlessEndpoints{}}
 }
 
 // Create instantiates a provider for a model.
 func (f *Factory) Create(model config.Model) (ChatProvider, error) {
+	model, err := resolveAPIKey(model)
+	if err != nil {
+		return nil, err
+	}
+	toolsInPrompt := model.EffectiveToolStrategy() != config.ToolStrategyNative
+	repairs := model.ToolCallRepairs
 	switch strings.ToLower(model.Provider) {
 	case "openai":
 		if model.APIKey == "" {
-			return nil, errors.New("openai provider requires apiKey")
+			return nil, errors.New("openai provider requires apiKey or apiKeyCommand")
 		}
 		base := model.BaseURL
 		if base == "" {
 			base = "https://api.openai.com/v1"
 		}
+		sampling := samplingFromModel(model)
+		sampling.extra = openAIReasoningParams(model, base)
+		return &openAIProvider{
+			client:        f.client,
+			baseURL:       strings.TrimRight(base, "/"),
+			apiKey:        model.APIKey,
+			sampling:      sampling,
+			toolsInPrompt: toolsInPrompt,
+			repairs:       repairs,
+			tools:         f.tools,
+			omitRepeated:  model.OmitRepeatedTools,
+			streamless:    f.streamless,
+		}, nil
+	case "openrouter":
+		if model.APIKey == "" {
+			return nil, errors.New("openrouter provider requires apiKey or apiKeyCommand")
+		}
+		base := model.BaseURL
+		if base == "" {
+			base = openRouterBaseURL
+		}
+		sampling := samplingFromModel(model)
+		sampling.extra = openRouterParams(model)
 		return &openAIProvider{
-			client:  f.client,
-			baseURL: strings.TrimRight(base, "/"),
-			apiKey:  model.APIKey,
+			client:   f.client,
+			baseURL:  strings.TrimRight(base, "/"),
+			apiKey:   model.APIKey,
+			sampling: sampling,
+			headers: map[string]string{
+				"HTTP-Referer": openRouterReferer,
+				"X-Title":      openRouterTitle,
+			},
+			reportRouting: true,
+			toolsInPrompt: toolsInPrompt,
+			repairs:       repairs,
+			tools:         f.tools,
+			omitRepeated:  model.OmitRepeatedTools,
+			streamless:    f.streamless,
+		}, nil
+	case "tgi", "huggingface":
+		base := strings.TrimRight(model.BaseURL, "/")
+		if base == "" {
+			return nil, errors.New("tgi provider requires baseUrl")
+		}
+		if !strings.HasSuffix(base, "/v1") {
+			base += "/v1"
+		}
+		return &openAIProvider{
+			client:        f.client,
+			baseURL:       base,
+			apiKey:        model.APIKey,
+			sampling:      samplingFromModel(model),
+			toolsInPrompt: toolsInPrompt,
+			repairs:       repairs,
+			tools:         f.tools,
+			omitRepeated:  model.OmitRepeatedTools,
+			streamless:    f.streamless,
 		}, nil
 	case "ollama":
 		base := model.BaseURL
 		if base == "" {
 			base = "http://localhost:11434"
 		}
+		sampling := samplingFromModel(model)
+		sampling.think = ollamaThink(model)
 		return &ollamaProvider{
-			client:  f.client,
-			baseURL: strings.TrimRight(base, "/"),
+			client:         f.client,
+			baseURL:        strings.TrimRight(base, "/"),
+			sampling:       sampling,
+			toolsInPrompt:  toolsInPrompt,
+			constrainCalls: model.ConstrainToolCalls,
+			repairs:        repairs,
+			tools:          f.tools,
 		}, nil
+	case "mock":
+		path := strings.TrimSpace(model.Scenario)
+		if path == "" {
+			return nil, errors.New("mock provider requires scenario")
+		}
+		state, err := f.mockState(path)
+		if err != nil {
+			return nil, err
+		}
+		return &mockProvider{state: state}, nil
 	default:
 		return nil, fmt.Errorf("unknown provider %q", model.Provider)
 	}
 }
 
+// openRouterParams builds the OpenRouter routing body fields; extraParams take precedence.
+func openRouterParams(model config.Model) map[string]any {
+	params := make(map[string]any, len(model.ExtraParams)+3)
+	if len(model.ProviderPreferences) > 0 {
+		params["provider"] = model.ProviderPreferences
+	}
+	if len(model.FallbackModels) > 0 {
+		params["models"] = append([]string{model.Name}, model.FallbackModels...)
+	}
+	if reasoning := openRouterReasoning(model); reasoning != nil {
+		params["reasoning"] = reasoning
+	}
+	for key, value := range model.ExtraParams {
+		params[key] = value
+	}
+	return params
+}
+
 var _ ChatProvider = (*openAIProvider)(nil)
 var _ ChatProvider = (*ollamaProvider)(nil)
 
 type openAIProvider struct {
-	client  HTTPClient
-	baseURL string
-	apiKey  string
+	client   HTTPClient
+	baseURL  string
+	apiKey   string
+	sampling samplingOptions
+	// headers are extra HTTP headers sent with every request.
+	headers map[string]string
+	// reportRouting emits ChunkRouting with the upstream model named in the stream.
+	reportRouting bool
+	// toolsInPrompt describes tools in the system prompt and reads calls from the answer
+	// text instead of using the tools API (the direct and chooseFunction strategies).
+	toolsInPrompt bool
+	// repairs is how many times a tool call written as invalid JSON is sent back for correction.
+	repairs int
+	// tools caches encoded tools arrays across turns.
+	tools *toolSchemaCache
+	// omitRepeated leaves the tools array out of the follow-up requests of a tool loop.
+	omitRepeated bool
+	// streamless lists base URLs that do not stream; they get non-streaming requests.
+	streamless *streamlessEndpoints
 }
 
 func (p *openAIProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
-	stream := make(chan StreamChunk)
+	encoded, err := p.tools.encode(req.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("encode tools: %w", err)
+	}
+	stream := newChunkStream(req.TurnID)
 	go func() {
-		defer close(stream)
+		defer stream.close()
 
-		messages := buildOpenAIMessages(req)
-		openAITools, definitions := buildOpenAITools(req.Tools)
+		messages := buildOpenAIMessages(req, p.toolsInPrompt)
+		openAITools, definitions := encoded.payload, encoded.definitions
+		if p.toolsInPrompt {
+			openAITools = nil
+		}
 		thinkingSent := false
+		repaired := 0
 
 		for {
 			result, err := p.streamOnce(ctx, req.Model, messages, openAITools, stream, &thinkingSent)
 			if err != nil {
 				if err != context.Canceled {
-					stream <- StreamChunk{Type: ChunkError, Err: err}
+					stream.send(StreamChunk{Type: ChunkError, Err: err})
 				}
 				return
 			}
+			if p.toolsInPrompt && len(definitions) > 0 {
+				result.readPromptToolCalls(definitions)
+			}
 
 			messages = append(messages, result.assistantMessage)
 
 			if len(result.toolCalls) == 0 {
-				stream <- StreamChunk{Type: ChunkDone}
+				if p.toolsInPrompt && len(definitions) > 0 && repaired < p.repairs {
+					if err := malformedToolCall(result.assistantMessage.Content); err != nil {
+						repaired++
+						logToolCallRepair(ctx, repaired, err)
+						stream.send(StreamChunk{Type: ChunkRetry, Content: "invalid tool call JSON"})
+						messages = append(messages, openAIMessage{Role: "user", Content: toolCallRepairPrompt(err)})
+						continue
+					}
+				}
+				stream.send(StreamChunk{Type: ChunkDone, FinishReason: result.finishReason})
 				return
 			}
 
@@ -104,138 +337,272 @@ func (p *openAIProvider) Stream(ctx context.Context, req ChatRequest) (<-chan St
 				toolMessage, err := p.awaitToolResult(ctx, stream, definitions, call)
 				if err != nil {
 					if err != context.Canceled {
-						stream <- StreamChunk{Type: ChunkError, Err: err}
+						stream.send(StreamChunk{Type: ChunkError, Err: err})
 					}
 					return
 				}
+				if p.toolsInPrompt {
+					toolMessage = promptToolResult(toolMessage.Name, toolMessage.Content)
+				}
 				messages = append(messages, toolMessage)
 			}
+			if p.omitRepeated && openAITools != nil {
+				if logger := LoggerFromContext(ctx); logger != nil {
+					logger.Debugf("Omitting %d bytes of tool definitions from follow-up requests", len(openAITools))
+				}
+				openAITools = nil
+			}
 		}
 	}()
-	return stream, nil
+	return stream.ch, nil
 }
 
 type openAIPassResult struct {
 	assistantMessage openAIMessage
 	toolCalls        []toolCallRequest
+	finishReason     string
 }
 
-func (p *openAIProvider) streamOnce(ctx context.Context, model string, messages []openAIMessage, tools []openAITool, stream chan<- StreamChunk, thinkingSent *bool) (*openAIPassResult, error) {
-	payload, err := json.Marshal(openAIRequestPayload{
-		Model:       model,
-		Stream:      true,
-		Messages:    messages,
-		Tools:       tools,
-		Temperature: defaultTemperature,
-	})
-	if err != nil {
-		return nil, err
+// readPromptToolCalls picks up tool calls the model wrote as JSON in its answer and
+// rewrites the answer the way replayed tool calls are sent back.
+func (r *openAIPassResult) readPromptToolCalls(definitions map[string]ToolDefinition) {
+	calls, cleaned := parseManualToolCall(r.assistantMessage.Content)
+	if len(calls) == 0 {
+		return
 	}
-
-	logger := LoggerFromContext(ctx)
-	if logger != nil {
-		logger.Debugf("Open AI LLM request: %s", string(payload))
+	content := formatToolCallContent(calls, definitions)
+	if cleaned = strings.TrimSpace(cleaned); cleaned != "" {
+		content = cleaned + "\n\n" + content
+	}
+	r.assistantMessage = openAIMessage{Role: "assistant", Content: content}
+	r.toolCalls = r.toolCalls[:0]
+	for _, call := range calls {
+		r.toolCalls = append(r.toolCalls, toolCallRequest{Call: call})
 	}
+	r.finishReason = ""
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(payload))
-	if err != nil {
-		return nil, err
+// toolCallRepairPrompt asks the model to resend a tool call whose JSON did not parse.
+func toolCallRepairPrompt(err error) string {
+	return fmt.Sprintf("Your function call JSON was invalid: %v. Please resend only the valid FUNCTION_CALL JSON.", err)
+}
+
+func logToolCallRepair(ctx context.Context, attempt int, err error) {
+	if logger := LoggerFromContext(ctx); logger != nil {
+		logger.Debugf("Invalid tool call JSON (%v); asking for a corrected call, attempt %d", err, attempt)
 	}
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
+}
 
-	resp, err := p.client.Do(httpReq)
+// promptToolResult passes a tool result as a user message, since chat templates without
+// tool support reject the tool role.
+func promptToolResult(name, content string) openAIMessage {
+	return openAIMessage{Role: "user", Content: fmt.Sprintf("Result of %s:\n%s", name, content)}
+}
+
+func (p *openAIProvider) streamOnce(ctx context.Context, model string, messages []openAIMessage, tools json.RawMessage, stream *chunkStream, thinkingSent *bool) (*openAIPassResult, error) {
+	logger := LoggerFromContext(ctx)
+	streaming := !p.streamless.has(p.baseURL)
+	resp, err := p.post(ctx, model, messages, tools, streaming)
 	if err != nil {
 		return nil, err
 	}
+	if streaming && resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		resp.Body.Close()
+		if !rejectsStreaming(resp.StatusCode, body) {
+			return nil, responseError("openai", resp.StatusCode, body)
+		}
+		p.streamless.add(p.baseURL)
+		if logger != nil {
+			logger.Debugf("%s rejected a streaming request (%s); falling back to non-streaming requests", p.baseURL, strings.TrimSpace(string(body)))
+		}
+		streaming = false
+		if resp, err = p.post(ctx, model, messages, tools, false); err != nil {
+			return nil, err
+		}
+	}
 	if resp.StatusCode >= 300 {
 		defer resp.Body.Close()
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("openai response %d: %s", resp.StatusCode, string(body))
+		return nil, responseError("openai", resp.StatusCode, body)
 	}
 
+	resp.Body = spoolBody(resp.Body, spoolMemoryLimit)
 	defer resp.Body.Close()
 
 	if !*thinkingSent {
-		stream <- StreamChunk{Type: ChunkThinking}
+		stream.send(StreamChunk{Type: ChunkThinking})
 		*thinkingSent = true
 	}
 
-	var (
-		builder       strings.Builder
-		accumulator   = newToolAccumulator()
-		assistantCall = openAIMessage{Role: "assistant"}
-	)
-
-	logResponse := func(toolCalls []toolCallRequest) {
-		if logger == nil {
-			return
+	pass := &openAIPass{provider: p, stream: stream, logger: logger, accumulator: newToolAccumulator(), assistantCall: openAIMessage{Role: "assistant"}}
+	if !streaming || isJSONResponse(resp) {
+		if streaming {
+			p.streamless.add(p.baseURL)
+			if logger != nil {
+				logger.Debugf("%s answered a streaming request with %s; using non-streaming requests from now on", p.baseURL, resp.Header.Get("Content-Type"))
+			}
 		}
-		if len(toolCalls) > 0 {
-			logger.Debugf("LLM response (tool_calls): content=%q toolCalls=%d", assistantCall.Content, len(toolCalls))
-			return
+		chunk, err := decodeOpenAICompletion(resp.Body)
+		if err != nil {
+			return nil, err
 		}
-		logger.Debugf("LLM response: %s", assistantCall.Content)
+		if result, err := pass.handle(chunk); result != nil || err != nil {
+			return result, err
+		}
+		return pass.finish(), nil
 	}
 
-	scanner := bufio.NewScanner(resp.Body)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || !strings.HasPrefix(line, "data:") {
-			continue
+	events := newSSEReader(resp.Body)
+	for {
+		event, err := events.Next()
+		if errors.Is(err, io.EOF) {
+			break
 		}
-		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				break
+			}
+			return nil, err
+		}
+		data := strings.TrimSpace(event.Data)
 		if data == "[DONE]" {
 			break
 		}
-
-		var chunk openAIStreamChunk
-		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-			return nil, err
+		if data == "" {
+			continue
+		}
+		if event.Event == "error" {
+			return nil, streamError(sseErrorMessage(data))
 		}
 
-		for _, choice := range chunk.Choices {
-			emitReasoningChunks(stream, choice.Delta.Reasoning, choice.Delta.ReasoningContent)
-
-			if choice.Delta.Content != "" {
-				stream <- StreamChunk{Type: ChunkToken, Content: choice.Delta.Content}
-				builder.WriteString(choice.Delta.Content)
-			}
-
-			if len(choice.Delta.ToolCalls) > 0 {
-				accumulator.add(choice.Delta.ToolCalls)
-			}
-
-			if choice.FinishReason == "stop" {
-				assistantCall.Content = builder.String()
-				logResponse(nil)
-				return &openAIPassResult{assistantMessage: assistantCall}, nil
+		// Some gateways put several chunks in one event, one per data line.
+		decoder := json.NewDecoder(strings.NewReader(data))
+		for decoder.More() {
+			var chunk openAIStreamChunk
+			if err := decoder.Decode(&chunk); err != nil {
+				return nil, err
 			}
-			if choice.FinishReason == "tool_calls" {
-				assistantCall.Content = builder.String()
-				assistantCall.ToolCalls = accumulator.complete()
-				toolRequests := accumulator.requests()
-				logResponse(toolRequests)
-				return &openAIPassResult{
-					assistantMessage: assistantCall,
-					toolCalls:        toolRequests,
-				}, nil
+			if result, err := pass.handle(chunk); result != nil || err != nil {
+				return result, err
 			}
 		}
 	}
+	return pass.finish(), nil
+}
 
-	if err := scanner.Err(); err != nil && !errors.Is(err, context.Canceled) {
+// post sends one chat completion request.
+func (p *openAIProvider) post(ctx context.Context, model string, messages []openAIMessage, tools json.RawMessage, streaming bool) (*http.Response, error) {
+	payload, err := json.Marshal(openAIRequestPayload{
+		Model:       model,
+		Stream:      streaming,
+		Messages:    messages,
+		Tools:       tools,
+		Temperature: defaultTemperature,
+		Seed:        p.sampling.seed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload, err = mergeExtraParams(payload, p.sampling.extra)
+	if err != nil {
 		return nil, err
 	}
 
-	assistantCall.Content = builder.String()
-	logResponse(nil)
-	return &openAIPassResult{assistantMessage: assistantCall}, nil
+	if logger := LoggerFromContext(ctx); logger != nil {
+		logger.Debugf("Open AI LLM request: %s", string(payload))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for key, value := range p.headers {
+		httpReq.Header.Set(key, value)
+	}
+	resp, err := doHTTP(p.client, httpReq, payload)
+	if err != nil {
+		return nil, networkError(err)
+	}
+	return resp, nil
+}
+
+// openAIPass collects one response of the tool loop from its chunks.
+type openAIPass struct {
+	provider      *openAIProvider
+	stream        *chunkStream
+	logger        Logger
+	builder       strings.Builder
+	accumulator   *toolAccumulator
+	assistantCall openAIMessage
+	routingSent   bool
 }
 
-func (p *openAIProvider) awaitToolResult(ctx context.Context, stream chan<- StreamChunk, defs map[string]ToolDefinition, call toolCallRequest) (openAIMessage, error) {
+// handle forwards one chunk and returns the pass result once the chunk ends the response.
+func (r *openAIPass) handle(chunk openAIStreamChunk) (*openAIPassResult, error) {
+	if message := chunk.errorMessage(); message != "" {
+		return nil, streamError(message)
+	}
+
+	if r.provider.reportRouting && !r.routingSent && chunk.Model != "" {
+		r.stream.send(StreamChunk{Type: ChunkRouting, Routing: &RoutingInfo{Model: chunk.Model, Provider: chunk.Provider}})
+		r.routingSent = true
+	}
+
+	for _, choice := range chunk.Choices {
+		emitReasoningChunks(r.stream, choice.Delta.Reasoning, choice.Delta.ReasoningContent)
+
+		if choice.Delta.Content != "" {
+			r.stream.send(StreamChunk{Type: ChunkToken, Content: choice.Delta.Content})
+			r.builder.WriteString(choice.Delta.Content)
+		}
+
+		if len(choice.Delta.ToolCalls) > 0 {
+			r.accumulator.add(choice.Delta.ToolCalls)
+		}
+
+		if isStopReason(choice.FinishReason) {
+			r.assistantCall.Content = r.builder.String()
+			r.logResponse(nil)
+			return &openAIPassResult{assistantMessage: r.assistantCall, finishReason: choice.FinishReason}, nil
+		}
+		if choice.FinishReason == "tool_calls" {
+			r.assistantCall.Content = r.builder.String()
+			r.assistantCall.ToolCalls = r.accumulator.complete()
+			toolRequests := r.accumulator.requests()
+			r.logResponse(toolRequests)
+			return &openAIPassResult{
+				assistantMessage: r.assistantCall,
+				toolCalls:        toolRequests,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// finish ends a response that stopped without a finish reason.
+func (r *openAIPass) finish() *openAIPassResult {
+	r.assistantCall.Content = r.builder.String()
+	r.logResponse(nil)
+	return &openAIPassResult{assistantMessage: r.assistantCall}
+}
+
+func (r *openAIPass) logResponse(toolCalls []toolCallRequest) {
+	if r.logger == nil {
+		return
+	}
+	if len(toolCalls) > 0 {
+		r.logger.Debugf("LLM response (tool_calls): content=%q toolCalls=%d", r.assistantCall.Content, len(toolCalls))
+		return
+	}
+	r.logger.Debugf("LLM response: %s", r.assistantCall.Content)
+}
+
+func (p *openAIProvider) awaitToolResult(ctx context.Context, stream *chunkStream, defs map[string]ToolDefinition, call toolCallRequest) (openAIMessage, error) {
 	definition, ok := defs[call.Call.Function.Name]
 	if !ok {
 		return openAIMessage{}, fmt.Errorf("unknown tool requested: %s", call.Call.Function.Name)
@@ -262,7 +629,7 @@ func (p *openAIProvider) awaitToolResult(ctx context.Context, stream chan<- Stre
 		},
 	}
 
-	stream <- StreamChunk{Type: ChunkToolCall, ToolCall: tc}
+	stream.send(StreamChunk{Type: ChunkToolCall, ToolCall: tc})
 
 	var result ToolResult
 	select {
@@ -319,15 +686,67 @@ type openAIRequestPayload struct {
 	Model       string          `json:"model"`
 	Stream      bool            `json:"stream"`
 	Messages    []openAIMessage `json:"messages"`
-	Tools       []openAITool    `json:"tools,omitempty"`
+	Tools       json.RawMessage `json:"tools,omitempty"`
 	Temperature float64         `json:"temperature"`
+	Seed        *int64          `json:"seed,omitempty"`
 }
 
 type openAIStreamChunk struct {
-	Choices []struct {
-		Delta        openAIDelta `json:"delta"`
-		FinishReason string      `json:"finish_reason"`
-	} `json:"choices"`
+	Model    string               `json:"model"`
+	Provider string               `json:"provider"`
+	Choices  []openAIStreamChoice `json:"choices"`
+	// Error is sent mid-stream by TGI (a string) and OpenRouter (an object).
+	Error json.RawMessage `json:"error"`
+}
+
+type openAIStreamChoice struct {
+	Delta        openAIDelta `json:"delta"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// isStopReason reports whether a finish_reason ends the answer; TGI uses its own values.
+func isStopReason(reason string) bool {
+	switch reason {
+	case "stop", "eos_token", "stop_sequence", "length":
+		return true
+	default:
+		return false
+	}
+}
+
+// errorMessage extracts a readable message from an in-stream error event.
+func (c openAIStreamChunk) errorMessage() string {
+	if len(c.Error) == 0 || string(c.Error) == "null" {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(c.Error, &text); err == nil {
+		return text
+	}
+	var obj struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(c.Error, &obj); err == nil && obj.Message != "" {
+		return obj.Message
+	}
+	return string(c.Error)
+}
+
+// sseErrorMessage extracts a readable message from the data of an "error" event.
+func sseErrorMessage(data string) string {
+	var chunk openAIStreamChunk
+	if err := json.Unmarshal([]byte(data), &chunk); err == nil {
+		if message := chunk.errorMessage(); message != "" {
+			return message
+		}
+	}
+	var obj struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(data), &obj); err == nil && obj.Message != "" {
+		return obj.Message
+	}
+	return data
 }
 
 type openAIDelta struct {
@@ -344,23 +763,63 @@ type openAIToolCallDelta struct {
 	Function openAIToolFunction `json:"function"`
 }
 
-func buildOpenAIMessages(req ChatRequest) []openAIMessage {
+func buildOpenAIMessages(req ChatRequest, toolsInPrompt bool) []openAIMessage {
 	messages := make([]openAIMessage, 0, len(req.Messages)+1)
-	if strings.TrimSpace(req.SystemPrompt) != "" {
+	systemPrompt := req.SystemPrompt
+	if toolsInPrompt {
+		systemPrompt = enhanceSystemPromptWithToolSchema(systemPrompt, req.Tools)
+	}
+	if strings.TrimSpace(systemPrompt) != "" {
 		messages = append(messages, openAIMessage{
 			Role:    "system",
-			Content: req.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
 	for _, msg := range req.Messages {
-		messages = append(messages, openAIMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+		if toolsInPrompt {
+			switch {
+			case msg.Role == "tool":
+				messages = append(messages, promptToolResult(msg.ToolName, msg.Content))
+			case len(msg.ToolCalls) > 0:
+				messages = append(messages, openAIMessage{Role: msg.Role, Content: replayedToolCallContent(msg)})
+			default:
+				messages = append(messages, openAIMessage{Role: msg.Role, Content: msg.Content})
+			}
+			continue
+		}
+		out := openAIMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+			Name:       msg.ToolName,
+		}
+		for _, call := range msg.ToolCalls {
+			out.ToolCalls = append(out.ToolCalls, openAIToolCall{
+				ID:   call.ID,
+				Type: "function",
+				Function: openAIToolFunction{
+					Name:      call.Name(),
+					Arguments: encodeToolArguments(call.Arguments),
+				},
+			})
+		}
+		messages = append(messages, out)
 	}
 	return messages
 }
 
+// encodeToolArguments renders replayed tool arguments as the JSON string providers expect.
+func encodeToolArguments(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
 func buildOpenAITools(defs []ToolDefinition) ([]openAITool, map[string]ToolDefinition) {
 	if len(defs) == 0 {
 		return nil, nil
@@ -483,8 +942,19 @@ func (r toolCallRequest) arguments() (map[string]any, error) {
 }
 
 type ollamaProvider struct {
-	client  HTTPClient
-	baseURL string
+	client   HTTPClient
+	baseURL  string
+	sampling samplingOptions
+	// toolsInPrompt embeds the tool schemas in the system prompt; otherwise tools are
+	// sent through Ollama's tools field (the native strategy).
+	toolsInPrompt bool
+	// constrainCalls sends a function call JSON schema as the format of answers that are
+	// expected to call a tool, so small models cannot produce malformed call JSON.
+	constrainCalls bool
+	// repairs is how many times a tool call written as invalid JSON is sent back for correction.
+	repairs int
+	// tools caches encoded tools arrays across turns.
+	tools *toolSchemaCache
 }
 
 type ollamaMessage struct {
@@ -498,8 +968,10 @@ type ollamaRequestPayload struct {
 	Model    string          `json:"model"`
 	Stream   bool            `json:"stream"`
 	Messages []ollamaMessage `json:"messages"`
-	Tools    []openAITool    `json:"tools,omitempty"`
+	Tools    json.RawMessage `json:"tools,omitempty"`
 	Options  map[string]any  `json:"options,omitempty"`
+	Think    any             `json:"think,omitempty"`
+	Format   any             `json:"format,omitempty"`
 }
 
 type ollamaToolFunction struct {
@@ -533,6 +1005,7 @@ type ollamaStreamChunk struct {
 	InternalThoughts  json.RawMessage `json:"internal_thoughts"`
 	InternalMonologue json.RawMessage `json:"internal_monologue"`
 	Error             string          `json:"error"`
+	DoneReason        string          `json:"done_reason"`
 }
 
 type ollamaOutgoingToolCall struct {
@@ -583,34 +1056,58 @@ func (tc ollamaRawToolCall) toOpenAIToolCall() openAIToolCall {
 }
 
 func (p *ollamaProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
-	stream := make(chan StreamChunk)
+	stream := newChunkStream(req.TurnID)
 
-	messages := buildOllamaMessages(req)
-	_, definitions := buildOpenAITools(req.Tools)
+	messages := buildOllamaMessages(req, p.toolsInPrompt)
+	encoded, err := p.tools.encode(req.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("encode tools: %w", err)
+	}
+	tools, definitions := encoded.payload, encoded.definitions
+	var format any
+	if p.toolsInPrompt {
+		tools = nil
+		if p.constrainCalls && req.ExpectToolCall && len(req.Tools) > 0 {
+			format = toolCallFormat(req.Tools)
+		}
+	}
 
 	go func() {
-		defer close(stream)
+		defer stream.close()
 
 		thinkingSent := false
+		repaired := 0
 		for {
-			result, err := p.streamOnce(ctx, req.Model, true, messages, stream, &thinkingSent, definitions)
+			result, err := p.streamOnce(ctx, req.Model, true, messages, tools, format, stream, &thinkingSent, definitions)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
 					return
 				}
-				stream <- StreamChunk{Type: ChunkError, Err: err}
+				stream.send(StreamChunk{Type: ChunkError, Err: err})
 				return
 			}
 
 			messages = append(messages, result.assistantMessage)
 
 			if len(result.toolCalls) == 0 {
-				stream <- StreamChunk{Type: ChunkDone}
+				if p.toolsInPrompt && len(definitions) > 0 && repaired < p.repairs {
+					if err := malformedToolCall(result.assistantMessage.Content); err != nil {
+						repaired++
+						logToolCallRepair(ctx, repaired, err)
+						stream.send(StreamChunk{Type: ChunkRetry, Content: "invalid tool call JSON"})
+						messages = append(messages, ollamaMessage{Role: "user", Content: toolCallRepairPrompt(err)})
+						continue
+					}
+				}
+				stream.send(StreamChunk{Type: ChunkDone, FinishReason: result.finishReason})
 				return
 			}
 
+			// Only the call itself is constrained; the answer after the tool result is free text.
+			format = nil
+
 			if len(definitions) == 0 {
-				stream <- StreamChunk{Type: ChunkError, Err: fmt.Errorf("ollama requested tool call but no tool definitions provided")}
+				stream.send(StreamChunk{Type: ChunkError, Err: fmt.Errorf("ollama requested tool call but no tool definitions provided")})
 				return
 			}
 
@@ -620,7 +1117,7 @@ func (p *ollamaProvider) Stream(ctx context.Context, req ChatRequest) (<-chan St
 					if errors.Is(err, context.Canceled) {
 						return
 					}
-					stream <- StreamChunk{Type: ChunkError, Err: err}
+					stream.send(StreamChunk{Type: ChunkError, Err: err})
 					return
 				}
 				messages = append(messages, toolMessage)
@@ -628,12 +1125,13 @@ func (p *ollamaProvider) Stream(ctx context.Context, req ChatRequest) (<-chan St
 		}
 	}()
 
-	return stream, nil
+	return stream.ch, nil
 }
 
 type ollamaPassResult struct {
 	assistantMessage ollamaMessage
 	toolCalls        []toolCallRequest
+	finishReason     string
 }
 
 func (p *ollamaProvider) streamOnce(
@@ -641,11 +1139,13 @@ func (p *ollamaProvider) streamOnce(
 	model string,
 	streaming bool,
 	messages []ollamaMessage,
-	stream chan<- StreamChunk,
+	tools json.RawMessage,
+	format any,
+	stream *chunkStream,
 	thinkingSent *bool,
 	definitions map[string]ToolDefinition,
 ) (*ollamaPassResult, error) {
-	payload, err := buildOllamaPayload(model, messages, streaming)
+	payload, err := buildOllamaPayload(model, messages, tools, format, streaming, p.sampling)
 	if err != nil {
 		return nil, err
 	}
@@ -661,46 +1161,50 @@ func (p *ollamaProvider) streamOnce(
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.client.Do(httpReq)
+	resp, err := doHTTP(p.client, httpReq, payload)
 	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			return nil, err
-		}
-		return nil, err
+		return nil, networkError(err)
 	}
 	if resp.StatusCode >= 300 {
 		defer resp.Body.Close()
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, fmt.Errorf("ollama response %d: %s", resp.StatusCode, string(body))
+		return nil, responseError("ollama", resp.StatusCode, body)
 	}
+	resp.Body = spoolBody(resp.Body, spoolMemoryLimit)
 	defer resp.Body.Close()
 
 	if !*thinkingSent {
-		stream <- StreamChunk{Type: ChunkThinking}
+		stream.send(StreamChunk{Type: ChunkThinking})
 		*thinkingSent = true
 	}
 
-	decoder := json.NewDecoder(resp.Body)
+	scanner := newObjectScanner(resp.Body)
 	var (
-		builder   strings.Builder
-		toolCalls []openAIToolCall
-		assistant = ollamaMessage{Role: "assistant"}
+		builder      strings.Builder
+		toolCalls    []openAIToolCall
+		assistant    = ollamaMessage{Role: "assistant"}
+		finishReason string
+		decoded      bool
+		stray        []string
 	)
 
-	for {
-		var chunk ollamaStreamChunk
-		if err := decoder.Decode(&chunk); err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if errors.Is(err, context.Canceled) {
-				return nil, err
+	for scanner.Scan() {
+		token := scanner.Bytes()
+		if token[0] != '{' {
+			stray = append(stray, string(token))
+			if logger != nil {
+				logger.Debugf("Skipping non-JSON Ollama stream data: %q", token)
 			}
-			return nil, err
+			continue
+		}
+		var chunk ollamaStreamChunk
+		if err := json.Unmarshal(token, &chunk); err != nil {
+			return nil, fmt.Errorf("decode ollama chunk: %w", err)
 		}
+		decoded = true
 
 		if chunk.Error != "" {
-			stream <- StreamChunk{Type: ChunkError, Err: errors.New(chunk.Error)}
+			stream.send(StreamChunk{Type: ChunkError, Err: streamError(chunk.Error)})
 			continue
 		}
 
@@ -718,7 +1222,7 @@ func (p *ollamaProvider) streamOnce(
 		)
 
 		if chunk.Message.Content != "" {
-			stream <- StreamChunk{Type: ChunkToken, Content: chunk.Message.Content}
+			stream.send(StreamChunk{Type: ChunkToken, Content: chunk.Message.Content})
 			builder.WriteString(chunk.Message.Content)
 		}
 
@@ -730,9 +1234,16 @@ func (p *ollamaProvider) streamOnce(
 		}
 
 		if chunk.Done {
+			finishReason = chunk.DoneReason
 			break
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !decoded && len(stray) > 0 {
+		return nil, fmt.Errorf("unexpected ollama response: %s", strings.Join(stray, "\n"))
+	}
 
 	assistant.Content = builder.String()
 	if len(toolCalls) == 0 {
@@ -742,8 +1253,10 @@ func (p *ollamaProvider) streamOnce(
 		}
 	}
 
-	if len(toolCalls) > 0 {
-		callContent := formatOllamaToolCallContent(toolCalls, definitions)
+	if len(toolCalls) > 0 && !p.toolsInPrompt {
+		assistant.ToolCalls = ollamaOutgoingToolCalls(toolCalls)
+	} else if len(toolCalls) > 0 {
+		callContent := formatToolCallContent(toolCalls, definitions)
 		if callContent != "" {
 			content := strings.TrimSpace(assistant.Content)
 			if content == "" {
@@ -766,7 +1279,7 @@ func (p *ollamaProvider) streamOnce(
 	}
 	if len(toolCalls) == 0 {
 		logResponse(0)
-		return &ollamaPassResult{assistantMessage: assistant}, nil
+		return &ollamaPassResult{assistantMessage: assistant, finishReason: finishReason}, nil
 	}
 
 	requests := make([]toolCallRequest, 0, len(toolCalls))
@@ -783,7 +1296,7 @@ func (p *ollamaProvider) streamOnce(
 
 func (p *ollamaProvider) awaitToolResult(
 	ctx context.Context,
-	stream chan<- StreamChunk,
+	stream *chunkStream,
 	definitions map[string]ToolDefinition,
 	call toolCallRequest,
 ) (ollamaMessage, error) {
@@ -813,7 +1326,7 @@ func (p *ollamaProvider) awaitToolResult(
 		},
 	}
 
-	stream <- StreamChunk{Type: ChunkToolCall, ToolCall: tc}
+	stream.send(StreamChunk{Type: ChunkToolCall, ToolCall: tc})
 
 	var result ToolResult
 	select {
@@ -835,13 +1348,16 @@ func (p *ollamaProvider) awaitToolResult(
 }
 
 func buildOllamaRequest(req ChatRequest) ([]byte, error) {
-	messages := buildOllamaMessages(req)
-	return buildOllamaPayload(req.Model, messages, req.Stream)
+	messages := buildOllamaMessages(req, true)
+	return buildOllamaPayload(req.Model, messages, nil, nil, req.Stream, samplingOptions{})
 }
 
-func buildOllamaMessages(req ChatRequest) []ollamaMessage {
+func buildOllamaMessages(req ChatRequest, toolsInPrompt bool) []ollamaMessage {
 	messages := make([]ollamaMessage, 0, len(req.Messages)+1)
-	systemPrompt := enhanceSystemPromptWithToolSchema(req.SystemPrompt, req.Tools)
+	systemPrompt := req.SystemPrompt
+	if toolsInPrompt {
+		systemPrompt = enhanceSystemPromptWithToolSchema(systemPrompt, req.Tools)
+	}
 	if strings.TrimSpace(systemPrompt) != "" {
 		messages = append(messages, ollamaMessage{
 			Role:    "system",
@@ -849,17 +1365,69 @@ func buildOllamaMessages(req ChatRequest) []ollamaMessage {
 		})
 	}
 	for _, msg := range req.Messages {
-		messages = append(messages, ollamaMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+		out := ollamaMessage{
+			Role:     msg.Role,
+			Content:  msg.Content,
+			ToolName: msg.ToolName,
+		}
+		if len(msg.ToolCalls) > 0 {
+			if toolsInPrompt {
+				out.Content = replayedToolCallContent(msg)
+			} else {
+				for _, call := range msg.ToolCalls {
+					out.ToolCalls = append(out.ToolCalls, ollamaOutgoingToolCall{
+						ID:       call.ID,
+						Type:     "function",
+						Function: ollamaOutgoingToolSignature{Name: call.Name(), Arguments: call.Arguments},
+					})
+				}
+			}
+		}
+		messages = append(messages, out)
 	}
 	return messages
 }
 
+// replayedToolCallContent mirrors the live loop of the prompt-based strategies, which
+// records tool calls as JSON in the assistant content.
+func replayedToolCallContent(msg Message) string {
+	calls := make([]openAIToolCall, 0, len(msg.ToolCalls))
+	definitions := make(map[string]ToolDefinition, len(msg.ToolCalls))
+	for _, call := range msg.ToolCalls {
+		calls = append(calls, openAIToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: openAIToolFunction{Name: call.Name(), Arguments: encodeToolArguments(call.Arguments)},
+		})
+		definitions[call.Name()] = ToolDefinition{Name: call.Name(), Server: call.Server, Method: call.Method}
+	}
+	callContent := formatToolCallContent(calls, definitions)
+	if content := strings.TrimSpace(msg.Content); content != "" {
+		callContent = content + "\n\n" + callContent
+	}
+	return callContent
+}
+
+// ollamaOutgoingToolCalls converts parsed tool calls for Ollama's tool_calls field.
+func ollamaOutgoingToolCalls(calls []openAIToolCall) []ollamaOutgoingToolCall {
+	out := make([]ollamaOutgoingToolCall, 0, len(calls))
+	for _, call := range calls {
+		args, err := toolCallRequest{Call: call}.arguments()
+		if err != nil {
+			args = map[string]any{}
+		}
+		out = append(out, ollamaOutgoingToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: ollamaOutgoingToolSignature{Name: call.Function.Name, Arguments: args},
+		})
+	}
+	return out
+}
+
 func enhanceSystemPromptWithToolSchema(prompt string, defs []ToolDefinition) string {
 	prompt = strings.TrimSpace(prompt)
-	schema := buildToolSchemaPrompt(defs)
+	schema := ToolSchemaPrompt(defs)
 	if schema == "" {
 		return prompt
 	}
@@ -869,7 +1437,9 @@ func enhanceSystemPromptWithToolSchema(prompt string, defs []ToolDefinition) str
 	return prompt + "\n\n" + schema
 }
 
-func buildToolSchemaPrompt(defs []ToolDefinition) string {
+// ToolSchemaPrompt describes the tools in the text form embedded into the system prompt
+// of models without native tool calling.
+func ToolSchemaPrompt(defs []ToolDefinition) string {
 	type toolEntry struct {
 		name        string
 		description string
@@ -954,163 +1524,58 @@ func buildToolSchemaPrompt(defs []ToolDefinition) string {
 	return strings.TrimRight(builder.String(), "\n")
 }
 
-func buildOllamaPayload(model string, messages []ollamaMessage, stream bool) ([]byte, error) {
+func buildOllamaPayload(model string, messages []ollamaMessage, tools json.RawMessage, format any, stream bool, sampling samplingOptions) ([]byte, error) {
 	payload := ollamaRequestPayload{
 		Model:    model,
 		Stream:   stream,
 		Messages: messages,
+		Tools:    tools,
+		Format:   format,
 		Options: map[string]any{
 			"temperature": defaultTemperature,
 		},
+		Think: sampling.think,
 	}
-	return json.Marshal(payload)
-}
-
-func parseManualToolCall(content string) ([]openAIToolCall, string) {
-	cleaned := content
-	var calls []openAIToolCall
-
-	for {
-		blocks := findJSONBlocks(cleaned)
-		if len(blocks) == 0 {
-			break
-		}
-
-		parsed := false
-		for _, block := range blocks {
-			call, ok := parseToolCallJSON(cleaned[block.start:block.end])
-			if !ok {
-				continue
-			}
-			calls = append(calls, call)
-			cleaned = removeJSONBlock(cleaned, block.start, block.end)
-			parsed = true
-			break
-		}
-
-		if !parsed {
-			break
-		}
+	if sampling.seed != nil {
+		payload.Options["seed"] = *sampling.seed
 	}
-
-	if len(calls) == 0 {
-		return nil, content
+	for key, value := range sampling.extra {
+		payload.Options[key] = value
 	}
-	return calls, cleaned
-}
-
-type jsonBlock struct {
-	start int
-	end   int
+	return json.Marshal(payload)
 }
 
-func findJSONBlocks(text string) []jsonBlock {
-	var (
-		blocks   []jsonBlock
-		depth    int
-		start    = -1
-		inString bool
-		escape   bool
-	)
-
-	for i := 0; i < len(text); i++ {
-		ch := text[i]
-		if inString {
-			if escape {
-				escape = false
-				continue
-			}
-			if ch == '\\' {
-				escape = true
-				continue
-			}
-			if ch == '"' {
-				inString = false
-			}
-			continue
-		}
-
-		switch ch {
-		case '"':
-			inString = true
-		case '{':
-			if depth == 0 {
-				start = i
-			}
-			depth++
-		case '}':
-			if depth == 0 {
-				continue
-			}
-			depth--
-			if depth == 0 && start >= 0 {
-				blocks = append(blocks, jsonBlock{start: start, end: i + 1})
-			}
+// toolCallFormat builds the Ollama format schema for the FUNCTION_CALL answer. The name is
+// limited to the offered tools; a single tool also pins the arguments to its input schema.
+func toolCallFormat(defs []ToolDefinition) map[string]any {
+	names := make([]any, 0, len(defs))
+	servers := make([]any, 0, len(defs))
+	seen := map[string]bool{}
+	for _, def := range defs {
+		names = append(names, def.Name)
+		if !seen[def.Server] {
+			seen[def.Server] = true
+			servers = append(servers, def.Server)
 		}
 	}
-	return blocks
-}
-
-func parseToolCallJSON(raw string) (openAIToolCall, bool) {
-	var payload struct {
-		Name      string          `json:"name"`
-		Arguments json.RawMessage `json:"arguments"`
-	}
-	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
-		return openAIToolCall{}, false
-	}
-	name := strings.TrimSpace(payload.Name)
-	if name == "" {
-		return openAIToolCall{}, false
-	}
-
-	argText := strings.TrimSpace(string(payload.Arguments))
-	if argText == "" || argText == "null" {
-		argText = "{}"
+	arguments := map[string]any{"type": "object"}
+	if len(defs) == 1 {
+		if params := cloneAnyMap(defs[0].Parameters); params != nil {
+			arguments = params
+		}
 	}
-
-	return openAIToolCall{
-		Type: "function",
-		Function: openAIToolFunction{
-			Name:      name,
-			Arguments: argText,
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"server":    map[string]any{"type": "string", "enum": servers},
+			"name":      map[string]any{"type": "string", "enum": names},
+			"arguments": arguments,
 		},
-	}, true
-}
-
-func removeJSONBlock(content string, start, end int) string {
-	left := strings.TrimRight(content[:start], " \t\r\n")
-	left = trimTrailingFence(left)
-	right := strings.TrimLeft(content[end:], " \t\r\n")
-	right = trimLeadingFence(right)
-
-	switch {
-	case left == "":
-		return strings.TrimSpace(right)
-	case right == "":
-		return strings.TrimSpace(left)
-	default:
-		return strings.TrimSpace(left + "\n\n" + right)
-	}
-}
-
-func trimTrailingFence(s string) string {
-	s = strings.TrimRight(s, " \t\r\n")
-	if strings.HasSuffix(s, "```") {
-		return strings.TrimRight(s[:len(s)-3], " \t\r\n")
-	}
-	return s
-}
-
-func trimLeadingFence(s string) string {
-	s = strings.TrimLeft(s, " \t\r\n")
-	if strings.HasPrefix(s, "```") {
-		return strings.TrimLeft(s[3:], " \t\r\n")
+		"required": []any{"name", "arguments"},
 	}
-	return s
 }
 
-func formatOllamaToolCallContent(calls []openAIToolCall, definitions map[string]ToolDefinition) string {
+func formatToolCallContent(calls []openAIToolCall, definitions map[string]ToolDefinition) string {
 	if len(calls) == 0 {
 		return ""
 	}
@@ -1175,10 +1640,10 @@ func extractReasoningSegments(raw json.RawMessage) []string {
 	return out
 }
 
-func emitReasoningChunks(stream chan<- StreamChunk, raws ...json.RawMessage) {
+func emitReasoningChunks(stream *chunkStream, raws ...json.RawMessage) {
 	for _, raw := range raws {
 		for _, text := range extractReasoningSegments(raw) {
-			stream <- StreamChunk{Type: ChunkThinking, Content: text}
+			stream.send(StreamChunk{Type: ChunkThinking, Content: text})
 		}
 	}
 }
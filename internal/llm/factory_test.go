@@ -7,8 +7,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -958,3 +961,1067 @@ func (l *recordingLogger) Entries() []string {
 	copy(out, l.entries)
 	return out
 }
+
+func TestProvidersSendConfiguredSeed(t *testing.T) {
+	t.Parallel()
+
+	seed := int64(42)
+	var (
+		mu       sync.Mutex
+		payloads = map[string]map[string]any{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+			return
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("unmarshal request: %v", err)
+			return
+		}
+		mu.Lock()
+		payloads[r.URL.Path] = payload
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/chat/completions":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"ok"},"finish_reason":"stop"}]}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+		case "/api/chat":
+			io.WriteString(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`+"\n")
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	factory := NewFactory(server.Client())
+	for _, model := range []config.Model{
+		{Name: "gpt-4.1", Provider: "openai", APIKey: "sk-test", BaseURL: server.URL, Seed: &seed},
+		{Name: "llama3", Provider: "ollama", BaseURL: server.URL, Seed: &seed},
+	} {
+		provider, err := factory.Create(model)
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		stream, err := provider.Stream(ctx, ChatRequest{
+			Model:    model.Name,
+			Messages: []Message{{Role: "user", Content: "hi"}},
+			Stream:   true,
+		})
+		if err != nil {
+			cancel()
+			t.Fatalf("stream %s: %v", model.Provider, err)
+		}
+		for range stream {
+		}
+		cancel()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := payloads["/chat/completions"]["seed"]; got != float64(42) {
+		t.Fatalf("expected openai seed 42, got %v", got)
+	}
+	options, ok := payloads["/api/chat"]["options"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected ollama options, got %v", payloads["/api/chat"])
+	}
+	if got := options["seed"]; got != float64(42) {
+		t.Fatalf("expected ollama seed 42, got %v", got)
+	}
+	if got := options["temperature"]; got != 0.1 {
+		t.Fatalf("expected temperature 0.1, got %v", got)
+	}
+}
+
+func TestBuildOllamaRequestOmitsSeedByDefault(t *testing.T) {
+	data, err := buildOllamaRequest(ChatRequest{
+		Model:    "llama3",
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("buildOllamaRequest returned error: %v", err)
+	}
+	if strings.Contains(string(data), `"seed"`) {
+		t.Fatalf("expected no seed in payload, got %s", data)
+	}
+}
+
+func TestProvidersMergeExtraParams(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		payloads = map[string]map[string]any{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		payloads[r.URL.Path] = payload
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/chat/completions":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"ok"},"finish_reason":"stop"}]}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+		case "/api/chat":
+			io.WriteString(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`+"\n")
+		}
+	}))
+	defer server.Close()
+
+	extra := map[string]any{
+		"frequency_penalty": 0.5,
+		"temperature":       0.7,
+		"model":             "ignored",
+	}
+	factory := NewFactory(server.Client())
+	for _, model := range []config.Model{
+		{Name: "gpt-4.1", Provider: "openai", APIKey: "sk-test", BaseURL: server.URL, ExtraParams: extra},
+		{Name: "llama3", Provider: "ollama", BaseURL: server.URL, ExtraParams: map[string]any{"num_ctx": 8192, "temperature": 0.7}},
+	} {
+		provider, err := factory.Create(model)
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		stream, err := provider.Stream(ctx, ChatRequest{
+			Model:    model.Name,
+			Messages: []Message{{Role: "user", Content: "hi"}},
+			Stream:   true,
+		})
+		if err != nil {
+			cancel()
+			t.Fatalf("stream %s: %v", model.Provider, err)
+		}
+		for range stream {
+		}
+		cancel()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	openAI := payloads["/chat/completions"]
+	if openAI["frequency_penalty"] != 0.5 || openAI["temperature"] != 0.7 {
+		t.Fatalf("expected extra params in openai payload, got %v", openAI)
+	}
+	if openAI["model"] != "gpt-4.1" {
+		t.Fatalf("expected reserved model field to be kept, got %v", openAI["model"])
+	}
+	options, ok := payloads["/api/chat"]["options"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected ollama options, got %v", payloads["/api/chat"])
+	}
+	if options["num_ctx"] != float64(8192) || options["temperature"] != 0.7 {
+		t.Fatalf("expected extra params in ollama options, got %v", options)
+	}
+}
+
+func TestProvidersSendReasoningSettings(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		payloads = map[string]map[string]any{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		payloads[r.URL.Path] = payload
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/chat/completions":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"ok"},"finish_reason":"stop"}]}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+		case "/api/chat":
+			io.WriteString(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`+"\n")
+		}
+	}))
+	defer server.Close()
+
+	factory := NewFactory(server.Client())
+	for _, model := range []config.Model{
+		{Name: "o3", Provider: "openai", APIKey: "sk-test", BaseURL: server.URL, ReasoningEffort: "High"},
+		{Name: "qwen3", Provider: "ollama", BaseURL: server.URL, ReasoningEffort: "none"},
+	} {
+		provider, err := factory.Create(model)
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		stream, err := provider.Stream(ctx, ChatRequest{
+			Model:    model.Name,
+			Messages: []Message{{Role: "user", Content: "hi"}},
+			Stream:   true,
+		})
+		if err != nil {
+			cancel()
+			t.Fatalf("stream %s: %v", model.Provider, err)
+		}
+		for range stream {
+		}
+		cancel()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := payloads["/chat/completions"]["reasoning_effort"]; got != "high" {
+		t.Fatalf("expected openai reasoning_effort high, got %v", got)
+	}
+	if got, ok := payloads["/api/chat"]["think"]; !ok || got != false {
+		t.Fatalf("expected ollama think=false, got %v", payloads["/api/chat"])
+	}
+}
+
+func TestReasoningParamsPerAPI(t *testing.T) {
+	anthropic := openAIReasoningParams(config.Model{ThinkingBudget: 4096}, "https://api.anthropic.com/v1")
+	thinking, ok := anthropic["thinking"].(map[string]any)
+	if !ok || thinking["type"] != "enabled" || thinking["budget_tokens"] != 4096 {
+		t.Fatalf("expected anthropic thinking budget, got %v", anthropic)
+	}
+	if params := openAIReasoningParams(config.Model{ThinkingBudget: 4096}, "https://api.openai.com/v1"); len(params) != 0 {
+		t.Fatalf("expected openai to ignore thinking budget, got %v", params)
+	}
+
+	cases := []struct {
+		model config.Model
+		want  map[string]any
+	}{
+		{config.Model{ReasoningEffort: "medium"}, map[string]any{"effort": "medium"}},
+		{config.Model{ReasoningEffort: "none"}, map[string]any{"enabled": false}},
+		{config.Model{ReasoningEffort: "high", ThinkingBudget: 2000}, map[string]any{"max_tokens": 2000}},
+		{config.Model{}, nil},
+	}
+	for _, tc := range cases {
+		if got := openRouterReasoning(tc.model); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("openRouterReasoning(%+v) = %v, want %v", tc.model, got, tc.want)
+		}
+	}
+
+	if got := ollamaThink(config.Model{ReasoningEffort: "minimal"}); got != "low" {
+		t.Fatalf("expected minimal effort to map to low, got %v", got)
+	}
+	if got := ollamaThink(config.Model{ThinkingBudget: 1024}); got != true {
+		t.Fatalf("expected a budget to enable ollama thinking, got %v", got)
+	}
+	if got := ollamaThink(config.Model{}); got != nil {
+		t.Fatalf("expected no think flag by default, got %v", got)
+	}
+}
+
+func TestOpenRouterProviderSendsRoutingFieldsAndReportsUpstreamModel(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer or-key" {
+			t.Errorf("unexpected authorization header: %q", got)
+		}
+		if r.Header.Get("HTTP-Referer") == "" || r.Header.Get("X-Title") != "humble-ai-cli" {
+			t.Errorf("missing openrouter attribution headers: %v", r.Header)
+		}
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		prefs, ok := payload["provider"].(map[string]any)
+		if !ok || prefs["allow_fallbacks"] != false {
+			t.Errorf("expected provider preferences, got %v", payload["provider"])
+		}
+		models, ok := payload["models"].([]any)
+		if !ok || len(models) != 2 || models[0] != "openai/gpt-4o" || models[1] != "anthropic/claude-3.5-sonnet" {
+			t.Errorf("expected fallback models, got %v", payload["models"])
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"model":"anthropic/claude-3.5-sonnet","provider":"Anthropic","choices":[{"delta":{"content":"Hi"}}]}`+"\n\n")
+		io.WriteString(w, `data: {"model":"anthropic/claude-3.5-sonnet","provider":"Anthropic","choices":[{"delta":{},"finish_reason":"stop"}]}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	factory := NewFactory(server.Client())
+	model := config.Model{
+		Name:                "openai/gpt-4o",
+		Provider:            "openrouter",
+		APIKey:              "or-key",
+		BaseURL:             server.URL,
+		ProviderPreferences: map[string]any{"allow_fallbacks": false},
+		FallbackModels:      []string{"anthropic/claude-3.5-sonnet"},
+	}
+	provider, err := factory.Create(model)
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{
+		Model:    model.Name,
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	var routings []RoutingInfo
+	var content strings.Builder
+	for chunk := range stream {
+		switch chunk.Type {
+		case ChunkRouting:
+			routings = append(routings, *chunk.Routing)
+		case ChunkToken:
+			content.WriteString(chunk.Content)
+		case ChunkError:
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+	}
+	if len(routings) != 1 || routings[0].Model != "anthropic/claude-3.5-sonnet" || routings[0].Provider != "Anthropic" {
+		t.Fatalf("unexpected routing chunks: %#v", routings)
+	}
+	if content.String() != "Hi" {
+		t.Fatalf("unexpected content: %q", content.String())
+	}
+}
+
+func TestOpenRouterProviderRequiresAPIKey(t *testing.T) {
+	if _, err := NewFactory(nil).Create(config.Model{Name: "openai/gpt-4o", Provider: "openrouter"}); err == nil {
+		t.Fatal("expected error for missing apiKey")
+	}
+}
+
+func TestTGIProviderHandlesStreamQuirks(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer hf_token" {
+			t.Errorf("unexpected authorization header: %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		// TGI omits the space after "data:", sends null content and ends with eos_token without [DONE].
+		io.WriteString(w, `data:{"model":"tgi","choices":[{"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}`+"\n\n")
+		io.WriteString(w, `data:{"model":"tgi","choices":[{"delta":{"role":"assistant","content":null},"finish_reason":"eos_token"}]}`+"\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{
+		Name:     "tgi",
+		Provider: "tgi",
+		APIKey:   "hf_token",
+		BaseURL:  server.URL + "/",
+	})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{Model: "tgi", Messages: []Message{{Role: "user", Content: "hi"}}, Stream: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var content strings.Builder
+	for chunk := range stream {
+		switch chunk.Type {
+		case ChunkToken:
+			content.WriteString(chunk.Content)
+		case ChunkError:
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+	}
+	if content.String() != "Hello" {
+		t.Fatalf("unexpected content: %q", content.String())
+	}
+}
+
+func TestTGIProviderSurfacesInStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("expected no authorization header without apiKey")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data:{"error":"Input validation error: inputs too long","error_type":"validation"}`+"\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "tgi", Provider: "tgi", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{Model: "tgi", Stream: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var streamErr error
+	for chunk := range stream {
+		if chunk.Type == ChunkError {
+			streamErr = chunk.Err
+		}
+	}
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "inputs too long") {
+		t.Fatalf("expected in-stream error, got %v", streamErr)
+	}
+}
+
+func TestProvidersReportLengthFinishReason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		provider string
+		body     string
+	}{
+		{
+			name:     "openai",
+			provider: "openai",
+			body: `data: {"choices":[{"delta":{"content":"Partial"},"finish_reason":null}]}` + "\n\n" +
+				`data: {"choices":[{"delta":{},"finish_reason":"length"}]}` + "\n\n" +
+				"data: [DONE]\n\n",
+		},
+		{
+			name:     "ollama",
+			provider: "ollama",
+			body: `{"message":{"role":"assistant","content":"Partial"},"done":false}` + "\n" +
+				`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"length"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			provider, err := NewFactory(server.Client()).Create(config.Model{
+				Name:     "m",
+				Provider: tt.provider,
+				APIKey:   "sk",
+				BaseURL:  server.URL,
+			})
+			if err != nil {
+				t.Fatalf("create provider: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			stream, err := provider.Stream(ctx, ChatRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}, Stream: true})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			var reason string
+			for chunk := range stream {
+				switch chunk.Type {
+				case ChunkDone:
+					reason = chunk.FinishReason
+				case ChunkError:
+					t.Fatalf("unexpected stream error: %v", chunk.Err)
+				}
+			}
+			if reason != FinishLength {
+				t.Fatalf("expected finish reason %q, got %q", FinishLength, reason)
+			}
+		})
+	}
+}
+
+func TestProviderPayloadsReplayToolCallMessages(t *testing.T) {
+	req := ChatRequest{
+		Model: "m",
+		Messages: []Message{
+			{Role: "user", Content: "weather?"},
+			{Role: "assistant", ToolCalls: []MessageToolCall{{ID: "call_1_0", Server: "weather", Method: "forecast", Arguments: map[string]any{"city": "Seoul"}}}},
+			{Role: "tool", Content: "sunny", ToolCallID: "call_1_0", ToolName: "weather__forecast"},
+			{Role: "assistant", Content: "It is sunny."},
+		},
+	}
+
+	openAI := buildOpenAIMessages(req, false)
+	if len(openAI) != 4 {
+		t.Fatalf("unexpected openai messages: %+v", openAI)
+	}
+	call := openAI[1].ToolCalls
+	if len(call) != 1 || call[0].ID != "call_1_0" || call[0].Function.Name != "weather__forecast" || call[0].Function.Arguments != `{"city":"Seoul"}` {
+		t.Fatalf("unexpected openai tool call: %+v", openAI[1])
+	}
+	if openAI[2].ToolCallID != "call_1_0" || openAI[2].Name != "weather__forecast" {
+		t.Fatalf("unexpected openai tool result: %+v", openAI[2])
+	}
+
+	// Ollama messages start with the tool schema system prompt.
+	ollama := buildOllamaMessages(req, true)[1:]
+	if !strings.Contains(ollama[1].Content, `"name":"weather__forecast"`) || !strings.Contains(ollama[1].Content, `"server":"weather"`) {
+		t.Fatalf("expected tool call JSON in ollama assistant content, got %q", ollama[1].Content)
+	}
+	if ollama[2].Role != "tool" || ollama[2].ToolName != "weather__forecast" {
+		t.Fatalf("unexpected ollama tool result: %+v", ollama[2])
+	}
+}
+
+func TestProvidersFollowToolStrategy(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		payloads = map[string][]map[string]any{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		payloads[r.URL.Path] = append(payloads[r.URL.Path], payload)
+		first := len(payloads[r.URL.Path]) == 1
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/chat/completions" && first:
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"{\"name\":\"weather__forecast\",\"arguments\":{\"city\":\"Seoul\"}}"},"finish_reason":"stop"}]}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+		case r.URL.Path == "/chat/completions":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"It is sunny."},"finish_reason":"stop"}]}`+"\n\n")
+			io.WriteString(w, "data: [DONE]\n\n")
+		case first:
+			io.WriteString(w, `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"weather__forecast","arguments":{"city":"Seoul"}}}]},"done":true}`+"\n")
+		default:
+			io.WriteString(w, `{"message":{"role":"assistant","content":"It is sunny."},"done":true}`+"\n")
+		}
+	}))
+	defer server.Close()
+
+	tools := []ToolDefinition{{Name: "weather__forecast", Description: "Forecast", Server: "weather", Method: "forecast"}}
+	factory := NewFactory(server.Client())
+	for _, model := range []config.Model{
+		{Name: "local", Provider: "openai", APIKey: "sk-test", BaseURL: server.URL, ToolStrategy: "direct"},
+		{Name: "qwen3", Provider: "ollama", BaseURL: server.URL, ToolStrategy: "native"},
+	} {
+		provider, err := factory.Create(model)
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		stream, err := provider.Stream(ctx, ChatRequest{
+			Model:        model.Name,
+			SystemPrompt: "Base prompt.",
+			Messages:     []Message{{Role: "user", Content: "weather?"}},
+			Stream:       true,
+			Tools:        tools,
+		})
+		if err != nil {
+			cancel()
+			t.Fatalf("stream %s: %v", model.Provider, err)
+		}
+		called := false
+		for chunk := range stream {
+			if chunk.Type == ChunkToolCall {
+				called = chunk.ToolCall.Server == "weather" && chunk.ToolCall.Arguments["city"] == "Seoul"
+				if err := chunk.ToolCall.Respond(ctx, ToolResult{Content: "sunny"}); err != nil {
+					t.Errorf("respond: %v", err)
+				}
+			}
+			if chunk.Err != nil {
+				t.Errorf("%s stream error: %v", model.Provider, chunk.Err)
+			}
+		}
+		cancel()
+		if !called {
+			t.Fatalf("expected %s to request weather.forecast", model.Provider)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	direct := payloads["/chat/completions"]
+	if len(direct) != 2 {
+		t.Fatalf("expected 2 openai requests, got %d", len(direct))
+	}
+	if _, ok := direct[0]["tools"]; ok {
+		t.Fatalf("expected no tools field with the direct strategy, got %v", direct[0]["tools"])
+	}
+	messages := direct[0]["messages"].([]any)
+	if system := messages[0].(map[string]any)["content"].(string); !strings.Contains(system, "**weather__forecast**") {
+		t.Fatalf("expected tool schema in the system prompt, got %q", system)
+	}
+	followup := direct[1]["messages"].([]any)
+	last := followup[len(followup)-1].(map[string]any)
+	if last["role"] != "user" || last["content"] != "Result of weather__forecast:\nsunny" {
+		t.Fatalf("expected tool result as a user message, got %v", last)
+	}
+
+	native := payloads["/api/chat"]
+	if len(native) != 2 {
+		t.Fatalf("expected 2 ollama requests, got %d", len(native))
+	}
+	if got, ok := native[0]["tools"].([]any); !ok || len(got) != 1 {
+		t.Fatalf("expected the tools field with the native strategy, got %v", native[0]["tools"])
+	}
+	messages = native[0]["messages"].([]any)
+	if system := messages[0].(map[string]any)["content"].(string); system != "Base prompt." {
+		t.Fatalf("expected the system prompt without tool schema, got %q", system)
+	}
+	followup = native[1]["messages"].([]any)
+	assistant := followup[len(followup)-2].(map[string]any)
+	if calls, ok := assistant["tool_calls"].([]any); !ok || len(calls) != 1 {
+		t.Fatalf("expected the assistant tool_calls to be replayed natively, got %v", assistant)
+	}
+}
+
+func TestOllamaConstrainsExpectedToolCall(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		payloads []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		first := len(payloads) == 1
+		mu.Unlock()
+		if first {
+			io.WriteString(w, `{"message":{"role":"assistant","content":"{\"server\":\"weather\",\"name\":\"weather__forecast\",\"arguments\":{\"city\":\"Seoul\"}}"},"done":true}`+"\n")
+			return
+		}
+		io.WriteString(w, `{"message":{"role":"assistant","content":"It is sunny."},"done":true}`+"\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{
+		Name: "qwen3", Provider: "ollama", BaseURL: server.URL, ToolStrategy: "chooseFunction", ConstrainToolCalls: true,
+	})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	params := map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}}
+	stream, err := provider.Stream(ctx, ChatRequest{
+		Model:          "qwen3",
+		Messages:       []Message{{Role: "user", Content: "weather?"}},
+		Stream:         true,
+		Tools:          []ToolDefinition{{Name: "weather__forecast", Server: "weather", Method: "forecast", Parameters: params}},
+		ExpectToolCall: true,
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	for chunk := range stream {
+		if chunk.Type == ChunkToolCall {
+			if err := chunk.ToolCall.Respond(ctx, ToolResult{Content: "sunny"}); err != nil {
+				t.Errorf("respond: %v", err)
+			}
+		}
+		if chunk.Err != nil {
+			t.Errorf("stream error: %v", chunk.Err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(payloads))
+	}
+	format, ok := payloads[0]["format"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected a format schema on the call request, got %v", payloads[0]["format"])
+	}
+	properties := format["properties"].(map[string]any)
+	if names := properties["name"].(map[string]any)["enum"].([]any); len(names) != 1 || names[0] != "weather__forecast" {
+		t.Fatalf("expected the name to be limited to the offered tool, got %v", names)
+	}
+	if args := properties["arguments"].(map[string]any); args["properties"] == nil {
+		t.Fatalf("expected the arguments to follow the tool input schema, got %v", args)
+	}
+	if _, ok := payloads[1]["format"]; ok {
+		t.Fatalf("expected the answer after the tool result to be unconstrained, got %v", payloads[1]["format"])
+	}
+}
+
+func TestProviderRepairsMalformedToolCall(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		payloads []map[string]any
+	)
+	answers := []string{
+		`{\"name\": \"weather__forecast\", \"arguments\": {\"city\": \"Seoul}}`,
+		`{\"name\": \"weather__forecast\", \"arguments\": {\"city\": \"Seoul\"}}`,
+		`It is sunny.`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		answer := answers[min(len(payloads), len(answers))-1]
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"choices":[{"delta":{"content":"`+answer+`"},"finish_reason":"stop"}]}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{
+		Name: "local", Provider: "openai", APIKey: "sk-test", BaseURL: server.URL, ToolStrategy: "direct", ToolCallRepairs: 1,
+	})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{
+		Model:    "local",
+		Messages: []Message{{Role: "user", Content: "weather?"}},
+		Stream:   true,
+		Tools:    []ToolDefinition{{Name: "weather__forecast", Server: "weather", Method: "forecast"}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	called := false
+	for chunk := range stream {
+		if chunk.Type == ChunkToolCall {
+			called = chunk.ToolCall.Arguments["city"] == "Seoul"
+			if err := chunk.ToolCall.Respond(ctx, ToolResult{Content: "sunny"}); err != nil {
+				t.Errorf("respond: %v", err)
+			}
+		}
+		if chunk.Err != nil {
+			t.Errorf("stream error: %v", chunk.Err)
+		}
+	}
+	if !called {
+		t.Fatalf("expected the corrected call to run")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 3 {
+		t.Fatalf("expected the malformed answer, the corrected call and the answer, got %d requests", len(payloads))
+	}
+	messages := payloads[1]["messages"].([]any)
+	last := messages[len(messages)-1].(map[string]any)
+	if content, _ := last["content"].(string); last["role"] != "user" || !strings.HasPrefix(content, "Your function call JSON was invalid: ") {
+		t.Fatalf("expected a corrective user message, got %v", last)
+	}
+}
+
+func TestOpenAIProviderReusesToolSchemas(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		tools []json.RawMessage
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Tools    json.RawMessage `json:"tools"`
+			Messages []any           `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		tools = append(tools, payload.Tools)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if len(payload.Messages) == 1 {
+			io.WriteString(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"add","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`+"\n\n")
+		} else {
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"3"},"finish_reason":"stop"}]}`+"\n\n")
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	defs := []ToolDefinition{{Name: "add", Description: "Add numbers", Server: "calculator", Method: "add", Parameters: map[string]any{"type": "object"}}}
+	factory := NewFactory(server.Client())
+	for _, omit := range []bool{false, true} {
+		provider, err := factory.Create(config.Model{Name: "gpt", Provider: "openai", APIKey: "sk", BaseURL: server.URL, OmitRepeatedTools: omit})
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		stream, err := provider.Stream(ctx, ChatRequest{
+			Model:    "gpt",
+			Messages: []Message{{Role: "user", Content: "1+2?"}},
+			Stream:   true,
+			Tools:    defs,
+		})
+		if err != nil {
+			cancel()
+			t.Fatalf("stream: %v", err)
+		}
+		for chunk := range stream {
+			if chunk.Type == ChunkToolCall {
+				if err := chunk.ToolCall.Respond(ctx, ToolResult{Content: "3"}); err != nil {
+					t.Errorf("respond: %v", err)
+				}
+			}
+			if chunk.Err != nil {
+				t.Errorf("stream error: %v", chunk.Err)
+			}
+		}
+		cancel()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(tools) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(tools))
+	}
+	for i, got := range tools[:3] {
+		if string(got) != string(tools[0]) || len(got) == 0 {
+			t.Fatalf("request %d: expected the same tools array, got %s", i+1, got)
+		}
+	}
+	if len(tools[3]) != 0 {
+		t.Fatalf("expected the follow-up to omit tools with omitRepeatedTools, got %s", tools[3])
+	}
+	if len(factory.tools.entries) != 1 {
+		t.Fatalf("expected one cached tool set across turns, got %d", len(factory.tools.entries))
+	}
+}
+
+func TestOpenAIProviderFallsBackWhenGatewayDoesNotStream(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		streams []bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Stream   bool  `json:"stream"`
+			Messages []any `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		streams = append(streams, payload.Stream)
+		mu.Unlock()
+
+		// The gateway ignores "stream": true and answers with a whole completion.
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if len(payload.Messages) == 1 {
+			io.WriteString(w, `{"model":"gw","choices":[{"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"add","arguments":"{\"a\":1}"}},{"id":"call_2","type":"function","function":{"name":"add","arguments":"{\"a\":2}"}}]},"finish_reason":"stop"}]}`)
+			return
+		}
+		io.WriteString(w, `{"model":"gw","choices":[{"message":{"role":"assistant","content":"Sum is 3"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "gw", Provider: "openai", APIKey: "sk", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{
+		Model:    "gw",
+		Messages: []Message{{Role: "user", Content: "1+2?"}},
+		Stream:   true,
+		Tools:    []ToolDefinition{{Name: "add", Description: "Add numbers", Server: "calculator", Method: "add", Parameters: map[string]any{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var (
+		tokens    []string
+		arguments []string
+		done      bool
+	)
+	for chunk := range stream {
+		switch chunk.Type {
+		case ChunkToolCall:
+			arguments = append(arguments, fmt.Sprint(chunk.ToolCall.Arguments["a"]))
+			if err := chunk.ToolCall.Respond(ctx, ToolResult{Content: "ok"}); err != nil {
+				t.Errorf("respond: %v", err)
+			}
+		case ChunkToken:
+			tokens = append(tokens, chunk.Content)
+		case ChunkDone:
+			done = true
+		case ChunkError:
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+	}
+	if !done || len(tokens) != 1 || tokens[0] != "Sum is 3" {
+		t.Fatalf("expected the answer as one token followed by done, got %q (done=%v)", tokens, done)
+	}
+	if len(arguments) != 2 || arguments[0] != "1" || arguments[1] != "2" {
+		t.Fatalf("expected both tool calls kept apart, got %v", arguments)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(streams) != 2 || !streams[0] || streams[1] {
+		t.Fatalf("expected a streaming request, then a non-streaming one, got %v", streams)
+	}
+}
+
+func TestOpenAIProviderRetriesWithoutStreamingWhenRejected(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		streams []bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		mu.Lock()
+		streams = append(streams, payload.Stream)
+		mu.Unlock()
+
+		if payload.Stream {
+			http.Error(w, `{"error":{"message":"stream is not supported"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"choices":[{"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	factory := NewFactory(server.Client())
+	for turn := 0; turn < 2; turn++ {
+		provider, err := factory.Create(config.Model{Name: "gw", Provider: "openai", APIKey: "sk", BaseURL: server.URL})
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		stream, err := provider.Stream(ctx, ChatRequest{Model: "gw", Messages: []Message{{Role: "user", Content: "hi"}}, Stream: true})
+		if err != nil {
+			cancel()
+			t.Fatalf("stream: %v", err)
+		}
+		var content strings.Builder
+		for chunk := range stream {
+			if chunk.Type == ChunkError {
+				t.Fatalf("unexpected stream error: %v", chunk.Err)
+			}
+			if chunk.Type == ChunkToken {
+				content.WriteString(chunk.Content)
+			}
+		}
+		cancel()
+		if content.String() != "Hello" {
+			t.Fatalf("turn %d: unexpected content %q", turn+1, content.String())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Once rejected, later turns skip the streaming attempt.
+	if len(streams) != 3 || !streams[0] || streams[1] || streams[2] {
+		t.Fatalf("expected one rejected streaming request and two plain ones, got %v", streams)
+	}
+}
+
+func TestOpenAIProviderKeepsUnrelatedClientErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":{"message":"invalid api key"}}`},
+		{"unauthorized mentioning streams", http.StatusUnauthorized, `{"error":{"message":"key not allowed for streaming requests"}}`},
+		{"rate limited upstream", http.StatusTooManyRequests, `{"error":{"message":"upstream model unavailable, streaming rate limit reached"}}`},
+		{"bad request on another param", http.StatusBadRequest, `{"error":{"message":"unknown field stream_options","param":"stream_options"}}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				http.Error(w, tc.body, tc.status)
+			}))
+			defer server.Close()
+
+			provider, err := NewFactory(server.Client()).Create(config.Model{Name: "gw", Provider: "openai", APIKey: "sk", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("create provider: %v", err)
+			}
+			stream, err := provider.Stream(context.Background(), ChatRequest{Model: "gw", Stream: true})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			var streamErr error
+			for chunk := range stream {
+				if chunk.Type == ChunkError {
+					streamErr = chunk.Err
+				}
+			}
+			if streamErr == nil || !strings.Contains(streamErr.Error(), strconv.Itoa(tc.status)) || calls.Load() != 1 {
+				t.Fatalf("expected the %d without a retry, got %v after %d calls", tc.status, streamErr, calls.Load())
+			}
+		})
+	}
+}
+
+func TestRejectsStreamingRecognizesStreamParam(t *testing.T) {
+	t.Parallel()
+
+	if !rejectsStreaming(http.StatusUnprocessableEntity, []byte(`{"error":{"message":"invalid value","param":"stream"}}`)) {
+		t.Fatalf("expected a 422 naming the stream param to reject streaming")
+	}
+	if !rejectsStreaming(http.StatusBadRequest, []byte(`Streaming is not supported for this model`)) {
+		t.Fatalf("expected a plain-text 400 to reject streaming")
+	}
+}
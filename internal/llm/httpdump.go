@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type contextKeyHTTPDump struct{}
+
+// WithHTTPDump attaches a writer that receives the raw HTTP traffic of provider requests
+// made with ctx: each request with its body and each response as it is read, so event
+// stream frames appear exactly as the server sent them. Credentials in headers are redacted.
+func WithHTTPDump(ctx context.Context, w io.Writer) context.Context {
+	if w == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyHTTPDump{}, w)
+}
+
+func httpDumpFromContext(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(contextKeyHTTPDump{}).(io.Writer); ok {
+		return w
+	}
+	return nil
+}
+
+// dumpedSecrets are headers whose values never reach a dump.
+var dumpedSecrets = map[string]bool{
+	"Authorization": true,
+	"Api-Key":       true,
+	"X-Api-Key":     true,
+	"Cookie":        true,
+}
+
+// doHTTP sends req, copying the traffic to the dump attached to its context, if any.
+func doHTTP(client HTTPClient, req *http.Request, payload []byte) (*http.Response, error) {
+	dump := httpDumpFromContext(req.Context())
+	if dump == nil {
+		return client.Do(req)
+	}
+	fmt.Fprintf(dump, ">>> %s %s\n%s\n%s\n\n", req.Method, req.URL, formatDumpHeaders(req.Header), payload)
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Fprintf(dump, "<<< error: %v\n\n", err)
+		return nil, err
+	}
+	fmt.Fprintf(dump, "<<< %s\n%s\n\n", resp.Status, formatDumpHeaders(resp.Header))
+	resp.Body = dumpBody{ReadCloser: resp.Body, dump: dump}
+	return resp, nil
+}
+
+func formatDumpHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		if dumpedSecrets[http.CanonicalHeaderKey(key)] {
+			value = "[redacted]"
+		}
+		lines = append(lines, key+": "+value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dumpBody copies a response body to the dump as it is read.
+type dumpBody struct {
+	io.ReadCloser
+	dump io.Writer
+}
+
+func (b dumpBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = b.dump.Write(p[:n])
+	}
+	if err != nil && err != io.EOF {
+		fmt.Fprintf(b.dump, "\n<<< read error: %v\n", err)
+	}
+	return n, err
+}
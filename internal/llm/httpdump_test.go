@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// lockedBuffer is written by the request and by the goroutine reading the response.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestHTTPDumpRecordsRawTrafficWithoutCredentials(t *testing.T) {
+	t.Parallel()
+
+	frames := "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\n" +
+		"data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: [DONE]\n\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, frames)
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{
+		Name:     "gpt-4o-mini",
+		Provider: "openai",
+		APIKey:   "sk-secret",
+		BaseURL:  server.URL,
+	})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+
+	dump := &lockedBuffer{}
+	ctx := WithHTTPDump(context.Background(), dump)
+	stream, err := provider.Stream(ctx, ChatRequest{
+		Model:    "gpt-4o-mini",
+		Stream:   true,
+		Messages: []Message{{Role: "user", Content: "Say hi"}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	for range stream {
+	}
+
+	got := dump.String()
+	for _, phrase := range []string{
+		">>> POST " + server.URL + "/chat/completions\n",
+		"Authorization: [redacted]\n",
+		`"content":"Say hi"`,
+		"<<< 200 OK\n",
+		"Content-Type: text/event-stream\n",
+		frames,
+	} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected dump to contain %q, got:\n%s", phrase, got)
+		}
+	}
+	if strings.Contains(got, "sk-secret") {
+		t.Fatalf("expected the API key to be redacted, got:\n%s", got)
+	}
+}
+
+func TestHTTPDumpRecordsFailedRequests(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "llama3.2", Provider: "ollama", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	dump := &lockedBuffer{}
+	stream, err := provider.Stream(WithHTTPDump(context.Background(), dump), ChatRequest{
+		Model:    "llama3.2",
+		Stream:   true,
+		Messages: []Message{{Role: "user", Content: "hello"}},
+	})
+	if err == nil {
+		for range stream {
+		}
+	}
+
+	got := dump.String()
+	for _, phrase := range []string{">>> POST " + server.URL + "/api/chat\n", "<<< 404 Not Found\n", `{"error":"model not found"}`} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected dump to contain %q, got:\n%s", phrase, got)
+		}
+	}
+}
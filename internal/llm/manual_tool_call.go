@@ -0,0 +1,498 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Models without native tool calling write their tool calls into the answer text.
+// parseManualToolCall recognizes the shapes local models commonly produce:
+//
+//	{"name": "docs__read", "arguments": {...}}                       the FUNCTION_CALL schema
+//	<tool_call>{"name": "docs__read", "arguments": {...}}</tool_call>  Hermes and Qwen templates
+//	<tool_call><name>docs__read</name><arguments>{...}</arguments></tool_call>
+//	<function=docs__read>{...}</function>                              Llama 3.1
+//	{"functionCall": [{"name": "docs__read", "args": {...}}]}          Gemini-style, object or array
+//	{"tool_calls": [{"function": {"name": "docs__read", "arguments": "{...}"}}]}
+//	[{"name": "docs__read", "arguments": {...}}, ...]                  several calls at once
+//	{'name': 'docs__read', 'arguments': {'raw': True}}                python dict literals
+
+var (
+	toolCallTagPattern     = regexp.MustCompile(`(?s)<(tool_call|function_call)>(.*?)</(?:tool_call|function_call)>`)
+	functionTagPattern     = regexp.MustCompile(`(?s)<function=([\w.\-]+)>(.*?)</function>`)
+	xmlToolNamePattern     = regexp.MustCompile(`(?s)<name>\s*(.*?)\s*</name>`)
+	xmlToolArgumentPattern = regexp.MustCompile(`(?s)<(?:arguments|parameters)>(.*?)</(?:arguments|parameters)>`)
+)
+
+// toolCallWrapperKeys hold one call or a list of calls inside an outer object.
+var toolCallWrapperKeys = []string{"functionCall", "function_call", "functionCalls", "tool_calls", "toolCalls"}
+
+// toolArgumentKeys name the arguments object, in order of preference.
+var toolArgumentKeys = []string{"arguments", "args", "parameters", "input"}
+
+// parseManualToolCall extracts tool calls written in the answer text and returns them with
+// the text that remains once the calls, their tags and code fences are removed.
+func parseManualToolCall(content string) ([]openAIToolCall, string) {
+	cleaned := content
+	var calls []openAIToolCall
+
+	cleaned = extractTaggedCalls(cleaned, functionTagPattern, &calls, func(match []string) ([]openAIToolCall, bool) {
+		args, ok := normalizeArguments(match[2])
+		if !ok {
+			return nil, false
+		}
+		return []openAIToolCall{newManualToolCall(match[1], args)}, true
+	})
+	cleaned = extractTaggedCalls(cleaned, toolCallTagPattern, &calls, func(match []string) ([]openAIToolCall, bool) {
+		if parsed, ok := parseToolCallPayload(match[2]); ok {
+			return parsed, true
+		}
+		return parseXMLToolCall(match[2])
+	})
+
+	for {
+		parsed := false
+		for _, block := range findJSONBlocks(cleaned) {
+			found, ok := parseToolCallPayload(cleaned[block.start:block.end])
+			if !ok {
+				continue
+			}
+			calls = append(calls, found...)
+			cleaned = removeJSONBlock(cleaned, block.start, block.end)
+			parsed = true
+			break
+		}
+		if !parsed {
+			break
+		}
+	}
+
+	if len(calls) == 0 {
+		return nil, content
+	}
+	return calls, cleaned
+}
+
+var (
+	callNameKeyPattern = regexp.MustCompile(`["']name["']\s*:`)
+	callTagOpenPattern = regexp.MustCompile(`<(?:tool_call|function_call)>|<function=[^>\s]*>?`)
+)
+
+// malformedToolCall explains why an answer that tries to call a tool yielded no call: a
+// JSON object with a "name" key that does not decode, or a tool call tag that holds no
+// valid call. It returns nil when the answer has a call or does not attempt one.
+func malformedToolCall(content string) error {
+	if calls, _ := parseManualToolCall(content); len(calls) > 0 {
+		return nil
+	}
+	candidates := make([]string, 0, 1)
+	for _, block := range findJSONBlocks(content) {
+		candidates = append(candidates, content[block.start:block.end])
+	}
+	if open := strings.IndexByte(content, '{'); open >= 0 && len(candidates) == 0 {
+		// An object that is never closed, typically an answer cut off mid-call.
+		candidates = append(candidates, content[open:])
+	}
+	for _, candidate := range candidates {
+		if !callNameKeyPattern.MatchString(candidate) {
+			continue
+		}
+		if _, err := decodeJSONNumbers(stripCodeFence(candidate)); err != nil {
+			return err
+		}
+		return errors.New(`a call needs a string "name" and an "arguments" object`)
+	}
+	if loc := callTagOpenPattern.FindStringIndex(content); loc != nil {
+		return fmt.Errorf("the %s tag does not hold a valid call", content[loc[0]:loc[1]])
+	}
+	return nil
+}
+
+// extractTaggedCalls removes every tag matched by pattern whose contents parse as tool calls.
+func extractTaggedCalls(content string, pattern *regexp.Regexp, calls *[]openAIToolCall, parse func([]string) ([]openAIToolCall, bool)) string {
+	for {
+		parsed := false
+		for _, loc := range pattern.FindAllStringSubmatchIndex(content, -1) {
+			match := make([]string, len(loc)/2)
+			for i := range match {
+				if loc[2*i] >= 0 {
+					match[i] = content[loc[2*i]:loc[2*i+1]]
+				}
+			}
+			found, ok := parse(match)
+			if !ok {
+				continue
+			}
+			*calls = append(*calls, found...)
+			content = removeJSONBlock(content, loc[0], loc[1])
+			parsed = true
+			break
+		}
+		if !parsed {
+			return content
+		}
+	}
+}
+
+// parseXMLToolCall reads <name> and <arguments> children of a <tool_call> tag.
+func parseXMLToolCall(body string) ([]openAIToolCall, bool) {
+	name := xmlToolNamePattern.FindStringSubmatch(body)
+	if name == nil || strings.TrimSpace(name[1]) == "" {
+		return nil, false
+	}
+	args := "{}"
+	if match := xmlToolArgumentPattern.FindStringSubmatch(body); match != nil {
+		normalized, ok := normalizeArguments(match[1])
+		if !ok {
+			return nil, false
+		}
+		args = normalized
+	}
+	return []openAIToolCall{newManualToolCall(name[1], args)}, true
+}
+
+// parseToolCallPayload decodes a JSON (or python literal) value holding one or more calls.
+func parseToolCallPayload(raw string) ([]openAIToolCall, bool) {
+	value, ok := decodeLooseJSON(stripCodeFence(raw))
+	if !ok {
+		return nil, false
+	}
+	calls, ok := normalizeToolCalls(value)
+	return calls, ok && len(calls) > 0
+}
+
+// normalizeToolCalls turns a decoded payload into calls; every element of a list must be a call.
+func normalizeToolCalls(value any) ([]openAIToolCall, bool) {
+	switch v := value.(type) {
+	case []any:
+		var calls []openAIToolCall
+		for _, item := range v {
+			found, ok := normalizeToolCalls(item)
+			if !ok {
+				return nil, false
+			}
+			calls = append(calls, found...)
+		}
+		return calls, len(calls) > 0
+	case map[string]any:
+		for _, key := range toolCallWrapperKeys {
+			if inner, ok := v[key]; ok {
+				return normalizeToolCalls(inner)
+			}
+		}
+		if fn, ok := v["function"].(map[string]any); ok {
+			v = fn
+		}
+		name, _ := v["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return nil, false
+		}
+		args := "{}"
+		for _, key := range toolArgumentKeys {
+			raw, ok := v[key]
+			if !ok {
+				continue
+			}
+			switch a := raw.(type) {
+			case nil:
+			case string:
+				normalized, ok := normalizeArguments(a)
+				if !ok {
+					return nil, false
+				}
+				args = normalized
+			case map[string]any:
+				encoded, err := json.Marshal(a)
+				if err != nil {
+					return nil, false
+				}
+				args = string(encoded)
+			default:
+				return nil, false
+			}
+			break
+		}
+		return []openAIToolCall{newManualToolCall(name, args)}, true
+	default:
+		return nil, false
+	}
+}
+
+// normalizeArguments re-encodes an arguments object written as JSON or a python dict.
+func normalizeArguments(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "null" {
+		return "{}", true
+	}
+	value, ok := decodeLooseJSON(raw)
+	if !ok {
+		return "", false
+	}
+	if _, isObject := value.(map[string]any); !isObject {
+		return "", false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+func newManualToolCall(name, args string) openAIToolCall {
+	return openAIToolCall{
+		Type: "function",
+		Function: openAIToolFunction{
+			Name:      strings.TrimSpace(name),
+			Arguments: args,
+		},
+	}
+}
+
+// decodeLooseJSON decodes JSON, falling back to python literal syntax. Numbers keep
+// their original text.
+func decodeLooseJSON(raw string) (any, bool) {
+	raw = strings.TrimSpace(raw)
+	if value, err := decodeJSONNumbers(raw); err == nil {
+		return value, true
+	}
+	converted, ok := pythonLiteralToJSON(raw)
+	if !ok {
+		return nil, false
+	}
+	value, err := decodeJSONNumbers(converted)
+	return value, err == nil
+}
+
+func decodeJSONNumbers(raw string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after value")
+	}
+	return value, nil
+}
+
+var pythonConstants = map[string]string{"True": "true", "False": "false", "None": "null"}
+
+// pythonLiteralToJSON rewrites a python dict or list literal as JSON: single-quoted
+// strings, True/False/None and trailing commas.
+func pythonLiteralToJSON(s string) (string, bool) {
+	var b bytes.Buffer
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"':
+			value, next, ok := readQuoted(s, i)
+			if !ok {
+				return "", false
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return "", false
+			}
+			b.Write(encoded)
+			i = next
+		case c == '_' || isASCIILetter(c):
+			j := i
+			for j < len(s) && (s[j] == '_' || isASCIILetter(s[j]) || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			word := s[i:j]
+			if constant, ok := pythonConstants[word]; ok {
+				word = constant
+			}
+			b.WriteString(word)
+			i = j
+		case c == ',':
+			j := i + 1
+			for j < len(s) && strings.IndexByte(" \t\r\n", s[j]) >= 0 {
+				j++
+			}
+			if j < len(s) && (s[j] == '}' || s[j] == ']') {
+				i++
+				continue
+			}
+			b.WriteByte(c)
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), true
+}
+
+// readQuoted reads the string literal starting at s[start] and returns its value and the
+// index after the closing quote.
+func readQuoted(s string, start int) (string, int, bool) {
+	quote := s[start]
+	var value strings.Builder
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		if c == quote {
+			return value.String(), i + 1, true
+		}
+		if c != '\\' || i+1 >= len(s) {
+			value.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			value.WriteByte('\n')
+		case 't':
+			value.WriteByte('\t')
+		case 'r':
+			value.WriteByte('\r')
+		case 'u':
+			if i+4 < len(s) {
+				if code, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					value.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			value.WriteByte('u')
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return "", 0, false
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+type jsonBlock struct {
+	start int
+	end   int
+}
+
+// findJSONBlocks returns the top-level {...} objects in text, and [...] lists that start
+// with an object. Quotes only delimit strings inside a block, so apostrophes in the
+// surrounding prose do not hide later blocks.
+func findJSONBlocks(text string) []jsonBlock {
+	var (
+		blocks []jsonBlock
+		depth  int
+		start  = -1
+		quote  byte
+		escape bool
+	)
+
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if quote != 0 {
+			if escape {
+				escape = false
+				continue
+			}
+			if ch == '\\' {
+				escape = true
+				continue
+			}
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			if depth > 0 {
+				quote = ch
+			}
+		case '\'':
+			if depth > 0 && opensValue(text[:i]) {
+				quote = ch
+			}
+		case '{', '[':
+			if depth == 0 {
+				if ch == '[' && !startsWithObject(text[i+1:]) {
+					continue
+				}
+				start = i
+			}
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 && start >= 0 {
+				blocks = append(blocks, jsonBlock{start: start, end: i + 1})
+			}
+		}
+	}
+	return blocks
+}
+
+// opensValue reports whether a quote after before starts a key or value, as in python
+// dicts, rather than being an apostrophe inside a word.
+func opensValue(before string) bool {
+	before = strings.TrimRight(before, " \t\r\n")
+	return before != "" && strings.IndexByte("{[,:", before[len(before)-1]) >= 0
+}
+
+func startsWithObject(text string) bool {
+	return strings.HasPrefix(strings.TrimLeft(text, " \t\r\n"), "{")
+}
+
+func removeJSONBlock(content string, start, end int) string {
+	left := strings.TrimRight(content[:start], " \t\r\n")
+	left = trimTrailingFence(left)
+	right := strings.TrimLeft(content[end:], " \t\r\n")
+	right = trimLeadingFence(right)
+
+	switch {
+	case left == "":
+		return strings.TrimSpace(right)
+	case right == "":
+		return strings.TrimSpace(left)
+	default:
+		return strings.TrimSpace(left + "\n\n" + right)
+	}
+}
+
+// trimTrailingFence drops a code fence that opens right before a removed call,
+// including its language tag.
+func trimTrailingFence(s string) string {
+	s = strings.TrimRight(s, " \t\r\n")
+	lineStart := strings.LastIndexByte(s, '\n') + 1
+	if last := s[lineStart:]; strings.HasPrefix(last, "```") && !strings.ContainsAny(last[3:], " \t`") {
+		return strings.TrimRight(s[:lineStart], " \t\r\n")
+	}
+	return s
+}
+
+// stripCodeFence removes a ``` fence, with or without a language tag, around a payload.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	if nl := strings.IndexByte(s, '\n'); nl >= 0 {
+		s = s[nl+1:]
+	} else {
+		s = s[3:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
+
+func trimLeadingFence(s string) string {
+	s = strings.TrimLeft(s, " \t\r\n")
+	if strings.HasPrefix(s, "```") {
+		return strings.TrimLeft(s[3:], " \t\r\n")
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"testing"
+)
+
+func TestParseManualToolCallFormats(t *testing.T) {
+	type want struct {
+		name string
+		args string
+	}
+	cases := []struct {
+		name    string
+		content string
+		calls   []want
+		cleaned string
+	}{
+		{
+			name:    "function call schema in a fence",
+			content: "Looking it up.\n```json\n{\"server\": \"docs\", \"name\": \"docs__read\", \"arguments\": {\"path\": \"a.md\"}}\n```\nDone.",
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}},
+			cleaned: "Looking it up.\n\nDone.",
+		},
+		{
+			name:    "tool_call tag with JSON",
+			content: "<tool_call>\n{\"name\": \"docs__read\", \"arguments\": {\"path\": \"a.md\"}}\n</tool_call>",
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}},
+		},
+		{
+			name:    "tool_call tag with fenced JSON",
+			content: "Sure.\n<tool_call>\n```json\n{\"name\": \"docs__read\", \"arguments\": {}}\n```\n</tool_call>",
+			calls:   []want{{"docs__read", `{}`}},
+			cleaned: "Sure.",
+		},
+		{
+			name:    "tool_call tag with xml children",
+			content: "<tool_call><name>docs__read</name><arguments>{\"path\": \"a.md\"}</arguments></tool_call>",
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}},
+		},
+		{
+			name:    "llama function tag",
+			content: "<function=docs__read>{\"path\": \"a.md\"}</function>",
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}},
+		},
+		{
+			name:    "functionCall object",
+			content: `{"functionCall": {"name": "docs__read", "args": {"path": "a.md"}}}`,
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}},
+		},
+		{
+			name:    "functionCall array",
+			content: `{"functionCall": [{"name": "docs__read", "args": {"path": "a.md"}}, {"name": "docs__list"}]}`,
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}, {"docs__list", `{}`}},
+		},
+		{
+			name:    "openai tool_calls echo with string arguments",
+			content: `{"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "docs__read", "arguments": "{\"path\": \"a.md\"}"}}]}`,
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}},
+		},
+		{
+			name:    "top-level array of calls",
+			content: "Two lookups:\n[{\"name\": \"docs__read\", \"arguments\": {\"path\": \"a.md\"}}, {\"name\": \"docs__read\", \"parameters\": {\"path\": \"b.md\"}}]",
+			calls:   []want{{"docs__read", `{"path":"a.md"}`}, {"docs__read", `{"path":"b.md"}`}},
+			cleaned: "Two lookups:",
+		},
+		{
+			name:    "python dict",
+			content: "I'll check that: {'name': 'docs__read', 'arguments': {'path': 'it\\'s.md', 'raw': True, 'limit': None, 'tags': ['a', 'b',],}}",
+			calls:   []want{{"docs__read", `{"limit":null,"path":"it's.md","raw":true,"tags":["a","b"]}`}},
+			cleaned: "I'll check that:",
+		},
+		{
+			name:    "large numbers keep their digits",
+			content: `{"name": "ids__get", "arguments": {"id": 12345678901234567890}}`,
+			calls:   []want{{"ids__get", `{"id":12345678901234567890}`}},
+		},
+		{
+			name:    "apostrophes in prose do not hide the call",
+			content: "It's easy; here's the call {\"name\": \"docs__read\", \"arguments\": {}}",
+			calls:   []want{{"docs__read", `{}`}},
+			cleaned: "It's easy; here's the call",
+		},
+		{
+			name:    "plain JSON data is not a call",
+			content: "The config is {\"debug\": true} and the list is [1, 2].",
+		},
+		{
+			name:    "arrays with a non-call element are not calls",
+			content: `[{"name": "docs__read"}, {"id": 1}]`,
+		},
+		{
+			name:    "arguments must be an object",
+			content: `{"name": "docs__read", "arguments": [1, 2]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls, cleaned := parseManualToolCall(tc.content)
+			if len(calls) != len(tc.calls) {
+				t.Fatalf("expected %d calls, got %+v", len(tc.calls), calls)
+			}
+			for i, call := range calls {
+				if call.Function.Name != tc.calls[i].name || call.Function.Arguments != tc.calls[i].args {
+					t.Fatalf("call %d = %s(%s), want %s(%s)", i, call.Function.Name, call.Function.Arguments, tc.calls[i].name, tc.calls[i].args)
+				}
+				if call.Type != "function" {
+					t.Fatalf("expected function type, got %q", call.Type)
+				}
+				if _, err := (toolCallRequest{Call: call}).arguments(); err != nil {
+					t.Fatalf("normalized arguments do not decode: %v", err)
+				}
+			}
+			if len(tc.calls) == 0 {
+				if cleaned != tc.content {
+					t.Fatalf("expected content unchanged, got %q", cleaned)
+				}
+				return
+			}
+			if cleaned != tc.cleaned {
+				t.Fatalf("cleaned = %q, want %q", cleaned, tc.cleaned)
+			}
+		})
+	}
+}
+
+func TestMalformedToolCall(t *testing.T) {
+	cases := []struct {
+		name      string
+		content   string
+		malformed bool
+	}{
+		{"valid call", `{"name": "docs__read", "arguments": {"path": "a.md"}}`, false},
+		{"plain answer", "The guide says to run make.", false},
+		{"unrelated JSON", "Use {\"path\": \"a.md\" as input.", false},
+		{"missing quote", `{"name": "docs__read", "arguments": {"path": "a.md}}`, true},
+		{"cut off", "Reading it.\n{\"name\": \"docs__read\", \"arguments\": {\"path\":", true},
+		{"arguments not an object", `{"name": "docs__read", "arguments": ["a.md"]}`, true},
+		{"empty tool_call tag", "<tool_call>docs__read please</tool_call>", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := malformedToolCall(tc.content)
+			if (err != nil) != tc.malformed {
+				t.Fatalf("malformedToolCall(%q) = %v, want malformed %v", tc.content, err, tc.malformed)
+			}
+		})
+	}
+}
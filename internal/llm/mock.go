@@ -0,0 +1,220 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The mock provider plays back a scenario file instead of calling a model, for demos,
+// integration tests and deterministic bug reports:
+//
+//	{
+//	  "delayMs": 30,
+//	  "turns": [
+//	    {"match": "weather", "chunks": [
+//	      {"toolCall": {"server": "weather", "method": "forecast", "arguments": {"city": "Seoul"}}},
+//	      {"token": "It is sunny in Seoul."}
+//	    ]},
+//	    {"chunks": [{"thinking": "Greeting."}, {"token": "Hello!"}]}
+//	  ]
+//	}
+
+// mockScenario is the file the mock provider plays back.
+type mockScenario struct {
+	// DelayMillis pauses before every chunk so demos stream visibly.
+	DelayMillis int `json:"delayMs,omitempty"`
+	// Loop starts over once every ordered turn has been played.
+	Loop  bool       `json:"loop,omitempty"`
+	Turns []mockTurn `json:"turns"`
+}
+
+// mockTurn is the scripted answer to one request.
+type mockTurn struct {
+	// Match answers every request whose latest user message contains this text. Turns
+	// without it answer the remaining requests in order.
+	Match        string      `json:"match,omitempty"`
+	Chunks       []mockChunk `json:"chunks"`
+	FinishReason string      `json:"finishReason,omitempty"`
+}
+
+// mockChunk sets exactly one of its fields.
+type mockChunk struct {
+	Token    string        `json:"token,omitempty"`
+	Thinking string        `json:"thinking,omitempty"`
+	ToolCall *mockToolCall `json:"toolCall,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type mockToolCall struct {
+	Server    string         `json:"server"`
+	Method    string         `json:"method"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// mockState is a loaded scenario and its position. The factory keeps one per scenario
+// file, since a provider is created for every turn.
+type mockState struct {
+	scenario mockScenario
+
+	mu   sync.Mutex
+	next int
+}
+
+func loadMockScenario(path string) (mockScenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mockScenario{}, fmt.Errorf("read mock scenario: %w", err)
+	}
+	var scenario mockScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return mockScenario{}, fmt.Errorf("parse mock scenario %s: %w", path, err)
+	}
+	for i, turn := range scenario.Turns {
+		for j, chunk := range turn.Chunks {
+			if chunk.ToolCall != nil && strings.TrimSpace(chunk.ToolCall.Method) == "" {
+				return mockScenario{}, fmt.Errorf("mock scenario %s: turn %d chunk %d: toolCall needs a method", path, i+1, j+1)
+			}
+		}
+	}
+	return scenario, nil
+}
+
+// mockState returns the shared state for a scenario file, loading it on first use.
+func (f *Factory) mockState(path string) (*mockState, error) {
+	f.mocksMu.Lock()
+	defer f.mocksMu.Unlock()
+	if state, ok := f.mocks[path]; ok {
+		return state, nil
+	}
+	scenario, err := loadMockScenario(path)
+	if err != nil {
+		return nil, err
+	}
+	if f.mocks == nil {
+		f.mocks = map[string]*mockState{}
+	}
+	state := &mockState{scenario: scenario}
+	f.mocks[path] = state
+	return state, nil
+}
+
+// nextTurn picks the turn answering message.
+func (s *mockState) nextTurn(message string) (mockTurn, error) {
+	for _, turn := range s.scenario.Turns {
+		if turn.Match != "" && strings.Contains(message, turn.Match) {
+			return turn, nil
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for pass := 0; pass < 2; pass++ {
+		for s.next < len(s.scenario.Turns) {
+			turn := s.scenario.Turns[s.next]
+			s.next++
+			if turn.Match == "" {
+				return turn, nil
+			}
+		}
+		if !s.scenario.Loop {
+			break
+		}
+		s.next = 0
+	}
+	return mockTurn{}, errors.New("mock scenario has no turn left")
+}
+
+type mockProvider struct {
+	state *mockState
+}
+
+func (p *mockProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	var message string
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			message = req.Messages[i].Content
+			break
+		}
+	}
+	turn, err := p.state.nextTurn(message)
+	if err != nil {
+		return nil, err
+	}
+	delay := time.Duration(p.state.scenario.DelayMillis) * time.Millisecond
+
+	stream := newChunkStream(req.TurnID)
+	go func() {
+		defer stream.close()
+		stream.send(StreamChunk{Type: ChunkThinking})
+		for _, chunk := range turn.Chunks {
+			if delay > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+			switch {
+			case chunk.ToolCall != nil:
+				if err := p.callTool(ctx, stream, req.Tools, *chunk.ToolCall); err != nil {
+					return
+				}
+			case chunk.Error != "":
+				stream.send(StreamChunk{Type: ChunkError, Err: errors.New(chunk.Error)})
+				return
+			case chunk.Thinking != "":
+				stream.send(StreamChunk{Type: ChunkThinking, Content: chunk.Thinking})
+			default:
+				stream.send(StreamChunk{Type: ChunkToken, Content: chunk.Token})
+			}
+		}
+		stream.send(StreamChunk{Type: ChunkDone, FinishReason: turn.FinishReason})
+	}()
+	return stream.ch, nil
+}
+
+// callTool requests a scripted tool call and waits for its result, which the scenario ignores.
+func (p *mockProvider) callTool(ctx context.Context, stream *chunkStream, tools []ToolDefinition, call mockToolCall) error {
+	var description string
+	for _, def := range tools {
+		if def.Server == call.Server && def.Method == call.Method {
+			description = def.Description
+			break
+		}
+	}
+	arguments := call.Arguments
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+
+	resultCh := make(chan ToolResult, 1)
+	stream.send(StreamChunk{Type: ChunkToolCall, ToolCall: &ToolCall{
+		Server:      call.Server,
+		Method:      call.Method,
+		Description: description,
+		Arguments:   arguments,
+		Respond: func(ctx context.Context, result ToolResult) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case resultCh <- result:
+				return nil
+			}
+		},
+	}})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case result := <-resultCh:
+		if logger := LoggerFromContext(ctx); logger != nil {
+			logger.Debugf("Mock tool result for %s: %s", ToolName(call.Server, call.Method), result.Content)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestMockProviderPlaysScenario(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	scenario := `{
+  "turns": [
+    {"match": "weather", "chunks": [
+      {"toolCall": {"server": "weather", "method": "forecast", "arguments": {"city": "Seoul"}}},
+      {"token": "It is sunny."}
+    ]},
+    {"chunks": [{"thinking": "Greeting."}, {"token": "Hello!"}], "finishReason": "stop"},
+    {"chunks": [{"token": "Partial"}, {"error": "scripted failure"}]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(scenario), 0o644); err != nil {
+		t.Fatalf("write scenario: %v", err)
+	}
+
+	factory := NewFactory(nil)
+	play := func(message string) (string, []string) {
+		t.Helper()
+		provider, err := factory.Create(config.Model{Name: "demo", Provider: "mock", Scenario: path})
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		stream, err := provider.Stream(ctx, ChatRequest{Model: "demo", Messages: []Message{{Role: "user", Content: message}}})
+		if err != nil {
+			return "", []string{"error: " + err.Error()}
+		}
+		var text strings.Builder
+		var events []string
+		for chunk := range stream {
+			switch chunk.Type {
+			case ChunkToken:
+				text.WriteString(chunk.Content)
+			case ChunkThinking:
+				if chunk.Content != "" {
+					events = append(events, "thinking: "+chunk.Content)
+				}
+			case ChunkToolCall:
+				events = append(events, "call: "+ToolName(chunk.ToolCall.Server, chunk.ToolCall.Method)+" "+chunk.ToolCall.Arguments["city"].(string))
+				if err := chunk.ToolCall.Respond(ctx, ToolResult{Content: "sunny"}); err != nil {
+					t.Fatalf("respond: %v", err)
+				}
+			case ChunkError:
+				events = append(events, "error: "+chunk.Err.Error())
+			case ChunkDone:
+				events = append(events, "done: "+chunk.FinishReason)
+			}
+		}
+		return text.String(), events
+	}
+
+	if text, events := play("hi"); text != "Hello!" || strings.Join(events, "|") != "thinking: Greeting.|done: stop" {
+		t.Fatalf("first ordered turn = %q %v", text, events)
+	}
+	if text, events := play("weather in Seoul?"); text != "It is sunny." || strings.Join(events, "|") != "call: weather__forecast Seoul|done: " {
+		t.Fatalf("matched turn = %q %v", text, events)
+	}
+	if text, events := play("again"); text != "Partial" || strings.Join(events, "|") != "error: scripted failure" {
+		t.Fatalf("second ordered turn = %q %v", text, events)
+	}
+	if _, events := play("more"); len(events) != 1 || !strings.Contains(events[0], "no turn left") {
+		t.Fatalf("expected the scenario to run out, got %v", events)
+	}
+
+	if _, err := factory.Create(config.Model{Name: "demo", Provider: "mock"}); err == nil {
+		t.Fatalf("expected an error without a scenario")
+	}
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// errTruncatedObject reports a stream that ended inside a JSON object.
+var errTruncatedObject = errors.New("stream ended inside a JSON object")
+
+// newObjectScanner splits a newline-delimited JSON stream into top-level objects. It does
+// not rely on the newlines: keep-alive blank lines are skipped, objects written back to
+// back or split across reads come out whole, and text outside any object, such as a stray
+// "data:" prefix, comes out as its own token, one line at a time. Object tokens start with
+// '{'; everything else is such stray text.
+func newObjectScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamToken)
+	scanner.Split(scanJSONObjects)
+	return scanner
+}
+
+// scanJSONObjects is the bufio.SplitFunc behind newObjectScanner.
+func scanJSONObjects(data []byte, atEOF bool) (int, []byte, error) {
+	start := 0
+	for start < len(data) && isJSONSpace(data[start]) {
+		start++
+	}
+	if start == len(data) {
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return start, nil, nil
+	}
+
+	if data[start] != '{' {
+		end := bytes.IndexAny(data[start:], "{\n")
+		if end < 0 {
+			if !atEOF {
+				return start, nil, nil
+			}
+			end = len(data) - start
+		}
+		return start + end, bytes.TrimSpace(data[start : start+end]), nil
+	}
+
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i + 1, data[start : i+1], nil
+			}
+		}
+	}
+	if atEOF {
+		return 0, nil, errTruncatedObject
+	}
+	return start, nil, nil
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,241 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// ollamaStreamFixtures are Ollama-compatible stream bodies seen in the wild, with the
+// tokens each should split into.
+var ollamaStreamFixtures = []struct {
+	name   string
+	stream string
+	want   []string
+	err    error
+}{
+	{
+		name:   "one object per line",
+		stream: "{\"a\":1}\n{\"b\":2}\n",
+		want:   []string{`{"a":1}`, `{"b":2}`},
+	},
+	{
+		name:   "keep-alive blank lines",
+		stream: "\n\n{\"a\":1}\r\n\r\n \n{\"b\":2}\n\n",
+		want:   []string{`{"a":1}`, `{"b":2}`},
+	},
+	{
+		name:   "concatenated objects",
+		stream: "{\"a\":1}{\"b\":2} {\"c\":3}",
+		want:   []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+	},
+	{
+		name:   "braces and escapes inside strings",
+		stream: `{"content":"a } b \" { c \\"}` + "\n" + `{"content":"[\"x\"]"}`,
+		want:   []string{`{"content":"a } b \" { c \\"}`, `{"content":"[\"x\"]"}`},
+	},
+	{
+		name:   "nested objects and arrays",
+		stream: `{"message":{"tool_calls":[{"function":{"arguments":{"list":[1,{"k":"v"}]}}}]}}`,
+		want:   []string{`{"message":{"tool_calls":[{"function":{"arguments":{"list":[1,{"k":"v"}]}}}]}}`},
+	},
+	{
+		name:   "stray text outside objects",
+		stream: "data: {\"a\":1}\n: keep-alive\n{\"b\":2}\n",
+		want:   []string{"data:", `{"a":1}`, ": keep-alive", `{"b":2}`},
+	},
+	{
+		name:   "truncated final object",
+		stream: "{\"a\":1}\n{\"b\":",
+		want:   []string{`{"a":1}`},
+		err:    errTruncatedObject,
+	},
+	{
+		name:   "empty body",
+		stream: "",
+	},
+}
+
+func scanObjects(r io.Reader) ([]string, error) {
+	scanner := newObjectScanner(r)
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	return tokens, scanner.Err()
+}
+
+func TestObjectScannerSplitsFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range ollamaStreamFixtures {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, r := range []io.Reader{strings.NewReader(tc.stream), iotest.OneByteReader(strings.NewReader(tc.stream))} {
+				got, err := scanObjects(r)
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("error = %v, want %v", err, tc.err)
+				}
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Fatalf("tokens = %q, want %q", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func FuzzObjectScanner(f *testing.F) {
+	for _, tc := range ollamaStreamFixtures {
+		f.Add([]byte(tc.stream))
+	}
+	f.Add([]byte("}}]]{{[["))
+	f.Add([]byte(`{"a":"\`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tokens, err := scanObjects(bytes.NewReader(data))
+		if err != nil && !errors.Is(err, errTruncatedObject) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		total := 0
+		for _, token := range tokens {
+			if token == "" {
+				t.Fatal("empty token")
+			}
+			if !bytes.Contains(data, []byte(token)) {
+				t.Fatalf("token %q is not part of the input", token)
+			}
+			total += len(token)
+			// Decoding a malformed object must fail cleanly, never panic.
+			var chunk ollamaStreamChunk
+			_ = json.Unmarshal([]byte(token), &chunk)
+		}
+		if total > len(data) {
+			t.Fatalf("tokens hold %d bytes of a %d byte input", total, len(data))
+		}
+	})
+}
+
+func FuzzObjectScannerKeepsValidObjects(f *testing.F) {
+	f.Add("Hello", "\n", 3)
+	f.Add(`a "quoted" {brace} \ slash`, "", 1)
+	f.Add("", "\r\n\r\n", 7)
+
+	f.Fuzz(func(t *testing.T, content, separator string, count int) {
+		if strings.Trim(separator, " \t\r\n") != "" || count < 0 || count > 20 {
+			t.Skip()
+		}
+		var (
+			stream strings.Builder
+			want   []string
+		)
+		for i := 0; i < count; i++ {
+			chunk := ollamaStreamChunk{Done: i == count-1}
+			chunk.Message.Content = content
+			encoded, err := json.Marshal(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, string(encoded))
+			stream.Write(encoded)
+			stream.WriteString(separator)
+		}
+		got, err := scanObjects(iotest.HalfReader(strings.NewReader(stream.String())))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("tokens = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestOllamaProviderToleratesMalformedStreams(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "keep-alives and concatenated objects",
+			body: "\n{\"message\":{\"content\":\"Hel\"},\"done\":false}{\"message\":{\"content\":\"lo\"},\"done\":false}\n\n\n{\"message\":{\"content\":\"\"},\"done\":true}",
+			want: "Hello",
+		},
+		{
+			name: "stray prefixes are skipped",
+			body: "data: {\"message\":{\"content\":\"Hi\"},\"done\":true}\n",
+			want: "Hi",
+		},
+		{
+			name:    "plain text body",
+			body:    "upstream timed out\n",
+			wantErr: "unexpected ollama response: upstream timed out",
+		},
+		{
+			name:    "malformed object",
+			body:    "{\"message\":{\"content\":}}\n",
+			wantErr: "decode ollama chunk",
+		},
+		{
+			name:    "cut off mid-object",
+			body:    "{\"message\":{\"content\":\"Hel\"},\"done\":false}\n{\"message\":{\"cont",
+			wantErr: errTruncatedObject.Error(),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				io.WriteString(w, tc.body)
+			}))
+			defer server.Close()
+
+			provider, err := NewFactory(server.Client()).Create(config.Model{Name: "llama3", Provider: "ollama", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("create provider: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			stream, err := provider.Stream(ctx, ChatRequest{Model: "llama3", Messages: []Message{{Role: "user", Content: "hi"}}, Stream: true})
+			if err != nil {
+				t.Fatalf("stream: %v", err)
+			}
+			var (
+				content   strings.Builder
+				streamErr error
+			)
+			for chunk := range stream {
+				switch chunk.Type {
+				case ChunkToken:
+					content.WriteString(chunk.Content)
+				case ChunkError:
+					streamErr = chunk.Err
+				}
+			}
+			if tc.wantErr != "" {
+				if streamErr == nil || !strings.Contains(streamErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, streamErr)
+				}
+				return
+			}
+			if streamErr != nil {
+				t.Fatalf("unexpected stream error: %v", streamErr)
+			}
+			if content.String() != tc.want {
+				t.Fatalf("content = %q, want %q", content.String(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// streamlessEndpoints is the set of OpenAI-compatible base URLs found not to stream. It is
+// shared by the providers of a Factory so only the first request to a gateway pays for
+// the detection.
+type streamlessEndpoints struct {
+	mu   sync.Mutex
+	urls map[string]bool
+}
+
+func (s *streamlessEndpoints) has(baseURL string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.urls[baseURL]
+}
+
+func (s *streamlessEndpoints) add(baseURL string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.urls == nil {
+		s.urls = map[string]bool{}
+	}
+	s.urls[baseURL] = true
+}
+
+// isJSONResponse reports a plain JSON body where an event stream was asked for, which is
+// how gateways that ignore "stream": true answer.
+func isJSONResponse(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// streamRejections are the phrases gateways use to refuse the stream parameter.
+var streamRejections = [][]byte{
+	[]byte("stream is not supported"),
+	[]byte("streaming is not supported"),
+}
+
+// rejectsStreaming reports a 400 or 422 that refuses the stream parameter itself, either
+// naming it as the bad param or saying streaming is not supported.
+func rejectsStreaming(status int, body []byte) bool {
+	if status != http.StatusBadRequest && status != http.StatusUnprocessableEntity {
+		return false
+	}
+	var payload struct {
+		Error struct {
+			Param string `json:"param"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.Error.Param == "stream" {
+		return true
+	}
+	lower := bytes.ToLower(body)
+	for _, phrase := range streamRejections {
+		if bytes.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// openAICompletion is a non-streamed chat completion; each message carries the whole answer.
+type openAICompletion struct {
+	Model    string `json:"model"`
+	Provider string `json:"provider"`
+	Choices  []struct {
+		Message      openAIDelta `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Error json.RawMessage `json:"error"`
+}
+
+// decodeOpenAICompletion reads a non-streamed completion as one stream chunk, so the whole
+// answer goes out as a single token.
+func decodeOpenAICompletion(body io.Reader) (openAIStreamChunk, error) {
+	var completion openAICompletion
+	if err := json.NewDecoder(body).Decode(&completion); err != nil {
+		return openAIStreamChunk{}, fmt.Errorf("decode completion: %w", err)
+	}
+	chunk := openAIStreamChunk{Model: completion.Model, Provider: completion.Provider, Error: completion.Error}
+	for _, choice := range completion.Choices {
+		message := choice.Message
+		// Whole messages carry no call indexes; number the calls so they stay apart.
+		for i := range message.ToolCalls {
+			message.ToolCalls[i].Index = i
+		}
+		// Some gateways report "stop" next to tool calls; the calls decide.
+		reason := choice.FinishReason
+		if len(message.ToolCalls) > 0 {
+			reason = "tool_calls"
+		} else if reason == "" {
+			reason = "stop"
+		}
+		chunk.Choices = append(chunk.Choices, openAIStreamChoice{Delta: message, FinishReason: reason})
+	}
+	return chunk, nil
+}
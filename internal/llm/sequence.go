@@ -0,0 +1,33 @@
+package llm
+
+import "sync"
+
+// chunkStream is the sending side of a provider stream. It tags every chunk with the
+// request's turn ID and numbers chunks in the order they enter the channel, so consumers
+// can tell a dropped or reordered chunk from a gap in the model's output.
+type chunkStream struct {
+	ch     chan StreamChunk
+	turnID string
+
+	mu   sync.Mutex
+	next uint64
+}
+
+func newChunkStream(turnID string) *chunkStream {
+	return &chunkStream{ch: make(chan StreamChunk, streamBuffer), turnID: turnID}
+}
+
+// send stamps and queues chunk. The lock is held across the channel send, so sequence
+// numbers follow channel order even when several goroutines send.
+func (s *chunkStream) send(chunk StreamChunk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	chunk.Seq = s.next
+	chunk.TurnID = s.turnID
+	s.ch <- chunk
+}
+
+func (s *chunkStream) close() {
+	close(s.ch)
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// assertSequenced fails unless chunks are numbered 1, 2, 3, ... and all carry turnID.
+func assertSequenced(t *testing.T, chunks []StreamChunk, turnID string) {
+	t.Helper()
+	if len(chunks) == 0 {
+		t.Fatalf("turn %s: no chunks", turnID)
+	}
+	for i, chunk := range chunks {
+		if chunk.Seq != uint64(i+1) || chunk.TurnID != turnID {
+			t.Fatalf("turn %s: chunk %d has seq %d and turn %q", turnID, i, chunk.Seq, chunk.TurnID)
+		}
+	}
+}
+
+func TestChunkStreamNumbersConcurrentSendersInChannelOrder(t *testing.T) {
+	stream := newChunkStream("turn-1")
+	var wg sync.WaitGroup
+	for sender := 0; sender < 4; sender++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				stream.send(StreamChunk{Type: ChunkToken, Content: fmt.Sprintf("%d-%d", sender, i)})
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		stream.close()
+	}()
+
+	var chunks []StreamChunk
+	for chunk := range stream.ch {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 400 {
+		t.Fatalf("expected 400 chunks, got %d", len(chunks))
+	}
+	assertSequenced(t, chunks, "turn-1")
+}
+
+func TestMockStreamsKeepOrderAcrossConcurrentToolCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	scenario := `{"loop": true, "turns": [{"chunks": [
+  {"thinking": "Looking it up."},
+  {"toolCall": {"server": "weather", "method": "forecast"}},
+  {"token": "Sunny"},
+  {"toolCall": {"server": "weather", "method": "forecast"}},
+  {"token": " and warm."}
+]}]}`
+	if err := os.WriteFile(path, []byte(scenario), 0o644); err != nil {
+		t.Fatalf("write scenario: %v", err)
+	}
+	factory := NewFactory(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	turns := []string{"turn-a", "turn-b", "turn-c"}
+	results := make([][]StreamChunk, len(turns))
+	var wg sync.WaitGroup
+	for i, turnID := range turns {
+		provider, err := factory.Create(config.Model{Name: "demo", Provider: "mock", Scenario: path})
+		if err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+		stream, err := provider.Stream(ctx, ChatRequest{Model: "demo", TurnID: turnID, Messages: []Message{{Role: "user", Content: "weather?"}}})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range stream {
+				results[i] = append(results[i], chunk)
+				if chunk.Type == ChunkToolCall {
+					// Answer from another goroutine while this stream waits, as the App does.
+					go func() { _ = chunk.ToolCall.Respond(ctx, ToolResult{Content: "sunny"}) }()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, turnID := range turns {
+		assertSequenced(t, results[i], turnID)
+		if len(results[i]) != 7 {
+			t.Fatalf("turn %s: expected 7 chunks, got %d", turnID, len(results[i]))
+		}
+	}
+}
+
+func TestOllamaStreamNumbersChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"Hel"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"lo"},"done":false}`)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "llama3", Provider: "ollama", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	stream, err := provider.Stream(context.Background(), ChatRequest{Model: "llama3", TurnID: "turn-1", Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var chunks []StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	assertSequenced(t, chunks, "turn-1")
+}
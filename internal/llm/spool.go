@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// streamBuffer is how many chunks a provider may queue ahead of its consumer.
+	streamBuffer = 64
+	// spoolMemoryLimit is how much of a response body is held in memory before the rest
+	// spills to a temporary file.
+	spoolMemoryLimit = 1 << 20
+	spoolReadSize    = 32 * 1024
+)
+
+var errSpoolClosed = errors.New("read from closed response body")
+
+// spoolBody reads body to the end in the background, so the HTTP connection keeps
+// draining while the consumer of the stream is busy, for example waiting on a tool
+// confirmation. Gateways drop connections whose reader stalls. Data not yet consumed is
+// kept in memory up to memoryLimit and then in a temporary file, which Close removes.
+func spoolBody(body io.ReadCloser, memoryLimit int) io.ReadCloser {
+	s := &spool{body: body, limit: memoryLimit}
+	s.cond = sync.NewCond(&s.mu)
+	go s.fill()
+	return s
+}
+
+type spool struct {
+	body  io.ReadCloser
+	limit int
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// mem holds unread data until the first spill; after that all data goes through file.
+	mem []byte
+	// file, when set, holds the data from written back to read.
+	file          *os.File
+	read, written int64
+	// err ends the stream once the buffered data is consumed; io.EOF for a clean end.
+	err    error
+	closed bool
+}
+
+func (s *spool) fill() {
+	buf := make([]byte, spoolReadSize)
+	for {
+		n, err := s.body.Read(buf)
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		if n > 0 {
+			if werr := s.store(buf[:n]); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		if err != nil {
+			s.err = err
+		}
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// store appends data; the caller holds s.mu.
+func (s *spool) store(data []byte) error {
+	if s.file == nil && len(s.mem)+len(data) <= s.limit {
+		s.mem = append(s.mem, data...)
+		return nil
+	}
+	if s.file == nil {
+		file, err := os.CreateTemp("", "humble-ai-stream-*")
+		if err != nil {
+			return err
+		}
+		s.file = file
+		if _, err := s.file.Write(s.mem); err != nil {
+			return err
+		}
+		s.written = int64(len(s.mem))
+		s.mem = nil
+	}
+	n, err := s.file.WriteAt(data, s.written)
+	s.written += int64(n)
+	return err
+}
+
+func (s *spool) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if s.closed {
+			return 0, errSpoolClosed
+		}
+		if s.file == nil && len(s.mem) > 0 {
+			n := copy(p, s.mem)
+			s.mem = s.mem[n:]
+			return n, nil
+		}
+		if s.file != nil && s.read < s.written {
+			want := int64(len(p))
+			if left := s.written - s.read; left < want {
+				want = left
+			}
+			n, err := s.file.ReadAt(p[:want], s.read)
+			s.read += int64(n)
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.cond.Wait()
+	}
+}
+
+func (s *spool) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	file := s.file
+	s.file, s.mem = nil, nil
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	err := s.body.Close()
+	if file != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func TestSpoolBodyDrainsAheadOfReaderAndSpillsToDisk(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 4096) // 64 KiB
+	pr, pw := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		_, err := pw.Write(data)
+		pw.Close()
+		written <- err
+	}()
+
+	body := spoolBody(pr, 1024)
+	// Nothing reads the spool yet; the writer must still finish.
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the body was not drained while the reader was idle")
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 1 {
+		t.Fatalf("expected one spill file, got %d", len(entries))
+	}
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes that differ from the %d written", len(got), len(data))
+	}
+	if err := body.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Fatalf("expected the spill file to be removed, found %v", entries)
+	}
+}
+
+func TestSpoolBodyCloseUnblocksReaders(t *testing.T) {
+	t.Parallel()
+
+	pr, _ := io.Pipe()
+	body := spoolBody(pr, 1024)
+	done := make(chan error, 1)
+	go func() {
+		_, err := body.Read(make([]byte, 8))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	body.Close()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error after close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Read stayed blocked after Close")
+	}
+}
+
+func TestOpenAIProviderKeepsReadingWhileConsumerStalls(t *testing.T) {
+	t.Parallel()
+
+	const tokens = 20000
+	finished := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(finished)
+		w.Header().Set("Content-Type", "text/event-stream")
+		padding := strings.Repeat("x", 200)
+		for i := 0; i < tokens; i++ {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":\"%s\"}}]}\n\n", padding)
+		}
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "gpt", Provider: "openai", APIKey: "sk", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{Model: "gpt", Stream: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	// A consumer that stops reading, as when it waits on the user, must not stall the
+	// server's writes of a body far larger than the socket buffers.
+	<-stream
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server could not finish writing while the consumer stalled")
+	}
+
+	count := 0
+	for chunk := range stream {
+		if chunk.Type == ChunkError {
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+		if chunk.Type == ChunkToken {
+			count++
+		}
+	}
+	if count != tokens {
+		t.Fatalf("expected %d tokens, got %d", tokens, count)
+	}
+}
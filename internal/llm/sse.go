@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// maxStreamToken bounds one line of an event stream or one object of a JSON stream.
+const maxStreamToken = 1024 * 1024
+
+// sseEvent is one dispatched server-sent event.
+type sseEvent struct {
+	// Event is the event type; empty means the default "message" type.
+	Event string
+	// Data holds the data lines of the event joined with "\n".
+	Data string
+	ID   string
+}
+
+// sseReader parses a text/event-stream body following the WHATWG rules: lines end in
+// CRLF, LF or CR, lines starting with ":" are comments, a single space after the field
+// colon is dropped, data lines accumulate until a blank line dispatches the event, and
+// unknown fields are ignored. An event left unterminated at the end of the body is still
+// dispatched, since some servers close the connection right after the last data line.
+type sseReader struct {
+	scanner *bufio.Scanner
+	lastID  string
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamToken)
+	scanner.Split(scanSSELines)
+	return &sseReader{scanner: scanner}
+}
+
+// Next returns the next event with data, or io.EOF at the end of the stream.
+func (r *sseReader) Next() (sseEvent, error) {
+	var (
+		event   string
+		data    strings.Builder
+		hasData bool
+	)
+	dispatch := func() sseEvent {
+		return sseEvent{Event: event, Data: strings.TrimSuffix(data.String(), "\n"), ID: r.lastID}
+	}
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if hasData {
+				return dispatch(), nil
+			}
+			// Events without data are not dispatched; their event type does not carry over.
+			event = ""
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "event":
+			event = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastID = value
+			}
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return sseEvent{}, err
+	}
+	if hasData {
+		return dispatch(), nil
+	}
+	return sseEvent{}, io.EOF
+}
+
+// scanSSELines is a bufio.SplitFunc for event stream lines, which may end in CRLF, LF or
+// a lone CR.
+func scanSSELines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the buffer may be the first half of a CRLF.
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
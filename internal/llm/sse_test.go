@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func readSSEEvents(t *testing.T, r io.Reader) []sseEvent {
+	t.Helper()
+	reader := newSSEReader(r)
+	var events []sseEvent
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestSSEReaderConformance(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		stream string
+		want   []sseEvent
+	}{
+		{
+			name:   "single data line",
+			stream: "data: hello\n\n",
+			want:   []sseEvent{{Data: "hello"}},
+		},
+		{
+			name:   "multi-line data joined with newlines",
+			stream: "data: first\ndata: second\ndata:\n\n",
+			want:   []sseEvent{{Data: "first\nsecond\n"}},
+		},
+		{
+			name:   "only one leading space is dropped",
+			stream: "data:no space\n\ndata:  two spaces\n\n",
+			want:   []sseEvent{{Data: "no space"}, {Data: " two spaces"}},
+		},
+		{
+			name:   "comments and unknown fields are ignored",
+			stream: ": ping\n\n:keep-alive\nretry: 1000\nfoo: bar\ndata: x\n\n",
+			want:   []sseEvent{{Data: "x"}},
+		},
+		{
+			name:   "event type applies to its own event only",
+			stream: "event: update\ndata: a\n\ndata: b\n\n",
+			want:   []sseEvent{{Event: "update", Data: "a"}, {Data: "b"}},
+		},
+		{
+			name:   "events without data are not dispatched",
+			stream: "event: ping\n\ndata: a\n\n",
+			want:   []sseEvent{{Data: "a"}},
+		},
+		{
+			name:   "id persists across events",
+			stream: "id: 7\ndata: a\n\ndata: b\n\n",
+			want:   []sseEvent{{Data: "a", ID: "7"}, {Data: "b", ID: "7"}},
+		},
+		{
+			name:   "field without colon",
+			stream: "data\ndata: x\n\n",
+			want:   []sseEvent{{Data: "\nx"}},
+		},
+		{
+			name:   "CRLF and lone CR line endings",
+			stream: "data: a\r\ndata: b\r\n\r\ndata: c\rdata: d\r\r",
+			want:   []sseEvent{{Data: "a\nb"}, {Data: "c\nd"}},
+		},
+		{
+			name:   "unterminated final event",
+			stream: "data: a\n\ndata: b",
+			want:   []sseEvent{{Data: "a"}, {Data: "b"}},
+		},
+		{
+			name:   "empty stream",
+			stream: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := readSSEEvents(t, strings.NewReader(tc.stream)); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("events = %#v, want %#v", got, tc.want)
+			}
+			// Reading one byte at a time splits CRLF pairs across reads.
+			if got := readSSEEvents(t, iotest.OneByteReader(strings.NewReader(tc.stream))); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("one byte at a time: events = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIProviderParsesGatewayEventStreams(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, ": OPENROUTER PROCESSING\r\n\r\n")
+		io.WriteString(w, "event: ping\r\ndata: {}\r\n\r\n")
+		// One chunk spread over several data lines.
+		io.WriteString(w, "event: message\r\ndata: {\"choices\":[{\"delta\":\r\ndata: {\"content\":\"Hel\"}}]}\r\n\r\n")
+		// Two chunks in one event.
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\ndata: {\"choices\":[{\"delta\":{\"content\":\"!\"},\"finish_reason\":\"stop\"}]}\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "gw", Provider: "openai", APIKey: "sk", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := provider.Stream(ctx, ChatRequest{Model: "gw", Messages: []Message{{Role: "user", Content: "hi"}}, Stream: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var content strings.Builder
+	for chunk := range stream {
+		if chunk.Type == ChunkError {
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		}
+		if chunk.Type == ChunkToken {
+			content.WriteString(chunk.Content)
+		}
+	}
+	if content.String() != "Hello!" {
+		t.Fatalf("unexpected content: %q", content.String())
+	}
+}
+
+func TestOpenAIProviderReportsErrorEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\n")
+		io.WriteString(w, "event: error\ndata: {\"message\":\"upstream overloaded\"}\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewFactory(server.Client()).Create(config.Model{Name: "gw", Provider: "openai", APIKey: "sk", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	stream, err := provider.Stream(context.Background(), ChatRequest{Model: "gw", Stream: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var streamErr error
+	for chunk := range stream {
+		if chunk.Type == ChunkError {
+			streamErr = chunk.Err
+		}
+	}
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "upstream overloaded") {
+		t.Fatalf("expected the error event, got %v", streamErr)
+	}
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+)
+
+// maxCachedToolSets bounds the tool schema cache; routing and tool filters only produce a
+// handful of distinct tool sets in a session.
+const maxCachedToolSets = 16
+
+// toolSchemaCache keeps encoded tools arrays between turns. A provider is created for every
+// turn, so the factory owns the cache and hands it to the providers it creates.
+type toolSchemaCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]encodedTools
+}
+
+// encodedTools is the tools array as sent to the API, with the definitions indexed by
+// function name for resolving the model's calls.
+type encodedTools struct {
+	payload     json.RawMessage
+	definitions map[string]ToolDefinition
+}
+
+// encode returns the tools array for defs, building it only the first time the same
+// definitions are seen. A nil cache builds every time.
+func (c *toolSchemaCache) encode(defs []ToolDefinition) (encodedTools, error) {
+	if len(defs) == 0 {
+		return encodedTools{}, nil
+	}
+	if c == nil {
+		return buildEncodedTools(defs)
+	}
+	fingerprint, err := json.Marshal(defs)
+	if err != nil {
+		return encodedTools{}, err
+	}
+	key := sha256.Sum256(fingerprint)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.entries[key]; ok {
+		return cached, nil
+	}
+	encoded, err := buildEncodedTools(defs)
+	if err != nil {
+		return encodedTools{}, err
+	}
+	if c.entries == nil || len(c.entries) >= maxCachedToolSets {
+		c.entries = map[[sha256.Size]byte]encodedTools{}
+	}
+	c.entries[key] = encoded
+	return encoded, nil
+}
+
+func buildEncodedTools(defs []ToolDefinition) (encodedTools, error) {
+	tools, definitions := buildOpenAITools(defs)
+	payload, err := json.Marshal(tools)
+	if err != nil {
+		return encodedTools{}, err
+	}
+	return encodedTools{payload: payload, definitions: definitions}, nil
+}
@@ -2,12 +2,42 @@ package llm
 
 import (
 	"context"
+	"strings"
 )
 
 // Message represents a single conversation message.
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// ToolCalls lists the tools an assistant message asked to call.
+	ToolCalls []MessageToolCall `json:"toolCalls,omitempty"`
+	// ToolCallID ties a "tool" message to the call it answers.
+	ToolCallID string `json:"toolCallId,omitempty"`
+	// ToolName is the namespaced tool that produced a "tool" message.
+	ToolName string `json:"toolName,omitempty"`
+}
+
+// MessageToolCall is a past tool invocation replayed into the conversation context.
+type MessageToolCall struct {
+	ID        string         `json:"id"`
+	Server    string         `json:"server"`
+	Method    string         `json:"method"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// Name returns the namespaced tool name the model was offered for this call.
+func (c MessageToolCall) Name() string {
+	return ToolName(c.Server, c.Method)
+}
+
+// ToolName builds the "server__method" name under which MCP tools are offered to models.
+func ToolName(server, method string) string {
+	server = strings.TrimSpace(server)
+	method = strings.TrimSpace(method)
+	if server != "" && method != "" {
+		return server + "__" + method
+	}
+	return method
 }
 
 // Logger allows providers to emit debug logs without depending on a concrete implementation.
@@ -40,6 +70,11 @@ type ChatRequest struct {
 	SystemPrompt string           `json:"systemPrompt,omitempty"`
 	Stream       bool             `json:"stream"`
 	Tools        []ToolDefinition `json:"tools,omitempty"`
+	// ExpectToolCall reports that the first answer should call one of Tools, as when the
+	// chooseFunction step has already picked the tool.
+	ExpectToolCall bool `json:"expectToolCall,omitempty"`
+	// TurnID is copied onto every chunk of the response stream.
+	TurnID string `json:"turnId,omitempty"`
 }
 
 // ChunkType is the type of a streaming response chunk.
@@ -56,6 +91,11 @@ const (
 	ChunkDone
 	// ChunkError signals an error mid stream.
 	ChunkError
+	// ChunkRouting reports which upstream model actually served the response.
+	ChunkRouting
+	// ChunkRetry reports that the provider sent its request again, e.g. to have a
+	// malformed tool call repaired; Content says why.
+	ChunkRetry
 )
 
 // ToolCallResponder handles sending a tool result back to the LLM provider.
@@ -74,6 +114,9 @@ type ToolCall struct {
 type ToolResult struct {
 	Content string
 	IsError bool
+	// Assets lists the files binary content of the result was saved to; Content refers
+	// to them by path.
+	Assets []string
 }
 
 // ToolDefinition describes a single callable tool provided to the LLM.
@@ -85,12 +128,28 @@ type ToolDefinition struct {
 	Parameters  map[string]any `json:"parameters"`
 }
 
+// RoutingInfo describes the upstream model chosen by a routing provider.
+type RoutingInfo struct {
+	Model    string
+	Provider string
+}
+
+// FinishLength is the finish reason reported when the model stopped at its token limit.
+const FinishLength = "length"
+
 // StreamChunk represents a single streamed chunk.
 type StreamChunk struct {
 	Type     ChunkType
 	Content  string
 	Err      error
 	ToolCall *ToolCall
+	Routing  *RoutingInfo
+	// FinishReason is set on ChunkDone when the provider reported why generation stopped.
+	FinishReason string
+	// Seq numbers the chunks of one stream from 1 without gaps, in the order they were sent.
+	Seq uint64
+	// TurnID is the ChatRequest's TurnID, identifying the turn the chunk belongs to.
+	TurnID string
 }
 
 // ChatProvider defines streaming chat interactions.
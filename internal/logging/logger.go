@@ -7,6 +7,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
 )
 
 // Level represents the severity of a log message.
@@ -39,7 +41,7 @@ type Logger struct {
 // NewLogger creates a logger rooted at the user's home directory.
 func NewLogger(home string, level string) (*Logger, error) {
 	ll := parseLevel(level)
-	dir := filepath.Join(home, ".humble-ai-cli", "logs")
+	dir := filepath.Join(config.Dir(home), "logs")
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, fmt.Errorf("create log directory: %w", err)
 	}
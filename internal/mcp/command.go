@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultShutdownTimeout is how long a command server gets to exit after its stdin is
+// closed, and again after killSignal, before it is killed.
+const defaultShutdownTimeout = 5 * time.Second
+
+// killSignals are the signals killSignal may name; all exist on every platform, though
+// Windows can only deliver SIGKILL, so the others fall through to killing the process.
+var killSignals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+}
+
+// parseKillSignal accepts a signal name with or without the SIG prefix, in any case.
+func parseKillSignal(name, value string) (syscall.Signal, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return syscall.SIGTERM, nil
+	}
+	if !strings.HasPrefix(value, "SIG") {
+		value = "SIG" + value
+	}
+	sig, ok := killSignals[value]
+	if !ok {
+		return 0, fmt.Errorf("server %q has unsupported killSignal %q (use SIGTERM, SIGINT, SIGHUP, SIGQUIT or SIGKILL)", name, value)
+	}
+	return sig, nil
+}
+
+// commandTransport runs a command server like sdk.CommandTransport, but shuts it down
+// on the server's own terms: close stdin, wait ShutdownTimeout, send KillSignal, wait
+// ShutdownTimeout again, then kill it.
+type commandTransport struct {
+	cmd             *exec.Cmd
+	shutdownTimeout time.Duration
+	killSignal      os.Signal
+}
+
+func newCommandTransport(home string, cfg serverConfig) *commandTransport {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = cfg.commandEnv(os.Environ())
+	if cfg.Cwd != "" {
+		cmd.Dir = expandHome(home, cfg.Cwd)
+	}
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	return &commandTransport{cmd: cmd, shutdownTimeout: timeout, killSignal: cfg.KillSignal}
+}
+
+func (t *commandTransport) Connect(ctx context.Context) (sdk.Connection, error) {
+	// The SDK's own SIGTERM and SIGKILL come only after ours have had their turn.
+	inner := &sdk.CommandTransport{Command: t.cmd, TerminateDuration: 3 * t.shutdownTimeout}
+	conn, err := inner.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &commandConn{Connection: conn, transport: t}, nil
+}
+
+// kill stops a process that never finished connecting.
+func (t *commandTransport) kill() {
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+		_ = t.cmd.Wait()
+	}
+}
+
+type commandConn struct {
+	sdk.Connection
+	transport *commandTransport
+	once      sync.Once
+	err       error
+}
+
+func (c *commandConn) Close() error {
+	c.once.Do(func() {
+		done := make(chan error, 1)
+		go func() { done <- c.Connection.Close() }()
+		process := c.transport.cmd.Process
+		timeout := c.transport.shutdownTimeout
+		select {
+		case c.err = <-done:
+			return
+		case <-time.After(timeout):
+		}
+		if c.transport.killSignal == nil || process.Signal(c.transport.killSignal) != nil {
+			_ = process.Kill()
+			c.err = <-done
+			return
+		}
+		select {
+		case c.err = <-done:
+			return
+		case <-time.After(timeout):
+		}
+		_ = process.Kill()
+		c.err = <-done
+	})
+	return c.err
+}
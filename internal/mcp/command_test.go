@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const helperEnv = "HUMBLE_MCP_COMMAND_HELPER"
+
+// TestCommandServerHelper is the command server started by the tests below. It keeps
+// running after its stdin closes, so only a signal stops it, and records which one.
+func TestCommandServerHelper(t *testing.T) {
+	marker := os.Getenv(helperEnv)
+	if marker == "" {
+		t.Skip("helper process only")
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	server := sdk.NewServer(&sdk.Implementation{Name: "helper", Version: "0.0.1"}, nil)
+	_ = server.Run(context.Background(), &sdk.StdioTransport{})
+	sig := <-signals
+	cwd, _ := os.Getwd()
+	if os.Getenv("HELPER_IGNORE_SIGNAL") != "" {
+		_ = os.WriteFile(marker, []byte("ignored "+sig.String()), 0o644)
+		select {}
+	}
+	_ = os.WriteFile(marker, []byte(sig.String()+"\n"+cwd), 0o644)
+	os.Exit(0)
+}
+
+func dialHelper(t *testing.T, raw rawServerConfig) (*sessionHolder, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("signals other than kill cannot be delivered on Windows")
+	}
+	marker := filepath.Join(t.TempDir(), "marker")
+	raw.Command = os.Args[0]
+	raw.Args = []string{"-test.run=^TestCommandServerHelper$"}
+	if raw.Env == nil {
+		raw.Env = map[string]string{}
+	}
+	raw.Env[helperEnv] = marker
+	cfg, err := buildServerConfig("helper", raw)
+	if err != nil {
+		t.Fatalf("buildServerConfig() error = %v", err)
+	}
+	holder, err := defaultSessionDialer(t.TempDir())(context.Background(), cfg, nil)
+	if err != nil {
+		t.Fatalf("dial error = %v", err)
+	}
+	return holder, marker
+}
+
+func TestCommandTransportUsesCwdAndKillSignal(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder, marker := dialHelper(t, rawServerConfig{Cwd: dir, ShutdownTimeout: "100ms", KillSignal: "int"})
+
+	start := time.Now()
+	_ = holder.Close()
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("Close took %v", elapsed)
+	}
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("expected the helper to record a signal: %v", err)
+	}
+	if got := string(data); got != "interrupt\n"+dir {
+		t.Fatalf("marker = %q, want the interrupt signal and cwd %s", got, dir)
+	}
+}
+
+func TestCommandTransportKillsServerIgnoringSignal(t *testing.T) {
+	holder, marker := dialHelper(t, rawServerConfig{
+		ShutdownTimeout: "100ms",
+		Env:             map[string]string{"HELPER_IGNORE_SIGNAL": "1"},
+	})
+
+	start := time.Now()
+	_ = holder.Close()
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("Close took %v; the server should be killed after the second timeout", elapsed)
+	}
+	data, err := os.ReadFile(marker)
+	if err != nil || !strings.HasPrefix(string(data), "ignored terminated") {
+		t.Fatalf("expected SIGTERM by default before the kill, got %q (%v)", data, err)
+	}
+}
+
+func TestBuildServerConfigValidatesShutdownSettings(t *testing.T) {
+	for _, raw := range []rawServerConfig{
+		{Command: "x", Cwd: "relative/dir"},
+		{Command: "x", ShutdownTimeout: "soon"},
+		{Command: "x", KillSignal: "SIGUSR9"},
+	} {
+		if _, err := buildServerConfig("files", raw); err == nil {
+			t.Fatalf("expected an error for %+v", raw)
+		}
+	}
+	cfg, err := buildServerConfig("files", rawServerConfig{Command: "x", Cwd: "~/proj", ShutdownTimeout: "2s", KillSignal: "sighup"})
+	if err != nil {
+		t.Fatalf("buildServerConfig() error = %v", err)
+	}
+	if cfg.Cwd != "~/proj" || cfg.ShutdownTimeout != 2*time.Second || cfg.KillSignal != syscall.SIGHUP {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
+
+func TestCommandTransportExpandsCwdInTheManagersHome(t *testing.T) {
+	home := t.TempDir()
+	cfg, err := buildServerConfig("files", rawServerConfig{Command: "x", Cwd: "~/proj"})
+	if err != nil {
+		t.Fatalf("buildServerConfig() error = %v", err)
+	}
+	if got, want := newCommandTransport(home, cfg).cmd.Dir, filepath.Join(home, "proj"); got != want {
+		t.Fatalf("cmd.Dir = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// assetsDir is where binary tool results are saved.
+func assetsDir(home string) string {
+	return filepath.Join(config.Dir(home), "assets")
+}
+
+// convertResult turns a tool result into text for the model. Images, audio and binary
+// resources cannot go into the conversation, so they are saved under the assets directory
+// and the model gets a line naming the saved file instead.
+func (m *Manager) convertResult(res *sdk.CallToolResult) (llm.ToolResult, error) {
+	if res == nil {
+		return llm.ToolResult{}, errors.New("nil result returned from MCP server")
+	}
+
+	var (
+		builder strings.Builder
+		assets  []string
+	)
+	write := func(text string) {
+		if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n") {
+			builder.WriteByte('\n')
+		}
+		builder.WriteString(text)
+	}
+	saveBinary := func(kind, mimeType string, data []byte) error {
+		path, err := saveAsset(assetsDir(m.home), mimeType, data)
+		if err != nil {
+			return fmt.Errorf("save %s from MCP result: %w", kind, err)
+		}
+		assets = append(assets, path)
+		write(assetReference(kind, mimeType, path, len(data)))
+		return nil
+	}
+
+	for _, content := range res.Content {
+		var err error
+		switch c := content.(type) {
+		case *sdk.TextContent:
+			builder.WriteString(c.Text)
+		case *sdk.ImageContent:
+			err = saveBinary("image", c.MIMEType, c.Data)
+		case *sdk.AudioContent:
+			err = saveBinary("audio", c.MIMEType, c.Data)
+		case *sdk.EmbeddedResource:
+			switch {
+			case c.Resource == nil:
+			case c.Resource.Blob != nil:
+				err = saveBinary("resource "+c.Resource.URI, c.Resource.MIMEType, c.Resource.Blob)
+			default:
+				write(c.Resource.Text)
+			}
+		case *sdk.ResourceLink:
+			write(fmt.Sprintf("[resource link %s]", c.URI))
+		}
+		if err != nil {
+			return llm.ToolResult{}, err
+		}
+	}
+
+	if builder.Len() == 0 && res.StructuredContent != nil {
+		data, err := json.Marshal(res.StructuredContent)
+		if err != nil {
+			return llm.ToolResult{}, fmt.Errorf("marshal structured MCP result: %w", err)
+		}
+		builder.Write(data)
+	}
+
+	return llm.ToolResult{
+		Content: builder.String(),
+		IsError: res.IsError,
+		Assets:  assets,
+	}, nil
+}
+
+func assetReference(kind, mimeType, path string, size int) string {
+	if mimeType == "" {
+		mimeType = "unknown type"
+	}
+	return fmt.Sprintf("[%s saved to %s (%s, %d bytes)]", kind, path, mimeType, size)
+}
+
+// saveAsset writes data under dir, named by its content hash so the same image returned
+// twice is stored once.
+func saveAsset(dir, mimeType string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	path := filepath.Join(dir, hex.EncodeToString(sum[:8])+assetExtension(mimeType))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// assetExtension picks a file extension for mimeType, preferring the common one where
+// the system table lists several.
+func assetExtension(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	base = strings.ToLower(strings.TrimSpace(base))
+	switch base {
+	case "":
+		return ".bin"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "audio/mpeg":
+		return ".mp3"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "application/pdf":
+		return ".pdf"
+	}
+	if exts, err := mime.ExtensionsByType(base); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestConvertResultSavesBinaryContent(t *testing.T) {
+	home := t.TempDir()
+	m := &Manager{home: home}
+	png := []byte("\x89PNG\r\n\x1a\nfake image")
+	blob := []byte{0x00, 0x01, 0x02}
+
+	res, err := m.convertResult(&sdk.CallToolResult{
+		Content: []sdk.Content{
+			&sdk.TextContent{Text: "Here is the chart."},
+			&sdk.ImageContent{Data: png, MIMEType: "image/png"},
+			&sdk.EmbeddedResource{Resource: &sdk.ResourceContents{URI: "file:///notes.txt", MIMEType: "text/plain", Text: "embedded notes"}},
+			&sdk.EmbeddedResource{Resource: &sdk.ResourceContents{URI: "file:///data.bin", Blob: blob}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("convertResult: %v", err)
+	}
+	if len(res.Assets) != 2 {
+		t.Fatalf("expected 2 saved assets, got %v", res.Assets)
+	}
+	for _, path := range res.Assets {
+		if filepath.Dir(path) != assetsDir(home) {
+			t.Fatalf("asset %s saved outside %s", path, assetsDir(home))
+		}
+		if !strings.Contains(res.Content, path) {
+			t.Fatalf("expected content to reference %s, got %q", path, res.Content)
+		}
+	}
+	if filepath.Ext(res.Assets[0]) != ".png" || filepath.Ext(res.Assets[1]) != ".bin" {
+		t.Fatalf("unexpected asset names: %v", res.Assets)
+	}
+	saved, err := os.ReadFile(res.Assets[0])
+	if err != nil || !bytes.Equal(saved, png) {
+		t.Fatalf("expected the image bytes to be saved, got %q (%v)", saved, err)
+	}
+
+	want := []string{
+		"Here is the chart.",
+		"[image saved to " + res.Assets[0] + " (image/png, 18 bytes)]",
+		"embedded notes",
+		"[resource file:///data.bin saved to " + res.Assets[1] + " (unknown type, 3 bytes)]",
+	}
+	if got := strings.Split(res.Content, "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected content:\n%s", res.Content)
+	}
+}
+
+func TestConvertResultKeepsTextOnlyResultsUnchanged(t *testing.T) {
+	m := &Manager{home: t.TempDir()}
+	res, err := m.convertResult(&sdk.CallToolResult{
+		Content: []sdk.Content{&sdk.TextContent{Text: "a"}, &sdk.TextContent{Text: "b"}},
+		IsError: true,
+	})
+	if err != nil {
+		t.Fatalf("convertResult: %v", err)
+	}
+	if res.Content != "ab" || !res.IsError || res.Assets != nil {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if _, err := os.Stat(assetsDir(m.home)); !os.IsNotExist(err) {
+		t.Fatalf("expected no assets directory for text results, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LogMessage is a logging notification sent by an MCP server.
+type LogMessage struct {
+	Server string
+	// Level is the MCP severity: debug, info, notice, warning, error, critical, alert or emergency.
+	Level  string
+	Logger string
+	Text   string
+}
+
+// SetLogHandler routes logging notifications from servers connected afterwards to fn.
+// Servers that advertise logging support are asked to send messages at level and above.
+func (m *Manager) SetLogHandler(level string, fn func(LogMessage)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logHandler = fn
+	m.logLevel = strings.TrimSpace(level)
+}
+
+// clientOptions must be called with m.mu held.
+func (m *Manager) clientOptions(server string) *sdk.ClientOptions {
+	if m.logHandler == nil {
+		return nil
+	}
+	handler := m.logHandler
+	return &sdk.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *sdk.LoggingMessageRequest) {
+			if req == nil || req.Params == nil {
+				return
+			}
+			handler(LogMessage{
+				Server: server,
+				Level:  string(req.Params.Level),
+				Logger: req.Params.Logger,
+				Text:   formatLogData(req.Params.Data),
+			})
+		},
+	}
+}
+
+// subscribeLogs sets the server's log level so it starts sending notifications.
+func (m *Manager) subscribeLogs(ctx context.Context, holder *sessionHolder) {
+	m.mu.Lock()
+	level := m.logLevel
+	enabled := m.logHandler != nil
+	m.mu.Unlock()
+	if !enabled || level == "" || holder.session == nil {
+		return
+	}
+	init := holder.session.InitializeResult()
+	if init == nil || init.Capabilities == nil || init.Capabilities.Logging == nil {
+		return
+	}
+	_ = holder.session.SetLoggingLevel(ctx, &sdk.SetLoggingLevelParams{Level: sdk.LoggingLevel(level)})
+}
+
+func formatLogData(data any) string {
+	switch v := data.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprint(data)
+	}
+	return string(encoded)
+}
@@ -7,14 +7,18 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/jsoncheck"
 	"github.com/gamzabox/humble-ai-cli/internal/llm"
 )
 
@@ -46,8 +50,24 @@ type serverConfig struct {
 	Command     string
 	Args        []string
 	Env         map[string]string
-	URL         string
-	Transport   string
+	// InheritEnv passes the CLI's environment to a command server; when false only the
+	// baseline variables, EnvAllowlist matches and Env reach the process.
+	InheritEnv   bool
+	EnvAllowlist []string
+	// Cwd is the command server's working directory; ShutdownTimeout and KillSignal
+	// control how it is stopped.
+	Cwd             string
+	ShutdownTimeout time.Duration
+	KillSignal      os.Signal
+	URL             string
+	Transport       string
+	// AllowedPaths restricts path-typed tool arguments to these roots when non-empty.
+	AllowedPaths []string
+	// IdleTimeout closes the session after this long without calls; zero keeps it open.
+	IdleTimeout time.Duration
+	// Prewarm connects at startup and pings every PingInterval to keep the session alive.
+	Prewarm      bool
+	PingInterval time.Duration
 }
 
 const (
@@ -86,7 +106,7 @@ func (cfg serverConfig) connectionKind() (string, error) {
 	}
 }
 
-type sessionDialer func(context.Context, serverConfig) (*sessionHolder, error)
+type sessionDialer func(context.Context, serverConfig, *sdk.ClientOptions) (*sessionHolder, error)
 
 // Manager loads server configurations and executes MCP tool calls.
 type Manager struct {
@@ -95,6 +115,18 @@ type Manager struct {
 	servers  map[string]serverConfig
 	sessions map[string]*sessionHolder
 	connect  sessionDialer
+
+	// keepAlive holds the cancel funcs of running prewarm loops, keyed by server name.
+	keepAlive  map[string]context.CancelFunc
+	prewarmCtx context.Context
+	closed     bool
+
+	logHandler func(LogMessage)
+	logLevel   string
+
+	// dropSession, when set, decides before each tool call whether to simulate a dropped
+	// connection (chaos testing).
+	dropSession func() bool
 }
 
 // NewManager creates a Manager rooted at the provided home directory.
@@ -104,10 +136,11 @@ func NewManager(home string) (*Manager, error) {
 		return nil, err
 	}
 	return &Manager{
-		home:     home,
-		servers:  servers,
-		sessions: make(map[string]*sessionHolder),
-		connect:  defaultSessionDialer,
+		home:      home,
+		servers:   servers,
+		sessions:  make(map[string]*sessionHolder),
+		connect:   defaultSessionDialer(home),
+		keepAlive: make(map[string]context.CancelFunc),
 	}, nil
 }
 
@@ -144,6 +177,14 @@ func (m *Manager) Describe(name string) (Server, bool) {
 
 // Call executes the given tool on the specified server.
 func (m *Manager) Call(ctx context.Context, server, method string, arguments map[string]any) (llm.ToolResult, error) {
+	m.mu.Lock()
+	allowed := m.servers[server].AllowedPaths
+	drop := m.dropSession
+	m.mu.Unlock()
+	if err := checkAllowedPaths(m.home, allowed, arguments); err != nil {
+		return llm.ToolResult{}, fmt.Errorf("call tool %q on server %q: %w", method, server, err)
+	}
+
 	params := &sdk.CallToolParams{
 		Name:      method,
 		Arguments: arguments,
@@ -156,9 +197,15 @@ func (m *Manager) Call(ctx context.Context, server, method string, arguments map
 			return llm.ToolResult{}, err
 		}
 
-		result, err := holder.session.CallTool(ctx, params)
+		var result *sdk.CallToolResult
+		if drop != nil && drop() {
+			err = fmt.Errorf("injected disconnect: %w", sdk.ErrConnectionClosed)
+		} else {
+			result, err = holder.session.CallTool(ctx, params)
+		}
+		m.release(server, holder)
 		if err == nil {
-			return convertResult(result)
+			return m.convertResult(result)
 		}
 
 		lastErr = err
@@ -170,6 +217,14 @@ func (m *Manager) Call(ctx context.Context, server, method string, arguments map
 	return llm.ToolResult{}, fmt.Errorf("call tool %q on server %q: %w", method, server, lastErr)
 }
 
+// InjectDisconnects makes tool calls behave as if the session dropped whenever drop
+// returns true, so the reconnect path can be exercised.
+func (m *Manager) InjectDisconnects(drop func() bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropSession = drop
+}
+
 // Tools lists functions provided by the specified server.
 func (m *Manager) Tools(ctx context.Context, server string) ([]Function, error) {
 	var (
@@ -182,6 +237,7 @@ func (m *Manager) Tools(ctx context.Context, server string) ([]Function, error)
 		}
 
 		tools, err := m.fetchTools(ctx, holder.session)
+		m.release(server, holder)
 		if err == nil {
 			return tools, nil
 		}
@@ -197,6 +253,11 @@ func (m *Manager) Tools(ctx context.Context, server string) ([]Function, error)
 // Close shuts down all cached MCP sessions.
 func (m *Manager) Close() error {
 	m.mu.Lock()
+	m.closed = true
+	for name, cancel := range m.keepAlive {
+		cancel()
+		delete(m.keepAlive, name)
+	}
 	sessions := make([]*sessionHolder, 0, len(m.sessions))
 	for name, holder := range m.sessions {
 		if holder != nil {
@@ -242,6 +303,7 @@ func (m *Manager) Reload() error {
 	for _, holder := range toClose {
 		_ = holder.Close()
 	}
+	m.syncKeepAlive()
 	return nil
 }
 
@@ -260,6 +322,7 @@ func (m *Manager) ensureSession(ctx context.Context, name string) (*sessionHolde
 	holder := m.sessions[name]
 	var stale *sessionHolder
 	if holder != nil && holder.alive() {
+		holder.acquire()
 		m.mu.Unlock()
 		return holder, nil
 	}
@@ -268,28 +331,66 @@ func (m *Manager) ensureSession(ctx context.Context, name string) (*sessionHolde
 		stale = holder
 	}
 	dial := m.connect
+	opts := m.clientOptions(name)
 	m.mu.Unlock()
 
 	if stale != nil {
 		_ = stale.Close()
 	}
 
-	newHolder, err := dial(ctx, cfg)
+	newHolder, err := dial(ctx, cfg, opts)
 	if err != nil {
 		return nil, fmt.Errorf("connect MCP server %q: %w", name, err)
 	}
+	m.subscribeLogs(ctx, newHolder)
+	m.reportCapabilities(name, newHolder)
 
 	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		_ = newHolder.Close()
+		return nil, fmt.Errorf("connect MCP server %q: manager closed", name)
+	}
 	if existing := m.sessions[name]; existing != nil && existing.alive() {
+		existing.acquire()
 		m.mu.Unlock()
 		_ = newHolder.Close()
 		return existing, nil
 	}
 	m.sessions[name] = newHolder
+	newHolder.acquire()
 	m.mu.Unlock()
 	return newHolder, nil
 }
 
+// release marks the end of a call started by ensureSession and arms the idle timer
+// once the session has no calls in flight.
+func (m *Manager) release(server string, holder *sessionHolder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	holder.active--
+	if holder.active > 0 || m.sessions[server] != holder {
+		return
+	}
+	if cfg := m.servers[server]; cfg.IdleTimeout > 0 && !cfg.Prewarm {
+		holder.idleTimer = time.AfterFunc(cfg.IdleTimeout, func() { m.reapIdle(server, holder) })
+	}
+}
+
+// reapIdle closes a session that stayed unused for its idle timeout. The next call
+// reconnects transparently through ensureSession.
+func (m *Manager) reapIdle(server string, holder *sessionHolder) {
+	m.mu.Lock()
+	if m.sessions[server] != holder || holder.active > 0 {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.sessions, server)
+	holder.idleTimer = nil
+	m.mu.Unlock()
+	_ = holder.Close()
+}
+
 func (m *Manager) handleSessionError(server string, holder *sessionHolder, err error) bool {
 	if !errors.Is(err, sdk.ErrConnectionClosed) {
 		return false
@@ -333,11 +434,19 @@ func (m *Manager) fetchTools(ctx context.Context, session *sdk.ClientSession) ([
 	return out, nil
 }
 
-func defaultSessionDialer(ctx context.Context, cfg serverConfig) (*sessionHolder, error) {
+// defaultSessionDialer connects to servers for a Manager rooted at home, which a command
+// server's cwd may start with ~/ to name.
+func defaultSessionDialer(home string) sessionDialer {
+	return func(ctx context.Context, cfg serverConfig, opts *sdk.ClientOptions) (*sessionHolder, error) {
+		return dialSession(ctx, home, cfg, opts)
+	}
+}
+
+func dialSession(ctx context.Context, home string, cfg serverConfig, opts *sdk.ClientOptions) (*sessionHolder, error) {
 	client := sdk.NewClient(&sdk.Implementation{
 		Name:    "humble-ai-cli",
 		Version: "0.1.0",
-	}, nil)
+	}, opts)
 
 	kind, err := cfg.connectionKind()
 	if err != nil {
@@ -346,17 +455,10 @@ func defaultSessionDialer(ctx context.Context, cfg serverConfig) (*sessionHolder
 
 	switch kind {
 	case transportCommand:
-		cmd := exec.Command(cfg.Command, cfg.Args...)
-		if env := envList(cfg.Env); len(env) > 0 {
-			cmd.Env = append(os.Environ(), env...)
-		}
-		transport := &sdk.CommandTransport{Command: cmd}
+		transport := newCommandTransport(home, cfg)
 		session, err := client.Connect(ctx, transport, nil)
 		if err != nil {
-			if cmd.Process != nil {
-				_ = cmd.Process.Kill()
-				_ = cmd.Wait()
-			}
+			transport.kill()
 			return nil, err
 		}
 		return newSessionHolder(session, nil), nil
@@ -393,6 +495,10 @@ type sessionHolder struct {
 	session    *sdk.ClientSession
 	extraClose func() error
 
+	// active and idleTimer track in-flight calls for idle reaping; guarded by Manager.mu.
+	active    int
+	idleTimer *time.Timer
+
 	once sync.Once
 	done chan struct{}
 
@@ -415,6 +521,14 @@ func newSessionHolder(session *sdk.ClientSession, extraClose func() error) *sess
 	return holder
 }
 
+func (h *sessionHolder) acquire() {
+	h.active++
+	if h.idleTimer != nil {
+		h.idleTimer.Stop()
+		h.idleTimer = nil
+	}
+}
+
 func (h *sessionHolder) Close() error {
 	err := h.session.Close()
 	h.recordClose(err)
@@ -503,7 +617,7 @@ func defaultSchema() map[string]any {
 }
 
 func configFilePath(home string) string {
-	return filepath.Join(home, ".humble-ai-cli", "mcp-servers.json")
+	return filepath.Join(config.Dir(home), "mcp-servers.json")
 }
 
 func readConfigFile(home string) (mcpConfigFile, error) {
@@ -518,7 +632,7 @@ func readConfigFile(home string) (mcpConfigFile, error) {
 
 	var file mcpConfigFile
 	if err := json.Unmarshal(data, &file); err != nil {
-		return mcpConfigFile{}, fmt.Errorf("parse MCP server config: %w", err)
+		return mcpConfigFile{}, fmt.Errorf("parse MCP server config: %w", jsoncheck.Annotate(data, err))
 	}
 	if file.Servers == nil {
 		file.Servers = map[string]rawServerConfig{}
@@ -526,6 +640,34 @@ func readConfigFile(home string) (mcpConfigFile, error) {
 	return file, nil
 }
 
+// CheckConfigFile reports unknown fields, type mismatches and invalid server entries in the
+// MCP server config file at path. A missing file yields os.ErrNotExist.
+func CheckConfigFile(path string) ([]jsoncheck.Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := jsoncheck.Check(data, mcpConfigFile{})
+	if err != nil {
+		return nil, fmt.Errorf("parse MCP server config: %w", err)
+	}
+	var file mcpConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return issues, nil
+	}
+	keys := make([]string, 0, len(file.Servers))
+	for key := range file.Servers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := buildServerConfig(key, file.Servers[key]); err != nil {
+			issues = append(issues, jsoncheck.Issue{Path: "mcpServers." + key, Message: err.Error()})
+		}
+	}
+	return issues, nil
+}
+
 func writeConfigFile(home string, file mcpConfigFile) error {
 	path := configFilePath(home)
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
@@ -658,14 +800,36 @@ type mcpConfigFile struct {
 }
 
 type rawServerConfig struct {
-	Name        string            `json:"name,omitempty"`
-	Description string            `json:"description,omitempty"`
-	Enabled     *bool             `json:"enabled,omitempty"`
-	Command     string            `json:"command,omitempty"`
-	Args        []string          `json:"args,omitempty"`
-	Env         map[string]string `json:"env,omitempty"`
-	URL         string            `json:"url,omitempty"`
-	Transport   string            `json:"transport,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Enabled      *bool             `json:"enabled,omitempty"`
+	Command      string            `json:"command,omitempty"`
+	Args         []string          `json:"args,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+	InheritEnv   *bool             `json:"inheritEnv,omitempty"`
+	EnvAllowlist []string          `json:"envAllowlist,omitempty"`
+	Cwd          string            `json:"cwd,omitempty"`
+	// ShutdownTimeout and KillSignal apply to command servers, e.g. "10s" and "SIGINT".
+	ShutdownTimeout string   `json:"shutdownTimeout,omitempty"`
+	KillSignal      string   `json:"killSignal,omitempty"`
+	URL             string   `json:"url,omitempty"`
+	Transport       string   `json:"transport,omitempty"`
+	AllowedPaths    []string `json:"allowedPaths,omitempty"`
+	IdleTimeout     string   `json:"idleTimeout,omitempty"`
+	Prewarm         bool     `json:"prewarm,omitempty"`
+	PingInterval    string   `json:"pingInterval,omitempty"`
+}
+
+func parseServerDuration(name, field, value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("server %q has invalid %s %q (use a duration such as \"10m\")", name, field, value)
+	}
+	return d, nil
 }
 
 func buildServerConfig(key string, raw rawServerConfig) (serverConfig, error) {
@@ -684,9 +848,57 @@ func buildServerConfig(key string, raw rawServerConfig) (serverConfig, error) {
 		Command:     strings.TrimSpace(raw.Command),
 		Args:        append([]string(nil), raw.Args...),
 		Env:         cloneStringMap(raw.Env),
+		InheritEnv:  raw.InheritEnv == nil || *raw.InheritEnv,
 		URL:         strings.TrimSpace(raw.URL),
 		Transport:   strings.ToLower(strings.TrimSpace(raw.Transport)),
 	}
+	for _, pattern := range raw.EnvAllowlist {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return serverConfig{}, fmt.Errorf("server %q has invalid envAllowlist entry %q", name, pattern)
+		}
+		cfg.EnvAllowlist = append(cfg.EnvAllowlist, pattern)
+	}
+	if cfg.InheritEnv && len(cfg.EnvAllowlist) > 0 {
+		return serverConfig{}, fmt.Errorf("server %q sets envAllowlist without \"inheritEnv\": false", name)
+	}
+	for _, path := range raw.AllowedPaths {
+		path = strings.TrimSpace(path)
+		if path != "~" && !strings.HasPrefix(path, "~/") && !filepath.IsAbs(path) {
+			return serverConfig{}, fmt.Errorf("server %q allowedPaths entry %q must be absolute or start with ~/", name, path)
+		}
+		cfg.AllowedPaths = append(cfg.AllowedPaths, path)
+	}
+	if cwd := strings.TrimSpace(raw.Cwd); cwd != "" {
+		if cwd != "~" && !strings.HasPrefix(cwd, "~/") && !filepath.IsAbs(cwd) {
+			return serverConfig{}, fmt.Errorf("server %q cwd %q must be absolute or start with ~/", name, cwd)
+		}
+		cfg.Cwd = cwd
+	}
+	shutdown, err := parseServerDuration(name, "shutdownTimeout", raw.ShutdownTimeout)
+	if err != nil {
+		return serverConfig{}, err
+	}
+	cfg.ShutdownTimeout = shutdown
+	signal, err := parseKillSignal(name, raw.KillSignal)
+	if err != nil {
+		return serverConfig{}, err
+	}
+	cfg.KillSignal = signal
+	idle, err := parseServerDuration(name, "idleTimeout", raw.IdleTimeout)
+	if err != nil {
+		return serverConfig{}, err
+	}
+	cfg.IdleTimeout = idle
+	cfg.Prewarm = raw.Prewarm
+	interval, err := parseServerDuration(name, "pingInterval", raw.PingInterval)
+	if err != nil {
+		return serverConfig{}, err
+	}
+	if interval == 0 {
+		interval = defaultPingInterval
+	}
+	cfg.PingInterval = interval
 	if raw.Enabled != nil {
 		cfg.Enabled = *raw.Enabled
 	}
@@ -732,6 +944,56 @@ func cloneStringMap(src map[string]string) map[string]string {
 	return dst
 }
 
+// baselineEnv are the variables a command server gets even with inheritEnv false: what
+// programs need to start and find their tools, none of which usually holds secrets.
+var baselineEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LC_ALL", "LC_CTYPE", "TERM", "TZ",
+	"TMPDIR", "TEMP", "TMP",
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// commandEnv returns the environment of a command server started from parent, or nil
+// when it simply inherits parent.
+func (cfg serverConfig) commandEnv(parent []string) []string {
+	extra := envList(cfg.Env)
+	if cfg.InheritEnv {
+		if len(extra) == 0 {
+			return nil
+		}
+		return append(append([]string(nil), parent...), extra...)
+	}
+	env := make([]string, 0, len(baselineEnv)+len(extra))
+	for _, entry := range parent {
+		name, _, ok := strings.Cut(entry, "=")
+		if ok && cfg.passesEnv(name) {
+			env = append(env, entry)
+		}
+	}
+	return append(env, extra...)
+}
+
+// passesEnv reports whether the parent's variable name reaches a server that does not
+// inherit the whole environment. Names compare case-insensitively on Windows.
+func (cfg serverConfig) passesEnv(name string) bool {
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+	for _, base := range baselineEnv {
+		if name == base {
+			return true
+		}
+	}
+	for _, pattern := range cfg.EnvAllowlist {
+		if runtime.GOOS == "windows" {
+			pattern = strings.ToUpper(pattern)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func envList(env map[string]string) []string {
 	if len(env) == 0 {
 		return nil
@@ -792,29 +1054,3 @@ func (h *headerInjector) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 	return base.RoundTrip(req)
 }
-
-func convertResult(res *sdk.CallToolResult) (llm.ToolResult, error) {
-	if res == nil {
-		return llm.ToolResult{}, errors.New("nil result returned from MCP server")
-	}
-
-	var builder strings.Builder
-	for _, content := range res.Content {
-		if text, ok := content.(*sdk.TextContent); ok {
-			builder.WriteString(text.Text)
-		}
-	}
-
-	if builder.Len() == 0 && res.StructuredContent != nil {
-		data, err := json.Marshal(res.StructuredContent)
-		if err != nil {
-			return llm.ToolResult{}, fmt.Errorf("marshal structured MCP result: %w", err)
-		}
-		builder.Write(data)
-	}
-
-	return llm.ToolResult{
-		Content: builder.String(),
-		IsError: res.IsError,
-	}, nil
-}
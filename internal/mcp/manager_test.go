@@ -3,11 +3,13 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -105,6 +107,41 @@ func TestManagerReconnectsClosedSession(t *testing.T) {
 	}
 }
 
+func TestManagerReconnectsAfterInjectedDisconnect(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"test": {
+			"enabled": true,
+			"command": "ignored",
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+	dropped := 0
+	mgr.InjectDisconnects(func() bool {
+		dropped++
+		return dropped == 1
+	})
+
+	res, err := mgr.Call(context.Background(), "test", "echo", nil)
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if res.Content != "call-1" {
+		t.Fatalf("Call() content = %q, want %q", res.Content, "call-1")
+	}
+	if got := dialer.connectionCount(); got != 2 {
+		t.Fatalf("expected a reconnect after the injected disconnect, got %d connections", got)
+	}
+}
+
 func TestManagerCloseShutsDownSessions(t *testing.T) {
 	t.Parallel()
 
@@ -184,7 +221,7 @@ func TestDefaultSessionDialerConnectsSSEServer(t *testing.T) {
 		},
 	}
 
-	holder, err := defaultSessionDialer(ctx, cfg)
+	holder, err := defaultSessionDialer(t.TempDir())(ctx, cfg, nil)
 	if err != nil {
 		t.Fatalf("defaultSessionDialer() error = %v", err)
 	}
@@ -261,7 +298,7 @@ func TestDefaultSessionDialerConnectsHTTPServer(t *testing.T) {
 		},
 	}
 
-	holder, err := defaultSessionDialer(ctx, cfg)
+	holder, err := defaultSessionDialer(t.TempDir())(ctx, cfg, nil)
 	if err != nil {
 		t.Fatalf("defaultSessionDialer() error = %v", err)
 	}
@@ -311,7 +348,7 @@ func newTestDialer(t *testing.T) *testDialer {
 	return &testDialer{t: t}
 }
 
-func (d *testDialer) connect(ctx context.Context, cfg serverConfig) (*sessionHolder, error) {
+func (d *testDialer) connect(ctx context.Context, cfg serverConfig, opts *sdk.ClientOptions) (*sessionHolder, error) {
 	ct, st := sdk.NewInMemoryTransports()
 
 	server := sdk.NewServer(&sdk.Implementation{Name: "test-server", Version: "0.0.1"}, nil)
@@ -326,7 +363,7 @@ func (d *testDialer) connect(ctx context.Context, cfg serverConfig) (*sessionHol
 		return nil, err
 	}
 
-	client := sdk.NewClient(&sdk.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	client := sdk.NewClient(&sdk.Implementation{Name: "test-client", Version: "0.0.1"}, opts)
 	session, err := client.Connect(ctx, ct, nil)
 	if err != nil {
 		_ = serverSession.Close()
@@ -415,3 +452,329 @@ func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
 	}
 	t.Fatalf("condition not met within %s", timeout)
 }
+
+func TestManagerRejectsPathsOutsideAllowedRoots(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	home := t.TempDir()
+	workspace := filepath.Join(home, "workspace")
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		t.Fatalf("mkdir workspace: %v", err)
+	}
+	if err := os.Symlink("/etc", filepath.Join(workspace, "escape")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	writeServerConfig(t, home, map[string]map[string]any{
+		"files": {
+			"command":      "ignored",
+			"allowedPaths": []string{"~/workspace"},
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+
+	allowed := []map[string]any{
+		{"path": filepath.Join(workspace, "notes.txt")},
+		{"path": "notes/today.md"},
+		{"query": "grep for /etc/passwd mentions", "limit": float64(3)},
+	}
+	for _, args := range allowed {
+		if _, err := mgr.Call(ctx, "files", "echo", args); err != nil {
+			t.Fatalf("Call(%v) error = %v", args, err)
+		}
+	}
+
+	rejected := []map[string]any{
+		{"path": "/etc/passwd"},
+		{"path": "../../etc/passwd"},
+		{"source": "~/.ssh/id_rsa"},
+		{"path": filepath.Join(workspace, "escape", "passwd")},
+		{"options": map[string]any{"paths": []any{filepath.Join(workspace, "a"), "/var/log"}}},
+		{"content": "x", "uri": "file:///etc/shadow"},
+	}
+	for _, args := range rejected {
+		_, err := mgr.Call(ctx, "files", "echo", args)
+		if !errors.Is(err, ErrPathNotAllowed) {
+			t.Fatalf("Call(%v) error = %v, want ErrPathNotAllowed", args, err)
+		}
+	}
+
+	if got := dialer.callCount; got != len(allowed) {
+		t.Fatalf("expected only allowed calls to reach the server, got %d", got)
+	}
+}
+
+func TestBuildServerConfigRejectsRelativeAllowedPaths(t *testing.T) {
+	_, err := buildServerConfig("files", rawServerConfig{Command: "x", AllowedPaths: []string{"workspace"}})
+	if err == nil {
+		t.Fatalf("expected error for relative allowedPaths entry")
+	}
+}
+
+func TestManagerReapsIdleSessionAndReconnects(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"test": {
+			"enabled":     true,
+			"command":     "ignored",
+			"idleTimeout": "20ms",
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+
+	if _, err := mgr.Call(ctx, "test", "echo", nil); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+
+	handle := dialer.firstHandle()
+	if handle == nil {
+		t.Fatalf("expected first session handle")
+	}
+	waitFor(t, time.Second, func() bool { return !handle.alive() })
+
+	res, err := mgr.Call(ctx, "test", "echo", nil)
+	if err != nil {
+		t.Fatalf("Call() after idle reap error = %v", err)
+	}
+	if res.Content != "call-2" {
+		t.Fatalf("Call() after idle reap content = %q, want %q", res.Content, "call-2")
+	}
+	if got := dialer.connectionCount(); got != 2 {
+		t.Fatalf("expected reconnection after idle reap, got %d connections", got)
+	}
+}
+
+func TestManagerKeepsSessionWithoutIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"test": {
+			"enabled": true,
+			"command": "ignored",
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+
+	if _, err := mgr.Call(ctx, "test", "echo", nil); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if handle := dialer.firstHandle(); handle == nil || !handle.alive() {
+		t.Fatalf("expected session to stay open without idleTimeout")
+	}
+}
+
+func TestBuildServerConfigRejectsInvalidIdleTimeout(t *testing.T) {
+	for _, value := range []string{"soon", "-1m"} {
+		if _, err := buildServerConfig("files", rawServerConfig{Command: "x", IdleTimeout: value}); err == nil {
+			t.Fatalf("expected error for idleTimeout %q", value)
+		}
+	}
+	cfg, err := buildServerConfig("files", rawServerConfig{Command: "x", IdleTimeout: "10m"})
+	if err != nil {
+		t.Fatalf("buildServerConfig() error = %v", err)
+	}
+	if cfg.IdleTimeout != 10*time.Minute {
+		t.Fatalf("IdleTimeout = %v, want 10m", cfg.IdleTimeout)
+	}
+}
+
+func TestServerConfigCommandEnvHonorsInheritEnv(t *testing.T) {
+	parent := []string{"PATH=/usr/bin", "HOME=/home/me", "AWS_SECRET_ACCESS_KEY=s3cret", "GITHUB_TOKEN=t", "NODE_OPTIONS=--x", "NODE_ENV=dev"}
+
+	inherit, err := buildServerConfig("files", rawServerConfig{Command: "x"})
+	if err != nil {
+		t.Fatalf("buildServerConfig() error = %v", err)
+	}
+	if env := inherit.commandEnv(parent); env != nil {
+		t.Fatalf("expected the parent environment to be inherited as is, got %v", env)
+	}
+	inherit.Env = map[string]string{"API_KEY": "k"}
+	if env := inherit.commandEnv(parent); len(env) != len(parent)+1 || env[len(env)-1] != "API_KEY=k" {
+		t.Fatalf("expected the parent environment plus env, got %v", env)
+	}
+
+	off := false
+	restricted, err := buildServerConfig("files", rawServerConfig{
+		Command:      "x",
+		Env:          map[string]string{"API_KEY": "k"},
+		InheritEnv:   &off,
+		EnvAllowlist: []string{"NODE_*"},
+	})
+	if err != nil {
+		t.Fatalf("buildServerConfig() error = %v", err)
+	}
+	got := strings.Join(restricted.commandEnv(parent), " ")
+	if want := "PATH=/usr/bin HOME=/home/me NODE_OPTIONS=--x NODE_ENV=dev API_KEY=k"; got != want {
+		t.Fatalf("commandEnv() = %q, want %q", got, want)
+	}
+	restricted.EnvAllowlist = nil
+	restricted.Env = nil
+	if env := restricted.commandEnv(parent); env == nil || len(env) != 2 {
+		t.Fatalf("expected only the baseline variables, got %v", env)
+	}
+}
+
+func TestBuildServerConfigRejectsEnvAllowlistWhileInheriting(t *testing.T) {
+	if _, err := buildServerConfig("files", rawServerConfig{Command: "x", EnvAllowlist: []string{"NODE_*"}}); err == nil {
+		t.Fatal("expected an error for envAllowlist without inheritEnv false")
+	}
+	off := false
+	if _, err := buildServerConfig("files", rawServerConfig{Command: "x", InheritEnv: &off, EnvAllowlist: []string{"["}}); err == nil {
+		t.Fatal("expected an error for an invalid envAllowlist pattern")
+	}
+}
+
+func TestManagerPrewarmConnectsAndKeepsSessionAlive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"warm": {
+			"enabled":      true,
+			"command":      "ignored",
+			"prewarm":      true,
+			"pingInterval": "20ms",
+		},
+		"cold": {
+			"enabled": true,
+			"command": "ignored",
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+	mgr.Prewarm(ctx)
+
+	waitFor(t, time.Second, func() bool { return dialer.connectionCount() == 1 })
+
+	if err := dialer.closeFirstServer(); err != nil {
+		t.Fatalf("closeFirstServer() error = %v", err)
+	}
+	waitFor(t, time.Second, func() bool { return dialer.connectionCount() == 2 })
+
+	if _, err := mgr.Call(ctx, "warm", "echo", nil); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if got := dialer.connectionCount(); got != 2 {
+		t.Fatalf("expected call to reuse the prewarmed session, got %d connections", got)
+	}
+}
+
+func TestManagerCloseStopsPrewarm(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"warm": {
+			"enabled":      true,
+			"command":      "ignored",
+			"prewarm":      true,
+			"pingInterval": "10ms",
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+	mgr.Prewarm(context.Background())
+	waitFor(t, time.Second, func() bool { return dialer.connectionCount() == 1 })
+
+	handle := dialer.firstHandle()
+	if err := mgr.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	waitFor(t, time.Second, func() bool { return !handle.alive() })
+
+	time.Sleep(50 * time.Millisecond)
+	if got := dialer.connectionCount(); got != 1 {
+		t.Fatalf("expected no reconnection after Close, got %d connections", got)
+	}
+}
+
+func TestManagerRoutesServerLogMessages(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"test": {
+			"enabled": true,
+			"command": "ignored",
+		},
+	})
+
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+
+	received := make(chan LogMessage, 4)
+	mgr.SetLogHandler("info", func(msg LogMessage) { received <- msg })
+
+	if _, err := mgr.Call(ctx, "test", "echo", nil); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+
+	dialer.mu.Lock()
+	server := dialer.serverHandles[0]
+	dialer.mu.Unlock()
+	if err := server.Log(ctx, &sdk.LoggingMessageParams{Level: "debug", Data: "too verbose"}); err != nil {
+		t.Fatalf("Log(debug) error = %v", err)
+	}
+	if err := server.Log(ctx, &sdk.LoggingMessageParams{Level: "warning", Logger: "index", Data: map[string]any{"slow": true}}); err != nil {
+		t.Fatalf("Log(warning) error = %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		want := LogMessage{Server: "test", Level: "warning", Logger: "index", Text: `{"slow":true}`}
+		if msg != want {
+			t.Fatalf("log message = %+v, want %+v", msg, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected log message from server")
+	}
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrPathNotAllowed indicates a tool argument referenced a path outside the server's allowedPaths.
+var ErrPathNotAllowed = errors.New("path not allowed")
+
+// pathArgumentHints are argument name fragments that mark a value as a filesystem path.
+var pathArgumentHints = []string{"path", "file", "dir", "folder", "root", "source", "destination", "src", "dst", "target", "cwd"}
+
+// checkAllowedPaths rejects path-typed arguments that resolve outside the allowed roots.
+// Relative paths are resolved against the first root, as filesystem servers typically do.
+func checkAllowedPaths(home string, allowed []string, arguments map[string]any) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	roots := make([]string, 0, len(allowed))
+	for _, root := range allowed {
+		roots = append(roots, resolveRealPath(expandHome(home, root)))
+	}
+	return walkPathArguments("", arguments, false, func(name, value string) error {
+		candidate := strings.TrimPrefix(value, "file://")
+		candidate = expandHome(home, candidate)
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(roots[0], candidate)
+		}
+		candidate = resolveRealPath(candidate)
+		for _, root := range roots {
+			if within(root, candidate) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: argument %q (%s) is outside %s", ErrPathNotAllowed, name, value, strings.Join(allowed, ", "))
+	})
+}
+
+func walkPathArguments(name string, value any, pathTyped bool, check func(name, value string) error) error {
+	switch v := value.(type) {
+	case string:
+		if pathTyped || looksLikePath(v) {
+			return check(name, v)
+		}
+	case []any:
+		for _, item := range v {
+			if err := walkPathArguments(name, item, pathTyped, check); err != nil {
+				return err
+			}
+		}
+	case []string:
+		for _, item := range v {
+			if err := walkPathArguments(name, item, pathTyped, check); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := key
+			if name != "" {
+				child = name + "." + key
+			}
+			if err := walkPathArguments(child, v[key], isPathArgument(key), check); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isPathArgument(name string) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range pathArgumentHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikePath(value string) bool {
+	return strings.HasPrefix(value, "/") || strings.HasPrefix(value, "~/") ||
+		strings.HasPrefix(value, "../") || strings.HasPrefix(value, "file://")
+}
+
+func expandHome(home, path string) string {
+	if path == "~" {
+		return home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// resolveRealPath cleans a path and resolves symlinks in its longest existing prefix.
+func resolveRealPath(path string) string {
+	path = filepath.Clean(path)
+	rest := ""
+	for current := path; ; {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(current), rest)
+		current = parent
+	}
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
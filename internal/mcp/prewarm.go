@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"time"
+)
+
+const defaultPingInterval = 30 * time.Second
+
+// Prewarm connects every enabled server marked with "prewarm" in the background and
+// keeps its session alive with periodic pings, so the first tool call does not pay
+// the process startup cost. The loops stop when ctx is cancelled or on Close.
+func (m *Manager) Prewarm(ctx context.Context) {
+	m.mu.Lock()
+	m.prewarmCtx = ctx
+	m.mu.Unlock()
+	m.syncKeepAlive()
+}
+
+// syncKeepAlive starts loops for newly prewarmed servers and stops loops for servers
+// that were disabled or removed, e.g. after Reload.
+func (m *Manager) syncKeepAlive() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.prewarmCtx == nil || m.closed {
+		return
+	}
+	for name, cancel := range m.keepAlive {
+		if cfg, ok := m.servers[name]; !ok || !cfg.Enabled || !cfg.Prewarm {
+			cancel()
+			delete(m.keepAlive, name)
+		}
+	}
+	for name, cfg := range m.servers {
+		if !cfg.Enabled || !cfg.Prewarm {
+			continue
+		}
+		if _, running := m.keepAlive[name]; running {
+			continue
+		}
+		ctx, cancel := context.WithCancel(m.prewarmCtx)
+		m.keepAlive[name] = cancel
+		go m.keepAliveLoop(ctx, name, cfg.PingInterval)
+	}
+}
+
+func (m *Manager) keepAliveLoop(ctx context.Context, server string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.ping(ctx, server)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ping connects the server if needed and checks the session is responsive. A dead
+// session is dropped so the next ping or call reconnects it.
+func (m *Manager) ping(ctx context.Context, server string) {
+	holder, err := m.ensureSession(ctx, server)
+	if err != nil {
+		return
+	}
+	err = holder.session.Ping(ctx, nil)
+	m.release(server, holder)
+	if err != nil && ctx.Err() == nil {
+		_ = holder.Close()
+		m.removeSession(server, holder)
+	}
+}
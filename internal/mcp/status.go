@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Capability gaps that cost a CLI feature.
+const (
+	warnNoTools   = "no tools capability: the model gets no functions from this server"
+	warnNoLogging = "no logging capability: its logs are not forwarded to the log file"
+)
+
+// ServerStatus is what a server advertised about itself in the MCP handshake.
+type ServerStatus struct {
+	Name string
+	// Implementation and Version identify the server program, e.g. "github-mcp" "1.4.0".
+	Implementation  string
+	Version         string
+	ProtocolVersion string
+	// Capabilities lists what the server advertised, e.g. "tools (listChanged)", "logging".
+	Capabilities []string
+	// Warnings name the features of the CLI the server cannot support.
+	Warnings []string
+}
+
+// Status connects to server if it is not connected yet and returns what it advertised.
+func (m *Manager) Status(ctx context.Context, server string) (ServerStatus, error) {
+	holder, err := m.ensureSession(ctx, server)
+	if err != nil {
+		return ServerStatus{Name: server}, err
+	}
+	defer m.release(server, holder)
+	var init *sdk.InitializeResult
+	if holder.session != nil {
+		init = holder.session.InitializeResult()
+	}
+	return serverStatus(server, init), nil
+}
+
+func serverStatus(server string, init *sdk.InitializeResult) ServerStatus {
+	status := ServerStatus{Name: server}
+	if init == nil {
+		return status
+	}
+	status.ProtocolVersion = init.ProtocolVersion
+	if info := init.ServerInfo; info != nil {
+		status.Implementation = info.Name
+		if info.Title != "" && info.Title != info.Name {
+			status.Implementation = info.Title + " (" + info.Name + ")"
+		}
+		status.Version = info.Version
+	}
+	caps := init.Capabilities
+	if caps == nil {
+		caps = &sdk.ServerCapabilities{}
+	}
+	// add records a capability with the optional features that are switched on.
+	add := func(name string, features map[string]bool) {
+		var on []string
+		for _, feature := range []string{"listChanged", "subscribe"} {
+			if features[feature] {
+				on = append(on, feature)
+			}
+		}
+		if len(on) > 0 {
+			name += " (" + strings.Join(on, ", ") + ")"
+		}
+		status.Capabilities = append(status.Capabilities, name)
+	}
+	if caps.Tools != nil {
+		add("tools", map[string]bool{"listChanged": caps.Tools.ListChanged})
+	} else {
+		status.Warnings = append(status.Warnings, warnNoTools)
+	}
+	if caps.Resources != nil {
+		add("resources", map[string]bool{"listChanged": caps.Resources.ListChanged, "subscribe": caps.Resources.Subscribe})
+	}
+	if caps.Prompts != nil {
+		add("prompts", map[string]bool{"listChanged": caps.Prompts.ListChanged})
+	}
+	if caps.Logging != nil {
+		add("logging", nil)
+	} else {
+		status.Warnings = append(status.Warnings, warnNoLogging)
+	}
+	if caps.Completions != nil {
+		add("completions", nil)
+	}
+	return status
+}
+
+// reportCapabilities passes a new session's capability gaps to the log handler: a
+// server without tools as a warning, the rest as notices.
+func (m *Manager) reportCapabilities(server string, holder *sessionHolder) {
+	m.mu.Lock()
+	handler := m.logHandler
+	m.mu.Unlock()
+	if handler == nil || holder.session == nil {
+		return
+	}
+	for _, warning := range serverStatus(server, holder.session.InitializeResult()).Warnings {
+		level := "notice"
+		if warning == warnNoTools {
+			level = "warning"
+		}
+		handler(LogMessage{Server: server, Level: level, Logger: "humble-ai-cli", Text: warning})
+	}
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestManagerStatusReportsHandshake(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	writeServerConfig(t, home, map[string]map[string]any{
+		"test": {"enabled": true, "command": "ignored"},
+	})
+	mgr, err := NewManager(home)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+	dialer := newTestDialer(t)
+	mgr.connect = dialer.connect
+
+	status, err := mgr.Status(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Implementation != "test-server" || status.Version != "0.0.1" || status.ProtocolVersion == "" {
+		t.Fatalf("unexpected server info %+v", status)
+	}
+	if got := strings.Join(status.Capabilities, ", "); !strings.Contains(got, "tools") || !strings.Contains(got, "logging") {
+		t.Fatalf("expected tools and logging capabilities, got %q", got)
+	}
+	if len(status.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", status.Warnings)
+	}
+	if _, err := mgr.Status(context.Background(), "missing"); err == nil {
+		t.Fatal("expected an error for an unknown server")
+	}
+}
+
+func TestServerStatusWarnsAboutMissingCapabilities(t *testing.T) {
+	status := serverStatus("bare", &sdk.InitializeResult{
+		ProtocolVersion: "2025-06-18",
+		ServerInfo:      &sdk.Implementation{Name: "bare-server", Title: "Bare", Version: "2.0"},
+		Capabilities: &sdk.ServerCapabilities{
+			Resources: &sdk.ResourceCapabilities{ListChanged: true, Subscribe: true},
+		},
+	})
+	if status.Implementation != "Bare (bare-server)" || status.Version != "2.0" {
+		t.Fatalf("unexpected server info %+v", status)
+	}
+	if got := strings.Join(status.Capabilities, ", "); got != "resources (listChanged, subscribe)" {
+		t.Fatalf("Capabilities = %q", got)
+	}
+	if len(status.Warnings) != 2 || status.Warnings[0] != warnNoTools || status.Warnings[1] != warnNoLogging {
+		t.Fatalf("Warnings = %v", status.Warnings)
+	}
+}
@@ -0,0 +1,186 @@
+// Package probe checks empirically what a model endpoint supports: streamed answers,
+// calls through the tools API and JSON-only answers. Local models often claim more than
+// their templates deliver, so the results steer how tools are offered to them.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// CheckTimeout bounds each of the three checks.
+const CheckTimeout = time.Minute
+
+// Result records what a model did when probed.
+type Result struct {
+	// Streaming is true when the answer arrived in more than one chunk.
+	Streaming bool `json:"streaming"`
+	// ToolCalls is true when the model called the offered tool through the tools API.
+	ToolCalls bool `json:"toolCalls"`
+	// JSON is true when the model answered a JSON-only request with valid JSON.
+	JSON      bool      `json:"json"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+var echoTool = llm.ToolDefinition{
+	Name:        "probe__echo",
+	Description: "Echo the given text back.",
+	Server:      "probe",
+	Method:      "echo",
+	Parameters: map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"text": map[string]any{"type": "string"}},
+		"required":   []string{"text"},
+	},
+}
+
+// Run probes model through provider, which should be created with the native tool
+// strategy. It fails only when the endpoint cannot answer at all; a request rejected
+// because of the tools is reported as ToolCalls false.
+func Run(ctx context.Context, provider llm.ChatProvider, model string) (Result, error) {
+	result := Result{CheckedAt: time.Now().UTC().Truncate(time.Second)}
+
+	answer, chunks, _, err := ask(ctx, provider, llm.ChatRequest{
+		Model:    model,
+		Messages: []llm.Message{{Role: "user", Content: "Count from 1 to 20, separated by spaces."}},
+		Stream:   true,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	result.Streaming = chunks > 1 && strings.TrimSpace(answer) != ""
+
+	answer, _, _, err = ask(ctx, provider, llm.ChatRequest{
+		Model:        model,
+		SystemPrompt: "Answer with JSON only: no prose and no code fences.",
+		Messages:     []llm.Message{{Role: "user", Content: `Return a JSON object with the key "ok" set to true and the key "items" set to the numbers 1, 2 and 3.`}},
+		Stream:       true,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	var decoded map[string]any
+	result.JSON = json.Unmarshal([]byte(strings.TrimSpace(answer)), &decoded) == nil
+
+	_, _, call, err := ask(ctx, provider, llm.ChatRequest{
+		Model:    model,
+		Messages: []llm.Message{{Role: "user", Content: `Use the probe__echo tool to echo the text "ping".`}},
+		Stream:   true,
+		Tools:    []llm.ToolDefinition{echoTool},
+	})
+	result.ToolCalls = err == nil && call != nil && call.Server == echoTool.Server && call.Method == echoTool.Method
+	return result, nil
+}
+
+// ask streams one request and returns the answer text, the number of token chunks and
+// the first tool call. The request is abandoned at a tool call, which is never answered.
+func ask(ctx context.Context, provider llm.ChatProvider, req llm.ChatRequest) (string, int, *llm.ToolCall, error) {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	stream, err := provider.Stream(ctx, req)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	var (
+		answer strings.Builder
+		chunks int
+	)
+	for chunk := range stream {
+		switch {
+		case chunk.Err != nil || chunk.Type == llm.ChunkError:
+			cancel()
+			drain(stream)
+			if chunk.Err == nil {
+				chunk.Err = errors.New(chunk.Content)
+			}
+			return "", 0, nil, chunk.Err
+		case chunk.Type == llm.ChunkToolCall && chunk.ToolCall != nil:
+			cancel()
+			drain(stream)
+			return answer.String(), chunks, chunk.ToolCall, nil
+		case chunk.Type == llm.ChunkToken:
+			chunks++
+			answer.WriteString(chunk.Content)
+		}
+	}
+	if ctx.Err() != nil {
+		return "", 0, nil, ctx.Err()
+	}
+	return answer.String(), chunks, nil, nil
+}
+
+func drain(stream <-chan llm.StreamChunk) {
+	for range stream {
+	}
+}
+
+// Key identifies a model endpoint in the cache.
+func Key(model config.Model) string {
+	return strings.ToLower(model.Provider) + " " + strings.TrimRight(model.BaseURL, "/") + " " + model.Name
+}
+
+// Cache stores probe results in a JSON file keyed by Key.
+type Cache struct {
+	Path string
+}
+
+var cacheMu sync.Mutex
+
+// Load returns the cached result for key.
+func (c Cache) Load(key string) (Result, bool, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	results, err := c.read()
+	if err != nil {
+		return Result{}, false, err
+	}
+	result, ok := results[key]
+	return result, ok, nil
+}
+
+// Save stores result under key, keeping the other entries.
+func (c Cache) Save(key string, result Result) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	results, err := c.read()
+	if err != nil {
+		return err
+	}
+	results[key] = result
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal probe results: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return fmt.Errorf("create probe cache dir: %w", err)
+	}
+	if err := os.WriteFile(c.Path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write probe results: %w", err)
+	}
+	return nil
+}
+
+func (c Cache) read() (map[string]Result, error) {
+	results := map[string]Result{}
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return results, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read probe results: %w", err)
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(c.Path), err)
+	}
+	return results, nil
+}
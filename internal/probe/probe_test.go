@@ -0,0 +1,99 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// scriptedProvider answers in tokens, and calls the first offered tool when callTools is set.
+type scriptedProvider struct {
+	tokens    []string
+	callTools bool
+	err       error
+}
+
+func (p scriptedProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	stream := make(chan llm.StreamChunk)
+	go func() {
+		defer close(stream)
+		send := func(chunk llm.StreamChunk) bool {
+			select {
+			case stream <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if len(req.Tools) > 0 && p.callTools {
+			tool := req.Tools[0]
+			send(llm.StreamChunk{Type: llm.ChunkToolCall, ToolCall: &llm.ToolCall{Server: tool.Server, Method: tool.Method, Arguments: map[string]any{"text": "ping"}}})
+			<-ctx.Done()
+			return
+		}
+		for _, token := range p.tokens {
+			if !send(llm.StreamChunk{Type: llm.ChunkToken, Content: token}) {
+				return
+			}
+		}
+		send(llm.StreamChunk{Type: llm.ChunkDone})
+	}()
+	return stream, nil
+}
+
+func TestRunDetectsCapableModel(t *testing.T) {
+	provider := scriptedProvider{tokens: []string{`{"ok": true,`, ` "items": [1, 2, 3]}`}, callTools: true}
+	result, err := Run(context.Background(), provider, "llama3.1")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Streaming || !result.JSON || !result.ToolCalls {
+		t.Fatalf("expected every capability to be detected, got %+v", result)
+	}
+}
+
+func TestRunDetectsFlakyModel(t *testing.T) {
+	provider := scriptedProvider{tokens: []string{"```json\n{\"ok\": true}\n```"}}
+	result, err := Run(context.Background(), provider, "tiny")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Streaming || result.JSON || result.ToolCalls {
+		t.Fatalf("expected no capability to be detected, got %+v", result)
+	}
+}
+
+func TestRunFailsWhenTheEndpointIsDown(t *testing.T) {
+	if _, err := Run(context.Background(), scriptedProvider{err: errors.New("connection refused")}, "m"); err == nil {
+		t.Fatal("expected an error when the endpoint cannot answer")
+	}
+}
+
+func TestCacheKeepsResultsPerEndpoint(t *testing.T) {
+	cache := Cache{Path: filepath.Join(t.TempDir(), "profiles", "probes.json")}
+	local := config.Model{Name: "llama3", Provider: "ollama", BaseURL: "http://localhost:11434/"}
+	remote := config.Model{Name: "llama3", Provider: "ollama", BaseURL: "http://gpu-box:11434"}
+	want := Result{Streaming: true, JSON: true, CheckedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	if err := cache.Save(Key(local), want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := cache.Save(Key(remote), Result{}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, ok, err := cache.Load(Key(local))
+	if err != nil || !ok || got != want {
+		t.Fatalf("Load() = %+v, %t, %v; want %+v", got, ok, err, want)
+	}
+	if _, ok, _ := cache.Load(Key(config.Model{Name: "qwen", Provider: "ollama"})); ok {
+		t.Fatal("expected no result for an unprobed model")
+	}
+}
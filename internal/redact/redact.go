@@ -0,0 +1,140 @@
+// Package redact masks personal data in outbound text with stable placeholders.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Built-in rule names.
+const (
+	Email = "email"
+	Phone = "phone"
+	IPv4  = "ipv4"
+)
+
+var builtinPatterns = map[string]string{
+	Email: `[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`,
+	Phone: `(?:\+\d{1,3}[\s.-]?)?(?:\(\d{2,4}\)\s?|\b\d{2,4}[\s.-])\d{3,4}[\s.-]\d{4}\b`,
+	IPv4:  `\b(?:\d{1,3}\.){3}\d{1,3}\b`,
+}
+
+// DefaultBuiltins are applied when no built-in rules are configured.
+var DefaultBuiltins = []string{Email, Phone}
+
+// Rule is a named pattern whose matches are masked.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Builtin returns the built-in rule with the given name.
+func Builtin(name string) (Rule, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	expr, ok := builtinPatterns[name]
+	if !ok {
+		return Rule{}, false
+	}
+	return Rule{Name: name, Pattern: regexp.MustCompile(expr)}, true
+}
+
+// Compile builds the rule set from built-in names and custom name → regex pairs.
+func Compile(builtins []string, custom [][2]string) ([]Rule, error) {
+	if len(builtins) == 0 {
+		builtins = DefaultBuiltins
+	}
+	rules := make([]Rule, 0, len(builtins)+len(custom))
+	for _, name := range builtins {
+		rule, ok := Builtin(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction rule %q", name)
+		}
+		rules = append(rules, rule)
+	}
+	for _, pair := range custom {
+		pattern, err := regexp.Compile(pair[1])
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", pair[0], err)
+		}
+		rules = append(rules, Rule{Name: pair[0], Pattern: pattern})
+	}
+	return rules, nil
+}
+
+// Entry records one distinct masked value.
+type Entry struct {
+	Placeholder string
+	Rule        string
+	Original    string
+	Count       int
+}
+
+// Masker replaces matches with placeholders that stay stable for the same value.
+type Masker struct {
+	rules   []Rule
+	byValue map[string]int
+	entries []Entry
+	counts  map[string]int
+}
+
+// NewMasker creates a Masker for rules.
+func NewMasker(rules []Rule) *Masker {
+	return &Masker{rules: rules, byValue: make(map[string]int), counts: make(map[string]int)}
+}
+
+type span struct {
+	start, end int
+	rule       string
+}
+
+// Mask returns text with every rule match replaced and the number of replacements made.
+func (m *Masker) Mask(text string) (string, int) {
+	var spans []span
+	for _, rule := range m.rules {
+		for _, loc := range rule.Pattern.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			spans = append(spans, span{start: loc[0], end: loc[1], rule: rule.Name})
+		}
+	}
+	if len(spans) == 0 {
+		return text, 0
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	last, replaced := 0, 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		b.WriteString(text[last:s.start])
+		b.WriteString(m.placeholder(s.rule, text[s.start:s.end]))
+		last = s.end
+		replaced++
+	}
+	b.WriteString(text[last:])
+	return b.String(), replaced
+}
+
+func (m *Masker) placeholder(rule, value string) string {
+	key := rule + "\x00" + value
+	if idx, ok := m.byValue[key]; ok {
+		m.entries[idx].Count++
+		return m.entries[idx].Placeholder
+	}
+	m.counts[rule]++
+	label := strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(rule))
+	placeholder := fmt.Sprintf("[%s_%d]", label, m.counts[rule])
+	m.byValue[key] = len(m.entries)
+	m.entries = append(m.entries, Entry{Placeholder: placeholder, Rule: rule, Original: value, Count: 1})
+	return placeholder
+}
+
+// Entries lists masked values in the order they were first seen.
+func (m *Masker) Entries() []Entry {
+	return append([]Entry(nil), m.entries...)
+}
@@ -0,0 +1,54 @@
+package redact_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/redact"
+)
+
+func TestMaskDefaultRulesUseStablePlaceholders(t *testing.T) {
+	rules, err := redact.Compile(nil, nil)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	m := redact.NewMasker(rules)
+
+	first, n := m.Mask("Mail alice@example.com or call 010-1234-5678 on 2025-01-02.")
+	if n != 2 {
+		t.Fatalf("expected 2 replacements, got %d (%q)", n, first)
+	}
+	if first != "Mail [EMAIL_1] or call [PHONE_1] on 2025-01-02." {
+		t.Fatalf("unexpected masked text %q", first)
+	}
+
+	second, _ := m.Mask("cc alice@example.com and bob@example.org")
+	if second != "cc [EMAIL_1] and [EMAIL_2]" {
+		t.Fatalf("expected stable placeholders, got %q", second)
+	}
+
+	entries := m.Entries()
+	if len(entries) != 3 || entries[0].Original != "alice@example.com" || entries[0].Count != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func TestMaskCustomPatterns(t *testing.T) {
+	rules, err := redact.Compile([]string{"ipv4"}, [][2]string{{"employee-id", `EMP-\d{6}`}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	out, _ := redact.NewMasker(rules).Mask("EMP-123456 logged in from 10.0.0.12")
+	if out != "[EMPLOYEE_ID_1] logged in from [IPV4_1]" {
+		t.Fatalf("unexpected masked text %q", out)
+	}
+}
+
+func TestCompileRejectsUnknownRulesAndBadPatterns(t *testing.T) {
+	if _, err := redact.Compile([]string{"ssn"}, nil); err == nil {
+		t.Fatalf("expected error for unknown builtin")
+	}
+	if _, err := redact.Compile(nil, [][2]string{{"bad", "("}}); err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Fatalf("expected error naming the bad pattern, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+// CitationMarker is the convention models are asked to cite a tool result with; the
+// number is the position of the tool call in the answer's turn, starting at 1.
+const CitationMarker = "[ref:toolcall-%d]"
+
+var citationPattern = regexp.MustCompile(`\[ref:toolcall-(\d+)\]`)
+
+// Citation is a tool call an answer cites, numbered as in its marker.
+type Citation struct {
+	Number int
+	Call   history.ToolCall
+}
+
+// ResolveCitations replaces the citation markers of content with footnote numbers such as
+// [2] and returns the cited tool calls in order of number. Markers naming a call the turn
+// does not have are left as they are.
+func ResolveCitations(content string, calls []history.ToolCall) (string, []Citation) {
+	return resolveCitations(content, calls, func(n int) string { return fmt.Sprintf("[%d]", n) })
+}
+
+func resolveCitations(content string, calls []history.ToolCall, footnote func(n int) string) (string, []Citation) {
+	cited := map[int]bool{}
+	resolved := citationPattern.ReplaceAllStringFunc(content, func(marker string) string {
+		n, ok := citationNumber(marker, len(calls))
+		if !ok {
+			return marker
+		}
+		cited[n] = true
+		return footnote(n)
+	})
+	var citations []Citation
+	for n := 1; n <= len(calls); n++ {
+		if cited[n] {
+			citations = append(citations, Citation{Number: n, Call: calls[n-1]})
+		}
+	}
+	return resolved, citations
+}
+
+func citationNumber(marker string, calls int) (int, bool) {
+	n, err := strconv.Atoi(citationPattern.FindStringSubmatch(marker)[1])
+	if err != nil || n < 1 || n > calls {
+		return 0, false
+	}
+	return n, true
+}
+
+// htmlFootnote stands in for a resolved citation while content is rendered as HTML. The
+// private-use runes pass through rendering untouched, so citationLinks can find exactly the
+// citations that were resolved and nothing the answer merely wrote as [2].
+func htmlFootnote(n int) string {
+	return fmt.Sprintf("\uE000%d\uE001", n)
+}
+
+var htmlFootnotePattern = regexp.MustCompile("\uE000(\\d+)\uE001")
+
+// citationLinks turns the footnotes in rendered HTML into links to the footnotes of
+// message index msg.
+func citationLinks(rendered string, msg int) string {
+	return htmlFootnotePattern.ReplaceAllString(rendered, fmt.Sprintf(`<sup class="cite"><a href="#cite-%d-${1}">[${1}]</a></sup>`, msg))
+}
@@ -0,0 +1,172 @@
+package render
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// languageHint is one piece of evidence that a code block is written in a language.
+// Strong hints are enough on their own; weak ones only count together.
+type languageHint struct {
+	lang    string
+	pattern *regexp.Regexp
+	weight  int
+}
+
+const (
+	weakHint   = 1
+	strongHint = 3
+	// minLanguageScore keeps a single weak hint, such as a lone `let`, from labeling a block.
+	minLanguageScore = 2
+)
+
+// languageHints are checked in order; earlier languages win ties.
+var languageHints = []languageHint{
+	{"go", regexp.MustCompile(`(?m)^package \w+\s*$`), strongHint},
+	{"go", regexp.MustCompile(`(?m)^func (\([^)]*\) )?\w+\(`), strongHint},
+	{"go", regexp.MustCompile(`:= `), weakHint},
+	{"go", regexp.MustCompile(`\b(fmt|errors|strings)\.[A-Z]\w*\(`), weakHint},
+	{"go", regexp.MustCompile(`\berr != nil\b`), weakHint},
+
+	{"python", regexp.MustCompile(`(?m)^\s*def \w+\(.*\)\s*(->.*)?:\s*$`), strongHint},
+	{"python", regexp.MustCompile(`(?m)^\s*from [\w.]+ import \w`), strongHint},
+	{"python", regexp.MustCompile(`(?m)^\s*(if|elif|for|while|with|class|try|except)\b.*:\s*$`), weakHint},
+	{"python", regexp.MustCompile(`(?m)^import \w+(\.\w+)*( as \w+)?\s*$`), weakHint},
+	{"python", regexp.MustCompile(`\bself\.\w+`), weakHint},
+	{"python", regexp.MustCompile(`\bprint\(`), weakHint},
+
+	{"rust", regexp.MustCompile(`(?m)^\s*(pub )?fn \w+(<[^>]*>)?\(`), strongHint},
+	{"rust", regexp.MustCompile(`\blet mut \w+`), strongHint},
+	{"rust", regexp.MustCompile(`\b(println|vec|format)!\(`), strongHint},
+	{"rust", regexp.MustCompile(`(?m)^use (std|crate)::`), strongHint},
+
+	{"java", regexp.MustCompile(`\bpublic (static )?(class|void|interface) `), strongHint},
+	{"java", regexp.MustCompile(`\bSystem\.out\.print`), strongHint},
+
+	{"cpp", regexp.MustCompile(`\bstd::\w+`), strongHint},
+	{"cpp", regexp.MustCompile(`#include <(iostream|vector|string|map|memory)>`), strongHint},
+	{"c", regexp.MustCompile(`(?m)^#include [<"]`), strongHint},
+	{"c", regexp.MustCompile(`\bprintf\(`), weakHint},
+	{"c", regexp.MustCompile(`\bint main\(`), weakHint},
+
+	{"typescript", regexp.MustCompile(`(?m)^\s*(export )?(interface|type) \w+(<[^>]*>)? (=|\{)`), strongHint},
+	{"typescript", regexp.MustCompile(`\b(const|let)\s+\w+\s*:\s*(string|number|boolean)\b`), strongHint},
+
+	{"javascript", regexp.MustCompile(`\bconsole\.log\(`), strongHint},
+	{"javascript", regexp.MustCompile(`\brequire\(['"]`), strongHint},
+	{"javascript", regexp.MustCompile(`(?m)^\s*(const|let|var) \w+ = `), weakHint},
+	{"javascript", regexp.MustCompile(`\bfunction\s*\w*\(`), weakHint},
+	{"javascript", regexp.MustCompile(`\)\s*=>`), weakHint},
+
+	{"sql", regexp.MustCompile(`(?is)^select\s.+?\sfrom\s`), strongHint},
+	{"sql", regexp.MustCompile(`(?im)^\s*(insert into|update \w+ set|delete from|create (table|index|view))\b`), strongHint},
+
+	{"html", regexp.MustCompile(`(?i)^\s*<(!doctype html|html|head|body|div|span|ul|table|form)\b`), strongHint},
+
+	{"bash", regexp.MustCompile(`(?m)^\$ \S`), strongHint},
+	{"bash", regexp.MustCompile(`(?m)^(sudo|apt(-get)?|brew|npm|npx|yarn|pip3?|go (get|install|run|build|test|mod)|git|cd|mkdir|echo|export|curl|docker|kubectl|make|chmod)\b`), weakHint},
+	{"bash", regexp.MustCompile(`(?m)^\s*(fi|done|esac)\s*$`), strongHint},
+
+	{"yaml", regexp.MustCompile(`(?m)^[\w-]+:( [^{}();]*)?$`), weakHint},
+	{"yaml", regexp.MustCompile(`(?m)^\s+- [\w"']`), weakHint},
+}
+
+// shebangLanguages maps interpreters named on a #! line to fence labels.
+var shebangLanguages = map[string]string{
+	"bash":    "bash",
+	"sh":      "bash",
+	"zsh":     "bash",
+	"python":  "python",
+	"python3": "python",
+	"node":    "javascript",
+}
+
+// DetectLanguage guesses the language of a code block from its contents. It returns
+// an empty string when no language is a clear match, so callers can leave the block
+// unlabeled rather than mislabel it.
+func DetectLanguage(code string) string {
+	trimmed := strings.TrimSpace(code)
+	if trimmed == "" {
+		return ""
+	}
+	if lang := shebangLanguage(trimmed); lang != "" {
+		return lang
+	}
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+	if isUnifiedDiff(trimmed) {
+		return "diff"
+	}
+
+	scores := make(map[string]int)
+	best, bestScore := "", 0
+	for _, hint := range languageHints {
+		if !hint.pattern.MatchString(trimmed) {
+			continue
+		}
+		scores[hint.lang] += hint.weight
+		if scores[hint.lang] > bestScore {
+			best, bestScore = hint.lang, scores[hint.lang]
+		}
+	}
+	if bestScore < minLanguageScore {
+		return ""
+	}
+	return best
+}
+
+func shebangLanguage(code string) string {
+	if !strings.HasPrefix(code, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(code, "\n")
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := fields[0]
+	if strings.HasSuffix(interpreter, "/env") && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	if i := strings.LastIndex(interpreter, "/"); i >= 0 {
+		interpreter = interpreter[i+1:]
+	}
+	return shebangLanguages[interpreter]
+}
+
+func isUnifiedDiff(code string) bool {
+	return (strings.HasPrefix(code, "--- ") || strings.HasPrefix(code, "diff --git ")) &&
+		strings.Contains(code, "\n+++ ") && strings.Contains(code, "\n@@ ")
+}
+
+// LabelCodeFences adds a detected language to every opening ``` fence that has none,
+// leaving labeled fences and undetectable blocks untouched.
+func LabelCodeFences(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	changed := false
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		open := i
+		for i++; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "```" {
+				break
+			}
+		}
+		if trimmed != "```" {
+			continue
+		}
+		if lang := DetectLanguage(strings.Join(lines[open+1:min(i, len(lines))], "\n")); lang != "" {
+			lines[open] = strings.Replace(lines[open], "```", "```"+lang, 1)
+			changed = true
+		}
+	}
+	if !changed {
+		return markdown
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,75 @@
+package render_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"go", "package main\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}", "go"},
+		{"go snippet", "if err != nil {\n\treturn fmt.Errorf(\"load: %w\", err)\n}", "go"},
+		{"python", "def greet(name):\n    print(f\"hi {name}\")", "python"},
+		{"python shebang", "#!/usr/bin/env python3\nimport sys", "python"},
+		{"bash", "$ go install ./...\n$ humble-ai-cli --version", "bash"},
+		{"bash script", "for f in *.go; do\n  gofmt -l \"$f\"\ndone", "bash"},
+		{"json", "{\"name\": \"demo\", \"tags\": [1, 2]}", "json"},
+		{"sql", "SELECT id, name\nFROM users\nWHERE active = 1;", "sql"},
+		{"rust", "fn main() {\n    let mut total = 0;\n    println!(\"{}\", total);\n}", "rust"},
+		{"java", "public class App {\n  public static void main(String[] args) {}\n}", "java"},
+		{"cpp", "#include <iostream>\nint main() { std::cout << 1; }", "cpp"},
+		{"c", "#include <stdio.h>\nint main() { printf(\"hi\"); }", "c"},
+		{"typescript", "interface User {\n  name: string;\n}", "typescript"},
+		{"javascript", "const total = items.reduce((a, b) => a + b, 0);\nconsole.log(total);", "javascript"},
+		{"yaml", "name: demo\nsteps:\n  - run: make", "yaml"},
+		{"diff", "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new", "diff"},
+		{"ambiguous", "if x < 1 { return }", ""},
+		{"prose", "just some words", ""},
+		{"empty", "  \n", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := render.DetectLanguage(tc.code); got != tc.want {
+				t.Fatalf("DetectLanguage() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLabelCodeFencesOnlyTouchesUnlabeledBlocks(t *testing.T) {
+	in := "Run:\n\n```\n$ make test\n```\n\nThen:\n\n```python\nprint(1)\n```\n\n```\nwhatever\n```"
+	want := "Run:\n\n```bash\n$ make test\n```\n\nThen:\n\n```python\nprint(1)\n```\n\n```\nwhatever\n```"
+	if got := render.LabelCodeFences(in); got != want {
+		t.Fatalf("LabelCodeFences() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderersLabelDetectedCodeBlocks(t *testing.T) {
+	session := history.Session{Messages: []history.Message{
+		{Role: "assistant", Content: "```\nfunc add(a, b int) int {\n\treturn a + b\n}\n```"},
+	}}
+
+	var page bytes.Buffer
+	if err := render.HTML(&page, session, render.HTMLOptions{}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if !strings.Contains(page.String(), `<pre><code class="language-go"><span class="tok-k">func</span> add`) {
+		t.Fatalf("expected detected Go block in HTML export, got:\n%s", page.String())
+	}
+
+	var transcript bytes.Buffer
+	if err := render.Transcript(&transcript, session, render.Options{}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	if !strings.Contains(transcript.String(), "```go\nfunc add") {
+		t.Fatalf("expected labeled fence in transcript, got:\n%s", transcript.String())
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import "strings"
+
+// diffFenceLabels are the fence labels whose blocks are styled as unified diffs.
+var diffFenceLabels = map[string]bool{"diff": true, "patch": true, "udiff": true}
+
+// IsDiffFence reports whether line opens a fenced block labeled as a diff, e.g. "```diff".
+func IsDiffFence(line string) bool {
+	label, ok := strings.CutPrefix(strings.TrimSpace(line), "```")
+	return ok && diffFenceLabels[strings.ToLower(strings.TrimSpace(label))]
+}
+
+// ColorDiffLine styles one line of a unified diff for the terminal: file headers bold,
+// hunk headers cyan, additions green and removals red. A trailing newline is kept.
+func ColorDiffLine(line string) string {
+	body, newline := strings.CutSuffix(line, "\n")
+	style := ""
+	switch {
+	case strings.HasPrefix(body, "diff --git "), strings.HasPrefix(body, "---"), strings.HasPrefix(body, "+++"):
+		style = ansiBold
+	case strings.HasPrefix(body, "index "), strings.HasPrefix(body, "new file mode"), strings.HasPrefix(body, "deleted file mode"):
+		style = ansiDim
+	case strings.HasPrefix(body, "@@"):
+		style = ansiCyan
+	case strings.HasPrefix(body, "+"):
+		style = ansiGreen
+	case strings.HasPrefix(body, "-"):
+		style = ansiRed
+	}
+	if style == "" || body == "" {
+		return line
+	}
+	if newline {
+		return style + body + ansiReset + "\n"
+	}
+	return style + body + ansiReset
+}
+
+// colorDiffBlocks styles the lines of every diff fence in markdown.
+func colorDiffBlocks(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	inDiff := false
+	for i, line := range lines {
+		switch {
+		case inDiff && strings.TrimSpace(line) == "```":
+			inDiff = false
+		case inDiff:
+			lines[i] = ColorDiffLine(line)
+		case IsDiffFence(line):
+			inDiff = true
+		}
+	}
+	return strings.Join(lines, "\n")
+}
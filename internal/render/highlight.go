@@ -0,0 +1,180 @@
+package render
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// codeLanguage describes just enough of a language to highlight it without a lexer.
+type codeLanguage struct {
+	keywords     map[string]bool
+	lineComments []string
+	blockComment bool
+	backticks    bool
+}
+
+func words(list string) map[string]bool {
+	out := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		out[w] = true
+	}
+	return out
+}
+
+var (
+	goLanguage = codeLanguage{
+		keywords:     words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false"),
+		lineComments: []string{"//"},
+		blockComment: true,
+		backticks:    true,
+	}
+	pythonLanguage = codeLanguage{
+		keywords:     words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield None True False"),
+		lineComments: []string{"#"},
+	}
+	jsLanguage = codeLanguage{
+		keywords:     words("async await break case catch class const continue default delete do else export extends finally for from function if import in instanceof interface let new of return switch this throw try type typeof var void while yield null undefined true false"),
+		lineComments: []string{"//"},
+		blockComment: true,
+		backticks:    true,
+	}
+	shellLanguage = codeLanguage{
+		keywords:     words("if then else elif fi for while until do done case esac in function return export local echo"),
+		lineComments: []string{"#"},
+	}
+	jsonLanguage = codeLanguage{
+		keywords: words("true false null"),
+	}
+	cLikeLanguage = codeLanguage{
+		keywords:     words("abstract auto bool break case catch char class const continue default do double else enum extends final float for fn if impl implements import int let long match mod mut new null package private protected pub public return self static struct super switch this throw throws trait try use void while true false"),
+		lineComments: []string{"//"},
+		blockComment: true,
+	}
+	sqlLanguage = codeLanguage{
+		keywords:     words("select from where and or not insert into values update set delete create table drop alter join left right inner outer on group by order limit as null is in having distinct"),
+		lineComments: []string{"--"},
+		blockComment: true,
+	}
+)
+
+var codeLanguages = map[string]codeLanguage{
+	"go":         goLanguage,
+	"golang":     goLanguage,
+	"python":     pythonLanguage,
+	"py":         pythonLanguage,
+	"javascript": jsLanguage,
+	"js":         jsLanguage,
+	"typescript": jsLanguage,
+	"ts":         jsLanguage,
+	"tsx":        jsLanguage,
+	"jsx":        jsLanguage,
+	"bash":       shellLanguage,
+	"sh":         shellLanguage,
+	"shell":      shellLanguage,
+	"zsh":        shellLanguage,
+	"json":       jsonLanguage,
+	"c":          cLikeLanguage,
+	"cpp":        cLikeLanguage,
+	"java":       cLikeLanguage,
+	"rust":       cLikeLanguage,
+	"rs":         cLikeLanguage,
+	"sql":        sqlLanguage,
+}
+
+// highlightCode returns HTML-escaped code with keywords, strings, comments and numbers
+// wrapped in spans. Unknown languages are only escaped.
+func highlightCode(lang, code string) string {
+	spec, ok := codeLanguages[lang]
+	if !ok {
+		return html.EscapeString(code)
+	}
+	caseInsensitive := lang == "sql"
+
+	var b strings.Builder
+	span := func(class, text string) {
+		b.WriteString(`<span class="tok-` + class + `">`)
+		b.WriteString(html.EscapeString(text))
+		b.WriteString("</span>")
+	}
+
+	runes := []rune(code)
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:])
+		if prefix := lineCommentPrefix(spec, rest); prefix != "" {
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			span("c", string(runes[i:end]))
+			i = end
+			continue
+		}
+		if spec.blockComment && strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			text := rest
+			if end >= 0 {
+				text = rest[:end+4]
+			}
+			span("c", text)
+			i += len([]rune(text))
+			continue
+		}
+
+		r := runes[i]
+		switch {
+		case r == '"' || r == '\'' || (r == '`' && spec.backticks):
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' && r != '`' {
+					end++
+				} else if runes[end] == '\n' && r != '`' {
+					break
+				}
+				end++
+			}
+			if end < len(runes) && runes[end] == r {
+				end++
+			}
+			end = min(end, len(runes))
+			span("s", string(runes[i:end]))
+			i = end
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || unicode.IsLetter(runes[end]) || runes[end] == '.' || runes[end] == '_') {
+				end++
+			}
+			span("n", string(runes[i:end]))
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			word := string(runes[i:end])
+			key := word
+			if caseInsensitive {
+				key = strings.ToLower(word)
+			}
+			if spec.keywords[key] {
+				span("k", word)
+			} else {
+				b.WriteString(html.EscapeString(word))
+			}
+			i = end
+		default:
+			b.WriteString(html.EscapeString(string(r)))
+			i++
+		}
+	}
+	return b.String()
+}
+
+func lineCommentPrefix(spec codeLanguage, rest string) string {
+	for _, prefix := range spec.lineComments {
+		if strings.HasPrefix(rest, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
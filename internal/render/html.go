@@ -0,0 +1,222 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+// HTMLOptions controls standalone HTML export.
+type HTMLOptions struct {
+	// Title is used for the page title and heading; it defaults to "Conversation".
+	Title string
+	// Labels names the speakers.
+	Labels Labels
+}
+
+var (
+	thinkBlockPattern = regexp.MustCompile(`(?s)<think(?:ing)?>(.*?)</think(?:ing)?>`)
+	inlineCodePattern = regexp.MustCompile("`([^`\n]+)`")
+	boldPattern       = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+)
+
+const htmlStyle = `body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;max-width:860px;margin:2rem auto;padding:0 1rem;color:#1f2328;background:#fff;line-height:1.5}
+header{border-bottom:1px solid #d0d7de;margin-bottom:1.5rem}
+header dl{display:grid;grid-template-columns:max-content 1fr;gap:.2rem 1rem;font-size:.9rem;color:#59636e}
+header dt{font-weight:600}header dd{margin:0}
+.msg{border:1px solid #d0d7de;border-radius:8px;padding:.75rem 1rem;margin:1rem 0}
+.msg.user{background:#f6f8fa}
+.role{font-weight:600;margin-bottom:.4rem}.user .role{color:#0969da}.assistant .role{color:#1a7f37}
+.role time{font-weight:400;color:#59636e;font-size:.85rem;margin-left:.5rem}
+.rating{color:#59636e;font-size:.85rem}
+.cite a{text-decoration:none}.citations{color:#59636e;font-size:.85rem}
+.msg p{margin:.4rem 0;white-space:pre-wrap}
+code{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:.9em;background:#eff1f3;border-radius:4px;padding:.1em .3em}
+pre{background:#f6f8fa;border:1px solid #d0d7de;border-radius:6px;padding:.75rem;overflow-x:auto}
+pre code{background:none;padding:0}
+details{border-left:3px solid #d0d7de;padding:.2rem .75rem;margin:.5rem 0;color:#59636e}
+details.tool{border-color:#bf8700}details.tool.error{border-color:#cf222e}
+summary{cursor:pointer;font-weight:600}
+.tok-k{color:#cf222e}.tok-s{color:#0a3069}.tok-c{color:#6e7781;font-style:italic}.tok-n{color:#0550ae}`
+
+// HTML writes a session as a standalone HTML page with collapsible thinking and
+// tool call sections and highlighted code blocks.
+func HTML(w io.Writer, session history.Session, opts HTMLOptions) error {
+	title := strings.TrimSpace(opts.Title)
+	if title == "" {
+		title = "Conversation"
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", html.EscapeString(title), htmlStyle)
+
+	fmt.Fprintf(&b, "<header>\n<h1>%s</h1>\n<dl>\n", html.EscapeString(title))
+	writeField := func(name, value string) {
+		fmt.Fprintf(&b, "<dt>%s</dt><dd>%s</dd>\n", name, html.EscapeString(value))
+	}
+	writeField("Model", session.Model)
+	if session.Persona != "" {
+		writeField("Persona", session.Persona)
+	}
+	if session.Seed != nil {
+		writeField("Seed", fmt.Sprint(*session.Seed))
+	}
+	if !session.StartedAt.IsZero() {
+		writeField("Started", formatTimestamp(session.StartedAt))
+	}
+	if len(session.Tags) > 0 {
+		writeField("Tags", strings.Join(session.Tags, ", "))
+	}
+	for _, note := range session.Notes {
+		writeField("Note", note)
+	}
+	b.WriteString("</dl>\n")
+	writePromptHTML(&b, "System prompt", session.SystemPrompt)
+	writePromptHTML(&b, "Tool prompt", session.ToolPrompt)
+	b.WriteString("</header>\n<main>\n")
+
+	for i, msg := range session.Messages {
+		fmt.Fprintf(&b, "<section class=\"msg %s\">\n<div class=\"role\">%s", html.EscapeString(msg.Role), html.EscapeString(opts.Labels.role(msg.Role)))
+		if !msg.Timestamp.IsZero() {
+			fmt.Fprintf(&b, "<time>%s</time>", formatTimestamp(msg.Timestamp))
+		}
+		b.WriteString("</div>\n")
+		if thinking := strings.TrimSpace(msg.Thinking); thinking != "" {
+			b.WriteString("<details class=\"thinking\">\n<summary>Thinking</summary>\n")
+			writeMarkdownHTML(&b, thinking)
+			b.WriteString("</details>\n")
+		}
+		for _, call := range msg.ToolCalls {
+			writeToolCallHTML(&b, call)
+		}
+		content, citations := resolveCitations(msg.Content, msg.ToolCalls, htmlFootnote)
+		if len(citations) == 0 {
+			writeContentHTML(&b, content)
+		} else {
+			var rendered strings.Builder
+			writeContentHTML(&rendered, content)
+			b.WriteString(citationLinks(rendered.String(), i))
+			b.WriteString("<ol class=\"citations\">\n")
+			for _, citation := range citations {
+				fmt.Fprintf(&b, "<li id=\"cite-%d-%d\" value=\"%d\">%s</li>\n", i, citation.Number, citation.Number, html.EscapeString(ToolCallSummary(citation.Call)))
+			}
+			b.WriteString("</ol>\n")
+		}
+		if msg.Rating != nil {
+			fmt.Fprintf(&b, "<p class=\"rating\">%s</p>\n", html.EscapeString(ratingSummary(*msg.Rating)))
+		}
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</main>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writePromptHTML(b *strings.Builder, label, prompt string) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return
+	}
+	fmt.Fprintf(b, "<details class=\"prompt\">\n<summary>%s</summary>\n<pre>%s</pre>\n</details>\n", label, html.EscapeString(prompt))
+}
+
+func writeToolCallHTML(b *strings.Builder, call history.ToolCall) {
+	class := "tool"
+	if call.IsError {
+		class += " error"
+	}
+	fmt.Fprintf(b, "<details class=\"%s\">\n<summary>Tool call: %s.%s", class, html.EscapeString(call.Server), html.EscapeString(call.Method))
+	if call.IsError {
+		b.WriteString(" (error)")
+	}
+	b.WriteString("</summary>\n")
+	if len(call.Arguments) > 0 {
+		args, err := json.MarshalIndent(call.Arguments, "", "  ")
+		if err != nil {
+			args = []byte(formatArguments(call.Arguments))
+		}
+		fmt.Fprintf(b, "<div>Arguments</div>\n<pre><code class=\"language-json\">%s</code></pre>\n", highlightCode("json", string(args)))
+	}
+	if result := strings.TrimRight(call.Result, "\n"); result != "" {
+		fmt.Fprintf(b, "<div>Result</div>\n<pre><code>%s</code></pre>\n", html.EscapeString(result))
+	}
+	b.WriteString("</details>\n")
+}
+
+// writeContentHTML renders message text, turning <think> blocks into collapsible sections.
+func writeContentHTML(b *strings.Builder, content string) {
+	rest := content
+	for {
+		loc := thinkBlockPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			writeMarkdownHTML(b, rest)
+			return
+		}
+		writeMarkdownHTML(b, rest[:loc[0]])
+		b.WriteString("<details class=\"thinking\">\n<summary>Thinking</summary>\n")
+		writeMarkdownHTML(b, rest[loc[2]:loc[3]])
+		b.WriteString("</details>\n")
+		rest = rest[loc[1]:]
+	}
+}
+
+// writeMarkdownHTML renders fenced code blocks and paragraphs with light inline formatting.
+// Unlabeled code blocks are labeled with a detected language when one is clear.
+func writeMarkdownHTML(b *strings.Builder, text string) {
+	var paragraph []string
+	flush := func() {
+		joined := strings.TrimSpace(strings.Join(paragraph, "\n"))
+		paragraph = nil
+		if joined == "" {
+			return
+		}
+		fmt.Fprintf(b, "<p>%s</p>\n", inlineHTML(joined))
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			if trimmed == "" {
+				flush()
+				continue
+			}
+			paragraph = append(paragraph, line)
+			continue
+		}
+
+		flush()
+		lang := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+		var code []string
+		for i++; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "```" {
+				break
+			}
+			code = append(code, lines[i])
+		}
+		if lang == "" {
+			lang = DetectLanguage(strings.Join(code, "\n"))
+		}
+		class := ""
+		if lang != "" {
+			class = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(lang))
+		}
+		fmt.Fprintf(b, "<pre><code%s>%s</code></pre>\n", class, highlightCode(lang, strings.Join(code, "\n")))
+	}
+	flush()
+}
+
+func inlineHTML(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = inlineCodePattern.ReplaceAllString(escaped, "<code>$1</code>")
+	return boldPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+}
@@ -0,0 +1,107 @@
+package render_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+func TestHTMLRendersStandaloneTranscript(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	session := history.Session{
+		Model:     "gpt-4o",
+		StartedAt: at,
+		Tags:      []string{"demo"},
+		Messages: []history.Message{
+			{Role: "user", Content: "Show me <b>code</b> & a sum", Timestamp: at},
+			{
+				Role:      "assistant",
+				Content:   "<think>user wants Go</think>Here is `main`:\n\n```go\nfunc main() {\n\t// greet\n\tprintln(\"hi\", 42)\n}\n```\n\n**Done**",
+				Timestamp: at.Add(time.Second),
+				ToolCalls: []history.ToolCall{
+					{Server: "calc", Method: "add", Arguments: map[string]any{"a": 1}, Result: "3"},
+					{Server: "fs", Method: "read", Result: "denied", IsError: true},
+				},
+				Rating: &history.Rating{Score: 5, Comment: "<clear>"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := render.HTML(&buf, session, render.HTMLOptions{Title: "20250102_030405_show"}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>20250102_030405_show</title>",
+		"<style>",
+		"<dt>Tags</dt><dd>demo</dd>",
+		"Show me &lt;b&gt;code&lt;/b&gt; &amp; a sum",
+		"<details class=\"thinking\">\n<summary>Thinking</summary>\n<p>user wants Go</p>",
+		"<details class=\"tool\">\n<summary>Tool call: calc.add</summary>",
+		"<details class=\"tool error\">\n<summary>Tool call: fs.read (error)</summary>",
+		"<pre><code class=\"language-go\">",
+		`<span class="tok-k">func</span> main`,
+		`<span class="tok-c">// greet</span>`,
+		`<span class="tok-s">&#34;hi&#34;</span>`,
+		`<span class="tok-n">42</span>`,
+		"Here is <code>main</code>:",
+		"<strong>Done</strong>",
+		"<p class=\"rating\">Rating: 5/5 — &lt;clear&gt;</p>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in HTML export, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<script") || strings.Contains(out, "<link") {
+		t.Fatalf("expected a standalone page without external resources")
+	}
+}
+
+func TestHTMLRendersSavedThinking(t *testing.T) {
+	session := history.Session{Messages: []history.Message{
+		{Role: "assistant", Content: "42", Thinking: "six times seven"},
+	}}
+	var buf bytes.Buffer
+	if err := render.HTML(&buf, session, render.HTMLOptions{}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "<details class=\"thinking\">\n<summary>Thinking</summary>\n<p>six times seven</p>\n</details>\n<p>42</p>") {
+		t.Fatalf("expected saved thinking before the answer, got:\n%s", buf.String())
+	}
+}
+
+func TestHTMLLeavesUnknownLanguagesUnhighlighted(t *testing.T) {
+	session := history.Session{Messages: []history.Message{
+		{Role: "assistant", Content: "```\nif x < 1 { return }\n```"},
+	}}
+	var buf bytes.Buffer
+	if err := render.HTML(&buf, session, render.HTMLOptions{}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "<pre><code>if x &lt; 1 { return }</code></pre>") {
+		t.Fatalf("expected escaped plain code block, got:\n%s", buf.String())
+	}
+}
+
+func TestHTMLLinksOnlyResolvedCitations(t *testing.T) {
+	session := history.Session{Messages: []history.Message{{
+		Role:      "assistant",
+		Content:   "Step [1] of the guide [ref:toolcall-1].",
+		ToolCalls: []history.ToolCall{{Server: "web", Method: "fetch", Result: "guide"}},
+	}}}
+	var buf bytes.Buffer
+	if err := render.HTML(&buf, session, render.HTMLOptions{}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	want := `Step [1] of the guide <sup class="cite"><a href="#cite-0-1">[1]</a></sup>.`
+	if !strings.Contains(buf.String(), want) {
+		t.Fatalf("expected only the citation to be linked, got:\n%s", buf.String())
+	}
+}
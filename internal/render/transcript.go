@@ -0,0 +1,218 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+const maxToolSummaryLen = 120
+
+// Options controls transcript rendering.
+type Options struct {
+	// Color enables ANSI styling; disable it when output is piped.
+	Color bool
+	// Title is an optional heading such as the session file name.
+	Title string
+	// Labels names the speakers.
+	Labels Labels
+}
+
+// Labels names the speakers of a conversation; empty fields keep "You" and "Assistant".
+type Labels struct {
+	User      string
+	Assistant string
+}
+
+type painter struct {
+	enabled bool
+}
+
+func (p painter) paint(style, text string) string {
+	if !p.enabled || text == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// Transcript pretty-prints a saved session.
+func Transcript(w io.Writer, session history.Session, opts Options) error {
+	p := painter{enabled: opts.Color}
+	var b strings.Builder
+
+	if opts.Title != "" {
+		fmt.Fprintf(&b, "%s\n", p.paint(ansiBold, opts.Title))
+	}
+	fmt.Fprintf(&b, "%s %s\n", p.paint(ansiDim, "Model:"), session.Model)
+	if session.Persona != "" {
+		fmt.Fprintf(&b, "%s %s\n", p.paint(ansiDim, "Persona:"), session.Persona)
+	}
+	if session.Seed != nil {
+		fmt.Fprintf(&b, "%s %d\n", p.paint(ansiDim, "Seed:"), *session.Seed)
+	}
+	if !session.StartedAt.IsZero() {
+		fmt.Fprintf(&b, "%s %s\n", p.paint(ansiDim, "Started:"), formatTimestamp(session.StartedAt))
+	}
+	if len(session.Tags) > 0 {
+		fmt.Fprintf(&b, "%s %s\n", p.paint(ansiDim, "Tags:"), strings.Join(session.Tags, ", "))
+	}
+	for _, note := range session.Notes {
+		fmt.Fprintf(&b, "%s %s\n", p.paint(ansiDim, "Note:"), note)
+	}
+	writePrompt(&b, p, "System prompt:", session.SystemPrompt)
+	writePrompt(&b, p, "Tool prompt:", session.ToolPrompt)
+
+	for _, msg := range session.Messages {
+		b.WriteByte('\n')
+		header := opts.Labels.role(msg.Role) + ":"
+		switch msg.Role {
+		case "user":
+			header = p.paint(ansiBold+ansiCyan, header)
+		case "assistant":
+			header = p.paint(ansiBold+ansiGreen, header)
+		default:
+			header = p.paint(ansiBold, header)
+		}
+		if !msg.Timestamp.IsZero() {
+			header = p.paint(ansiDim, "["+formatTimestamp(msg.Timestamp)+"]") + " " + header
+		}
+		b.WriteString(header)
+		b.WriteByte('\n')
+
+		for _, call := range msg.ToolCalls {
+			b.WriteString(p.paint(ansiYellow, "  ↳ "+ToolCallSummary(call)))
+			if call.IsError {
+				b.WriteString(" " + p.paint(ansiRed, "(error)"))
+			}
+			b.WriteByte('\n')
+		}
+
+		content, citations := ResolveCitations(msg.Content, msg.ToolCalls)
+		content = strings.TrimRight(LabelCodeFences(content), "\n")
+		if p.enabled {
+			content = colorDiffBlocks(content)
+		}
+		if content != "" {
+			b.WriteString(content)
+			b.WriteByte('\n')
+		}
+		if len(citations) > 0 {
+			b.WriteString(p.paint(ansiDim, "Sources:"))
+			b.WriteByte('\n')
+			for _, citation := range citations {
+				b.WriteString(p.paint(ansiDim, fmt.Sprintf("  [%d] %s", citation.Number, ToolCallSummary(citation.Call))))
+				b.WriteByte('\n')
+			}
+		}
+		if msg.Rating != nil {
+			b.WriteString(p.paint(ansiDim, ratingSummary(*msg.Rating)))
+			b.WriteByte('\n')
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func ratingSummary(rating history.Rating) string {
+	summary := fmt.Sprintf("Rating: %d/5", rating.Score)
+	if rating.Comment != "" {
+		summary += " — " + rating.Comment
+	}
+	return summary
+}
+
+// writePrompt prints a pinned prompt indented under its label.
+func writePrompt(b *strings.Builder, p painter, label, prompt string) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s\n", p.paint(ansiDim, label))
+	for _, line := range strings.Split(prompt, "\n") {
+		b.WriteString(strings.TrimRight("  "+line, " "))
+		b.WriteByte('\n')
+	}
+}
+
+// ToolCallSummary returns a single-line description of a tool call and its result.
+func ToolCallSummary(call history.ToolCall) string {
+	var b strings.Builder
+	b.WriteString(call.Server)
+	b.WriteString(".")
+	b.WriteString(call.Method)
+	b.WriteString("(")
+	b.WriteString(truncate(formatArguments(call.Arguments), maxToolSummaryLen))
+	b.WriteString(")")
+	if result := strings.TrimSpace(call.Result); result != "" {
+		b.WriteString(" → ")
+		b.WriteString(truncate(strings.Join(strings.Fields(result), " "), maxToolSummaryLen))
+	}
+	return b.String()
+}
+
+func formatArguments(args map[string]any) string {
+	if len(args) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := json.Marshal(args[key])
+		if err != nil {
+			value = []byte(fmt.Sprint(args[key]))
+		}
+		parts = append(parts, key+"="+string(value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (l Labels) role(role string) string {
+	switch {
+	case role == "user" && l.User != "":
+		return l.User
+	case role == "assistant" && l.Assistant != "":
+		return l.Assistant
+	}
+	switch role {
+	case "user":
+		return "You"
+	case "assistant":
+		return "Assistant"
+	case "":
+		return "Unknown"
+	default:
+		return strings.ToUpper(role[:1]) + role[1:]
+	}
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
@@ -0,0 +1,180 @@
+package render_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/history"
+	"github.com/gamzabox/humble-ai-cli/internal/render"
+)
+
+func TestTranscriptIncludesTimestampsAndToolSummaries(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	session := history.Session{
+		Model:     "gpt-4o",
+		StartedAt: at,
+		Messages: []history.Message{
+			{Role: "user", Content: "What is 1+2?", Timestamp: at},
+			{
+				Role:      "assistant",
+				Content:   "It is 3.",
+				Timestamp: at.Add(2 * time.Second),
+				ToolCalls: []history.ToolCall{{
+					Server:    "calculator",
+					Method:    "add",
+					Arguments: map[string]any{"a": 1, "b": 2},
+					Result:    "3",
+				}},
+				Rating: &history.Rating{Score: 4, Comment: "correct but terse"},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := render.Transcript(&out, session, render.Options{Title: "demo.json"}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	got := out.String()
+	for _, phrase := range []string{
+		"demo.json",
+		"Model: gpt-4o",
+		"[2025-01-02 03:04:05] You:",
+		"[2025-01-02 03:04:07] Assistant:",
+		"calculator.add(a=1, b=2) → 3",
+		"It is 3.\nRating: 4/5 — correct but terse\n",
+	} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected transcript to contain %q, got:\n%s", phrase, got)
+		}
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Fatalf("expected no ANSI codes without Color, got %q", got)
+	}
+
+	out.Reset()
+	if err := render.Transcript(&out, session, render.Options{Color: true}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "\x1b[") {
+		t.Fatalf("expected ANSI codes with Color enabled")
+	}
+}
+
+func TestTranscriptShowsPinnedPrompts(t *testing.T) {
+	session := history.Session{
+		Model:        "gpt-4o",
+		SystemPrompt: "Be brief.\nCite sources.",
+		ToolPrompt:   "FUNCTIONS:\n\n# Connected MCP Servers",
+		Messages:     []history.Message{{Role: "user", Content: "hi"}},
+	}
+
+	var out bytes.Buffer
+	if err := render.Transcript(&out, session, render.Options{}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	got := out.String()
+	for _, phrase := range []string{
+		"System prompt:\n  Be brief.\n  Cite sources.\n",
+		"Tool prompt:\n  FUNCTIONS:\n\n  # Connected MCP Servers\n",
+	} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected transcript to contain %q, got:\n%s", phrase, got)
+		}
+	}
+
+	out.Reset()
+	if err := render.HTML(&out, session, render.HTMLOptions{}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "<summary>System prompt</summary>\n<pre>Be brief.\nCite sources.</pre>") {
+		t.Fatalf("expected HTML to include the system prompt, got:\n%s", out.String())
+	}
+}
+
+func TestTranscriptUsesLabels(t *testing.T) {
+	session := history.Session{
+		Model:    "claude",
+		Messages: []history.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}},
+	}
+	labels := render.Labels{User: "Me", Assistant: "Claude"}
+
+	var out bytes.Buffer
+	if err := render.Transcript(&out, session, render.Options{Labels: labels}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "Me:\nhi\n") || !strings.Contains(got, "Claude:\nhello\n") || strings.Contains(got, "You:") {
+		t.Fatalf("expected configured labels, got:\n%s", got)
+	}
+
+	out.Reset()
+	if err := render.HTML(&out, session, render.HTMLOptions{Labels: render.Labels{Assistant: "Claude"}}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `<div class="role">You`) || !strings.Contains(out.String(), `<div class="role">Claude`) {
+		t.Fatalf("expected the assistant label and the default user label, got:\n%s", out.String())
+	}
+}
+
+func TestTranscriptColorsDiffBlocks(t *testing.T) {
+	session := history.Session{
+		Model: "gpt-4o",
+		Messages: []history.Message{{
+			Role:    "assistant",
+			Content: "Change:\n```\ndiff --git a/app.go b/app.go\n--- a/app.go\n+++ b/app.go\n@@ -1 +1 @@\n-old\n+new\n```\n- not a diff line",
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := render.Transcript(&out, session, render.Options{Color: true}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	got := out.String()
+	for _, phrase := range []string{
+		"```diff\n\x1b[1mdiff --git a/app.go b/app.go\x1b[0m\n",
+		"\x1b[1m+++ b/app.go\x1b[0m\n\x1b[36m@@ -1 +1 @@\x1b[0m\n",
+		"\x1b[31m-old\x1b[0m\n\x1b[32m+new\x1b[0m\n```\n- not a diff line",
+	} {
+		if !strings.Contains(got, phrase) {
+			t.Fatalf("expected transcript to contain %q, got %q", phrase, got)
+		}
+	}
+}
+
+func TestTranscriptFootnotesCitations(t *testing.T) {
+	session := history.Session{
+		Model: "gpt-4o",
+		Messages: []history.Message{{
+			Role:    "assistant",
+			Content: "Paris is the capital [ref:toolcall-2]; see also [ref:toolcall-7].",
+			ToolCalls: []history.ToolCall{
+				{Server: "web", Method: "search", Arguments: map[string]any{"q": "france"}, Result: "France, a country"},
+				{Server: "web", Method: "fetch", Arguments: map[string]any{"url": "https://example.org"}, Result: "Capital: Paris"},
+			},
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := render.Transcript(&out, session, render.Options{}); err != nil {
+		t.Fatalf("Transcript() error = %v", err)
+	}
+	want := "Paris is the capital [2]; see also [ref:toolcall-7].\nSources:\n  [2] web.fetch(url=\"https://example.org\") → Capital: Paris\n"
+	if got := out.String(); !strings.Contains(got, want) {
+		t.Fatalf("expected transcript to contain %q, got:\n%s", want, got)
+	}
+
+	out.Reset()
+	if err := render.HTML(&out, session, render.HTMLOptions{}); err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	for _, phrase := range []string{
+		`capital <sup class="cite"><a href="#cite-0-2">[2]</a></sup>; see also [ref:toolcall-7].`,
+		`<li id="cite-0-2" value="2">web.fetch(url=&#34;https://example.org&#34;) → Capital: Paris</li>`,
+	} {
+		if !strings.Contains(out.String(), phrase) {
+			t.Fatalf("expected HTML to contain %q, got:\n%s", phrase, out.String())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) // Thursday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 2, 3, 5, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 2, 3, 15, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 1, 3, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2025, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 3 *", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", base.Add(90 * time.Minute)},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tc.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Fatalf("Parse(%q).Next() = %s, want %s", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestCronDayFieldsMatchEither(t *testing.T) {
+	s, err := Parse("0 0 13 * 5") // the 13th or any Friday
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got := s.Next(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("Next() = %s, want %s", got, want)
+	}
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "@every soon", "@every -1m"} {
+		if _, err := Parse(spec); err == nil {
+			t.Fatalf("Parse(%q) expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// VoicePlaceholder in a command argument is replaced with the configured voice.
+const VoicePlaceholder = "{voice}"
+
+// Sink speaks a single sentence and returns once it has been spoken.
+type Sink interface {
+	Speak(ctx context.Context, text string) error
+}
+
+// NewSink builds the sink described by the speech config: the endpoint when one is
+// set, otherwise the command or the platform's default speech command.
+func NewSink(cfg config.Speech, client *http.Client) Sink {
+	if cfg.Endpoint != "" {
+		if client == nil {
+			client = http.DefaultClient
+		}
+		return &HTTPSink{
+			Client:   client,
+			Endpoint: cfg.Endpoint,
+			Model:    cfg.Model,
+			Voice:    cfg.Voice,
+			APIKey:   cfg.APIKey,
+			Player:   cfg.Player,
+		}
+	}
+	args := cfg.Command
+	if len(args) == 0 {
+		args = DefaultCommand(cfg.Voice)
+	}
+	return &CommandSink{Args: args, Voice: cfg.Voice}
+}
+
+// DefaultCommand is say on macOS and espeak elsewhere; both read text from stdin.
+func DefaultCommand(voice string) []string {
+	name := "espeak"
+	if runtime.GOOS == "darwin" {
+		name = "say"
+	}
+	if voice == "" {
+		return []string{name}
+	}
+	return []string{name, "-v", VoicePlaceholder}
+}
+
+// Describe names a sink for status messages.
+func Describe(sink Sink) string {
+	switch s := sink.(type) {
+	case *CommandSink:
+		if len(s.Args) > 0 {
+			return s.Args[0]
+		}
+	case *HTTPSink:
+		return s.Endpoint
+	}
+	return "custom sink"
+}
+
+// CommandSink pipes each sentence to a local command such as say or espeak.
+type CommandSink struct {
+	Args  []string
+	Voice string
+}
+
+// Speak runs the command with the sentence on stdin.
+func (c *CommandSink) Speak(ctx context.Context, text string) error {
+	if len(c.Args) == 0 {
+		return errors.New("speech command is empty")
+	}
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = strings.ReplaceAll(arg, VoicePlaceholder, c.Voice)
+	}
+	return runWithInput(ctx, args, strings.NewReader(text))
+}
+
+// HTTPSink posts each sentence to an OpenAI-style speech endpoint and plays the
+// returned audio with Player. Without a player the endpoint is expected to play it.
+type HTTPSink struct {
+	Client   *http.Client
+	Endpoint string
+	Model    string
+	Voice    string
+	APIKey   string
+	Player   []string
+}
+
+// Speak requests audio for the sentence and plays it.
+func (h *HTTPSink) Speak(ctx context.Context, text string) error {
+	payload, err := json.Marshal(map[string]string{
+		"model": h.Model,
+		"voice": h.Voice,
+		"input": text,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("speech endpoint %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if len(h.Player) == 0 {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return runWithInput(ctx, h.Player, resp.Body)
+}
+
+func runWithInput(ctx context.Context, args []string, input io.Reader) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
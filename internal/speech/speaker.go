@@ -0,0 +1,115 @@
+package speech
+
+import (
+	"context"
+	"sync"
+)
+
+// Speaker speaks queued sentences in order on a background goroutine, so streaming
+// never waits for speech.
+type Speaker struct {
+	sink Sink
+
+	mu      sync.Mutex
+	queue   []string
+	cancel  context.CancelFunc
+	err     error
+	closed  bool
+	wake    chan struct{}
+	stopped chan struct{}
+}
+
+// NewSpeaker starts a speaker for the sink; call Close to stop it.
+func NewSpeaker(sink Sink) *Speaker {
+	s := &Speaker{
+		sink:    sink,
+		wake:    make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Sink returns the sink sentences are spoken through.
+func (s *Speaker) Sink() Sink {
+	return s.sink
+}
+
+// Say queues a sentence.
+func (s *Speaker) Say(text string) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.queue = append(s.queue, text)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	s.mu.Unlock()
+}
+
+// Stop drops queued sentences and interrupts the one being spoken.
+func (s *Speaker) Stop() {
+	s.mu.Lock()
+	s.queue = nil
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+}
+
+// Err returns and clears the first error since the last call.
+func (s *Speaker) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Close stops speaking and waits for the background goroutine to exit.
+func (s *Speaker) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.queue = nil
+	if s.cancel != nil {
+		s.cancel()
+	}
+	close(s.wake)
+	s.mu.Unlock()
+	<-s.stopped
+}
+
+func (s *Speaker) run() {
+	defer close(s.stopped)
+	for range s.wake {
+		for {
+			s.mu.Lock()
+			if len(s.queue) == 0 || s.closed {
+				s.mu.Unlock()
+				break
+			}
+			text := s.queue[0]
+			s.queue = s.queue[1:]
+			ctx, cancel := context.WithCancel(context.Background())
+			s.cancel = cancel
+			s.mu.Unlock()
+
+			err := s.sink.Speak(ctx, text)
+
+			s.mu.Lock()
+			if err != nil && ctx.Err() == nil && s.err == nil {
+				s.err = err
+			}
+			s.cancel = nil
+			s.mu.Unlock()
+			cancel()
+		}
+	}
+}
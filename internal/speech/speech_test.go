@@ -0,0 +1,142 @@
+package speech_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/speech"
+)
+
+func splitAll(tokens ...string) []string {
+	var s speech.Splitter
+	var out []string
+	for _, token := range tokens {
+		out = append(out, s.Write(token)...)
+	}
+	return append(out, s.Flush()...)
+}
+
+func TestSplitterEmitsSentencesAsTheyComplete(t *testing.T) {
+	var s speech.Splitter
+	if got := s.Write("Hello there. How are"); !reflect.DeepEqual(got, []string{"Hello there."}) {
+		t.Fatalf("Write() = %q", got)
+	}
+	if got := s.Write(" you? Fine"); !reflect.DeepEqual(got, []string{"How are you?"}) {
+		t.Fatalf("Write() = %q", got)
+	}
+	if got := s.Flush(); !reflect.DeepEqual(got, []string{"Fine"}) {
+		t.Fatalf("Flush() = %q", got)
+	}
+}
+
+func TestSplitterSkipsCodeAndMarkdown(t *testing.T) {
+	got := splitAll(
+		"## Steps\n\n1. Install it, e.g. with **brew**. Then run `make`.\n",
+		"``", "`bash\nmake test. really\n```\n",
+		"See [the docs](https://example.com) for more_info!",
+	)
+	want := []string{"Steps", "Install it, e.g. with brew.", "Then run make.", "See the docs for more_info!"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sentences = %q, want %q", got, want)
+	}
+}
+
+func TestCommandSinkPipesSentenceAndVoice(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "spoken.txt")
+	sink := &speech.CommandSink{
+		Args:  []string{"sh", "-c", `printf '%s:' "$0" >> "$1"; cat >> "$1"`, speech.VoicePlaceholder, out},
+		Voice: "Alex",
+	}
+	if err := sink.Speak(context.Background(), "Hello."); err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if string(data) != "Alex:Hello." {
+		t.Fatalf("expected voice and sentence, got %q", data)
+	}
+}
+
+func TestHTTPSinkPlaysReturnedAudio(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("missing authorization header")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte("AUDIO"))
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "audio.bin")
+	sink := speech.NewSink(config.Speech{
+		Endpoint: server.URL,
+		Model:    "tts-1",
+		Voice:    "alloy",
+		APIKey:   "sk-test",
+		Player:   []string{"sh", "-c", `cat > "$0"`, out},
+	}, server.Client())
+	if err := sink.Speak(context.Background(), "Hi."); err != nil {
+		t.Fatalf("Speak() error = %v", err)
+	}
+	if want := map[string]string{"model": "tts-1", "voice": "alloy", "input": "Hi."}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("payload = %v, want %v", got, want)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "AUDIO" {
+		t.Fatalf("expected audio piped to the player, got %q", data)
+	}
+}
+
+type recordingSink struct {
+	mu      sync.Mutex
+	spoken  []string
+	failing string
+}
+
+func (r *recordingSink) Speak(ctx context.Context, text string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if text == r.failing {
+		return os.ErrPermission
+	}
+	r.spoken = append(r.spoken, text)
+	return nil
+}
+
+func (r *recordingSink) Spoken() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.spoken...)
+}
+
+func TestSpeakerSpeaksInOrderAndReportsErrors(t *testing.T) {
+	sink := &recordingSink{failing: "bad"}
+	speaker := speech.NewSpeaker(sink)
+	defer speaker.Close()
+
+	for _, text := range []string{"one", "bad", "two"} {
+		speaker.Say(text)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.Spoken()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := sink.Spoken(); !reflect.DeepEqual(got, []string{"one", "two"}) {
+		t.Fatalf("spoken = %q", got)
+	}
+	if err := speaker.Err(); err == nil || !strings.Contains(err.Error(), "permission") {
+		t.Fatalf("expected the sink error to be reported, got %v", err)
+	}
+	if err := speaker.Err(); err != nil {
+		t.Fatalf("expected Err to clear, got %v", err)
+	}
+}
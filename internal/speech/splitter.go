@@ -0,0 +1,142 @@
+// Package speech reads assistant answers aloud one sentence at a time.
+package speech
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Splitter turns streamed answer tokens into speakable sentences. Fenced code blocks
+// are skipped and markdown markup is removed.
+type Splitter struct {
+	// line is the unfinished tail of the current line.
+	line   string
+	inCode bool
+}
+
+// Write adds streamed text and returns the sentences it completed.
+func (s *Splitter) Write(text string) []string {
+	s.line += text
+	var out []string
+	for {
+		nl := strings.IndexByte(s.line, '\n')
+		if nl < 0 {
+			break
+		}
+		line := s.line[:nl]
+		s.line = s.line[nl+1:]
+		out = append(out, s.completeLine(line)...)
+	}
+	if !s.inCode && !mayOpenFence(s.line) {
+		sentences, rest := splitSentences(s.line)
+		out = append(out, sentences...)
+		s.line = rest
+	}
+	return out
+}
+
+// Flush returns whatever is left once the answer is complete and resets the splitter.
+func (s *Splitter) Flush() []string {
+	line := s.line
+	s.line = ""
+	if s.inCode {
+		s.inCode = false
+		return nil
+	}
+	return s.completeLine(line)
+}
+
+// Reset drops buffered text, e.g. when a response is cancelled.
+func (s *Splitter) Reset() {
+	s.line = ""
+	s.inCode = false
+}
+
+func (s *Splitter) completeLine(line string) []string {
+	if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		s.inCode = !s.inCode
+		return nil
+	}
+	if s.inCode {
+		return nil
+	}
+	sentences, rest := splitSentences(line)
+	if rest = Clean(rest); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// mayOpenFence reports whether an unfinished line could still turn out to be a code fence.
+func mayOpenFence(line string) bool {
+	trimmed := strings.TrimLeft(line, " \t")
+	return strings.HasPrefix("```", trimmed) || strings.HasPrefix(trimmed, "```")
+}
+
+// splitSentences cuts text after sentence-ending punctuation followed by a space and
+// returns the cleaned sentences and the unfinished remainder.
+func splitSentences(text string) ([]string, string) {
+	var out []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if !isSentenceEnd(text[i]) {
+			continue
+		}
+		end := i + 1
+		for end < len(text) && strings.IndexByte(`"')]*_`, text[end]) >= 0 {
+			end++
+		}
+		if end >= len(text) || text[end] != ' ' {
+			continue
+		}
+		if text[i] == '.' && !endsSentence(text[start:i]) {
+			continue
+		}
+		if sentence := Clean(text[start:end]); sentence != "" {
+			out = append(out, sentence)
+		}
+		start = end + 1
+		i = end
+	}
+	return out, text[start:]
+}
+
+func isSentenceEnd(b byte) bool {
+	return b == '.' || b == '!' || b == '?'
+}
+
+// abbreviations end with a period without ending the sentence.
+var abbreviations = map[string]bool{
+	"e.g": true, "i.e": true, "etc": true, "vs": true, "mr": true, "mrs": true, "ms": true, "dr": true, "st": true,
+}
+
+// endsSentence rejects periods after list numbers ("1.") and common abbreviations.
+func endsSentence(before string) bool {
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return false
+	}
+	last := strings.ToLower(strings.TrimLeft(fields[len(fields)-1], "(\"'*_"))
+	if abbreviations[last] {
+		return false
+	}
+	if len(fields) == 1 && strings.IndexFunc(last, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+		return false
+	}
+	return true
+}
+
+var (
+	markdownLinkPattern   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownPrefixPattern = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s*|[-*+]\s+|\d+[.)]\s+)+`)
+	markdownMarkPattern   = regexp.MustCompile("[*`~]+")
+)
+
+// Clean removes markdown markup so a sentence reads naturally.
+func Clean(text string) string {
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = markdownPrefixPattern.ReplaceAllString(text, "")
+	text = markdownMarkPattern.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,182 @@
+// Package textdiff computes line-based unified diffs for comparing answers.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind identifies how a line changed between two texts.
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op is a single line of a diff.
+type Op struct {
+	Kind OpKind
+	Text string
+}
+
+// Lines returns the line-level edit script turning a into b, based on the longest
+// common subsequence of lines.
+func Lines(a, b string) []Op {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]Op, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			ops = append(ops, Op{Kind: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{Kind: Delete, Text: x[i]})
+			i++
+		default:
+			ops = append(ops, Op{Kind: Insert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		ops = append(ops, Op{Kind: Delete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		ops = append(ops, Op{Kind: Insert, Text: y[j]})
+	}
+	return ops
+}
+
+// Unified formats the diff between a and b in unified format with the given number
+// of context lines. It returns an empty string when the texts have the same lines.
+func Unified(oldName, newName, a, b string, context int) string {
+	ops := Lines(a, b)
+	changed := false
+	for _, op := range ops {
+		if op.Kind != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops, context) {
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines))
+		for _, op := range ops[h.from:h.to] {
+			switch op.Kind {
+			case Equal:
+				out.WriteString(" ")
+			case Delete:
+				out.WriteString("-")
+			case Insert:
+				out.WriteString("+")
+			}
+			out.WriteString(op.Text)
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+type hunk struct {
+	from, to           int
+	oldStart, oldLines int
+	newStart, newLines int
+}
+
+// hunks groups changed ops with up to context unchanged lines around them, merging
+// groups whose context overlaps.
+func hunks(ops []Op, context int) []hunk {
+	var out []hunk
+	oldLine, newLine := 1, 1
+	oldAt := make([]int, len(ops))
+	newAt := make([]int, len(ops))
+	for i, op := range ops {
+		oldAt[i], newAt[i] = oldLine, newLine
+		if op.Kind != Insert {
+			oldLine++
+		}
+		if op.Kind != Delete {
+			newLine++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		from := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == Equal {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+		to := min(end+context, len(ops))
+
+		h := hunk{from: from, to: to, oldStart: oldAt[from], newStart: newAt[from]}
+		for _, op := range ops[from:to] {
+			if op.Kind != Insert {
+				h.oldLines++
+			}
+			if op.Kind != Delete {
+				h.newLines++
+			}
+		}
+		out = append(out, h)
+		i = to
+	}
+	return out
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
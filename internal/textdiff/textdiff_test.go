@@ -0,0 +1,37 @@
+package textdiff
+
+import "testing"
+
+func TestUnifiedReportsChangedLinesWithContext(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	got := Unified("previous", "current", a, b, 1)
+	want := "--- previous\n+++ current\n" +
+		"@@ -2,3 +2,3 @@\n two\n-three\n+THREE\n four\n" +
+		"@@ -10 +10,2 @@\n ten\n+eleven\n"
+	if got != want {
+		t.Fatalf("Unified() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedMergesNearbyChanges(t *testing.T) {
+	got := Unified("a", "b", "x\n1\n2\ny\n", "X\n1\n2\nY\n", 1)
+	want := "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-x\n+X\n 1\n 2\n-y\n+Y\n"
+	if got != want {
+		t.Fatalf("Unified() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedIdenticalTextIsEmpty(t *testing.T) {
+	if got := Unified("a", "b", "same\ntext", "same\ntext\n", 3); got != "" {
+		t.Fatalf("Unified() = %q, want empty", got)
+	}
+}
+
+func TestLinesHandlesEmptyInput(t *testing.T) {
+	ops := Lines("", "new")
+	if len(ops) != 1 || ops[0] != (Op{Kind: Insert, Text: "new"}) {
+		t.Fatalf("Lines() = %+v", ops)
+	}
+}
@@ -0,0 +1,244 @@
+// Package tokenizer estimates token counts for context chunking and preflight checks.
+//
+// The estimators approximate each encoding's behaviour without shipping BPE vocabularies:
+// ASCII words are divided by the encoding's average characters per token, punctuation costs
+// one token, and non-ASCII runes cost a per-encoding fraction of a token.
+package tokenizer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Encoding names accepted by the model `tokenizer` setting.
+const (
+	O200KBase = "o200k_base"
+	Llama     = "llama"
+	Heuristic = "heuristic"
+	// CL100KBase is accepted as an alias of Heuristic.
+	CL100KBase = "cl100k_base"
+)
+
+// Counter estimates how many tokens a text occupies.
+type Counter interface {
+	Name() string
+	Count(text string) int
+}
+
+type estimator struct {
+	name string
+	// charsPerToken is the average ASCII word length covered by one token.
+	charsPerToken float64
+	// wideRuneTokens is the cost of a single non-ASCII rune.
+	wideRuneTokens float64
+}
+
+var encodings = map[string]estimator{
+	O200KBase: {name: O200KBase, charsPerToken: 4.4, wideRuneTokens: 0.6},
+	Llama:     {name: Llama, charsPerToken: 3.6, wideRuneTokens: 1.5},
+	// Heuristic also stands in for cl100k_base models (gpt-4, gpt-3.5), whose averages it
+	// matches.
+	Heuristic: {name: Heuristic, charsPerToken: 4.0, wideRuneTokens: 1.0},
+}
+
+// aliases maps accepted encoding names to the estimator that stands in for them.
+var aliases = map[string]string{
+	CL100KBase: Heuristic,
+}
+
+// Names lists the supported encoding names.
+func Names() []string {
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the counter for an encoding name or alias.
+func Lookup(name string) (Counter, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if target, ok := aliases[key]; ok {
+		key = target
+	}
+	enc, ok := encodings[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown tokenizer %q", name)
+	}
+	return enc, nil
+}
+
+// ForModel returns the configured tokenizer, or one inferred from the model name.
+// Unknown models fall back to the heuristic estimator.
+func ForModel(modelName, configured string) Counter {
+	if strings.TrimSpace(configured) != "" {
+		if counter, err := Lookup(configured); err == nil {
+			return counter
+		}
+	}
+	return encodings[inferEncoding(modelName)]
+}
+
+func inferEncoding(modelName string) string {
+	name := strings.ToLower(modelName)
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	switch {
+	case strings.HasPrefix(name, "gpt-4o"), strings.HasPrefix(name, "gpt-4.1"),
+		strings.HasPrefix(name, "gpt-5"), strings.HasPrefix(name, "o1"),
+		strings.HasPrefix(name, "o3"), strings.HasPrefix(name, "o4"):
+		return O200KBase
+	case strings.Contains(name, "llama"), strings.Contains(name, "mistral"),
+		strings.Contains(name, "mixtral"), strings.Contains(name, "gemma"):
+		return Llama
+	default:
+		return Heuristic
+	}
+}
+
+func (e estimator) Name() string {
+	return e.name
+}
+
+func (e estimator) Count(text string) int {
+	var (
+		total   float64
+		wordLen int
+	)
+	flushWord := func() {
+		if wordLen > 0 {
+			total += math.Ceil(float64(wordLen) / e.charsPerToken)
+			wordLen = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			wordLen++
+		case r == '\n':
+			flushWord()
+			total++
+		case unicode.IsSpace(r):
+			// Leading spaces are merged into the following word token.
+			flushWord()
+		case r < unicode.MaxASCII:
+			flushWord()
+			total++
+		default:
+			flushWord()
+			total += e.wideRuneTokens
+		}
+	}
+	flushWord()
+	return int(math.Ceil(total))
+}
+
+// Chunker splits text into pieces that each fit within MaxTokens.
+type Chunker struct {
+	Counter   Counter
+	MaxTokens int
+	// OverlapTokens repeats up to this many trailing tokens of a chunk at the start
+	// of the next one so context is not lost at boundaries. It is capped at half of MaxTokens.
+	OverlapTokens int
+	// StructureAware prefers paragraph, line, JSON element and sentence boundaries
+	// before falling back to words and runes.
+	StructureAware bool
+}
+
+var (
+	hardSeparators      = []string{"\n", " "}
+	structureSeparators = []string{"\n\n", "\n", "},", "],", ",", ". ", " "}
+)
+
+type piece struct {
+	text   string
+	tokens int
+}
+
+// Chunk splits text into pieces of at most maxTokens according to counter,
+// preferring line boundaries, then word boundaries, then rune boundaries.
+func Chunk(text string, maxTokens int, counter Counter) []string {
+	return Chunker{Counter: counter, MaxTokens: maxTokens}.Split(text)
+}
+
+// Split breaks text into chunks. Without overlap the chunks concatenate back to text.
+func (c Chunker) Split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	if c.MaxTokens <= 0 || c.Counter.Count(text) <= c.MaxTokens {
+		return []string{text}
+	}
+
+	separators := hardSeparators
+	if c.StructureAware {
+		separators = structureSeparators
+	}
+	overlap := min(max(c.OverlapTokens, 0), c.MaxTokens/2)
+
+	var (
+		chunks        []string
+		current       []piece
+		currentTokens int
+	)
+	flush := func() {
+		var b strings.Builder
+		for _, p := range current {
+			b.WriteString(p.text)
+		}
+		chunks = append(chunks, b.String())
+
+		keep := len(current)
+		kept := 0
+		for keep > 0 && kept+current[keep-1].tokens <= overlap {
+			keep--
+			kept += current[keep].tokens
+		}
+		current = append([]piece(nil), current[keep:]...)
+		currentTokens = kept
+	}
+
+	for _, p := range c.pieces(text, separators) {
+		if len(current) > 0 && currentTokens+p.tokens > c.MaxTokens {
+			flush()
+			for len(current) > 0 && currentTokens+p.tokens > c.MaxTokens {
+				currentTokens -= current[0].tokens
+				current = current[1:]
+			}
+		}
+		current = append(current, p)
+		currentTokens += p.tokens
+	}
+	if len(current) > 0 {
+		flush()
+	}
+	return chunks
+}
+
+// pieces recursively splits text on separators until every piece fits MaxTokens.
+func (c Chunker) pieces(text string, separators []string) []piece {
+	if tokens := c.Counter.Count(text); tokens <= c.MaxTokens {
+		return []piece{{text: text, tokens: tokens}}
+	}
+	if len(separators) == 0 {
+		out := make([]piece, 0, len(text))
+		for _, r := range text {
+			out = append(out, piece{text: string(r), tokens: c.Counter.Count(string(r))})
+		}
+		return out
+	}
+	parts := strings.SplitAfter(text, separators[0])
+	var out []piece
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		out = append(out, c.pieces(part, separators[1:])...)
+	}
+	return out
+}
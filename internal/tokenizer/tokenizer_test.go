@@ -0,0 +1,122 @@
+package tokenizer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/tokenizer"
+)
+
+func TestForModelInfersEncoding(t *testing.T) {
+	cases := map[string]string{
+		"gpt-4o-mini":              tokenizer.O200KBase,
+		"openai/gpt-4.1":           tokenizer.O200KBase,
+		"o3-mini":                  tokenizer.O200KBase,
+		"gpt-4-turbo":              tokenizer.Heuristic,
+		"gpt-3.5-turbo":            tokenizer.Heuristic,
+		"llama3.1:8b":              tokenizer.Llama,
+		"mistralai/mistral-7b":     tokenizer.Llama,
+		"some-unknown-local-model": tokenizer.Heuristic,
+	}
+	for model, want := range cases {
+		if got := tokenizer.ForModel(model, "").Name(); got != want {
+			t.Errorf("ForModel(%q) = %s, want %s", model, got, want)
+		}
+	}
+
+	if got := tokenizer.ForModel("gpt-4o", "llama").Name(); got != tokenizer.Llama {
+		t.Fatalf("expected configured tokenizer to win, got %s", got)
+	}
+	if got := tokenizer.ForModel("gpt-4o", "bogus").Name(); got != tokenizer.O200KBase {
+		t.Fatalf("expected unknown configured tokenizer to fall back to inference, got %s", got)
+	}
+}
+
+func TestCountDiffersByEncoding(t *testing.T) {
+	text := "안녕하세요 반갑습니다. The quick brown fox jumps over the lazy dog."
+	heuristic, _ := tokenizer.Lookup(tokenizer.Heuristic)
+	o200k, _ := tokenizer.Lookup(tokenizer.O200KBase)
+	llama, _ := tokenizer.Lookup(tokenizer.Llama)
+
+	if heuristic.Count("") != 0 {
+		t.Fatalf("expected empty text to count as 0 tokens")
+	}
+	if !(o200k.Count(text) < heuristic.Count(text) && heuristic.Count(text) < llama.Count(text)) {
+		t.Fatalf("expected o200k < heuristic < llama, got %d, %d, %d",
+			o200k.Count(text), heuristic.Count(text), llama.Count(text))
+	}
+	if _, err := tokenizer.Lookup("p50k_base"); err == nil {
+		t.Fatalf("expected error for unknown encoding")
+	}
+	alias, err := tokenizer.Lookup(" CL100K_BASE ")
+	if err != nil || alias.Name() != tokenizer.Heuristic || alias.Count(text) != heuristic.Count(text) {
+		t.Fatalf("expected cl100k_base to map to the heuristic estimator, got %v, %v", alias, err)
+	}
+}
+
+func TestChunkRespectsTokenLimit(t *testing.T) {
+	counter, _ := tokenizer.Lookup(tokenizer.Heuristic)
+	text := strings.Repeat("alpha beta gamma delta\n", 20) + strings.Repeat("x", 200)
+
+	chunks := tokenizer.Chunk(text, 16, counter)
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	if strings.Join(chunks, "") != text {
+		t.Fatalf("chunks do not reassemble the original text")
+	}
+	for i, chunk := range chunks {
+		if got := counter.Count(chunk); got > 16 {
+			t.Fatalf("chunk %d has %d tokens, exceeding limit", i, got)
+		}
+	}
+
+	if got := tokenizer.Chunk("short", 16, counter); len(got) != 1 || got[0] != "short" {
+		t.Fatalf("expected short text to stay whole, got %#v", got)
+	}
+}
+
+func TestChunkerStructureAwarePrefersJSONBoundaries(t *testing.T) {
+	counter, _ := tokenizer.Lookup(tokenizer.Heuristic)
+	var items []string
+	for i := 0; i < 30; i++ {
+		items = append(items, `{"id":`+strings.Repeat("1", 3)+`,"name":"item"}`)
+	}
+	text := "[" + strings.Join(items, ",") + "]"
+
+	chunks := tokenizer.Chunker{Counter: counter, MaxTokens: 40, StructureAware: true}.Split(text)
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	if strings.Join(chunks, "") != text {
+		t.Fatalf("chunks do not reassemble the original text")
+	}
+	for i, chunk := range chunks[:len(chunks)-1] {
+		if !strings.HasSuffix(chunk, "},") {
+			t.Fatalf("chunk %d does not end on a JSON element boundary: %q", i, chunk)
+		}
+	}
+}
+
+func TestChunkerOverlapRepeatsTrailingContext(t *testing.T) {
+	counter, _ := tokenizer.Lookup(tokenizer.Heuristic)
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, "item "+string(rune('a'+i))+".\n")
+	}
+	text := strings.Join(lines, "")
+
+	chunks := tokenizer.Chunker{Counter: counter, MaxTokens: 24, OverlapTokens: 8, StructureAware: true}.Split(text)
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	for i := 1; i < len(chunks); i++ {
+		firstLine := strings.SplitAfter(chunks[i], "\n")[0]
+		if !strings.Contains(chunks[i-1], firstLine) {
+			t.Fatalf("chunk %d does not start with overlap from the previous chunk: %q", i, chunks[i])
+		}
+		if got := counter.Count(chunks[i]); got > 24 {
+			t.Fatalf("chunk %d has %d tokens, exceeding limit", i, got)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package tui
+
+// notifyResize does nothing on Windows, where a resize is no signal; the screen picks up
+// the new size at its next redraw.
+func notifyResize(func()) (stop func()) {
+	return func() {}
+}
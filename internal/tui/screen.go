@@ -0,0 +1,541 @@
+// Package tui is a full-screen terminal frontend for the interactive chat: a scrollable
+// conversation pane, a status bar and an input box. It implements app.UI, so the App runs
+// the same turns as in the plain CLI and only the presentation differs.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	reverseVideo   = "\x1b[7m"
+	resetStyle     = "\x1b[0m"
+
+	defaultWidth  = 80
+	defaultHeight = 24
+
+	// redrawInterval batches the redraws of streamed output.
+	redrawInterval = 30 * time.Millisecond
+)
+
+// ErrNotTerminal is returned by New when input or output is not a terminal.
+var ErrNotTerminal = errors.New("the full-screen UI needs an interactive terminal")
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// Screen is the full-screen UI. The pane keeps everything written to Output; the view
+// follows new output unless it was scrolled back with PgUp/Up. The terminal stays in raw
+// mode until Close, so keys typed while an answer streams go to the input box instead of
+// being echoed over the screen.
+type Screen struct {
+	input *os.File
+	out   io.Writer
+	size  func() (int, int)
+	// restore puts the terminal back in the mode it had before New.
+	restore    func()
+	stopResize func()
+	// listen starts readKeys, the only reader of the terminal.
+	listen    sync.Once
+	submitted chan lineResult
+
+	mu sync.Mutex
+	// lines holds the finished lines of the conversation; partial is the line being written.
+	lines   []string
+	partial string
+	// rows caches lines wrapped to rowsWidth, so streamed output only wraps what is new.
+	rows      []string
+	rowsWidth int
+	// redrawPending is set while a batched redraw of streamed output is scheduled.
+	redrawPending bool
+	// scroll is how many wrapped rows the view is scrolled back from the bottom.
+	scroll int
+	status app.Status
+	prompt string
+	edit   []rune
+	cursor int
+	// reading is set while a ReadLine waits for submitted; keys typed in between still
+	// edit the input box.
+	reading  bool
+	inputErr error
+	// interrupt is what Ctrl+C runs while no read waits, i.e. while an answer streams.
+	interrupt func()
+	closed    bool
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// New switches the terminal to the alternate screen; Close switches it back.
+func New(input, output *os.File) (*Screen, error) {
+	if !term.IsTerminal(int(input.Fd())) || !term.IsTerminal(int(output.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	s := newScreen(output, func() (int, int) {
+		width, height, err := term.GetSize(int(output.Fd()))
+		if err != nil {
+			return defaultWidth, defaultHeight
+		}
+		return width, height
+	})
+	fd := int(input.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	s.input = input
+	s.restore = func() { _ = term.Restore(fd, oldState) }
+	s.stopResize = notifyResize(s.redraw)
+	s.listen.Do(func() { go s.readKeys(bufio.NewReader(input)) })
+	fmt.Fprint(s.out, enterAltScreen)
+	s.redraw()
+	return s, nil
+}
+
+func newScreen(out io.Writer, size func() (int, int)) *Screen {
+	return &Screen{out: out, size: size, submitted: make(chan lineResult, 1)}
+}
+
+// Close leaves the alternate screen, restoring what the terminal showed before and its
+// mode. It is safe to call more than once.
+func (s *Screen) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.stopResize != nil {
+		s.stopResize()
+	}
+	_, err := fmt.Fprint(s.out, leaveAltScreen)
+	if s.restore != nil {
+		s.restore()
+	}
+	return err
+}
+
+// SetInterruptHandler sets what Ctrl+C does while no line is being read.
+func (s *Screen) SetInterruptHandler(handler func()) {
+	s.mu.Lock()
+	s.interrupt = handler
+	s.mu.Unlock()
+}
+
+// Output returns the writer for the conversation pane.
+func (s *Screen) Output() io.Writer {
+	return paneWriter{s}
+}
+
+type paneWriter struct {
+	s *Screen
+}
+
+func (w paneWriter) Write(p []byte) (int, error) {
+	w.s.mu.Lock()
+	w.s.appendText(string(p))
+	w.s.mu.Unlock()
+	w.s.scheduleRedraw()
+	return len(p), nil
+}
+
+// scheduleRedraw redraws after redrawInterval, once for everything written until then.
+func (s *Screen) scheduleRedraw() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.redrawPending || s.closed {
+		return
+	}
+	s.redrawPending = true
+	time.AfterFunc(redrawInterval, func() {
+		s.mu.Lock()
+		s.redrawPending = false
+		s.mu.Unlock()
+		s.redraw()
+	})
+}
+
+// appendText adds text to the pane; the caller holds s.mu. Colors are dropped, and a
+// carriage return starts the current line over, as it would on a terminal.
+func (s *Screen) appendText(text string) {
+	text = ansiEscape.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", "    ")
+	for {
+		idx := strings.IndexAny(text, "\r\n")
+		if idx < 0 {
+			s.partial += text
+			return
+		}
+		if text[idx] == '\n' {
+			line := s.partial + text[:idx]
+			s.lines = append(s.lines, line)
+			if s.rowsWidth > 0 {
+				s.rows = append(s.rows, wrapLine(line, s.rowsWidth)...)
+			}
+		}
+		s.partial = ""
+		text = text[idx+1:]
+	}
+}
+
+// SetStatus updates the status bar.
+func (s *Screen) SetStatus(status app.Status) {
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+	s.redraw()
+}
+
+// ReadLine edits a line in the input box. The submitted line is echoed into the pane
+// behind its prompt, as the plain CLI shows it.
+func (s *Screen) ReadLine(prompt string) (string, error) {
+	line, _, err := s.ReadLineWithin(prompt, 0)
+	return line, err
+}
+
+// ReadLineWithin is ReadLine giving up after timeout, or waiting indefinitely when timeout
+// is not positive. A read that gave up leaves the keys to the next one.
+func (s *Screen) ReadLineWithin(prompt string, timeout time.Duration) (string, bool, error) {
+	return s.await(prompt, timeout)
+}
+
+// await shows prompt and waits for readKeys to submit a line.
+func (s *Screen) await(prompt string, timeout time.Duration) (string, bool, error) {
+	s.mu.Lock()
+	if s.inputErr != nil {
+		err := s.inputErr
+		s.mu.Unlock()
+		return "", true, err
+	}
+	s.prompt = ansiEscape.ReplaceAllString(prompt, "")
+	s.reading = true
+	s.mu.Unlock()
+	s.redraw()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case result := <-s.submitted:
+		return result.line, true, result.err
+	case <-expired:
+	}
+	s.mu.Lock()
+	select {
+	case result := <-s.submitted:
+		// The line was submitted as the time ran out.
+		s.mu.Unlock()
+		return result.line, true, result.err
+	default:
+	}
+	s.reading = false
+	s.prompt, s.edit, s.cursor = "", nil, 0
+	s.mu.Unlock()
+	s.redraw()
+	return "", false, nil
+}
+
+// readKeys applies key presses until input ends. Being the only reader of the terminal,
+// it leaves nothing behind a read that timed out to take the next line.
+func (s *Screen) readKeys(reader *bufio.Reader) {
+	for {
+		k, err := readKey(reader)
+		s.mu.Lock()
+		if err != nil {
+			s.inputErr = err
+			s.finishRead(lineResult{err: err})
+			s.mu.Unlock()
+			return
+		}
+		if k.b == 0x03 && !s.reading {
+			// Ctrl+C while an answer streams cancels it, as SIGINT does in the plain CLI.
+			interrupt := s.interrupt
+			s.mu.Unlock()
+			if interrupt != nil {
+				interrupt()
+			}
+			continue
+		}
+		if line, done, err := s.key(k); done {
+			s.finishRead(lineResult{line: line, err: err})
+		}
+		s.mu.Unlock()
+		s.redraw()
+	}
+}
+
+// finishRead hands result to the waiting read; the caller holds s.mu.
+func (s *Screen) finishRead(result lineResult) {
+	if s.reading {
+		s.reading = false
+		s.submitted <- result
+	}
+}
+
+// keyPress is one key: a byte, with the escape sequence that followed ESC or the rest of
+// a multi-byte rune read along with it.
+type keyPress struct {
+	b   byte
+	seq string
+	r   rune
+}
+
+// readKey reads a whole key press, so the screen is not locked while a sequence arrives.
+func readKey(reader *bufio.Reader) (keyPress, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return keyPress{}, err
+	}
+	k := keyPress{b: b, r: rune(b)}
+	switch {
+	case b == 0x1b:
+		k.seq, _ = readEscape(reader)
+	case b >= utf8.RuneSelf:
+		k.r = readRune(b, reader)
+	}
+	return k, nil
+}
+
+// key applies one key press; the caller holds s.mu. done reports that the read is over;
+// Enter, Ctrl+C and Ctrl+D do nothing while no read is waiting.
+func (s *Screen) key(k keyPress) (line string, done bool, err error) {
+	_, height := s.size()
+	page := max(paneHeight(height)-1, 1)
+	switch k.b {
+	case '\r', '\n':
+		if !s.reading {
+			break
+		}
+		line = string(s.edit)
+		s.appendText(s.prompt + line + "\n")
+		s.prompt, s.edit, s.cursor, s.scroll = "", nil, 0, 0
+		return line, true, nil
+	case 0x03: // Ctrl+C leaves the UI like /exit.
+		if s.reading {
+			return "", true, io.EOF
+		}
+	case 0x04: // Ctrl+D on an empty line.
+		if s.reading && len(s.edit) == 0 {
+			return "", true, io.EOF
+		}
+	case 0x7f, 0x08: // Backspace
+		if s.cursor > 0 {
+			s.edit = append(s.edit[:s.cursor-1], s.edit[s.cursor:]...)
+			s.cursor--
+		}
+	case 0x01: // Ctrl+A
+		s.cursor = 0
+	case 0x05: // Ctrl+E
+		s.cursor = len(s.edit)
+	case 0x15: // Ctrl+U
+		s.edit, s.cursor = nil, 0
+	case 0x1b:
+		switch k.seq {
+		case "D":
+			s.cursor = max(s.cursor-1, 0)
+		case "C":
+			s.cursor = min(s.cursor+1, len(s.edit))
+		case "H", "1~", "7~":
+			s.cursor = 0
+		case "F", "4~", "8~":
+			s.cursor = len(s.edit)
+		case "3~":
+			if s.cursor < len(s.edit) {
+				s.edit = append(s.edit[:s.cursor], s.edit[s.cursor+1:]...)
+			}
+		case "A":
+			s.scrollBy(1)
+		case "B":
+			s.scrollBy(-1)
+		case "5~":
+			s.scrollBy(page)
+		case "6~":
+			s.scrollBy(-page)
+		}
+	default:
+		if k.b < 0x20 || k.r == utf8.RuneError {
+			break
+		}
+		s.edit = append(s.edit[:s.cursor], append([]rune{k.r}, s.edit[s.cursor:]...)...)
+		s.cursor++
+	}
+	return "", false, nil
+}
+
+// scrollBy moves the view back by rows, or forward for negative rows; the caller holds s.mu.
+func (s *Screen) scrollBy(rows int) {
+	width, height := s.size()
+	limit := max(len(s.wrapped(width))-paneHeight(height), 0)
+	s.scroll = min(max(s.scroll+rows, 0), limit)
+}
+
+func readEscape(reader *bufio.Reader) (string, bool) {
+	next, err := reader.ReadByte()
+	if err != nil || (next != '[' && next != 'O') {
+		return "", false
+	}
+	var seq []byte
+	for len(seq) < 6 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return "", false
+		}
+		seq = append(seq, b)
+		if b == '~' || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') {
+			break
+		}
+	}
+	return string(seq), true
+}
+
+func readRune(first byte, reader *bufio.Reader) rune {
+	buf := []byte{first}
+	for len(buf) < utf8.UTFMax && !utf8.FullRune(buf) {
+		next, err := reader.ReadByte()
+		if err != nil {
+			return utf8.RuneError
+		}
+		buf = append(buf, next)
+	}
+	r, _ := utf8.DecodeRune(buf)
+	return r
+}
+
+// paneHeight is the number of conversation rows above the status bar and input box.
+func paneHeight(height int) int {
+	return max(height-2, 1)
+}
+
+// wrapped splits the pane's lines into rows of at most width cells; the caller holds s.mu.
+// Finished lines are wrapped again only when the width changes.
+func (s *Screen) wrapped(width int) []string {
+	if width != s.rowsWidth {
+		s.rows, s.rowsWidth = nil, width
+		for _, line := range s.lines {
+			s.rows = append(s.rows, wrapLine(line, width)...)
+		}
+	}
+	if s.partial == "" {
+		return s.rows
+	}
+	return append(s.rows[:len(s.rows):len(s.rows)], wrapLine(s.partial, width)...)
+}
+
+func wrapLine(line string, width int) []string {
+	if line == "" {
+		return []string{""}
+	}
+	var rows []string
+	var row strings.Builder
+	cells := 0
+	for _, r := range line {
+		w := runewidth.RuneWidth(r)
+		if cells+w > width && cells > 0 {
+			rows = append(rows, row.String())
+			row.Reset()
+			cells = 0
+		}
+		row.WriteRune(r)
+		cells += w
+	}
+	return append(rows, row.String())
+}
+
+// frame renders every row of the screen and the cursor position in the input row; the
+// caller holds s.mu.
+func (s *Screen) frame(width, height int) ([]string, int) {
+	rows := s.wrapped(width)
+	paneRows := paneHeight(height)
+	end := max(len(rows)-s.scroll, 0)
+	start := max(end-paneRows, 0)
+	frame := make([]string, 0, paneRows+2)
+	frame = append(frame, rows[start:end]...)
+	for len(frame) < paneRows {
+		frame = append(frame, "")
+	}
+	frame = append(frame, s.statusLine(width))
+
+	// Keep the cursor visible when the input is wider than the box.
+	prompt := []rune(s.prompt)
+	before := runewidth.StringWidth(string(prompt)) + runewidth.StringWidth(string(s.edit[:s.cursor]))
+	text := string(prompt) + string(s.edit)
+	for before >= width && len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		before -= runewidth.RuneWidth(r)
+	}
+	frame = append(frame, runewidth.Truncate(text, width, ""))
+	return frame, before
+}
+
+func (s *Screen) statusLine(width int) string {
+	status := s.status
+	parts := []string{status.Model}
+	if status.Provider != "" {
+		parts[0] += " (" + status.Provider + ")"
+	}
+	if status.ToolMode != "" {
+		parts = append(parts, "tools: "+string(status.ToolMode))
+	}
+	if status.Persona != "" {
+		parts = append(parts, "persona: "+status.Persona)
+	}
+	if status.Profile != "" {
+		parts = append(parts, "profile: "+status.Profile)
+	}
+	if status.Session != "" {
+		parts = append(parts, status.Session)
+	}
+	if s.scroll > 0 {
+		parts = append(parts, fmt.Sprintf("↓ %d more (PgDn)", s.scroll))
+	}
+	line := " " + strings.Join(parts, " │ ")
+	return runewidth.FillRight(runewidth.Truncate(line, width, "…"), width)
+}
+
+// redraw paints the whole screen.
+func (s *Screen) redraw() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	width, height := s.size()
+	rows, cursor := s.frame(width, height)
+	var b strings.Builder
+	b.WriteString("\x1b[?25l\x1b[H")
+	for idx, row := range rows {
+		if idx > 0 {
+			b.WriteString("\r\n")
+		}
+		if idx == len(rows)-2 {
+			b.WriteString(reverseVideo + row + resetStyle)
+			continue
+		}
+		b.WriteString(row)
+		b.WriteString("\x1b[K")
+	}
+	fmt.Fprintf(&b, "\x1b[%d;%dH\x1b[?25h", len(rows), cursor+1)
+	_, _ = io.WriteString(s.out, b.String())
+}
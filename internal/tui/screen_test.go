@@ -0,0 +1,246 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+func testScreen(width, height int) (*Screen, *bytes.Buffer) {
+	var out bytes.Buffer
+	return newScreen(&out, func() (int, int) { return width, height }), &out
+}
+
+// keyboard starts the screen's key loop on a pipe the test types into.
+func keyboard(t *testing.T, s *Screen) io.Writer {
+	t.Helper()
+	input, typing := io.Pipe()
+	s.listen.Do(func() { go s.readKeys(bufio.NewReader(input)) })
+	t.Cleanup(func() { _ = typing.Close() })
+	return typing
+}
+
+// readLine waits for a line at prompt while typed is entered once the read is waiting.
+func readLine(s *Screen, keys io.Writer, prompt, typed string) (string, error) {
+	go func() {
+		for !isReading(s) {
+			time.Sleep(time.Millisecond)
+		}
+		_, _ = io.WriteString(keys, typed)
+	}()
+	line, _, err := s.await(prompt, 0)
+	return line, err
+}
+
+func isReading(s *Screen) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reading
+}
+
+func currentFrame(s *Screen) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	width, height := s.size()
+	rows, _ := s.frame(width, height)
+	return rows
+}
+
+func TestScreenPaneWrapsAndFollowsOutput(t *testing.T) {
+	s, _ := testScreen(10, 5)
+	fmt.Fprint(s.Output(), "\x1b[36mhello\x1b[0m\nabcdefghijklmno\nworking...\rdone")
+
+	rows := currentFrame(s)
+	if len(rows) != 5 {
+		t.Fatalf("expected three pane rows, the status bar and the input box, got %d rows", len(rows))
+	}
+	want := []string{"abcdefghij", "klmno", "done"}
+	if got := rows[:3]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("pane = %q; want %q", got, want)
+	}
+	fmt.Fprint(s.Output(), "!\nnext")
+	rows = currentFrame(s)
+	if rows[1] != "done!" || rows[2] != "next" {
+		t.Fatalf("expected the pane to follow new output, got %q", rows[:3])
+	}
+}
+
+func TestScreenStatusBar(t *testing.T) {
+	s, _ := testScreen(60, 4)
+	s.SetStatus(app.Status{Model: "llama3", Provider: "ollama", ToolMode: config.ToolCallModeManual, Persona: "reviewer", Session: "2026-10-16T10-00-00.json"})
+	status := currentFrame(s)[2]
+	if !strings.HasPrefix(status, " llama3 (ollama) │ tools: manual │ persona: reviewer │ 2026") {
+		t.Fatalf("unexpected status bar %q", status)
+	}
+	if !strings.HasSuffix(status, "…") {
+		t.Fatalf("expected the status bar to be truncated to the width, got %q", status)
+	}
+}
+
+func TestScreenReadLineEditsAndEchoes(t *testing.T) {
+	s, _ := testScreen(40, 6)
+	keys := keyboard(t, s)
+	input := "helo\x1b[D\x1b[Dl\x1b[F!\r"
+	line, err := readLine(s, keys, "\x1b[1mllama3>\x1b[0m ", input)
+	if err != nil || line != "hello!" {
+		t.Fatalf("readLine() = %q, %v; want hello!", line, err)
+	}
+	if rows := currentFrame(s); rows[0] != "llama3> hello!" || rows[len(rows)-1] != "" {
+		t.Fatalf("expected the line echoed into the pane and the input cleared, got %q", rows)
+	}
+
+	if _, err := readLine(s, keys, "> ", "ab\x03"); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected ctrl+c to end input, got %v", err)
+	}
+}
+
+func TestScreenScrollsBack(t *testing.T) {
+	s, _ := testScreen(20, 5)
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(s.Output(), "line %d\n", i)
+	}
+	keys := keyboard(t, s)
+	// Up twice and PgUp (two rows on a three-row pane), then Ctrl+C.
+	if _, err := readLine(s, keys, "> ", "\x1b[A\x1b[A\x1b[5~\x03"); !errors.Is(err, io.EOF) {
+		t.Fatalf("readLine() error = %v", err)
+	}
+	rows := currentFrame(s)
+	if rows[0] != "line 4" || rows[2] != "line 6" {
+		t.Fatalf("expected the view scrolled back four rows, got %q", rows)
+	}
+	if !strings.Contains(rows[3], "↓ 4 more (PgDn)") {
+		t.Fatalf("expected a scroll indicator, got %q", rows[3])
+	}
+
+	if _, err := readLine(s, keys, "> ", "\x1b[5~\x1b[5~\x1b[5~\x1b[5~\r"); err != nil {
+		t.Fatalf("readLine() error = %v", err)
+	}
+	if rows := currentFrame(s); rows[2] != "> " {
+		t.Fatalf("expected submitting a line to return to the bottom, got %q", rows)
+	}
+}
+
+func TestScreenCloseLeavesAltScreen(t *testing.T) {
+	s, out := testScreen(20, 5)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !strings.HasSuffix(out.String(), leaveAltScreen) {
+		t.Fatalf("expected the alternate screen to be left, got %q", out.String())
+	}
+	out.Reset()
+	fmt.Fprint(s.Output(), "late output\n")
+	if out.Len() != 0 {
+		t.Fatalf("expected no drawing after Close, got %q", out.String())
+	}
+}
+
+func TestScreenTimedOutReadLeavesKeysToTheNextRead(t *testing.T) {
+	s, _ := testScreen(40, 6)
+	keys := keyboard(t, s)
+
+	line, answered, err := s.await("Call now? (Y/N): ", 20*time.Millisecond)
+	if err != nil || answered || line != "" {
+		t.Fatalf("await() = %q, %v, %v; want a timeout", line, answered, err)
+	}
+	if rows := currentFrame(s); rows[len(rows)-1] != "" {
+		t.Fatalf("expected the timed-out prompt to be cleared, got %q", rows)
+	}
+
+	line, err = readLine(s, keys, "llama3> ", "hi\r")
+	if err != nil || line != "hi" {
+		t.Fatalf("readLine() = %q, %v; want hi", line, err)
+	}
+	if rows := currentFrame(s); rows[0] != "llama3> hi" {
+		t.Fatalf("expected the line echoed behind the new prompt, got %q", rows)
+	}
+}
+
+func TestScreenIgnoresEnterWhileNoReadWaits(t *testing.T) {
+	s, _ := testScreen(40, 6)
+	keys := keyboard(t, s)
+	_, _ = io.WriteString(keys, "ahead\r")
+	_, _ = io.WriteString(keys, "!")
+	for {
+		s.mu.Lock()
+		typed := string(s.edit)
+		s.mu.Unlock()
+		if typed == "ahead!" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	line, err := readLine(s, keys, "> ", "\r")
+	if err != nil || line != "ahead!" {
+		t.Fatalf("readLine() = %q, %v; want the text typed ahead", line, err)
+	}
+}
+
+func TestScreenRewrapsOnlyWhenTheWidthChanges(t *testing.T) {
+	width := 10
+	var out bytes.Buffer
+	s := newScreen(&out, func() (int, int) { return width, 5 })
+	fmt.Fprint(s.Output(), "abcdefghijklmno\nxy")
+	if rows := currentFrame(s); rows[1] != "klmno" || rows[2] != "xy" {
+		t.Fatalf("unexpected pane %q", rows[:3])
+	}
+	fmt.Fprint(s.Output(), "z\n0123456789ab")
+	if rows := currentFrame(s); strings.Join(rows[:3], "|") != "xyz|0123456789|ab" {
+		t.Fatalf("expected new lines wrapped onto the cached rows, got %q", rows[:3])
+	}
+
+	width = 20
+	if rows := currentFrame(s); strings.Join(rows[:3], "|") != "abcdefghijklmno|xyz|0123456789ab" {
+		t.Fatalf("expected the pane rewrapped to the new width, got %q", rows[:3])
+	}
+}
+
+func TestScreenBatchesRedrawsOfStreamedOutput(t *testing.T) {
+	s, out := testScreen(20, 5)
+	for _, token := range []string{"one ", "two ", "three"} {
+		fmt.Fprint(s.Output(), token)
+	}
+	s.mu.Lock()
+	early := out.String()
+	s.mu.Unlock()
+	if early != "" {
+		t.Fatalf("expected streamed output to wait for the next batched redraw, got %q", early)
+	}
+	time.Sleep(3 * redrawInterval)
+	s.mu.Lock()
+	drawn := out.String()
+	s.mu.Unlock()
+	if strings.Count(drawn, "\x1b[H") != 1 || !strings.Contains(drawn, "one two three") {
+		t.Fatalf("expected one redraw with all the tokens, got %q", drawn)
+	}
+}
+
+func TestScreenCtrlCInterruptsWhileNoReadWaits(t *testing.T) {
+	s, _ := testScreen(20, 5)
+	interrupted := make(chan struct{}, 1)
+	s.SetInterruptHandler(func() { interrupted <- struct{}{} })
+	keys := keyboard(t, s)
+
+	_, _ = io.WriteString(keys, "\x03")
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second):
+		t.Fatalf("expected Ctrl+C to run the interrupt handler while an answer streams")
+	}
+
+	if _, err := readLine(s, keys, "> ", "\x03"); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected Ctrl+C at a prompt to end input, got %v", err)
+	}
+	if len(interrupted) != 0 {
+		t.Fatalf("expected Ctrl+C at a prompt not to run the interrupt handler")
+	}
+}
@@ -0,0 +1,231 @@
+// Package update checks GitHub releases and replaces the running binary with a verified download.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultAPIBaseURL = "https://api.github.com"
+	defaultRepo       = "gamzabox/humble-ai-cli"
+	// ChecksumsAsset is the release asset listing "<sha256>  <asset name>" lines.
+	ChecksumsAsset = "checksums.txt"
+	maxDownload    = 200 << 20
+)
+
+// ErrNoAsset indicates that the release has no binary for the current platform.
+var ErrNoAsset = errors.New("no release asset for this platform")
+
+// Release is the subset of the GitHub release payload used for updates.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Checker talks to the GitHub releases API.
+type Checker struct {
+	Client  *http.Client
+	BaseURL string
+	Repo    string
+}
+
+// NewChecker returns a Checker for the project repository on api.github.com.
+func NewChecker(client *http.Client) *Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Checker{Client: client, BaseURL: defaultAPIBaseURL, Repo: defaultRepo}
+}
+
+// Latest fetches the newest published release.
+func (c *Checker) Latest(ctx context.Context) (Release, error) {
+	url := strings.TrimRight(c.BaseURL, "/") + "/repos/" + c.Repo + "/releases/latest"
+	body, err := c.get(ctx, url, 1<<20)
+	if err != nil {
+		return Release{}, fmt.Errorf("fetch latest release: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return Release{}, fmt.Errorf("parse latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return Release{}, errors.New("latest release has no tag")
+	}
+	return release, nil
+}
+
+// AssetFor returns the binary asset for goos/goarch, named like "humble-ai-cli_linux_amd64[.exe]".
+func (r Release) AssetFor(goos, goarch string) (Asset, bool) {
+	want := fmt.Sprintf("humble-ai-cli_%s_%s", goos, goarch)
+	for _, asset := range r.Assets {
+		if asset.Name == want || asset.Name == want+".exe" {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Download fetches asset and verifies it against the release checksums file.
+func (c *Checker) Download(ctx context.Context, release Release, asset Asset) ([]byte, error) {
+	var checksums Asset
+	for _, a := range release.Assets {
+		if a.Name == ChecksumsAsset {
+			checksums = a
+		}
+	}
+	if checksums.URL == "" {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, ChecksumsAsset)
+	}
+	sums, err := c.get(ctx, checksums.URL, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", ChecksumsAsset, err)
+	}
+	expected, ok := lookupChecksum(sums, asset.Name)
+	if !ok {
+		return nil, fmt.Errorf("%s has no entry for %s", ChecksumsAsset, asset.Name)
+	}
+
+	data, err := c.get(ctx, asset.URL, maxDownload)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", asset.Name, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, expected) {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", asset.Name, got, expected)
+	}
+	return data, nil
+}
+
+func (c *Checker) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+func lookupChecksum(sums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+// Newer reports whether latest is a higher semantic version than current.
+func Newer(current, latest string) bool {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	next, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := range cur {
+		if next[i] != cur[i] {
+			return next[i] > cur[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if core, _, found := strings.Cut(v, "-"); found {
+		v = core
+	}
+	v, _, _ = strings.Cut(v, "+")
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// Replace atomically swaps the file at path for data, keeping it executable.
+func Replace(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return fmt.Errorf("create temp binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write temp binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("chmod temp binary: %w", err)
+	}
+	var old string
+	if runtime.GOOS == "windows" {
+		// A running executable cannot be overwritten on Windows, but it can be renamed.
+		old = path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("move current binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		if old != "" {
+			// Put the current binary back rather than leave nothing at path.
+			if restoreErr := os.Rename(old, path); restoreErr != nil {
+				return fmt.Errorf("replace binary: %w (restoring %s also failed: %v)", err, old, restoreErr)
+			}
+		}
+		return fmt.Errorf("replace binary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package update_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamzabox/humble-ai-cli/internal/update"
+)
+
+func newReleaseServer(t *testing.T, binary []byte, checksum string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/gamzabox/humble-ai-cli/releases/latest":
+			fmt.Fprintf(w, `{"tag_name": "v1.3.0", "html_url": "https://example.com/v1.3.0", "assets": [
+				{"name": "humble-ai-cli_linux_amd64", "browser_download_url": "%[1]s/dl/bin"},
+				{"name": "checksums.txt", "browser_download_url": "%[1]s/dl/sums"}
+			]}`, server.URL)
+		case "/dl/bin":
+			w.Write(binary)
+		case "/dl/sums":
+			fmt.Fprintf(w, "%s  humble-ai-cli_linux_amd64\n", checksum)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sha(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCheckerDownloadsVerifiedAsset(t *testing.T) {
+	binary := []byte("new binary")
+	server := newReleaseServer(t, binary, sha(binary))
+	checker := update.NewChecker(server.Client())
+	checker.BaseURL = server.URL
+
+	release, err := checker.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if release.TagName != "v1.3.0" {
+		t.Fatalf("unexpected tag %q", release.TagName)
+	}
+	asset, ok := release.AssetFor("linux", "amd64")
+	if !ok {
+		t.Fatalf("expected linux/amd64 asset")
+	}
+	if _, ok := release.AssetFor("darwin", "arm64"); ok {
+		t.Fatalf("did not expect darwin/arm64 asset")
+	}
+	data, err := checker.Download(context.Background(), release, asset)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if string(data) != "new binary" {
+		t.Fatalf("unexpected data %q", data)
+	}
+}
+
+func TestCheckerRejectsChecksumMismatch(t *testing.T) {
+	server := newReleaseServer(t, []byte("tampered"), sha([]byte("original")))
+	checker := update.NewChecker(server.Client())
+	checker.BaseURL = server.URL
+
+	release, err := checker.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	asset, _ := release.AssetFor("linux", "amd64")
+	if _, err := checker.Download(context.Background(), release, asset); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+
+	release.Assets = release.Assets[:1]
+	if _, err := checker.Download(context.Background(), release, asset); err == nil || !strings.Contains(err.Error(), "checksums.txt") {
+		t.Fatalf("expected missing checksums error, got %v", err)
+	}
+}
+
+func TestNewer(t *testing.T) {
+	cases := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v1.2.0", "v1.3.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"1.10.0", "v1.9.9", false},
+		{"v1.2.0-rc.1", "v1.2.1", true},
+		{"dev", "v0.1.0", true},
+		{"v1.0.0", "nightly", false},
+	}
+	for _, tc := range cases {
+		if got := update.Newer(tc.current, tc.latest); got != tc.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tc.current, tc.latest, got, tc.want)
+		}
+	}
+}
+
+func TestReplaceSwapsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "humble-ai-cli")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := update.Replace(path, []byte("new")); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Fatalf("expected replaced contents, got %q (%v)", data, err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Fatalf("expected executable mode, got %v", info.Mode())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected temp file cleanup, got %d entries", len(entries))
+	}
+}
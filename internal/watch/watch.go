@@ -0,0 +1,187 @@
+// Package watch polls the files matching a glob pattern and reports when they change.
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is how often files are polled when no interval is given.
+const DefaultInterval = time.Second
+
+// Watcher remembers the size and modification time of every matching file; a file counts
+// as changed when either differs, appears or disappears.
+type Watcher struct {
+	pattern  string
+	interval time.Duration
+	stamps   map[string]stamp
+}
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New starts watching pattern. Besides the filepath.Match syntax, a "**" path element
+// matches any number of directories, e.g. "src/**/*.go".
+func New(pattern string, interval time.Duration) (*Watcher, error) {
+	pattern = filepath.Clean(strings.TrimSpace(pattern))
+	for _, element := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if _, err := filepath.Match(element, ""); err != nil {
+			return nil, err
+		}
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	w := &Watcher{pattern: pattern, interval: interval}
+	stamps, err := w.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	w.stamps = stamps
+	return w, nil
+}
+
+// Files returns the matching files as of the last poll, sorted.
+func (w *Watcher) Files() []string {
+	files := make([]string, 0, len(w.stamps))
+	for path := range w.stamps {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Wait polls until a matching file changes and returns the changed paths, sorted. Editors
+// often save in several writes, so it keeps polling until a poll sees nothing new.
+func (w *Watcher) Wait(ctx context.Context) ([]string, error) {
+	changed := map[string]bool{}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		stamps, err := w.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		fresh := diff(w.stamps, stamps)
+		w.stamps = stamps
+		if len(fresh) == 0 && len(changed) > 0 {
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			return paths, nil
+		}
+		for _, path := range fresh {
+			changed[path] = true
+		}
+	}
+}
+
+func diff(old, current map[string]stamp) []string {
+	var changed []string
+	for path, s := range current {
+		if prev, ok := old[path]; !ok || prev != s {
+			changed = append(changed, path)
+		}
+	}
+	for path := range old {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+func (w *Watcher) snapshot() (map[string]stamp, error) {
+	paths, err := glob(w.pattern)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]stamp, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		stamps[path] = stamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// glob expands pattern; patterns with a "**" element walk the directory before it.
+func glob(pattern string) ([]string, error) {
+	elements := strings.Split(filepath.ToSlash(pattern), "/")
+	recursive := -1
+	for i, element := range elements {
+		if element == "**" {
+			recursive = i
+			break
+		}
+	}
+	if recursive < 0 {
+		return filepath.Glob(pattern)
+	}
+
+	root := filepath.FromSlash(strings.Join(elements[:recursive], "/"))
+	if recursive == 0 {
+		root = "."
+	} else if root == "" {
+		root = string(filepath.Separator)
+	}
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if matchElements(elements[recursive:], strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// matchElements matches path elements against pattern elements, where "**" takes any
+// number of elements.
+func matchElements(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchElements(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pattern[0], path[0])
+	return ok && matchElements(pattern[1:], path[1:])
+}
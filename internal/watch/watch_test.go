@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestNewMatchesRecursivePattern(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), "package main")
+	writeFile(t, filepath.Join(dir, "internal", "app", "app.go"), "package app")
+	writeFile(t, filepath.Join(dir, "internal", "app", "notes.txt"), "notes")
+
+	w, err := New(filepath.Join(dir, "**", "*.go"), time.Millisecond)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "internal", "app", "app.go"), filepath.Join(dir, "main.go")}
+	if got := w.Files(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestNewRejectsBadPattern(t *testing.T) {
+	if _, err := New("src/[a-.go", time.Second); err == nil {
+		t.Fatal("expected an error for a malformed pattern")
+	}
+}
+
+func TestWaitReportsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.go")
+	edited := filepath.Join(dir, "edited.go")
+	writeFile(t, kept, "package a")
+	writeFile(t, edited, "package a")
+
+	w, err := New(filepath.Join(dir, "*.go"), 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	added := filepath.Join(dir, "added.go")
+	writeFile(t, edited, "package a // edited")
+	writeFile(t, added, "package a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed, err := w.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if want := []string{added, edited}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("Wait() = %v, want %v", changed, want)
+	}
+	if len(w.Files()) != 3 {
+		t.Fatalf("expected three watched files, got %v", w.Files())
+	}
+}
+
+func TestWaitStopsWithContext(t *testing.T) {
+	w, err := New(filepath.Join(t.TempDir(), "*.go"), time.Millisecond)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := w.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to stop when the context ends")
+	}
+}
@@ -0,0 +1,205 @@
+// Package workspace detects the project the CLI was started in and builds a compact
+// summary of it for the model.
+package workspace
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Markers identify a project root, checked in this order.
+var Markers = []string{"go.mod", "package.json", ".git"}
+
+// DefaultKeyFiles are the files whose first lines are included when present in the root.
+var DefaultKeyFiles = []string{"README.md", "go.mod", "package.json", "pyproject.toml", "Cargo.toml", "Makefile"}
+
+// Default limits used when an Options field is zero.
+const (
+	DefaultMaxDepth    = 3
+	DefaultMaxEntries  = 200
+	DefaultHeaderLines = 10
+)
+
+// skippedDirs are never listed in the tree.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+// Options limits the size of a summary; zero values use the defaults.
+type Options struct {
+	MaxDepth    int
+	MaxEntries  int
+	HeaderLines int
+	KeyFiles    []string
+}
+
+// FindRoot walks up from dir to the nearest directory containing a project marker and
+// returns it with the markers found there.
+func FindRoot(dir string) (string, []string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, false
+	}
+	for {
+		var found []string
+		for _, marker := range Markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				found = append(found, marker)
+			}
+		}
+		if len(found) > 0 {
+			return dir, found, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, false
+		}
+		dir = parent
+	}
+}
+
+// Summarize describes the project at root: its markers, a depth-limited directory tree
+// and the first lines of key files.
+func Summarize(root string, markers []string, opts Options) (string, error) {
+	opts = withDefaults(opts)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s (%s)\n", filepath.Base(root), root)
+	if len(markers) > 0 {
+		fmt.Fprintf(&b, "Detected by: %s\n", strings.Join(markers, ", "))
+	}
+
+	b.WriteString("\nTree:\n")
+	remaining := opts.MaxEntries
+	omitted := 0
+	if err := writeTree(&b, root, 0, opts.MaxDepth, &remaining, &omitted); err != nil {
+		return "", err
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "  ... (%d more entries)\n", omitted)
+	}
+
+	for _, name := range opts.KeyFiles {
+		path, ok := keyFilePath(root, name)
+		if !ok {
+			continue
+		}
+		lines, truncated, err := headLines(path, opts.HeaderLines)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n--- %s ---\n", name)
+		for _, line := range lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		if truncated {
+			b.WriteString("...\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.HeaderLines <= 0 {
+		opts.HeaderLines = DefaultHeaderLines
+	}
+	if len(opts.KeyFiles) == 0 {
+		opts.KeyFiles = DefaultKeyFiles
+	}
+	return opts
+}
+
+// writeTree lists dir with directories first, skipping hidden and generated directories.
+func writeTree(b *strings.Builder, dir string, depth, maxDepth int, remaining, omitted *int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if depth == 0 {
+			return fmt.Errorf("read project directory: %w", err)
+		}
+		return nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+	indent := strings.Repeat("  ", depth+1)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || (entry.IsDir() && skippedDirs[name]) {
+			continue
+		}
+		if *remaining == 0 {
+			*omitted++
+			continue
+		}
+		*remaining--
+		if !entry.IsDir() {
+			fmt.Fprintf(b, "%s%s\n", indent, name)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s/\n", indent, name)
+		if depth+1 < maxDepth {
+			if err := writeTree(b, filepath.Join(dir, name), depth+1, maxDepth, remaining, omitted); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// keyFilePath resolves name inside root and reports false when it is not a local path
+// or a symlink leads outside the project.
+func keyFilePath(root, name string) (string, bool) {
+	if !filepath.IsLocal(name) {
+		return "", false
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", false
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(resolvedRoot, name))
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(resolvedRoot, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return path, true
+}
+
+func headLines(path string, limit int) ([]string, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(lines) == limit {
+			return lines, true, nil
+		}
+		lines = append(lines, scanner.Text())
+	}
+	return lines, false, scanner.Err()
+}
@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestFindRootWalksUpToMarker(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/demo\n")
+	nested := filepath.Join(root, "internal", "deep")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	got, markers, ok := FindRoot(nested)
+	if !ok || got != root || strings.Join(markers, ",") != "go.mod" {
+		t.Fatalf("FindRoot() = %q, %v, %v", got, markers, ok)
+	}
+}
+
+func TestSummarizeListsTreeAndKeyFileHeaders(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/demo\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "README.md"), "# Demo\nline 2\nline 3\n")
+	writeFile(t, filepath.Join(root, "cmd", "demo", "main.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "cmd", "demo", "deeper", "x.go"), "package deeper\n")
+	writeFile(t, filepath.Join(root, "node_modules", "left-pad", "index.js"), "")
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+
+	got, err := Summarize(root, []string{"go.mod", ".git"}, Options{MaxDepth: 2, HeaderLines: 2})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	want := "Project: " + filepath.Base(root) + " (" + root + ")\n" +
+		"Detected by: go.mod, .git\n\n" +
+		"Tree:\n" +
+		"  cmd/\n" +
+		"    demo/\n" +
+		"  README.md\n" +
+		"  go.mod\n\n" +
+		"--- README.md ---\n# Demo\nline 2\n...\n\n" +
+		"--- go.mod ---\nmodule example.com/demo\n\n..."
+	if got != want {
+		t.Fatalf("Summarize() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSummarizeCapsEntries(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		writeFile(t, filepath.Join(root, name), "")
+	}
+	got, err := Summarize(root, nil, Options{MaxEntries: 1, KeyFiles: []string{"none"}})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if !strings.Contains(got, "  a.txt\n  ... (2 more entries)") {
+		t.Fatalf("expected capped tree, got:\n%s", got)
+	}
+}
+
+func TestSummarizeSkipsKeyFilesOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "project")
+	writeFile(t, filepath.Join(parent, "secret"), "top secret\n")
+	writeFile(t, filepath.Join(root, "README.md"), "# Demo\n")
+	if err := os.Symlink(filepath.Join(parent, "secret"), filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+
+	got, err := Summarize(root, nil, Options{KeyFiles: []string{"../secret", "link", "README.md"}})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if strings.Contains(got, "top secret") || strings.Contains(got, "--- ../secret ---") || strings.Contains(got, "--- link ---") {
+		t.Fatalf("expected files outside the project to be skipped, got:\n%s", got)
+	}
+	if !strings.Contains(got, "--- README.md ---\n# Demo") {
+		t.Fatalf("expected README.md header, got:\n%s", got)
+	}
+}
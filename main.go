@@ -4,11 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 
-	"github.com/gamzabox/humble-ai-cli/internal/app"
-	"github.com/gamzabox/humble-ai-cli/internal/config"
-	"github.com/gamzabox/humble-ai-cli/internal/llm"
+	"github.com/gamzabox/humble-ai-cli/internal/cli"
 )
 
 func main() {
@@ -18,27 +15,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	store := config.NewFileStore(home)
-	factory := llm.NewFactory(nil)
-
-	options := app.Options{
-		Store:          store,
-		Factory:        factory,
-		Input:          os.Stdin,
-		Output:         os.Stdout,
-		ErrorOutput:    os.Stderr,
-		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
-		HomeDir:        home,
+	env := cli.Environment{
+		Home:       home,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		StdinPiped: stdinPiped(),
 	}
+	os.Exit(cli.Run(context.Background(), env, os.Args[1:]))
+}
 
-	instance, err := app.New(options)
+// stdinPiped reports whether stdin carries piped data instead of an interactive terminal.
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to initialize application: %v\n", err)
-		os.Exit(1)
-	}
-
-	if err := instance.Run(context.Background()); err != nil {
-		fmt.Fprintf(os.Stderr, "application error: %v\n", err)
-		os.Exit(1)
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice == 0
 }
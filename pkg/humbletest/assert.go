@@ -0,0 +1,15 @@
+package humbletest
+
+import (
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+var (
+	_ config.Store        = (*Store)(nil)
+	_ app.Clock           = (*Clock)(nil)
+	_ app.MCPExecutor     = (*MCP)(nil)
+	_ app.ProviderFactory = Factory(nil)
+	_ llm.ChatProvider    = (*Provider)(nil)
+)
@@ -0,0 +1,38 @@
+package humbletest
+
+import "github.com/gamzabox/humble-ai-cli/internal/llm"
+
+// Token is a chunk of answer text.
+func Token(text string) llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkToken, Content: text}
+}
+
+// Thinking is a chunk of reasoning text.
+func Thinking(text string) llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkThinking, Content: text}
+}
+
+// ToolCall asks the app to call server.method with arguments.
+func ToolCall(server, method string, arguments map[string]any) llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkToolCall, ToolCall: &llm.ToolCall{Server: server, Method: method, Arguments: arguments}}
+}
+
+// Done ends a turn normally.
+func Done() llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkDone}
+}
+
+// DoneWith ends a turn with a finish reason, such as llm.FinishLength.
+func DoneWith(reason string) llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkDone, FinishReason: reason}
+}
+
+// Error ends a turn with err, as a provider failing mid stream does.
+func Error(err error) llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkError, Err: err}
+}
+
+// Routing reports that the answer came from model at provider.
+func Routing(model, provider string) llm.StreamChunk {
+	return llm.StreamChunk{Type: llm.ChunkRouting, Routing: &llm.RoutingInfo{Model: model, Provider: provider}}
+}
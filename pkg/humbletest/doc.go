@@ -0,0 +1,17 @@
+// Package humbletest provides test doubles for code that embeds the app: an in-memory
+// config store, a scripted MCP executor, a settable clock, and scripted providers built
+// from stream chunks. They plug into Options, so integration tests run real turns without
+// a home directory full of config, MCP servers or a model endpoint. The app, config and
+// llm types the helpers use are re-exported as aliases, so callers outside this module
+// need no other import.
+//
+//	store := humbletest.NewStore(humbletest.Config{Models: []humbletest.Model{{Name: "m", Provider: "ollama", Active: true}}})
+//	provider := humbletest.NewProvider([]humbletest.StreamChunk{humbletest.Token("hi")})
+//	a, err := humbletest.NewApp(humbletest.Options{
+//		Store:   store,
+//		Factory: humbletest.Factory{"m": provider},
+//		MCP:     humbletest.NewMCP(),
+//		Clock:   humbletest.NewClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+//		...
+//	})
+package humbletest
@@ -0,0 +1,111 @@
+package humbletest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamzabox/humble-ai-cli/pkg/humbletest"
+)
+
+func TestHelpersRunAToolCallingSession(t *testing.T) {
+	home := t.TempDir()
+	store := humbletest.NewStore(humbletest.Config{
+		ToolCallMode: "auto",
+		Models:       []humbletest.Model{{Name: "stub-model", Provider: "openai", APIKey: "sk", Active: true}},
+	})
+	provider := humbletest.NewProvider(
+		[]humbletest.StreamChunk{
+			humbletest.Thinking("adding"),
+			humbletest.ToolCall("calculator", "add", map[string]any{"a": float64(2), "b": float64(3)}),
+			humbletest.Token("Final answer: 5"),
+		},
+	)
+	mcp := humbletest.NewMCP().
+		AddServer("calculator", "Adds numbers.", humbletest.MCPFunction{Name: "add", Description: "Add two numbers."}).
+		Respond("calculator", "add", humbletest.ToolResult{Content: "5"})
+	clock := humbletest.NewClock(time.Date(2025, 10, 16, 16, 20, 30, 0, time.UTC))
+
+	var output bytes.Buffer
+	a, err := humbletest.NewApp(humbletest.Options{
+		Store:          store,
+		Factory:        humbletest.Factory{"stub-model": provider},
+		Input:          strings.NewReader("Please add\n/hints off\n/exit\n"),
+		Output:         &output,
+		ErrorOutput:    &output,
+		HistoryRootDir: filepath.Join(home, ".humble-ai-cli", "sessions"),
+		HomeDir:        home,
+		MCP:            mcp,
+		Clock:          clock,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := a.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := output.String()
+	if !strings.Contains(got, "Final answer: 5") {
+		t.Fatalf("expected the scripted answer, got:\n%s", got)
+	}
+	calls := mcp.Calls()
+	if len(calls) != 1 || calls[0].Server != "calculator" || calls[0].Method != "add" || calls[0].Arguments["b"] != float64(3) {
+		t.Fatalf("unexpected MCP calls: %+v", calls)
+	}
+	if results := provider.ToolResults(); len(results) != 1 || results[0].Content != "5" {
+		t.Fatalf("expected the tool result sent back to the provider, got %+v", results)
+	}
+	if requests := provider.Requests(); len(requests) != 1 {
+		t.Fatalf("expected one request, got %d", len(requests))
+	}
+	cfg, _ := store.Load()
+	if store.Saves() != 1 || !cfg.DisableHints {
+		t.Fatalf("expected /hints off to be saved once, got %d saves, %+v", store.Saves(), cfg.DisableHints)
+	}
+	if !mcp.Closed() {
+		t.Fatal("expected Close to close the MCP executor")
+	}
+}
+
+func TestProviderReportsExhaustedScript(t *testing.T) {
+	provider := humbletest.NewProvider([]humbletest.StreamChunk{humbletest.Token("only")})
+	var types []humbletest.ChunkType
+	for range 2 {
+		stream, err := provider.Stream(context.Background(), humbletest.ChatRequest{TurnID: "t"})
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		for chunk := range stream {
+			types = append(types, chunk.Type)
+			if chunk.Type == humbletest.ChunkError && !errors.Is(chunk.Err, humbletest.ErrNoTurnLeft) {
+				t.Fatalf("unexpected error %v", chunk.Err)
+			}
+		}
+	}
+	want := []humbletest.ChunkType{humbletest.ChunkToken, humbletest.ChunkDone, humbletest.ChunkError}
+	if len(types) != len(want) {
+		t.Fatalf("chunk types = %v, want %v", types, want)
+	}
+	for idx := range want {
+		if types[idx] != want[idx] {
+			t.Fatalf("chunk types = %v, want %v", types, want)
+		}
+	}
+}
+
+func TestClockAdvances(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := humbletest.NewClock(start)
+	clock.Advance(time.Minute)
+	if got := clock.Now(); !got.Equal(start.Add(time.Minute)) {
+		t.Fatalf("Now() = %v", got)
+	}
+}
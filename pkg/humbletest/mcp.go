@@ -0,0 +1,136 @@
+package humbletest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// MCPCall is a tool call the MCP executor received.
+type MCPCall struct {
+	Server    string
+	Method    string
+	Arguments map[string]any
+}
+
+type mcpReply struct {
+	result llm.ToolResult
+	err    error
+}
+
+// MCP is a scripted app.MCPExecutor. Servers and their functions are declared with
+// AddServer; calls answer with the reply set by Respond or Fail, and a call without one
+// gets an error result naming the function.
+type MCP struct {
+	mu      sync.Mutex
+	servers []app.MCPServer
+	tools   map[string][]app.MCPFunction
+	replies map[string]mcpReply
+	calls   []MCPCall
+	reloads int
+	closed  bool
+}
+
+// NewMCP returns an executor with no servers.
+func NewMCP() *MCP {
+	return &MCP{tools: map[string][]app.MCPFunction{}, replies: map[string]mcpReply{}}
+}
+
+// AddServer enables a server offering functions and returns m for chaining.
+func (m *MCP) AddServer(name, description string, functions ...app.MCPFunction) *MCP {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers = append(m.servers, app.MCPServer{Name: name, Description: description})
+	m.tools[name] = append(m.tools[name], functions...)
+	return m
+}
+
+// Respond makes calls to server.method return result.
+func (m *MCP) Respond(server, method string, result llm.ToolResult) *MCP {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replies[server+"."+method] = mcpReply{result: result}
+	return m
+}
+
+// Fail makes calls to server.method fail with err, as a broken server would.
+func (m *MCP) Fail(server, method string, err error) *MCP {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replies[server+"."+method] = mcpReply{err: err}
+	return m
+}
+
+// EnabledServers returns the servers added so far.
+func (m *MCP) EnabledServers() []app.MCPServer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]app.MCPServer(nil), m.servers...)
+}
+
+// Describe returns the named server.
+func (m *MCP) Describe(server string) (app.MCPServer, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, srv := range m.servers {
+		if srv.Name == server {
+			return srv, true
+		}
+	}
+	return app.MCPServer{}, false
+}
+
+// Call records the call and returns the scripted reply.
+func (m *MCP) Call(ctx context.Context, server, method string, arguments map[string]any) (llm.ToolResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, MCPCall{Server: server, Method: method, Arguments: arguments})
+	reply, ok := m.replies[server+"."+method]
+	if !ok {
+		return llm.ToolResult{Content: fmt.Sprintf("humbletest: no reply scripted for %s.%s", server, method), IsError: true}, nil
+	}
+	return reply.result, reply.err
+}
+
+// Tools returns the functions added for server.
+func (m *MCP) Tools(ctx context.Context, server string) ([]app.MCPFunction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tools[server]; !ok {
+		return nil, fmt.Errorf("humbletest: unknown MCP server %q", server)
+	}
+	return append([]app.MCPFunction(nil), m.tools[server]...), nil
+}
+
+// Reload counts the reload; the scripted servers stay as they are.
+func (m *MCP) Reload() error {
+	m.mu.Lock()
+	m.reloads++
+	m.mu.Unlock()
+	return nil
+}
+
+// Close marks the executor closed.
+func (m *MCP) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	return nil
+}
+
+// Calls returns the calls received so far, oldest first.
+func (m *MCP) Calls() []MCPCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MCPCall(nil), m.calls...)
+}
+
+// Closed reports whether Close was called.
+func (m *MCP) Closed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
@@ -0,0 +1,116 @@
+package humbletest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// ErrNoTurnLeft is the stream error of a request that arrives after every scripted turn
+// was used.
+var ErrNoTurnLeft = errors.New("humbletest: no scripted turn left")
+
+// Provider is an llm.ChatProvider that answers each request with the next scripted turn.
+// A turn not ending in Done or Error gets a Done. After a ToolCall chunk the stream waits
+// until the app responds to the call, as real providers do, before sending the rest.
+type Provider struct {
+	mu       sync.Mutex
+	turns    [][]llm.StreamChunk
+	requests []llm.ChatRequest
+	results  []llm.ToolResult
+}
+
+// NewProvider returns a provider that plays turns in order, one per request.
+func NewProvider(turns ...[]llm.StreamChunk) *Provider {
+	return &Provider{turns: turns}
+}
+
+// Script appends turns to play after the ones already scripted.
+func (p *Provider) Script(turns ...[]llm.StreamChunk) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.turns = append(p.turns, turns...)
+	return p
+}
+
+// Stream records req and plays the next turn.
+func (p *Provider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	var turn []llm.StreamChunk
+	if len(p.turns) > 0 {
+		turn, p.turns = p.turns[0], p.turns[1:]
+	} else {
+		turn = []llm.StreamChunk{Error(ErrNoTurnLeft)}
+	}
+	p.mu.Unlock()
+
+	if n := len(turn); n == 0 || (turn[n-1].Type != llm.ChunkDone && turn[n-1].Type != llm.ChunkError) {
+		turn = append(turn[:n:n], Done())
+	}
+	out := make(chan llm.StreamChunk)
+	go func() {
+		defer close(out)
+		for idx, chunk := range turn {
+			chunk.Seq = uint64(idx + 1)
+			chunk.TurnID = req.TurnID
+			var answered chan struct{}
+			if chunk.Type == llm.ChunkToolCall && chunk.ToolCall != nil {
+				call := *chunk.ToolCall
+				answered = make(chan struct{})
+				var once sync.Once
+				call.Respond = func(ctx context.Context, result llm.ToolResult) error {
+					p.mu.Lock()
+					p.results = append(p.results, result)
+					p.mu.Unlock()
+					once.Do(func() { close(answered) })
+					return nil
+				}
+				chunk.ToolCall = &call
+			}
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+			if answered != nil {
+				select {
+				case <-answered:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Requests returns the requests received so far, oldest first.
+func (p *Provider) Requests() []llm.ChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]llm.ChatRequest(nil), p.requests...)
+}
+
+// ToolResults returns the tool results the app sent back, oldest first.
+func (p *Provider) ToolResults() []llm.ToolResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]llm.ToolResult(nil), p.results...)
+}
+
+// Factory is an app.ProviderFactory mapping model names to providers.
+type Factory map[string]llm.ChatProvider
+
+// Create returns the provider registered for the model's name.
+func (f Factory) Create(model config.Model) (llm.ChatProvider, error) {
+	provider, ok := f[model.Name]
+	if !ok {
+		return nil, fmt.Errorf("humbletest: no provider for model %q", model.Name)
+	}
+	return provider, nil
+}
@@ -0,0 +1,45 @@
+package humbletest
+
+import (
+	"sync"
+
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+)
+
+// Store is an in-memory config.Store.
+type Store struct {
+	mu    sync.Mutex
+	cfg   config.Config
+	saves int
+}
+
+// NewStore returns a store that loads cfg until something is saved.
+func NewStore(cfg config.Config) *Store {
+	return &Store{cfg: cfg}
+}
+
+// Load returns the current configuration.
+func (s *Store) Load() (config.Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg, nil
+}
+
+// Save replaces the configuration after validating it, as the file store does.
+func (s *Store) Save(cfg config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.saves++
+	return nil
+}
+
+// Saves reports how many times the configuration was saved.
+func (s *Store) Saves() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saves
+}
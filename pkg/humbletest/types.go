@@ -0,0 +1,55 @@
+package humbletest
+
+import (
+	"github.com/gamzabox/humble-ai-cli/internal/app"
+	"github.com/gamzabox/humble-ai-cli/internal/config"
+	"github.com/gamzabox/humble-ai-cli/internal/llm"
+)
+
+// The aliases below re-export the internal types the helpers work with, so code outside
+// this module can build an app and read what it sent without importing internal packages.
+type (
+	// App is a running CLI session.
+	App = app.App
+	// Options configures NewApp.
+	Options = app.Options
+	// MCPServer describes an MCP server offered by an executor.
+	MCPServer = app.MCPServer
+	// MCPFunction describes a function of an MCP server.
+	MCPFunction = app.MCPFunction
+
+	// Config is the contents of config.json.
+	Config = config.Config
+	// Model is one configured model.
+	Model = config.Model
+	// ToolCallMode is the confirmation mode for tool calls.
+	ToolCallMode = config.ToolCallMode
+
+	// ChatRequest is a request the app sent to a provider.
+	ChatRequest = llm.ChatRequest
+	// Message is one message of a ChatRequest.
+	Message = llm.Message
+	// StreamChunk is one piece of a provider's answer.
+	StreamChunk = llm.StreamChunk
+	// ChunkType tells stream chunks apart.
+	ChunkType = llm.ChunkType
+	// ToolResult is the result of a tool call.
+	ToolResult = llm.ToolResult
+)
+
+// Chunk types, for checking what a provider streamed.
+const (
+	ChunkToken    = llm.ChunkToken
+	ChunkThinking = llm.ChunkThinking
+	ChunkToolCall = llm.ChunkToolCall
+	ChunkDone     = llm.ChunkDone
+	ChunkError    = llm.ChunkError
+)
+
+// FinishLength is the finish reason of an answer cut at the token limit, for DoneWith.
+const FinishLength = llm.FinishLength
+
+// NewApp creates an app from opts, as the CLI does.
+func NewApp(opts Options) (*App, error) {
+	return app.New(opts)
+}